@@ -1,24 +1,43 @@
 package main
 
 import (
+	"encoding/json"
+	"flag"
+	"io"
 	"net/http"
 	_ "net/http/pprof"
 	"log"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/Kindling-project/kindling/collector/internal/application"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/payloadprofile"
 	"github.com/Kindling-project/kindling/collector/pkg/version"
 )
 
 func main() {
 	// Print version information
 	log.Printf("GitCommitInfo:%s\n", version.Version())
+	if len(os.Args) > 1 && os.Args[1] == "check" {
+		os.Exit(check(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "payloadprofile" {
+		os.Exit(payloadProfile(os.Args[2:], os.Stdout))
+	}
 	go func() {
 		log.Println(http.ListenAndServe(":6060", nil))
 	}()
-	app, err := application.New()
+	var app *application.Application
+	var err error
+	if len(os.Args) > 1 && os.Args[1] == "aggregator" {
+		app, err = newAggregator(os.Args[2:])
+	} else {
+		app, err = application.New()
+	}
 	if err != nil {
 		log.Fatalf("Failed to create application: %v", err)
 	}
@@ -30,14 +49,98 @@ func main() {
 
 	// Register signal handler
 	sigCh := make(chan os.Signal, 1)
-	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
 	// Block until a signal is received.
-	select {
-	case sig := <-sigCh:
+	for sig := range sigCh {
+		if sig == syscall.SIGHUP {
+			log.Printf("Received signal [%v], and will reload the configuration", sig)
+			if err = app.Reload(); err != nil {
+				log.Printf("Error happened when reloading: %v", err)
+			}
+			continue
+		}
 		log.Printf("Received signal [%v], and will exit", sig)
 		if err = app.Shutdown(); err != nil {
 			log.Printf("Error happened when shutting down: %v", err)
 			os.Exit(1)
 		}
+		return
+	}
+}
+
+// check verifies the agent works on the node, e.g. "kindling-collector check --config <path>".
+func check(args []string) int {
+	flags := flag.NewFlagSet("check", flag.ExitOnError)
+	configPath := flags.String("config", "kindling-collector-config.yml", "Configuration file")
+	timeout := flags.Duration("timeout", time.Minute, "Time to wait for the records of the test traffic")
+	_ = flags.Parse(args)
+	if err := application.Check(*configPath, *timeout); err != nil {
+		log.Printf("Check failed: %v", err)
+		return 1
+	}
+	log.Printf("Check passed: the records of the HTTP and DNS test traffic are received")
+	return 0
+}
+
+// newAggregator creates the aggregator receiving the records from the node agents, e.g.
+// "kindling-collector aggregator --config <path>".
+func newAggregator(args []string) (*application.Application, error) {
+	flags := flag.NewFlagSet("aggregator", flag.ExitOnError)
+	configPath := flags.String("config", "kindling-collector-config.yml", "Configuration file")
+	_ = flags.Parse(args)
+	return application.NewAggregator(*configPath)
+}
+
+// payloadProfile profiles the payloads of the capture files offline and prints the reports in JSON, e.g.
+// "kindling-collector payloadprofile --ports 7000,7001 <file>...". The reports are the same as the ones
+// at "/payloadprofile" of the controller.
+func payloadProfile(args []string, stdout io.Writer) int {
+	flags := flag.NewFlagSet("payloadprofile", flag.ExitOnError)
+	cfg := payloadprofile.NewDefaultConfig()
+	portsStr := flags.String("ports", "", "Comma-separated destination ports to be profiled, all ports if empty")
+	flags.IntVar(&cfg.PrefixLength, "prefix_length", cfg.PrefixLength, "Number of leading bytes used to cluster the payloads")
+	flags.Float64Var(&cfg.SimilarityThreshold, "similarity_threshold", cfg.SimilarityThreshold, "Minimum cosine similarity of the histograms to join a cluster")
+	flags.IntVar(&cfg.MaxPorts, "max_ports", cfg.MaxPorts, "Maximum number of ports to be profiled")
+	flags.IntVar(&cfg.MaxClustersPerPort, "max_clusters_per_port", cfg.MaxClustersPerPort, "Maximum number of clusters of each port")
+	_ = flags.Parse(args)
+	if flags.NArg() == 0 {
+		log.Printf("Usage: kindling-collector payloadprofile [flags] <pcap or pcapng file>...")
+		return 2
+	}
+	var ports []uint32
+	for _, portStr := range strings.Split(*portsStr, ",") {
+		if portStr = strings.TrimSpace(portStr); portStr == "" {
+			continue
+		}
+		port, err := strconv.ParseUint(portStr, 10, 16)
+		if err != nil {
+			log.Printf("Invalid port %q: %v", portStr, err)
+			return 2
+		}
+		ports = append(ports, uint32(port))
+	}
+	// The payloads of the same port in all files are clustered together.
+	profiler := payloadprofile.NewProfiler(cfg)
+	for _, path := range flags.Args() {
+		if err := observePcapFile(profiler, path, ports); err != nil {
+			log.Printf("Failed to profile the payloads of %s: %v", path, err)
+			return 1
+		}
+	}
+	encoder := json.NewEncoder(stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(profiler.Report()); err != nil {
+		log.Printf("Failed to write the reports: %v", err)
+		return 1
+	}
+	return 0
+}
+
+func observePcapFile(profiler *payloadprofile.Profiler, path string, ports []uint32) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
 	}
+	defer file.Close()
+	return profiler.ObservePcap(file, ports)
 }
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/payloadprofile"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/pcapexport"
+)
+
+func TestPayloadProfile(t *testing.T) {
+	dir := t.TempDir()
+	var paths []string
+	// Two kinds of requests of an in-house protocol are sent to 7000 in two captures.
+	for i, command := range []string{`{"cmd":"get","key":"k%d"}`, `PING %d`} {
+		var packets []*pcapexport.Packet
+		for j := 0; j < 10; j++ {
+			request := []byte(fmt.Sprintf(command, j))
+			packets = append(packets,
+				&pcapexport.Packet{Timestamp: uint64(j), FromClient: true, Data: request, Length: len(request)},
+				&pcapexport.Packet{Timestamp: uint64(j), FromClient: false, Data: []byte("OK\r\n"), Length: 4})
+		}
+		var file bytes.Buffer
+		tuple := pcapexport.Tuple{Protocol: pcapexport.TCP, SrcIp: "10.0.0.1", SrcPort: uint32(40000 + i), DstIp: "10.0.0.2", DstPort: 7000}
+		assert.NoError(t, pcapexport.WritePcapng(&file, tuple, packets))
+		path := filepath.Join(dir, fmt.Sprintf("%d.pcapng", i))
+		assert.NoError(t, os.WriteFile(path, file.Bytes(), 0600))
+		paths = append(paths, path)
+	}
+
+	var stdout bytes.Buffer
+	assert.Equal(t, 0, payloadProfile(append([]string{"--ports", "7000"}, paths...), &stdout))
+	var reports []*payloadprofile.PortReport
+	assert.NoError(t, json.Unmarshal(stdout.Bytes(), &reports))
+	if assert.Len(t, reports, 1) {
+		assert.Equal(t, uint32(7000), reports[0].Port)
+		assert.Equal(t, 20, reports[0].Samples)
+		assert.Equal(t, payloadprofile.TextEncoding, reports[0].Encoding)
+		if assert.Len(t, reports[0].Clusters, 2) {
+			assert.Equal(t, `{"cmd":"get","key":"k`, reports[0].Clusters[0].CommonPrefix)
+			assert.Equal(t, 10, reports[0].Clusters[0].Count)
+			assert.Equal(t, "PING ", reports[0].Clusters[1].CommonPrefix)
+			assert.Equal(t, 10, reports[0].Clusters[1].Count)
+		}
+	}
+
+	assert.Equal(t, 1, payloadProfile([]string{filepath.Join(dir, "missing.pcapng")}, &stdout))
+	assert.Equal(t, 2, payloadProfile([]string{"--ports", "http", paths[0]}, &stdout))
+}
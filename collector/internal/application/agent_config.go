@@ -0,0 +1,60 @@
+package application
+
+import (
+	"fmt"
+
+	"github.com/mitchellh/mapstructure"
+	"go.uber.org/zap"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network"
+	"github.com/Kindling-project/kindling/collector/pkg/metadata/kubernetes"
+)
+
+// agentConfigSpec is the spec of the KindlingConfig objects, whose fields are named like the config file.
+// For now only the protocol settings of the network analyzer could be changed at runtime.
+type agentConfigSpec struct {
+	ProtocolParser  []string                 `mapstructure:"protocol_parser"`
+	ProtocolConfigs []network.ProtocolConfig `mapstructure:"protocol_config"`
+}
+
+// onAgentConfigsChanged reloads the settings with the KindlingConfig objects.
+func (a *Application) onAgentConfigsChanged(configs []*kubernetes.AgentConfig) {
+	a.agentConfigsMutex.Lock()
+	a.agentConfigs = configs
+	a.agentConfigsMutex.Unlock()
+	logger := a.telemetry.GetGlobalTelemetryTools().Logger
+	if err := a.Reload(); err != nil {
+		logger.Warn("Failed to apply the KindlingConfig objects", zap.Error(err))
+		return
+	}
+	logger.Info("The KindlingConfig objects are applied", zap.Int("objects", len(configs)))
+}
+
+// applyAgentConfigs overrides the settings of the config file with the KindlingConfig objects in order.
+// The protocol_parser of the later object replaces the earlier one, and the entries of protocol_config
+// replace the ones with the same key.
+func applyAgentConfigs(cfg *network.Config, configs []*kubernetes.AgentConfig) error {
+	for _, config := range configs {
+		spec := &agentConfigSpec{}
+		if err := mapstructure.Decode(config.Spec, spec); err != nil {
+			return fmt.Errorf("invalid KindlingConfig %s/%s: %w", config.Namespace, config.Name, err)
+		}
+		if len(spec.ProtocolParser) > 0 {
+			cfg.ProtocolParser = spec.ProtocolParser
+		}
+		for _, protocolConfig := range spec.ProtocolConfigs {
+			replaced := false
+			for i := range cfg.ProtocolConfigs {
+				if cfg.ProtocolConfigs[i].Key == protocolConfig.Key {
+					cfg.ProtocolConfigs[i] = protocolConfig
+					replaced = true
+					break
+				}
+			}
+			if !replaced {
+				cfg.ProtocolConfigs = append(cfg.ProtocolConfigs, protocolConfig)
+			}
+		}
+	}
+	return nil
+}
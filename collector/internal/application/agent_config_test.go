@@ -0,0 +1,55 @@
+package application
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network"
+	"github.com/Kindling-project/kindling/collector/pkg/metadata/kubernetes"
+)
+
+func TestApplyAgentConfigs(t *testing.T) {
+	cfg := &network.Config{
+		ProtocolParser: []string{"http", "mysql"},
+		ProtocolConfigs: []network.ProtocolConfig{
+			{Key: "http", Ports: []uint32{80}, PayloadLength: 200},
+			{Key: "mysql", Ports: []uint32{3306}, Threshold: 100},
+		},
+	}
+	configs := []*kubernetes.AgentConfig{
+		{
+			Namespace: "order",
+			Name:      "mysql",
+			Spec: map[string]interface{}{
+				"protocol_config": []interface{}{
+					map[string]interface{}{"key": "mysql", "ports": []interface{}{int64(3306), int64(3307)}, "slow_threshold": int64(300)},
+				},
+			},
+		},
+		{
+			Namespace: "payment",
+			Name:      "redis",
+			Spec: map[string]interface{}{
+				"protocol_parser": []interface{}{"http", "mysql", "redis"},
+				"protocol_config": []interface{}{
+					map[string]interface{}{"key": "redis", "ports": []interface{}{int64(6380)}},
+				},
+			},
+		},
+	}
+	assert.NoError(t, applyAgentConfigs(cfg, configs))
+	assert.Equal(t, []string{"http", "mysql", "redis"}, cfg.ProtocolParser)
+	assert.Equal(t, []network.ProtocolConfig{
+		{Key: "http", Ports: []uint32{80}, PayloadLength: 200},
+		{Key: "mysql", Ports: []uint32{3306, 3307}, Threshold: 300},
+		{Key: "redis", Ports: []uint32{6380}},
+	}, cfg.ProtocolConfigs)
+
+	invalid := []*kubernetes.AgentConfig{{
+		Namespace: "order",
+		Name:      "invalid",
+		Spec:      map[string]interface{}{"protocol_parser": "http"},
+	}}
+	assert.Error(t, applyAgentConfigs(cfg, invalid))
+}
@@ -1,29 +1,47 @@
 package application
 
 import (
+	"context"
 	"flag"
 	"fmt"
+	"runtime"
+	"sync"
 
 	"github.com/spf13/viper"
+	"go.opentelemetry.io/otel/sdk/resource"
 	"go.uber.org/multierr"
 
 	"github.com/Kindling-project/kindling/collector/pkg/component"
 	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer"
 	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/cpuanalyzer"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/k8seventanalyzer"
 	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/k8sinfoanalyzer"
 	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network"
 	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/noopanalyzer"
 	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/tcpconnectanalyzer"
 	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/tcpmetricanalyzer"
 	"github.com/Kindling-project/kindling/collector/pkg/component/consumer"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/exporter"
 	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/exporter/cameraexporter"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/exporter/forwardexporter"
 	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/exporter/logexporter"
 	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/exporter/otelexporter"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/exporter/pluginexporter"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/exporter/tools/resourcedetection"
 	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/processor/aggregateprocessor"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/processor/alertprocessor"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/processor/downsampleprocessor"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/processor/erroreventprocessor"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/processor/flowlogprocessor"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/processor/hubbleprocessor"
 	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/processor/k8sprocessor"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/processor/slowqueryprocessor"
 	"github.com/Kindling-project/kindling/collector/pkg/component/controller"
 	"github.com/Kindling-project/kindling/collector/pkg/component/receiver"
 	"github.com/Kindling-project/kindling/collector/pkg/component/receiver/cgoreceiver"
+	"github.com/Kindling-project/kindling/collector/pkg/component/receiver/etwreceiver"
+	"github.com/Kindling-project/kindling/collector/pkg/component/receiver/grpcreceiver"
+	"github.com/Kindling-project/kindling/collector/pkg/metadata/kubernetes"
 )
 
 type Application struct {
@@ -33,6 +51,15 @@ type Application struct {
 	telemetry         *component.TelemetryManager
 	receiver          receiver.Receiver
 	analyzerManager   *analyzer.Manager
+	configPath        string
+	networkAnalyzer   *network.NetworkAnalyzer
+	// resourceDetectionConfig is the config of the detectors, which are run once before the exporters are
+	// created. The detected resource is exported with the data of all the exporters.
+	resourceDetectionConfig *resourcedetection.Config
+	resource                *resource.Resource
+	// agentConfigs are the KindlingConfig objects overriding the settings of the config file.
+	agentConfigsMutex sync.Mutex
+	agentConfigs      []*kubernetes.AgentConfig
 }
 
 func New() (*Application, error) {
@@ -46,6 +73,7 @@ func New() (*Application, error) {
 	// Initialize flags
 	configPath := flag.String("config", "kindling-collector-config.yml", "Configuration file")
 	flag.Parse()
+	app.configPath = *configPath
 	err := app.readInConfig(*configPath)
 	if err != nil {
 		return nil, fmt.Errorf("fail to read configuration: %w", err)
@@ -58,13 +86,37 @@ func New() (*Application, error) {
 	return app, nil
 }
 
+// NewAggregator creates the application receiving the records forwarded by the node agents through
+// the gRPC receiver. The records are processed and exported by the same processors and exporters as
+// the node agents with the configuration file.
+func NewAggregator(configPath string) (*Application, error) {
+	app := &Application{
+		viper:             viper.New(),
+		componentsFactory: NewComponentsFactory(),
+		telemetry:         component.NewTelemetryManager(),
+		controllerFactory: &controller.ControllerFactory{},
+		configPath:        configPath,
+	}
+	app.registerFactory()
+	if err := app.readInConfig(configPath); err != nil {
+		return nil, fmt.Errorf("fail to read configuration: %w", err)
+	}
+	if err := app.buildAggregatorPipeline(); err != nil {
+		return nil, fmt.Errorf("failed to build pipeline: %w", err)
+	}
+	return app, nil
+}
+
 func (a *Application) Run() error {
-	err := a.analyzerManager.StartAll(a.telemetry.GetGlobalTelemetryTools().Logger)
-	if err != nil {
-		return fmt.Errorf("failed to start application: %v", err)
+	// The aggregator has no analyzers.
+	if a.analyzerManager != nil {
+		err := a.analyzerManager.StartAll(a.telemetry.GetGlobalTelemetryTools().Logger)
+		if err != nil {
+			return fmt.Errorf("failed to start application: %v", err)
+		}
 	}
 	// Wait until the receiver shutdowns
-	err = a.receiver.Start()
+	err := a.receiver.Start()
 	if err != nil {
 		return fmt.Errorf("failed to start application: %v", err)
 	}
@@ -72,11 +124,41 @@ func (a *Application) Run() error {
 }
 
 func (a *Application) Shutdown() error {
+	if a.analyzerManager == nil {
+		return a.receiver.Shutdown()
+	}
 	return multierr.Combine(a.receiver.Shutdown(), a.analyzerManager.ShutdownAll(a.telemetry.GetGlobalTelemetryTools().Logger))
 }
 
+// Reload reads the configuration file again and applies the settings that could be changed at runtime,
+// which are overridden by the KindlingConfig objects if they are watched. For now only the protocol
+// settings of the network analyzer are supported.
+func (a *Application) Reload() error {
+	if a.networkAnalyzer == nil {
+		return fmt.Errorf("no network analyzer is running")
+	}
+	v := viper.New()
+	v.SetConfigFile(a.configPath)
+	if err := v.ReadInConfig(); err != nil {
+		return fmt.Errorf("error happened while reading config file: %w", err)
+	}
+	networkConfig := network.NewDefaultConfig()
+	if err := v.UnmarshalKey(AnalyzersKey+"."+network.Network.String(), networkConfig, mapStructureDecoderConfigFunc); err != nil {
+		return fmt.Errorf("error happened while constructing config: %w", err)
+	}
+	a.agentConfigsMutex.Lock()
+	agentConfigs := a.agentConfigs
+	a.agentConfigsMutex.Unlock()
+	if err := applyAgentConfigs(networkConfig, agentConfigs); err != nil {
+		return err
+	}
+	a.networkAnalyzer.ReloadProtocols(networkConfig)
+	return nil
+}
+
 func (a *Application) registerFactory() {
 	a.componentsFactory.RegisterReceiver(cgoreceiver.Cgo, cgoreceiver.NewCgoReceiver, cgoreceiver.NewDefaultConfig())
+	a.componentsFactory.RegisterReceiver(etwreceiver.Type, etwreceiver.NewEtwReceiver, etwreceiver.NewDefaultConfig())
 	a.componentsFactory.RegisterAnalyzer(network.Network.String(), network.NewNetworkAnalyzer, network.NewDefaultConfig())
 	a.componentsFactory.RegisterAnalyzer(cpuanalyzer.CpuProfile.String(), cpuanalyzer.NewCpuAnalyzer, cpuanalyzer.NewDefaultConfig())
 	a.componentsFactory.RegisterProcessor(k8sprocessor.K8sMetadata, k8sprocessor.NewKubernetesProcessor, &k8sprocessor.DefaultConfig)
@@ -85,9 +167,18 @@ func (a *Application) registerFactory() {
 	a.componentsFactory.RegisterExporter(logexporter.Type, logexporter.New, &logexporter.Config{})
 	a.componentsFactory.RegisterAnalyzer(noopanalyzer.Type.String(), noopanalyzer.New, &noopanalyzer.Config{})
 	a.componentsFactory.RegisterAnalyzer(k8sinfoanalyzer.Type.String(), k8sinfoanalyzer.New, k8sinfoanalyzer.NewDefaultConfig())
+	a.componentsFactory.RegisterAnalyzer(k8seventanalyzer.Type.String(), k8seventanalyzer.New, k8seventanalyzer.NewDefaultConfig())
 	a.componentsFactory.RegisterProcessor(aggregateprocessor.Type, aggregateprocessor.New, aggregateprocessor.NewDefaultConfig())
+	a.componentsFactory.RegisterProcessor(flowlogprocessor.Type, flowlogprocessor.New, flowlogprocessor.NewDefaultConfig())
+	a.componentsFactory.RegisterProcessor(hubbleprocessor.Type, hubbleprocessor.New, hubbleprocessor.NewDefaultConfig())
+	a.componentsFactory.RegisterProcessor(erroreventprocessor.Type, erroreventprocessor.New, erroreventprocessor.NewDefaultConfig())
+	a.componentsFactory.RegisterProcessor(slowqueryprocessor.Type, slowqueryprocessor.New, slowqueryprocessor.NewDefaultConfig())
+	a.componentsFactory.RegisterProcessor(alertprocessor.Type, alertprocessor.New, alertprocessor.NewDefaultConfig())
+	a.componentsFactory.RegisterProcessor(downsampleprocessor.Type, downsampleprocessor.New, downsampleprocessor.NewDefaultConfig())
 	a.componentsFactory.RegisterAnalyzer(tcpconnectanalyzer.Type.String(), tcpconnectanalyzer.New, tcpconnectanalyzer.NewDefaultConfig())
 	a.componentsFactory.RegisterExporter(cameraexporter.Type, cameraexporter.New, cameraexporter.NewDefaultConfig())
+	a.componentsFactory.RegisterExporter(forwardexporter.Type, forwardexporter.New, forwardexporter.NewDefaultConfig())
+	a.componentsFactory.RegisterExporter(pluginexporter.Type, pluginexporter.New, pluginexporter.NewDefaultConfig())
 }
 
 func (a *Application) readInConfig(path string) error {
@@ -102,42 +193,57 @@ func (a *Application) readInConfig(path string) error {
 	if err != nil {
 		return fmt.Errorf("error happened while constructing config: %w", err)
 	}
+	a.resourceDetectionConfig = resourcedetection.NewDefaultConfig()
+	if err := a.viper.UnmarshalKey(ResourceDetectionKey, a.resourceDetectionConfig, mapStructureDecoderConfigFunc); err != nil {
+		return fmt.Errorf("error happened while constructing config: %w", err)
+	}
 	return nil
 }
 
+// detectResource runs the detectors once, so all the exporters are created with the same resource.
+func (a *Application) detectResource() {
+	a.resource = resourcedetection.Detect(context.Background(), a.resourceDetectionConfig, a.telemetry.GetGlobalTelemetryTools().Logger)
+}
+
+// newExporter creates the exporter of the type with the detected resource.
+func (a *Application) newExporter(exporterType string) exporter.Exporter {
+	return a.componentsFactory.Exporters[exporterType].NewExporter(a.telemetry.GetTelemetryTools(exporterType), a.resource)
+}
+
 // buildPipeline builds a event processing pipeline based on hard-code.
 func (a *Application) buildPipeline() error {
 	// TODO: Build pipeline via configuration to implement dependency injection
 	// Initialize exporters
-	otelExporterFactory := a.componentsFactory.Exporters[otelexporter.Otel]
-	otelExporter := otelExporterFactory.NewFunc(otelExporterFactory.Config, a.telemetry.GetTelemetryTools(otelexporter.Otel))
-	cameraExporterFactory := a.componentsFactory.Exporters[cameraexporter.Type]
-	cameraExporter := cameraExporterFactory.NewFunc(cameraExporterFactory.Config, a.telemetry.GetTelemetryTools(cameraexporter.Type))
-	// Initialize all processors
-	// 1. DataGroup Aggregator
-	aggregateProcessorFactory := a.componentsFactory.Processors[aggregateprocessor.Type]
-	aggregateProcessor := aggregateProcessorFactory.NewFunc(aggregateProcessorFactory.Config, a.telemetry.GetTelemetryTools(aggregateprocessor.Type), otelExporter)
-	// 2. Kubernetes metadata processor
-	k8sProcessorFactory := a.componentsFactory.Processors[k8sprocessor.K8sMetadata]
-	k8sMetadataProcessor := k8sProcessorFactory.NewFunc(k8sProcessorFactory.Config, a.telemetry.GetTelemetryTools(k8sprocessor.K8sMetadata), aggregateProcessor)
+	a.detectResource()
+	otelExporter := a.newExporter(otelexporter.Otel)
+	cameraExporter := a.newExporter(cameraexporter.Type)
+	k8sMetadataProcessor := a.buildRecordPipeline(otelExporter)
+	// The records of the network analyzers are processed by the aggregator if they are forwarded.
+	var recordConsumer consumer.Consumer = k8sMetadataProcessor
+	forwarded := a.componentsFactory.Exporters[forwardexporter.Type].Config.(*forwardexporter.Config).Enable
+	if forwarded {
+		recordConsumer = a.newExporter(forwardexporter.Type)
+	}
 	// Initialize all analyzers
 	// 1. Common network request analyzer
 	networkAnalyzerFactory := a.componentsFactory.Analyzers[network.Network.String()]
 	// Now NetworkAnalyzer must be initialized before any other analyzers, because it will
 	// use its configuration to initialize the conntracker module which is also used by others.
-	networkAnalyzer := networkAnalyzerFactory.NewFunc(networkAnalyzerFactory.Config, a.telemetry.GetTelemetryTools(network.Network.String()), []consumer.Consumer{k8sMetadataProcessor})
+	networkAnalyzer := networkAnalyzerFactory.NewFunc(networkAnalyzerFactory.Config, a.telemetry.GetTelemetryTools(network.Network.String()), []consumer.Consumer{recordConsumer})
 	// 2. Layer 4 TCP events analyzer
 	tcpAnalyzerFactory := a.componentsFactory.Analyzers[tcpmetricanalyzer.TcpMetric.String()]
-	tcpAnalyzer := tcpAnalyzerFactory.NewFunc(tcpAnalyzerFactory.Config, a.telemetry.GetTelemetryTools(tcpmetricanalyzer.TcpMetric.String()), []consumer.Consumer{k8sMetadataProcessor})
+	tcpAnalyzer := tcpAnalyzerFactory.NewFunc(tcpAnalyzerFactory.Config, a.telemetry.GetTelemetryTools(tcpmetricanalyzer.TcpMetric.String()), []consumer.Consumer{recordConsumer})
 	tcpConnectAnalyzerFactory := a.componentsFactory.Analyzers[tcpconnectanalyzer.Type.String()]
-	tcpConnectAnalyzer := tcpConnectAnalyzerFactory.NewFunc(tcpConnectAnalyzerFactory.Config, a.telemetry.GetTelemetryTools(tcpconnectanalyzer.Type.String()), []consumer.Consumer{k8sMetadataProcessor})
+	tcpConnectAnalyzer := tcpConnectAnalyzerFactory.NewFunc(tcpConnectAnalyzerFactory.Config, a.telemetry.GetTelemetryTools(tcpconnectanalyzer.Type.String()), []consumer.Consumer{recordConsumer})
 
 	cpuAnalyzerFactory := a.componentsFactory.Analyzers[cpuanalyzer.CpuProfile.String()]
 	cpuAnalyzer := cpuAnalyzerFactory.NewFunc(cpuAnalyzerFactory.Config, a.telemetry.GetTelemetryTools(cpuanalyzer.CpuProfile.String()), []consumer.Consumer{cameraExporter})
 	k8sInfoAnalyzerFactory := a.componentsFactory.Analyzers[k8sinfoanalyzer.Type.String()]
 	k8sInfoAnalyzer := k8sInfoAnalyzerFactory.NewFunc(k8sInfoAnalyzerFactory.Config, a.telemetry.GetTelemetryTools(k8sinfoanalyzer.Type.String()), []consumer.Consumer{otelExporter})
+	k8sEventAnalyzerFactory := a.componentsFactory.Analyzers[k8seventanalyzer.Type.String()]
+	k8sEventAnalyzer := k8sEventAnalyzerFactory.NewFunc(k8sEventAnalyzerFactory.Config, a.telemetry.GetTelemetryTools(k8seventanalyzer.Type.String()), []consumer.Consumer{otelExporter})
 	// Initialize receiver packaged with multiple analyzers
-	analyzerManager, err := analyzer.NewManager(networkAnalyzer, tcpAnalyzer, tcpConnectAnalyzer, cpuAnalyzer, k8sInfoAnalyzer)
+	analyzerManager, err := analyzer.NewManager(networkAnalyzer, tcpAnalyzer, tcpConnectAnalyzer, cpuAnalyzer, k8sInfoAnalyzer, k8sEventAnalyzer)
 	if err != nil {
 		return fmt.Errorf("error happened while creating analyzer manager: %w", err)
 	}
@@ -146,11 +252,89 @@ func (a *Application) buildPipeline() error {
 	cgoReceiverFactory := a.componentsFactory.Receivers[cgoreceiver.Cgo]
 	cgoReceiver := cgoReceiverFactory.NewFunc(cgoReceiverFactory.Config, a.telemetry.GetTelemetryTools(cgoreceiver.Cgo), analyzerManager)
 	a.receiver = cgoReceiver
+	if runtime.GOOS == "windows" {
+		// The events are traced by ETW instead of the probe on Windows.
+		etwReceiverFactory := a.componentsFactory.Receivers[etwreceiver.Type]
+		a.receiver = etwReceiverFactory.NewFunc(etwReceiverFactory.Config, a.telemetry.GetTelemetryTools(etwreceiver.Type), analyzerManager)
+	}
 
 	a.controllerFactory.RegistModule("profile",
 		cpuAnalyzer.(*cpuanalyzer.CpuAnalyzer).ProfileModule,
 		cgoReceiver.(*cgoreceiver.CgoReceiver).ProfileModule,
 	)
+	a.networkAnalyzer = networkAnalyzer.(*network.NetworkAnalyzer)
+	// The normal requests are sampled by the analyzer with the rate of the aggregator, so the payloads of
+	// those sampled away are never built. The forwarded records are sampled by the remote aggregator, and
+	// the plugin exporter receives all the records with their payloads.
+	aggregateConfig := a.componentsFactory.Processors[aggregateprocessor.Type].Config.(*aggregateprocessor.Config)
+	if !forwarded && !a.componentsFactory.Exporters[pluginexporter.Type].Config.(*pluginexporter.Config).Enable &&
+		aggregateConfig.SamplingRate != nil {
+		a.networkAnalyzer.SetNormalSamplingRate(aggregateConfig.SamplingRate.NormalData)
+	}
+	if handler := a.networkAnalyzer.PayloadProfileHandler(); handler != nil {
+		a.controllerFactory.RegistHandler("/payloadprofile", handler)
+	}
+	if handler := a.networkAnalyzer.PcapExportHandler(); handler != nil {
+		a.controllerFactory.RegistHandler("/pcap", handler)
+	}
+	a.controllerFactory.RegistHandler("/connections", a.networkAnalyzer.ConnectionTableHandler())
+	// The objects are only notified if the watch of the KindlingConfig objects is enabled.
+	kubernetes.WatchAgentConfigs(a.onAgentConfigsChanged)
 
 	return nil
 }
+
+// buildRecordPipeline builds the processors of the records from the network analyzers, and returns
+// the first one.
+func (a *Application) buildRecordPipeline(otelExporter consumer.Consumer) consumer.Consumer {
+	// 1. Downsample processor, which merges the aggregated records into the coarser resolutions
+	downsampleProcessorFactory := a.componentsFactory.Processors[downsampleprocessor.Type]
+	downsampleProcessor := downsampleProcessorFactory.NewFunc(downsampleProcessorFactory.Config, a.telemetry.GetTelemetryTools(downsampleprocessor.Type), otelExporter)
+	// 2. Alert processor, which evaluates the alerting rules over the aggregated records
+	alertProcessorFactory := a.componentsFactory.Processors[alertprocessor.Type]
+	alertProcessor := alertProcessorFactory.NewFunc(alertProcessorFactory.Config, a.telemetry.GetTelemetryTools(alertprocessor.Type), downsampleProcessor)
+	// 3. DataGroup Aggregator
+	aggregateProcessorFactory := a.componentsFactory.Processors[aggregateprocessor.Type]
+	aggregateProcessor := aggregateProcessorFactory.NewFunc(aggregateProcessorFactory.Config, a.telemetry.GetTelemetryTools(aggregateprocessor.Type), alertProcessor)
+	// 4. Slow query processor, which logs the slow database queries and passes everything to the aggregator
+	slowQueryProcessorFactory := a.componentsFactory.Processors[slowqueryprocessor.Type]
+	slowQueryProcessor := slowQueryProcessorFactory.NewFunc(slowQueryProcessorFactory.Config, a.telemetry.GetTelemetryTools(slowqueryprocessor.Type), aggregateProcessor)
+	// 5. Error event processor, which exports the failed requests separately
+	errorEventProcessorFactory := a.componentsFactory.Processors[erroreventprocessor.Type]
+	errorEventProcessor := errorEventProcessorFactory.NewFunc(errorEventProcessorFactory.Config, a.telemetry.GetTelemetryTools(erroreventprocessor.Type), slowQueryProcessor)
+	// 6. Flow log processor, which needs the Kubernetes metadata
+	flowLogProcessorFactory := a.componentsFactory.Processors[flowlogprocessor.Type]
+	flowLogProcessor := flowLogProcessorFactory.NewFunc(flowLogProcessorFactory.Config, a.telemetry.GetTelemetryTools(flowlogprocessor.Type), errorEventProcessor)
+	// 7. Hubble processor, which serves the requests as the flows of Hubble and needs the Kubernetes metadata
+	hubbleProcessorFactory := a.componentsFactory.Processors[hubbleprocessor.Type]
+	hubbleProcessor := hubbleProcessorFactory.NewFunc(hubbleProcessorFactory.Config, a.telemetry.GetTelemetryTools(hubbleprocessor.Type), flowLogProcessor)
+	// 8. Plugin exporter, which streams the records with the Kubernetes metadata to the sidecar plugin
+	var metadataConsumer consumer.Consumer = hubbleProcessor
+	if a.componentsFactory.Exporters[pluginexporter.Type].Config.(*pluginexporter.Config).Enable {
+		pluginExporter := a.newExporter(pluginexporter.Type)
+		metadataConsumer = consumer.Fanout{pluginExporter, hubbleProcessor}
+	}
+	// 9. Kubernetes metadata processor
+	k8sProcessorFactory := a.componentsFactory.Processors[k8sprocessor.K8sMetadata]
+	return k8sProcessorFactory.NewFunc(k8sProcessorFactory.Config, a.telemetry.GetTelemetryTools(k8sprocessor.K8sMetadata), metadataConsumer)
+}
+
+// buildAggregatorPipeline builds the gRPC receiver passing the forwarded records to the processors.
+func (a *Application) buildAggregatorPipeline() error {
+	a.detectResource()
+	otelExporter := a.newExporter(otelexporter.Otel)
+	k8sMetadataProcessor := a.buildRecordPipeline(otelExporter)
+
+	// The receiver is not registered in the factory as it passes the records to a consumer instead of
+	// the analyzers, so its configuration is read here.
+	grpcReceiverConfig := grpcreceiver.NewDefaultConfig()
+	if err := a.viper.UnmarshalKey(ReceiversKey+"."+grpcreceiver.Type, grpcReceiverConfig, mapStructureDecoderConfigFunc); err != nil {
+		return fmt.Errorf("error happened while constructing config: %w", err)
+	}
+	grpcReceiver, err := grpcreceiver.New(grpcReceiverConfig, a.telemetry.GetTelemetryTools(grpcreceiver.Type), k8sMetadataProcessor)
+	if err != nil {
+		return fmt.Errorf("error happened while creating grpc receiver: %w", err)
+	}
+	a.receiver = grpcReceiver
+	return nil
+}
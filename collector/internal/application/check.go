@@ -0,0 +1,230 @@
+package application
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer"
+	"github.com/Kindling-project/kindling/collector/pkg/component/controller"
+	"github.com/Kindling-project/kindling/collector/pkg/component/receiver/cgoreceiver"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+)
+
+const (
+	checkHttpPath = "/kindling-check"
+	// checkDomain is answered by the DNS server of the check with 127.0.0.1.
+	checkDomain = "kindling-check.local"
+	// checkInterval is the interval between the requests sent until their records are received.
+	checkInterval = 2 * time.Second
+)
+
+// Check attaches the probe with the configuration file, sends HTTP requests and DNS queries through the
+// loopback to the test servers started in the process, and waits until their records are received from
+// the network analyzer. It returns nil only if the agent works on the node.
+// The comms suppressed by the receiver are not applied, otherwise the traffic of the collector itself is
+// not captured. The probe can't be attached if another collector on the node is running with it.
+func Check(configPath string, timeout time.Duration) error {
+	app := &Application{
+		viper:             viper.New(),
+		componentsFactory: NewComponentsFactory(),
+		telemetry:         component.NewTelemetryManager(),
+		controllerFactory: &controller.ControllerFactory{},
+	}
+	app.registerFactory()
+	if err := app.readInConfig(configPath); err != nil {
+		return fmt.Errorf("fail to read configuration: %w", err)
+	}
+
+	httpListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return fmt.Errorf("fail to start the HTTP test server: %w", err)
+	}
+	httpServer := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	})}
+	go func() {
+		_ = httpServer.Serve(httpListener)
+	}()
+	defer httpServer.Close()
+	dnsConn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		return fmt.Errorf("fail to start the DNS test server: %w", err)
+	}
+	go serveCheckDns(dnsConn)
+	defer dnsConn.Close()
+
+	exporter := newCheckExporter(map[string]uint32{
+		protocol.HTTP: uint32(httpListener.Addr().(*net.TCPAddr).Port),
+		protocol.DNS:  uint32(dnsConn.LocalAddr().(*net.UDPAddr).Port),
+	})
+	if err = app.buildCheckPipeline(exporter); err != nil {
+		return fmt.Errorf("failed to build pipeline: %w", err)
+	}
+	if err = app.Run(); err != nil {
+		return err
+	}
+	defer func() {
+		_ = app.Shutdown()
+	}()
+
+	httpClient := &http.Client{Timeout: checkInterval, Transport: &http.Transport{DisableKeepAlives: true}}
+	httpUrl := fmt.Sprintf("http://%s%s", httpListener.Addr().String(), checkHttpPath)
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+	deadline := time.After(timeout)
+	for {
+		if exporter.missing(protocol.HTTP) {
+			if resp, err := httpClient.Get(httpUrl); err == nil {
+				_ = resp.Body.Close()
+			}
+		}
+		if exporter.missing(protocol.DNS) {
+			_ = sendCheckDnsQuery(dnsConn.LocalAddr().String())
+		}
+		select {
+		case <-exporter.done:
+			return nil
+		case <-deadline:
+			return fmt.Errorf("no record of %s is received in %v, check the logs of the probe and the subscribed events",
+				strings.Join(exporter.missingProtocols(), " and "), timeout)
+		case <-ticker.C:
+		}
+	}
+}
+
+// buildCheckPipeline builds the receiver and the network analyzer exporting the records to the exporter.
+func (a *Application) buildCheckPipeline(exporter *checkExporter) error {
+	networkAnalyzerFactory := a.componentsFactory.Analyzers[network.Network.String()]
+	networkConfig := networkAnalyzerFactory.Config.(*network.Config)
+	// The test DNS server doesn't listen on the configured ports.
+	networkConfig.DetectUdpDns = true
+	// The records of HTTP are sent soon after the responses as the connections are not reused.
+	networkConfig.FdReuseTimeout = 1
+	networkAnalyzer := networkAnalyzerFactory.NewFunc(networkConfig, a.telemetry.GetTelemetryTools(network.Network.String()), []consumer.Consumer{exporter})
+	analyzerManager, err := analyzer.NewManager(networkAnalyzer)
+	if err != nil {
+		return fmt.Errorf("error happened while creating analyzer manager: %w", err)
+	}
+	a.analyzerManager = analyzerManager
+	a.networkAnalyzer = networkAnalyzer.(*network.NetworkAnalyzer)
+
+	cgoReceiverFactory := a.componentsFactory.Receivers[cgoreceiver.Cgo]
+	receiverConfig := *cgoReceiverFactory.Config.(*cgoreceiver.Config)
+	receiverConfig.ProcessFilterInfo.Comms = nil
+	a.receiver = cgoReceiverFactory.NewFunc(&receiverConfig, a.telemetry.GetTelemetryTools(cgoreceiver.Cgo), analyzerManager)
+	return nil
+}
+
+// checkExporter receives the records of the check, and closes done once the records of all the
+// protocols are received.
+type checkExporter struct {
+	// ports are the ports of the test servers by the protocols missing records.
+	ports map[string]uint32
+	mutex sync.Mutex
+	done  chan struct{}
+}
+
+func newCheckExporter(ports map[string]uint32) *checkExporter {
+	return &checkExporter{
+		ports: ports,
+		done:  make(chan struct{}),
+	}
+}
+
+func (e *checkExporter) Consume(dataGroup *model.DataGroup) error {
+	if dataGroup.Name != constnames.NetRequestMetricGroupName {
+		return nil
+	}
+	protocolName := dataGroup.Labels.GetStringValue(constlabels.Protocol)
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	port, ok := e.ports[protocolName]
+	if !ok || uint32(dataGroup.Labels.GetIntValue(constlabels.DstPort)) != port {
+		return nil
+	}
+	delete(e.ports, protocolName)
+	if len(e.ports) == 0 {
+		close(e.done)
+	}
+	return nil
+}
+
+func (e *checkExporter) missing(protocolName string) bool {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	_, ok := e.ports[protocolName]
+	return ok
+}
+
+func (e *checkExporter) missingProtocols() []string {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	protocols := make([]string, 0, len(e.ports))
+	for protocolName := range e.ports {
+		protocols = append(protocols, protocolName)
+	}
+	return protocols
+}
+
+// newCheckDnsQuery returns the query of the A record of checkDomain.
+func newCheckDnsQuery(id uint16) []byte {
+	// The header with the flag RD and a question
+	query := binary.BigEndian.AppendUint16(nil, id)
+	query = append(query, 0x01, 0x00, 0, 1, 0, 0, 0, 0, 0, 0)
+	for _, label := range strings.Split(checkDomain, ".") {
+		query = append(query, byte(len(label)))
+		query = append(query, label...)
+	}
+	// The root, type A and class IN
+	return append(query, 0, 0, 1, 0, 1)
+}
+
+// serveCheckDns answers the queries with 127.0.0.1 until the connection is closed.
+func serveCheckDns(conn net.PacketConn) {
+	buf := make([]byte, 512)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		if n < 12 {
+			continue
+		}
+		answer := append([]byte{}, buf[:n]...)
+		// The flags QR, RD and RA, and an answer
+		answer[2], answer[3] = 0x81, 0x80
+		answer[6], answer[7] = 0, 1
+		// The name pointing to the question, type A, class IN, TTL 0 and the address
+		answer = append(answer, 0xc0, 0x0c, 0, 1, 0, 1, 0, 0, 0, 0, 0, 4, 127, 0, 0, 1)
+		_, _ = conn.WriteTo(answer, addr)
+	}
+}
+
+func sendCheckDnsQuery(address string) error {
+	conn, err := net.Dial("udp", address)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if err = conn.SetDeadline(time.Now().Add(checkInterval)); err != nil {
+		return err
+	}
+	if _, err = conn.Write(newCheckDnsQuery(uint16(time.Now().UnixNano()))); err != nil {
+		return err
+	}
+	_, err = conn.Read(make([]byte, 512))
+	return err
+}
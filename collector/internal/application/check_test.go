@@ -0,0 +1,63 @@
+package application
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/dns"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+)
+
+func TestCheckExporter(t *testing.T) {
+	exporter := newCheckExporter(map[string]uint32{protocol.HTTP: 8080, protocol.DNS: 5353})
+	newRecord := func(protocolName string, port int64) *model.DataGroup {
+		labels := model.NewAttributeMap()
+		labels.AddStringValue(constlabels.Protocol, protocolName)
+		labels.AddIntValue(constlabels.DstPort, port)
+		return model.NewDataGroup(constnames.NetRequestMetricGroupName, labels, 0)
+	}
+
+	// The records of the other traffic are ignored.
+	_ = exporter.Consume(newRecord(protocol.HTTP, 80))
+	assert.True(t, exporter.missing(protocol.HTTP))
+	_ = exporter.Consume(newRecord(protocol.HTTP, 8080))
+	assert.False(t, exporter.missing(protocol.HTTP))
+	assert.Equal(t, []string{protocol.DNS}, exporter.missingProtocols())
+
+	_ = exporter.Consume(newRecord(protocol.DNS, 5353))
+	select {
+	case <-exporter.done:
+	default:
+		t.Fatal("the check is not done")
+	}
+}
+
+func TestCheckDns(t *testing.T) {
+	assert.True(t, dns.IsDnsMessage(newCheckDnsQuery(1), false))
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer conn.Close()
+	go serveCheckDns(conn)
+
+	client, err := net.Dial("udp", conn.LocalAddr().String())
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer client.Close()
+	_ = client.SetDeadline(time.Now().Add(time.Second))
+	_, _ = client.Write(newCheckDnsQuery(1))
+	answer := make([]byte, 512)
+	n, err := client.Read(answer)
+	if assert.NoError(t, err) {
+		assert.True(t, dns.IsDnsMessage(answer[:n], true))
+	}
+}
@@ -3,11 +3,13 @@ package application
 import (
 	"github.com/mitchellh/mapstructure"
 	"github.com/spf13/viper"
+	"go.opentelemetry.io/otel/sdk/resource"
 
 	"github.com/Kindling-project/kindling/collector/pkg/component"
 	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer"
 	"github.com/Kindling-project/kindling/collector/pkg/component/consumer"
 	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/exporter"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/exporter/tools/resourcedetection"
 	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/processor"
 	"github.com/Kindling-project/kindling/collector/pkg/component/receiver"
 )
@@ -17,6 +19,8 @@ const (
 	AnalyzersKey  = "analyzers"
 	ProcessorsKey = "processors"
 	ExportersKey  = "exporters"
+	// ResourceDetectionKey is the config of the resource attributes added to the data of all the exporters.
+	ResourceDetectionKey = "resource_detection"
 )
 
 var ComponentsKeyMap = []string{ReceiversKey, AnalyzersKey, ProcessorsKey, ExportersKey}
@@ -53,6 +57,16 @@ type ExporterFactory struct {
 	Config  interface{}
 }
 
+// NewExporter creates the exporter exporting the detected resource attributes with the data. The exporters
+// with a resource of their own take the attributes into it, and the others get them as the labels.
+func (f ExporterFactory) NewExporter(telemetry *component.TelemetryTools, res *resource.Resource) exporter.Exporter {
+	if cfg, ok := f.Config.(resourcedetection.ResourceConfig); ok {
+		cfg.SetResource(res)
+		return f.NewFunc(f.Config, telemetry)
+	}
+	return resourcedetection.NewExporter(res, f.NewFunc(f.Config, telemetry))
+}
+
 func NewComponentsFactory() *ComponentsFactory {
 	return &ComponentsFactory{
 		Receivers:  make(map[string]ReceiverFactory),
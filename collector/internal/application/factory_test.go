@@ -5,11 +5,18 @@ import (
 
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
 
+	"github.com/Kindling-project/kindling/collector/pkg/component"
 	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/payloadprofile"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/pcapexport"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/exporter"
 	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/processor/k8sprocessor"
 	"github.com/Kindling-project/kindling/collector/pkg/component/receiver/cgoreceiver"
 	"github.com/Kindling-project/kindling/collector/pkg/metadata/kubernetes"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
 )
 
 func TestConstructConfig(t *testing.T) {
@@ -42,6 +49,18 @@ func TestConstructConfig(t *testing.T) {
 			EnableTrace: false,
 			Endpoint:    "",
 		},
+		LeaderElection: &kubernetes.LeaderElectionConfig{
+			Enable:         false,
+			LeaseNamespace: "kindling",
+			LeaseName:      "kindling-agent-leader",
+			LeaseDuration:  15,
+			RenewDeadline:  10,
+			RetryPeriod:    2,
+		},
+		AgentConfigCrd: &kubernetes.AgentConfigCrdConfig{
+			Enable:    false,
+			Namespace: "",
+		},
 	}
 	assert.Equal(t, expectedCfg, k8sCfg)
 
@@ -57,6 +76,7 @@ func TestConstructConfig(t *testing.T) {
 		EnableConntrack:       true,
 		ConntrackMaxStateSize: 131072,
 		ConntrackRateLimit:    500,
+		DnatChainDepth:        1,
 		ProcRoot:              "/proc",
 		// Case: This slice is from the default config. The config file doesn't have this field.
 		ProtocolParser: []string{"http", "mysql", "dns", "redis", "kafka", "dubbo"},
@@ -72,6 +92,81 @@ func TestConstructConfig(t *testing.T) {
 		UrlClusteringMethod: "blank",
 
 		IgnoreDnsRcode3Error: true,
+		// Case: These structs are from the default config. The config file doesn't have these fields.
+		SyscallBreakdown: &network.SyscallBreakdownConfig{
+			Enable:             false,
+			MaxEventsPerThread: 1000,
+		},
+		DnsDedup: &network.DnsDedupConfig{
+			Enable: false,
+			Window: 10000,
+		},
+		NodeLocalDns: &network.NodeLocalDnsConfig{
+			Enable:     false,
+			CacheIps:   []string{"169.254.20.10"},
+			CacheComms: []string{"node-cache"},
+			Window:     2000,
+		},
+		DnsAmplification: &network.DnsAmplificationConfig{
+			Enable:        false,
+			SearchDomains: []string{"*.svc.cluster.local", "svc.cluster.local", "cluster.local"},
+			Window:        2000,
+		},
+		PayloadProfile: &payloadprofile.Config{
+			Enable:              false,
+			PrefixLength:        32,
+			SimilarityThreshold: 0.8,
+			MaxPorts:            100,
+			MaxClustersPerPort:  20,
+		},
+		PcapExport: &pcapexport.Config{
+			Enable:               false,
+			MaxCaptures:          10,
+			MaxPacketsPerCapture: 10000,
+		},
+		HealthCheck: &network.HealthCheckConfig{
+			Enable:     false,
+			Action:     "label",
+			Urls:       []string{"/healthz", "/livez", "/readyz", "/health", "/actuator/health"},
+			UserAgents: []string{"kube-probe/", "ELB-HealthChecker/", "GoogleHC/"},
+		},
+		DnsAttribution: &network.DnsAttributionConfig{
+			Enable: false,
+			Window: 1000,
+		},
+		FanOut: &network.FanOutConfig{
+			Enable:      false,
+			MatchThread: false,
+			Window:      10000,
+		},
+		ConsumerQueue: &network.ConsumerQueueConfig{
+			Enable: false,
+			Size:   10000,
+		},
+		PayloadMask: &network.PayloadMaskConfig{
+			Enable:        false,
+			HttpHeaders:   []string{"Authorization", "Proxy-Authorization", "Cookie"},
+			MysqlLiterals: true,
+			RedisAuth:     true,
+			LdapBind:      true,
+		},
+		ParserTiering: &network.ParserTieringConfig{
+			Enable:            false,
+			ColdAfter:         600,
+			ColdCheckInterval: 100,
+		},
+		ProtocolInfo: &network.ProtocolInfoConfig{
+			Enable:     false,
+			Interval:   60,
+			Expiration: 3600,
+		},
+		ThreadPool: &network.ThreadPoolConfig{
+			Enable:   false,
+			Interval: 30,
+		},
+		WorkloadOverride: &network.WorkloadOverrideConfig{
+			Enable: false,
+		},
 	}
 	assert.Equal(t, expectedNetworkConfig, networkConfig)
 
@@ -146,3 +241,43 @@ func TestConstructConfig(t *testing.T) {
 	}
 	assert.Equal(t, expectedCgoreceiverConfig, cgoreceiverConfig)
 }
+
+type resourceConfig struct {
+	resource *resource.Resource
+}
+
+func (c *resourceConfig) SetResource(res *resource.Resource) {
+	c.resource = res
+}
+
+type labelsExporter struct {
+	labels *model.AttributeMap
+}
+
+func (e *labelsExporter) Consume(dataGroup *model.DataGroup) error {
+	e.labels = dataGroup.Labels
+	return nil
+}
+
+func TestExporterFactory_NewExporter(t *testing.T) {
+	res := resource.NewSchemaless(attribute.String("cloud.region", "us-east-1"))
+	factory := NewComponentsFactory()
+	factory.RegisterExporter("resource", func(cfg interface{}, telemetry *component.TelemetryTools) exporter.Exporter {
+		return &labelsExporter{}
+	}, &resourceConfig{})
+
+	// The exporter with a resource of its own takes the attributes into it.
+	resourceExporter := factory.Exporters["resource"].NewExporter(component.NewDefaultTelemetryTools(), res)
+	assert.Same(t, res, factory.Exporters["resource"].Config.(*resourceConfig).resource)
+	assert.NoError(t, resourceExporter.Consume(model.NewDataGroup("request", model.NewAttributeMap(), 0)))
+	assert.False(t, resourceExporter.(*labelsExporter).labels.HasAttribute("cloud.region"))
+
+	// The others get the attributes as the labels.
+	next := &labelsExporter{}
+	factory.RegisterExporter("labels", func(cfg interface{}, telemetry *component.TelemetryTools) exporter.Exporter {
+		return next
+	}, &struct{}{})
+	labelExporter := factory.Exporters["labels"].NewExporter(component.NewDefaultTelemetryTools(), res)
+	assert.NoError(t, labelExporter.Consume(model.NewDataGroup("request", model.NewAttributeMap(), 0)))
+	assert.Equal(t, "us-east-1", next.labels.GetStringValue("cloud.region"))
+}
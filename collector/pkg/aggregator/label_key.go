@@ -50,11 +50,11 @@ func (s *LabelSelectors) AppendSelectors(selectors ...LabelSelector) {
 	s.selectors = append(s.selectors, selectors...)
 }
 
-const maxLabelKeySize = 37
+const maxLabelKeySize = 38
 
 type LabelKeys struct {
 	// LabelKeys will be used as key of map, so it is must be an array instead of a slice.
-	// Now 34 is enough for all cases. If there is more than 34 labels, must increase this value.
+	// Now 38 is enough for all cases. If there is more than 38 labels, must increase this value.
 	keys [maxLabelKeySize]LabelKey
 }
 
@@ -20,3 +20,10 @@ type Analyzer interface {
 	// ConsumableEvents returns the events' name that this analyzer can consume
 	ConsumableEvents() []string
 }
+
+// CaptureLengthNegotiator is implemented by the analyzers that could tell the length of the payloads
+// they need, so the snaplen in the kernel could be lowered.
+type CaptureLengthNegotiator interface {
+	// CaptureLength returns the length of the payloads needed, or 0 if the whole snaplen is needed.
+	CaptureLength() int
+}
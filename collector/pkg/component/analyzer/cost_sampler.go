@@ -0,0 +1,59 @@
+package analyzer
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultCostSampleRate measures one out of every 100 calls.
+const DefaultCostSampleRate = 100
+
+// CostSampler estimates the CPU time consumed by the components. Timing every call is too
+// expensive on the hot path, so only one out of every rate calls is measured and the elapsed
+// time is multiplied by the rate. The wall time of the sampled calls approximates the CPU time
+// because the measured code runs synchronously without blocking. It is safe for concurrent use.
+type CostSampler struct {
+	rate  uint64
+	count uint64
+	// costs maps the component names to the estimated nanoseconds of type *int64.
+	costs sync.Map
+}
+
+func NewCostSampler(rate int) *CostSampler {
+	if rate <= 0 {
+		rate = DefaultCostSampleRate
+	}
+	return &CostSampler{rate: uint64(rate)}
+}
+
+// Start returns the start time of the call if it is sampled, otherwise it returns the zero time.
+func (s *CostSampler) Start() time.Time {
+	if atomic.AddUint64(&s.count, 1)%s.rate != 0 {
+		return time.Time{}
+	}
+	return time.Now()
+}
+
+// Stop adds the estimated cost of the call to the component if the call is sampled.
+func (s *CostSampler) Stop(name string, start time.Time) {
+	if start.IsZero() {
+		return
+	}
+	cost := int64(time.Since(start)) * int64(s.rate)
+	value, ok := s.costs.Load(name)
+	if !ok {
+		value, _ = s.costs.LoadOrStore(name, new(int64))
+	}
+	atomic.AddInt64(value.(*int64), cost)
+}
+
+// Costs returns the estimated nanoseconds consumed by each component so far.
+func (s *CostSampler) Costs() map[string]int64 {
+	ret := make(map[string]int64)
+	s.costs.Range(func(k, v interface{}) bool {
+		ret[k.(string)] = atomic.LoadInt64(v.(*int64))
+		return true
+	})
+	return ret
+}
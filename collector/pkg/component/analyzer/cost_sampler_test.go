@@ -0,0 +1,27 @@
+package analyzer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCostSampler(t *testing.T) {
+	s := NewCostSampler(4)
+	sampled := 0
+	for i := 0; i < 8; i++ {
+		start := s.Start()
+		if !start.IsZero() {
+			sampled++
+			start = start.Add(-time.Millisecond)
+		}
+		s.Stop("networkanalyzer", start)
+	}
+	assert.Equal(t, 2, sampled)
+	// Each sampled call took at least 1ms and stands for 4 calls.
+	assert.GreaterOrEqual(t, s.Costs()["networkanalyzer"], int64(8*time.Millisecond))
+	assert.NotContains(t, s.Costs(), "tcpanalyzer")
+
+	assert.Equal(t, uint64(DefaultCostSampleRate), NewCostSampler(0).rate)
+}
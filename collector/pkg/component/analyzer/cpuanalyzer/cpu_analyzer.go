@@ -11,6 +11,7 @@ import (
 	"github.com/Kindling-project/kindling/collector/pkg/metadata/kubernetes"
 	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
 	"github.com/Kindling-project/kindling/collector/pkg/model/constvalues"
+	"github.com/Kindling-project/kindling/collector/pkg/model/timeunit"
 
 	"go.uber.org/atomic"
 	"go.uber.org/zap/zapcore"
@@ -262,8 +263,6 @@ func (ca *CpuAnalyzer) ConsumeCpuEvent(event *model.KindlingEvent) {
 	ca.PutEventToSegments(event.GetPid(), event.Ctx.ThreadInfo.GetTid(), event.Ctx.ThreadInfo.Comm, ev)
 }
 
-var nanoToSeconds uint64 = 1e9
-
 func (ca *CpuAnalyzer) PutEventToSegments(pid uint32, tid uint32, threadName string, event TimedEvent) {
 	ca.lock.Lock()
 	defer ca.lock.Unlock()
@@ -278,13 +277,13 @@ func (ca *CpuAnalyzer) PutEventToSegments(pid uint32, tid uint32, threadName str
 	timeSegments, exist := tidCpuEvents[tid]
 	maxSegmentSize := ca.cfg.SegmentSize
 	if exist {
-		endOffset := int(event.EndTimestamp()/nanoToSeconds - timeSegments.BaseTime)
+		endOffset := int(timeunit.Timestamp(event.EndTimestamp()).Seconds() - timeSegments.BaseTime)
 		if endOffset < 0 {
 			ca.telemetry.Logger.Debugf("EndOffset of the event is negative. EndTimestamp=%d, BaseTime=%d",
 				event.EndTimestamp(), timeSegments.BaseTime)
 			return
 		}
-		startOffset := int(event.StartTimestamp()/nanoToSeconds - timeSegments.BaseTime)
+		startOffset := int(timeunit.Timestamp(event.StartTimestamp()).Seconds() - timeSegments.BaseTime)
 		if startOffset < 0 {
 			startOffset = 0
 		}
@@ -295,14 +294,14 @@ func (ca *CpuAnalyzer) PutEventToSegments(pid uint32, tid uint32, threadName str
 			if startOffset*2 >= 3*maxSegmentSize {
 				// clear all elements
 				ca.telemetry.Logger.Debugf("pid=%d, tid=%d, comm=%s, reset BaseTime from %d to %d", pid, tid,
-					threadName, timeSegments.BaseTime, event.StartTimestamp()/nanoToSeconds)
+					threadName, timeSegments.BaseTime, timeunit.Timestamp(event.StartTimestamp()).Seconds())
 				timeSegments.Segments.Clear()
-				timeSegments.BaseTime = event.StartTimestamp() / nanoToSeconds
+				timeSegments.BaseTime = timeunit.Timestamp(event.StartTimestamp()).Seconds()
 				endOffset = endOffset - startOffset
 				startOffset = 0
 				for i := 0; i < maxSegmentSize; i++ {
-					segment := newSegment((timeSegments.BaseTime+uint64(i))*nanoToSeconds,
-						(timeSegments.BaseTime+uint64(i+1))*nanoToSeconds)
+					segment := newSegment(uint64(timeunit.Unix(timeSegments.BaseTime+uint64(i))),
+						uint64(timeunit.Unix(timeSegments.BaseTime+uint64(i+1))))
 					timeSegments.Segments.UpdateByIndex(i, segment)
 				}
 			} else {
@@ -320,8 +319,8 @@ func (ca *CpuAnalyzer) PutEventToSegments(pid uint32, tid uint32, threadName str
 					movedIndex := i + clearSize
 					val := timeSegments.Segments.GetByIndex(movedIndex)
 					timeSegments.Segments.UpdateByIndex(i, val)
-					segmentTmp := newSegment((timeSegments.BaseTime+uint64(movedIndex))*nanoToSeconds,
-						(timeSegments.BaseTime+uint64(movedIndex+1))*nanoToSeconds)
+					segmentTmp := newSegment(uint64(timeunit.Unix(timeSegments.BaseTime+uint64(movedIndex))),
+						uint64(timeunit.Unix(timeSegments.BaseTime+uint64(movedIndex+1))))
 					timeSegments.Segments.UpdateByIndex(movedIndex, segmentTmp)
 				}
 			}
@@ -341,16 +340,16 @@ func (ca *CpuAnalyzer) PutEventToSegments(pid uint32, tid uint32, threadName str
 			Pid:        pid,
 			Tid:        tid,
 			ThreadName: threadName,
-			BaseTime:   event.StartTimestamp() / nanoToSeconds,
+			BaseTime:   timeunit.Timestamp(event.StartTimestamp()).Seconds(),
 			Segments:   NewCircleQueue(maxSegmentSize),
 		}
 		for i := 0; i < maxSegmentSize; i++ {
-			segment := newSegment((newTimeSegments.BaseTime+uint64(i))*nanoToSeconds,
-				(newTimeSegments.BaseTime+uint64(i+1))*nanoToSeconds)
+			segment := newSegment(uint64(timeunit.Unix(newTimeSegments.BaseTime+uint64(i))),
+				uint64(timeunit.Unix(newTimeSegments.BaseTime+uint64(i+1))))
 			newTimeSegments.Segments.UpdateByIndex(i, segment)
 		}
 
-		endOffset := int(event.EndTimestamp()/nanoToSeconds - newTimeSegments.BaseTime)
+		endOffset := int(timeunit.Timestamp(event.EndTimestamp()).Seconds() - newTimeSegments.BaseTime)
 
 		for i := 0; i <= endOffset && i < maxSegmentSize; i++ {
 			val := newTimeSegments.Segments.GetByIndex(i)
@@ -9,6 +9,7 @@ import (
 	"github.com/Kindling-project/kindling/collector/pkg/model"
 	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
 	"github.com/Kindling-project/kindling/collector/pkg/model/constvalues"
+	"github.com/Kindling-project/kindling/collector/pkg/model/timeunit"
 )
 
 // Eager Initialization
@@ -174,8 +175,8 @@ func (ca *CpuAnalyzer) sendEvents(keyElements *model.AttributeMap, pid uint32, s
 			pid, startTime, endTime)
 		return
 	}
-	startTimeSecond := startTime / nanoToSeconds
-	endTimeSecond := endTime / nanoToSeconds
+	startTimeSecond := timeunit.Timestamp(startTime).Seconds()
+	endTimeSecond := timeunit.Timestamp(endTime).Seconds()
 
 	for _, timeSegments := range tidCpuEvents {
 		if endTimeSecond < timeSegments.BaseTime || startTimeSecond > timeSegments.BaseTime+uint64(maxSegmentSize) {
@@ -0,0 +1,18 @@
+package k8seventanalyzer
+
+import "github.com/Kindling-project/kindling/collector/pkg/metadata/kubernetes"
+
+type Config struct {
+	// Set "Enable" true to watch the container events from the API-server.
+	Enable        bool                `mapstructure:"enable"`
+	KubeAuthType  kubernetes.AuthType `mapstructure:"kube_auth_type"`
+	KubeConfigDir string              `mapstructure:"kube_config_dir"`
+}
+
+func NewDefaultConfig() *Config {
+	return &Config{
+		Enable:        false,
+		KubeAuthType:  kubernetes.AuthTypeServiceAccount,
+		KubeConfigDir: kubernetes.DefaultKubeConfigPath,
+	}
+}
@@ -0,0 +1,280 @@
+package k8seventanalyzer
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/informers"
+	k8s "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer"
+	"github.com/Kindling-project/kindling/collector/pkg/metadata/kubernetes"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+)
+
+const Type analyzer.Type = "k8seventanalyzer"
+
+const nodeNameEnv = "MY_NODE_NAME"
+
+const (
+	ContainerRestartEvent = "container_restart"
+	ImagePullEvent        = "image_pull"
+	LivenessFailureEvent  = "liveness_failure"
+)
+
+// K8sEventAnalyzer watches the pods and events on the current node, and sends the
+// container restarts, image pulls and liveness-probe failures as dataGroups.
+// If the leader election is enabled, only the leader watches the events and sends
+// the ones of all the nodes.
+type K8sEventAnalyzer struct {
+	cfg           *Config
+	nextConsumers []consumer.Consumer
+	telemetry     *component.TelemetryTools
+	nodeName      string
+	clientSet     k8s.Interface
+	// allNodes is true if the events of all the nodes are sent.
+	allNodes bool
+	stopCh   chan struct{}
+	// eventMutex guards the event watch, which is started and stopped with the leadership.
+	eventMutex sync.Mutex
+	startTime  time.Time
+	// eventStopCh is nil if the events are not watched.
+	eventStopCh chan struct{}
+}
+
+func New(cfg interface{}, telemetry *component.TelemetryTools, consumer []consumer.Consumer) analyzer.Analyzer {
+	config, ok := cfg.(*Config)
+	if !ok {
+		telemetry.Logger.Panic("Cannot convert k8seventanalyzer config")
+	}
+	return &K8sEventAnalyzer{
+		cfg:           config,
+		nextConsumers: consumer,
+		telemetry:     telemetry,
+	}
+}
+
+func (a *K8sEventAnalyzer) Start() error {
+	if !a.cfg.Enable {
+		return nil
+	}
+	clientSet, err := kubernetes.NewClientSet(a.cfg.KubeAuthType, a.cfg.KubeConfigDir)
+	if err != nil {
+		return fmt.Errorf("cannot connect to kubernetes: %w", err)
+	}
+	nodeName, ok := os.LookupEnv(nodeNameEnv)
+	if !ok {
+		a.telemetry.Logger.Warn("[MY_NODE_NAME] is not found in env variable, the events of all nodes will be sent")
+	}
+	a.nodeName = nodeName
+	a.clientSet = clientSet
+	a.allNodes = kubernetes.IsLeaderElectionEnabled()
+	a.stopCh = make(chan struct{})
+
+	factory := informers.NewSharedInformerFactoryWithOptions(clientSet, 0,
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			if a.nodeName != "" && options.FieldSelector == "" {
+				options.FieldSelector = fields.OneTermEqualSelector("spec.nodeName", a.nodeName).String()
+			}
+		}))
+	factory.Core().V1().Pods().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: a.onPodUpdate,
+	})
+	factory.Start(a.stopCh)
+	// Events can't be filtered by the node on the server side, so they are watched only by the leader.
+	kubernetes.WatchLeadership(a.startEventWatch, a.stopEventWatch)
+	return nil
+}
+
+func (a *K8sEventAnalyzer) startEventWatch() {
+	a.eventMutex.Lock()
+	defer a.eventMutex.Unlock()
+	if a.eventStopCh != nil {
+		return
+	}
+	select {
+	case <-a.stopCh:
+		return
+	default:
+	}
+	a.startTime = time.Now()
+	a.eventStopCh = make(chan struct{})
+	eventFactory := informers.NewSharedInformerFactoryWithOptions(a.clientSet, 0,
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.FieldSelector = fields.OneTermEqualSelector("involvedObject.kind", "Pod").String()
+		}))
+	eventFactory.Core().V1().Events().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: a.onEventAdd,
+		UpdateFunc: func(_, newObj interface{}) {
+			a.onEventAdd(newObj)
+		},
+	})
+	eventFactory.Start(a.eventStopCh)
+}
+
+func (a *K8sEventAnalyzer) stopEventWatch() {
+	a.eventMutex.Lock()
+	defer a.eventMutex.Unlock()
+	if a.eventStopCh != nil {
+		close(a.eventStopCh)
+		a.eventStopCh = nil
+	}
+}
+
+func (a *K8sEventAnalyzer) getStartTime() time.Time {
+	a.eventMutex.Lock()
+	defer a.eventMutex.Unlock()
+	return a.startTime
+}
+
+func (a *K8sEventAnalyzer) onPodUpdate(oldObj interface{}, newObj interface{}) {
+	oldPod, ok := oldObj.(*corev1.Pod)
+	if !ok {
+		return
+	}
+	newPod, ok := newObj.(*corev1.Pod)
+	if !ok {
+		return
+	}
+	for _, dataGroup := range containerRestartDataGroups(oldPod, newPod) {
+		a.sendToNextConsumer(dataGroup)
+	}
+}
+
+func (a *K8sEventAnalyzer) onEventAdd(obj interface{}) {
+	event, ok := obj.(*corev1.Event)
+	if !ok {
+		return
+	}
+	// The informer lists all the existing events when starting, which are ignored here.
+	if eventTime(event).Before(a.getStartTime()) {
+		return
+	}
+	if !a.allNodes && a.nodeName != "" && event.Source.Host != "" && event.Source.Host != a.nodeName {
+		return
+	}
+	if dataGroup := eventDataGroup(event); dataGroup != nil {
+		a.sendToNextConsumer(dataGroup)
+	}
+}
+
+func (a *K8sEventAnalyzer) sendToNextConsumer(dataGroup *model.DataGroup) {
+	if ce := a.telemetry.Logger.Check(zapcore.DebugLevel, ""); ce != nil {
+		a.telemetry.Logger.Debug("K8sEventAnalyzer send to consumer: \n" + dataGroup.String())
+	}
+	for _, nextConsumer := range a.nextConsumers {
+		if err := nextConsumer.Consume(dataGroup); err != nil {
+			a.telemetry.Logger.Warn("Error sending container event to next consumer: ", zap.Error(err))
+		}
+	}
+}
+
+// containerRestartDataGroups compares the restart count of each container between the
+// old and new pod, and returns a dataGroup for each container that has been restarted.
+func containerRestartDataGroups(oldPod *corev1.Pod, newPod *corev1.Pod) []*model.DataGroup {
+	oldRestartCounts := make(map[string]int32, len(oldPod.Status.ContainerStatuses))
+	for _, status := range oldPod.Status.ContainerStatuses {
+		oldRestartCounts[status.Name] = status.RestartCount
+	}
+	dataGroups := make([]*model.DataGroup, 0)
+	for _, status := range newPod.Status.ContainerStatuses {
+		oldCount, ok := oldRestartCounts[status.Name]
+		if !ok || status.RestartCount <= oldCount {
+			continue
+		}
+		var reason, message string
+		timestamp := time.Now()
+		if terminated := status.LastTerminationState.Terminated; terminated != nil {
+			reason = terminated.Reason
+			message = fmt.Sprintf("exit code %d", terminated.ExitCode)
+			if !terminated.FinishedAt.IsZero() {
+				timestamp = terminated.FinishedAt.Time
+			}
+		}
+		dataGroups = append(dataGroups, newEventDataGroup(newPod.Namespace, newPod.Name, status.Name,
+			ContainerRestartEvent, reason, message, timestamp))
+	}
+	return dataGroups
+}
+
+// eventDataGroup converts the kubernetes event to a dataGroup. Nil is returned if the event is not interested.
+func eventDataGroup(event *corev1.Event) *model.DataGroup {
+	var eventType string
+	switch {
+	case event.Reason == "Pulled" || event.Reason == "Failed" && strings.Contains(event.Message, "pull image"):
+		eventType = ImagePullEvent
+	case event.Reason == "Unhealthy" && strings.HasPrefix(event.Message, "Liveness probe failed"):
+		eventType = LivenessFailureEvent
+	default:
+		return nil
+	}
+	return newEventDataGroup(event.InvolvedObject.Namespace, event.InvolvedObject.Name,
+		containerNameFromFieldPath(event.InvolvedObject.FieldPath),
+		eventType, event.Reason, event.Message, eventTime(event))
+}
+
+// containerNameFromFieldPath returns the container name from the field path like "spec.containers{name}".
+func containerNameFromFieldPath(fieldPath string) string {
+	start := strings.Index(fieldPath, "{")
+	end := strings.LastIndex(fieldPath, "}")
+	if start < 0 || end <= start {
+		return ""
+	}
+	return fieldPath[start+1 : end]
+}
+
+func eventTime(event *corev1.Event) time.Time {
+	if !event.LastTimestamp.IsZero() {
+		return event.LastTimestamp.Time
+	}
+	if !event.EventTime.IsZero() {
+		return event.EventTime.Time
+	}
+	return event.CreationTimestamp.Time
+}
+
+func newEventDataGroup(namespace, pod, container, eventType, reason, message string, timestamp time.Time) *model.DataGroup {
+	labels := model.NewAttributeMapWithValues(map[string]model.AttributeValue{
+		constlabels.Namespace:    model.NewStringValue(namespace),
+		constlabels.Pod:          model.NewStringValue(pod),
+		constlabels.Container:    model.NewStringValue(container),
+		constlabels.EventType:    model.NewStringValue(eventType),
+		constlabels.EventReason:  model.NewStringValue(reason),
+		constlabels.EventMessage: model.NewStringValue(message),
+	})
+	return model.NewDataGroup(constnames.K8sContainerEventGroupName, labels, uint64(timestamp.UnixNano()),
+		model.NewIntMetric(constnames.K8sContainerEventMetricName, 1))
+}
+
+func (a *K8sEventAnalyzer) ConsumeEvent(event *model.KindlingEvent) error {
+	return nil
+}
+
+func (a *K8sEventAnalyzer) Shutdown() error {
+	if a.stopCh != nil {
+		close(a.stopCh)
+	}
+	a.stopEventWatch()
+	return nil
+}
+
+func (a *K8sEventAnalyzer) Type() analyzer.Type {
+	return Type
+}
+
+func (a *K8sEventAnalyzer) ConsumableEvents() []string {
+	return nil
+}
@@ -0,0 +1,75 @@
+package k8seventanalyzer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+func TestContainerRestartDataGroups(t *testing.T) {
+	finishedAt := time.Unix(1660000000, 0)
+	oldPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app-0"},
+		Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{
+			{Name: "app", RestartCount: 1},
+			{Name: "sidecar", RestartCount: 0},
+		}},
+	}
+	newPod := oldPod.DeepCopy()
+	newPod.Status.ContainerStatuses[0].RestartCount = 2
+	newPod.Status.ContainerStatuses[0].LastTerminationState.Terminated = &corev1.ContainerStateTerminated{
+		Reason:     "OOMKilled",
+		ExitCode:   137,
+		FinishedAt: metav1.NewTime(finishedAt),
+	}
+
+	dataGroups := containerRestartDataGroups(oldPod, newPod)
+	assert.Equal(t, 1, len(dataGroups))
+	labels := dataGroups[0].Labels
+	assert.Equal(t, "app", labels.GetStringValue(constlabels.Container))
+	assert.Equal(t, ContainerRestartEvent, labels.GetStringValue(constlabels.EventType))
+	assert.Equal(t, "OOMKilled", labels.GetStringValue(constlabels.EventReason))
+	assert.Equal(t, uint64(finishedAt.UnixNano()), dataGroups[0].Timestamp)
+
+	assert.Equal(t, 0, len(containerRestartDataGroups(newPod, newPod)))
+}
+
+func TestEventDataGroup(t *testing.T) {
+	tests := []struct {
+		name      string
+		reason    string
+		message   string
+		eventType string
+	}{
+		{"image pulled", "Pulled", "Successfully pulled image \"nginx\"", ImagePullEvent},
+		{"image pull failed", "Failed", "Failed to pull image \"nginx\"", ImagePullEvent},
+		{"liveness failed", "Unhealthy", "Liveness probe failed: connection refused", LivenessFailureEvent},
+		{"readiness failed", "Unhealthy", "Readiness probe failed: connection refused", ""},
+		{"scheduled", "Scheduled", "Successfully assigned default/app-0", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := &corev1.Event{
+				InvolvedObject: corev1.ObjectReference{
+					Kind: "Pod", Namespace: "default", Name: "app-0", FieldPath: "spec.containers{app}",
+				},
+				Reason:        tt.reason,
+				Message:       tt.message,
+				LastTimestamp: metav1.Now(),
+			}
+			dataGroup := eventDataGroup(event)
+			if tt.eventType == "" {
+				assert.Nil(t, dataGroup)
+				return
+			}
+			assert.Equal(t, tt.eventType, dataGroup.Labels.GetStringValue(constlabels.EventType))
+			assert.Equal(t, "app", dataGroup.Labels.GetStringValue(constlabels.Container))
+			assert.Equal(t, "app-0", dataGroup.Labels.GetStringValue(constlabels.Pod))
+		})
+	}
+}
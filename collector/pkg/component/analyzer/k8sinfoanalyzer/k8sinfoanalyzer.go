@@ -40,6 +40,10 @@ func (a *K8sInfoAnalyzer) sendToNextConsumer() {
 		case <-a.stopProfileChan:
 			return
 		case <-timer.C:
+			// The workloads of the whole cluster are sent by the leader only.
+			if !kubernetes.IsLeader() {
+				continue
+			}
 			func() {
 				dataGroups := kubernetes.GetWorkloadDataGroup()
 				for _, nextConsumer := range a.nextConsumers {
@@ -16,6 +16,8 @@ type Manager struct {
 	consumeAllEventsAnalyzers []Analyzer
 	// eventAnalyzersMap maps the event names and the analyzers
 	eventAnalyzersMap map[string][]Analyzer
+	// costSampler estimates the CPU time consumed by each analyzer
+	costSampler *CostSampler
 }
 
 func NewManager(analyzers ...Analyzer) (*Manager, error) {
@@ -51,6 +53,7 @@ func NewManager(analyzers ...Analyzer) (*Manager, error) {
 		allAnalyzers:              analyzers,
 		eventAnalyzersMap:         analyzerMap,
 		consumeAllEventsAnalyzers: consumeAllEventsAnalyzers,
+		costSampler:               NewCostSampler(DefaultCostSampleRate),
 	}, nil
 }
 
@@ -90,3 +93,29 @@ func (m *Manager) GetConsumableAnalyzers(eventName string) []Analyzer {
 		return m.consumeAllEventsAnalyzers
 	}
 }
+
+// CostSampler returns the sampler used to estimate the CPU time consumed by each analyzer.
+// The cost of an analyzer includes the next consumers that it calls synchronously.
+func (m *Manager) CostSampler() *CostSampler {
+	return m.costSampler
+}
+
+// CaptureLength returns the max length of the payloads needed by the analyzers implementing
+// CaptureLengthNegotiator. It is 0 if none of them could tell or any of them needs the whole snaplen.
+func (m *Manager) CaptureLength() int {
+	length := 0
+	for _, analyzer := range m.allAnalyzers {
+		negotiator, ok := analyzer.(CaptureLengthNegotiator)
+		if !ok {
+			continue
+		}
+		analyzerLength := negotiator.CaptureLength()
+		if analyzerLength <= 0 {
+			return 0
+		}
+		if analyzerLength > length {
+			length = analyzerLength
+		}
+	}
+	return length
+}
@@ -34,6 +34,26 @@ func TestNewManager(t *testing.T) {
 	assert.True(t, ok)
 }
 
+func TestManager_CaptureLength(t *testing.T) {
+	manager, _ := NewManager(&testAnalyzer{})
+	assert.Equal(t, 0, manager.CaptureLength())
+
+	manager, _ = NewManager(&testAnalyzer{}, &testNegotiatorAnalyzer{length: 512}, &testNegotiatorAnalyzer{length: 1024})
+	assert.Equal(t, 1024, manager.CaptureLength())
+
+	manager, _ = NewManager(&testNegotiatorAnalyzer{length: 512}, &testNegotiatorAnalyzer{length: 0})
+	assert.Equal(t, 0, manager.CaptureLength())
+}
+
+type testNegotiatorAnalyzer struct {
+	testAnalyzer
+	length int
+}
+
+func (t *testNegotiatorAnalyzer) CaptureLength() int {
+	return t.length
+}
+
 type testAnalyzer struct {
 }
 
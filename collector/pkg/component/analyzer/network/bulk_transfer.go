@@ -0,0 +1,117 @@
+package network
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constvalues"
+)
+
+type bulkTransferKey struct {
+	pid     int64
+	srcIp   string
+	srcPort int64
+	dstIp   string
+	dstPort int64
+}
+
+// pendingBulkTransfer is the statement starting a bulk load, e.g. COPY FROM STDIN of PostgreSQL, whose
+// rows are sent by the client in the next request.
+type pendingBulkTransfer struct {
+	startTimestamp uint64
+	contentKey     string
+	lastSeen       time.Time
+}
+
+type bulkTransferTracker struct {
+	mutex   sync.Mutex
+	pending map[bulkTransferKey]*pendingBulkTransfer
+}
+
+func newBulkTransferTracker() *bulkTransferTracker {
+	return &bulkTransferTracker{pending: make(map[bulkTransferKey]*pendingBulkTransfer)}
+}
+
+// observeBulkTransfer returns true if the record is a part of a bulk transfer, in which case the record
+// should be dropped, as its latency is the time to stream the rows instead of serving a query and would
+// skew the percentiles of the requests. The statement starting a bulk load is held until its rows are
+// sent, and the record of the whole transfer is returned once it ends. The caller owns the returned record.
+func (na *NetworkAnalyzer) observeBulkTransfer(record *model.DataGroup, now time.Time) (*model.DataGroup, bool) {
+	labels := record.Labels
+	direction := labels.GetStringValue(constlabels.BulkTransferDirection)
+	if na.bulkTransfers == nil || direction == "" {
+		return nil, false
+	}
+	key := bulkTransferKey{
+		pid:     labels.GetIntValue(constlabels.Pid),
+		srcIp:   labels.GetStringValue(constlabels.SrcIp),
+		srcPort: labels.GetIntValue(constlabels.SrcPort),
+		dstIp:   labels.GetStringValue(constlabels.DstIp),
+		dstPort: labels.GetIntValue(constlabels.DstPort),
+	}
+	tracker := na.bulkTransfers
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+	if labels.GetBoolValue(constlabels.BulkTransferPending) {
+		tracker.pending[key] = &pendingBulkTransfer{
+			startTimestamp: record.Timestamp,
+			contentKey:     labels.GetStringValue(constlabels.ContentKey),
+			lastSeen:       now,
+		}
+		return nil, true
+	}
+
+	startTimestamp := record.Timestamp
+	contentKey := labels.GetStringValue(constlabels.ContentKey)
+	bytes := getIntMetric(record, constvalues.ResponseIo)
+	if direction == constvalues.BulkTransferIn {
+		bytes = getIntMetric(record, constvalues.RequestIo)
+		if pending, ok := tracker.pending[key]; ok {
+			delete(tracker.pending, key)
+			startTimestamp = pending.startTimestamp
+			contentKey = pending.contentKey
+		}
+	}
+	endTimestamp := record.Timestamp + uint64(getIntMetric(record, constvalues.RequestTotalTime))
+
+	transferLabels := model.NewAttributeMap()
+	transferLabels.AddIntValue(constlabels.Pid, labels.GetIntValue(constlabels.Pid))
+	transferLabels.AddStringValue(constlabels.Comm, labels.GetStringValue(constlabels.Comm))
+	transferLabels.AddStringValue(constlabels.ContainerId, labels.GetStringValue(constlabels.ContainerId))
+	transferLabels.AddBoolValue(constlabels.IsServer, labels.GetBoolValue(constlabels.IsServer))
+	transferLabels.AddStringValue(constlabels.SrcIp, labels.GetStringValue(constlabels.SrcIp))
+	transferLabels.AddStringValue(constlabels.DstIp, labels.GetStringValue(constlabels.DstIp))
+	transferLabels.AddIntValue(constlabels.DstPort, labels.GetIntValue(constlabels.DstPort))
+	transferLabels.AddStringValue(constlabels.Protocol, labels.GetStringValue(constlabels.Protocol))
+	transferLabels.AddStringValue(constlabels.ContentKey, contentKey)
+	transferLabels.AddStringValue(constlabels.BulkTransferDirection, direction)
+	transferLabels.AddBoolValue(constlabels.IsError, labels.GetBoolValue(constlabels.IsError))
+	metrics := []*model.Metric{
+		model.NewIntMetric(constnames.BulkTransferBytesMetric, bytes),
+		model.NewIntMetric(constnames.BulkTransferDurationMetric, int64(elapsed(endTimestamp, startTimestamp))),
+	}
+	// The rows are unknown if the stream fails, or if the end of the stream exceeds the snaplen.
+	if labels.HasAttribute(constlabels.BulkTransferRows) {
+		metrics = append(metrics, model.NewIntMetric(constnames.BulkTransferRowsMetric,
+			labels.GetIntValue(constlabels.BulkTransferRows)))
+	}
+	return model.NewDataGroup(constnames.BulkTransferMetricGroupName, transferLabels, startTimestamp, metrics...), true
+}
+
+// cleanBulkTransfers removes the statements whose rows have not been sent within the no-response threshold.
+func (na *NetworkAnalyzer) cleanBulkTransfers(now time.Time) {
+	if na.bulkTransfers == nil {
+		return
+	}
+	threshold := time.Duration(na.cfg.getNoResponseThreshold()) * time.Second
+	na.bulkTransfers.mutex.Lock()
+	defer na.bulkTransfers.mutex.Unlock()
+	for key, pending := range na.bulkTransfers.pending {
+		if now.Sub(pending.lastSeen) >= threshold {
+			delete(na.bulkTransfers.pending, key)
+		}
+	}
+}
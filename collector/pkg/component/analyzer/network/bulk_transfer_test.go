@@ -0,0 +1,95 @@
+package network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constvalues"
+)
+
+func newPostgresqlRecord(timestamp uint64, duration time.Duration, contentKey string, requestIo, responseIo int64) *model.DataGroup {
+	labels := model.NewAttributeMap()
+	labels.AddIntValue(constlabels.Pid, 2048)
+	labels.AddStringValue(constlabels.Comm, "postgres")
+	labels.AddStringValue(constlabels.SrcIp, "10.0.0.1")
+	labels.AddIntValue(constlabels.SrcPort, 52500)
+	labels.AddStringValue(constlabels.DstIp, "10.0.0.2")
+	labels.AddIntValue(constlabels.DstPort, 5432)
+	labels.AddBoolValue(constlabels.IsServer, true)
+	labels.AddStringValue(constlabels.Protocol, protocol.POSTGRESQL)
+	labels.AddStringValue(constlabels.ContentKey, contentKey)
+	labels.AddBoolValue(constlabels.IsError, false)
+	return model.NewDataGroup(constnames.NetRequestMetricGroupName, labels, timestamp,
+		model.NewIntMetric(constvalues.RequestTotalTime, int64(duration)),
+		model.NewIntMetric(constvalues.RequestIo, requestIo),
+		model.NewIntMetric(constvalues.ResponseIo, responseIo))
+}
+
+func TestObserveBulkTransfer(t *testing.T) {
+	na := &NetworkAnalyzer{cfg: NewDefaultConfig(), bulkTransfers: newBulkTransferTracker()}
+	now := time.Now()
+
+	query := newPostgresqlRecord(100000000, time.Millisecond, "select employees *", 50, 100)
+	transfer, ok := na.observeBulkTransfer(query, now)
+	assert.False(t, ok)
+	assert.Nil(t, transfer)
+
+	// COPY FROM STDIN is answered by CopyInResponse, and the rows are sent in the next request.
+	statement := newPostgresqlRecord(200000000, time.Millisecond, "copy employees", 40, 12)
+	statement.Labels.AddStringValue(constlabels.BulkTransferDirection, constvalues.BulkTransferIn)
+	statement.Labels.AddBoolValue(constlabels.BulkTransferPending, true)
+	transfer, ok = na.observeBulkTransfer(statement, now)
+	assert.True(t, ok)
+	assert.Nil(t, transfer)
+	assert.Len(t, na.bulkTransfers.pending, 1)
+
+	rows := newPostgresqlRecord(202000000, 3*time.Second, "copy", 8<<20, 20)
+	rows.Labels.AddStringValue(constlabels.BulkTransferDirection, constvalues.BulkTransferIn)
+	rows.Labels.AddIntValue(constlabels.BulkTransferRows, 100000)
+	transfer, ok = na.observeBulkTransfer(rows, now)
+	assert.True(t, ok)
+	if assert.NotNil(t, transfer) {
+		assert.Equal(t, constnames.BulkTransferMetricGroupName, transfer.Name)
+		assert.Equal(t, uint64(200000000), transfer.Timestamp)
+		assert.Equal(t, "copy employees", transfer.Labels.GetStringValue(constlabels.ContentKey))
+		assert.Equal(t, constvalues.BulkTransferIn, transfer.Labels.GetStringValue(constlabels.BulkTransferDirection))
+		assert.Equal(t, protocol.POSTGRESQL, transfer.Labels.GetStringValue(constlabels.Protocol))
+		assert.Equal(t, int64(8<<20), getIntMetric(transfer, constnames.BulkTransferBytesMetric))
+		assert.Equal(t, int64(100000), getIntMetric(transfer, constnames.BulkTransferRowsMetric))
+		assert.Equal(t, int64(3002000000), getIntMetric(transfer, constnames.BulkTransferDurationMetric))
+	}
+	assert.Empty(t, na.bulkTransfers.pending)
+
+	// COPY TO STDOUT streams the rows in the response, whose end exceeds the snaplen.
+	export := newPostgresqlRecord(300000000, 2*time.Second, "copy *", 60, 4<<20)
+	export.Labels.AddStringValue(constlabels.BulkTransferDirection, constvalues.BulkTransferOut)
+	transfer, ok = na.observeBulkTransfer(export, now)
+	assert.True(t, ok)
+	if assert.NotNil(t, transfer) {
+		assert.Equal(t, "copy *", transfer.Labels.GetStringValue(constlabels.ContentKey))
+		assert.Equal(t, int64(4<<20), getIntMetric(transfer, constnames.BulkTransferBytesMetric))
+		assert.Equal(t, int64(2000000000), getIntMetric(transfer, constnames.BulkTransferDurationMetric))
+		_, ok = transfer.GetMetric(constnames.BulkTransferRowsMetric)
+		assert.False(t, ok)
+	}
+}
+
+func TestCleanBulkTransfers(t *testing.T) {
+	na := &NetworkAnalyzer{cfg: NewDefaultConfig(), bulkTransfers: newBulkTransferTracker()}
+	now := time.Now()
+	statement := newPostgresqlRecord(200000000, time.Millisecond, "copy employees", 40, 12)
+	statement.Labels.AddStringValue(constlabels.BulkTransferDirection, constvalues.BulkTransferIn)
+	statement.Labels.AddBoolValue(constlabels.BulkTransferPending, true)
+	na.observeBulkTransfer(statement, now)
+
+	na.cleanBulkTransfers(now.Add(time.Second))
+	assert.Len(t, na.bulkTransfers.pending, 1)
+	na.cleanBulkTransfers(now.Add(time.Duration(na.cfg.getNoResponseThreshold()) * time.Second))
+	assert.Empty(t, na.bulkTransfers.pending)
+}
@@ -1,10 +1,28 @@
 package network
 
+import (
+	"time"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/payloadprofile"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/pcapexport"
+)
+
 const (
-	defaultFdReuseTimeout        = 15
-	defaultNoResponseThreshold   = 120
-	defaultConnectTimeout        = 1
-	defaultResponseSlowThreshold = 500
+	defaultFdReuseTimeout         = 15
+	defaultNoResponseThreshold    = 120
+	defaultConnectTimeout         = 1
+	defaultResponseSlowThreshold  = 500
+	defaultDnsDedupWindow         = 10000
+	defaultNodeLocalDnsWindow     = 2000
+	defaultDnsAmplificationWindow = 2000
+	defaultDnsAttributionWindow   = 1000
+	defaultFanOutWindow           = 10000
+	defaultConsumerQueueSize      = 10000
+	defaultParserColdAfter        = 600
+	defaultColdCheckInterval      = 100
+	defaultProtocolInfoInterval   = 60
+	defaultProtocolInfoExpiration = 3600
+	defaultThreadPoolInterval     = 30
 )
 
 type Config struct {
@@ -22,10 +40,119 @@ type Config struct {
 	ConntrackMaxStateSize int    `mapstructure:"conntrack_max_state_size"`
 	ConntrackRateLimit    int    `mapstructure:"conntrack_rate_limit"`
 	ProcRoot              string `mapstructure:"proc_root"`
+	// DnatChainDepth is the maximum number of the DNAT hops followed in conntrack, e.g. 2 for
+	// service VIP -> NodePort -> pod. The last hop is reported as the DNAT address.
+	DnatChainDepth int `mapstructure:"dnat_chain_depth"`
+	// EnableThreadName adds the names of the threads that read the request and write the response
+	// as labels, which are read from /proc/<pid>/task/<tid>/comm.
+	EnableThreadName bool `mapstructure:"enable_thread_name"`
 
 	ProtocolParser      []string         `mapstructure:"protocol_parser"`
 	ProtocolConfigs     []ProtocolConfig `mapstructure:"protocol_config,omitempty"`
 	UrlClusteringMethod string           `mapstructure:"url_clustering_method"`
+	// DropUnknownPorts are the ports whose traffic is dropped instead of being recorded as NOSUPPORT
+	// if no parser recognizes it, e.g. the ports carrying encrypted or backup traffic.
+	DropUnknownPorts []uint32 `mapstructure:"drop_unknown_ports"`
+	// DetectUdpDns parses the UDP payloads that look like DNS messages as DNS even if they are not sent
+	// to the ports configured with the DNS key, e.g. the resolvers listening on 5300 or 8600.
+	DetectUdpDns bool `mapstructure:"detect_udp_dns"`
+	// KeepMulticastDns keeps the mDNS (5353) and LLMNR (5355) queries as the records labeled with the protocol
+	// "mdns" and "llmnr". They are dropped by default, as they are multicast to the local link instead of being
+	// resolved by the DNS servers.
+	KeepMulticastDns bool `mapstructure:"keep_multicast_dns"`
+	// HttpSessionCookie is the name of the cookie holding the session ID. The hash of the cookie is added
+	// as the label "http_session_hash" to observe the sticky sessions. It is disabled if empty.
+	HttpSessionCookie string `mapstructure:"http_session_cookie"`
+	// HttpSoapOperation reads the operations of the SOAP and XML-RPC requests from the SOAPAction header
+	// or the body, and appends them to the content key of HTTP, e.g. "/ws/StockService#GetQuote".
+	// The faults of the responses are taken as errors.
+	HttpSoapOperation bool `mapstructure:"http_soap_operation"`
+	// HttpElasticsearch takes the operations and the normalized indices of the Elasticsearch requests as the
+	// content key of HTTP, e.g. "search logs-*", and reads "took" and "status" from the responses.
+	HttpElasticsearch bool `mapstructure:"http_elasticsearch"`
+	// HttpInfluxdb reads the measurements and the points of the InfluxDB writes sent to "/write" and
+	// "/api/v2/write" from the line protocol, and takes "write <measurement>" as the content key of HTTP.
+	HttpInfluxdb bool `mapstructure:"http_influxdb"`
+	// HttpGraphqlPaths are the paths of the GraphQL endpoints. The type and the name of the operations sent
+	// to them with POST are appended to the content key of HTTP, e.g. "/graphql#query GetUser".
+	HttpGraphqlPaths []string `mapstructure:"http_graphql_paths"`
+	// AdaptiveSnaplen truncates the payloads on the ports of the known protocols to the lengths their parsers
+	// need, e.g. the headers of HTTP. The snaplen in the kernel is lowered if all the enabled parsers need less.
+	AdaptiveSnaplen bool `mapstructure:"adaptive_snaplen"`
+
+	// SyscallBreakdown adds the time spent in the syscalls by the request thread to the slow requests.
+	SyscallBreakdown *SyscallBreakdownConfig `mapstructure:"syscall_breakdown"`
+
+	// DnsDedup collapses the identical DNS queries sent by resolver retries into one record.
+	DnsDedup *DnsDedupConfig `mapstructure:"dns_dedup"`
+
+	// NodeLocalDns links the queries sent to the NodeLocal DNSCache with the ones it forwards to the upstream.
+	NodeLocalDns *NodeLocalDnsConfig `mapstructure:"node_local_dns"`
+
+	// DnsAmplification merges the NXDOMAIN queries expanded from the search domains into the successful
+	// query of the same name.
+	DnsAmplification *DnsAmplificationConfig `mapstructure:"dns_amplification"`
+
+	// PayloadProfile clusters the payloads of the NOSUPPORT requests by port.
+	PayloadProfile *payloadprofile.Config `mapstructure:"payload_profile"`
+
+	// PcapExport captures the payloads of the connections chosen via the http API and exports them in pcapng.
+	PcapExport *pcapexport.Config `mapstructure:"pcap_export"`
+
+	// HealthCheck recognizes the health-check requests and labels or drops them.
+	HealthCheck *HealthCheckConfig `mapstructure:"health_check"`
+
+	// DnsAttribution attaches the DNS resolution time to the request sent to the resolved IP.
+	DnsAttribution *DnsAttributionConfig `mapstructure:"dns_attribution"`
+
+	// FanOut labels the inbound requests with the outbound requests the process sent while handling them.
+	FanOut *FanOutConfig `mapstructure:"fan_out"`
+
+	// ConsumerQueue gives each next consumer its own queue so a slow one doesn't block the others.
+	ConsumerQueue *ConsumerQueueConfig `mapstructure:"consumer_queue"`
+
+	// PayloadMask masks the sensitive fields inside the parsers, so they are neither in the labels nor in the payloads.
+	PayloadMask *PayloadMaskConfig `mapstructure:"payload_mask"`
+	// ParserTiering moves the parsers that have not matched recently to a cold tier to save CPU.
+	ParserTiering *ParserTieringConfig `mapstructure:"parser_tiering"`
+	// ProtocolInfo reports the protocols and the ports each container has been observed speaking.
+	ProtocolInfo *ProtocolInfoConfig `mapstructure:"protocol_info"`
+	// ThreadPool reports the threads handling the server-side requests of each process and how busy they are.
+	ThreadPool *ThreadPoolConfig `mapstructure:"thread_pool"`
+	// WorkloadOverride overrides the slow threshold and the payloads with the annotations of the pods,
+	// e.g. "kindling.io/slow-threshold: 200ms". It needs the metadata of Kubernetes.
+	WorkloadOverride *WorkloadOverrideConfig `mapstructure:"workload_override"`
+}
+
+type SyscallBreakdownConfig struct {
+	Enable bool `mapstructure:"enable"`
+	// MaxEventsPerThread is the number of the latest syscalls kept for each thread.
+	MaxEventsPerThread int `mapstructure:"max_events_per_thread"`
+}
+
+type DnsDedupConfig struct {
+	Enable bool `mapstructure:"enable"`
+	// The unit is millisecond.
+	Window int `mapstructure:"window"`
+}
+
+type NodeLocalDnsConfig struct {
+	Enable bool `mapstructure:"enable"`
+	// CacheIps are the addresses the NodeLocal DNSCache listens on.
+	CacheIps []string `mapstructure:"cache_ips"`
+	// CacheComms are the command names of the NodeLocal DNSCache process.
+	CacheComms []string `mapstructure:"cache_comms"`
+	// The unit is millisecond.
+	Window int `mapstructure:"window"`
+}
+
+type DnsAmplificationConfig struct {
+	Enable bool `mapstructure:"enable"`
+	// SearchDomains are the suffixes appended by the resolver to the names with fewer dots than ndots.
+	// "*" matches one label, e.g. the namespace in "*.svc.cluster.local".
+	SearchDomains []string `mapstructure:"search_domains"`
+	// The unit is millisecond.
+	Window int `mapstructure:"window"`
 }
 
 func NewDefaultConfig() *Config {
@@ -40,6 +167,7 @@ func NewDefaultConfig() *Config {
 		IgnoreDnsRcode3Error:  false,
 		ConntrackMaxStateSize: 131072,
 		ConntrackRateLimit:    500,
+		DnatChainDepth:        1,
 		ProcRoot:              "/proc",
 		ProtocolParser:        []string{"http", "mysql", "dns", "redis", "kafka", "dubbo"},
 		ProtocolConfigs: []ProtocolConfig{
@@ -78,15 +206,164 @@ func NewDefaultConfig() *Config {
 			},
 		},
 		UrlClusteringMethod: "alphabet",
+		SyscallBreakdown: &SyscallBreakdownConfig{
+			Enable:             false,
+			MaxEventsPerThread: 1000,
+		},
+		DnsDedup: &DnsDedupConfig{
+			Enable: false,
+			Window: defaultDnsDedupWindow,
+		},
+		NodeLocalDns: &NodeLocalDnsConfig{
+			Enable:     false,
+			CacheIps:   []string{"169.254.20.10"},
+			CacheComms: []string{"node-cache"},
+			Window:     defaultNodeLocalDnsWindow,
+		},
+		DnsAmplification: &DnsAmplificationConfig{
+			Enable:        false,
+			SearchDomains: []string{"*.svc.cluster.local", "svc.cluster.local", "cluster.local"},
+			Window:        defaultDnsAmplificationWindow,
+		},
+		PayloadProfile: payloadprofile.NewDefaultConfig(),
+		PcapExport:     pcapexport.NewDefaultConfig(),
+		HealthCheck: &HealthCheckConfig{
+			Enable:     false,
+			Action:     healthCheckActionLabel,
+			Urls:       []string{"/healthz", "/livez", "/readyz", "/health", "/actuator/health"},
+			UserAgents: []string{"kube-probe/", "ELB-HealthChecker/", "GoogleHC/"},
+		},
+		DnsAttribution: &DnsAttributionConfig{
+			Enable: false,
+			Window: defaultDnsAttributionWindow,
+		},
+		FanOut: &FanOutConfig{
+			Enable:      false,
+			MatchThread: false,
+			Window:      defaultFanOutWindow,
+		},
+		ConsumerQueue: &ConsumerQueueConfig{
+			Enable: false,
+			Size:   defaultConsumerQueueSize,
+		},
+		PayloadMask: &PayloadMaskConfig{
+			Enable:        false,
+			HttpHeaders:   []string{"Authorization", "Proxy-Authorization", "Cookie"},
+			MysqlLiterals: true,
+			RedisAuth:     true,
+			LdapBind:      true,
+		},
+		ParserTiering: &ParserTieringConfig{
+			Enable:            false,
+			ColdAfter:         defaultParserColdAfter,
+			ColdCheckInterval: defaultColdCheckInterval,
+		},
+		ProtocolInfo: &ProtocolInfoConfig{
+			Enable:     false,
+			Interval:   defaultProtocolInfoInterval,
+			Expiration: defaultProtocolInfoExpiration,
+		},
+		ThreadPool: &ThreadPoolConfig{
+			Enable:   false,
+			Interval: defaultThreadPoolInterval,
+		},
+		WorkloadOverride: &WorkloadOverrideConfig{
+			Enable: false,
+		},
 	}
 }
 
+type ProtocolInfoConfig struct {
+	Enable bool `mapstructure:"enable"`
+	// Interval is the period of reporting the protocols. The unit is second.
+	Interval int `mapstructure:"interval"`
+	// Expiration is the time after which a protocol not observed any more is no longer reported. The unit is second.
+	Expiration int `mapstructure:"expiration"`
+}
+
+type ThreadPoolConfig struct {
+	Enable bool `mapstructure:"enable"`
+	// Interval is the window of counting the threads and the period of reporting them. The unit is second.
+	Interval int `mapstructure:"interval"`
+}
+
+type PayloadMaskConfig struct {
+	Enable bool `mapstructure:"enable"`
+	// HttpHeaders are the names of the HTTP request headers whose values are masked. They are case-insensitive.
+	HttpHeaders []string `mapstructure:"http_headers"`
+	// MysqlLiterals masks the string and numeric literals of the MySQL statements.
+	MysqlLiterals bool `mapstructure:"mysql_literals"`
+	// RedisAuth masks the arguments of the Redis AUTH command.
+	RedisAuth bool `mapstructure:"redis_auth"`
+	// LdapBind masks the passwords of the LDAP simple binds.
+	LdapBind bool `mapstructure:"ldap_bind"`
+}
+
+type ParserTieringConfig struct {
+	Enable bool `mapstructure:"enable"`
+	// ColdAfter is the time after which a parser without any match is moved to the cold tier. The unit is second.
+	ColdAfter int `mapstructure:"cold_after"`
+	// ColdCheckInterval means the cold parsers are tried once every ColdCheckInterval unknown payloads.
+	ColdCheckInterval int `mapstructure:"cold_check_interval"`
+}
+
+type ConsumerQueueConfig struct {
+	Enable bool `mapstructure:"enable"`
+	// Size is the capacity of the queue of each consumer. The records are dropped when the queue is full.
+	Size int `mapstructure:"size"`
+}
+
+type DnsAttributionConfig struct {
+	Enable bool `mapstructure:"enable"`
+	// Window is the maximum time between the resolution and the request. The unit is millisecond.
+	Window int `mapstructure:"window"`
+}
+
+type FanOutConfig struct {
+	Enable bool `mapstructure:"enable"`
+	// MatchThread only counts the outbound requests sent by the threads handling the inbound request,
+	// which suits the servers handling each request in one thread.
+	MatchThread bool `mapstructure:"match_thread"`
+	// Window is how long the outbound requests are kept after they end, which should cover the
+	// longest inbound request. The unit is millisecond.
+	Window int `mapstructure:"window"`
+}
+
+type HealthCheckConfig struct {
+	Enable bool `mapstructure:"enable"`
+	// Action is "label" or "drop". The health checks are labeled with "is_health_check" if it is "label".
+	Action string `mapstructure:"action"`
+	// Urls are the paths of the HTTP health-check requests. The query of the URL is ignored.
+	Urls []string `mapstructure:"urls"`
+	// UserAgents are the prefixes of the User-Agent headers of the HTTP health-check requests.
+	UserAgents []string `mapstructure:"user_agents"`
+	// SourceIps are the addresses of the health checkers. All requests from them are health checks.
+	SourceIps []string `mapstructure:"source_ips"`
+}
+
 type ProtocolConfig struct {
 	Key            string   `mapstructure:"key,omitempty"`
 	Ports          []uint32 `mapstructure:"ports,omitempty"`
 	PayloadLength  int      `mapstructure:"payload_length"`
 	DisableDiscern bool     `mapstructure:"disable_discern,omitempty"`
 	Threshold      int      `mapstructure:"slow_threshold,omitempty"`
+	// NoResponseThreshold overrides the global one for the protocol. The unit is second.
+	NoResponseThreshold int `mapstructure:"no_response_threshold,omitempty"`
+	// PortNoResponseThresholds overrides the threshold of the protocol for the specified ports.
+	PortNoResponseThresholds []PortNoResponseThreshold `mapstructure:"port_no_response_thresholds,omitempty"`
+	// OnewayPorts are the ports whose requests are not expected to be responded, so the requests
+	// without responses are not regarded as the NoResponse errors.
+	OnewayPorts []uint32 `mapstructure:"oneway_ports,omitempty"`
+	// PayloadEncoding is how the payloads are rendered as labels, i.e. "text", "hex", "base64" or "escaped".
+	PayloadEncoding string `mapstructure:"payload_encoding,omitempty"`
+	// PayloadLabelLength limits the length of the payload labels after encoding. No limit if it is 0.
+	PayloadLabelLength int `mapstructure:"payload_label_length,omitempty"`
+}
+
+type PortNoResponseThreshold struct {
+	Port uint32 `mapstructure:"port"`
+	// The unit is second.
+	Threshold int `mapstructure:"threshold"`
 }
 
 func (cfg *Config) GetConnectTimeout() int {
@@ -120,3 +397,87 @@ func (cfg *Config) getNoResponseThreshold() int {
 		return defaultNoResponseThreshold
 	}
 }
+
+func (cfg *Config) getDnatChainDepth() int {
+	if cfg.DnatChainDepth > 0 {
+		return cfg.DnatChainDepth
+	}
+	return 1
+}
+
+func (cfg *Config) getDnsDedupWindow() time.Duration {
+	if cfg.DnsDedup.Window > 0 {
+		return time.Duration(cfg.DnsDedup.Window) * time.Millisecond
+	}
+	return defaultDnsDedupWindow * time.Millisecond
+}
+
+func (cfg *Config) getNodeLocalDnsWindow() time.Duration {
+	if cfg.NodeLocalDns.Window > 0 {
+		return time.Duration(cfg.NodeLocalDns.Window) * time.Millisecond
+	}
+	return defaultNodeLocalDnsWindow * time.Millisecond
+}
+
+func (cfg *Config) getDnsAmplificationWindow() time.Duration {
+	if cfg.DnsAmplification.Window > 0 {
+		return time.Duration(cfg.DnsAmplification.Window) * time.Millisecond
+	}
+	return defaultDnsAmplificationWindow * time.Millisecond
+}
+
+func (cfg *Config) getDnsAttributionWindow() time.Duration {
+	if cfg.DnsAttribution.Window > 0 {
+		return time.Duration(cfg.DnsAttribution.Window) * time.Millisecond
+	}
+	return defaultDnsAttributionWindow * time.Millisecond
+}
+
+func (cfg *Config) getFanOutWindow() time.Duration {
+	if cfg.FanOut.Window > 0 {
+		return time.Duration(cfg.FanOut.Window) * time.Millisecond
+	}
+	return defaultFanOutWindow * time.Millisecond
+}
+
+func (cfg *Config) getConsumerQueueSize() int {
+	if cfg.ConsumerQueue.Size > 0 {
+		return cfg.ConsumerQueue.Size
+	}
+	return defaultConsumerQueueSize
+}
+
+func (cfg *Config) getParserColdAfter() time.Duration {
+	if cfg.ParserTiering.ColdAfter > 0 {
+		return time.Duration(cfg.ParserTiering.ColdAfter) * time.Second
+	}
+	return defaultParserColdAfter * time.Second
+}
+
+func (cfg *Config) getColdCheckInterval() int {
+	if cfg.ParserTiering.ColdCheckInterval > 0 {
+		return cfg.ParserTiering.ColdCheckInterval
+	}
+	return defaultColdCheckInterval
+}
+
+func (cfg *Config) getProtocolInfoInterval() time.Duration {
+	if cfg.ProtocolInfo.Interval > 0 {
+		return time.Duration(cfg.ProtocolInfo.Interval) * time.Second
+	}
+	return defaultProtocolInfoInterval * time.Second
+}
+
+func (cfg *Config) getThreadPoolInterval() time.Duration {
+	if cfg.ThreadPool.Interval > 0 {
+		return time.Duration(cfg.ThreadPool.Interval) * time.Second
+	}
+	return defaultThreadPoolInterval * time.Second
+}
+
+func (cfg *Config) getProtocolInfoExpiration() time.Duration {
+	if cfg.ProtocolInfo.Expiration > 0 {
+		return time.Duration(cfg.ProtocolInfo.Expiration) * time.Second
+	}
+	return defaultProtocolInfoExpiration * time.Second
+}
@@ -0,0 +1,96 @@
+package network
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+// upgradedProtocols are the protocols parsed only after the connections are upgraded by HTTP. They are
+// not used to classify the connections, as their messages are too short to be recognized reliably.
+var upgradedProtocols = map[string]bool{
+	protocol.WEBSOCKET: true,
+}
+
+// connectionKey identifies a connection. The ports are included because the fd could be
+// reused by another connection after it is closed.
+type connectionKey struct {
+	pid   uint32
+	fd    int32
+	sport uint32
+	dport uint32
+}
+
+func getConnectionKey(evt *model.KindlingEvent) connectionKey {
+	return connectionKey{
+		pid:   evt.GetPid(),
+		fd:    evt.GetFd(),
+		sport: evt.GetSport(),
+		dport: evt.GetDport(),
+	}
+}
+
+type connectionProtocol struct {
+	parser *protocol.ProtocolParser
+	// lastTs is the unix nanoseconds when the connection was parsed last time.
+	lastTs int64
+}
+
+// getConnectionParser returns the parser that recognized the previous requests of the connection.
+// The protocol is kept per connection because a server could serve multiple protocols on one port,
+// e.g. gRPC and HTTP/1 with cmux, in which case the protocol cached for the port is not reliable.
+func (na *NetworkAnalyzer) getConnectionParser(key connectionKey, now time.Time) (*protocol.ProtocolParser, bool) {
+	value, ok := na.connectionProtocols.Load(key)
+	if !ok {
+		return nil, false
+	}
+	connProtocol := value.(*connectionProtocol)
+	atomic.StoreInt64(&connProtocol.lastTs, now.UnixNano())
+	return connProtocol.parser, true
+}
+
+func (na *NetworkAnalyzer) setConnectionParser(key connectionKey, parser *protocol.ProtocolParser, now time.Time) {
+	na.connectionProtocols.Store(key, &connectionProtocol{parser: parser, lastTs: now.UnixNano()})
+}
+
+// forgetConnectionParser removes the connections recognized by the parser, which are classified again.
+func (na *NetworkAnalyzer) forgetConnectionParser(parser *protocol.ProtocolParser) {
+	na.connectionProtocols.Range(func(k, v interface{}) bool {
+		if v.(*connectionProtocol).parser == parser {
+			na.connectionProtocols.Delete(k)
+		}
+		return true
+	})
+}
+
+// cleanConnectionProtocols removes the connections that have not been parsed within the threshold.
+func (na *NetworkAnalyzer) cleanConnectionProtocols(now time.Time) {
+	threshold := int64(na.cfg.getNoResponseThreshold()) * int64(time.Second)
+	na.connectionProtocols.Range(func(k, v interface{}) bool {
+		if now.UnixNano()-atomic.LoadInt64(&v.(*connectionProtocol).lastTs) >= threshold {
+			na.connectionProtocols.Delete(k)
+		}
+		return true
+	})
+}
+
+// switchUpgradedConnection keeps the connection parsed as the protocol switched to by the HTTP response
+// 101 Switching Protocols, e.g. WebSocket, if the protocol is enabled.
+func (na *NetworkAnalyzer) switchUpgradedConnection(key connectionKey, parser *protocol.ProtocolParser, records []*model.DataGroup, now time.Time) {
+	if parser.GetProtocol() != protocol.HTTP {
+		return
+	}
+	for _, record := range records {
+		upgrade := record.Labels.GetStringValue(constlabels.HttpUpgrade)
+		if upgrade == "" || !upgradedProtocols[upgrade] {
+			continue
+		}
+		if upgradedParser, ok := na.protocolMap[upgrade]; ok {
+			na.setConnectionParser(key, upgradedParser, now)
+		}
+		return
+	}
+}
@@ -0,0 +1,33 @@
+package network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/generic"
+)
+
+func TestConnectionProtocols(t *testing.T) {
+	na := &NetworkAnalyzer{cfg: NewDefaultConfig()}
+	parser := generic.NewGenericParser()
+	now := time.Now()
+	key := connectionKey{pid: 100, fd: 3, sport: 45678, dport: 8080}
+	na.setConnectionParser(key, parser, now)
+
+	// The other connections on the same port are not affected.
+	_, ok := na.getConnectionParser(connectionKey{pid: 100, fd: 4, sport: 45679, dport: 8080}, now)
+	assert.False(t, ok)
+	got, ok := na.getConnectionParser(key, now.Add(60*time.Second))
+	assert.True(t, ok)
+	assert.Equal(t, parser, got)
+
+	// The connection was parsed 60s later, so it is kept until 180s.
+	na.cleanConnectionProtocols(now.Add(179 * time.Second))
+	_, ok = na.getConnectionParser(key, now.Add(60*time.Second))
+	assert.True(t, ok)
+	na.cleanConnectionProtocols(now.Add(180 * time.Second))
+	_, ok = na.getConnectionParser(key, now)
+	assert.False(t, ok)
+}
@@ -0,0 +1,156 @@
+package network
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/timeunit"
+)
+
+// ConnectionEntry is a connection tracked by the analyzer, with the message pair waiting to be sent.
+type ConnectionEntry struct {
+	Pid        uint32 `json:"pid"`
+	Fd         int32  `json:"fd"`
+	Generation uint32 `json:"generation,omitempty"`
+	Comm       string `json:"comm"`
+	SrcIp      string `json:"src_ip"`
+	SrcPort    uint32 `json:"src_port"`
+	DstIp      string `json:"dst_ip"`
+	DstPort    uint32 `json:"dst_port"`
+	IsServer   bool   `json:"is_server"`
+	// Protocol is the protocol recognized for the connection, which is empty before the first
+	// message pair is parsed.
+	Protocol string `json:"protocol,omitempty"`
+	// PortProtocols are the protocols cached for the port in the order they are tried.
+	PortProtocols []string `json:"port_protocols,omitempty"`
+	// State is one of "connecting", "requesting" and "responding".
+	State     string `json:"state"`
+	Requests  int    `json:"requests"`
+	Responses int    `json:"responses"`
+	// IdleMs is the time since the last event of the message pair.
+	IdleMs int64  `json:"idle_ms"`
+	Dnat   string `json:"dnat,omitempty"`
+}
+
+// ConnectionFilter selects the connections. The zero value matches all of them.
+type ConnectionFilter struct {
+	Pid uint32
+	// Port matches either the source or the destination port.
+	Port     uint32
+	Protocol string
+}
+
+func (f *ConnectionFilter) match(entry *ConnectionEntry) bool {
+	if f.Pid != 0 && entry.Pid != f.Pid {
+		return false
+	}
+	if f.Port != 0 && entry.SrcPort != f.Port && entry.DstPort != f.Port {
+		return false
+	}
+	if f.Protocol != "" && !strings.EqualFold(entry.Protocol, f.Protocol) {
+		return false
+	}
+	return true
+}
+
+// ConnectionTable returns the connections tracked currently, sorted by pid and fd.
+func (na *NetworkAnalyzer) ConnectionTable(filter ConnectionFilter) []*ConnectionEntry {
+	var portProtocols map[uint32][]string
+	if na.parserFactory != nil {
+		portProtocols = na.parserFactory.GetCachedProtocols()
+	}
+	now := timeunit.Now()
+	entries := make([]*ConnectionEntry, 0)
+	na.protocolMutex.RLock()
+	na.requestMonitor.Range(func(k, v interface{}) bool {
+		entry := na.newConnectionEntry(k.(messagePairKey), v.(*messagePairs), now)
+		if entry == nil {
+			return true
+		}
+		entry.PortProtocols = portProtocols[entry.DstPort]
+		if filter.match(entry) {
+			entries = append(entries, entry)
+		}
+		return true
+	})
+	na.protocolMutex.RUnlock()
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Pid != entries[j].Pid {
+			return entries[i].Pid < entries[j].Pid
+		}
+		if entries[i].Fd != entries[j].Fd {
+			return entries[i].Fd < entries[j].Fd
+		}
+		return entries[i].Generation < entries[j].Generation
+	})
+	return entries
+}
+
+func (na *NetworkAnalyzer) newConnectionEntry(key messagePairKey, mps *messagePairs, now timeunit.Timestamp) *ConnectionEntry {
+	mps.mutex.RLock()
+	defer mps.mutex.RUnlock()
+	var evt *model.KindlingEvent
+	entry := &ConnectionEntry{Pid: key.pid, Fd: key.fd, Generation: key.generation}
+	switch {
+	case mps.responses != nil:
+		evt = mps.responses.event
+		entry.State = "responding"
+	case mps.requests != nil:
+		evt = mps.requests.event
+		entry.State = "requesting"
+	case mps.connects != nil:
+		evt = mps.connects.event
+		entry.State = "connecting"
+	default:
+		return nil
+	}
+	if mps.requests != nil {
+		entry.Requests = mps.requests.size()
+	}
+	if mps.responses != nil {
+		entry.Responses = mps.responses.size()
+	}
+	entry.Comm = evt.GetComm()
+	entry.SrcIp = evt.GetSip()
+	entry.SrcPort = evt.GetSport()
+	entry.DstIp = evt.GetDip()
+	entry.DstPort = evt.GetDport()
+	entry.IsServer = evt.GetCtx().GetFdInfo().Role
+	entry.IdleMs = now.Sub(timeunit.Timestamp(mps.getTimeoutTs())).In(timeunit.Millisecond)
+	if value, ok := na.connectionProtocols.Load(getConnectionKey(evt)); ok {
+		entry.Protocol = value.(*connectionProtocol).parser.GetProtocol()
+	}
+	if mps.natTuple != nil {
+		entry.Dnat = net.JoinHostPort(mps.natTuple.ReplSrcIP.String(), strconv.Itoa(int(mps.natTuple.ReplSrcPort)))
+	}
+	return entry
+}
+
+// ConnectionTableHandler returns the handler reporting the connections tracked currently as JSON.
+// The connections are filtered by the query parameters "pid", "port" and "protocol".
+func (na *NetworkAnalyzer) ConnectionTableHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var filter ConnectionFilter
+		query := r.URL.Query()
+		for name, value := range map[string]*uint32{"pid": &filter.Pid, "port": &filter.Port} {
+			if s := query.Get(name); s != "" {
+				n, err := strconv.ParseUint(s, 10, 32)
+				if err != nil {
+					http.Error(w, "invalid "+name+": "+s, http.StatusBadRequest)
+					return
+				}
+				*value = uint32(n)
+			}
+		}
+		filter.Protocol = query.Get("protocol")
+		w.Header().Set("Content-Type", "application/json")
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		_ = encoder.Encode(na.ConnectionTable(filter))
+	})
+}
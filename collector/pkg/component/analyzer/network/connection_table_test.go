@@ -0,0 +1,74 @@
+package network
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/factory"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+)
+
+func TestConnectionTableHandler(t *testing.T) {
+	na := &NetworkAnalyzer{
+		cfg:           NewDefaultConfig(),
+		parserFactory: factory.NewParserFactory(),
+	}
+	request := newServerEvent(constnames.ReadEvent, 5, 1000, 10)
+	na.requestMonitor.Store(getMessagePairKey(request), &messagePairs{requests: newEvents(request, 200)})
+	na.setConnectionParser(getConnectionKey(request), na.parserFactory.GetParser("http"), time.Now())
+	connect := newServerEvent(constnames.ConnectEvent, 6, 2000, 10)
+	connect.Ctx.FdInfo.Role = false
+	connect.Ctx.FdInfo.Dport = 3306
+	na.requestMonitor.Store(getMessagePairKey(connect), &messagePairs{connects: newEvents(connect, 200)})
+
+	tests := []struct {
+		name   string
+		query  string
+		status int
+		fds    []int32
+	}{
+		{name: "all", query: "", status: http.StatusOK, fds: []int32{5, 6}},
+		{name: "pid", query: "?pid=100", status: http.StatusOK, fds: []int32{5, 6}},
+		{name: "other pid", query: "?pid=1", status: http.StatusOK, fds: []int32{}},
+		{name: "port", query: "?port=3306", status: http.StatusOK, fds: []int32{6}},
+		{name: "protocol", query: "?protocol=HTTP", status: http.StatusOK, fds: []int32{5}},
+		{name: "invalid port", query: "?port=http", status: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			na.ConnectionTableHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/connections"+tt.query, nil))
+			require.Equal(t, tt.status, recorder.Code)
+			if tt.status != http.StatusOK {
+				return
+			}
+			var entries []*ConnectionEntry
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &entries))
+			fds := make([]int32, 0, len(entries))
+			for _, entry := range entries {
+				fds = append(fds, entry.Fd)
+			}
+			assert.Equal(t, tt.fds, fds)
+		})
+	}
+
+	entries := na.ConnectionTable(ConnectionFilter{Port: 8080})
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, "requesting", entries[0].State)
+		assert.Equal(t, "http", entries[0].Protocol)
+		assert.Equal(t, 1, entries[0].Requests)
+		assert.True(t, entries[0].IsServer)
+		assert.Equal(t, "java", entries[0].Comm)
+	}
+	entries = na.ConnectionTable(ConnectionFilter{Port: 3306})
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, "connecting", entries[0].State)
+		assert.Empty(t, entries[0].Protocol)
+	}
+}
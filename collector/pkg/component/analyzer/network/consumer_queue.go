@@ -0,0 +1,71 @@
+package network
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+)
+
+// consumerQueue delivers the records to one next consumer in its own goroutine, so a stalled
+// exporter doesn't back up the analyzer and the other consumers. The records are dropped when
+// the queue is full.
+type consumerQueue struct {
+	consumer consumer.Consumer
+	// name is the type of the consumer, which is used as the label of the drop metric.
+	name    string
+	queue   chan *model.DataGroup
+	dropped int64
+}
+
+func newConsumerQueue(c consumer.Consumer, size int) *consumerQueue {
+	return &consumerQueue{
+		consumer: c,
+		name:     fmt.Sprintf("%T", c),
+		queue:    make(chan *model.DataGroup, size),
+	}
+}
+
+// offer enqueues a copy of the record without blocking. It returns false if the record is dropped.
+func (q *consumerQueue) offer(record *model.DataGroup) bool {
+	// Check the capacity first to avoid cloning the records that will be dropped.
+	if len(q.queue) < cap(q.queue) {
+		select {
+		case q.queue <- record.Clone():
+			return true
+		default:
+		}
+	}
+	atomic.AddInt64(&q.dropped, 1)
+	return false
+}
+
+func (q *consumerQueue) getDropped() int64 {
+	return atomic.LoadInt64(&q.dropped)
+}
+
+// run consumes the records until stopChan is closed.
+func (q *consumerQueue) run(stopChan <-chan bool) {
+	for {
+		select {
+		case record := <-q.queue:
+			_ = q.consumer.Consume(record)
+		case <-stopChan:
+			return
+		}
+	}
+}
+
+// consume delivers the record to all next consumers. The caller still owns the record.
+func (na *NetworkAnalyzer) consume(record *model.DataGroup) {
+	if na.consumerQueues == nil {
+		for _, nexConsumer := range na.nextConsumers {
+			_ = nexConsumer.Consume(record)
+		}
+		return
+	}
+	for _, queue := range na.consumerQueues {
+		queue.offer(record)
+	}
+}
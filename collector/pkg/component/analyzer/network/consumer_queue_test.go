@@ -0,0 +1,54 @@
+package network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+)
+
+type chanConsumer struct {
+	dataGroups chan *model.DataGroup
+}
+
+func (c *chanConsumer) Consume(dataGroup *model.DataGroup) error {
+	c.dataGroups <- dataGroup
+	return nil
+}
+
+func TestConsumerQueue(t *testing.T) {
+	stalled := &queueTimeConsumer{}
+	normal := &chanConsumer{dataGroups: make(chan *model.DataGroup, 10)}
+	na := &NetworkAnalyzer{
+		nextConsumers:  []consumer.Consumer{stalled, normal},
+		consumerQueues: []*consumerQueue{newConsumerQueue(stalled, 2), newConsumerQueue(normal, 10)},
+	}
+	stopChan := make(chan bool)
+	defer close(stopChan)
+	// The worker of the first queue is not started to simulate a stalled exporter.
+	go na.consumerQueues[1].run(stopChan)
+
+	record := model.NewDataGroup(constnames.NetRequestMetricGroupName, model.NewAttributeMap(), 0)
+	for i := 0; i < 5; i++ {
+		record.Labels.UpdateAddIntValue(constlabels.Pid, int64(i))
+		na.consume(record)
+	}
+	// The slow consumer doesn't block the others, and each one receives a copy of the record.
+	for i := 0; i < 5; i++ {
+		select {
+		case dataGroup := <-normal.dataGroups:
+			assert.Equal(t, int64(i), dataGroup.Labels.GetIntValue(constlabels.Pid))
+		case <-time.After(time.Second):
+			t.Fatal("the records are not delivered to the normal consumer")
+		}
+	}
+	assert.Equal(t, int64(3), na.consumerQueues[0].getDropped())
+	assert.Equal(t, int64(0), na.consumerQueues[1].getDropped())
+	assert.Empty(t, stalled.dataGroups)
+	assert.Equal(t, "*network.queueTimeConsumer", na.consumerQueues[0].name)
+}
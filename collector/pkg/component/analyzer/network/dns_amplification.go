@@ -0,0 +1,175 @@
+package network
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+// dnsRcodeNameError is the RCODE of NXDOMAIN.
+const dnsRcodeNameError = 3
+
+type dnsAmplificationKey struct {
+	pid   int64
+	srcIp string
+}
+
+type dnsAmplificationEntry struct {
+	record *model.DataGroup
+	// names are the logical names the domain may be expanded from.
+	names    []string
+	expireAt time.Time
+}
+
+// dnsAmplificationTracker detects the search-domain amplification of the resolvers. A name with fewer
+// dots than ndots is tried with each search domain appended before it is resolved, e.g. "redis" is sent
+// as "redis.<ns>.svc.cluster.local." and "api.example.com" fails as "api.example.com.<ns>.svc.cluster.local."
+// and the other search domains first. The NXDOMAIN queries of a process are held for the window and merged
+// into its next successful query of the same logical name, which is labeled with "amplification_count".
+// It is safe for concurrent use.
+type dnsAmplificationTracker struct {
+	// The labels of each search domain, in which "*" matches any label.
+	searchDomains [][]string
+	window        time.Duration
+
+	mutex    sync.Mutex
+	failures map[dnsAmplificationKey][]*dnsAmplificationEntry
+}
+
+func newDnsAmplificationTracker(searchDomains []string, window time.Duration) *dnsAmplificationTracker {
+	t := &dnsAmplificationTracker{
+		searchDomains: make([][]string, 0, len(searchDomains)),
+		window:        window,
+		failures:      make(map[dnsAmplificationKey][]*dnsAmplificationEntry),
+	}
+	for _, domain := range searchDomains {
+		domain = strings.Trim(domain, ".")
+		if domain == "" {
+			continue
+		}
+		t.searchDomains = append(t.searchDomains, strings.Split(domain, "."))
+	}
+	return t
+}
+
+// logicalNames returns the domain without the trailing dot and the names left by removing each
+// search domain the domain ends with.
+func (t *dnsAmplificationTracker) logicalNames(domain string) []string {
+	name := strings.TrimSuffix(domain, ".")
+	names := []string{name}
+	labels := strings.Split(name, ".")
+	for _, searchDomain := range t.searchDomains {
+		prefixLen := len(labels) - len(searchDomain)
+		if prefixLen <= 0 {
+			continue
+		}
+		matched := true
+		for i, label := range searchDomain {
+			if label != "*" && !strings.EqualFold(label, labels[prefixLen+i]) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			names = append(names, strings.Join(labels[:prefixLen], "."))
+		}
+	}
+	return names
+}
+
+func hasCommonName(names1 []string, names2 []string) bool {
+	for _, name1 := range names1 {
+		for _, name2 := range names2 {
+			if strings.EqualFold(name1, name2) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// add handles a DNS record and returns the records that are ready to be reported. The caller still owns
+// the record. The NXDOMAIN queries sent by the clients are held until a successful query merges them or
+// their window expires; the other records are returned as copies immediately.
+func (t *dnsAmplificationTracker) add(record *model.DataGroup, now time.Time) []*model.DataGroup {
+	labels := record.Labels
+	if labels.GetBoolValue(constlabels.IsServer) {
+		return []*model.DataGroup{record.Clone()}
+	}
+	key := dnsAmplificationKey{
+		pid:   labels.GetIntValue(constlabels.Pid),
+		srcIp: labels.GetStringValue(constlabels.SrcIp),
+	}
+	domain := labels.GetStringValue(constlabels.DnsDomain)
+	rcode := labels.GetIntValue(constlabels.DnsRcode)
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if rcode == dnsRcodeNameError {
+		// A retry of the same query replaces the held one, so it is not counted twice.
+		id := labels.GetIntValue(constlabels.DnsId)
+		for _, entry := range t.failures[key] {
+			if entry.record.Labels.GetIntValue(constlabels.DnsId) == id &&
+				entry.record.Labels.GetStringValue(constlabels.DnsDomain) == domain {
+				entry.record = record.Clone()
+				return nil
+			}
+		}
+		t.failures[key] = append(t.failures[key], &dnsAmplificationEntry{
+			record:   record.Clone(),
+			names:    t.logicalNames(domain),
+			expireAt: now.Add(t.window),
+		})
+		return nil
+	}
+
+	result := record.Clone()
+	var count int64
+	if rcode == 0 && !labels.GetBoolValue(constlabels.IsError) {
+		names := t.logicalNames(domain)
+		entries := t.failures[key]
+		remaining := entries[:0]
+		for _, entry := range entries {
+			if hasCommonName(entry.names, names) {
+				count++
+				continue
+			}
+			remaining = append(remaining, entry)
+		}
+		if len(remaining) == 0 {
+			delete(t.failures, key)
+		} else {
+			t.failures[key] = remaining
+		}
+	}
+	result.Labels.UpdateAddIntValue(constlabels.AmplificationCount, count)
+	return []*model.DataGroup{result}
+}
+
+// flush removes the NXDOMAIN queries whose window has expired and returns them. They are the
+// names that failed to resolve with all the search domains.
+func (t *dnsAmplificationTracker) flush(now time.Time) []*model.DataGroup {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	records := make([]*model.DataGroup, 0)
+	for key, entries := range t.failures {
+		remaining := entries[:0]
+		for _, entry := range entries {
+			if now.Before(entry.expireAt) {
+				remaining = append(remaining, entry)
+				continue
+			}
+			entry.record.Labels.UpdateAddIntValue(constlabels.AmplificationCount, 0)
+			records = append(records, entry.record)
+		}
+		if len(remaining) == 0 {
+			delete(t.failures, key)
+		} else {
+			t.failures[key] = remaining
+		}
+	}
+	return records
+}
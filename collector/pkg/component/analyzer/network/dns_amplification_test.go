@@ -0,0 +1,67 @@
+package network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+func newAmplifiedDnsRecord(id int64, domain string, rcode int64) *model.DataGroup {
+	record := newDnsRecord(id, domain, "10.96.0.10", rcode != 0)
+	record.Labels.AddIntValue(constlabels.DnsRcode, rcode)
+	return record
+}
+
+func TestDnsAmplificationTracker_Merge(t *testing.T) {
+	now := time.Now()
+	tracker := newDnsAmplificationTracker([]string{"*.svc.cluster.local", "svc.cluster.local", "cluster.local"}, time.Second)
+
+	assert.Empty(t, tracker.add(newAmplifiedDnsRecord(1, "api.example.com.default.svc.cluster.local.", 3), now))
+	assert.Empty(t, tracker.add(newAmplifiedDnsRecord(2, "api.example.com.svc.cluster.local.", 3), now))
+	// The retry of the same query is not counted twice.
+	assert.Empty(t, tracker.add(newAmplifiedDnsRecord(2, "api.example.com.svc.cluster.local.", 3), now))
+	assert.Empty(t, tracker.add(newAmplifiedDnsRecord(3, "api.example.com.cluster.local.", 3), now))
+	// The failure of another name is not merged.
+	assert.Empty(t, tracker.add(newAmplifiedDnsRecord(4, "missing.default.svc.cluster.local.", 3), now))
+
+	records := tracker.add(newAmplifiedDnsRecord(5, "api.example.com.", 0), now)
+	if assert.Len(t, records, 1) {
+		assert.Equal(t, "api.example.com.", records[0].Labels.GetStringValue(constlabels.DnsDomain))
+		assert.Equal(t, int64(3), records[0].Labels.GetIntValue(constlabels.AmplificationCount))
+	}
+
+	records = tracker.flush(now.Add(time.Second))
+	if assert.Len(t, records, 1) {
+		assert.Equal(t, "missing.default.svc.cluster.local.", records[0].Labels.GetStringValue(constlabels.DnsDomain))
+		assert.Equal(t, int64(0), records[0].Labels.GetIntValue(constlabels.AmplificationCount))
+	}
+	assert.Empty(t, tracker.flush(now.Add(2*time.Second)))
+}
+
+func TestDnsAmplificationTracker_ResolvedBySearchDomain(t *testing.T) {
+	now := time.Now()
+	tracker := newDnsAmplificationTracker([]string{"*.svc.cluster.local", "svc.cluster.local", "cluster.local"}, time.Second)
+
+	assert.Empty(t, tracker.add(newAmplifiedDnsRecord(1, "kube-dns.kube-system.default.svc.cluster.local.", 3), now))
+	records := tracker.add(newAmplifiedDnsRecord(2, "kube-dns.kube-system.svc.cluster.local.", 0), now)
+	if assert.Len(t, records, 1) {
+		assert.Equal(t, int64(1), records[0].Labels.GetIntValue(constlabels.AmplificationCount))
+	}
+	assert.Empty(t, tracker.flush(now.Add(time.Second)))
+}
+
+func TestDnsAmplificationTracker_ServerSide(t *testing.T) {
+	now := time.Now()
+	tracker := newDnsAmplificationTracker([]string{"cluster.local"}, time.Second)
+
+	record := newAmplifiedDnsRecord(1, "redis.cluster.local.", 3)
+	record.Labels.AddBoolValue(constlabels.IsServer, true)
+	records := tracker.add(record, now)
+	if assert.Len(t, records, 1) {
+		assert.False(t, records[0].Labels.HasAttribute(constlabels.AmplificationCount))
+	}
+}
@@ -0,0 +1,102 @@
+package network
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constvalues"
+)
+
+type dnsResolutionKey struct {
+	pid int64
+	ip  string
+}
+
+type dnsResolution struct {
+	duration int64
+	endTs    uint64
+}
+
+// dnsResolutionTracker remembers the latest resolutions of each process, so the time spent on
+// resolving the domain can be attributed to the first request the process sends to the resolved
+// IP within the window. It is safe for concurrent use.
+type dnsResolutionTracker struct {
+	// The unit is nanosecond.
+	window      uint64
+	mutex       sync.Mutex
+	resolutions map[dnsResolutionKey]dnsResolution
+}
+
+func newDnsResolutionTracker(window uint64) *dnsResolutionTracker {
+	return &dnsResolutionTracker{
+		window:      window,
+		resolutions: make(map[dnsResolutionKey]dnsResolution),
+	}
+}
+
+// record stores the resolution for each address of the answers if the record is a successful DNS query
+// with A or AAAA answers.
+func (t *dnsResolutionTracker) record(record *model.DataGroup) {
+	labels := record.Labels
+	ips := labels.GetStringValue(constlabels.DnsIp)
+	if ips == "" || labels.GetBoolValue(constlabels.IsError) || labels.GetBoolValue(constlabels.IsServer) {
+		return
+	}
+	var duration int64
+	if metric, ok := record.GetMetric(constvalues.RequestTotalTime); ok {
+		duration = metric.GetInt().Value
+	}
+	pid := labels.GetIntValue(constlabels.Pid)
+	resolution := dnsResolution{duration: duration, endTs: uint64(labels.GetIntValue(constlabels.EndTimestamp))}
+	t.mutex.Lock()
+	for _, ip := range strings.Split(ips, ",") {
+		t.resolutions[dnsResolutionKey{pid: pid, ip: ip}] = resolution
+	}
+	t.mutex.Unlock()
+}
+
+// attribute adds the label "dns_time" to the client request if it is the first one sent to the
+// resolved IP within the window after the resolution. Each resolution is attributed only once.
+func (t *dnsResolutionTracker) attribute(record *model.DataGroup) {
+	labels := record.Labels
+	if labels.GetBoolValue(constlabels.IsServer) {
+		return
+	}
+	key := dnsResolutionKey{pid: labels.GetIntValue(constlabels.Pid), ip: labels.GetStringValue(constlabels.DstIp)}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	resolution, ok := t.resolutions[key]
+	if !ok || record.Timestamp < resolution.endTs {
+		return
+	}
+	delete(t.resolutions, key)
+	if record.Timestamp-resolution.endTs <= t.window {
+		labels.UpdateAddIntValue(constlabels.DnsTime, resolution.duration)
+	}
+}
+
+// clean removes the resolutions whose window has expired.
+func (t *dnsResolutionTracker) clean(nowTs uint64) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	for key, resolution := range t.resolutions {
+		if nowTs > resolution.endTs+t.window {
+			delete(t.resolutions, key)
+		}
+	}
+}
+
+// attributeDnsTime records the DNS resolutions and attributes them to the subsequent requests.
+func (na *NetworkAnalyzer) attributeDnsTime(record *model.DataGroup) {
+	if na.dnsResolutionTracker == nil {
+		return
+	}
+	if record.Labels.GetStringValue(constlabels.Protocol) == protocol.DNS {
+		na.dnsResolutionTracker.record(record)
+	} else {
+		na.dnsResolutionTracker.attribute(record)
+	}
+}
@@ -0,0 +1,75 @@
+package network
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constvalues"
+)
+
+func newResolutionRecord(pid int64, ip string, start uint64, end uint64) *model.DataGroup {
+	labels := model.NewAttributeMap()
+	labels.AddIntValue(constlabels.Pid, pid)
+	labels.AddStringValue(constlabels.Protocol, protocol.DNS)
+	labels.AddStringValue(constlabels.DnsIp, ip)
+	labels.AddIntValue(constlabels.EndTimestamp, int64(end))
+	return model.NewDataGroup(constnames.NetRequestMetricGroupName, labels, start,
+		model.NewIntMetric(constvalues.RequestTotalTime, int64(end-start)))
+}
+
+func newRequestRecord(pid int64, dstIp string, start uint64) *model.DataGroup {
+	labels := model.NewAttributeMap()
+	labels.AddIntValue(constlabels.Pid, pid)
+	labels.AddStringValue(constlabels.Protocol, protocol.HTTP)
+	labels.AddStringValue(constlabels.DstIp, dstIp)
+	return model.NewDataGroup(constnames.NetRequestMetricGroupName, labels, start)
+}
+
+func TestDnsResolutionTracker(t *testing.T) {
+	na := &NetworkAnalyzer{dnsResolutionTracker: newDnsResolutionTracker(1000)}
+	na.attributeDnsTime(newResolutionRecord(100, "10.0.0.2", 1000, 1300))
+
+	// The requests of other processes or to other IPs are not attributed.
+	for _, record := range []*model.DataGroup{newRequestRecord(101, "10.0.0.2", 1500), newRequestRecord(100, "10.0.0.3", 1500)} {
+		na.attributeDnsTime(record)
+		assert.False(t, record.Labels.HasAttribute(constlabels.DnsTime))
+	}
+
+	first := newRequestRecord(100, "10.0.0.2", 1500)
+	na.attributeDnsTime(first)
+	assert.Equal(t, int64(300), first.Labels.GetIntValue(constlabels.DnsTime))
+	// Only the first request is attributed.
+	second := newRequestRecord(100, "10.0.0.2", 1600)
+	na.attributeDnsTime(second)
+	assert.False(t, second.Labels.HasAttribute(constlabels.DnsTime))
+
+	// The request out of the window is not attributed.
+	na.attributeDnsTime(newResolutionRecord(100, "10.0.0.2", 5000, 5300))
+	late := newRequestRecord(100, "10.0.0.2", 6400)
+	na.attributeDnsTime(late)
+	assert.False(t, late.Labels.HasAttribute(constlabels.DnsTime))
+	assert.Empty(t, na.dnsResolutionTracker.resolutions)
+}
+
+func TestDnsResolutionTracker_Clean(t *testing.T) {
+	tracker := newDnsResolutionTracker(1000)
+	tracker.record(newResolutionRecord(100, "10.0.0.2", 1000, 1300))
+	failed := newResolutionRecord(100, "10.0.0.3", 1000, 1300)
+	failed.Labels.AddBoolValue(constlabels.IsError, true)
+	tracker.record(failed)
+	assert.Len(t, tracker.resolutions, 1)
+
+	tracker.clean(2300)
+	assert.Len(t, tracker.resolutions, 1)
+	tracker.clean(2301)
+	assert.Empty(t, tracker.resolutions)
+
+	// Each address of the answers is recorded.
+	tracker.record(newResolutionRecord(100, "10.0.0.4,2001:db8::1", 3000, 3300))
+	assert.Len(t, tracker.resolutions, 2)
+}
@@ -0,0 +1,92 @@
+package network
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+// dnsDedupKey identifies the attempts of one DNS query. The resolver of glibc sends the same query
+// with the same id to multiple nameservers, so the destination is not a part of the key.
+type dnsDedupKey struct {
+	pid    int64
+	srcIp  string
+	id     int64
+	domain string
+}
+
+type dnsDedupEntry struct {
+	record   *model.DataGroup
+	attempts int64
+	expireAt time.Time
+}
+
+// dnsDeduplicator collapses the records of the identical DNS queries emitted within a window
+// into one record with the label "dns_attempts". It is safe for concurrent use.
+type dnsDeduplicator struct {
+	window  time.Duration
+	mutex   sync.Mutex
+	entries map[dnsDedupKey]*dnsDedupEntry
+}
+
+func newDnsDeduplicator(window time.Duration) *dnsDeduplicator {
+	return &dnsDeduplicator{
+		window:  window,
+		entries: make(map[dnsDedupKey]*dnsDedupEntry),
+	}
+}
+
+func isDnsRecord(record *model.DataGroup) bool {
+	return record.Labels.GetStringValue(constlabels.Protocol) == protocol.DNS &&
+		record.Labels.HasAttribute(constlabels.DnsId)
+}
+
+func getDnsDedupKey(record *model.DataGroup) dnsDedupKey {
+	labels := record.Labels
+	return dnsDedupKey{
+		pid:    labels.GetIntValue(constlabels.Pid),
+		srcIp:  labels.GetStringValue(constlabels.SrcIp),
+		id:     labels.GetIntValue(constlabels.DnsId),
+		domain: labels.GetStringValue(constlabels.DnsDomain),
+	}
+}
+
+// add holds a copy of the record until the window of its query expires. The caller still owns the record.
+// The first successful attempt is kept as the result of the query; the latest attempt is kept if all of them failed.
+func (d *dnsDeduplicator) add(record *model.DataGroup, now time.Time) {
+	key := getDnsDedupKey(record)
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	entry, ok := d.entries[key]
+	if !ok {
+		d.entries[key] = &dnsDedupEntry{
+			record:   record.Clone(),
+			attempts: 1,
+			expireAt: now.Add(d.window),
+		}
+		return
+	}
+	entry.attempts++
+	if entry.record.Labels.GetBoolValue(constlabels.IsError) {
+		entry.record = record.Clone()
+	}
+}
+
+// flush removes the entries whose window has expired and returns their records.
+func (d *dnsDeduplicator) flush(now time.Time) []*model.DataGroup {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	records := make([]*model.DataGroup, 0)
+	for key, entry := range d.entries {
+		if now.Before(entry.expireAt) {
+			continue
+		}
+		entry.record.Labels.UpdateAddIntValue(constlabels.DnsAttempts, entry.attempts)
+		records = append(records, entry.record)
+		delete(d.entries, key)
+	}
+	return records
+}
@@ -0,0 +1,65 @@
+package network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+)
+
+func newDnsRecord(id int64, domain string, dstIp string, isError bool) *model.DataGroup {
+	labels := model.NewAttributeMap()
+	labels.AddIntValue(constlabels.Pid, 100)
+	labels.AddStringValue(constlabels.SrcIp, "10.0.0.1")
+	labels.AddStringValue(constlabels.DstIp, dstIp)
+	labels.AddStringValue(constlabels.Protocol, protocol.DNS)
+	labels.AddIntValue(constlabels.DnsId, id)
+	labels.AddStringValue(constlabels.DnsDomain, domain)
+	labels.AddBoolValue(constlabels.IsError, isError)
+	return model.NewDataGroup(constnames.NetRequestMetricGroupName, labels, 0)
+}
+
+func TestDnsDeduplicator(t *testing.T) {
+	now := time.Now()
+	window := 5 * time.Second
+	d := newDnsDeduplicator(window)
+
+	// Retries over two nameservers: the first one times out and the second one succeeds.
+	d.add(newDnsRecord(1, "kindling.io.", "10.0.0.53", true), now)
+	d.add(newDnsRecord(1, "kindling.io.", "10.0.0.54", false), now.Add(time.Second))
+	// Another query is not collapsed.
+	d.add(newDnsRecord(2, "kindling.io.", "10.0.0.53", false), now.Add(time.Second))
+
+	assert.Empty(t, d.flush(now.Add(window-time.Millisecond)))
+
+	records := d.flush(now.Add(window + time.Second))
+	assert.Len(t, records, 2)
+	attempts := make(map[int64]*model.DataGroup)
+	for _, record := range records {
+		attempts[record.Labels.GetIntValue(constlabels.DnsAttempts)] = record
+	}
+	collapsed, ok := attempts[2]
+	if assert.True(t, ok) {
+		assert.Equal(t, int64(1), collapsed.Labels.GetIntValue(constlabels.DnsId))
+		assert.Equal(t, "10.0.0.54", collapsed.Labels.GetStringValue(constlabels.DstIp))
+		assert.False(t, collapsed.Labels.GetBoolValue(constlabels.IsError))
+	}
+	single, ok := attempts[1]
+	if assert.True(t, ok) {
+		assert.Equal(t, int64(2), single.Labels.GetIntValue(constlabels.DnsId))
+	}
+	assert.Empty(t, d.flush(now.Add(2*window)))
+}
+
+func TestIsDnsRecord(t *testing.T) {
+	assert.True(t, isDnsRecord(newDnsRecord(1, "kindling.io.", "10.0.0.53", false)))
+
+	labels := model.NewAttributeMap()
+	labels.AddStringValue(constlabels.Protocol, protocol.HTTP)
+	assert.False(t, isDnsRecord(model.NewDataGroup(constnames.NetRequestMetricGroupName, labels, 0)))
+}
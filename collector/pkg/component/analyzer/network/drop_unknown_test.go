@@ -0,0 +1,50 @@
+package network
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/factory"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+)
+
+func TestParseProtocols_DropUnknownPorts(t *testing.T) {
+	cfg := NewDefaultConfig()
+	cfg.DropUnknownPorts = []uint32{8080}
+	na := &NetworkAnalyzer{
+		cfg:               cfg,
+		dataGroupPool:     &NoCacheDataGroupPool{},
+		parserFactory:     factory.NewParserFactory(),
+		parserCostSampler: analyzer.NewCostSampler(analyzer.DefaultCostSampleRate),
+		dropUnknownPorts:  map[uint32]bool{8080: true},
+	}
+	na.parsers = []*protocol.ProtocolParser{na.parserFactory.GetGenericParser()}
+	newMessagePairs := func(dport uint32) *messagePairs {
+		newEvent := func(name string, timestamp uint64, data string) *model.KindlingEvent {
+			evt := newServerEvent(name, 5, timestamp, 10)
+			evt.Ctx.FdInfo.Dport = dport
+			evt.UserAttributes[0] = model.KeyValue{Key: "res", ValueType: model.ValueType_INT64, Value: Int64ToBytes(int64(len(data)))}
+			evt.UserAttributes[1] = model.KeyValue{Key: "data", ValueType: model.ValueType_BYTEBUF, Value: []byte(data)}
+			evt.ParamsNumber = 2
+			return evt
+		}
+		return &messagePairs{
+			requests:  newEvents(newEvent(constnames.ReadEvent, 1000, "\x16\x03\x01"), 1000),
+			responses: newEvents(newEvent(constnames.WriteEvent, 2000, "\x16\x03\x03"), 1000),
+		}
+	}
+
+	// Both the first classification and the one cached for the connection are dropped.
+	assert.Empty(t, na.parseProtocols(newMessagePairs(8080)))
+	assert.Empty(t, na.parseProtocols(newMessagePairs(8080)))
+
+	records := na.parseProtocols(newMessagePairs(9090))
+	if assert.Len(t, records, 1) {
+		assert.Equal(t, protocol.NOSUPPORT, records[0].Labels.GetStringValue(constlabels.Protocol))
+	}
+}
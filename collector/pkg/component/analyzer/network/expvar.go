@@ -0,0 +1,55 @@
+package network
+
+import (
+	"expvar"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const expvarName = "networkanalyzer"
+
+var expvarOnce sync.Once
+
+// publishExpvar publishes the internal state of the analyzer, which is served at "/debug/vars" if the
+// debug module of the controller is enabled. The state is only collected when the variable is read.
+func publishExpvar(na *NetworkAnalyzer) {
+	expvarOnce.Do(func() {
+		expvar.Publish(expvarName, expvar.Func(na.getExpvar))
+	})
+}
+
+func (na *NetworkAnalyzer) getExpvar() interface{} {
+	var connectionCount int
+	na.connectionProtocols.Range(func(k, v interface{}) bool {
+		connectionCount++
+		return true
+	})
+	// The keys of JSON objects must be strings.
+	cachedProtocols := make(map[string][]string)
+	if na.parserFactory != nil {
+		for port, protocols := range na.parserFactory.GetCachedProtocols() {
+			cachedProtocols[strconv.FormatUint(uint64(port), 10)] = protocols
+		}
+	}
+	consumerQueues := make(map[string]int, len(na.consumerQueues))
+	for _, queue := range na.consumerQueues {
+		consumerQueues[queue.name] = len(queue.queue)
+	}
+	vars := map[string]interface{}{
+		"tcp_message_pair_size":  atomic.LoadInt64(&na.tcpMessagePairSize),
+		"udp_message_pair_size":  atomic.LoadInt64(&na.udpMessagePairSize),
+		"event_channel_size":     len(na.eventChan),
+		"event_channel_capacity": cap(na.eventChan),
+		"connection_protocols":   connectionCount,
+		"cached_port_protocols":  cachedProtocols,
+		"consumer_queue_sizes":   consumerQueues,
+	}
+	na.protocolMutex.RLock()
+	if na.parserTiers != nil {
+		vars["cold_parsers"] = na.parserTiers.getColdProtocols(time.Now())
+	}
+	na.protocolMutex.RUnlock()
+	return vars
+}
@@ -0,0 +1,38 @@
+package network
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/factory"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+)
+
+func TestGetExpvar(t *testing.T) {
+	na := &NetworkAnalyzer{
+		parserFactory:      factory.NewParserFactory(),
+		eventChan:          make(chan *model.KindlingEvent, 10),
+		tcpMessagePairSize: 3,
+	}
+	na.eventChan <- &model.KindlingEvent{}
+	na.parserFactory.AddCachedParser(3306, na.parserFactory.GetParser(protocol.MYSQL))
+	na.setConnectionParser(connectionKey{pid: 1, fd: 3}, na.parserFactory.GetParser(protocol.MYSQL), time.Now())
+	na.parserTiers = newParserTiers([]*protocol.ProtocolParser{na.parserFactory.GetParser(protocol.HTTP)}, time.Second, 10, time.Unix(0, 0))
+
+	data, err := json.Marshal(na.getExpvar())
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"tcp_message_pair_size": 3,
+		"udp_message_pair_size": 0,
+		"event_channel_size": 1,
+		"event_channel_capacity": 10,
+		"connection_protocols": 1,
+		"cached_port_protocols": {"3306": ["mysql"]},
+		"consumer_queue_sizes": {},
+		"cold_parsers": ["http"]
+	}`, string(data))
+}
@@ -0,0 +1,116 @@
+package network
+
+import (
+	"sync"
+
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constvalues"
+)
+
+// outboundCall is a request a process sent as a client.
+type outboundCall struct {
+	startTs uint64
+	endTs   uint64
+	tid     int64
+	latency int64
+}
+
+// fanOutTracker remembers the outbound requests of each process, so the inbound request can be
+// labeled with the number and the total latency of the outbound requests sent while it was handled.
+// It approximates the local call tree: the outbound requests of the concurrent inbound requests
+// of a process are counted for all of them unless the threads are matched. It is safe for concurrent use.
+type fanOutTracker struct {
+	// matchThread only counts the outbound requests sent by the threads reading or writing the inbound request.
+	matchThread bool
+	// The unit is nanosecond.
+	window uint64
+	mutex  sync.Mutex
+	calls  map[int64][]outboundCall
+}
+
+func newFanOutTracker(matchThread bool, window uint64) *fanOutTracker {
+	return &fanOutTracker{
+		matchThread: matchThread,
+		window:      window,
+		calls:       make(map[int64][]outboundCall),
+	}
+}
+
+// record stores the outbound request.
+func (t *fanOutTracker) record(record *model.DataGroup) {
+	labels := record.Labels
+	var latency int64
+	if metric, ok := record.GetMetric(constvalues.RequestTotalTime); ok {
+		latency = metric.GetInt().Value
+	}
+	call := outboundCall{
+		startTs: record.Timestamp,
+		endTs:   uint64(labels.GetIntValue(constlabels.EndTimestamp)),
+		tid:     labels.GetIntValue(constlabels.RequestTid),
+		latency: latency,
+	}
+	pid := labels.GetIntValue(constlabels.Pid)
+	t.mutex.Lock()
+	t.calls[pid] = append(t.calls[pid], call)
+	t.mutex.Unlock()
+}
+
+// attribute adds the labels "fan_out_count" and "fan_out_latency_sum" to the inbound request. The outbound
+// requests are counted if they start and end while the inbound request is handled.
+func (t *fanOutTracker) attribute(record *model.DataGroup) {
+	labels := record.Labels
+	startTs := record.Timestamp
+	endTs := uint64(labels.GetIntValue(constlabels.EndTimestamp))
+	requestTid := labels.GetIntValue(constlabels.RequestTid)
+	responseTid := labels.GetIntValue(constlabels.ResponseTid)
+	var (
+		count      int64
+		latencySum int64
+	)
+	t.mutex.Lock()
+	for _, call := range t.calls[labels.GetIntValue(constlabels.Pid)] {
+		if call.startTs < startTs || call.endTs > endTs {
+			continue
+		}
+		if t.matchThread && call.tid != requestTid && call.tid != responseTid {
+			continue
+		}
+		count++
+		latencySum += call.latency
+	}
+	t.mutex.Unlock()
+	labels.UpdateAddIntValue(constlabels.FanOutCount, count)
+	labels.UpdateAddIntValue(constlabels.FanOutLatencySum, latencySum)
+}
+
+// clean removes the outbound requests ended before the window.
+func (t *fanOutTracker) clean(nowTs uint64) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	for pid, calls := range t.calls {
+		remaining := calls[:0]
+		for _, call := range calls {
+			if call.endTs+t.window >= nowTs {
+				remaining = append(remaining, call)
+			}
+		}
+		if len(remaining) == 0 {
+			delete(t.calls, pid)
+		} else {
+			t.calls[pid] = remaining
+		}
+	}
+}
+
+// trackFanOut records the outbound requests and attributes them to the inbound requests.
+func (na *NetworkAnalyzer) trackFanOut(record *model.DataGroup) {
+	if na.fanOutTracker == nil {
+		return
+	}
+	if record.Labels.GetBoolValue(constlabels.IsServer) {
+		na.fanOutTracker.attribute(record)
+	} else {
+		na.fanOutTracker.record(record)
+	}
+}
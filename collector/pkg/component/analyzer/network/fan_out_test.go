@@ -0,0 +1,55 @@
+package network
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constvalues"
+)
+
+func newFanOutRecord(isServer bool, tid int64, start uint64, end uint64) *model.DataGroup {
+	labels := model.NewAttributeMap()
+	labels.AddIntValue(constlabels.Pid, 100)
+	labels.AddIntValue(constlabels.RequestTid, tid)
+	labels.AddIntValue(constlabels.ResponseTid, tid)
+	labels.AddBoolValue(constlabels.IsServer, isServer)
+	labels.AddStringValue(constlabels.Protocol, protocol.HTTP)
+	labels.AddIntValue(constlabels.EndTimestamp, int64(end))
+	return model.NewDataGroup(constnames.NetRequestMetricGroupName, labels, start,
+		model.NewIntMetric(constvalues.RequestTotalTime, int64(end-start)))
+}
+
+func TestFanOutTracker(t *testing.T) {
+	na := &NetworkAnalyzer{fanOutTracker: newFanOutTracker(false, 1000)}
+	na.trackFanOut(newFanOutRecord(false, 11, 1100, 1300))
+	na.trackFanOut(newFanOutRecord(false, 12, 1400, 1500))
+	// The outbound request ending after the inbound request is not counted.
+	na.trackFanOut(newFanOutRecord(false, 11, 1900, 2100))
+
+	inbound := newFanOutRecord(true, 11, 1000, 2000)
+	na.trackFanOut(inbound)
+	assert.Equal(t, int64(2), inbound.Labels.GetIntValue(constlabels.FanOutCount))
+	assert.Equal(t, int64(300), inbound.Labels.GetIntValue(constlabels.FanOutLatencySum))
+
+	// The outbound requests are cleaned after the window.
+	na.fanOutTracker.clean(2600)
+	assert.Len(t, na.fanOutTracker.calls[100], 1)
+	na.fanOutTracker.clean(3101)
+	assert.Empty(t, na.fanOutTracker.calls)
+}
+
+func TestFanOutTracker_MatchThread(t *testing.T) {
+	na := &NetworkAnalyzer{fanOutTracker: newFanOutTracker(true, 1000)}
+	na.trackFanOut(newFanOutRecord(false, 11, 1100, 1300))
+	na.trackFanOut(newFanOutRecord(false, 12, 1400, 1500))
+
+	inbound := newFanOutRecord(true, 11, 1000, 2000)
+	na.trackFanOut(inbound)
+	assert.Equal(t, int64(1), inbound.Labels.GetIntValue(constlabels.FanOutCount))
+	assert.Equal(t, int64(200), inbound.Labels.GetIntValue(constlabels.FanOutLatencySum))
+}
@@ -0,0 +1,108 @@
+package network
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+)
+
+// fdGeneration is the connection currently on the fd. It is replaced rather than modified when the
+// fd is reused, so only lastTs and closed are accessed concurrently.
+type fdGeneration struct {
+	generation uint32
+	tuple      socketTuple
+	// lastTs is the timestamp of the latest event on the fd.
+	lastTs int64
+	// closed is set to 1 when the connection is closed, after which the next event on the fd starts
+	// a new generation even if the ports are the same.
+	closed int32
+}
+
+// socketTuple is the 4-tuple of the connection in the process. The tuple of the fd is from the client
+// to the server, while that of tcp_close is from the local side to the remote side.
+type socketTuple struct {
+	pid   uint32
+	sip   uint32
+	sport uint32
+	dip   uint32
+	dport uint32
+}
+
+func getSocketTuple(evt *model.KindlingEvent) socketTuple {
+	fdInfo := evt.GetCtx().GetFdInfo()
+	key := socketTuple{pid: evt.GetPid(), sport: fdInfo.GetSport(), dport: fdInfo.GetDport()}
+	// TODO Only support IPV4, may support IPV6 in future.
+	if len(fdInfo.GetSip()) > 0 && len(fdInfo.GetDip()) > 0 {
+		key.sip, key.dip = fdInfo.GetSip()[0], fdInfo.GetDip()[0]
+	}
+	return key
+}
+
+// getGeneration returns the generation of the connection the event belongs to. The fd could be closed
+// and reused by another connection immediately on a busy server, so a new generation is assigned when
+// the fd is accepted again, the connection is closed or its ports change. The message pairs of the
+// previous generation are flushed at that time instead of being mixed with the events of the new connection.
+func (na *NetworkAnalyzer) getGeneration(evt *model.KindlingEvent, accepted bool) uint32 {
+	key := getMessagePairKey(evt)
+	tuple := getSocketTuple(evt)
+	var generation uint32
+	if value, ok := na.fdGenerations.Load(key); ok {
+		current := value.(*fdGeneration)
+		if !accepted && atomic.LoadInt32(&current.closed) == 0 &&
+			current.tuple.sport == tuple.sport && current.tuple.dport == tuple.dport {
+			atomic.StoreInt64(&current.lastTs, int64(evt.Timestamp))
+			return current.generation
+		}
+		na.flushGeneration(key, current.generation)
+		na.tupleFds.Delete(current.tuple)
+		generation = current.generation + 1
+	}
+	na.fdGenerations.Store(key, &fdGeneration{generation: generation, tuple: tuple, lastTs: int64(evt.Timestamp)})
+	na.tupleFds.Store(tuple, key)
+	return generation
+}
+
+// closeGeneration marks the connection closed by the tcp_close event, which carries the tuple of the
+// connection instead of the fd. The pairs are flushed when the next connection on the fd starts, as the
+// events of the closed connection read on other CPUs may arrive later.
+func (na *NetworkAnalyzer) closeGeneration(evt *model.KindlingEvent) {
+	local := socketTuple{
+		pid:   evt.GetPid(),
+		sip:   uint32(evt.GetUintUserAttribute("sip")),
+		sport: uint32(evt.GetUintUserAttribute("sport")),
+		dip:   uint32(evt.GetUintUserAttribute("dip")),
+		dport: uint32(evt.GetUintUserAttribute("dport")),
+	}
+	remote := socketTuple{pid: local.pid, sip: local.dip, sport: local.dport, dip: local.sip, dport: local.sport}
+	for _, tuple := range []socketTuple{local, remote} {
+		key, ok := na.tupleFds.LoadAndDelete(tuple)
+		if !ok {
+			continue
+		}
+		if value, ok := na.fdGenerations.Load(key); ok && value.(*fdGeneration).tuple == tuple {
+			atomic.StoreInt32(&value.(*fdGeneration).closed, 1)
+		}
+	}
+}
+
+// flushGeneration distributes the message pairs of the connection that has left the fd.
+func (na *NetworkAnalyzer) flushGeneration(key messagePairKey, generation uint32) {
+	key.generation = generation
+	if pairInterface, ok := na.requestMonitor.Load(key); ok {
+		_ = na.distributeTraceMetric(pairInterface.(*messagePairs), nil)
+	}
+}
+
+// cleanFdGenerations removes the fds without any event after all of their message pairs are flushed
+// by the timeout check.
+func (na *NetworkAnalyzer) cleanFdGenerations(now time.Time) {
+	threshold := int64(na.getMaxNoResponseThreshold()+na.cfg.GetFdReuseTimeout()) * int64(time.Second)
+	na.fdGenerations.Range(func(k, v interface{}) bool {
+		if now.UnixNano()-atomic.LoadInt64(&v.(*fdGeneration).lastTs) >= threshold {
+			na.fdGenerations.Delete(k)
+			na.tupleFds.Delete(v.(*fdGeneration).tuple)
+		}
+		return true
+	})
+}
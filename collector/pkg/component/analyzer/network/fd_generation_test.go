@@ -0,0 +1,109 @@
+package network
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/factory"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+)
+
+func newFdGenerationAnalyzer(c consumer.Consumer) *NetworkAnalyzer {
+	cfg := NewDefaultConfig()
+	cfg.EnableConntrack = false
+	na := &NetworkAnalyzer{
+		cfg:               cfg,
+		nextConsumers:     []consumer.Consumer{c},
+		dataGroupPool:     &NoCacheDataGroupPool{},
+		parserFactory:     factory.NewParserFactory(),
+		parserCostSampler: analyzer.NewCostSampler(analyzer.DefaultCostSampleRate),
+		telemetry:         component.NewDefaultTelemetryTools(),
+	}
+	newSelfMetrics(na.telemetry.MeterProvider, na)
+	na.parsers = append(na.parsers, na.parserFactory.GetGenericParser())
+	return na
+}
+
+func newFdGenerationEvent(name string, sport uint32, timestamp uint64) *model.KindlingEvent {
+	evt := newServerEvent(name, 5, timestamp, 10)
+	evt.Ctx.FdInfo.Sport = sport
+	evt.UserAttributes[0] = model.KeyValue{Key: "res", ValueType: model.ValueType_INT64, Value: Int64ToBytes(4)}
+	evt.UserAttributes[1] = model.KeyValue{Key: "data", ValueType: model.ValueType_BYTEBUF, Value: []byte("ping")}
+	evt.ParamsNumber = 2
+	return evt
+}
+
+func TestFdGeneration(t *testing.T) {
+	c := &queueTimeConsumer{}
+	na := newFdGenerationAnalyzer(c)
+	newEvent := newFdGenerationEvent
+
+	assert.NoError(t, na.processEvent(newEvent(constnames.ReadEvent, 40000, 1000)))
+	// The fd is closed without any response and reused by another connection immediately.
+	assert.NoError(t, na.processEvent(newEvent(constnames.ReadEvent, 40001, 5000)))
+	if assert.Len(t, c.dataGroups, 1) {
+		assert.Equal(t, int64(40000), c.dataGroups[0].Labels.GetIntValue(constlabels.SrcPort))
+		assert.Equal(t, int64(constlabels.NoResponse), c.dataGroups[0].Labels.GetIntValue(constlabels.ErrorType))
+	}
+	assert.NoError(t, na.processEvent(newEvent(constnames.WriteEvent, 40001, 6000)))
+	assert.Len(t, c.dataGroups, 1)
+
+	// The accepted fd starts a new generation even if the ports are the same.
+	na.recordAccept(newEvent(constnames.Accept4Event, 40001, 8000))
+	if assert.Len(t, c.dataGroups, 2) {
+		assert.Equal(t, int64(40001), c.dataGroups[1].Labels.GetIntValue(constlabels.SrcPort))
+		assert.False(t, c.dataGroups[1].Labels.GetBoolValue(constlabels.IsError))
+	}
+	assert.Equal(t, uint32(2), na.getGeneration(newEvent(constnames.ReadEvent, 40001, 9000), false))
+
+	na.cleanFdGenerations(time.Unix(0, 9000).Add(time.Duration(na.getMaxNoResponseThreshold()+na.cfg.GetFdReuseTimeout()) * time.Second))
+	_, ok := na.fdGenerations.Load(messagePairKey{pid: 100, fd: 5})
+	assert.False(t, ok)
+}
+
+func TestFdGeneration_CloseThenReuse(t *testing.T) {
+	c := &queueTimeConsumer{}
+	na := newFdGenerationAnalyzer(c)
+	// The tcp_close event carries the tuple from the local side, which is the server.
+	closeEvent := &model.KindlingEvent{
+		Name:      constnames.TcpCloseEvent,
+		Timestamp: 3000,
+		Ctx:       model.Context{ThreadInfo: model.Thread{Pid: 100, Tid: 101}},
+		UserAttributes: [16]model.KeyValue{
+			{Key: "sip", ValueType: model.ValueType_UINT32, Value: uint32ToBytes(33554442)},
+			{Key: "sport", ValueType: model.ValueType_UINT32, Value: uint32ToBytes(8080)},
+			{Key: "dip", ValueType: model.ValueType_UINT32, Value: uint32ToBytes(16777226)},
+			{Key: "dport", ValueType: model.ValueType_UINT32, Value: uint32ToBytes(40000)},
+		},
+		ParamsNumber: 4,
+	}
+
+	assert.NoError(t, na.processEvent(newFdGenerationEvent(constnames.ReadEvent, 40000, 1000)))
+	assert.NoError(t, na.processEvent(closeEvent))
+	assert.Empty(t, c.dataGroups)
+	// The client reconnects from the same port and the server gets the same fd.
+	assert.NoError(t, na.processEvent(newFdGenerationEvent(constnames.ReadEvent, 40000, 5000)))
+	if assert.Len(t, c.dataGroups, 1) {
+		assert.Equal(t, int64(constlabels.NoResponse), c.dataGroups[0].Labels.GetIntValue(constlabels.ErrorType))
+	}
+	assert.Equal(t, uint32(1), na.getGeneration(newFdGenerationEvent(constnames.WriteEvent, 40000, 6000), false))
+
+	// The tcp_close of another process doesn't match.
+	closeEvent.Ctx.ThreadInfo.Pid = 200
+	assert.NoError(t, na.processEvent(closeEvent))
+	assert.Equal(t, uint32(1), na.getGeneration(newFdGenerationEvent(constnames.ReadEvent, 40000, 7000), false))
+}
+
+func uint32ToBytes(value uint32) []byte {
+	bytes := make([]byte, 4)
+	binary.LittleEndian.PutUint32(bytes, value)
+	return bytes
+}
@@ -0,0 +1,97 @@
+package network
+
+import (
+	"strings"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+const (
+	healthCheckActionLabel = "label"
+	healthCheckActionDrop  = "drop"
+)
+
+// healthCheckMatcher recognizes the requests sent by the health checkers, like the probes of
+// kubelet and the load balancers. A request is a health check if any of the rules matches.
+type healthCheckMatcher struct {
+	urls       map[string]bool
+	userAgents []string
+	sourceIps  map[string]bool
+	drop       bool
+}
+
+func newHealthCheckMatcher(cfg *HealthCheckConfig) *healthCheckMatcher {
+	m := &healthCheckMatcher{
+		urls:       make(map[string]bool, len(cfg.Urls)),
+		userAgents: cfg.UserAgents,
+		sourceIps:  make(map[string]bool, len(cfg.SourceIps)),
+		drop:       cfg.Action == healthCheckActionDrop,
+	}
+	for _, url := range cfg.Urls {
+		m.urls[url] = true
+	}
+	for _, ip := range cfg.SourceIps {
+		m.sourceIps[ip] = true
+	}
+	return m
+}
+
+// match returns true if the record is a health check. The URL and user-agent rules only apply
+// to HTTP; the source rule applies to all protocols.
+func (m *healthCheckMatcher) match(labels *model.AttributeMap) bool {
+	return m.matchRequest(labels.GetStringValue(constlabels.Protocol), labels.GetStringValue(constlabels.SrcIp), labels)
+}
+
+// matchRequest is the same as match but takes the attributes parsed from the request, so the
+// decision can be made before the record is built. The attributes may be nil.
+func (m *healthCheckMatcher) matchRequest(protocolName string, srcIp string, attributes *model.AttributeMap) bool {
+	if m.sourceIps[srcIp] {
+		return true
+	}
+	if protocolName != protocol.HTTP || attributes == nil {
+		return false
+	}
+	if m.urls[trimUrlQuery(attributes.GetStringValue(constlabels.HttpUrl))] {
+		return true
+	}
+	userAgent := attributes.GetStringValue(constlabels.HttpUserAgent)
+	if userAgent == "" {
+		return false
+	}
+	for _, prefix := range m.userAgents {
+		if strings.HasPrefix(userAgent, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// isDroppedHealthCheck returns true if the record is a health check and should be dropped.
+// The health checks are labeled with "is_health_check" otherwise.
+func (na *NetworkAnalyzer) isDroppedHealthCheck(record *model.DataGroup) bool {
+	if na.healthCheckMatcher == nil || !na.healthCheckMatcher.match(record.Labels) {
+		return false
+	}
+	if na.healthCheckMatcher.drop {
+		return true
+	}
+	record.Labels.UpdateAddBoolValue(constlabels.IsHealthCheck, true)
+	return false
+}
+
+// isDroppedRequest returns true if the request is a health check and should be dropped. It is checked
+// before the record is built, so the payloads and the labels of the dropped requests are never built.
+func (na *NetworkAnalyzer) isDroppedRequest(protocolName string, evt *model.KindlingEvent, attributes *model.AttributeMap) bool {
+	return na.healthCheckMatcher != nil && na.healthCheckMatcher.drop &&
+		na.healthCheckMatcher.matchRequest(protocolName, evt.GetSip(), attributes)
+}
+
+// trimUrlQuery removes the query of the URL.
+func trimUrlQuery(url string) string {
+	if index := strings.IndexByte(url, '?'); index != -1 {
+		return url[:index]
+	}
+	return url
+}
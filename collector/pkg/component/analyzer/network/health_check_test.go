@@ -0,0 +1,93 @@
+package network
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+)
+
+func newHealthCheckLabels(protocolName string, srcIp string, url string, userAgent string) *model.AttributeMap {
+	labels := model.NewAttributeMap()
+	labels.AddStringValue(constlabels.Protocol, protocolName)
+	labels.AddStringValue(constlabels.SrcIp, srcIp)
+	if url != "" {
+		labels.AddStringValue(constlabels.HttpUrl, url)
+	}
+	if userAgent != "" {
+		labels.AddStringValue(constlabels.HttpUserAgent, userAgent)
+	}
+	return labels
+}
+
+func TestHealthCheckMatcher(t *testing.T) {
+	cfg := NewDefaultConfig().HealthCheck
+	cfg.SourceIps = []string{"10.0.0.100"}
+	m := newHealthCheckMatcher(cfg)
+	assert.False(t, m.drop)
+
+	tests := []struct {
+		name   string
+		labels *model.AttributeMap
+		want   bool
+	}{
+		{name: "url", labels: newHealthCheckLabels(protocol.HTTP, "10.0.0.1", "/healthz?verbose", "curl/7.79.1"), want: true},
+		{name: "user agent", labels: newHealthCheckLabels(protocol.HTTP, "10.0.0.1", "/status", "kube-probe/1.24"), want: true},
+		{name: "source", labels: newHealthCheckLabels(protocol.MYSQL, "10.0.0.100", "", ""), want: true},
+		{name: "normal request", labels: newHealthCheckLabels(protocol.HTTP, "10.0.0.1", "/healthz/orders", "curl/7.79.1"), want: false},
+		{name: "url of other protocols", labels: newHealthCheckLabels(protocol.DNS, "10.0.0.1", "/healthz", ""), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, m.match(tt.labels))
+		})
+	}
+}
+
+func TestIsDroppedHealthCheck(t *testing.T) {
+	cfg := NewDefaultConfig().HealthCheck
+	na := &NetworkAnalyzer{healthCheckMatcher: newHealthCheckMatcher(cfg)}
+	newRecord := func(url string) *model.DataGroup {
+		return model.NewDataGroup(constnames.NetRequestMetricGroupName, newHealthCheckLabels(protocol.HTTP, "10.0.0.1", url, ""), 0)
+	}
+
+	healthCheck := newRecord("/livez")
+	assert.False(t, na.isDroppedHealthCheck(healthCheck))
+	assert.True(t, healthCheck.Labels.GetBoolValue(constlabels.IsHealthCheck))
+	request := newRecord("/orders")
+	assert.False(t, na.isDroppedHealthCheck(request))
+	assert.False(t, request.Labels.HasAttribute(constlabels.IsHealthCheck))
+
+	cfg.Action = healthCheckActionDrop
+	na.healthCheckMatcher = newHealthCheckMatcher(cfg)
+	assert.True(t, na.isDroppedHealthCheck(newRecord("/livez")))
+	assert.False(t, na.isDroppedHealthCheck(newRecord("/orders")))
+
+	// Nothing is done if it is disabled.
+	assert.False(t, (&NetworkAnalyzer{}).isDroppedHealthCheck(newRecord("/livez")))
+}
+
+func TestGetRecords_DroppedHealthCheck(t *testing.T) {
+	cfg := NewDefaultConfig().HealthCheck
+	cfg.Action = healthCheckActionDrop
+	na := &NetworkAnalyzer{cfg: NewDefaultConfig(), dataGroupPool: &NoCacheDataGroupPool{},
+		healthCheckMatcher: newHealthCheckMatcher(cfg)}
+	mps := &messagePairs{
+		requests:  newEvents(newServerEvent(constnames.ReadEvent, 5, 1000, 10), 1000),
+		responses: newEvents(newServerEvent(constnames.WriteEvent, 5, 2000, 10), 1000),
+	}
+
+	// The records of the dropped requests are never built.
+	assert.Empty(t, na.getRecords(mps, protocol.HTTP, newHealthCheckLabels(protocol.HTTP, "", "/livez", "")))
+	mp := &messagePair{request: mps.requests.event, response: mps.responses.event}
+	assert.Nil(t, na.getRecordWithSinglePair(mp, protocol.HTTP, newHealthCheckLabels(protocol.HTTP, "", "/livez", "")))
+
+	records := na.getRecords(mps, protocol.HTTP, newHealthCheckLabels(protocol.HTTP, "", "/orders", ""))
+	assert.Len(t, records, 1)
+	assert.True(t, records[0].Labels.HasAttribute(constlabels.RequestPayload))
+	assert.NoError(t, na.distributeRecords([]*model.DataGroup{nil}))
+}
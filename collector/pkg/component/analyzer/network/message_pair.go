@@ -9,6 +9,28 @@ import (
 	"github.com/Kindling-project/kindling/collector/pkg/model"
 )
 
+// maxElapsed bounds the durations, which are never longer than the no-response threshold unless a timestamp
+// is corrupted, e.g. the start time of an event missing its latency.
+const maxElapsed = uint64(time.Hour)
+
+// skewedDurationCount is the number of durations clamped because of the skewed or corrupted timestamps.
+var skewedDurationCount int64
+
+// elapsed returns the time from start to end. The timestamps of the events read on different CPUs
+// could be slightly skewed, so end might be earlier than start, in which case 0 is returned instead
+// of a negative or overflowed duration. The absurdly long durations are clamped to maxElapsed.
+func elapsed(end uint64, start uint64) uint64 {
+	if end < start {
+		atomic.AddInt64(&skewedDurationCount, 1)
+		return 0
+	}
+	if end-start > maxElapsed {
+		atomic.AddInt64(&skewedDurationCount, 1)
+		return maxElapsed
+	}
+	return end - start
+}
+
 type mergableEvent struct {
 	events []*model.KindlingEvent // Keep No more than 10 events.
 
@@ -71,9 +93,12 @@ func (evts *events) mergeEvent(evt *model.KindlingEvent) {
 		// persistent connect
 		evts.mergable.events = append(evts.mergable.events, evt)
 	}
-	evts.mergable.latency = evt.Timestamp - evts.mergable.ts + evts.mergable.latency
+	evts.mergable.latency += elapsed(evt.Timestamp, evts.mergable.ts)
 	evts.mergable.resVal += evt.GetResVal()
-	evts.mergable.ts = evt.Timestamp
+	// Keep the timestamp monotonic in case the event is from a CPU whose clock is behind.
+	if evt.Timestamp > evts.mergable.ts {
+		evts.mergable.ts = evt.Timestamp
+	}
 
 	// We have a constraint on the payload size. The merged data can accommodate a maximum payload size
 	// as same as SANPLEN that is also the maximum size of the syscall data.
@@ -108,6 +133,15 @@ func (evts *events) getData() []byte {
 	return evts.mergable.data
 }
 
+// isTruncated returns true if the captured data is shorter than the bytes read or written,
+// which means the payload has been truncated by the snaplen.
+func (evts *events) isTruncated() bool {
+	if evts.mergable == nil {
+		return int64(len(evts.event.GetData())) < evts.event.GetResVal()
+	}
+	return int64(len(evts.mergable.data)) < evts.mergable.resVal
+}
+
 func (evts *events) getFirstTimestamp() uint64 {
 	return evts.event.Timestamp
 }
@@ -150,13 +184,17 @@ func (evts *events) getDuration() uint64 {
 }
 
 type messagePairs struct {
-	connects         *events
-	requests         *events
-	responses        *events
-	natTuple         *conntracker.IPTranslation
+	connects  *events
+	requests  *events
+	responses *events
+	natTuple  *conntracker.IPTranslation
+	// natChain is set only if the DNAT chain is followed, whose last hop is natTuple.
+	natChain         []*conntracker.IPTranslation
 	isSend           int32
 	mutex            sync.RWMutex // only for update latency and resval now
 	maxPayloadLength int
+	// generation distinguishes the connections that reuse the same fd.
+	generation uint32
 }
 
 func (mps *messagePairs) checkSend() bool {
@@ -165,14 +203,18 @@ func (mps *messagePairs) checkSend() bool {
 }
 
 func (mps *messagePairs) getKey() messagePairKey {
+	var key messagePairKey
 	if mps.connects != nil {
-		return getMessagePairKey(mps.connects.event)
+		key = getMessagePairKey(mps.connects.event)
 	} else if mps.requests != nil {
-		return getMessagePairKey(mps.requests.event)
+		key = getMessagePairKey(mps.requests.event)
 	} else if mps.responses != nil {
-		return getMessagePairKey(mps.responses.event)
+		key = getMessagePairKey(mps.responses.event)
+	} else {
+		return messagePairKey{}
 	}
-	return messagePairKey{}
+	key.generation = mps.generation
+	return key
 }
 
 func (mps *messagePairs) mergeConnect(evt *model.KindlingEvent) {
@@ -259,7 +301,7 @@ func (mps *messagePairs) getWaitingTime() int64 {
 		return -1
 	}
 
-	return int64(mps.responses.getFirstTimestamp() - mps.responses.event.GetLatency() - mps.requests.getLastTimestamp())
+	return int64(elapsed(mps.responses.event.GetStartTime(), mps.requests.getLastTimestamp()))
 }
 
 func (mps *messagePairs) getDownloadTime() int64 {
@@ -275,7 +317,7 @@ func (mps *messagePairs) getDuration() uint64 {
 		return 0
 	}
 
-	return mps.responses.getLastTimestamp() - mps.requests.event.GetStartTime()
+	return elapsed(mps.responses.getLastTimestamp(), mps.requests.event.GetStartTime())
 }
 
 func (mps *messagePairs) getRquestSize() uint64 {
@@ -300,6 +342,13 @@ func (mps *messagePairs) getResponseSize() uint64 {
 	return uint64(mps.responses.mergable.resVal)
 }
 
+func (mps *messagePairs) isPayloadTruncated() bool {
+	if mps.requests != nil && mps.requests.isTruncated() {
+		return true
+	}
+	return mps.responses != nil && mps.responses.isTruncated()
+}
+
 type messagePair struct {
 	request  *model.KindlingEvent
 	response *model.KindlingEvent
@@ -319,7 +368,7 @@ func (mp *messagePair) getWaitingTime() int64 {
 		return -1
 	}
 
-	return int64(mp.response.Timestamp - mp.response.GetLatency() - mp.request.Timestamp)
+	return int64(elapsed(mp.response.GetStartTime(), mp.request.Timestamp))
 }
 
 func (mp *messagePair) getDownloadTime() int64 {
@@ -344,17 +393,26 @@ func (mp *messagePair) getResponseSize() uint64 {
 	return uint64(mp.response.GetResVal())
 }
 
+func (mp *messagePair) isPayloadTruncated() bool {
+	if mp.request != nil && int64(len(mp.request.GetData())) < mp.request.GetResVal() {
+		return true
+	}
+	return mp.response != nil && int64(len(mp.response.GetData())) < mp.response.GetResVal()
+}
+
 func (mp *messagePair) getDuration() uint64 {
 	if mp.response == nil {
 		return 0
 	}
 
-	return mp.response.Timestamp + mp.request.GetLatency() - mp.request.Timestamp
+	return elapsed(mp.response.Timestamp, mp.request.GetStartTime())
 }
 
 type messagePairKey struct {
 	pid uint32
 	fd  int32
+	// generation is 0 unless the fd has been reused by another connection.
+	generation uint32
 }
 
 func getMessagePairKey(evt *model.KindlingEvent) messagePairKey {
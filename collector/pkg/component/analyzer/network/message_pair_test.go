@@ -0,0 +1,70 @@
+package network
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+)
+
+func TestMessagePairs_SkewedTimestamps(t *testing.T) {
+	skewed := atomic.LoadInt64(&skewedDurationCount)
+	request := &model.KindlingEvent{Timestamp: 1000, Latency: 100}
+	// The response is read on another CPU whose clock is 50ns behind.
+	response := &model.KindlingEvent{Timestamp: 1000, Latency: 50}
+
+	mp := &messagePair{request: request, response: response}
+	assert.Equal(t, int64(0), mp.getWaitingTime())
+	assert.Equal(t, uint64(100), mp.getDuration())
+
+	mps := &messagePairs{requests: newEvents(request, 100), responses: newEvents(response, 100)}
+	assert.Equal(t, int64(0), mps.getWaitingTime())
+	assert.Equal(t, uint64(100), mps.getDuration())
+	assert.Equal(t, skewed+2, atomic.LoadInt64(&skewedDurationCount))
+
+	// The merged events keep the latest timestamp even if a later event has an earlier one.
+	mps.requests.mergeEvent(&model.KindlingEvent{Timestamp: 1200, Latency: 10})
+	mps.requests.mergeEvent(&model.KindlingEvent{Timestamp: 1150, Latency: 10})
+	assert.Equal(t, uint64(1200), mps.requests.getLastTimestamp())
+	assert.Equal(t, int64(300), mps.getSentTime())
+}
+
+func TestElapsed_Clamped(t *testing.T) {
+	skewed := atomic.LoadInt64(&skewedDurationCount)
+	assert.Equal(t, uint64(100), elapsed(1100, 1000))
+	assert.Equal(t, maxElapsed, elapsed(1000+maxElapsed, 1000))
+	// The start time is missing.
+	assert.Equal(t, maxElapsed, elapsed(1700000000000000000, 0))
+	assert.Equal(t, skewed+1, atomic.LoadInt64(&skewedDurationCount))
+}
+
+func TestMessagePairs_PayloadTruncated(t *testing.T) {
+	newEvent := func(res int64, data string) *model.KindlingEvent {
+		return &model.KindlingEvent{
+			ParamsNumber: 2,
+			UserAttributes: [16]model.KeyValue{
+				{Key: "res", ValueType: model.ValueType_INT64, Value: Int64ToBytes(res)},
+				{Key: "data", ValueType: model.ValueType_BYTEBUF, Value: []byte(data)},
+			},
+		}
+	}
+
+	mps := &messagePairs{requests: newEvents(newEvent(4, "ping"), 8)}
+	assert.False(t, mps.isPayloadTruncated())
+	mps.responses = newEvents(newEvent(1024, "pong"), 8)
+	assert.True(t, mps.isPayloadTruncated())
+
+	// The merged data is truncated once it exceeds the maximum payload length.
+	mps = &messagePairs{requests: newEvents(newEvent(4, "ping"), 8)}
+	mps.requests.mergeEvent(newEvent(4, "ping"))
+	assert.False(t, mps.isPayloadTruncated())
+	mps.requests.mergeEvent(newEvent(4, "ping"))
+	assert.True(t, mps.isPayloadTruncated())
+
+	mp := &messagePair{request: newEvent(4, "ping")}
+	assert.False(t, mp.isPayloadTruncated())
+	mp.request = newEvent(100, "ping")
+	assert.True(t, mp.isPayloadTruncated())
+}
@@ -3,20 +3,31 @@ package network
 import (
 	"context"
 	"sync"
+	"sync/atomic"
 
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/metric"
 )
 
 const (
-	netanalyzerMessagePairMetric   = "kindling_telemetry_netanalyer_messagepair_size"
-	netanalyzerParsedRequestMetric = "kindling_telemetry_netanalyer_parsedrequest_total"
+	netanalyzerMessagePairMetric    = "kindling_telemetry_netanalyer_messagepair_size"
+	netanalyzerParsedRequestMetric  = "kindling_telemetry_netanalyer_parsedrequest_total"
+	netanalyzerDroppedRecordMetric  = "kindling_telemetry_netanalyer_consumer_dropped_total"
+	netanalyzerParserCpuTimeMetric  = "kindling_telemetry_netanalyer_parser_cpu_time_nanoseconds_total"
+	netanalyzerSkewedDurationMetric = "kindling_telemetry_netanalyer_skewed_duration_total"
+	netanalyzerTruncatedMetric      = "kindling_telemetry_netanalyer_truncated_payload_total"
 )
 
 var (
 	selfTelemetryOnce                    sync.Once
 	netanalyzerMessagePairSizeInstrument metric.Int64GaugeObserver
 	netanalyzerParsedRequestTotal        metric.Int64Counter
+	netanalyzerDroppedRecordInstrument   metric.Int64CounterObserver
+	netanalyzerParserCpuTimeInstrument   metric.Int64CounterObserver
+	netanalyzerSkewedDurationInstrument  metric.Int64CounterObserver
+	netanalyzerTruncatedPayloadTotal     metric.Int64Counter
 )
 
 func newSelfMetrics(meterProvider metric.MeterProvider, na *NetworkAnalyzer) {
@@ -28,7 +39,36 @@ func newSelfMetrics(meterProvider metric.MeterProvider, na *NetworkAnalyzer) {
 			}, metric.WithDescription("The size of the message pairs stored in the map"))
 		netanalyzerParsedRequestTotal = metric.Must(meterProvider.Meter("kindling")).NewInt64Counter(netanalyzerParsedRequestMetric,
 			metric.WithDescription("The count of traces that the agent has processed"))
+		netanalyzerDroppedRecordInstrument = metric.Must(meterProvider.Meter("kindling")).NewInt64CounterObserver(netanalyzerDroppedRecordMetric,
+			func(ctx context.Context, result metric.Int64ObserverResult) {
+				for _, queue := range na.consumerQueues {
+					result.Observe(queue.getDropped(), attribute.String("consumer", queue.name))
+				}
+			}, metric.WithDescription("The count of records dropped because the queue of the consumer is full"))
+		netanalyzerParserCpuTimeInstrument = metric.Must(meterProvider.Meter("kindling")).NewInt64CounterObserver(netanalyzerParserCpuTimeMetric,
+			func(ctx context.Context, result metric.Int64ObserverResult) {
+				for name, value := range na.parserCostSampler.Costs() {
+					result.Observe(value, attribute.String("protocol", name))
+				}
+			}, metric.WithDescription("The estimated CPU time consumed by each protocol parser, which is sampled"))
+		netanalyzerSkewedDurationInstrument = metric.Must(meterProvider.Meter("kindling")).NewInt64CounterObserver(netanalyzerSkewedDurationMetric,
+			func(ctx context.Context, result metric.Int64ObserverResult) {
+				result.Observe(atomic.LoadInt64(&skewedDurationCount))
+			}, metric.WithDescription("The count of durations clamped because the end timestamp is earlier than the start one or absurdly later"))
+		netanalyzerTruncatedPayloadTotal = metric.Must(meterProvider.Meter("kindling")).NewInt64Counter(netanalyzerTruncatedMetric,
+			metric.WithDescription("The count of traces whose payload is truncated by the snaplen"))
 		// Suppress warnings of unused variables
 		_ = netanalyzerMessagePairSizeInstrument
+		_ = netanalyzerDroppedRecordInstrument
+		_ = netanalyzerParserCpuTimeInstrument
+		_ = netanalyzerSkewedDurationInstrument
 	})
 }
+
+// countTruncatedPayload increases the truncation counter of the record's protocol if its payload is truncated.
+func countTruncatedPayload(record *model.DataGroup) {
+	if !record.Labels.GetBoolValue(constlabels.PayloadTruncated) {
+		return
+	}
+	netanalyzerTruncatedPayloadTotal.Add(context.Background(), 1, attribute.String("protocol", record.Labels.GetStringValue(constlabels.Protocol)))
+}
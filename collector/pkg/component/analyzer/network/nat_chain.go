@@ -0,0 +1,22 @@
+package network
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/Kindling-project/kindling/collector/pkg/metadata/conntracker"
+)
+
+// formatNatChain returns the addresses of the DNAT hops like "10.0.0.1:30080,172.16.0.5:8080".
+func formatNatChain(chain []*conntracker.IPTranslation) string {
+	var builder strings.Builder
+	for i, translation := range chain {
+		if i > 0 {
+			builder.WriteByte(',')
+		}
+		builder.WriteString(translation.ReplSrcIP.String())
+		builder.WriteByte(':')
+		builder.WriteString(strconv.Itoa(int(translation.ReplSrcPort)))
+	}
+	return builder.String()
+}
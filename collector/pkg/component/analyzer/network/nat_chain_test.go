@@ -0,0 +1,37 @@
+package network
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/metadata/conntracker"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+)
+
+func TestGetRecords_NatChain(t *testing.T) {
+	na := &NetworkAnalyzer{cfg: NewDefaultConfig(), dataGroupPool: &NoCacheDataGroupPool{}}
+	chain := []*conntracker.IPTranslation{
+		{ReplSrcIP: net.ParseIP("192.168.1.2"), ReplSrcPort: 30080},
+		{ReplSrcIP: net.ParseIP("172.16.0.5"), ReplSrcPort: 8080},
+	}
+	mps := &messagePairs{
+		requests:  newEvents(newServerEvent(constnames.ReadEvent, 5, 1000, 10), 1000),
+		responses: newEvents(newServerEvent(constnames.WriteEvent, 5, 2000, 10), 1000),
+		natTuple:  chain[1],
+		natChain:  chain,
+	}
+
+	labels := na.getRecords(mps, protocol.NOSUPPORT, nil)[0].Labels
+	assert.Equal(t, "172.16.0.5", labels.GetStringValue(constlabels.DnatIp))
+	assert.Equal(t, int64(8080), labels.GetIntValue(constlabels.DnatPort))
+	assert.Equal(t, "192.168.1.2:30080,172.16.0.5:8080", labels.GetStringValue(constlabels.DnatChain))
+
+	// The chain is not added for a single hop, which is the same as the DNAT address.
+	mps.natChain = chain[1:]
+	labels = na.getRecords(mps, protocol.NOSUPPORT, nil)[0].Labels
+	assert.False(t, labels.HasAttribute(constlabels.DnatChain))
+}
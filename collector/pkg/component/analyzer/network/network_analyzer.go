@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/hex"
 	"math/rand"
+	"net/http"
 	"os"
 	"strconv"
 	"sync"
@@ -15,10 +16,13 @@ import (
 
 	"github.com/Kindling-project/kindling/collector/pkg/component"
 	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/payloadprofile"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/pcapexport"
 	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
 	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/factory"
 	"github.com/Kindling-project/kindling/collector/pkg/component/consumer"
 	"github.com/Kindling-project/kindling/collector/pkg/metadata/conntracker"
+	"github.com/Kindling-project/kindling/collector/pkg/metadata/kubernetes"
 	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
 
 	"go.uber.org/zap/zapcore"
@@ -26,11 +30,11 @@ import (
 	"github.com/Kindling-project/kindling/collector/pkg/model"
 	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
 	"github.com/Kindling-project/kindling/collector/pkg/model/constvalues"
+	"github.com/Kindling-project/kindling/collector/pkg/model/timeunit"
 )
 
 const (
-	CACHE_ADD_THRESHOLD   = 50
-	CACHE_RESET_THRESHOLD = 5000
+	CACHE_ADD_THRESHOLD = 50
 
 	Network analyzer.Type = "networkanalyzer"
 )
@@ -60,6 +64,77 @@ type NetworkAnalyzer struct {
 	// snaplen is the maximum data size the event could accommodate bytes.
 	// It is set by setting the environment variable SNAPLEN. See https://github.com/KindlingProject/kindling/pull/387.
 	snaplen int
+
+	// threadNameResolver is nil if the thread names are not needed.
+	threadNameResolver *threadNameResolver
+	// syscallTracker is nil if the syscall breakdown is disabled.
+	syscallTracker *syscallTracker
+	// acceptMonitor stores the timestamps of the connections accepted but not read yet.
+	acceptMonitor sync.Map
+	// fdGenerations stores the generation of the connection currently on each fd.
+	fdGenerations sync.Map
+	// tupleFds stores the fd of each connection by its tuple, so the tcp_close events can be matched to the fds.
+	tupleFds sync.Map
+	// connectionProtocols stores the protocol recognized for each connection.
+	connectionProtocols sync.Map
+	// quicPorts are the UDP ports whose datagrams are analysed as QUIC.
+	quicPorts map[uint32]bool
+	// quicMonitor stores the QUIC connections waiting for the first packets from the servers.
+	quicMonitor sync.Map
+	// ntpPorts are the UDP ports whose datagrams are analysed as NTP.
+	ntpPorts map[uint32]bool
+	// ntpMonitor stores the NTP requests by their transmit timestamps until they are answered.
+	ntpMonitor sync.Map
+	// sipPorts are the UDP ports whose datagrams are analysed as SIP.
+	sipPorts map[uint32]bool
+	// sipMonitor stores the SIP requests by their Call-IDs and CSeqs until the final responses.
+	sipMonitor sync.Map
+	// dnsDeduplicator is nil if the DNS dedup is disabled.
+	dnsDeduplicator *dnsDeduplicator
+	// nodeLocalDnsLinker is nil if the NodeLocal DNSCache handling is disabled.
+	nodeLocalDnsLinker *nodeLocalDnsLinker
+	// dnsAmplificationTracker is nil if the detection of the search-domain amplification is disabled.
+	dnsAmplificationTracker *dnsAmplificationTracker
+	// payloadProfiler is nil if the payload profile is disabled.
+	payloadProfiler *payloadprofile.Profiler
+	// pcapRecorder is nil if the pcap export is disabled.
+	pcapRecorder *pcapexport.Recorder
+	// healthCheckMatcher is nil if the health-check recognition is disabled.
+	healthCheckMatcher *healthCheckMatcher
+	// sampleNormalRequests is true if the normal requests are sampled by the analyzer, see SetNormalSamplingRate.
+	sampleNormalRequests bool
+	normalSamplingRate   int
+	// dnsResolutionTracker is nil if the DNS time attribution is disabled.
+	dnsResolutionTracker *dnsResolutionTracker
+	// fanOutTracker is nil if the fan-out tracking is disabled.
+	fanOutTracker *fanOutTracker
+	// consumerQueues is nil if the records are delivered to the next consumers synchronously.
+	consumerQueues []*consumerQueue
+	// The overrides of the no-response threshold and the oneway ports from the protocol configs.
+	protocolNoResponseThresholds map[string]int
+	portNoResponseThresholds     map[uint32]int
+	onewayPorts                  map[uint32]bool
+	// dropUnknownPorts are the ports whose NOSUPPORT records are dropped.
+	dropUnknownPorts map[uint32]bool
+	// parserTiers is nil if the parser tiering is disabled.
+	parserTiers *parserTiers
+	// reloadChan passes the configs with new protocol settings to the goroutine consuming the events.
+	reloadChan chan *Config
+	// protocolMutex guards the protocol settings against the timeout checker while they are reloaded.
+	// They are only modified in the goroutine consuming the events, which doesn't need the lock to read them.
+	protocolMutex sync.RWMutex
+	// parserCostSampler estimates the CPU time consumed by each protocol parser.
+	parserCostSampler *analyzer.CostSampler
+	// protocolInfoTracker is nil if the protocol info is disabled.
+	protocolInfoTracker *protocolInfoTracker
+	// threadPoolTracker is nil if the thread pool metrics are disabled.
+	threadPoolTracker *threadPoolTracker
+	// podMetadata is nil if the workload overrides are disabled.
+	podMetadata *kubernetes.K8sMetaDataCache
+	// sshSessions collects the SSH records to build the records of the handshakes.
+	sshSessions *sshSessionTracker
+	// bulkTransfers collects the statements starting the bulk loads to build the records of the transfers.
+	bulkTransfers *bulkTransferTracker
 }
 
 func NewNetworkAnalyzer(cfg interface{}, telemetry *component.TelemetryTools, consumers []consumer.Consumer) analyzer.Analyzer {
@@ -70,8 +145,11 @@ func NewNetworkAnalyzer(cfg interface{}, telemetry *component.TelemetryTools, co
 		nextConsumers: consumers,
 		telemetry:     telemetry,
 
-		eventChan: make(chan *model.KindlingEvent, config.EventChannelSize),
-		stopChan:  make(chan bool),
+		eventChan:  make(chan *model.KindlingEvent, config.EventChannelSize),
+		stopChan:   make(chan bool),
+		reloadChan: make(chan *Config, 1),
+
+		parserCostSampler: analyzer.NewCostSampler(analyzer.DefaultCostSampleRate),
 	}
 	if config.EnableConntrack {
 		connConfig := &conntracker.Config{
@@ -85,12 +163,130 @@ func NewNetworkAnalyzer(cfg interface{}, telemetry *component.TelemetryTools, co
 		na.conntracker, _ = conntracker.NewConntracker(connConfig)
 	}
 
-	na.parserFactory = factory.NewParserFactory(factory.WithUrlClusteringMethod(na.cfg.UrlClusteringMethod), factory.WithIgnoreDnsRcode3Error(na.cfg.IgnoreDnsRcode3Error))
+	parserOptions := []factory.Option{factory.WithUrlClusteringMethod(na.cfg.UrlClusteringMethod), factory.WithIgnoreDnsRcode3Error(na.cfg.IgnoreDnsRcode3Error),
+		factory.WithHttpSessionCookie(na.cfg.HttpSessionCookie), factory.WithHttpSoapOperation(na.cfg.HttpSoapOperation),
+		factory.WithHttpElasticsearch(na.cfg.HttpElasticsearch), factory.WithHttpInfluxdb(na.cfg.HttpInfluxdb),
+		factory.WithHttpGraphqlPaths(na.cfg.HttpGraphqlPaths)}
+	if config.PayloadMask != nil && config.PayloadMask.Enable {
+		parserOptions = append(parserOptions, factory.WithHttpMaskedHeaders(config.PayloadMask.HttpHeaders),
+			factory.WithMysqlLiteralsMasked(config.PayloadMask.MysqlLiterals), factory.WithRedisAuthMasked(config.PayloadMask.RedisAuth),
+			factory.WithLdapBindMasked(config.PayloadMask.LdapBind))
+	}
+	na.parserFactory = factory.NewParserFactory(parserOptions...)
+	na.initNoResponseThresholds()
+	na.dropUnknownPorts = make(map[uint32]bool, len(config.DropUnknownPorts))
+	for _, port := range config.DropUnknownPorts {
+		na.dropUnknownPorts[port] = true
+	}
 	na.snaplen = getSnaplenEnv()
+	if config.EnableThreadName {
+		na.threadNameResolver = newThreadNameResolver(config.ProcRoot)
+	}
+	if config.SyscallBreakdown != nil && config.SyscallBreakdown.Enable && config.SyscallBreakdown.MaxEventsPerThread > 0 {
+		na.syscallTracker = newSyscallTracker(config.SyscallBreakdown.MaxEventsPerThread)
+	}
+	if config.DnsDedup != nil && config.DnsDedup.Enable {
+		na.dnsDeduplicator = newDnsDeduplicator(config.getDnsDedupWindow())
+	}
+	if config.NodeLocalDns != nil && config.NodeLocalDns.Enable {
+		na.nodeLocalDnsLinker = newNodeLocalDnsLinker(config.NodeLocalDns.CacheIps, config.NodeLocalDns.CacheComms, config.getNodeLocalDnsWindow())
+	}
+	if config.DnsAmplification != nil && config.DnsAmplification.Enable {
+		na.dnsAmplificationTracker = newDnsAmplificationTracker(config.DnsAmplification.SearchDomains, config.getDnsAmplificationWindow())
+	}
+	if config.PayloadProfile != nil && config.PayloadProfile.Enable {
+		na.payloadProfiler = payloadprofile.NewProfiler(config.PayloadProfile)
+	}
+	if config.PcapExport != nil && config.PcapExport.Enable {
+		na.pcapRecorder = pcapexport.NewRecorder(config.PcapExport)
+	}
+	if config.HealthCheck != nil && config.HealthCheck.Enable {
+		na.healthCheckMatcher = newHealthCheckMatcher(config.HealthCheck)
+	}
+	if config.DnsAttribution != nil && config.DnsAttribution.Enable {
+		na.dnsResolutionTracker = newDnsResolutionTracker(uint64(config.getDnsAttributionWindow()))
+	}
+	if config.FanOut != nil && config.FanOut.Enable {
+		na.fanOutTracker = newFanOutTracker(config.FanOut.MatchThread, uint64(config.getFanOutWindow()))
+	}
+	if config.ConsumerQueue != nil && config.ConsumerQueue.Enable {
+		for _, c := range consumers {
+			na.consumerQueues = append(na.consumerQueues, newConsumerQueue(c, config.getConsumerQueueSize()))
+		}
+	}
+	if config.ProtocolInfo != nil && config.ProtocolInfo.Enable {
+		na.protocolInfoTracker = newProtocolInfoTracker(config.getProtocolInfoExpiration())
+	}
+	if config.ThreadPool != nil && config.ThreadPool.Enable {
+		na.threadPoolTracker = newThreadPoolTracker()
+	}
+	na.podMetadata = newPodMetadata(config.WorkloadOverride)
+	na.sshSessions = newSshSessionTracker()
+	na.bulkTransfers = newBulkTransferTracker()
 
 	return na
 }
 
+// PayloadProfileHandler returns the handler reporting the inferred framing patterns of the
+// NOSUPPORT requests, or nil if the payload profile is disabled.
+func (na *NetworkAnalyzer) PayloadProfileHandler() http.Handler {
+	if na.payloadProfiler == nil {
+		return nil
+	}
+	return na.payloadProfiler
+}
+
+// PcapExportHandler returns the handler capturing the payloads of the chosen connections and
+// exporting them in pcapng, or nil if the pcap export is disabled.
+func (na *NetworkAnalyzer) PcapExportHandler() http.Handler {
+	if na.pcapRecorder == nil {
+		return nil
+	}
+	return na.pcapRecorder
+}
+
+// recordPcapPacket passes the payload of the event to the pcap recorder, which keeps it only if
+// the connection is being captured.
+func (na *NetworkAnalyzer) recordPcapPacket(evt *model.KindlingEvent) {
+	if na.pcapRecorder == nil || !na.pcapRecorder.Capturing() || evt.GetDataLen() <= 0 || evt.GetResVal() < 0 {
+		return
+	}
+	// The result of sendmmsg is the number of messages.
+	if evt.Name == constnames.SendMMsgEvent {
+		for _, e := range model.ConvertSendmmsg(evt) {
+			na.recordPcapPacket(e)
+		}
+		return
+	}
+	isRequest, err := evt.IsRequest()
+	if err != nil {
+		return
+	}
+	tuple := pcapexport.Tuple{
+		Protocol: pcapexport.TCP,
+		SrcIp:    evt.GetSip(),
+		SrcPort:  evt.GetSport(),
+		DstIp:    evt.GetDip(),
+		DstPort:  evt.GetDport(),
+	}
+	if evt.IsUdp() == 1 {
+		tuple.Protocol = pcapexport.UDP
+	}
+	na.pcapRecorder.Observe(tuple, &pcapexport.Packet{
+		Timestamp:  evt.Timestamp,
+		FromClient: isRequest,
+		Data:       evt.GetData(),
+		Length:     int(evt.GetResVal()),
+	})
+}
+
+func (na *NetworkAnalyzer) profileUnknownPayload(port uint32, mps *messagePairs) {
+	if na.payloadProfiler == nil || mps.requests == nil {
+		return
+	}
+	na.payloadProfiler.Observe(port, mps.requests.getData())
+}
+
 func getSnaplenEnv() int {
 	snaplen := os.Getenv("SNAPLEN")
 	snaplenInt, err := strconv.Atoi(snaplen)
@@ -112,20 +308,67 @@ func (na *NetworkAnalyzer) ConsumableEvents() []string {
 		constnames.SendMsgEvent,
 		constnames.RecvMsgEvent,
 		constnames.SendMMsgEvent,
+		constnames.AcceptEvent,
+		constnames.Accept4Event,
+		constnames.EpollWaitEvent,
+		constnames.PollEvent,
+		constnames.PpollEvent,
+		constnames.SelectEvent,
+		constnames.TcpCloseEvent,
 	}
 }
 
 func (na *NetworkAnalyzer) Start() error {
 	// TODO When import multi analyzers, this part should move to factory. The metric will relate with analyzers.
 	newSelfMetrics(na.telemetry.MeterProvider, na)
+	publishExpvar(na)
 
 	if na.cfg.EnableTimeoutCheck {
 		go na.consumerFdNoReusingTrace()
 	}
+	if na.holdsDnsRecords() {
+		go na.flushDnsRecords()
+	}
+	if na.protocolInfoTracker != nil {
+		go na.reportProtocolInfo()
+	}
+	if na.threadPoolTracker != nil {
+		go na.reportThreadPool()
+	}
+	for _, queue := range na.consumerQueues {
+		go queue.run(na.stopChan)
+	}
 	// go na.consumerUnFinishTrace()
+	na.initProtocols(time.Now())
+
+	rand.Seed(time.Now().UnixNano())
+	go na.ConsumeEventFromChannel()
+	return nil
+}
+
+// initProtocols builds the parsers and the protocol settings from the protocol configs.
+func (na *NetworkAnalyzer) initProtocols(now time.Time) {
 	na.staticPortMap = map[uint32]string{}
+	na.quicPorts = map[uint32]bool{}
+	na.ntpPorts = map[uint32]bool{}
+	na.sipPorts = map[uint32]bool{}
 	for _, config := range na.cfg.ProtocolConfigs {
 		for _, port := range config.Ports {
+			// The ports of QUIC are kept apart, as TCP is sent to the same ports, e.g. HTTPS on 443.
+			if config.Key == protocol.QUIC {
+				na.quicPorts[port] = true
+				continue
+			}
+			// NTP is only sent over UDP and has no parser in the "protocol_parser" array.
+			if config.Key == protocol.NTP {
+				na.ntpPorts[port] = true
+				continue
+			}
+			// SIP over UDP is paired by the Call-ID and the CSeq instead of the order of the messages.
+			if config.Key == protocol.SIP {
+				na.sipPorts[port] = true
+				continue
+			}
 			na.staticPortMap[port] = config.Key
 		}
 	}
@@ -134,6 +377,13 @@ func (na *NetworkAnalyzer) Start() error {
 	disableDisernProtocols := map[string]bool{}
 	for _, config := range na.cfg.ProtocolConfigs {
 		protocol.SetPayLoadLength(config.Key, config.PayloadLength)
+		err := protocol.SetPayloadFormat(config.Key, protocol.PayloadFormat{
+			Encoding:  config.PayloadEncoding,
+			MaxLength: config.PayloadLabelLength,
+		})
+		if err != nil {
+			na.telemetry.Logger.Warnf("%v, the text encoding is used instead", err)
+		}
 		na.slowThresholdMap[config.Key] = config.Threshold
 		disableDisernProtocols[config.Key] = config.DisableDiscern
 	}
@@ -145,7 +395,7 @@ func (na *NetworkAnalyzer) Start() error {
 		if protocolParser != nil {
 			na.protocolMap[protocolName] = protocolParser
 			disableDiscern, ok := disableDisernProtocols[protocolName]
-			if !ok || !disableDiscern {
+			if (!ok || !disableDiscern) && !upgradedProtocols[protocolName] {
 				parsers = append(parsers, protocolParser)
 			}
 		}
@@ -153,13 +403,12 @@ func (na *NetworkAnalyzer) Start() error {
 	// Add Generic Last
 	parsers = append(parsers, na.parserFactory.GetGenericParser())
 	na.parsers = parsers
+	if na.cfg.ParserTiering != nil && na.cfg.ParserTiering.Enable {
+		na.parserTiers = newParserTiers(parsers, na.cfg.getParserColdAfter(), na.cfg.getColdCheckInterval(), now)
+	}
 
 	// Add Udp Dns
 	na.udpDnsParser = na.parserFactory.GetUdpDnsParser()
-
-	rand.Seed(time.Now().UnixNano())
-	go na.ConsumeEventFromChannel()
-	return nil
 }
 
 func (na *NetworkAnalyzer) Shutdown() error {
@@ -186,6 +435,8 @@ func (na *NetworkAnalyzer) ConsumeEventFromChannel() {
 			if err != nil {
 				na.telemetry.Logger.Error("error happened when processing event: ", zap.Error(err))
 			}
+		case cfg := <-na.reloadChan:
+			na.reloadProtocols(cfg, time.Now())
 		case <-na.stopChan:
 			return
 		}
@@ -193,6 +444,13 @@ func (na *NetworkAnalyzer) ConsumeEventFromChannel() {
 }
 
 func (na *NetworkAnalyzer) processEvent(evt *model.KindlingEvent) error {
+	if na.syscallTracker != nil {
+		na.syscallTracker.record(evt)
+	}
+	if evt.Name == constnames.TcpCloseEvent {
+		na.closeGeneration(evt)
+		return nil
+	}
 	if evt.Category != model.Category_CAT_NET {
 		return nil
 	}
@@ -209,10 +467,21 @@ func (na *NetworkAnalyzer) processEvent(evt *model.KindlingEvent) error {
 	if fd.GetSip() == nil {
 		return nil
 	}
+	na.recordPcapPacket(evt)
 
 	// if not dns and udp == 1, return
 	if fd.GetProtocol() == model.L4Proto_UDP {
-		if protocolName, ok := na.staticPortMap[evt.GetDport()]; !ok || protocolName != protocol.DNS {
+		if na.isUdpQuicEvent(evt) {
+			return na.analyseQuic(evt)
+		}
+		if na.isUdpNtpEvent(evt) {
+			return na.analyseNtp(evt)
+		}
+		if na.isUdpSipEvent(evt) {
+			return na.analyseSip(evt)
+		}
+		dnsProtocol := na.getUdpDnsProtocol(evt)
+		if dnsProtocol == "" {
 			return nil
 		}
 		isRequest, err := evt.IsRequest()
@@ -248,7 +517,7 @@ func (na *NetworkAnalyzer) processEvent(evt *model.KindlingEvent) error {
 							response: evt,
 						}
 						records := make([]*model.DataGroup, 0)
-						records = append(records, na.getRecordWithSinglePair(mp, protocol.DNS, responseAttributes))
+						records = append(records, na.getRecordWithSinglePair(mp, dnsProtocol, responseAttributes))
 						return na.distributeRecords(records)
 					}
 				}
@@ -264,6 +533,11 @@ func (na *NetworkAnalyzer) processEvent(evt *model.KindlingEvent) error {
 		return na.analyseConnect(evt)
 	}
 
+	if evt.IsAccept() {
+		na.recordAccept(evt)
+		return nil
+	}
+
 	if evt.GetDataLen() <= 0 || evt.GetResVal() < 0 {
 		// TODO: analyse udp
 		return nil
@@ -303,15 +577,16 @@ func (na *NetworkAnalyzer) consumerFdNoReusingTrace() {
 	for {
 		select {
 		case <-timer.C:
+			na.protocolMutex.RLock()
 			na.requestMonitor.Range(func(k, v interface{}) bool {
 				mps := v.(*messagePairs)
 				var timeoutTs = mps.getTimeoutTs()
 				if timeoutTs != 0 {
-					var duration = time.Now().UnixNano()/1000000000 - int64(timeoutTs)/1000000000
-					if mps.responses != nil && duration >= int64(na.cfg.GetFdReuseTimeout()) {
+					var duration = timeunit.Now().Sub(timeunit.Timestamp(timeoutTs))
+					if mps.responses != nil && duration >= timeunit.FromSeconds(na.cfg.GetFdReuseTimeout()) {
 						// No FdReuse Request
 						_ = na.distributeTraceMetric(mps, nil)
-					} else if duration >= int64(na.cfg.getNoResponseThreshold()) {
+					} else if duration >= timeunit.FromSeconds(na.getMessagePairsNoResponseThreshold(mps)) {
 						// No Response Request
 						_ = na.distributeTraceMetric(mps, nil)
 					}
@@ -322,15 +597,15 @@ func (na *NetworkAnalyzer) consumerFdNoReusingTrace() {
 				dnsCache := v.(*DnsUdpCache)
 				dnsCache.requestCache.Range(func(k2, v2 interface{}) bool {
 					udpReq := v2.(*udpRequest)
-					var duration = time.Now().UnixNano()/1000000000 - int64(udpReq.event.Timestamp)/1000000000
-					if duration >= int64(na.cfg.getNoResponseThreshold()) {
+					var duration = timeunit.Now().Sub(timeunit.Timestamp(udpReq.event.Timestamp))
+					if duration >= timeunit.FromSeconds(na.getNoResponseThreshold(udpReq.event.GetDport(), protocol.DNS)) {
 						dnsCache.deleteRequest(k2)
 						// No Response Request
 						records := make([]*model.DataGroup, 0)
 						mp := &messagePair{
 							request: udpReq.event,
 						}
-						records = append(records, na.getRecordWithSinglePair(mp, protocol.DNS, udpReq.attritutes))
+						records = append(records, na.getRecordWithSinglePair(mp, getDnsRecordProtocol(udpReq.event), udpReq.attritutes))
 						_ = na.distributeRecords(records)
 					}
 					return true
@@ -340,6 +615,27 @@ func (na *NetworkAnalyzer) consumerFdNoReusingTrace() {
 				}
 				return true
 			})
+			na.cleanQuicConnections()
+			na.cleanNtpRequests()
+			na.cleanSipRequests()
+			na.protocolMutex.RUnlock()
+			na.cleanAccepts(time.Now())
+			na.cleanConnectionProtocols(time.Now())
+			na.cleanSshSessions(time.Now())
+			na.cleanBulkTransfers(time.Now())
+			na.cleanFdGenerations(time.Now())
+			if na.dnsResolutionTracker != nil {
+				na.dnsResolutionTracker.clean(uint64(time.Now().UnixNano()))
+			}
+			if na.fanOutTracker != nil {
+				na.fanOutTracker.clean(uint64(time.Now().UnixNano()))
+			}
+			if na.threadNameResolver != nil {
+				na.threadNameResolver.clean(time.Now())
+			}
+			if na.syscallTracker != nil {
+				na.syscallTracker.clean(uint64(time.Now().Add(-time.Duration(na.cfg.getNoResponseThreshold()) * time.Second).UnixNano()))
+			}
 		case <-na.stopChan:
 			timer.Stop()
 			return
@@ -348,12 +644,14 @@ func (na *NetworkAnalyzer) consumerFdNoReusingTrace() {
 }
 
 func (na *NetworkAnalyzer) analyseConnect(evt *model.KindlingEvent) error {
+	maxPayloadLength := na.captureLength(evt.GetDport())
 	mps := &messagePairs{
-		connects:         newEvents(evt, na.snaplen),
+		connects:         newEvents(evt, maxPayloadLength),
 		requests:         nil,
 		responses:        nil,
 		mutex:            sync.RWMutex{},
-		maxPayloadLength: na.snaplen,
+		maxPayloadLength: maxPayloadLength,
+		generation:       na.getGeneration(evt, false),
 	}
 	if pairInterface, exist := na.requestMonitor.LoadOrStore(mps.getKey(), mps); exist {
 		// There is an old message pair
@@ -384,12 +682,17 @@ func (na *NetworkAnalyzer) recordMessagePairSize(evt *model.KindlingEvent, count
 }
 
 func (na *NetworkAnalyzer) analyseRequest(evt *model.KindlingEvent) error {
+	if evt.GetCtx().GetFdInfo().GetRole() {
+		na.consumeFirstRead(evt)
+	}
+	maxPayloadLength := na.captureLength(evt.GetDport())
 	mps := &messagePairs{
 		connects:         nil,
-		requests:         newEvents(evt, na.snaplen),
+		requests:         newEvents(evt, maxPayloadLength),
 		responses:        nil,
 		mutex:            sync.RWMutex{},
-		maxPayloadLength: na.snaplen,
+		maxPayloadLength: maxPayloadLength,
+		generation:       na.getGeneration(evt, false),
 	}
 	if pairInterface, exist := na.requestMonitor.LoadOrStore(mps.getKey(), mps); exist {
 		// There is an old message pair
@@ -419,7 +722,9 @@ func (na *NetworkAnalyzer) analyseRequest(evt *model.KindlingEvent) error {
 }
 
 func (na *NetworkAnalyzer) analyseResponse(evt *model.KindlingEvent) error {
-	pairInterface, ok := na.requestMonitor.Load(getMessagePairKey(evt))
+	key := getMessagePairKey(evt)
+	key.generation = na.getGeneration(evt, false)
+	pairInterface, ok := na.requestMonitor.Load(key)
 	if !ok {
 		return nil
 	}
@@ -463,9 +768,16 @@ func (na *NetworkAnalyzer) distributeTraceMetric(oldPairs *messagePairs, newPair
 		srcPort := uint16(queryEvt.GetSport())
 		dstPort := uint16(queryEvt.GetDport())
 		isUdp := queryEvt.IsUdp()
-		natTuple := na.conntracker.GetDNATTuple(srcIP, dstIP, srcPort, dstPort, isUdp)
-		if nil != natTuple {
-			oldPairs.natTuple = natTuple
+		if depth := na.cfg.getDnatChainDepth(); depth > 1 {
+			if chain := na.conntracker.GetDNATChain(srcIP, dstIP, srcPort, dstPort, isUdp, depth); len(chain) > 0 {
+				oldPairs.natTuple = chain[len(chain)-1]
+				oldPairs.natChain = chain
+			}
+		} else {
+			natTuple := na.conntracker.GetDNATTuple(srcIP, dstIP, srcPort, dstPort, isUdp)
+			if nil != natTuple {
+				oldPairs.natTuple = natTuple
+			}
 		}
 	}
 
@@ -479,18 +791,114 @@ func (na *NetworkAnalyzer) distributeTraceMetric(oldPairs *messagePairs, newPair
 
 func (na *NetworkAnalyzer) distributeRecords(records []*model.DataGroup) error {
 	for _, record := range records {
+		// The record is nil if the request is dropped before it is built.
+		if record == nil {
+			continue
+		}
+		if na.isDroppedHealthCheck(record) {
+			na.dataGroupPool.Free(record)
+			continue
+		}
+		if transfer, ok := na.observeBulkTransfer(record, time.Now()); ok {
+			na.dataGroupPool.Free(record)
+			if transfer != nil {
+				na.consume(transfer)
+			}
+			continue
+		}
+		na.applyWorkloadOverrides(record)
+		na.attributeDnsTime(record)
+		na.trackFanOut(record)
+		na.observeProtocol(record)
+		na.observeThreadPool(record)
+		if na.holdsDnsRecords() && isDnsRecord(record) {
+			na.holdDnsRecord(record, time.Now())
+			na.dataGroupPool.Free(record)
+			continue
+		}
 		if ce := na.telemetry.Logger.Check(zapcore.DebugLevel, ""); ce != nil {
 			na.telemetry.Logger.Debug("NetworkAnalyzer To NextProcess:\n" + record.String())
 		}
 		netanalyzerParsedRequestTotal.Add(context.Background(), 1, attribute.String("protocol", record.Labels.GetStringValue(constlabels.Protocol)))
-		for _, nexConsumer := range na.nextConsumers {
-			_ = nexConsumer.Consume(record)
-		}
+		countTruncatedPayload(record)
+		handshake := na.observeSshHandshake(record, time.Now())
+		na.consume(record)
 		na.dataGroupPool.Free(record)
+		if handshake != nil {
+			na.consume(handshake)
+		}
 	}
 	return nil
 }
 
+func (na *NetworkAnalyzer) holdsDnsRecords() bool {
+	return na.dnsDeduplicator != nil || na.nodeLocalDnsLinker != nil || na.dnsAmplificationTracker != nil
+}
+
+// holdDnsRecord passes the DNS record through the NodeLocal DNSCache linker, the amplification tracker
+// and then the deduplicator. The records are reported by flushDnsRecords. The caller still owns the record.
+func (na *NetworkAnalyzer) holdDnsRecord(record *model.DataGroup, now time.Time) {
+	if na.nodeLocalDnsLinker == nil {
+		na.consumeLinkedDnsRecord(record, now)
+		return
+	}
+	for _, linked := range na.nodeLocalDnsLinker.add(record, now) {
+		na.consumeLinkedDnsRecord(linked, now)
+	}
+}
+
+func (na *NetworkAnalyzer) consumeLinkedDnsRecord(record *model.DataGroup, now time.Time) {
+	if na.dnsAmplificationTracker == nil {
+		na.consumeMergedDnsRecord(record, now)
+		return
+	}
+	for _, merged := range na.dnsAmplificationTracker.add(record, now) {
+		na.consumeMergedDnsRecord(merged, now)
+	}
+}
+
+func (na *NetworkAnalyzer) consumeMergedDnsRecord(record *model.DataGroup, now time.Time) {
+	if na.dnsDeduplicator != nil {
+		na.dnsDeduplicator.add(record, now)
+		return
+	}
+	na.consumeDnsRecord(record)
+}
+
+func (na *NetworkAnalyzer) consumeDnsRecord(record *model.DataGroup) {
+	netanalyzerParsedRequestTotal.Add(context.Background(), 1, attribute.String("protocol", protocol.DNS))
+	countTruncatedPayload(record)
+	na.consume(record)
+}
+
+func (na *NetworkAnalyzer) flushDnsRecords() {
+	timer := time.NewTicker(1 * time.Second)
+	for {
+		select {
+		case <-timer.C:
+			now := time.Now()
+			if na.nodeLocalDnsLinker != nil {
+				for _, record := range na.nodeLocalDnsLinker.flush(now) {
+					na.consumeLinkedDnsRecord(record, now)
+				}
+			}
+			if na.dnsAmplificationTracker != nil {
+				for _, record := range na.dnsAmplificationTracker.flush(now) {
+					na.consumeMergedDnsRecord(record, now)
+				}
+			}
+			if na.dnsDeduplicator != nil {
+				for _, record := range na.dnsDeduplicator.flush(now) {
+					na.consumeDnsRecord(record)
+				}
+			}
+		case <-na.stopChan:
+			timer.Stop()
+			return
+		}
+	}
+}
+
 func (na *NetworkAnalyzer) parseProtocols(mps *messagePairs) []*model.DataGroup {
 	// Step 1:  Static Config for port and protocol set in config file
 	port := mps.getPort()
@@ -518,40 +926,109 @@ func (na *NetworkAnalyzer) parseProtocols(mps *messagePairs) []*model.DataGroup
 		return na.getConnectFailRecords(mps)
 	}
 
-	// Step2 Cache protocol and port
+	// Step2 Cache protocol and connection
+	now := time.Now()
+	connKey := getConnectionKey(mps.requests.event)
+	if parser, ok := na.getConnectionParser(connKey, now); ok {
+		records := na.parseProtocol(mps, parser)
+		if records != nil {
+			na.hitParser(parser, now)
+			if protocol.NOSUPPORT == parser.GetProtocol() {
+				return na.getUnknownRecords(port, mps, records)
+			}
+			na.switchUpgradedConnection(connKey, parser, records, now)
+			return records
+		}
+		// The upgraded connections keep the protocol, as their messages are not recognized by the
+		// other parsers. The data not parsed is recorded with the protocol only.
+		if upgradedProtocols[parser.GetProtocol()] {
+			return na.getRecords(mps, parser.GetProtocol(), nil)
+		}
+		// The connection may switch to another protocol, so it is classified again.
+	}
+
+	// Step3 Cache protocol and port
 	// TODO There is concurrent modify case when looping. Considering threadsafe.
 	cacheParsers, ok := na.parserFactory.GetCachedParsersByPort(port)
 	if ok {
 		for _, parser := range cacheParsers {
+			// The generic parser cached for the port is skipped, otherwise one connection with an unknown
+			// protocol would make the other connections on the same port unrecognized. The generic result
+			// is cached for the connection in step 2 instead.
+			if protocol.NOSUPPORT == parser.GetProtocol() {
+				continue
+			}
 			records := na.parseProtocol(mps, parser)
 			if records != nil {
-				if protocol.NOSUPPORT == parser.GetProtocol() {
-					// Reset mapping for  generic and port when exceed threshold so as to parsed by other protcols.
-					if parser.AddPortCount(port) == CACHE_RESET_THRESHOLD {
-						parser.ResetPort(port)
-						na.parserFactory.RemoveCachedParser(port, parser)
-					}
-				}
+				na.hitParser(parser, now)
+				na.setConnectionParser(connKey, parser, now)
+				na.switchUpgradedConnection(connKey, parser, records, now)
 				return records
 			}
 		}
 	}
 
-	// Step3 Loop all protocols
-	for _, parser := range na.parsers {
-		records := na.parseProtocol(mps, parser)
-		if records != nil {
-			// Add mapping for port and protocol when exceed threshold
-			if parser.AddPortCount(port) == CACHE_ADD_THRESHOLD {
-				na.parserFactory.AddCachedParser(port, parser)
-			}
-			return records
+	// Step4 Loop all protocols
+	if parser, records, exhaustive := na.classifyProtocol(mps, now); records != nil {
+		// Add mapping for port and protocol when exceed threshold
+		if parser.AddPortCount(port) == CACHE_ADD_THRESHOLD {
+			na.parserFactory.AddCachedParser(port, parser)
+		}
+		if exhaustive {
+			na.setConnectionParser(connKey, parser, now)
+		}
+		na.switchUpgradedConnection(connKey, parser, records, now)
+		if protocol.NOSUPPORT == parser.GetProtocol() {
+			return na.getUnknownRecords(port, mps, records)
 		}
+		return records
+	}
+	if na.dropUnknownPorts[port] {
+		return []*model.DataGroup{}
 	}
+	na.profileUnknownPayload(port, mps)
 	return na.getRecords(mps, protocol.NOSUPPORT, nil)
 }
 
+// getUnknownRecords returns the records parsed by the generic parser, or nothing if the unknown
+// traffic of the port should be dropped.
+func (na *NetworkAnalyzer) getUnknownRecords(port uint32, mps *messagePairs, records []*model.DataGroup) []*model.DataGroup {
+	if na.dropUnknownPorts[port] {
+		for _, record := range records {
+			na.dataGroupPool.Free(record)
+		}
+		return []*model.DataGroup{}
+	}
+	na.profileUnknownPayload(port, mps)
+	return records
+}
+
+// classifyProtocol tries all the parsers in order, or tier by tier if the parser tiering is enabled.
+// exhaustive is false if some parsers are skipped.
+func (na *NetworkAnalyzer) classifyProtocol(mps *messagePairs, now time.Time) (*protocol.ProtocolParser, []*model.DataGroup, bool) {
+	if na.parserTiers != nil {
+		return na.parserTiers.classify(now, func(parser *protocol.ProtocolParser) []*model.DataGroup {
+			return na.parseProtocol(mps, parser)
+		})
+	}
+	for _, parser := range na.parsers {
+		if records := na.parseProtocol(mps, parser); records != nil {
+			return parser, records, true
+		}
+	}
+	return nil, nil, true
+}
+
+func (na *NetworkAnalyzer) hitParser(parser *protocol.ProtocolParser, now time.Time) {
+	if na.parserTiers != nil {
+		na.parserTiers.hit(parser, now)
+	}
+}
+
 func (na *NetworkAnalyzer) parseProtocol(mps *messagePairs, parser *protocol.ProtocolParser) []*model.DataGroup {
+	// The failed attempts are counted as well, as they are part of the cost of enabling the parser.
+	start := na.parserCostSampler.Start()
+	defer na.parserCostSampler.Stop(parser.GetProtocol(), start)
 	if parser.MultiRequests() {
 		// Not mergable requests
 		return na.parseMultipleRequests(mps, parser)
@@ -580,26 +1057,33 @@ func (na *NetworkAnalyzer) parseProtocol(mps *messagePairs, parser *protocol.Pro
 }
 
 // parseMultipleRequests parses the messagePairs when we know there could be multiple read requests.
-// This is used only when the protocol is DNS now.
+// This is used by the protocols whose responses are matched with the requests by the ids, e.g. DNS and bRPC.
+// The payload of one event may carry multiple messages if the parser has a splitter.
 func (na *NetworkAnalyzer) parseMultipleRequests(mps *messagePairs, parser *protocol.ProtocolParser) []*model.DataGroup {
 	// Match with key when disordering.
 	size := mps.requests.size()
-	parsedReqMsgs := make([]*protocol.PayloadMessage, size)
+	parsedReqMsgs := make([]*protocol.PayloadMessage, 0, size)
+	// The request event of each parsed message.
+	reqEvents := make([]*model.KindlingEvent, 0, size)
 	for i := 0; i < size; i++ {
 		req := mps.requests.getEvent(i)
-		requestMsg := protocol.NewRequestMessage(req.GetData())
-		if !parser.ParseRequest(requestMsg) {
-			// Parse failure
-			return nil
+		for _, data := range parser.Split(req.GetData()) {
+			requestMsg := protocol.NewRequestMessage(data)
+			if !parser.ParseRequest(requestMsg) {
+				// Parse failure
+				return nil
+			}
+			parsedReqMsgs = append(parsedReqMsgs, requestMsg)
+			reqEvents = append(reqEvents, req)
 		}
-		parsedReqMsgs[i] = requestMsg
 	}
 
 	records := make([]*model.DataGroup, 0)
 	if mps.responses == nil {
-		size := mps.requests.size()
-		for i := 0; i < size; i++ {
-			req := mps.requests.getEvent(i)
+		for i, req := range reqEvents {
+			if parsedReqMsgs[i].GetAttributes().GetBoolValue(constlabels.Oneway) {
+				continue
+			}
 			mp := &messagePair{
 				request:  req,
 				response: nil,
@@ -613,30 +1097,41 @@ func (na *NetworkAnalyzer) parseMultipleRequests(mps *messagePairs, parser *prot
 		size := mps.responses.size()
 		for i := 0; i < size; i++ {
 			resp := mps.responses.getEvent(i)
-			responseMsg := protocol.NewResponseMessage(resp.GetData(), model.NewAttributeMap())
-			if !parser.ParseResponse(responseMsg) {
-				// Parse failure
-				return nil
-			}
-			// Match Request with response
-			matchIdx := parser.PairMatch(parsedReqMsgs, responseMsg)
-			if matchIdx == -1 {
-				return nil
-			}
-			matchedRequestIdx[matchIdx] = true
+			for _, data := range parser.Split(resp.GetData()) {
+				responseMsg := protocol.NewResponseMessage(data, model.NewAttributeMap())
+				if !parser.ParseResponse(responseMsg) {
+					// Parse failure
+					return nil
+				}
+				// The messages pushed by the servers, e.g. PUBLISH of MQTT delivered to the subscribers,
+				// are not the responses of any request.
+				if responseMsg.GetAttributes().GetBoolValue(constlabels.Oneway) {
+					continue
+				}
+				// Match Request with response
+				matchIdx := parser.PairMatch(parsedReqMsgs, responseMsg)
+				if matchIdx == -1 {
+					return nil
+				}
+				matchedRequestIdx[matchIdx] = true
 
-			mp := &messagePair{
-				request:  mps.requests.getEvent(matchIdx),
-				response: resp,
-				natTuple: mps.natTuple,
+				mp := &messagePair{
+					request:  reqEvents[matchIdx],
+					response: resp,
+					natTuple: mps.natTuple,
+				}
+				// The labels of the request, e.g. the method, are kept unless the response has them too.
+				attributes := parsedReqMsgs[matchIdx].GetAttributes().Clone()
+				attributes.Merge(responseMsg.GetAttributes())
+				records = append(records, na.getRecordWithSinglePair(mp, parser.GetProtocol(), attributes))
 			}
-			records = append(records, na.getRecordWithSinglePair(mp, parser.GetProtocol(), responseMsg.GetAttributes()))
 		}
 		// 498 Case
-		reqSize := mps.requests.size()
-		for i := 0; i < reqSize; i++ {
-			req := mps.requests.getEvent(i)
+		for i, req := range reqEvents {
 			if _, matched := matchedRequestIdx[i]; !matched {
+				if parsedReqMsgs[i].GetAttributes().GetBoolValue(constlabels.Oneway) {
+					continue
+				}
 				mp := &messagePair{
 					request:  req,
 					response: nil,
@@ -677,12 +1172,15 @@ func (na *NetworkAnalyzer) getRecords(mps *messagePairs, protocol string, attrib
 	evt := mps.requests.event
 	// See the issue https://github.com/KindlingProject/kindling/issues/388 for details.
 	if attributes != nil && attributes.HasAttribute(constlabels.HttpContinue) {
-		if pairInterface, ok := na.requestMonitor.Load(getMessagePairKey(evt)); ok {
+		if pairInterface, ok := na.requestMonitor.Load(mps.getKey()); ok {
 			var oldPairs = pairInterface.(*messagePairs)
 			oldPairs.putRequestBack(mps.requests)
 		}
 		return []*model.DataGroup{}
 	}
+	if na.isDroppedRequest(protocol, evt, attributes) {
+		return []*model.DataGroup{}
+	}
 
 	slow := false
 	if mps.responses != nil {
@@ -693,6 +1191,16 @@ func (na *NetworkAnalyzer) getRecords(mps *messagePairs, protocol string, attrib
 	labels := ret.Labels
 	labels.UpdateAddIntValue(constlabels.Pid, int64(evt.GetPid()))
 	addMessagePairsTid(labels, mps)
+	if na.threadNameResolver != nil {
+		var requestEvt, responseEvt *model.KindlingEvent
+		if mps.requests != nil {
+			requestEvt = mps.requests.event
+		}
+		if mps.responses != nil {
+			responseEvt = mps.responses.event
+		}
+		na.addThreadNames(labels, requestEvt, responseEvt)
+	}
 	labels.UpdateAddStringValue(constlabels.Comm, evt.GetComm())
 	labels.UpdateAddStringValue(constlabels.SrcIp, evt.GetSip())
 	labels.UpdateAddStringValue(constlabels.DstIp, evt.GetDip())
@@ -704,8 +1212,14 @@ func (na *NetworkAnalyzer) getRecords(mps *messagePairs, protocol string, attrib
 	labels.UpdateAddBoolValue(constlabels.IsError, false)
 	labels.UpdateAddIntValue(constlabels.ErrorType, int64(constlabels.NoError))
 	labels.UpdateAddBoolValue(constlabels.IsSlow, slow)
+	if slow && na.syscallTracker != nil {
+		na.addSyscallBreakdown(labels, mps)
+	}
 	labels.UpdateAddBoolValue(constlabels.IsServer, evt.GetCtx().GetFdInfo().Role)
 	labels.UpdateAddStringValue(constlabels.Protocol, protocol)
+	if mps.isPayloadTruncated() {
+		labels.UpdateAddBoolValue(constlabels.PayloadTruncated, true)
+	}
 
 	labels.Merge(attributes)
 
@@ -714,14 +1228,18 @@ func (na *NetworkAnalyzer) getRecords(mps *messagePairs, protocol string, attrib
 		labels.UpdateAddIntValue(constlabels.EndTimestamp, int64(endTimestamp))
 	}
 
-	if mps.responses == nil {
-		addProtocolPayload(protocol, labels, mps.requests.getData(), nil)
-	} else {
-		addProtocolPayload(protocol, labels, mps.requests.getData(), mps.responses.getData())
+	noResponse := mps.responses == nil && !na.isOneway(mps.getPort(), protocol)
+	// The payloads are only exported with the traces, so they are not built for the requests sampled away.
+	if na.sampleNormalRequest(labels, noResponse) {
+		if mps.responses == nil {
+			addProtocolPayload(protocol, labels, mps.requests.getData(), nil)
+		} else {
+			addProtocolPayload(protocol, labels, mps.requests.getData(), mps.responses.getData())
+		}
 	}
 
 	// If no protocol error found, we check other errors
-	if !labels.GetBoolValue(constlabels.IsError) && mps.responses == nil {
+	if !labels.GetBoolValue(constlabels.IsError) && noResponse {
 		labels.AddBoolValue(constlabels.IsError, true)
 		labels.AddIntValue(constlabels.ErrorType, int64(constlabels.NoResponse))
 	}
@@ -730,6 +1248,9 @@ func (na *NetworkAnalyzer) getRecords(mps *messagePairs, protocol string, attrib
 		labels.UpdateAddStringValue(constlabels.DnatIp, mps.natTuple.ReplSrcIP.String())
 		labels.UpdateAddIntValue(constlabels.DnatPort, int64(mps.natTuple.ReplSrcPort))
 	}
+	if len(mps.natChain) > 1 {
+		labels.UpdateAddStringValue(constlabels.DnatChain, formatNatChain(mps.natChain))
+	}
 
 	ret.UpdateAddIntMetric(constvalues.ConnectTime, int64(mps.getConnectDuration()))
 	ret.UpdateAddIntMetric(constvalues.RequestSentTime, mps.getSentTime())
@@ -747,14 +1268,19 @@ func (na *NetworkAnalyzer) getRecords(mps *messagePairs, protocol string, attrib
 // getRecordWithSinglePair generates a record whose metrics are copied from the input messagePair,
 // instead of messagePairs. This is used only when there could be multiple real requests in messagePairs.
 // For now, only messagePairs with DNS protocol would run into this method.
+// It returns nil if the request is dropped.
 func (na *NetworkAnalyzer) getRecordWithSinglePair(mp *messagePair, protocol string, attributes *model.AttributeMap) *model.DataGroup {
 	evt := mp.request
+	if na.isDroppedRequest(protocol, evt, attributes) {
+		return nil
+	}
 
 	slow := na.isSlow(mp.getDuration(), protocol)
 	ret := na.dataGroupPool.Get()
 	labels := ret.Labels
 	labels.UpdateAddIntValue(constlabels.Pid, int64(evt.GetPid()))
 	addMessagePairTid(labels, mp)
+	na.addThreadNames(labels, mp.request, mp.response)
 	labels.UpdateAddStringValue(constlabels.Comm, evt.GetComm())
 	labels.UpdateAddStringValue(constlabels.SrcIp, evt.GetSip())
 	labels.UpdateAddStringValue(constlabels.DstIp, evt.GetDip())
@@ -768,19 +1294,25 @@ func (na *NetworkAnalyzer) getRecordWithSinglePair(mp *messagePair, protocol str
 	labels.UpdateAddBoolValue(constlabels.IsSlow, slow)
 	labels.UpdateAddBoolValue(constlabels.IsServer, evt.GetCtx().GetFdInfo().Role)
 	labels.UpdateAddStringValue(constlabels.Protocol, protocol)
+	if mp.isPayloadTruncated() {
+		labels.UpdateAddBoolValue(constlabels.PayloadTruncated, true)
+	}
 
 	labels.Merge(attributes)
 	if mp.response != nil {
 		labels.UpdateAddIntValue(constlabels.EndTimestamp, int64(mp.response.Timestamp))
 	}
-	if mp.response == nil {
-		addProtocolPayload(protocol, labels, evt.GetData(), nil)
-	} else {
-		addProtocolPayload(protocol, labels, evt.GetData(), mp.response.GetData())
+	noResponse := mp.response == nil && !na.isOneway(evt.GetDport(), protocol)
+	if na.sampleNormalRequest(labels, noResponse) {
+		if mp.response == nil {
+			addProtocolPayload(protocol, labels, evt.GetData(), nil)
+		} else {
+			addProtocolPayload(protocol, labels, evt.GetData(), mp.response.GetData())
+		}
 	}
 
 	// If no protocol error found, we check other errors
-	if !labels.GetBoolValue(constlabels.IsError) && mp.response == nil {
+	if !labels.GetBoolValue(constlabels.IsError) && noResponse {
 		labels.AddBoolValue(constlabels.IsError, true)
 		labels.AddIntValue(constlabels.ErrorType, int64(constlabels.NoResponse))
 	}
@@ -13,6 +13,7 @@ import (
 	viperpackage "github.com/spf13/viper"
 
 	"github.com/Kindling-project/kindling/collector/pkg/component"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer"
 	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
 	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/factory"
 	"github.com/Kindling-project/kindling/collector/pkg/component/consumer"
@@ -53,17 +54,29 @@ func TestDnsProtocol(t *testing.T) {
 		"dns/client-trace-sendmmg.yml")
 	testProtocol(t, "dns/client-event.yml",
 		"dns/client-trace-dns3.yml")
+	testProtocol(t, "dns/client-event.yml",
+		"dns/client-trace-aaaa.yml")
+	testProtocol(t, "dns/client-event.yml",
+		"dns/client-trace-srv.yml")
+	testProtocol(t, "dns/client-event.yml",
+		"dns/client-trace-edns.yml")
 	testProtocol(t, "dns/client-event-tcp.yml",
 		"dns/client-trace-tcp.yml")
+	testProtocol(t, "dns/client-event-tcp.yml",
+		"dns/client-trace-tcp-pipelined.yml")
 }
 
 func TestKafkaProtocol(t *testing.T) {
 	testProtocol(t, "kafka/provider-event.yml",
-		"kafka/provider-trace-produce-split.yml")
+		"kafka/provider-trace-produce-split.yml",
+		"kafka/provider-trace-produce-headers.yml")
 
 	testProtocol(t, "kafka/consumer-event.yml",
 		"kafka/consumer-trace-fetch-split.yml",
-		"kafka/consumer-trace-fetch-multi-topics.yml")
+		"kafka/consumer-trace-fetch-multi-topics.yml",
+		"kafka/consumer-trace-heartbeat.yml",
+		"kafka/consumer-trace-offset-commit.yml",
+		"kafka/consumer-trace-metadata.yml")
 }
 
 func TestDubboProtocol(t *testing.T) {
@@ -78,6 +91,129 @@ func TestRocketMQProtocol(t *testing.T) {
 		"rocketmq/server-trace-error.yml")
 }
 
+func TestMongodbProtocol(t *testing.T) {
+	testProtocol(t, "mongodb/server-event.yml",
+		"mongodb/server-trace-find.yml",
+		"mongodb/server-trace-error.yml")
+}
+
+func TestTarsProtocol(t *testing.T) {
+	testProtocol(t, "tars/server-event.yml",
+		"tars/server-trace-normal.yml",
+		"tars/server-trace-error.yml")
+}
+
+func TestGrpcProtocol(t *testing.T) {
+	testProtocol(t, "grpc/server-event.yml",
+		"grpc/server-trace-normal.yml",
+		"grpc/server-trace-error.yml")
+}
+
+func TestTripleProtocol(t *testing.T) {
+	testProtocol(t, "triple/server-event.yml",
+		"triple/server-trace-normal.yml",
+		"triple/server-trace-error.yml")
+}
+
+func TestHttp2Protocol(t *testing.T) {
+	testProtocol(t, "http2/server-event.yml",
+		"http2/server-trace-normal.yml",
+		"http2/server-trace-error.yml")
+}
+
+func TestQuicProtocol(t *testing.T) {
+	testProtocol(t, "quic/client-event.yml",
+		"quic/client-trace.yml")
+}
+
+func TestNtpProtocol(t *testing.T) {
+	testProtocol(t, "ntp/client-event.yml",
+		"ntp/client-trace.yml",
+		"ntp/client-trace-kod.yml")
+}
+
+func TestSipProtocol(t *testing.T) {
+	testProtocol(t, "sip/client-event.yml",
+		"sip/client-trace-invite.yml",
+		"sip/client-trace-register.yml",
+		"sip/client-trace-busy.yml")
+}
+
+func TestBrpcProtocol(t *testing.T) {
+	testProtocol(t, "brpc/server-event.yml",
+		"brpc/server-trace-normal.yml",
+		"brpc/server-trace-error.yml",
+		"brpc/server-trace-multi.yml")
+}
+
+func TestBoltProtocol(t *testing.T) {
+	testProtocol(t, "bolt/server-event.yml",
+		"bolt/server-trace-normal.yml",
+		"bolt/server-trace-error.yml")
+}
+
+func TestCassandraProtocol(t *testing.T) {
+	testProtocol(t, "cassandra/server-event.yml",
+		"cassandra/server-trace-normal.yml",
+		"cassandra/server-trace-error.yml",
+		"cassandra/server-trace-multi.yml")
+}
+
+func TestFtpProtocol(t *testing.T) {
+	testProtocol(t, "ftp/server-event.yml",
+		"ftp/server-trace-normal.yml",
+		"ftp/server-trace-error.yml")
+}
+
+func TestTlsProtocol(t *testing.T) {
+	testProtocol(t, "tls/client-event.yml",
+		"tls/client-trace-normal.yml",
+		"tls/client-trace-alert.yml")
+}
+
+func TestSshProtocol(t *testing.T) {
+	testProtocol(t, "ssh/server-event.yml",
+		"ssh/server-trace-normal.yml",
+		"ssh/server-trace-error.yml")
+}
+
+func TestMqttProtocol(t *testing.T) {
+	testProtocol(t, "mqtt/server-event.yml",
+		"mqtt/server-trace-normal.yml",
+		"mqtt/server-trace-error.yml",
+		"mqtt/server-trace-multi.yml",
+		"mqtt/server-trace-pushed.yml")
+}
+
+func TestLdapProtocol(t *testing.T) {
+	testProtocol(t, "ldap/server-event.yml",
+		"ldap/server-trace-bind.yml",
+		"ldap/server-trace-error.yml",
+		"ldap/server-trace-search.yml")
+}
+
+func TestOracleProtocol(t *testing.T) {
+	testProtocol(t, "oracle/server-event.yml",
+		"oracle/server-trace-connect.yml",
+		"oracle/server-trace-execute.yml",
+		"oracle/server-trace-error.yml")
+}
+
+func TestPostgresqlProtocol(t *testing.T) {
+	testProtocol(t, "postgresql/server-event.yml",
+		"postgresql/server-trace-query.yml",
+		"postgresql/server-trace-error.yml")
+}
+
+func TestWebsocketProtocol(t *testing.T) {
+	// The connection is parsed as WebSocket after it is upgraded.
+	testProtocol(t, "websocket/server-event.yml",
+		"websocket/server-trace-upgrade.yml",
+		"websocket/server-trace-message.yml",
+		"websocket/server-trace-oneway.yml",
+		"websocket/server-trace-close.yml")
+}
+
 func TestNoSupportProtocol(t *testing.T) {
 	testProtocol(t, "nosupport/server-event.yml",
 		"nosupport/server-trace-normal.yml",
@@ -124,6 +260,8 @@ func prepareNetworkAnalyzer() *NetworkAnalyzer {
 			dataGroupPool: &NoCacheDataGroupPool{},
 			nextConsumers: []consumer.Consumer{&NopProcessor{}},
 			telemetry:     component.NewDefaultTelemetryTools(),
+
+			parserCostSampler: analyzer.NewCostSampler(analyzer.DefaultCostSampleRate),
 		}
 		na.staticPortMap = map[uint32]string{}
 		for _, config := range na.cfg.ProtocolConfigs {
@@ -171,7 +309,9 @@ func testProtocol(t *testing.T, eventYaml string, traceYamls ...string) {
 				_ = na.processEvent(event)
 			}
 			if model.L4Proto(eventCommon.Ctx.Fd.Protocol) == model.L4Proto_TCP {
-				if pairInterface, ok := na.requestMonitor.Load(getMessagePairKey(events[0])); ok {
+				key := getMessagePairKey(events[0])
+				key.generation = na.getGeneration(events[0], false)
+				if pairInterface, ok := na.requestMonitor.Load(key); ok {
 					var oldPairs = pairInterface.(*messagePairs)
 					_ = na.distributeTraceMetric(oldPairs, nil)
 				}
@@ -0,0 +1,79 @@
+package network
+
+import "github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+
+// onewayProtocols are the protocols whose messages are not answered by the responses, so the messages
+// without responses are not errors.
+var onewayProtocols = map[string]bool{
+	protocol.WEBSOCKET: true,
+}
+
+// initNoResponseThresholds builds the overrides of the no-response threshold from the protocol configs.
+func (na *NetworkAnalyzer) initNoResponseThresholds() {
+	na.protocolNoResponseThresholds = make(map[string]int)
+	na.portNoResponseThresholds = make(map[uint32]int)
+	na.onewayPorts = make(map[uint32]bool)
+	for _, config := range na.cfg.ProtocolConfigs {
+		if config.NoResponseThreshold > 0 {
+			na.protocolNoResponseThresholds[config.Key] = config.NoResponseThreshold
+		}
+		for _, portThreshold := range config.PortNoResponseThresholds {
+			if portThreshold.Threshold > 0 {
+				na.portNoResponseThresholds[portThreshold.Port] = portThreshold.Threshold
+			}
+		}
+		for _, port := range config.OnewayPorts {
+			na.onewayPorts[port] = true
+		}
+	}
+}
+
+// getNoResponseThreshold returns the threshold in seconds. The override of the port takes precedence
+// over the one of the protocol, which takes precedence over the global one.
+func (na *NetworkAnalyzer) getNoResponseThreshold(port uint32, protocolName string) int {
+	if threshold, ok := na.portNoResponseThresholds[port]; ok {
+		return threshold
+	}
+	if threshold, ok := na.protocolNoResponseThresholds[protocolName]; ok {
+		return threshold
+	}
+	return na.cfg.getNoResponseThreshold()
+}
+
+// getMessagePairsNoResponseThreshold returns the threshold of the messagePairs, which are not parsed
+// yet. The protocol is taken from the static port config or the protocol recognized for the connection.
+func (na *NetworkAnalyzer) getMessagePairsNoResponseThreshold(mps *messagePairs) int {
+	port := mps.getPort()
+	protocolName, ok := na.staticPortMap[port]
+	if !ok && mps.requests != nil && len(na.protocolNoResponseThresholds) > 0 {
+		if value, ok := na.connectionProtocols.Load(getConnectionKey(mps.requests.event)); ok {
+			protocolName = value.(*connectionProtocol).parser.GetProtocol()
+		}
+	}
+	return na.getNoResponseThreshold(port, protocolName)
+}
+
+func (na *NetworkAnalyzer) isOnewayPort(port uint32) bool {
+	return na.onewayPorts[port]
+}
+
+// isOneway returns whether the requests of the port or the protocol are expected to have no responses.
+func (na *NetworkAnalyzer) isOneway(port uint32, protocolName string) bool {
+	return na.onewayPorts[port] || onewayProtocols[protocolName]
+}
+
+// getMaxNoResponseThreshold returns the maximum threshold including the overrides.
+func (na *NetworkAnalyzer) getMaxNoResponseThreshold() int {
+	max := na.cfg.getNoResponseThreshold()
+	for _, threshold := range na.protocolNoResponseThresholds {
+		if threshold > max {
+			max = threshold
+		}
+	}
+	for _, threshold := range na.portNoResponseThresholds {
+		if threshold > max {
+			max = threshold
+		}
+	}
+	return max
+}
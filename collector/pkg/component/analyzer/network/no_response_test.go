@@ -0,0 +1,50 @@
+package network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/factory"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+)
+
+func TestGetNoResponseThreshold(t *testing.T) {
+	cfg := NewDefaultConfig()
+	cfg.ProtocolConfigs = []ProtocolConfig{
+		{
+			Key:                      protocol.HTTP,
+			Ports:                    []uint32{80},
+			NoResponseThreshold:      600,
+			PortNoResponseThresholds: []PortNoResponseThreshold{{Port: 8080, Threshold: 1800}},
+		},
+		{
+			Key:                 protocol.DNS,
+			Ports:               []uint32{53},
+			NoResponseThreshold: 5,
+			OnewayPorts:         []uint32{5353},
+		},
+	}
+	na := &NetworkAnalyzer{cfg: cfg, staticPortMap: map[uint32]string{80: protocol.HTTP, 53: protocol.DNS}}
+	na.initNoResponseThresholds()
+
+	newMessagePairs := func(dport uint32) *messagePairs {
+		evt := &model.KindlingEvent{Ctx: model.Context{FdInfo: model.Fd{Dport: dport}}}
+		return &messagePairs{requests: newEvents(evt, 100)}
+	}
+	assert.Equal(t, 600, na.getMessagePairsNoResponseThreshold(newMessagePairs(80)))
+	assert.Equal(t, 5, na.getMessagePairsNoResponseThreshold(newMessagePairs(53)))
+	// The port override takes precedence over the protocol.
+	assert.Equal(t, 1800, na.getMessagePairsNoResponseThreshold(newMessagePairs(8080)))
+	assert.Equal(t, 120, na.getMessagePairsNoResponseThreshold(newMessagePairs(9000)))
+
+	// The protocol recognized for the connection is used if the port is not configured.
+	mps := newMessagePairs(9000)
+	na.setConnectionParser(getConnectionKey(mps.requests.event), factory.NewParserFactory().GetParser(protocol.HTTP), time.Now())
+	assert.Equal(t, 600, na.getMessagePairsNoResponseThreshold(mps))
+
+	assert.True(t, na.isOnewayPort(5353))
+	assert.False(t, na.isOnewayPort(53))
+}
@@ -0,0 +1,159 @@
+package network
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constvalues"
+)
+
+type nodeLocalDnsEntry struct {
+	record   *model.DataGroup
+	expireAt time.Time
+}
+
+// nodeLocalDnsLinker links the query a pod sends to the NodeLocal DNSCache with the query the cache
+// forwards to the upstream on a cache miss. The upstream query is merged into the pod's record instead
+// of being reported separately, so the latency of one resolution is not counted twice.
+// It is safe for concurrent use.
+type nodeLocalDnsLinker struct {
+	cacheIps   map[string]bool
+	cacheComms map[string]bool
+	window     time.Duration
+
+	mutex sync.Mutex
+	// The records waiting for their counterparts, keyed by the domain.
+	podQueries      map[string][]*nodeLocalDnsEntry
+	upstreamQueries map[string][]*nodeLocalDnsEntry
+}
+
+func newNodeLocalDnsLinker(cacheIps []string, cacheComms []string, window time.Duration) *nodeLocalDnsLinker {
+	l := &nodeLocalDnsLinker{
+		cacheIps:        make(map[string]bool, len(cacheIps)),
+		cacheComms:      make(map[string]bool, len(cacheComms)),
+		window:          window,
+		podQueries:      make(map[string][]*nodeLocalDnsEntry),
+		upstreamQueries: make(map[string][]*nodeLocalDnsEntry),
+	}
+	for _, ip := range cacheIps {
+		l.cacheIps[ip] = true
+	}
+	for _, comm := range cacheComms {
+		l.cacheComms[comm] = true
+	}
+	return l
+}
+
+// isPodQuery returns true if the record is a query sent to the cache by a client.
+func (l *nodeLocalDnsLinker) isPodQuery(record *model.DataGroup) bool {
+	labels := record.Labels
+	return !labels.GetBoolValue(constlabels.IsServer) &&
+		l.cacheIps[labels.GetStringValue(constlabels.DstIp)] &&
+		!l.cacheComms[labels.GetStringValue(constlabels.Comm)]
+}
+
+// isUpstreamQuery returns true if the record is a query forwarded by the cache to the upstream.
+func (l *nodeLocalDnsLinker) isUpstreamQuery(record *model.DataGroup) bool {
+	labels := record.Labels
+	return !labels.GetBoolValue(constlabels.IsServer) &&
+		!l.cacheIps[labels.GetStringValue(constlabels.DstIp)] &&
+		l.cacheComms[labels.GetStringValue(constlabels.Comm)]
+}
+
+func getRecordEndTimestamp(record *model.DataGroup) uint64 {
+	return uint64(record.Labels.GetIntValue(constlabels.EndTimestamp))
+}
+
+// isForwardedBy returns true if the upstream query is sent while the pod's query is being resolved.
+func isForwardedBy(upstream *model.DataGroup, pod *model.DataGroup) bool {
+	return upstream.Timestamp >= pod.Timestamp && upstream.Timestamp <= getRecordEndTimestamp(pod)
+}
+
+// link marks the pod's query as a cache miss and adds the information of the upstream query.
+func link(pod *model.DataGroup, upstream *model.DataGroup) {
+	pod.Labels.UpdateAddBoolValue(constlabels.DnsCacheHit, false)
+	pod.Labels.UpdateAddStringValue(constlabels.DnsUpstreamIp, upstream.Labels.GetStringValue(constlabels.DstIp))
+	var upstreamLatency int64
+	if metric, ok := upstream.GetMetric(constvalues.RequestTotalTime); ok {
+		upstreamLatency = metric.GetInt().Value
+	}
+	pod.Labels.UpdateAddIntValue(constlabels.DnsUpstreamLatency, upstreamLatency)
+}
+
+// add handles a DNS record and returns the records that are ready to be reported. The caller still
+// owns the record. The records unrelated to the cache are returned as copies immediately.
+func (l *nodeLocalDnsLinker) add(record *model.DataGroup, now time.Time) []*model.DataGroup {
+	isPodQuery := l.isPodQuery(record)
+	if !isPodQuery && !l.isUpstreamQuery(record) {
+		return []*model.DataGroup{record.Clone()}
+	}
+	domain := record.Labels.GetStringValue(constlabels.DnsDomain)
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if isPodQuery {
+		upstreams := l.upstreamQueries[domain]
+		for i, upstream := range upstreams {
+			if isForwardedBy(upstream.record, record) {
+				l.upstreamQueries[domain] = append(upstreams[:i], upstreams[i+1:]...)
+				pod := record.Clone()
+				link(pod, upstream.record)
+				return []*model.DataGroup{pod}
+			}
+		}
+		l.podQueries[domain] = append(l.podQueries[domain], &nodeLocalDnsEntry{record: record.Clone(), expireAt: now.Add(l.window)})
+		return nil
+	}
+	pods := l.podQueries[domain]
+	for i, pod := range pods {
+		if isForwardedBy(record, pod.record) {
+			l.podQueries[domain] = append(pods[:i], pods[i+1:]...)
+			link(pod.record, record)
+			return []*model.DataGroup{pod.record}
+		}
+	}
+	l.upstreamQueries[domain] = append(l.upstreamQueries[domain], &nodeLocalDnsEntry{record: record.Clone(), expireAt: now.Add(l.window)})
+	return nil
+}
+
+// flush removes the records whose window has expired and returns them. The pod's queries that are
+// not forwarded are answered by the cache. The upstream queries that match no pod's query, like the
+// prefetches of the cache, are reported as they are.
+func (l *nodeLocalDnsLinker) flush(now time.Time) []*model.DataGroup {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	records := make([]*model.DataGroup, 0)
+	for domain, entries := range l.podQueries {
+		remaining := entries[:0]
+		for _, entry := range entries {
+			if now.Before(entry.expireAt) {
+				remaining = append(remaining, entry)
+				continue
+			}
+			entry.record.Labels.UpdateAddBoolValue(constlabels.DnsCacheHit, true)
+			records = append(records, entry.record)
+		}
+		if len(remaining) == 0 {
+			delete(l.podQueries, domain)
+		} else {
+			l.podQueries[domain] = remaining
+		}
+	}
+	for domain, entries := range l.upstreamQueries {
+		remaining := entries[:0]
+		for _, entry := range entries {
+			if now.Before(entry.expireAt) {
+				remaining = append(remaining, entry)
+				continue
+			}
+			records = append(records, entry.record)
+		}
+		if len(remaining) == 0 {
+			delete(l.upstreamQueries, domain)
+		} else {
+			l.upstreamQueries[domain] = remaining
+		}
+	}
+	return records
+}
@@ -0,0 +1,66 @@
+package network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constvalues"
+)
+
+const nodeLocalDnsIp = "169.254.20.10"
+
+func newNodeLocalDnsRecord(comm string, domain string, dstIp string, start uint64, end uint64) *model.DataGroup {
+	record := newDnsRecord(1, domain, dstIp, false)
+	record.Labels.AddStringValue(constlabels.Comm, comm)
+	record.Labels.AddIntValue(constlabels.EndTimestamp, int64(end))
+	record.Timestamp = start
+	record.AddIntMetricWithName(constvalues.RequestTotalTime, int64(end-start))
+	return record
+}
+
+func TestNodeLocalDnsLinker_CacheMiss(t *testing.T) {
+	now := time.Now()
+	l := newNodeLocalDnsLinker([]string{nodeLocalDnsIp}, []string{"node-cache"}, time.Second)
+
+	// The upstream query completes before the query of the pod.
+	assert.Empty(t, l.add(newNodeLocalDnsRecord("node-cache", "kindling.io.", "10.96.0.10", 1200, 1800), now))
+	records := l.add(newNodeLocalDnsRecord("java", "kindling.io.", nodeLocalDnsIp, 1000, 2000), now)
+	if assert.Len(t, records, 1) {
+		labels := records[0].Labels
+		assert.False(t, labels.GetBoolValue(constlabels.DnsCacheHit))
+		assert.Equal(t, "10.96.0.10", labels.GetStringValue(constlabels.DnsUpstreamIp))
+		assert.Equal(t, int64(600), labels.GetIntValue(constlabels.DnsUpstreamLatency))
+		assert.Equal(t, "java", labels.GetStringValue(constlabels.Comm))
+	}
+	// The upstream query is not reported separately.
+	assert.Empty(t, l.flush(now.Add(2*time.Second)))
+}
+
+func TestNodeLocalDnsLinker_CacheHit(t *testing.T) {
+	now := time.Now()
+	l := newNodeLocalDnsLinker([]string{nodeLocalDnsIp}, []string{"node-cache"}, time.Second)
+
+	assert.Empty(t, l.add(newNodeLocalDnsRecord("java", "kindling.io.", nodeLocalDnsIp, 1000, 2000), now))
+	// The upstream query for another domain or out of the query's time doesn't match.
+	assert.Empty(t, l.add(newNodeLocalDnsRecord("node-cache", "github.com.", "10.96.0.10", 1200, 1800), now))
+	assert.Empty(t, l.add(newNodeLocalDnsRecord("node-cache", "kindling.io.", "10.96.0.10", 3000, 3500), now))
+	// Other queries are returned immediately.
+	assert.Len(t, l.add(newNodeLocalDnsRecord("java", "kindling.io.", "10.96.0.10", 1000, 2000), now), 1)
+
+	assert.Empty(t, l.flush(now.Add(time.Second-time.Millisecond)))
+	records := l.flush(now.Add(time.Second))
+	assert.Len(t, records, 3)
+	for _, record := range records {
+		if record.Labels.GetStringValue(constlabels.Comm) == "java" {
+			assert.True(t, record.Labels.GetBoolValue(constlabels.DnsCacheHit))
+		} else {
+			assert.False(t, record.Labels.HasAttribute(constlabels.DnsCacheHit))
+		}
+	}
+	assert.Empty(t, l.podQueries)
+	assert.Empty(t, l.upstreamQueries)
+}
@@ -0,0 +1,121 @@
+package network
+
+import (
+	"strconv"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/ntp"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+	"github.com/Kindling-project/kindling/collector/pkg/model/timeunit"
+)
+
+// ntpKey pairs the NTP response with the request, whose transmit timestamp is echoed as the origin
+// timestamp of the response.
+type ntpKey struct {
+	udpKey
+	transmitTimestamp uint64
+}
+
+type ntpRequest struct {
+	event  *model.KindlingEvent
+	packet *ntp.Packet
+}
+
+// isUdpNtpEvent returns true if the UDP event is sent to the ports configured with the NTP key.
+func (na *NetworkAnalyzer) isUdpNtpEvent(evt *model.KindlingEvent) bool {
+	return na.ntpPorts[evt.GetDport()]
+}
+
+func (na *NetworkAnalyzer) analyseNtp(evt *model.KindlingEvent) error {
+	if evt.GetDataLen() <= 0 || evt.GetResVal() < 0 {
+		return nil
+	}
+	isRequest, err := evt.IsRequest()
+	if err != nil {
+		return err
+	}
+	key := getUdpKey(evt)
+	if isRequest {
+		if evt.Name == constnames.SendMMsgEvent {
+			for _, e := range model.ConvertSendmmsg(evt) {
+				na.consumeNtpRequest(e, key)
+			}
+		} else {
+			na.consumeNtpRequest(evt, key)
+		}
+		return nil
+	}
+
+	packet, ok := ntp.ParsePacket(evt.GetData())
+	if !ok {
+		return nil
+	}
+	value, ok := na.ntpMonitor.LoadAndDelete(ntpKey{udpKey: key, transmitTimestamp: packet.OriginTimestamp})
+	if !ok {
+		return nil
+	}
+	request := value.(*ntpRequest)
+	mp := &messagePair{
+		request:  request.event,
+		response: evt,
+	}
+	return na.distributeNtpRecord(mp, getNtpAttributes(request, evt, packet))
+}
+
+func (na *NetworkAnalyzer) consumeNtpRequest(evt *model.KindlingEvent, key udpKey) {
+	packet, ok := ntp.ParsePacket(evt.GetData())
+	if !ok {
+		return
+	}
+	na.ntpMonitor.Store(ntpKey{udpKey: key, transmitTimestamp: packet.TransmitTimestamp}, &ntpRequest{
+		event:  evt,
+		packet: packet,
+	})
+}
+
+func (na *NetworkAnalyzer) distributeNtpRecord(mp *messagePair, attributes *model.AttributeMap) error {
+	return na.distributeRecords([]*model.DataGroup{na.getRecordWithSinglePair(mp, protocol.NTP, attributes)})
+}
+
+// getNtpAttributes returns the labels of the request and the response, which is nil if there is no response.
+// The clock offset is only known by the client, whose events are timestamped by the local clock.
+func getNtpAttributes(request *ntpRequest, responseEvent *model.KindlingEvent, response *ntp.Packet) *model.AttributeMap {
+	attributes := model.NewAttributeMap()
+	mode := ntp.ModeString(request.packet.Mode)
+	attributes.AddStringValue(constlabels.ProtocolVersion, strconv.Itoa(int(request.packet.Version)))
+	attributes.AddStringValue(constlabels.NtpMode, mode)
+	attributes.AddStringValue(constlabels.ContentKey, mode)
+	if response == nil {
+		return attributes
+	}
+	attributes.AddIntValue(constlabels.NtpStratum, int64(response.Stratum))
+	if response.IsKissOfDeath() {
+		attributes.AddStringValue(constlabels.NtpKissCode, response.KissCode())
+		attributes.AddBoolValue(constlabels.IsError, true)
+		attributes.AddIntValue(constlabels.ErrorType, int64(constlabels.ProtocolError))
+	} else if response.Mode == ntp.ModeServer && !request.event.GetCtx().GetFdInfo().GetRole() {
+		offset := ntp.ClockOffset(response, int64(request.event.Timestamp), int64(responseEvent.Timestamp))
+		attributes.AddIntValue(constlabels.NtpOffset, offset)
+	}
+	return attributes
+}
+
+// cleanNtpRequests records the requests not answered within the no-response threshold.
+func (na *NetworkAnalyzer) cleanNtpRequests() {
+	na.ntpMonitor.Range(func(k, v interface{}) bool {
+		request := v.(*ntpRequest)
+		threshold := timeunit.FromSeconds(na.getNoResponseThreshold(k.(ntpKey).dport, protocol.NTP))
+		if timeunit.Now().Sub(timeunit.Timestamp(request.event.Timestamp)) < threshold {
+			return true
+		}
+		na.ntpMonitor.Delete(k)
+		// No Response Request
+		mp := &messagePair{
+			request: request.event,
+		}
+		_ = na.distributeNtpRecord(mp, getNtpAttributes(request, nil, nil))
+		return true
+	})
+}
@@ -0,0 +1,113 @@
+package network
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+)
+
+// parserTiers splits the parsers into a hot tier and a cold tier by their latest matches. Most nodes
+// only run one or two protocols, so trying every parser on each unknown payload wastes CPU. The hot
+// parsers are tried first as usual, while the cold ones are tried only once every checkInterval
+// payloads that no hot parser recognizes. A cold parser is promoted back as soon as it matches.
+// It is safe for concurrent use.
+type parserTiers struct {
+	// parsers keeps the configured order without the generic parser.
+	parsers []*protocol.ProtocolParser
+	// lastHits maps the parsers to the unix nanoseconds of their latest matches.
+	lastHits map[*protocol.ProtocolParser]*int64
+	generic  *protocol.ProtocolParser
+	// The unit is nanosecond.
+	coldAfter     int64
+	checkInterval uint64
+	unknownCount  uint64
+}
+
+// newParserTiers creates the tiers in which all the parsers are hot at first.
+func newParserTiers(parsers []*protocol.ProtocolParser, coldAfter time.Duration, checkInterval int, now time.Time) *parserTiers {
+	t := &parserTiers{
+		lastHits:      make(map[*protocol.ProtocolParser]*int64, len(parsers)),
+		coldAfter:     int64(coldAfter),
+		checkInterval: uint64(checkInterval),
+	}
+	for _, parser := range parsers {
+		if protocol.NOSUPPORT == parser.GetProtocol() {
+			t.generic = parser
+			continue
+		}
+		lastHit := now.UnixNano()
+		t.parsers = append(t.parsers, parser)
+		t.lastHits[parser] = &lastHit
+	}
+	return t
+}
+
+// inherit copies the latest matches of the parsers that exist in the old tiers as well.
+func (t *parserTiers) inherit(old *parserTiers) {
+	for parser, lastHit := range t.lastHits {
+		if oldHit, ok := old.lastHits[parser]; ok {
+			atomic.StoreInt64(lastHit, atomic.LoadInt64(oldHit))
+		}
+	}
+	atomic.StoreUint64(&t.unknownCount, atomic.LoadUint64(&old.unknownCount))
+}
+
+func (t *parserTiers) isCold(parser *protocol.ProtocolParser, nowTs int64) bool {
+	return nowTs-atomic.LoadInt64(t.lastHits[parser]) > t.coldAfter
+}
+
+// hit records the match of the parser, which promotes it to the hot tier if it is cold.
+func (t *parserTiers) hit(parser *protocol.ProtocolParser, now time.Time) {
+	if lastHit, ok := t.lastHits[parser]; ok {
+		atomic.StoreInt64(lastHit, now.UnixNano())
+	}
+}
+
+// classify tries the parsers tier by tier and falls back to the generic parser. exhaustive is false
+// if the cold parsers are skipped, in which case the generic result is not reliable enough to be cached.
+func (t *parserTiers) classify(now time.Time, parse func(*protocol.ProtocolParser) []*model.DataGroup) (
+	parser *protocol.ProtocolParser, records []*model.DataGroup, exhaustive bool) {
+	nowTs := now.UnixNano()
+	hasCold := false
+	for _, parser := range t.parsers {
+		if t.isCold(parser, nowTs) {
+			hasCold = true
+			continue
+		}
+		if records := parse(parser); records != nil {
+			t.hit(parser, now)
+			return parser, records, true
+		}
+	}
+	exhaustive = !hasCold || atomic.AddUint64(&t.unknownCount, 1)%t.checkInterval == 0
+	if hasCold && exhaustive {
+		for _, parser := range t.parsers {
+			if !t.isCold(parser, nowTs) {
+				continue
+			}
+			if records := parse(parser); records != nil {
+				t.hit(parser, now)
+				return parser, records, true
+			}
+		}
+	}
+	if t.generic != nil {
+		if records := parse(t.generic); records != nil {
+			return t.generic, records, exhaustive
+		}
+	}
+	return nil, nil, exhaustive
+}
+
+// getColdProtocols returns the protocols of the parsers in the cold tier.
+func (t *parserTiers) getColdProtocols(now time.Time) []string {
+	protocols := make([]string, 0)
+	for _, parser := range t.parsers {
+		if t.isCold(parser, now.UnixNano()) {
+			protocols = append(protocols, parser.GetProtocol())
+		}
+	}
+	return protocols
+}
@@ -0,0 +1,67 @@
+package network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/factory"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+)
+
+func TestParserTiers(t *testing.T) {
+	parserFactory := factory.NewParserFactory()
+	http := parserFactory.GetParser(protocol.HTTP)
+	mysql := parserFactory.GetParser(protocol.MYSQL)
+	generic := parserFactory.GetGenericParser()
+	start := time.Unix(0, 0)
+	tiers := newParserTiers([]*protocol.ProtocolParser{http, mysql, generic}, 10*time.Second, 3, start)
+
+	var tried []string
+	payloadOf := func(matched string) func(*protocol.ProtocolParser) []*model.DataGroup {
+		tried = tried[:0]
+		return func(parser *protocol.ProtocolParser) []*model.DataGroup {
+			tried = append(tried, parser.GetProtocol())
+			if parser.GetProtocol() == matched || parser.GetProtocol() == protocol.NOSUPPORT {
+				return []*model.DataGroup{}
+			}
+			return nil
+		}
+	}
+
+	// Both parsers are hot at first.
+	parser, _, exhaustive := tiers.classify(start.Add(5*time.Second), payloadOf(protocol.HTTP))
+	assert.Equal(t, http, parser)
+	assert.True(t, exhaustive)
+
+	// MySQL has not matched for more than 10s, so it is skipped except for every 3rd unknown payload.
+	now := start.Add(12 * time.Second)
+	for i := 1; i <= 3; i++ {
+		parser, _, exhaustive = tiers.classify(now, payloadOf(""))
+		assert.Equal(t, generic, parser)
+		if i < 3 {
+			assert.Equal(t, []string{protocol.HTTP, protocol.NOSUPPORT}, tried)
+			assert.False(t, exhaustive)
+		} else {
+			assert.Equal(t, []string{protocol.HTTP, protocol.MYSQL, protocol.NOSUPPORT}, tried)
+			assert.True(t, exhaustive)
+		}
+	}
+
+	// The cold parser is promoted back once it matches.
+	for i := 0; i < 3; i++ {
+		parser, _, _ = tiers.classify(now, payloadOf(protocol.MYSQL))
+	}
+	assert.Equal(t, mysql, parser)
+	parser, _, exhaustive = tiers.classify(now, payloadOf(protocol.MYSQL))
+	assert.Equal(t, mysql, parser)
+	assert.Equal(t, []string{protocol.HTTP, protocol.MYSQL}, tried)
+	assert.True(t, exhaustive)
+
+	// The matches through the caches keep the parser hot as well.
+	tiers.hit(http, start.Add(20*time.Second))
+	assert.False(t, tiers.isCold(http, start.Add(25*time.Second).UnixNano()))
+	assert.True(t, tiers.isCold(mysql, start.Add(25*time.Second).UnixNano()))
+}
@@ -0,0 +1,96 @@
+package payloadprofile
+
+import "math"
+
+const (
+	// histogramBins holds the counts of the 256 byte values followed by the counts of the bigrams,
+	// which are hashed into 256 bins.
+	histogramBins = 512
+	bigramOffset  = 256
+)
+
+// histogram is the byte and bigram histogram of the prefix of a payload, normalized to the unit length
+// so that the clusters are compared by the cosine similarity.
+type histogram [histogramBins]float32
+
+func newHistogram(prefix []byte) *histogram {
+	h := &histogram{}
+	for i, b := range prefix {
+		h[b]++
+		if i > 0 {
+			h[bigramOffset+bigramBin(prefix[i-1], b)]++
+		}
+	}
+	h.normalize()
+	return h
+}
+
+// bigramBin hashes the bigram. The bins of the common bigrams rarely collide as the prefixes are short.
+func bigramBin(first, second byte) int {
+	return (int(first)*31 + int(second)) & 0xff
+}
+
+func (h *histogram) normalize() {
+	norm := h.norm()
+	if norm == 0 {
+		return
+	}
+	for i := range h {
+		h[i] = float32(float64(h[i]) / norm)
+	}
+}
+
+func (h *histogram) norm() float64 {
+	return math.Sqrt(h.dot(h))
+}
+
+func (h *histogram) dot(other *histogram) float64 {
+	var sum float64
+	for i := range h {
+		sum += float64(h[i]) * float64(other[i])
+	}
+	return sum
+}
+
+// cluster is a group of payloads with similar histograms. Its centroid is the sum of the histograms
+// of its payloads, whose norm is cached for the cosine similarity.
+type cluster struct {
+	sum   histogram
+	norm  float64
+	count int
+	// sample is the prefix of the first payload of the cluster.
+	sample []byte
+	// commonPrefix is the longest prefix shared by all payloads of the cluster.
+	commonPrefix []byte
+}
+
+func newCluster(prefix []byte, h *histogram) *cluster {
+	return &cluster{
+		sum:          *h,
+		norm:         h.norm(),
+		count:        1,
+		sample:       append([]byte(nil), prefix...),
+		commonPrefix: append([]byte(nil), prefix...),
+	}
+}
+
+// similarity returns the cosine similarity between the normalized histogram and the centroid.
+func (c *cluster) similarity(h *histogram) float64 {
+	if c.norm == 0 {
+		return 0
+	}
+	return h.dot(&c.sum) / c.norm
+}
+
+func (c *cluster) add(prefix []byte, h *histogram) {
+	c.count++
+	for i := range c.sum {
+		c.sum[i] += h[i]
+	}
+	c.norm = c.sum.norm()
+	length := 0
+	for length < len(c.commonPrefix) && length < len(prefix) && c.commonPrefix[length] == prefix[length] {
+		length++
+	}
+	c.commonPrefix = c.commonPrefix[:length]
+}
@@ -0,0 +1,27 @@
+package payloadprofile
+
+type Config struct {
+	// Set "Enable" true to profile the payloads of the NOSUPPORT requests.
+	Enable bool `mapstructure:"enable"`
+	// PrefixLength is the number of leading bytes whose byte and bigram histograms are used to
+	// cluster the payloads.
+	PrefixLength int `mapstructure:"prefix_length"`
+	// SimilarityThreshold is the minimum cosine similarity between the histograms of a payload and
+	// a cluster for the payload to join the cluster.
+	SimilarityThreshold float64 `mapstructure:"similarity_threshold"`
+	// MaxPorts limits the number of ports profiled to bound the memory usage.
+	MaxPorts int `mapstructure:"max_ports"`
+	// MaxClustersPerPort limits the number of clusters kept for each port.
+	// The payloads not similar to any of them are only counted.
+	MaxClustersPerPort int `mapstructure:"max_clusters_per_port"`
+}
+
+func NewDefaultConfig() *Config {
+	return &Config{
+		Enable:              false,
+		PrefixLength:        32,
+		SimilarityThreshold: 0.8,
+		MaxPorts:            100,
+		MaxClustersPerPort:  20,
+	}
+}
@@ -0,0 +1,254 @@
+package payloadprofile
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// The formats of the capture files, see https://www.ietf.org/archive/id/draft-tuexen-opsawg-pcapng-05.html
+// and https://wiki.wireshark.org/Development/LibpcapFileFormat.
+const (
+	pcapngSectionHeaderBlock  = 0x0a0d0d0a
+	pcapngInterfaceDescBlock  = 0x00000001
+	pcapngSimplePacketBlock   = 0x00000003
+	pcapngEnhancedPacketBlock = 0x00000006
+	pcapngByteOrderMagic      = 0x1a2b3c4d
+	pcapMagicMicroseconds     = 0xa1b2c3d4
+	pcapMagicNanoseconds      = 0xa1b23c4d
+	pcapHeaderLength          = 24
+	pcapRecordHeaderLength    = 16
+	// maxBlockLength bounds the memory allocated for a corrupted length.
+	maxBlockLength = 16 << 20
+)
+
+// The link types of the packets, see https://www.tcpdump.org/linktypes.html.
+const (
+	linkTypeEthernet = 1
+	linkTypeRaw      = 101
+	linkTypeLinuxSll = 113
+	linkTypeIpv4     = 228
+	linkTypeIpv6     = 229
+)
+
+const (
+	etherTypeIpv4      = 0x0800
+	etherTypeIpv6      = 0x86dd
+	etherTypeVlan      = 0x8100
+	ethernetHeaderLen  = 14
+	linuxSllHeaderLen  = 16
+	ipv6HeaderLength   = 40
+	ipProtocolTcp      = 6
+	ipProtocolUdp      = 17
+	udpHeaderLength    = 8
+	ipv4FragmentOffset = 0x1fff
+)
+
+var errUnknownFormat = errors.New("not a pcap or pcapng file")
+
+// ObservePcap records the payloads of a capture file, e.g. the file captured by tcpdump or exported from
+// "/pcap" of the controller, so the payloads can be profiled offline. The payloads are grouped by their
+// destination ports, and only the ports given are profiled if any. Each TCP segment is profiled as a
+// payload, so the requests should fit in a segment, which is true for most requests of the in-house
+// protocols.
+func (p *Profiler) ObservePcap(r io.Reader, ports []uint32) error {
+	filter := make(map[uint32]bool, len(ports))
+	for _, port := range ports {
+		filter[port] = true
+	}
+	return readPackets(bufio.NewReader(r), func(linkType uint16, data []byte) {
+		port, payload, ok := transportPayload(linkType, data)
+		if !ok || (len(filter) > 0 && !filter[port]) {
+			return
+		}
+		p.Observe(port, payload)
+	})
+}
+
+// readPackets calls the handler with the link type and the captured data of each packet.
+func readPackets(r *bufio.Reader, handler func(linkType uint16, data []byte)) error {
+	magic, err := r.Peek(4)
+	if err != nil {
+		return errUnknownFormat
+	}
+	if binary.LittleEndian.Uint32(magic) == pcapngSectionHeaderBlock {
+		return readPcapng(r, handler)
+	}
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		if value := order.Uint32(magic); value == pcapMagicMicroseconds || value == pcapMagicNanoseconds {
+			return readPcap(r, order, handler)
+		}
+	}
+	return errUnknownFormat
+}
+
+func readPcap(r *bufio.Reader, order binary.ByteOrder, handler func(linkType uint16, data []byte)) error {
+	header := make([]byte, pcapHeaderLength)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return fmt.Errorf("read the pcap header: %w", err)
+	}
+	linkType := uint16(order.Uint32(header[20:]))
+	record := make([]byte, pcapRecordHeaderLength)
+	for {
+		if _, err := io.ReadFull(r, record); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return fmt.Errorf("read the pcap record: %w", err)
+		}
+		capturedLength := order.Uint32(record[8:])
+		if capturedLength > maxBlockLength {
+			return fmt.Errorf("invalid captured length %d", capturedLength)
+		}
+		data := make([]byte, capturedLength)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return fmt.Errorf("read the pcap record: %w", err)
+		}
+		handler(linkType, data)
+	}
+}
+
+// readPcapng reads the packets of all sections. The byte order of each section is given by its header.
+func readPcapng(r *bufio.Reader, handler func(linkType uint16, data []byte)) error {
+	var order binary.ByteOrder = binary.LittleEndian
+	var linkTypes []uint16
+	header := make([]byte, 8)
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return fmt.Errorf("read the pcapng block: %w", err)
+		}
+		if binary.LittleEndian.Uint32(header) == pcapngSectionHeaderBlock {
+			magic, err := r.Peek(4)
+			if err != nil {
+				return fmt.Errorf("read the pcapng section: %w", err)
+			}
+			order = binary.LittleEndian
+			if binary.BigEndian.Uint32(magic) == pcapngByteOrderMagic {
+				order = binary.BigEndian
+			}
+			linkTypes = linkTypes[:0]
+		}
+		blockLength := order.Uint32(header[4:])
+		if blockLength < 12 || blockLength > maxBlockLength || blockLength%4 != 0 {
+			return fmt.Errorf("invalid pcapng block length %d", blockLength)
+		}
+		// The body is followed by the length again.
+		body := make([]byte, blockLength-8)
+		if _, err := io.ReadFull(r, body); err != nil {
+			return fmt.Errorf("read the pcapng block: %w", err)
+		}
+		body = body[:len(body)-4]
+		switch order.Uint32(header) {
+		case pcapngInterfaceDescBlock:
+			if len(body) < 2 {
+				return errors.New("invalid pcapng interface description block")
+			}
+			linkTypes = append(linkTypes, order.Uint16(body))
+		case pcapngEnhancedPacketBlock:
+			if len(body) < 20 {
+				return errors.New("invalid pcapng enhanced packet block")
+			}
+			interfaceId, capturedLength := order.Uint32(body), order.Uint32(body[12:])
+			if int(interfaceId) >= len(linkTypes) || int(capturedLength) > len(body)-20 {
+				return errors.New("invalid pcapng enhanced packet block")
+			}
+			handler(linkTypes[interfaceId], body[20:20+capturedLength])
+		case pcapngSimplePacketBlock:
+			// The packets are captured on the first interface, and the captured length is the smaller of the
+			// original length and the body.
+			if len(body) < 4 || len(linkTypes) == 0 {
+				return errors.New("invalid pcapng simple packet block")
+			}
+			data := body[4:]
+			if originalLength := order.Uint32(body); int(originalLength) < len(data) {
+				data = data[:originalLength]
+			}
+			handler(linkTypes[0], data)
+		}
+	}
+}
+
+// transportPayload returns the destination port and the payload of a TCP or UDP packet.
+func transportPayload(linkType uint16, data []byte) (uint32, []byte, bool) {
+	var etherType uint16
+	switch linkType {
+	case linkTypeEthernet:
+		if len(data) < ethernetHeaderLen {
+			return 0, nil, false
+		}
+		etherType, data = binary.BigEndian.Uint16(data[12:]), data[ethernetHeaderLen:]
+		if etherType == etherTypeVlan && len(data) >= 4 {
+			etherType, data = binary.BigEndian.Uint16(data[2:]), data[4:]
+		}
+	case linkTypeLinuxSll:
+		if len(data) < linuxSllHeaderLen {
+			return 0, nil, false
+		}
+		etherType, data = binary.BigEndian.Uint16(data[14:]), data[linuxSllHeaderLen:]
+	case linkTypeRaw, linkTypeIpv4, linkTypeIpv6:
+		if len(data) == 0 {
+			return 0, nil, false
+		}
+		etherType = etherTypeIpv4
+		if data[0]>>4 == 6 {
+			etherType = etherTypeIpv6
+		}
+	default:
+		return 0, nil, false
+	}
+
+	var protocol byte
+	switch etherType {
+	case etherTypeIpv4:
+		if len(data) < 20 || data[0]>>4 != 4 {
+			return 0, nil, false
+		}
+		headerLength, totalLength := int(data[0]&0x0f)*4, int(binary.BigEndian.Uint16(data[2:]))
+		// The fragments except the first one have no transport header.
+		if binary.BigEndian.Uint16(data[6:])&ipv4FragmentOffset != 0 || headerLength < 20 || totalLength < headerLength {
+			return 0, nil, false
+		}
+		if totalLength < len(data) {
+			data = data[:totalLength]
+		}
+		if len(data) < headerLength {
+			return 0, nil, false
+		}
+		protocol, data = data[9], data[headerLength:]
+	case etherTypeIpv6:
+		// The extension headers are not supported.
+		if len(data) < ipv6HeaderLength {
+			return 0, nil, false
+		}
+		payloadLength := int(binary.BigEndian.Uint16(data[4:]))
+		protocol, data = data[6], data[ipv6HeaderLength:]
+		if payloadLength < len(data) {
+			data = data[:payloadLength]
+		}
+	default:
+		return 0, nil, false
+	}
+
+	switch protocol {
+	case ipProtocolTcp:
+		if len(data) < 20 {
+			return 0, nil, false
+		}
+		headerLength := int(data[12]>>4) * 4
+		if headerLength < 20 || len(data) < headerLength {
+			return 0, nil, false
+		}
+		return uint32(binary.BigEndian.Uint16(data[2:])), data[headerLength:], true
+	case ipProtocolUdp:
+		if len(data) < udpHeaderLength {
+			return 0, nil, false
+		}
+		return uint32(binary.BigEndian.Uint16(data[2:])), data[udpHeaderLength:], true
+	}
+	return 0, nil, false
+}
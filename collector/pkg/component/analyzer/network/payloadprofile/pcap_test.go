@@ -0,0 +1,87 @@
+package payloadprofile
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/pcapexport"
+)
+
+func analyzePcap(file []byte, ports []uint32) ([]*PortReport, error) {
+	profiler := NewProfiler(nil)
+	if err := profiler.ObservePcap(bytes.NewReader(file), ports); err != nil {
+		return nil, err
+	}
+	return profiler.Report(), nil
+}
+
+func TestObservePcapng(t *testing.T) {
+	var file bytes.Buffer
+	// Each capture exported by the controller is a section, so the files can be concatenated.
+	for i, client := range []string{"10.0.0.1", "10.0.0.2"} {
+		var packets []*pcapexport.Packet
+		for j := 0; j < 5; j++ {
+			request := newFrame("get key")
+			packets = append(packets,
+				&pcapexport.Packet{Timestamp: uint64(j), FromClient: true, Data: request, Length: len(request)},
+				&pcapexport.Packet{Timestamp: uint64(j), FromClient: false, Data: []byte("OK"), Length: 2})
+		}
+		tuple := pcapexport.Tuple{Protocol: pcapexport.TCP, SrcIp: client, SrcPort: uint32(40000 + i), DstIp: "10.0.0.3", DstPort: 7000}
+		assert.NoError(t, pcapexport.WritePcapng(&file, tuple, packets))
+	}
+
+	reports, err := analyzePcap(file.Bytes(), []uint32{7000})
+	assert.NoError(t, err)
+	if assert.Len(t, reports, 1) {
+		assert.Equal(t, uint32(7000), reports[0].Port)
+		assert.Equal(t, 10, reports[0].Samples)
+		assert.Contains(t, reports[0].LengthFields, &LengthField{Offset: 2, Size: 4, Adjustment: 6, HitRatio: 1})
+		assert.Equal(t, 10, reports[0].Clusters[0].Count)
+	}
+
+	// The responses are profiled by the ports of the clients without the filter.
+	reports, err = analyzePcap(file.Bytes(), nil)
+	assert.NoError(t, err)
+	assert.Len(t, reports, 3)
+}
+
+func TestObservePcap(t *testing.T) {
+	// An Ethernet frame of UDP from 10.0.0.1:5000 to 10.0.0.2:9000 in a big-endian pcap file.
+	payload := []byte("HELLO")
+	frame := make([]byte, 0, 64)
+	frame = append(frame, make([]byte, 12)...)
+	frame = binary.BigEndian.AppendUint16(frame, etherTypeIpv4)
+	frame = append(frame, 0x45, 0)
+	frame = binary.BigEndian.AppendUint16(frame, uint16(20+udpHeaderLength+len(payload)))
+	frame = append(frame, 0, 0, 0x40, 0, 64, ipProtocolUdp, 0, 0, 10, 0, 0, 1, 10, 0, 0, 2)
+	frame = binary.BigEndian.AppendUint16(frame, 5000)
+	frame = binary.BigEndian.AppendUint16(frame, 9000)
+	frame = binary.BigEndian.AppendUint16(frame, uint16(udpHeaderLength+len(payload)))
+	frame = append(frame, 0, 0)
+	frame = append(frame, payload...)
+	// The Ethernet padding is ignored by the total length of IPv4.
+	frame = append(frame, 0, 0, 0)
+
+	file := binary.BigEndian.AppendUint32(nil, pcapMagicMicroseconds)
+	file = append(file, 0, 2, 0, 4, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0xff, 0xff)
+	file = binary.BigEndian.AppendUint32(file, linkTypeEthernet)
+	for i := 0; i < 3; i++ {
+		file = append(file, make([]byte, 8)...)
+		file = binary.BigEndian.AppendUint32(file, uint32(len(frame)))
+		file = binary.BigEndian.AppendUint32(file, uint32(len(frame)))
+		file = append(file, frame...)
+	}
+
+	reports, err := analyzePcap(file, nil)
+	assert.NoError(t, err)
+	if assert.Len(t, reports, 1) {
+		assert.Equal(t, uint32(9000), reports[0].Port)
+		assert.Equal(t, []*Cluster{{Sample: "HELLO", CommonPrefix: "HELLO", Count: 3}}, reports[0].Clusters)
+	}
+
+	_, err = analyzePcap([]byte("GET / HTTP/1.1\r\n"), nil)
+	assert.Equal(t, errUnknownFormat, err)
+}
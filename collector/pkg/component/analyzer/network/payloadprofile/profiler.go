@@ -0,0 +1,271 @@
+package payloadprofile
+
+import (
+	"encoding/hex"
+	"sort"
+	"sync"
+)
+
+const (
+	// maxPositions is the number of leading bytes checked for the constant values.
+	maxPositions = 16
+	// maxLengthFieldOffset is the maximum offset where a length field is searched.
+	maxLengthFieldOffset = 8
+	// maxLengthAdjustment is the maximum difference between the payload length and the length field.
+	maxLengthAdjustment = 64
+	// minSamples is the minimum number of samples before a pattern is reported.
+	minSamples = 10
+	// minHitRatio is the minimum ratio of the samples that must match a pattern.
+	minHitRatio = 0.8
+	topClusters = 10
+)
+
+// Profiler clusters the payloads of the requests whose protocol is not supported by the similarity of
+// their byte and bigram histograms, so the framing of in-house protocols can be inferred. It is safe
+// for concurrent use.
+type Profiler struct {
+	cfg   *Config
+	mutex sync.Mutex
+	ports map[uint32]*portProfile
+}
+
+func NewProfiler(cfg *Config) *Profiler {
+	if cfg == nil {
+		cfg = NewDefaultConfig()
+	}
+	return &Profiler{
+		cfg:   cfg,
+		ports: make(map[uint32]*portProfile),
+	}
+}
+
+// Observe records the payload of a request sent to the port.
+func (p *Profiler) Observe(port uint32, payload []byte) {
+	if len(payload) == 0 {
+		return
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	profile, ok := p.ports[port]
+	if !ok {
+		if len(p.ports) >= p.cfg.MaxPorts {
+			return
+		}
+		profile = newPortProfile(port)
+		p.ports[port] = profile
+	}
+	profile.observe(payload, p.cfg)
+}
+
+// Report returns the reports of all ports, sorted by the number of samples.
+func (p *Profiler) Report() []*PortReport {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	ret := make([]*PortReport, 0, len(p.ports))
+	for _, profile := range p.ports {
+		ret = append(ret, profile.report())
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Samples == ret[j].Samples {
+			return ret[i].Port < ret[j].Port
+		}
+		return ret[i].Samples > ret[j].Samples
+	})
+	return ret
+}
+
+// Reset clears all the samples.
+func (p *Profiler) Reset() {
+	p.mutex.Lock()
+	p.ports = make(map[uint32]*portProfile)
+	p.mutex.Unlock()
+}
+
+type positionStat struct {
+	value    byte
+	constant bool
+	samples  int
+}
+
+type lengthFieldKey struct {
+	offset int
+	size   int
+}
+
+type portProfile struct {
+	port           uint32
+	samples        int
+	textSamples    int
+	crlfSamples    int
+	clusters       []*cluster
+	unclustered    int
+	positions      [maxPositions]positionStat
+	lengthFieldHit map[lengthFieldKey]map[int]int
+}
+
+func newPortProfile(port uint32) *portProfile {
+	return &portProfile{
+		port:           port,
+		lengthFieldHit: make(map[lengthFieldKey]map[int]int),
+	}
+}
+
+func (p *portProfile) observe(payload []byte, cfg *Config) {
+	p.samples++
+	if isText(payload) {
+		p.textSamples++
+	}
+	if hasCRLF(payload) {
+		p.crlfSamples++
+	}
+
+	prefixLength := cfg.PrefixLength
+	if prefixLength > len(payload) {
+		prefixLength = len(payload)
+	}
+	p.cluster(payload[:prefixLength], cfg)
+
+	for i := 0; i < maxPositions && i < len(payload); i++ {
+		stat := &p.positions[i]
+		if stat.samples == 0 {
+			stat.value = payload[i]
+			stat.constant = true
+		} else if stat.value != payload[i] {
+			stat.constant = false
+		}
+		stat.samples++
+	}
+
+	// A length field is an integer in network byte order whose value is the
+	// payload length minus a fixed adjustment, e.g. the length of the header.
+	for offset := 0; offset <= maxLengthFieldOffset; offset++ {
+		for _, size := range []int{2, 4} {
+			if offset+size > len(payload) {
+				continue
+			}
+			var value int
+			for _, b := range payload[offset : offset+size] {
+				value = value<<8 | int(b)
+			}
+			adjustment := len(payload) - value
+			if adjustment < 0 || adjustment > maxLengthAdjustment {
+				continue
+			}
+			key := lengthFieldKey{offset: offset, size: size}
+			hits, ok := p.lengthFieldHit[key]
+			if !ok {
+				hits = make(map[int]int)
+				p.lengthFieldHit[key] = hits
+			}
+			hits[adjustment]++
+		}
+	}
+}
+
+// cluster adds the prefix to the most similar cluster, or starts a new cluster if none is similar enough.
+func (p *portProfile) cluster(prefix []byte, cfg *Config) {
+	h := newHistogram(prefix)
+	var best *cluster
+	var bestSimilarity float64
+	for _, c := range p.clusters {
+		if similarity := c.similarity(h); similarity > bestSimilarity {
+			best, bestSimilarity = c, similarity
+		}
+	}
+	if best != nil && bestSimilarity >= cfg.SimilarityThreshold {
+		best.add(prefix, h)
+		return
+	}
+	if len(p.clusters) < cfg.MaxClustersPerPort {
+		p.clusters = append(p.clusters, newCluster(prefix, h))
+		return
+	}
+	p.unclustered++
+}
+
+func (p *portProfile) report() *PortReport {
+	report := &PortReport{
+		Port:          p.port,
+		Samples:       p.samples,
+		Encoding:      BinaryEncoding,
+		CRLFDelimited: p.samples > 0 && float64(p.crlfSamples)/float64(p.samples) >= minHitRatio,
+		Unclustered:   p.unclustered,
+	}
+	if p.samples > 0 && float64(p.textSamples)/float64(p.samples) >= minHitRatio {
+		report.Encoding = TextEncoding
+	}
+
+	for _, c := range p.clusters {
+		report.Clusters = append(report.Clusters, &Cluster{
+			Sample:       formatBytes(c.sample),
+			CommonPrefix: formatBytes(c.commonPrefix),
+			Count:        c.count,
+		})
+	}
+	sort.SliceStable(report.Clusters, func(i, j int) bool {
+		return report.Clusters[i].Count > report.Clusters[j].Count
+	})
+	if len(report.Clusters) > topClusters {
+		report.Clusters = report.Clusters[:topClusters]
+	}
+
+	if p.samples < minSamples {
+		return report
+	}
+	for i, stat := range p.positions {
+		if stat.constant && float64(stat.samples)/float64(p.samples) >= minHitRatio {
+			report.MagicBytes = append(report.MagicBytes, &MagicByte{Offset: i, Value: hex.EncodeToString([]byte{stat.value})})
+		}
+	}
+	for key, hits := range p.lengthFieldHit {
+		for adjustment, count := range hits {
+			ratio := float64(count) / float64(p.samples)
+			if ratio < minHitRatio {
+				continue
+			}
+			report.LengthFields = append(report.LengthFields, &LengthField{
+				Offset:     key.offset,
+				Size:       key.size,
+				Adjustment: adjustment,
+				HitRatio:   ratio,
+			})
+		}
+	}
+	sort.Slice(report.LengthFields, func(i, j int) bool {
+		a, b := report.LengthFields[i], report.LengthFields[j]
+		if a.HitRatio != b.HitRatio {
+			return a.HitRatio > b.HitRatio
+		}
+		if a.Offset != b.Offset {
+			return a.Offset < b.Offset
+		}
+		return a.Size > b.Size
+	})
+	return report
+}
+
+func isText(payload []byte) bool {
+	for _, b := range payload {
+		if (b < 0x20 || b > 0x7e) && b != '\r' && b != '\n' && b != '\t' {
+			return false
+		}
+	}
+	return true
+}
+
+func hasCRLF(payload []byte) bool {
+	for i := 0; i+1 < len(payload); i++ {
+		if payload[i] == '\r' && payload[i+1] == '\n' {
+			return true
+		}
+	}
+	return false
+}
+
+// formatBytes returns the text if all bytes are printable, otherwise the hex string prefixed with "0x".
+func formatBytes(data []byte) string {
+	if isText(data) {
+		return string(data)
+	}
+	return "0x" + hex.EncodeToString(data)
+}
@@ -0,0 +1,104 @@
+package payloadprofile
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func analyze(port uint32, payloads [][]byte, cfg *Config) *PortReport {
+	profiler := NewProfiler(cfg)
+	for _, payload := range payloads {
+		profiler.Observe(port, payload)
+	}
+	return profiler.Report()[0]
+}
+
+// newFrame builds a payload with the magic bytes 0xcafe, a 4-byte length of the body and the body.
+func newFrame(body string) []byte {
+	frame := []byte{0xca, 0xfe, 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(frame[2:6], uint32(len(body)))
+	return append(frame, body...)
+}
+
+func TestAnalyzeBinaryProtocol(t *testing.T) {
+	payloads := make([][]byte, 0)
+	for _, body := range []string{"get", "set key", "delete key", "ping", "get key", "set a b", "x", "list all", "get b", "set b c"} {
+		payloads = append(payloads, newFrame(body))
+	}
+	report := analyze(7000, payloads, nil)
+
+	assert.Equal(t, 10, report.Samples)
+	assert.Equal(t, BinaryEncoding, report.Encoding)
+	assert.Contains(t, report.MagicBytes, &MagicByte{Offset: 0, Value: "ca"})
+	assert.Contains(t, report.MagicBytes, &MagicByte{Offset: 1, Value: "fe"})
+	assert.NotEmpty(t, report.LengthFields)
+	assert.Equal(t, &LengthField{Offset: 2, Size: 4, Adjustment: 6, HitRatio: 1}, report.LengthFields[0])
+	// The frames of the commands with the same length are the most similar.
+	assert.Equal(t, &Cluster{Sample: "0xcafe00000007736574206b6579", CommonPrefix: "0xcafe00000007", Count: 4}, report.Clusters[0])
+}
+
+func TestAnalyzeTextProtocol(t *testing.T) {
+	payloads := make([][]byte, 0)
+	for i := 0; i < 10; i++ {
+		payloads = append(payloads, []byte("CMD get key\r\n"))
+	}
+	report := analyze(7001, payloads, nil)
+	assert.Equal(t, TextEncoding, report.Encoding)
+	assert.True(t, report.CRLFDelimited)
+	assert.Equal(t, []*Cluster{{Sample: "CMD get key\r\n", CommonPrefix: "CMD get key\r\n", Count: 10}}, report.Clusters)
+}
+
+func TestClusterBySimilarity(t *testing.T) {
+	var payloads [][]byte
+	for i := 0; i < 10; i++ {
+		// The same fields in different orders share no prefix but the bytes and the bigrams.
+		if i%2 == 0 {
+			payloads = append(payloads, []byte(fmt.Sprintf(`{"method":"get","id":%d}`, i)))
+		} else {
+			payloads = append(payloads, []byte(fmt.Sprintf(`{"id":%d,"method":"get"}`, i)))
+		}
+		payloads = append(payloads, newFrame(fmt.Sprintf("PING %d", i)))
+	}
+	report := analyze(7002, payloads, nil)
+	assert.Equal(t, 20, report.Samples)
+	if assert.Len(t, report.Clusters, 2) {
+		assert.Equal(t, &Cluster{Sample: `{"method":"get","id":0}`, CommonPrefix: `{"`, Count: 10}, report.Clusters[0])
+		assert.Equal(t, &Cluster{Sample: "0xcafe0000000650494e472030", CommonPrefix: "0xcafe0000000650494e4720", Count: 10}, report.Clusters[1])
+	}
+	assert.Zero(t, report.Unclustered)
+}
+
+func TestProfilerLimits(t *testing.T) {
+	profiler := NewProfiler(&Config{PrefixLength: 1, SimilarityThreshold: 0.8, MaxPorts: 1, MaxClustersPerPort: 1})
+	profiler.Observe(80, []byte("a"))
+	profiler.Observe(80, []byte("b"))
+	profiler.Observe(81, []byte("a"))
+
+	reports := profiler.Report()
+	assert.Equal(t, 1, len(reports))
+	assert.Equal(t, 1, reports[0].Unclustered)
+}
+
+func TestServeHTTP(t *testing.T) {
+	profiler := NewProfiler(nil)
+	profiler.Observe(80, []byte("a"))
+	profiler.Observe(81, []byte("b"))
+
+	recorder := httptest.NewRecorder()
+	profiler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/payloadprofile?port=81", nil))
+	var reports []*PortReport
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &reports))
+	assert.Equal(t, 1, len(reports))
+	assert.Equal(t, uint32(81), reports[0].Port)
+
+	recorder = httptest.NewRecorder()
+	profiler.ServeHTTP(recorder, httptest.NewRequest(http.MethodDelete, "/payloadprofile", nil))
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+	assert.Empty(t, profiler.Report())
+}
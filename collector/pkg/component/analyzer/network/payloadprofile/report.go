@@ -0,0 +1,86 @@
+package payloadprofile
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+const (
+	TextEncoding   = "text"
+	BinaryEncoding = "binary"
+)
+
+// PortReport is the inferred framing pattern of the payloads sent to a port.
+type PortReport struct {
+	Port    uint32 `json:"port"`
+	Samples int    `json:"samples"`
+	// Encoding is "text" if most payloads only contain printable characters, otherwise "binary".
+	Encoding      string `json:"encoding"`
+	CRLFDelimited bool   `json:"crlf_delimited"`
+	// MagicBytes are the bytes that have the same value in all payloads.
+	MagicBytes []*MagicByte `json:"magic_bytes,omitempty"`
+	// LengthFields are the candidates of the field that contains the payload length.
+	LengthFields []*LengthField `json:"length_fields,omitempty"`
+	// Clusters are the largest clusters of the payloads with similar byte and bigram histograms.
+	Clusters []*Cluster `json:"clusters"`
+	// Unclustered is the number of the payloads not similar to any cluster after the clusters are full.
+	Unclustered int `json:"unclustered"`
+}
+
+type MagicByte struct {
+	Offset int    `json:"offset"`
+	Value  string `json:"value"`
+}
+
+// LengthField means the integer in network byte order at [Offset, Offset+Size)
+// equals the payload length minus Adjustment.
+type LengthField struct {
+	Offset     int     `json:"offset"`
+	Size       int     `json:"size"`
+	Adjustment int     `json:"adjustment"`
+	HitRatio   float64 `json:"hit_ratio"`
+}
+
+// Cluster is a group of the payloads whose prefixes have similar byte and bigram histograms.
+type Cluster struct {
+	// Sample is the prefix of the first payload of the cluster.
+	Sample string `json:"sample"`
+	// CommonPrefix is the longest prefix shared by all payloads of the cluster, which is empty if the
+	// payloads only share the byte distribution, e.g. the same fields in different orders.
+	CommonPrefix string `json:"common_prefix"`
+	Count        int    `json:"count"`
+}
+
+// ServeHTTP returns the reports in JSON. Use the query "port" to get the report of a
+// single port, and the method DELETE to clear all the samples.
+func (p *Profiler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodDelete {
+		p.Reset()
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	reports := p.Report()
+	if portStr := r.URL.Query().Get("port"); portStr != "" {
+		port, err := strconv.ParseUint(portStr, 10, 32)
+		if err != nil {
+			http.Error(w, "invalid port", http.StatusBadRequest)
+			return
+		}
+		filtered := make([]*PortReport, 0, 1)
+		for _, report := range reports {
+			if report.Port == uint32(port) {
+				filtered = append(filtered, report)
+			}
+		}
+		reports = filtered
+	}
+	msg, err := json.Marshal(reports)
+	if err != nil {
+		http.Error(w, "write response failed", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(msg)
+}
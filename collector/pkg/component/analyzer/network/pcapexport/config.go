@@ -0,0 +1,19 @@
+package pcapexport
+
+type Config struct {
+	// Set "Enable" true to allow capturing the payloads of the chosen connections via the http API.
+	Enable bool `mapstructure:"enable"`
+	// MaxCaptures limits the number of connections captured at the same time.
+	MaxCaptures int `mapstructure:"max_captures"`
+	// MaxPacketsPerCapture limits the number of packets kept for each connection.
+	// The other packets are only counted.
+	MaxPacketsPerCapture int `mapstructure:"max_packets_per_capture"`
+}
+
+func NewDefaultConfig() *Config {
+	return &Config{
+		Enable:               false,
+		MaxCaptures:          10,
+		MaxPacketsPerCapture: 10000,
+	}
+}
@@ -0,0 +1,183 @@
+package pcapexport
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"net"
+)
+
+// The blocks of pcapng, see https://www.ietf.org/archive/id/draft-tuexen-opsawg-pcapng-05.html.
+const (
+	sectionHeaderBlock    = 0x0a0d0d0a
+	interfaceDescBlock    = 0x00000001
+	enhancedPacketBlock   = 0x00000006
+	byteOrderMagic        = 0x1a2b3c4d
+	optionEndOfOpt        = 0
+	optionIfTsresol       = 9
+	linkTypeRaw           = 101
+	nanosecondResolution  = 9
+	ipv4HeaderLength      = 20
+	tcpHeaderLength       = 20
+	udpHeaderLength       = 8
+	maxIpv4PacketLength   = 65535
+	ipProtocolTcp         = 6
+	ipProtocolUdp         = 17
+	tcpFlagPushAck        = 0x18
+	defaultTtl            = 64
+	defaultTcpWindowSize  = 65535
+	ipv4DontFragmentFlags = 0x4000
+)
+
+// WritePcapng writes the packets of the connection in pcapng. The IPv4 and the TCP or UDP headers
+// are synthesized from the tuple, and the TCP sequence numbers of each direction start from 1.
+// The packets are recorded with their original lengths, so the payloads truncated by the snaplen
+// are shown as the packets truncated by the capture in Wireshark.
+func WritePcapng(w io.Writer, tuple Tuple, packets []*Packet) error {
+	writer := bufio.NewWriter(w)
+	writeBlock(writer, sectionHeaderBlock, func(body []byte) []byte {
+		body = binary.LittleEndian.AppendUint32(body, byteOrderMagic)
+		// Version 1.0 and the unspecified section length.
+		body = binary.LittleEndian.AppendUint16(body, 1)
+		body = binary.LittleEndian.AppendUint16(body, 0)
+		return binary.LittleEndian.AppendUint64(body, 0xffffffffffffffff)
+	})
+	writeBlock(writer, interfaceDescBlock, func(body []byte) []byte {
+		body = binary.LittleEndian.AppendUint16(body, linkTypeRaw)
+		body = binary.LittleEndian.AppendUint16(body, 0)
+		// No limit of the snaplen.
+		body = binary.LittleEndian.AppendUint32(body, 0)
+		// The timestamps are in nanoseconds.
+		body = appendOption(body, optionIfTsresol, []byte{nanosecondResolution})
+		return appendOption(body, optionEndOfOpt, nil)
+	})
+
+	clientIp, serverIp := net.ParseIP(tuple.SrcIp).To4(), net.ParseIP(tuple.DstIp).To4()
+	var clientSeq, serverSeq uint32 = 1, 1
+	var id uint16
+	for _, packet := range packets {
+		srcIp, dstIp, srcPort, dstPort := clientIp, serverIp, tuple.SrcPort, tuple.DstPort
+		seq, ack := &clientSeq, serverSeq
+		if !packet.FromClient {
+			srcIp, dstIp, srcPort, dstPort = serverIp, clientIp, tuple.DstPort, tuple.SrcPort
+			seq, ack = &serverSeq, clientSeq
+		}
+		transportLength := udpHeaderLength
+		if tuple.Protocol == TCP {
+			transportLength = tcpHeaderLength
+		}
+		// The payloads larger than an IPv4 packet are split into multiple packets.
+		maxSegmentLength := maxIpv4PacketLength - ipv4HeaderLength - transportLength
+		data, length := packet.Data, packet.Length
+		for {
+			segmentLength := length
+			if segmentLength > maxSegmentLength {
+				segmentLength = maxSegmentLength
+			}
+			captured := data
+			if len(captured) > segmentLength {
+				captured = captured[:segmentLength]
+			}
+			headers := make([]byte, 0, ipv4HeaderLength+transportLength+len(captured))
+			headers = appendIpv4Header(headers, srcIp, dstIp, tuple.Protocol, id, transportLength+segmentLength)
+			if tuple.Protocol == TCP {
+				headers = appendTcpHeader(headers, uint16(srcPort), uint16(dstPort), *seq, ack)
+			} else {
+				headers = appendUdpHeader(headers, uint16(srcPort), uint16(dstPort), segmentLength)
+			}
+			writeEnhancedPacket(writer, packet.Timestamp, append(headers, captured...), ipv4HeaderLength+transportLength+segmentLength)
+			id++
+			*seq += uint32(segmentLength)
+			data, length = data[len(captured):], length-segmentLength
+			if length <= 0 {
+				break
+			}
+		}
+	}
+	return writer.Flush()
+}
+
+func writeBlock(writer *bufio.Writer, blockType uint32, appendBody func([]byte) []byte) {
+	body := appendBody(make([]byte, 0, 64))
+	totalLength := uint32(12 + len(body))
+	block := make([]byte, 0, totalLength)
+	block = binary.LittleEndian.AppendUint32(block, blockType)
+	block = binary.LittleEndian.AppendUint32(block, totalLength)
+	block = append(block, body...)
+	block = binary.LittleEndian.AppendUint32(block, totalLength)
+	_, _ = writer.Write(block)
+}
+
+func writeEnhancedPacket(writer *bufio.Writer, timestamp uint64, data []byte, originalLength int) {
+	writeBlock(writer, enhancedPacketBlock, func(body []byte) []byte {
+		// The only interface.
+		body = binary.LittleEndian.AppendUint32(body, 0)
+		body = binary.LittleEndian.AppendUint32(body, uint32(timestamp>>32))
+		body = binary.LittleEndian.AppendUint32(body, uint32(timestamp))
+		body = binary.LittleEndian.AppendUint32(body, uint32(len(data)))
+		body = binary.LittleEndian.AppendUint32(body, uint32(originalLength))
+		body = append(body, data...)
+		return append(body, make([]byte, padding(len(data)))...)
+	})
+}
+
+func appendOption(body []byte, code uint16, value []byte) []byte {
+	body = binary.LittleEndian.AppendUint16(body, code)
+	body = binary.LittleEndian.AppendUint16(body, uint16(len(value)))
+	body = append(body, value...)
+	return append(body, make([]byte, padding(len(value)))...)
+}
+
+// padding returns the number of bytes needed to align the length to 32 bits.
+func padding(length int) int {
+	return (4 - length%4) % 4
+}
+
+func appendIpv4Header(b []byte, srcIp, dstIp net.IP, protocol string, id uint16, payloadLength int) []byte {
+	start := len(b)
+	ipProtocol := byte(ipProtocolUdp)
+	if protocol == TCP {
+		ipProtocol = ipProtocolTcp
+	}
+	b = append(b, 0x45, 0)
+	b = binary.BigEndian.AppendUint16(b, uint16(ipv4HeaderLength+payloadLength))
+	b = binary.BigEndian.AppendUint16(b, id)
+	b = binary.BigEndian.AppendUint16(b, ipv4DontFragmentFlags)
+	b = append(b, defaultTtl, ipProtocol, 0, 0)
+	b = append(b, srcIp...)
+	b = append(b, dstIp...)
+	binary.BigEndian.PutUint16(b[start+10:], checksum(b[start:]))
+	return b
+}
+
+// appendTcpHeader appends the header with PSH and ACK. The checksum is left zero as Wireshark
+// doesn't validate it by default.
+func appendTcpHeader(b []byte, srcPort, dstPort uint16, seq, ack uint32) []byte {
+	b = binary.BigEndian.AppendUint16(b, srcPort)
+	b = binary.BigEndian.AppendUint16(b, dstPort)
+	b = binary.BigEndian.AppendUint32(b, seq)
+	b = binary.BigEndian.AppendUint32(b, ack)
+	b = append(b, tcpHeaderLength/4<<4, tcpFlagPushAck)
+	b = binary.BigEndian.AppendUint16(b, defaultTcpWindowSize)
+	// The checksum and the urgent pointer.
+	return append(b, 0, 0, 0, 0)
+}
+
+func appendUdpHeader(b []byte, srcPort, dstPort uint16, payloadLength int) []byte {
+	b = binary.BigEndian.AppendUint16(b, srcPort)
+	b = binary.BigEndian.AppendUint16(b, dstPort)
+	b = binary.BigEndian.AppendUint16(b, uint16(udpHeaderLength+payloadLength))
+	// The checksum is optional for IPv4.
+	return append(b, 0, 0)
+}
+
+func checksum(header []byte) uint16 {
+	var sum uint32
+	for i := 0; i+1 < len(header); i += 2 {
+		sum += uint32(header[i])<<8 | uint32(header[i+1])
+	}
+	for sum > 0xffff {
+		sum = sum>>16 + sum&0xffff
+	}
+	return ^uint16(sum)
+}
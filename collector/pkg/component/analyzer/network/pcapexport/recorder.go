@@ -0,0 +1,243 @@
+package pcapexport
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	TCP = "tcp"
+	UDP = "udp"
+)
+
+// Tuple is the 5-tuple of a connection. The source is the client and the destination is the server,
+// like the connections served at "/connections".
+type Tuple struct {
+	Protocol string `json:"protocol"`
+	SrcIp    string `json:"src_ip"`
+	SrcPort  uint32 `json:"src_port"`
+	DstIp    string `json:"dst_ip"`
+	DstPort  uint32 `json:"dst_port"`
+}
+
+// Packet is the payload of a syscall reading or writing the connection.
+type Packet struct {
+	// Timestamp is in nanoseconds.
+	Timestamp  uint64
+	FromClient bool
+	// Data is the captured payload, which is truncated by the snaplen.
+	Data []byte
+	// Length is the number of bytes read or written by the syscall.
+	Length int
+}
+
+// CaptureStatus describes a capture of a connection.
+type CaptureStatus struct {
+	Tuple
+	StartTime time.Time `json:"start_time"`
+	Packets   int       `json:"packets"`
+	Bytes     int       `json:"bytes"`
+	// Dropped is the number of packets dropped after the capture is full.
+	Dropped int `json:"dropped"`
+}
+
+type capture struct {
+	status  CaptureStatus
+	packets []*Packet
+}
+
+// Recorder keeps the payloads of the connections chosen via the http API, so they can be
+// exported in pcapng. It is safe for concurrent use.
+type Recorder struct {
+	cfg      *Config
+	mutex    sync.Mutex
+	captures map[Tuple]*capture
+	// active is the number of captures, checked before taking the lock for every event.
+	active int32
+}
+
+func NewRecorder(cfg *Config) *Recorder {
+	if cfg == nil {
+		cfg = NewDefaultConfig()
+	}
+	return &Recorder{
+		cfg:      cfg,
+		captures: make(map[Tuple]*capture),
+	}
+}
+
+// Start begins capturing the connection. It does nothing if the connection is being captured.
+func (r *Recorder) Start(tuple Tuple) (*CaptureStatus, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if c, ok := r.captures[tuple]; ok {
+		status := c.status
+		return &status, nil
+	}
+	if len(r.captures) >= r.cfg.MaxCaptures {
+		return nil, fmt.Errorf("the number of captures exceeds %d", r.cfg.MaxCaptures)
+	}
+	c := &capture{status: CaptureStatus{Tuple: tuple, StartTime: time.Now()}}
+	r.captures[tuple] = c
+	atomic.StoreInt32(&r.active, int32(len(r.captures)))
+	status := c.status
+	return &status, nil
+}
+
+// Stop ends capturing the connection and discards its packets.
+func (r *Recorder) Stop(tuple Tuple) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if _, ok := r.captures[tuple]; !ok {
+		return false
+	}
+	delete(r.captures, tuple)
+	atomic.StoreInt32(&r.active, int32(len(r.captures)))
+	return true
+}
+
+// Capturing returns whether any connection is being captured.
+func (r *Recorder) Capturing() bool {
+	return atomic.LoadInt32(&r.active) > 0
+}
+
+// Observe records the packet if the connection is being captured. The data is copied.
+func (r *Recorder) Observe(tuple Tuple, packet *Packet) {
+	if !r.Capturing() {
+		return
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	c, ok := r.captures[tuple]
+	if !ok {
+		return
+	}
+	if len(c.packets) >= r.cfg.MaxPacketsPerCapture {
+		c.status.Dropped++
+		return
+	}
+	copied := *packet
+	copied.Data = append([]byte(nil), packet.Data...)
+	c.packets = append(c.packets, &copied)
+	c.status.Packets++
+	c.status.Bytes += packet.Length
+}
+
+// Captures returns the status of all captures, sorted by the start time.
+func (r *Recorder) Captures() []*CaptureStatus {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	ret := make([]*CaptureStatus, 0, len(r.captures))
+	for _, c := range r.captures {
+		status := c.status
+		ret = append(ret, &status)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].StartTime.Before(ret[j].StartTime)
+	})
+	return ret
+}
+
+// packets returns the packets captured so far, or false if the connection is not being captured.
+func (r *Recorder) packets(tuple Tuple) ([]*Packet, bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	c, ok := r.captures[tuple]
+	if !ok {
+		return nil, false
+	}
+	return append([]*Packet(nil), c.packets...), true
+}
+
+// ServeHTTP manages the captures of the connections specified by the query parameters "protocol"
+// ("tcp" by default), "src_ip", "src_port", "dst_ip" and "dst_port". POST starts capturing the
+// connection, GET downloads the packets captured so far in pcapng, and DELETE stops the capture.
+// GET without the parameters returns the status of all captures in JSON.
+func (r *Recorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+	if req.Method == http.MethodGet && query.Get("src_ip") == "" && query.Get("dst_ip") == "" {
+		writeJSON(w, http.StatusOK, r.Captures())
+		return
+	}
+	tuple, err := parseTuple(query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	switch req.Method {
+	case http.MethodPost:
+		status, err := r.Start(tuple)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		writeJSON(w, http.StatusOK, status)
+	case http.MethodDelete:
+		if !r.Stop(tuple) {
+			http.Error(w, "the connection is not being captured", http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodGet:
+		packets, ok := r.packets(tuple)
+		if !ok {
+			http.Error(w, "the connection is not being captured", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-pcapng")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q",
+			fmt.Sprintf("%s-%s-%d-%s-%d.pcapng", tuple.Protocol, tuple.SrcIp, tuple.SrcPort, tuple.DstIp, tuple.DstPort)))
+		w.WriteHeader(http.StatusOK)
+		_ = WritePcapng(w, tuple, packets)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func parseTuple(query map[string][]string) (Tuple, error) {
+	get := func(name string) string {
+		if values := query[name]; len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+	tuple := Tuple{Protocol: get("protocol"), SrcIp: get("src_ip"), DstIp: get("dst_ip")}
+	if tuple.Protocol == "" {
+		tuple.Protocol = TCP
+	}
+	if tuple.Protocol != TCP && tuple.Protocol != UDP {
+		return tuple, fmt.Errorf("invalid protocol: %s", tuple.Protocol)
+	}
+	for _, ip := range []string{tuple.SrcIp, tuple.DstIp} {
+		// Only IPv4 is supported by the probe now.
+		if parsed := net.ParseIP(ip); parsed == nil || parsed.To4() == nil {
+			return tuple, fmt.Errorf("invalid IPv4 address: %q", ip)
+		}
+	}
+	for name, port := range map[string]*uint32{"src_port": &tuple.SrcPort, "dst_port": &tuple.DstPort} {
+		n, err := strconv.ParseUint(get(name), 10, 16)
+		if err != nil {
+			return tuple, fmt.Errorf("invalid %s: %q", name, get(name))
+		}
+		*port = uint32(n)
+	}
+	return tuple, nil
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	msg, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, "write response failed", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_, _ = w.Write(msg)
+}
@@ -0,0 +1,130 @@
+package pcapexport
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type pcapngBlock struct {
+	blockType uint32
+	body      []byte
+}
+
+func readBlocks(t *testing.T, data []byte) []*pcapngBlock {
+	blocks := make([]*pcapngBlock, 0)
+	for len(data) > 0 {
+		assert.GreaterOrEqual(t, len(data), 12)
+		length := binary.LittleEndian.Uint32(data[4:8])
+		assert.Equal(t, uint32(0), length%4)
+		assert.Equal(t, length, binary.LittleEndian.Uint32(data[length-4:length]))
+		blocks = append(blocks, &pcapngBlock{
+			blockType: binary.LittleEndian.Uint32(data[0:4]),
+			body:      data[8 : length-4],
+		})
+		data = data[length:]
+	}
+	return blocks
+}
+
+// readPacket returns the captured bytes and the original length of an enhanced packet block.
+func readPacket(block *pcapngBlock) ([]byte, uint32) {
+	capturedLength := binary.LittleEndian.Uint32(block.body[12:16])
+	return block.body[20 : 20+capturedLength], binary.LittleEndian.Uint32(block.body[16:20])
+}
+
+func TestWritePcapng(t *testing.T) {
+	tuple := Tuple{Protocol: TCP, SrcIp: "10.0.0.1", SrcPort: 52380, DstIp: "10.0.0.2", DstPort: 6379}
+	var buffer bytes.Buffer
+	err := WritePcapng(&buffer, tuple, []*Packet{
+		{Timestamp: 1000000001, FromClient: true, Data: []byte("*1\r\n$4\r\nPING\r\n"), Length: 14},
+		// The response is truncated by the snaplen.
+		{Timestamp: 1000000002, FromClient: false, Data: []byte("+PO"), Length: 7},
+		{Timestamp: 1000000003, FromClient: true, Data: []byte("a"), Length: 70000},
+	})
+	assert.NoError(t, err)
+
+	blocks := readBlocks(t, buffer.Bytes())
+	assert.Len(t, blocks, 6)
+	assert.Equal(t, uint32(sectionHeaderBlock), blocks[0].blockType)
+	assert.Equal(t, uint32(byteOrderMagic), binary.LittleEndian.Uint32(blocks[0].body))
+	assert.Equal(t, uint32(interfaceDescBlock), blocks[1].blockType)
+	assert.Equal(t, uint16(linkTypeRaw), binary.LittleEndian.Uint16(blocks[1].body))
+
+	request, length := readPacket(blocks[2])
+	assert.Equal(t, uint32(40+14), length)
+	assert.Equal(t, []byte{10, 0, 0, 1, 10, 0, 0, 2}, request[12:20])
+	assert.Equal(t, uint16(0), checksum(request[:20]))
+	assert.Equal(t, uint16(52380), binary.BigEndian.Uint16(request[20:22]))
+	assert.Equal(t, uint16(6379), binary.BigEndian.Uint16(request[22:24]))
+	assert.Equal(t, uint32(1), binary.BigEndian.Uint32(request[24:28]))
+	assert.Equal(t, "*1\r\n$4\r\nPING\r\n", string(request[40:]))
+	assert.Equal(t, uint32(1000000001), binary.LittleEndian.Uint32(blocks[2].body[8:12]))
+
+	response, length := readPacket(blocks[3])
+	assert.Equal(t, uint32(40+7), length)
+	assert.Equal(t, uint16(40+7), binary.BigEndian.Uint16(response[2:4]))
+	assert.Equal(t, uint16(6379), binary.BigEndian.Uint16(response[20:22]))
+	assert.Equal(t, uint32(1), binary.BigEndian.Uint32(response[24:28]))
+	// The acknowledgment number is the next sequence number of the client.
+	assert.Equal(t, uint32(15), binary.BigEndian.Uint32(response[28:32]))
+	assert.Equal(t, "+PO", string(response[40:]))
+
+	// The payload larger than an IPv4 packet is split.
+	first, length := readPacket(blocks[4])
+	assert.Equal(t, uint32(maxIpv4PacketLength), length)
+	assert.Equal(t, uint32(15), binary.BigEndian.Uint32(first[24:28]))
+	assert.Equal(t, "a", string(first[40:]))
+	second, length := readPacket(blocks[5])
+	assert.Equal(t, uint32(40+70000-(maxIpv4PacketLength-40)), length)
+	assert.Equal(t, uint32(15+maxIpv4PacketLength-40), binary.BigEndian.Uint32(second[24:28]))
+	assert.Len(t, second, 40)
+}
+
+func TestRecorderServeHTTP(t *testing.T) {
+	recorder := NewRecorder(&Config{Enable: true, MaxCaptures: 1, MaxPacketsPerCapture: 1})
+	tuple := Tuple{Protocol: UDP, SrcIp: "10.0.0.1", SrcPort: 40000, DstIp: "10.0.0.2", DstPort: 53}
+	query := "?protocol=udp&src_ip=10.0.0.1&src_port=40000&dst_ip=10.0.0.2&dst_port=53"
+	serve := func(method string, target string) *httptest.ResponseRecorder {
+		response := httptest.NewRecorder()
+		recorder.ServeHTTP(response, httptest.NewRequest(method, target, nil))
+		return response
+	}
+
+	// The packets are not kept before the capture starts.
+	recorder.Observe(tuple, &Packet{FromClient: true, Data: []byte("query"), Length: 5})
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/pcap"+query).Code)
+	assert.Equal(t, http.StatusBadRequest, serve(http.MethodPost, "/pcap?src_ip=10.0.0.1&dst_ip=10.0.0.2").Code)
+
+	assert.Equal(t, http.StatusOK, serve(http.MethodPost, "/pcap"+query).Code)
+	assert.Equal(t, http.StatusConflict, serve(http.MethodPost, "/pcap?src_ip=10.0.0.1&src_port=1&dst_ip=10.0.0.2&dst_port=53").Code)
+	recorder.Observe(tuple, &Packet{FromClient: true, Data: []byte("query"), Length: 5})
+	recorder.Observe(tuple, &Packet{FromClient: false, Data: []byte("answer"), Length: 6})
+
+	response := serve(http.MethodGet, "/pcap")
+	var captures []*CaptureStatus
+	assert.NoError(t, json.Unmarshal(response.Body.Bytes(), &captures))
+	assert.Len(t, captures, 1)
+	assert.Equal(t, tuple, captures[0].Tuple)
+	assert.Equal(t, 1, captures[0].Packets)
+	assert.Equal(t, 1, captures[0].Dropped)
+
+	response = serve(http.MethodGet, "/pcap"+query)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, "application/x-pcapng", response.Header().Get("Content-Type"))
+	blocks := readBlocks(t, response.Body.Bytes())
+	assert.Len(t, blocks, 3)
+	packet, _ := readPacket(blocks[2])
+	assert.Equal(t, byte(ipProtocolUdp), packet[9])
+	assert.Equal(t, uint16(8+5), binary.BigEndian.Uint16(packet[24:26]))
+	assert.Equal(t, "query", string(packet[28:]))
+
+	assert.Equal(t, http.StatusNoContent, serve(http.MethodDelete, "/pcap"+query).Code)
+	assert.False(t, recorder.Capturing())
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodDelete, "/pcap"+query).Code)
+}
@@ -0,0 +1,171 @@
+package bolt
+
+import (
+	"encoding/binary"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+// The versions of the protocol, see https://github.com/sofastack/sofa-bolt/wiki/SOFA-Bolt-Protocol-Basic-Design
+const (
+	protocolV1 = 1
+	protocolV2 = 2
+)
+
+// version is the only version of the commands, which is ver2 of V1, and both ver1 and ver2 of V2.
+const version = 1
+
+// The types of the commands.
+const (
+	typeResponse = 0
+	typeRequest  = 1
+	typeOneway   = 2
+)
+
+// The codes of the commands.
+const (
+	cmdHeartbeat = 0
+	cmdRequest   = 1
+	cmdResponse  = 2
+)
+
+// The keys of the header set by SOFARPC.
+const (
+	headerTargetService = "sofa_head_target_service"
+	headerMethodName    = "sofa_head_method_name"
+	headerService       = "service"
+)
+
+// minCommandLength is the length of the fixed fields of the responses of V1, which are the shortest.
+const minCommandLength = 20
+
+// maxLength limits the lengths of the class, the header and the content.
+const maxLength = 16 * 1024 * 1024
+
+type command struct {
+	typ       uint8
+	cmdCode   uint16
+	requestId uint32
+	// status is the response status.
+	status uint16
+	header []byte
+}
+
+// readCommand reads the fixed fields of the command. The header is read if it is not truncated.
+//
+// Request of V1:  proto(1) type(1) cmdcode(2) ver2(1) requestId(4) codec(1) timeout(4) classLen(2) headerLen(2) contentLen(4)
+// Response of V1: proto(1) type(1) cmdcode(2) ver2(1) requestId(4) codec(1) respstatus(2) classLen(2) headerLen(2) contentLen(4)
+// V2 adds ver1(1) after proto and switch(1) after codec.
+func readCommand(data []byte) (*command, bool) {
+	if len(data) < 2 {
+		return nil, false
+	}
+	offset := 1
+	switch data[0] {
+	case protocolV1:
+	case protocolV2:
+		if data[1] != version {
+			return nil, false
+		}
+		offset++
+	default:
+		return nil, false
+	}
+	if len(data) < offset+8 {
+		return nil, false
+	}
+	cmd := &command{
+		typ:       data[offset],
+		cmdCode:   binary.BigEndian.Uint16(data[offset+1:]),
+		requestId: binary.BigEndian.Uint32(data[offset+4:]),
+	}
+	if data[offset+3] != version {
+		return nil, false
+	}
+	// Skip type, cmdcode, ver2, requestId and codec
+	offset += 9
+	if data[0] == protocolV2 {
+		offset++
+	}
+	if cmd.cmdCode > cmdResponse {
+		return nil, false
+	}
+	switch cmd.typ {
+	case typeRequest, typeOneway:
+		if cmd.cmdCode == cmdResponse {
+			return nil, false
+		}
+		// timeout
+		offset += 4
+	case typeResponse:
+		if cmd.cmdCode == cmdRequest || len(data) < offset+2 {
+			return nil, false
+		}
+		cmd.status = binary.BigEndian.Uint16(data[offset:])
+		offset += 2
+	default:
+		return nil, false
+	}
+	if len(data) < offset+8 {
+		return nil, false
+	}
+	classLength := int(binary.BigEndian.Uint16(data[offset:]))
+	headerLength := int(binary.BigEndian.Uint16(data[offset+2:]))
+	contentLength := binary.BigEndian.Uint32(data[offset+4:])
+	// The RPC commands always hold the class of the content.
+	if contentLength > maxLength || (cmd.cmdCode != cmdHeartbeat && classLength == 0) {
+		return nil, false
+	}
+	offset += 8 + classLength
+	if offset+headerLength <= len(data) {
+		cmd.header = data[offset : offset+headerLength]
+	}
+	return cmd, true
+}
+
+// readHeader reads the header serialized by SimpleMapSerializer, whose keys and values are prefixed by
+// their lengths in 4 bytes. It returns the entries before the invalid one.
+func readHeader(data []byte) map[string]string {
+	header := make(map[string]string)
+	for offset := 0; offset < len(data); {
+		var entry [2]string
+		for i := 0; i < 2; i++ {
+			if offset+4 > len(data) {
+				return header
+			}
+			length := int(int32(binary.BigEndian.Uint32(data[offset:])))
+			offset += 4
+			if length < 0 {
+				// null
+				continue
+			}
+			if length > len(data)-offset {
+				return header
+			}
+			entry[i] = string(data[offset : offset+length])
+			offset += length
+		}
+		header[entry[0]] = entry[1]
+	}
+	return header
+}
+
+func NewBoltParser() *protocol.ProtocolParser {
+	requestParser := protocol.CreatePkgParser(fastfailBoltRequest(), parseBoltRequest())
+	responseParser := protocol.CreatePkgParser(fastfailBoltResponse(), parseBoltResponse())
+	return protocol.NewProtocolParser(protocol.BOLT, requestParser, responseParser, boltPair())
+}
+
+// boltPair matches the responses with the requests by the request id, as the requests of a connection
+// could be responded out of order.
+func boltPair() protocol.PairMatch {
+	return func(requests []*protocol.PayloadMessage, response *protocol.PayloadMessage) int {
+		for i, request := range requests {
+			if request.GetIntAttribute(constlabels.BoltRequestId) == response.GetIntAttribute(constlabels.BoltRequestId) {
+				return i
+			}
+		}
+		return -1
+	}
+}
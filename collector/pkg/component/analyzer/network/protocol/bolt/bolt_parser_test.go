@@ -0,0 +1,117 @@
+package bolt
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+const requestClass = "com.alipay.sofa.rpc.core.request.SofaRequest"
+
+func newHeader(entries ...string) []byte {
+	var header []byte
+	for _, entry := range entries {
+		header = binary.BigEndian.AppendUint32(header, uint32(len(entry)))
+		header = append(header, entry...)
+	}
+	return header
+}
+
+func appendBody(data []byte, class string, header []byte, content []byte) []byte {
+	data = binary.BigEndian.AppendUint16(data, uint16(len(class)))
+	data = binary.BigEndian.AppendUint16(data, uint16(len(header)))
+	data = binary.BigEndian.AppendUint32(data, uint32(len(content)))
+	data = append(data, class...)
+	data = append(data, header...)
+	return append(data, content...)
+}
+
+func newRequest(proto uint8, typ uint8, requestId uint32, header []byte) []byte {
+	data := []byte{proto}
+	if proto == protocolV2 {
+		data = append(data, version)
+	}
+	data = append(data, typ, 0, cmdRequest, version)
+	data = binary.BigEndian.AppendUint32(data, requestId)
+	// codec
+	data = append(data, 1)
+	if proto == protocolV2 {
+		// switch
+		data = append(data, 0)
+	}
+	data = binary.BigEndian.AppendUint32(data, 3000)
+	return appendBody(data, requestClass, header, []byte("content"))
+}
+
+func newResponse(requestId uint32, status uint16) []byte {
+	data := []byte{protocolV1, typeResponse, 0, cmdResponse, version}
+	data = binary.BigEndian.AppendUint32(data, requestId)
+	data = append(data, 1)
+	data = binary.BigEndian.AppendUint16(data, status)
+	return appendBody(data, "com.alipay.sofa.rpc.core.response.SofaResponse", nil, []byte("content"))
+}
+
+func TestParseBolt(t *testing.T) {
+	parser := NewBoltParser()
+	header := newHeader(headerTargetService, "com.example.HelloService:1.0", headerMethodName, "sayHello")
+	first := protocol.NewRequestMessage(newRequest(protocolV1, typeRequest, 7, header))
+	assert.True(t, parser.ParseRequest(first))
+	assert.Equal(t, int64(7), first.GetIntAttribute(constlabels.BoltRequestId))
+	assert.Equal(t, "com.example.HelloService:1.0#sayHello", first.GetStringAttribute(constlabels.ContentKey))
+	second := protocol.NewRequestMessage(newRequest(protocolV2, typeRequest, 8, header))
+	assert.True(t, parser.ParseRequest(second))
+	assert.Equal(t, "sayHello", second.GetStringAttribute(constlabels.BoltMethod))
+	requests := []*protocol.PayloadMessage{first, second}
+
+	response := protocol.NewResponseMessage(newResponse(8, statusSuccess), protocol.NewRequestMessage(nil).GetAttributes())
+	assert.True(t, parser.ParseResponse(response))
+	assert.Equal(t, 1, parser.PairMatch(requests, response))
+	assert.False(t, response.GetBoolAttribute(constlabels.IsError))
+
+	// SERVER_THREADPOOL_BUSY
+	response = protocol.NewResponseMessage(newResponse(7, 0x0004), protocol.NewRequestMessage(nil).GetAttributes())
+	assert.True(t, parser.ParseResponse(response))
+	assert.Equal(t, 0, parser.PairMatch(requests, response))
+	assert.True(t, response.GetBoolAttribute(constlabels.IsError))
+	assert.Equal(t, int64(4), response.GetIntAttribute(constlabels.BoltResponseStatus))
+}
+
+func TestParseOnewayAndHeartbeat(t *testing.T) {
+	parser := NewBoltParser()
+	oneway := protocol.NewRequestMessage(newRequest(protocolV1, typeOneway, 9, newHeader(headerService, "com.example.LogService:1.0")))
+	assert.True(t, parser.ParseRequest(oneway))
+	assert.True(t, oneway.GetBoolAttribute(constlabels.Oneway))
+	assert.Equal(t, "com.example.LogService:1.0#", oneway.GetStringAttribute(constlabels.ContentKey))
+
+	heartbeat := []byte{protocolV1, typeRequest, 0, cmdHeartbeat, version, 0, 0, 0, 10, 1}
+	heartbeat = binary.BigEndian.AppendUint32(heartbeat, 1000)
+	heartbeat = appendBody(heartbeat, "", nil, nil)
+	request := protocol.NewRequestMessage(heartbeat)
+	assert.True(t, parser.ParseRequest(request))
+	assert.Equal(t, "Heartbeat", request.GetStringAttribute(constlabels.ContentKey))
+}
+
+func TestParseInvalid(t *testing.T) {
+	parser := NewBoltParser()
+	data := newRequest(protocolV1, typeRequest, 7, newHeader(headerTargetService, "com.example.HelloService:1.0", headerMethodName, "sayHello"))
+	// The header is truncated.
+	request := protocol.NewRequestMessage(data[:len(data)-10])
+	assert.True(t, parser.ParseRequest(request))
+	assert.False(t, request.HasAttribute(constlabels.BoltService))
+	// A request is not a response.
+	assert.False(t, parser.ParseResponse(protocol.NewResponseMessage(data, protocol.NewRequestMessage(nil).GetAttributes())))
+	// Unknown version
+	invalid := append([]byte(nil), data...)
+	invalid[4] = 3
+	assert.False(t, parser.ParseRequest(protocol.NewRequestMessage(invalid)))
+	// Unknown command code
+	invalid = append([]byte(nil), data...)
+	invalid[3] = 5
+	assert.False(t, parser.ParseRequest(protocol.NewRequestMessage(invalid)))
+	// HTTP
+	assert.False(t, parser.ParseRequest(protocol.NewRequestMessage([]byte("GET /index.html HTTP/1.1\r\nHost: localhost\r\n\r\n"))))
+}
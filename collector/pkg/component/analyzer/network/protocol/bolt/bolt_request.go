@@ -0,0 +1,47 @@
+package bolt
+
+import (
+	"strconv"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+func fastfailBoltRequest() protocol.FastFailFn {
+	return func(message *protocol.PayloadMessage) bool {
+		return len(message.Data) < minCommandLength || (message.Data[0] != protocolV1 && message.Data[0] != protocolV2)
+	}
+}
+
+func parseBoltRequest() protocol.ParsePkgFn {
+	return func(message *protocol.PayloadMessage) (bool, bool) {
+		cmd, ok := readCommand(message.Data)
+		if !ok || cmd.typ == typeResponse {
+			return false, true
+		}
+
+		message.AddIntAttribute(constlabels.BoltRequestId, int64(cmd.requestId))
+		message.AddStringAttribute(constlabels.ProtocolVersion, strconv.Itoa(int(message.Data[0])))
+		if cmd.typ == typeOneway {
+			message.AddBoolAttribute(constlabels.Oneway, true)
+		}
+		if cmd.cmdCode == cmdHeartbeat {
+			message.AddStringAttribute(constlabels.ContentKey, "Heartbeat")
+			return true, true
+		}
+		header := readHeader(cmd.header)
+		service := header[headerTargetService]
+		if service == "" {
+			service = header[headerService]
+		}
+		method := header[headerMethodName]
+		if service != "" {
+			message.AddUtf8StringAttribute(constlabels.BoltService, service)
+			message.AddUtf8StringAttribute(constlabels.ContentKey, service+"#"+method)
+		}
+		if method != "" {
+			message.AddUtf8StringAttribute(constlabels.BoltMethod, method)
+		}
+		return true, true
+	}
+}
@@ -0,0 +1,33 @@
+package bolt
+
+import (
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+// statusSuccess is the response status of the successful requests. The others are the errors, e.g.
+// 0x0002 SERVER_EXCEPTION, 0x0004 SERVER_THREADPOOL_BUSY and 0x0007 TIMEOUT.
+const statusSuccess = 0
+
+func fastfailBoltResponse() protocol.FastFailFn {
+	return func(message *protocol.PayloadMessage) bool {
+		return len(message.Data) < minCommandLength || (message.Data[0] != protocolV1 && message.Data[0] != protocolV2)
+	}
+}
+
+func parseBoltResponse() protocol.ParsePkgFn {
+	return func(message *protocol.PayloadMessage) (bool, bool) {
+		cmd, ok := readCommand(message.Data)
+		if !ok || cmd.typ != typeResponse {
+			return false, true
+		}
+
+		message.AddIntAttribute(constlabels.BoltRequestId, int64(cmd.requestId))
+		message.AddIntAttribute(constlabels.BoltResponseStatus, int64(cmd.status))
+		if cmd.status != statusSuccess {
+			message.AddBoolAttribute(constlabels.IsError, true)
+			message.AddIntAttribute(constlabels.ErrorType, int64(constlabels.ProtocolError))
+		}
+		return true, true
+	}
+}
@@ -0,0 +1,159 @@
+package brpc
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// The wire types of the protobuf fields.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+var errInvalidMeta = errors.New("brpc: the meta is invalid")
+
+// The numbers of the fields in RpcMeta, see https://github.com/apache/brpc/blob/master/src/brpc/policy/baidu_rpc_meta.proto
+const (
+	metaRequest       = 1
+	metaResponse      = 2
+	metaCorrelationId = 4
+)
+
+// The numbers of the fields in RpcRequestMeta.
+const (
+	requestServiceName = 1
+	requestMethodName  = 2
+)
+
+// The numbers of the fields in RpcResponseMeta.
+const (
+	responseErrorCode = 1
+	responseErrorText = 2
+)
+
+type rpcMeta struct {
+	hasRequest    bool
+	correlationId int64
+	serviceName   string
+	methodName    string
+	errorCode     int64
+	errorText     string
+}
+
+// protoField is a field of a protobuf message, whose value is either the integer or the bytes.
+type protoField struct {
+	number   uint64
+	wireType uint64
+	integer  uint64
+	bytes    []byte
+}
+
+// readProtoFields reads the fields of a protobuf message. The message must be complete.
+func readProtoFields(data []byte) ([]protoField, error) {
+	fields := make([]protoField, 0, 4)
+	for offset := 0; offset < len(data); {
+		key, n := binary.Uvarint(data[offset:])
+		if n <= 0 {
+			return nil, errInvalidMeta
+		}
+		offset += n
+		field := protoField{number: key >> 3, wireType: key & 0x7}
+		switch field.wireType {
+		case wireVarint:
+			if field.integer, n = binary.Uvarint(data[offset:]); n <= 0 {
+				return nil, errInvalidMeta
+			}
+			offset += n
+		case wireFixed64:
+			if offset+8 > len(data) {
+				return nil, errInvalidMeta
+			}
+			field.integer = binary.LittleEndian.Uint64(data[offset:])
+			offset += 8
+		case wireFixed32:
+			if offset+4 > len(data) {
+				return nil, errInvalidMeta
+			}
+			field.integer = uint64(binary.LittleEndian.Uint32(data[offset:]))
+			offset += 4
+		case wireBytes:
+			length, n := binary.Uvarint(data[offset:])
+			if n <= 0 || length > uint64(len(data)-offset-n) {
+				return nil, errInvalidMeta
+			}
+			offset += n
+			field.bytes = data[offset : offset+int(length)]
+			offset += int(length)
+		default:
+			return nil, errInvalidMeta
+		}
+		if field.number == 0 {
+			return nil, errInvalidMeta
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+func readRpcMeta(data []byte) (*rpcMeta, error) {
+	fields, err := readProtoFields(data)
+	if err != nil {
+		return nil, err
+	}
+	meta := &rpcMeta{}
+	for _, field := range fields {
+		switch {
+		case field.number == metaRequest && field.wireType == wireBytes:
+			meta.hasRequest = true
+			if err = meta.readRequestMeta(field.bytes); err != nil {
+				return nil, err
+			}
+		case field.number == metaResponse && field.wireType == wireBytes:
+			if err = meta.readResponseMeta(field.bytes); err != nil {
+				return nil, err
+			}
+		case field.number == metaCorrelationId && field.wireType == wireVarint:
+			meta.correlationId = int64(field.integer)
+		}
+	}
+	return meta, nil
+}
+
+func (meta *rpcMeta) readRequestMeta(data []byte) error {
+	fields, err := readProtoFields(data)
+	if err != nil {
+		return err
+	}
+	for _, field := range fields {
+		if field.wireType != wireBytes {
+			continue
+		}
+		switch field.number {
+		case requestServiceName:
+			meta.serviceName = string(field.bytes)
+		case requestMethodName:
+			meta.methodName = string(field.bytes)
+		}
+	}
+	return nil
+}
+
+func (meta *rpcMeta) readResponseMeta(data []byte) error {
+	fields, err := readProtoFields(data)
+	if err != nil {
+		return err
+	}
+	for _, field := range fields {
+		switch {
+		case field.number == responseErrorCode && field.wireType == wireVarint:
+			// The negative int32 is encoded as 10 bytes.
+			meta.errorCode = int64(int32(field.integer))
+		case field.number == responseErrorText && field.wireType == wireBytes:
+			meta.errorText = string(field.bytes)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package brpc
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+// The header of the baidu_std protocol holds the magic "PRPC", the size of the body and the size of the meta.
+// The body consists of the meta serialized in protobuf, the payload and the attachment.
+var magic = []byte("PRPC")
+
+const (
+	headerLength = 12
+	// maxBodySize is the default max_body_size of bRPC.
+	maxBodySize = 64 * 1024 * 1024
+)
+
+// readMeta reads the meta following the header. The payload after the meta may be truncated.
+func readMeta(data []byte) (*rpcMeta, bool) {
+	if len(data) < headerLength || !bytes.Equal(data[:4], magic) {
+		return nil, false
+	}
+	bodySize := binary.BigEndian.Uint32(data[4:])
+	metaSize := binary.BigEndian.Uint32(data[8:])
+	if bodySize > maxBodySize || metaSize == 0 || metaSize > bodySize ||
+		uint32(len(data)-headerLength) < metaSize {
+		return nil, false
+	}
+	meta, err := readRpcMeta(data[headerLength : headerLength+metaSize])
+	if err != nil {
+		return nil, false
+	}
+	return meta, true
+}
+
+func NewBrpcParser() *protocol.ProtocolParser {
+	requestParser := protocol.CreatePkgParser(fastfailBrpcRequest(), parseBrpcRequest())
+	responseParser := protocol.CreatePkgParser(fastfailBrpcResponse(), parseBrpcResponse())
+	return protocol.NewProtocolParser(protocol.BRPC, requestParser, responseParser, brpcPair())
+}
+
+// brpcPair matches the responses with the requests by the correlation id, as the requests of a connection
+// could be responded out of order.
+func brpcPair() protocol.PairMatch {
+	return func(requests []*protocol.PayloadMessage, response *protocol.PayloadMessage) int {
+		for i, request := range requests {
+			if request.GetIntAttribute(constlabels.BrpcCorrelationId) == response.GetIntAttribute(constlabels.BrpcCorrelationId) {
+				return i
+			}
+		}
+		return -1
+	}
+}
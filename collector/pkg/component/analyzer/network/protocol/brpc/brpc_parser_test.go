@@ -0,0 +1,97 @@
+package brpc
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+func appendVarintField(data []byte, number uint64, value uint64) []byte {
+	data = binary.AppendUvarint(data, number<<3|wireVarint)
+	return binary.AppendUvarint(data, value)
+}
+
+func appendBytesField(data []byte, number uint64, value []byte) []byte {
+	data = binary.AppendUvarint(data, number<<3|wireBytes)
+	data = binary.AppendUvarint(data, uint64(len(value)))
+	return append(data, value...)
+}
+
+func newPacket(meta []byte, payload []byte) []byte {
+	data := append([]byte(nil), magic...)
+	data = binary.BigEndian.AppendUint32(data, uint32(len(meta)+len(payload)))
+	data = binary.BigEndian.AppendUint32(data, uint32(len(meta)))
+	return append(append(data, meta...), payload...)
+}
+
+func newRequest(correlationId uint64, service string, method string) []byte {
+	request := appendBytesField(nil, requestServiceName, []byte(service))
+	request = appendBytesField(request, requestMethodName, []byte(method))
+	// log_id
+	request = appendVarintField(request, 3, 12345)
+	meta := appendBytesField(nil, metaRequest, request)
+	meta = appendVarintField(meta, metaCorrelationId, correlationId)
+	return newPacket(meta, []byte{0x0a, 0x05, 'h', 'e', 'l', 'l', 'o'})
+}
+
+func newResponse(correlationId uint64, errorCode int32, errorText string) []byte {
+	var response []byte
+	if errorCode != 0 {
+		response = appendVarintField(response, responseErrorCode, uint64(int64(errorCode)))
+		response = appendBytesField(response, responseErrorText, []byte(errorText))
+	}
+	meta := appendBytesField(nil, metaResponse, response)
+	meta = appendVarintField(meta, metaCorrelationId, correlationId)
+	return newPacket(meta, []byte{0x0a, 0x05, 'w', 'o', 'r', 'l', 'd'})
+}
+
+func TestParseBrpc(t *testing.T) {
+	parser := NewBrpcParser()
+	first := protocol.NewRequestMessage(newRequest(1, "example.EchoService", "Echo"))
+	assert.True(t, parser.ParseRequest(first))
+	assert.Equal(t, "example.EchoService#Echo", first.GetStringAttribute(constlabels.ContentKey))
+	second := protocol.NewRequestMessage(newRequest(2, "example.EchoService", "Echo"))
+	assert.True(t, parser.ParseRequest(second))
+	requests := []*protocol.PayloadMessage{first, second}
+
+	// The second request is responded first.
+	response := protocol.NewResponseMessage(newResponse(2, 0, ""), protocol.NewRequestMessage(nil).GetAttributes())
+	assert.True(t, parser.ParseResponse(response))
+	assert.Equal(t, 1, parser.PairMatch(requests, response))
+	assert.False(t, response.GetBoolAttribute(constlabels.IsError))
+
+	// The negative error code is encoded in 10 bytes.
+	response = protocol.NewResponseMessage(newResponse(1, -1, "timeout"), protocol.NewRequestMessage(nil).GetAttributes())
+	assert.True(t, parser.ParseResponse(response))
+	assert.Equal(t, 0, parser.PairMatch(requests, response))
+	assert.True(t, response.GetBoolAttribute(constlabels.IsError))
+	assert.Equal(t, int64(-1), response.GetIntAttribute(constlabels.BrpcErrorCode))
+	assert.Equal(t, "timeout", response.GetStringAttribute(constlabels.BrpcErrorText))
+
+	response = protocol.NewResponseMessage(newResponse(3, 1008, "EREQUEST"), protocol.NewRequestMessage(nil).GetAttributes())
+	assert.True(t, parser.ParseResponse(response))
+	assert.Equal(t, -1, parser.PairMatch(requests, response))
+}
+
+func TestParseInvalid(t *testing.T) {
+	parser := NewBrpcParser()
+	data := newRequest(1, "example.EchoService", "Echo")
+	// The payload is truncated.
+	assert.True(t, parser.ParseRequest(protocol.NewRequestMessage(data[:len(data)-3])))
+	// The meta is truncated.
+	assert.False(t, parser.ParseRequest(protocol.NewRequestMessage(data[:20])))
+	// A request is not a response.
+	assert.False(t, parser.ParseResponse(protocol.NewResponseMessage(data, protocol.NewRequestMessage(nil).GetAttributes())))
+	// The meta is larger than the body.
+	invalid := append([]byte(nil), data...)
+	binary.BigEndian.PutUint32(invalid[4:], 1)
+	assert.False(t, parser.ParseRequest(protocol.NewRequestMessage(invalid)))
+	// The meta is not protobuf.
+	assert.False(t, parser.ParseRequest(protocol.NewRequestMessage(newPacket([]byte{0x0f, 0x01}, nil))))
+	// HTTP
+	assert.False(t, parser.ParseRequest(protocol.NewRequestMessage([]byte("GET /index.html HTTP/1.1\r\nHost: localhost\r\n\r\n"))))
+}
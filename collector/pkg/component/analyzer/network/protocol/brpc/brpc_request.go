@@ -0,0 +1,29 @@
+package brpc
+
+import (
+	"bytes"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+func fastfailBrpcRequest() protocol.FastFailFn {
+	return func(message *protocol.PayloadMessage) bool {
+		return len(message.Data) < headerLength || !bytes.Equal(message.Data[:4], magic)
+	}
+}
+
+func parseBrpcRequest() protocol.ParsePkgFn {
+	return func(message *protocol.PayloadMessage) (bool, bool) {
+		meta, ok := readMeta(message.Data)
+		if !ok || !meta.hasRequest || meta.serviceName == "" || meta.methodName == "" {
+			return false, true
+		}
+
+		message.AddIntAttribute(constlabels.BrpcCorrelationId, meta.correlationId)
+		message.AddUtf8StringAttribute(constlabels.BrpcService, meta.serviceName)
+		message.AddUtf8StringAttribute(constlabels.BrpcMethod, meta.methodName)
+		message.AddUtf8StringAttribute(constlabels.ContentKey, meta.serviceName+"#"+meta.methodName)
+		return true, true
+	}
+}
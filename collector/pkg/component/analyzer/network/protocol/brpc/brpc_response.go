@@ -0,0 +1,34 @@
+package brpc
+
+import (
+	"bytes"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+func fastfailBrpcResponse() protocol.FastFailFn {
+	return func(message *protocol.PayloadMessage) bool {
+		return len(message.Data) < headerLength || !bytes.Equal(message.Data[:4], magic)
+	}
+}
+
+func parseBrpcResponse() protocol.ParsePkgFn {
+	return func(message *protocol.PayloadMessage) (bool, bool) {
+		meta, ok := readMeta(message.Data)
+		if !ok || meta.hasRequest {
+			return false, true
+		}
+
+		message.AddIntAttribute(constlabels.BrpcCorrelationId, meta.correlationId)
+		message.AddIntAttribute(constlabels.BrpcErrorCode, meta.errorCode)
+		if meta.errorCode != 0 {
+			if meta.errorText != "" {
+				message.AddUtf8StringAttribute(constlabels.BrpcErrorText, meta.errorText)
+			}
+			message.AddBoolAttribute(constlabels.IsError, true)
+			message.AddIntAttribute(constlabels.ErrorType, int64(constlabels.ProtocolError))
+		}
+		return true, true
+	}
+}
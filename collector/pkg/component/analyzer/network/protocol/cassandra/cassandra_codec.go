@@ -0,0 +1,138 @@
+package cassandra
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+var (
+	errTruncated = errors.New("cassandra: the body is truncated")
+	errInvalid   = errors.New("cassandra: the body is invalid")
+)
+
+// cqlReader reads the notations of the body, which are big-endian.
+type cqlReader struct {
+	data   []byte
+	offset int
+}
+
+func (r *cqlReader) skip(n int) error {
+	if n < 0 {
+		return errInvalid
+	}
+	if r.offset+n > len(r.data) {
+		return errTruncated
+	}
+	r.offset += n
+	return nil
+}
+
+func (r *cqlReader) readByte() (uint8, error) {
+	if r.offset+1 > len(r.data) {
+		return 0, errTruncated
+	}
+	r.offset++
+	return r.data[r.offset-1], nil
+}
+
+func (r *cqlReader) readShort() (uint16, error) {
+	if r.offset+2 > len(r.data) {
+		return 0, errTruncated
+	}
+	r.offset += 2
+	return binary.BigEndian.Uint16(r.data[r.offset-2:]), nil
+}
+
+func (r *cqlReader) readInt() (int32, error) {
+	if r.offset+4 > len(r.data) {
+		return 0, errTruncated
+	}
+	r.offset += 4
+	return int32(binary.BigEndian.Uint32(r.data[r.offset-4:])), nil
+}
+
+// readString reads the [string], which is prefixed by the length in [short].
+func (r *cqlReader) readString() (string, error) {
+	length, err := r.readShort()
+	if err != nil {
+		return "", err
+	}
+	return r.readN(int(length))
+}
+
+// readLongString reads the [long string], which is prefixed by the length in [int]. The truncated
+// string is returned with errTruncated.
+func (r *cqlReader) readLongString() (string, error) {
+	length, err := r.readInt()
+	if err != nil {
+		return "", err
+	}
+	if length < 0 {
+		return "", errInvalid
+	}
+	if r.offset+int(length) > len(r.data) {
+		s := string(r.data[r.offset:])
+		r.offset = len(r.data)
+		return s, errTruncated
+	}
+	return r.readN(int(length))
+}
+
+func (r *cqlReader) readN(n int) (string, error) {
+	if r.offset+n > len(r.data) {
+		return "", errTruncated
+	}
+	r.offset += n
+	return string(r.data[r.offset-n : r.offset]), nil
+}
+
+// skipBytes skips the [bytes] or the [value], whose negative lengths mean null or not set.
+func (r *cqlReader) skipBytes() error {
+	length, err := r.readInt()
+	if err != nil {
+		return err
+	}
+	if length < 0 {
+		return nil
+	}
+	return r.skip(int(length))
+}
+
+func (r *cqlReader) skipShortBytes() error {
+	length, err := r.readShort()
+	if err != nil {
+		return err
+	}
+	return r.skip(int(length))
+}
+
+// skipStringList skips the [string list] of the warnings.
+func (r *cqlReader) skipStringList() error {
+	n, err := r.readShort()
+	if err != nil {
+		return err
+	}
+	for i := 0; i < int(n); i++ {
+		if err = r.skipShortBytes(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// skipBytesMap skips the [bytes map] of the custom payload.
+func (r *cqlReader) skipBytesMap() error {
+	n, err := r.readShort()
+	if err != nil {
+		return err
+	}
+	for i := 0; i < int(n); i++ {
+		if err = r.skipShortBytes(); err != nil {
+			return err
+		}
+		if err = r.skipBytes(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
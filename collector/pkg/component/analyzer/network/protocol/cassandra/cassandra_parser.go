@@ -0,0 +1,169 @@
+package cassandra
+
+import (
+	"encoding/binary"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+// The versions of the native protocol, see https://github.com/apache/cassandra/tree/trunk/doc
+// The highest bit of the version is set for the responses.
+const (
+	version4          = 0x04
+	version5          = 0x05
+	versionResponse   = 0x80
+	versionNumberMask = 0x7f
+)
+
+// The flags of the frame.
+const (
+	flagCompression   = 0x01
+	flagTracing       = 0x02
+	flagCustomPayload = 0x04
+	flagWarning       = 0x08
+	flagUseBeta       = 0x10
+	flagMask          = 0x1f
+)
+
+// The opcodes of the requests.
+const (
+	opStartup      = 0x01
+	opOptions      = 0x05
+	opQuery        = 0x07
+	opPrepare      = 0x09
+	opExecute      = 0x0a
+	opRegister     = 0x0b
+	opBatch        = 0x0d
+	opAuthResponse = 0x0f
+)
+
+// The opcodes of the responses.
+const (
+	opError         = 0x00
+	opReady         = 0x02
+	opAuthenticate  = 0x03
+	opSupported     = 0x06
+	opResult        = 0x08
+	opEvent         = 0x0c
+	opAuthChallenge = 0x0e
+	opAuthSuccess   = 0x10
+)
+
+var requestOpcodes = map[uint8]string{
+	opStartup:      "STARTUP",
+	opOptions:      "OPTIONS",
+	opQuery:        "QUERY",
+	opPrepare:      "PREPARE",
+	opExecute:      "EXECUTE",
+	opRegister:     "REGISTER",
+	opBatch:        "BATCH",
+	opAuthResponse: "AUTH_RESPONSE",
+}
+
+var responseOpcodes = map[uint8]string{
+	opError:         "ERROR",
+	opReady:         "READY",
+	opAuthenticate:  "AUTHENTICATE",
+	opSupported:     "SUPPORTED",
+	opResult:        "RESULT",
+	opEvent:         "EVENT",
+	opAuthChallenge: "AUTH_CHALLENGE",
+	opAuthSuccess:   "AUTH_SUCCESS",
+}
+
+const (
+	frameHeaderLength = 9
+	// maxFrameLength is the max length of the frame body defined by the protocol.
+	maxFrameLength = 256 * 1024 * 1024
+)
+
+// After the handshake, the frames of v5 are wrapped in the segments. The uncompressed header is 3 bytes
+// holding the length of the payload in the low 17 bits and the self-contained flag, followed by CRC24.
+const (
+	segmentHeaderLength = 6
+	segmentLengthMask   = 0x1ffff
+)
+
+type frame struct {
+	version  uint8
+	response bool
+	flags    uint8
+	stream   int16
+	opcode   uint8
+	// body may be truncated.
+	body []byte
+}
+
+// readFrame reads the frame at the beginning of the data, or the first frame in the segment of v5.
+// The segments are assumed to be uncompressed, as the compressed ones could not be told apart.
+func readFrame(data []byte) (*frame, bool) {
+	if f, ok := readFrameHeader(data); ok {
+		return f, true
+	}
+	if len(data) < segmentHeaderLength+frameHeaderLength {
+		return nil, false
+	}
+	payloadLength := int((uint32(data[0]) | uint32(data[1])<<8 | uint32(data[2])<<16) & segmentLengthMask)
+	if payloadLength < frameHeaderLength {
+		return nil, false
+	}
+	f, ok := readFrameHeader(data[segmentHeaderLength:])
+	if !ok || f.version != version5 {
+		return nil, false
+	}
+	return f, true
+}
+
+// readFrameHeader reads the header of the frame, whose length is only checked against the limit as the
+// body may be truncated.
+func readFrameHeader(data []byte) (*frame, bool) {
+	if len(data) < frameHeaderLength {
+		return nil, false
+	}
+	f := &frame{
+		version:  data[0] & versionNumberMask,
+		response: data[0]&versionResponse != 0,
+		flags:    data[1],
+		stream:   int16(binary.BigEndian.Uint16(data[2:])),
+		opcode:   data[4],
+	}
+	if (f.version != version4 && f.version != version5) || f.flags&^flagMask != 0 {
+		return nil, false
+	}
+	if f.response {
+		if _, ok := responseOpcodes[f.opcode]; !ok {
+			return nil, false
+		}
+	} else if _, ok := requestOpcodes[f.opcode]; !ok {
+		return nil, false
+	}
+	length := binary.BigEndian.Uint32(data[5:])
+	if length > maxFrameLength {
+		return nil, false
+	}
+	f.body = data[frameHeaderLength:]
+	if uint32(len(f.body)) > length {
+		f.body = f.body[:length]
+	}
+	return f, true
+}
+
+func NewCassandraParser() *protocol.ProtocolParser {
+	requestParser := protocol.CreatePkgParser(fastfailCassandraRequest(), parseCassandraRequest())
+	responseParser := protocol.CreatePkgParser(fastfailCassandraResponse(), parseCassandraResponse())
+	return protocol.NewProtocolParser(protocol.CASSANDRA, requestParser, responseParser, cassandraPair())
+}
+
+// cassandraPair matches the responses with the requests by the stream id, as the requests of a
+// connection are multiplexed on the streams and could be responded out of order.
+func cassandraPair() protocol.PairMatch {
+	return func(requests []*protocol.PayloadMessage, response *protocol.PayloadMessage) int {
+		for i, request := range requests {
+			if request.GetIntAttribute(constlabels.CassandraStreamId) == response.GetIntAttribute(constlabels.CassandraStreamId) {
+				return i
+			}
+		}
+		return -1
+	}
+}
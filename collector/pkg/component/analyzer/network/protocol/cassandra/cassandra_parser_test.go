@@ -0,0 +1,225 @@
+package cassandra
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+func newFrame(version uint8, flags uint8, stream int16, opcode uint8, body []byte) []byte {
+	data := []byte{version, flags}
+	data = binary.BigEndian.AppendUint16(data, uint16(stream))
+	data = append(data, opcode)
+	data = binary.BigEndian.AppendUint32(data, uint32(len(body)))
+	return append(data, body...)
+}
+
+// newSegment wraps the frame in the uncompressed segment of v5. The CRCs are not checked by the parser.
+func newSegment(frame []byte) []byte {
+	header := uint32(len(frame)) | 1<<17
+	data := []byte{byte(header), byte(header >> 8), byte(header >> 16), 0, 0, 0}
+	data = append(data, frame...)
+	return append(data, 0, 0, 0, 0)
+}
+
+func appendString(data []byte, s string) []byte {
+	data = binary.BigEndian.AppendUint16(data, uint16(len(s)))
+	return append(data, s...)
+}
+
+func appendLongString(data []byte, s string) []byte {
+	data = binary.BigEndian.AppendUint32(data, uint32(len(s)))
+	return append(data, s...)
+}
+
+func newQuery(version uint8, stream int16, query string) []byte {
+	body := appendLongString(nil, query)
+	// ONE
+	body = binary.BigEndian.AppendUint16(body, 0x0001)
+	if version == version5 {
+		body = binary.BigEndian.AppendUint32(body, 0)
+	} else {
+		body = append(body, 0)
+	}
+	return newFrame(version, 0, stream, opQuery, body)
+}
+
+func newError(version uint8, flags uint8, stream int16, code int32, errMsg string) []byte {
+	var body []byte
+	if flags&flagWarning != 0 {
+		body = binary.BigEndian.AppendUint16(body, 1)
+		body = appendString(body, "Aggregation query used without partition key")
+	}
+	body = binary.BigEndian.AppendUint32(body, uint32(code))
+	body = appendString(body, errMsg)
+	return newFrame(version|versionResponse, flags, stream, opError, body)
+}
+
+func newVoidResult(version uint8, stream int16) []byte {
+	return newFrame(version|versionResponse, 0, stream, opResult, []byte{0, 0, 0, 1})
+}
+
+func TestParseQuery(t *testing.T) {
+	parser := NewCassandraParser()
+	first := protocol.NewRequestMessage(newQuery(version4, 1, "SELECT * FROM ks.users WHERE id = 123e4567-e89b-12d3-a456-426614174000 AND name = 'it''s'"))
+	assert.True(t, parser.ParseRequest(first))
+	assert.Equal(t, "QUERY", first.GetStringAttribute(constlabels.CassandraOpcode))
+	assert.Equal(t, "SELECT * FROM ks.users WHERE id = ? AND name = ?", first.GetStringAttribute(constlabels.CassandraQuery))
+	assert.Equal(t, "select ks.users", first.GetStringAttribute(constlabels.ContentKey))
+	assert.Equal(t, "ks", first.GetStringAttribute(constlabels.CassandraKeyspace))
+
+	second := protocol.NewRequestMessage(newQuery(version4, 2, "UPDATE Orders SET paid = true WHERE id = 42"))
+	assert.True(t, parser.ParseRequest(second))
+	assert.Equal(t, "update orders", second.GetStringAttribute(constlabels.ContentKey))
+	assert.False(t, second.HasAttribute(constlabels.CassandraKeyspace))
+	requests := []*protocol.PayloadMessage{first, second}
+
+	// The second request is responded first.
+	response := protocol.NewResponseMessage(newVoidResult(version4, 2), protocol.NewRequestMessage(nil).GetAttributes())
+	assert.True(t, parser.ParseResponse(response))
+	assert.Equal(t, 1, parser.PairMatch(requests, response))
+	assert.False(t, response.GetBoolAttribute(constlabels.IsError))
+	assert.False(t, response.HasAttribute(constlabels.CassandraErrCode))
+
+	response = protocol.NewResponseMessage(newError(version4, flagWarning, 1, 0x1200, "Operation timed out"), protocol.NewRequestMessage(nil).GetAttributes())
+	assert.True(t, parser.ParseResponse(response))
+	assert.Equal(t, 0, parser.PairMatch(requests, response))
+	assert.True(t, response.GetBoolAttribute(constlabels.IsError))
+	assert.Equal(t, int64(0x1200), response.GetIntAttribute(constlabels.CassandraErrCode))
+	assert.Equal(t, "Operation timed out", response.GetStringAttribute(constlabels.CassandraErrMsg))
+
+	// The server error
+	response = protocol.NewResponseMessage(newError(version4, 0, 3, 0, "java.lang.NullPointerException"), protocol.NewRequestMessage(nil).GetAttributes())
+	assert.True(t, parser.ParseResponse(response))
+	assert.Equal(t, -1, parser.PairMatch(requests, response))
+	assert.True(t, response.GetBoolAttribute(constlabels.IsError))
+	assert.True(t, response.HasAttribute(constlabels.CassandraErrCode))
+}
+
+func TestParseVersion5(t *testing.T) {
+	parser := NewCassandraParser()
+
+	// The keyspace is set by the flag of the query parameters.
+	body := appendLongString(nil, "INSERT INTO users (id, name) VALUES (?, ?)")
+	body = binary.BigEndian.AppendUint16(body, 0x0001)
+	body = binary.BigEndian.AppendUint32(body, queryFlagValues|queryFlagPageSize|queryFlagKeyspace)
+	body = binary.BigEndian.AppendUint16(body, 2)
+	body = binary.BigEndian.AppendUint32(body, 4)
+	body = append(body, 0, 0, 0, 1)
+	// null
+	body = binary.BigEndian.AppendUint32(body, 0xffffffff)
+	body = binary.BigEndian.AppendUint32(body, 5000)
+	body = appendString(body, "shop")
+	request := protocol.NewRequestMessage(newSegment(newFrame(version5, 0, 7, opQuery, body)))
+	assert.True(t, parser.ParseRequest(request))
+	assert.Equal(t, int64(7), request.GetIntAttribute(constlabels.CassandraStreamId))
+	assert.Equal(t, "insert users", request.GetStringAttribute(constlabels.ContentKey))
+	assert.Equal(t, "shop", request.GetStringAttribute(constlabels.CassandraKeyspace))
+
+	body = appendLongString(nil, "SELECT name FROM users WHERE id = ?")
+	body = binary.BigEndian.AppendUint32(body, prepareFlagKeyspace)
+	body = appendString(body, "shop")
+	request = protocol.NewRequestMessage(newSegment(newFrame(version5, 0, 8, opPrepare, body)))
+	assert.True(t, parser.ParseRequest(request))
+	assert.Equal(t, "PREPARE", request.GetStringAttribute(constlabels.CassandraOpcode))
+	assert.Equal(t, "select users", request.GetStringAttribute(constlabels.ContentKey))
+	assert.Equal(t, "shop", request.GetStringAttribute(constlabels.CassandraKeyspace))
+
+	response := protocol.NewResponseMessage(newSegment(newError(version5, 0, 8, 0x2200, "unconfigured table users")), protocol.NewRequestMessage(nil).GetAttributes())
+	assert.True(t, parser.ParseResponse(response))
+	assert.Equal(t, int64(8), response.GetIntAttribute(constlabels.CassandraStreamId))
+	assert.Equal(t, "unconfigured table users", response.GetStringAttribute(constlabels.CassandraErrMsg))
+}
+
+func TestParseOtherRequests(t *testing.T) {
+	parser := NewCassandraParser()
+
+	body := binary.BigEndian.AppendUint16(nil, 1)
+	body = appendString(body, "CQL_VERSION")
+	body = appendString(body, "3.0.0")
+	request := protocol.NewRequestMessage(newFrame(version4, 0, 0, opStartup, body))
+	assert.True(t, parser.ParseRequest(request))
+	assert.Equal(t, "STARTUP", request.GetStringAttribute(constlabels.ContentKey))
+
+	body = appendString(nil, "0123456789abcdef")
+	body = binary.BigEndian.AppendUint16(body, 0x0001)
+	body = append(body, 0)
+	request = protocol.NewRequestMessage(newFrame(version4, 0, 1, opExecute, body))
+	assert.True(t, parser.ParseRequest(request))
+	assert.Equal(t, "EXECUTE", request.GetStringAttribute(constlabels.ContentKey))
+	assert.False(t, request.HasAttribute(constlabels.CassandraQuery))
+
+	request = protocol.NewRequestMessage(newQuery(version4, 2, `USE "Shop"`))
+	assert.True(t, parser.ParseRequest(request))
+	assert.Equal(t, "use", request.GetStringAttribute(constlabels.ContentKey))
+	assert.Equal(t, "Shop", request.GetStringAttribute(constlabels.CassandraKeyspace))
+
+	// The compressed body is not parsed.
+	request = protocol.NewRequestMessage(newFrame(version4, flagCompression, 3, opQuery, []byte{0, 0, 0, 40, 0xf0, 0x1d}))
+	assert.True(t, parser.ParseRequest(request))
+	assert.Equal(t, "QUERY", request.GetStringAttribute(constlabels.ContentKey))
+
+	// The query is truncated.
+	data := newQuery(version4, 4, "SELECT * FROM system.peers WHERE peer = '10.0.0.1'")
+	request = protocol.NewRequestMessage(data[:40])
+	assert.True(t, parser.ParseRequest(request))
+	assert.Equal(t, "select system.peers", request.GetStringAttribute(constlabels.ContentKey))
+}
+
+func TestParseInvalid(t *testing.T) {
+	parser := NewCassandraParser()
+	// The events are not responses.
+	event := newFrame(version4|versionResponse, 0, -1, opEvent, appendString(nil, "TOPOLOGY_CHANGE"))
+	assert.False(t, parser.ParseResponse(protocol.NewResponseMessage(event, protocol.NewRequestMessage(nil).GetAttributes())))
+	// A request is not a response.
+	query := newQuery(version4, 1, "SELECT * FROM users")
+	assert.False(t, parser.ParseResponse(protocol.NewResponseMessage(query, protocol.NewRequestMessage(nil).GetAttributes())))
+	// Unsupported version
+	assert.False(t, parser.ParseRequest(protocol.NewRequestMessage(newQuery(0x03, 1, "SELECT * FROM users"))))
+	// Unknown opcode
+	assert.False(t, parser.ParseRequest(protocol.NewRequestMessage(newFrame(version4, 0, 1, opResult, nil))))
+	// Unknown flags
+	assert.False(t, parser.ParseRequest(protocol.NewRequestMessage(newFrame(version4, 0x40, 1, opOptions, nil))))
+	// HTTP
+	assert.False(t, parser.ParseRequest(protocol.NewRequestMessage([]byte("GET /index.html HTTP/1.1\r\nHost: localhost\r\n\r\n"))))
+}
+
+func TestNormalizeQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"SELECT  *\n  FROM users\tWHERE id IN (1, 2, 3) LIMIT 10;", "SELECT * FROM users WHERE id IN (?, ?, ?) LIMIT ?;"},
+		{"INSERT INTO t (k, v, b, f) VALUES (-1.5e10, 0xcafe, false, $$it's$$)", "INSERT INTO t (k, v, b, f) VALUES (-?, ?, ?, ?)"},
+		{"-- comment\nSELECT \"Name\" FROM t1 /* inline */ WHERE v2 = 'a' // tail", "SELECT \"Name\" FROM t1 WHERE v2 = ?"},
+		{"UPDATE t SET v = 'unclosed", "UPDATE t SET v = ?"},
+		{"DELETE FROM t WHERE id = F47AC10B-58CC-4372-A567-0E02B2C3D479", "DELETE FROM t WHERE id = ?"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, normalizeQuery(tt.query), tt.query)
+	}
+}
+
+func TestDescribeStatement(t *testing.T) {
+	tests := []struct {
+		query    string
+		key      string
+		keyspace string
+	}{
+		{"SELECT * FROM \"Shop\".\"Users\" WHERE id = ?", "select \"Shop\".\"Users\"", "Shop"},
+		{"INSERT INTO shop.orders(id) VALUES (?)", "insert shop.orders", "shop"},
+		{"TRUNCATE TABLE logs", "truncate logs", ""},
+		{"CREATE TABLE IF NOT EXISTS t (k int PRIMARY KEY)", "create table", ""},
+		{"BEGIN BATCH INSERT INTO t (k) VALUES (?) APPLY BATCH", "batch", ""},
+		{"LIST ROLES", "", ""},
+	}
+	for _, tt := range tests {
+		key, keyspace := describeStatement(tt.query)
+		assert.Equal(t, tt.key, key, tt.query)
+		assert.Equal(t, tt.keyspace, keyspace, tt.query)
+	}
+}
@@ -0,0 +1,207 @@
+package cassandra
+
+import (
+	"strings"
+)
+
+// normalizeQuery replaces the literals of the CQL statement with "?", removes the comments and collapses
+// the whitespaces, so the statements only differing in the values are the same. The quoted identifiers
+// are kept.
+func normalizeQuery(query string) string {
+	var b strings.Builder
+	b.Grow(len(query))
+	space := false
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f':
+			space = true
+			continue
+		case strings.HasPrefix(query[i:], "--") || strings.HasPrefix(query[i:], "//"):
+			if end := strings.IndexByte(query[i:], '\n'); end >= 0 {
+				i += end
+			} else {
+				i = len(query)
+			}
+			space = true
+			continue
+		case strings.HasPrefix(query[i:], "/*"):
+			if end := strings.Index(query[i+2:], "*/"); end >= 0 {
+				i += end + 3
+			} else {
+				i = len(query)
+			}
+			space = true
+			continue
+		}
+		if space && b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		space = false
+
+		switch {
+		case c == '\'':
+			i = skipQuoted(query, i)
+			b.WriteByte('?')
+		case strings.HasPrefix(query[i:], "$$"):
+			if end := strings.Index(query[i+2:], "$$"); end >= 0 {
+				i += end + 3
+			} else {
+				i = len(query)
+			}
+			b.WriteByte('?')
+		case c == '"':
+			end := skipQuoted(query, i)
+			if end < len(query) {
+				b.WriteString(query[i : end+1])
+			} else {
+				b.WriteString(query[i:])
+			}
+			i = end
+		case isUuidAt(query, i):
+			i += uuidLength - 1
+			b.WriteByte('?')
+		case isDigit(c):
+			// The integers, the floats and the blobs like 0xcafe
+			end := i + 1
+			for end < len(query) && (isIdentifierByte(query[end]) || query[end] == '.') {
+				end++
+			}
+			i = end - 1
+			b.WriteByte('?')
+		case isIdentifierByte(c):
+			end := i + 1
+			for end < len(query) && isIdentifierByte(query[end]) {
+				end++
+			}
+			word := query[i:end]
+			if strings.EqualFold(word, "true") || strings.EqualFold(word, "false") {
+				b.WriteByte('?')
+			} else {
+				b.WriteString(word)
+			}
+			i = end - 1
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// skipQuoted returns the index of the closing quote of the quoted text starting at start, or the
+// length of query if it is not closed. Two quotes in a row are an escaped quote.
+func skipQuoted(query string, start int) int {
+	quote := query[start]
+	for i := start + 1; i < len(query); i++ {
+		if query[i] != quote {
+			continue
+		}
+		if i+1 < len(query) && query[i+1] == quote {
+			i++
+			continue
+		}
+		return i
+	}
+	return len(query)
+}
+
+const uuidLength = 36
+
+// isUuidAt returns whether the UUID literal like 123e4567-e89b-12d3-a456-426614174000 starts at i.
+func isUuidAt(query string, i int) bool {
+	if len(query) < i+uuidLength || (len(query) > i+uuidLength && isIdentifierByte(query[i+uuidLength])) {
+		return false
+	}
+	for j := 0; j < uuidLength; j++ {
+		c := query[i+j]
+		switch j {
+		case 8, 13, 18, 23:
+			if c != '-' {
+				return false
+			}
+		default:
+			if !isDigit(c) && !('a' <= c && c <= 'f') && !('A' <= c && c <= 'F') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func isIdentifierByte(c byte) bool {
+	return isDigit(c) || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || c == '_'
+}
+
+// describeStatement returns the content key of the normalized statement and the keyspace in it.
+// The key is composed of the statement and the table for DML, e.g. "select ks.users", and of the
+// statement and the type of the object for DDL, e.g. "create table". The keyspace is found in the
+// qualified table name or "USE".
+func describeStatement(query string) (string, string) {
+	words := strings.Fields(query)
+	if len(words) == 0 {
+		return "", ""
+	}
+	var table string
+	statement := strings.ToLower(words[0])
+	switch statement {
+	case "select", "delete":
+		table = wordAfter(words, "from")
+	case "insert":
+		table = wordAfter(words, "into")
+	case "update":
+		table = wordAfter(words, "update")
+	case "truncate":
+		if table = wordAfter(words, "table"); table == "" {
+			table = wordAfter(words, "truncate")
+		}
+	case "create", "drop", "alter":
+		if len(words) < 2 {
+			return statement, ""
+		}
+		return statement + " " + strings.ToLower(words[1]), ""
+	case "use":
+		return statement, unquote(wordAfter(words, "use"))
+	case "begin":
+		return "batch", ""
+	default:
+		return "", ""
+	}
+	if table == "" {
+		return statement, ""
+	}
+	var keyspace string
+	if dot := strings.IndexByte(table, '.'); dot > 0 {
+		keyspace = unquote(table[:dot])
+	}
+	return statement + " " + table, keyspace
+}
+
+// wordAfter returns the identifier after the keyword, whose trailing punctuations like "(" are removed.
+// The unquoted identifier is lower-cased as it is case-insensitive.
+func wordAfter(words []string, keyword string) string {
+	for i := 0; i+1 < len(words); i++ {
+		if !strings.EqualFold(words[i], keyword) {
+			continue
+		}
+		word := words[i+1]
+		if end := strings.IndexAny(word, "(;,"); end >= 0 {
+			word = word[:end]
+		}
+		if !strings.Contains(word, `"`) {
+			word = strings.ToLower(word)
+		}
+		return word
+	}
+	return ""
+}
+
+func unquote(identifier string) string {
+	if len(identifier) >= 2 && identifier[0] == '"' && identifier[len(identifier)-1] == '"' {
+		return identifier[1 : len(identifier)-1]
+	}
+	return identifier
+}
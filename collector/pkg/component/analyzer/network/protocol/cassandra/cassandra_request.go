@@ -0,0 +1,146 @@
+package cassandra
+
+import (
+	"strconv"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+// The flags of the query parameters. The flags are [byte] in v4 and [int] in v5.
+const (
+	queryFlagValues            = 0x01
+	queryFlagPageSize          = 0x04
+	queryFlagPagingState       = 0x08
+	queryFlagSerialConsistency = 0x10
+	queryFlagTimestamp         = 0x20
+	queryFlagNamesForValues    = 0x40
+	queryFlagKeyspace          = 0x80
+)
+
+// The flag of PREPARE in v5 set if the keyspace follows.
+const prepareFlagKeyspace = 0x01
+
+func fastfailCassandraRequest() protocol.FastFailFn {
+	return func(message *protocol.PayloadMessage) bool {
+		return len(message.Data) < frameHeaderLength
+	}
+}
+
+func parseCassandraRequest() protocol.ParsePkgFn {
+	return func(message *protocol.PayloadMessage) (bool, bool) {
+		f, ok := readFrame(message.Data)
+		if !ok || f.response {
+			return false, true
+		}
+
+		opcode := requestOpcodes[f.opcode]
+		message.AddIntAttribute(constlabels.CassandraStreamId, int64(f.stream))
+		message.AddStringAttribute(constlabels.CassandraOpcode, opcode)
+		message.AddStringAttribute(constlabels.ProtocolVersion, strconv.Itoa(int(f.version)))
+		var query, keyspace string
+		// The compressed body is not parsed.
+		if f.flags&flagCompression == 0 {
+			query, keyspace = readStatement(f)
+		}
+		contentKey := opcode
+		if query != "" {
+			query = normalizeQuery(query)
+			message.AddUtf8StringAttribute(constlabels.CassandraQuery, query)
+			if key, queryKeyspace := describeStatement(query); key != "" {
+				contentKey = key
+				if keyspace == "" {
+					keyspace = queryKeyspace
+				}
+			}
+		}
+		if keyspace != "" {
+			message.AddUtf8StringAttribute(constlabels.CassandraKeyspace, keyspace)
+		}
+		message.AddUtf8StringAttribute(constlabels.ContentKey, contentKey)
+		return true, true
+	}
+}
+
+// readStatement reads the query of QUERY and PREPARE, and the keyspace set by the flag of v5.
+// The query may be truncated.
+func readStatement(f *frame) (string, string) {
+	r := &cqlReader{data: f.body}
+	if f.flags&flagCustomPayload != 0 {
+		if r.skipBytesMap() != nil {
+			return "", ""
+		}
+	}
+	switch f.opcode {
+	case opQuery:
+		query, err := r.readLongString()
+		if err != nil {
+			return query, ""
+		}
+		return query, readKeyspace(r, f.version)
+	case opPrepare:
+		query, err := r.readLongString()
+		if err != nil || f.version < version5 {
+			return query, ""
+		}
+		flags, err := r.readInt()
+		if err != nil || flags&prepareFlagKeyspace == 0 {
+			return query, ""
+		}
+		keyspace, _ := r.readString()
+		return query, keyspace
+	case opExecute:
+		// The id of the prepared statement, and the id of the result metadata in v5.
+		if r.skipShortBytes() != nil {
+			return "", ""
+		}
+		if f.version >= version5 && r.skipShortBytes() != nil {
+			return "", ""
+		}
+		return "", readKeyspace(r, f.version)
+	}
+	return "", ""
+}
+
+// readKeyspace reads the query parameters until the keyspace, which is only sent in v5.
+func readKeyspace(r *cqlReader, version uint8) string {
+	if version < version5 {
+		return ""
+	}
+	// consistency
+	if r.skip(2) != nil {
+		return ""
+	}
+	flags, err := r.readInt()
+	if err != nil || flags&queryFlagKeyspace == 0 {
+		return ""
+	}
+	if flags&queryFlagValues != 0 {
+		n, err := r.readShort()
+		if err != nil {
+			return ""
+		}
+		for i := 0; i < int(n); i++ {
+			if flags&queryFlagNamesForValues != 0 && r.skipShortBytes() != nil {
+				return ""
+			}
+			if r.skipBytes() != nil {
+				return ""
+			}
+		}
+	}
+	if flags&queryFlagPageSize != 0 && r.skip(4) != nil {
+		return ""
+	}
+	if flags&queryFlagPagingState != 0 && r.skipBytes() != nil {
+		return ""
+	}
+	if flags&queryFlagSerialConsistency != 0 && r.skip(2) != nil {
+		return ""
+	}
+	if flags&queryFlagTimestamp != 0 && r.skip(8) != nil {
+		return ""
+	}
+	keyspace, _ := r.readString()
+	return keyspace
+}
@@ -0,0 +1,63 @@
+package cassandra
+
+import (
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+// tracingIdLength is the length of the [uuid] of the tracing session.
+const tracingIdLength = 16
+
+func fastfailCassandraResponse() protocol.FastFailFn {
+	return func(message *protocol.PayloadMessage) bool {
+		return len(message.Data) < frameHeaderLength
+	}
+}
+
+func parseCassandraResponse() protocol.ParsePkgFn {
+	return func(message *protocol.PayloadMessage) (bool, bool) {
+		f, ok := readFrame(message.Data)
+		// The events are pushed by the servers on the stream -1 and not the responses of any request.
+		if !ok || !f.response || f.opcode == opEvent {
+			return false, true
+		}
+
+		message.AddIntAttribute(constlabels.CassandraStreamId, int64(f.stream))
+		if f.opcode != opError {
+			return true, true
+		}
+		// The error code 0 is the server error, so the code is added only for the errors.
+		code, errMsg := readError(f)
+		message.AddIntAttribute(constlabels.CassandraErrCode, int64(code))
+		if errMsg != "" {
+			message.AddUtf8StringAttribute(constlabels.CassandraErrMsg, errMsg)
+		}
+		message.AddBoolAttribute(constlabels.IsError, true)
+		message.AddIntAttribute(constlabels.ErrorType, int64(constlabels.ProtocolError))
+		return true, true
+	}
+}
+
+// readError reads the code and the message of ERROR, e.g. 0x2200 Invalid and 0x1200 Read_timeout.
+// The tracing id, the warnings and the custom payload precede the body if their flags are set.
+func readError(f *frame) (int32, string) {
+	if f.flags&flagCompression != 0 {
+		return 0, ""
+	}
+	r := &cqlReader{data: f.body}
+	if f.flags&flagTracing != 0 && r.skip(tracingIdLength) != nil {
+		return 0, ""
+	}
+	if f.flags&flagWarning != 0 && r.skipStringList() != nil {
+		return 0, ""
+	}
+	if f.flags&flagCustomPayload != 0 && r.skipBytesMap() != nil {
+		return 0, ""
+	}
+	code, err := r.readInt()
+	if err != nil {
+		return 0, ""
+	}
+	errMsg, _ := r.readString()
+	return code, errMsg
+}
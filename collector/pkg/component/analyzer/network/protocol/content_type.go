@@ -0,0 +1,45 @@
+package protocol
+
+import "strings"
+
+// The normalized formats of the payloads, which are the values of the label "content_type".
+const (
+	ContentTypeJson     = "json"
+	ContentTypeProtobuf = "protobuf"
+	ContentTypeAvro     = "avro"
+	ContentTypeForm     = "form"
+	ContentTypeXml      = "xml"
+	ContentTypeText     = "text"
+	ContentTypeOther    = "other"
+)
+
+// NormalizeContentType maps the media type of the payload to its serialization format, e.g.
+// "application/vnd.api+json; charset=utf-8" to "json", so the label has a small set of values.
+// The empty string is returned if the value is empty.
+func NormalizeContentType(value string) string {
+	// The media types are case-insensitive and the parameters are ignored. The values of the Kafka
+	// headers serialized by Spring Cloud Stream are quoted.
+	mediaType := strings.ToLower(strings.Trim(strings.TrimSpace(value), "\""))
+	if index := strings.IndexByte(mediaType, ';'); index != -1 {
+		mediaType = strings.TrimSpace(mediaType[:index])
+	}
+	switch {
+	case mediaType == "":
+		return ""
+	// Avro is checked before JSON for the ones like "application/vnd.kafka.avro.v2+json".
+	case strings.Contains(mediaType, "avro"):
+		return ContentTypeAvro
+	// The messages of gRPC are encoded by protobuf unless the subtype says otherwise, e.g. "application/grpc+json".
+	case strings.Contains(mediaType, "protobuf"), mediaType == "application/grpc", mediaType == "application/grpc+proto":
+		return ContentTypeProtobuf
+	case strings.Contains(mediaType, "json"):
+		return ContentTypeJson
+	case mediaType == "application/x-www-form-urlencoded", mediaType == "multipart/form-data":
+		return ContentTypeForm
+	case strings.Contains(mediaType, "xml"):
+		return ContentTypeXml
+	case strings.HasPrefix(mediaType, "text/"):
+		return ContentTypeText
+	}
+	return ContentTypeOther
+}
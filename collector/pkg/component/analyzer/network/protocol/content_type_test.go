@@ -0,0 +1,37 @@
+package protocol
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeContentType(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "", want: ""},
+		{value: "application/json", want: ContentTypeJson},
+		{value: "Application/JSON; charset=UTF-8", want: ContentTypeJson},
+		{value: "application/vnd.api+json", want: ContentTypeJson},
+		{value: "\"application/json\"", want: ContentTypeJson},
+		{value: "application/grpc+json", want: ContentTypeJson},
+		{value: "application/x-protobuf", want: ContentTypeProtobuf},
+		{value: "application/vnd.google.protobuf;proto=foo.Bar", want: ContentTypeProtobuf},
+		{value: "application/grpc", want: ContentTypeProtobuf},
+		{value: "avro/binary", want: ContentTypeAvro},
+		{value: "application/vnd.kafka.avro.v2+json", want: ContentTypeAvro},
+		{value: "application/x-www-form-urlencoded", want: ContentTypeForm},
+		{value: "multipart/form-data; boundary=abc", want: ContentTypeForm},
+		{value: "text/xml", want: ContentTypeXml},
+		{value: "application/soap+xml", want: ContentTypeXml},
+		{value: "text/plain; charset=utf-8", want: ContentTypeText},
+		{value: "application/octet-stream", want: ContentTypeOther},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			assert.Equal(t, tt.want, NormalizeContentType(tt.value))
+		})
+	}
+}
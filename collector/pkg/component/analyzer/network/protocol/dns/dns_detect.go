@@ -0,0 +1,38 @@
+package dns
+
+import "encoding/binary"
+
+const (
+	classINET = 1
+	classANY  = 255
+)
+
+// IsDnsMessage returns true if the UDP payload looks like a standard DNS query or response. It is used to
+// recognize the resolvers listening on non-standard ports, so it is strict to avoid false positives:
+// the message must carry exactly one valid question of the class IN or ANY.
+func IsDnsMessage(data []byte, response bool) bool {
+	if len(data) <= DNSHeaderSize {
+		return false
+	}
+	flags := binary.BigEndian.Uint16(data[2:4])
+	if (flags>>15 == 1) != response {
+		return false
+	}
+	// Only the standard query is supported.
+	if (flags>>11)&0xf != 0 {
+		return false
+	}
+	if binary.BigEndian.Uint16(data[4:6]) != 1 {
+		return false
+	}
+	// The queries carry no answers and authorities.
+	if !response && (binary.BigEndian.Uint16(data[6:8]) != 0 || binary.BigEndian.Uint16(data[8:10]) != 0) {
+		return false
+	}
+	_, offset, err := unpackDomainName(data, DNSHeaderSize)
+	if err != nil || offset+4 > len(data) {
+		return false
+	}
+	class := binary.BigEndian.Uint16(data[offset+2 : offset+4])
+	return class == classINET || class == classANY
+}
@@ -0,0 +1,150 @@
+package dns
+
+import (
+	"encoding/binary"
+	"net"
+	"strconv"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+const (
+	TypeOPT uint16 = 41
+
+	ednsOptionClientSubnet uint16 = 8
+
+	ecsFamilyIpV4 uint16 = 1
+	ecsFamilyIpV6 uint16 = 2
+)
+
+// dnsEdns holds the EDNS0 OPT record of the additional section. See https://www.rfc-editor.org/rfc/rfc6891.
+type dnsEdns struct {
+	udpSize       uint16
+	extendedRcode uint8
+	do            bool
+	// clientSubnet is the EDNS Client Subnet option as "address/prefix", see https://www.rfc-editor.org/rfc/rfc7871.
+	clientSubnet string
+}
+
+// skipRecords returns the offset after the resource records starting at offset.
+func skipRecords(msg []byte, offset int, count int) (int, error) {
+	var err error
+	for i := 0; i < count; i++ {
+		_, offset, err = unpackDomainName(msg, offset)
+		if err != nil {
+			return offset, err
+		}
+		if offset+10 > len(msg) {
+			return offset, ErrBuf
+		}
+		offset += 10 + int(binary.BigEndian.Uint16(msg[offset+8:]))
+	}
+	return offset, nil
+}
+
+// readEdns returns the OPT record of the additional section starting at offset, or nil if there is none.
+func readEdns(msg []byte, offset int, additionalCount uint16) *dnsEdns {
+	var err error
+	for i := 0; i < int(additionalCount); i++ {
+		/*
+			string name
+			uint16 type
+			uint16 class     // UDP payload size for OPT
+			uint32 ttl       // extended RCODE, version, DO and Z for OPT
+			uint16 rdlength
+			string rdata
+		*/
+		_, offset, err = unpackDomainName(msg, offset)
+		if err != nil || offset+10 > len(msg) {
+			return nil
+		}
+		aType := binary.BigEndian.Uint16(msg[offset:])
+		length := int(binary.BigEndian.Uint16(msg[offset+8:]))
+		if aType != TypeOPT {
+			offset += 10 + length
+			continue
+		}
+		edns := &dnsEdns{
+			udpSize:       binary.BigEndian.Uint16(msg[offset+2:]),
+			extendedRcode: msg[offset+4],
+			do:            msg[offset+6]&0x80 != 0,
+		}
+		offset += 10
+		end := offset + length
+		if end > len(msg) {
+			end = len(msg)
+		}
+		/*
+			uint16 option-code
+			uint16 option-length
+			string option-data
+		*/
+		for offset+4 <= end {
+			code := binary.BigEndian.Uint16(msg[offset:])
+			optionLength := int(binary.BigEndian.Uint16(msg[offset+2:]))
+			offset += 4
+			if offset+optionLength > end {
+				break
+			}
+			if code == ednsOptionClientSubnet {
+				edns.clientSubnet = readClientSubnet(msg[offset : offset+optionLength])
+			}
+			offset += optionLength
+		}
+		return edns
+	}
+	return nil
+}
+
+/*
+readClientSubnet formats the option data of the EDNS Client Subnet.
+
+	uint16 family
+	uint8  source prefix-length
+	uint8  scope prefix-length
+	string address  // truncated to the source prefix-length
+*/
+func readClientSubnet(data []byte) string {
+	if len(data) < 4 {
+		return ""
+	}
+	family := binary.BigEndian.Uint16(data)
+	sourcePrefix := int(data[2])
+	var ip net.IP
+	switch family {
+	case ecsFamilyIpV4:
+		ip = make(net.IP, net.IPv4len)
+	case ecsFamilyIpV6:
+		ip = make(net.IP, net.IPv6len)
+	default:
+		return ""
+	}
+	if len(data)-4 > len(ip) {
+		return ""
+	}
+	copy(ip, data[4:])
+	return ip.String() + "/" + strconv.Itoa(sourcePrefix)
+}
+
+// readMessageEdns skips the records before the additional section and reads the OPT record.
+// The message starts at base and message.Offset is at the first record to skip.
+func readMessageEdns(message *protocol.PayloadMessage, base int, skipCount int, additionalCount uint16) *dnsEdns {
+	if additionalCount == 0 {
+		return nil
+	}
+	msg := message.Data[base:]
+	offset, err := skipRecords(msg, message.Offset-base, skipCount)
+	if err != nil {
+		return nil
+	}
+	return readEdns(msg, offset, additionalCount)
+}
+
+func addEdnsAttributes(message *protocol.PayloadMessage, edns *dnsEdns) {
+	message.AddIntAttribute(constlabels.DnsEdnsUdpSize, int64(edns.udpSize))
+	message.AddBoolAttribute(constlabels.DnsEdnsDo, edns.do)
+	if edns.clientSubnet != "" {
+		message.AddStringAttribute(constlabels.DnsEdnsClientSubnet, edns.clientSubnet)
+	}
+}
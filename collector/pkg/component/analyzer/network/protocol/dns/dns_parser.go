@@ -1,6 +1,9 @@
 package dns
 
 import (
+	"encoding/binary"
+	"strings"
+
 	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
 	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
 )
@@ -33,7 +36,9 @@ func NewTcpDnsParser(ignoreDnsRcode3Error bool) *protocol.ProtocolParser {
 	requestParser := protocol.CreatePkgParser(fastfailDnsRequest(), parseTcpDnsRequest())
 	responseParser := protocol.CreatePkgParser(fastfailDnsResponse(), parseTcpDnsResponse(ignoreDnsRcode3Error))
 
-	return protocol.NewProtocolParser(protocol.DNS, requestParser, responseParser, dnsPair())
+	parser := protocol.NewProtocolParser(protocol.DNS, requestParser, responseParser, dnsPair())
+	parser.SetSplitter(splitTcpDnsMessages)
+	return parser
 }
 
 func NewUdpDnsParser(ignoreDnsRcode3Error bool) *protocol.ProtocolParser {
@@ -43,6 +48,31 @@ func NewUdpDnsParser(ignoreDnsRcode3Error bool) *protocol.ProtocolParser {
 	return protocol.NewProtocolParser(protocol.DNS, requestParser, responseParser, nil)
 }
 
+// splitTcpDnsMessages splits the payload by the 2-byte length prefixed to each message over TCP
+// (RFC 1035 section 4.2.2), as the clients may pipeline multiple queries in one write. Each message
+// keeps its length prefix. The last message is kept even if it is truncated.
+func splitTcpDnsMessages(data []byte) [][]byte {
+	messages := make([][]byte, 0, 1)
+	offset := 0
+	for offset+2 <= len(data) {
+		length := int(binary.BigEndian.Uint16(data[offset:]))
+		if length == 0 {
+			break
+		}
+		end := offset + 2 + length
+		if end >= len(data) {
+			messages = append(messages, data[offset:])
+			return messages
+		}
+		messages = append(messages, data[offset:end])
+		offset = end
+	}
+	if len(messages) == 0 {
+		return [][]byte{data}
+	}
+	return messages
+}
+
 func dnsPair() protocol.PairMatch {
 	return func(requests []*protocol.PayloadMessage, response *protocol.PayloadMessage) int {
 		for i, request := range requests {
@@ -81,3 +111,12 @@ func readQuery(message *protocol.PayloadMessage, queryCount uint16) (domain stri
 	message.Offset = offset
 	return domain, nil
 }
+
+// addServiceDiscoveryAttributes labels the queries of the services registered in Consul, which are
+// resolved by its DNS interface under the domain "consul".
+func addServiceDiscoveryAttributes(message *protocol.PayloadMessage, domain string) {
+	if strings.HasSuffix(domain, ".consul.") {
+		message.AddStringAttribute(constlabels.ServiceDiscovery, "consul")
+		message.AddStringAttribute(constlabels.ServiceDiscoveryOp, "dns_query")
+	}
+}
@@ -57,5 +57,13 @@ func parseDnsRequest(message *protocol.PayloadMessage) (bool, bool) {
 	}
 	message.AddIntAttribute(constlabels.DnsId, int64(id))
 	message.AddStringAttribute(constlabels.DnsDomain, domain)
+	addServiceDiscoveryAttributes(message, domain)
+
+	numOfAnswers, _ := message.ReadUInt16(offset + 6)
+	numOfAuthorities, _ := message.ReadUInt16(offset + 8)
+	numOfAdditionals, _ := message.ReadUInt16(offset + 10)
+	if edns := readMessageEdns(message, offset, int(numOfAnswers)+int(numOfAuthorities), numOfAdditionals); edns != nil {
+		addEdnsAttributes(message, edns)
+	}
 	return true, true
 }
@@ -1,7 +1,9 @@
 package dns
 
 import (
+	"encoding/binary"
 	"net"
+	"strconv"
 	"strings"
 
 	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
@@ -9,8 +11,11 @@ import (
 )
 
 const (
-	TypeA    uint16 = 1
-	TypeAAAA uint16 = 28
+	TypeA     uint16 = 1
+	TypeCNAME uint16 = 5
+	TypeTXT   uint16 = 16
+	TypeAAAA  uint16 = 28
+	TypeSRV   uint16 = 33
 )
 
 func fastfailDnsResponse() protocol.FastFailFn {
@@ -68,11 +73,30 @@ func parseDnsResponse(message *protocol.PayloadMessage, ignoreDnsRcode3Error boo
 		return false, true
 	}
 
-	ip := readIpV4Answer(message, numOfAnswers)
+	answers := readAnswers(message, offset, numOfAnswers)
+	if !answers.truncated {
+		numOfAuthorities, _ := message.ReadUInt16(offset + 8)
+		numOfAdditionals, _ := message.ReadUInt16(offset + 10)
+		if edns := readMessageEdns(message, offset, int(numOfAuthorities), numOfAdditionals); edns != nil {
+			addEdnsAttributes(message, edns)
+			// The upper 8 bits of the 12-bit RCODE are in the OPT record, e.g. BADVERS is 16.
+			rcode |= uint16(edns.extendedRcode) << 4
+		}
+	}
 
 	message.AddStringAttribute(constlabels.DnsDomain, domain)
-	if len(ip) > 0 {
-		message.AddStringAttribute(constlabels.DnsIp, ip)
+	addServiceDiscoveryAttributes(message, domain)
+	if len(answers.ips) > 0 {
+		message.AddStringAttribute(constlabels.DnsIp, strings.Join(answers.ips, ","))
+	}
+	if len(answers.cnames) > 0 {
+		message.AddStringAttribute(constlabels.DnsCname, strings.Join(answers.cnames, ","))
+	}
+	if len(answers.srvs) > 0 {
+		message.AddStringAttribute(constlabels.DnsSrv, strings.Join(answers.srvs, ","))
+	}
+	if len(answers.txts) > 0 {
+		message.AddStringAttribute(constlabels.DnsTxt, strings.Join(answers.txts, ","))
 	}
 	message.AddIntAttribute(constlabels.DnsId, int64(id))
 	message.AddIntAttribute(constlabels.DnsRcode, int64(rcode))
@@ -83,52 +107,104 @@ func parseDnsResponse(message *protocol.PayloadMessage, ignoreDnsRcode3Error boo
 	return true, true
 }
 
-func readIpV4Answer(message *protocol.PayloadMessage, answerCount uint16) string {
+// dnsAnswers holds the decoded records of the answer section.
+type dnsAnswers struct {
+	ips    []string
+	cnames []string
+	srvs   []string
+	txts   []string
+	// truncated is true if the decoding stopped before the end of the answer section.
+	truncated bool
+}
+
+// readAnswers decodes the A, AAAA, CNAME, SRV and TXT records of the answer section. The other types are
+// skipped. The decoding stops at the first truncated record, so the records before it are kept.
+// The names are compressed relative to the start of the message, which is at base.
+func readAnswers(message *protocol.PayloadMessage, base int, answerCount uint16) *dnsAnswers {
 	var (
 		aType  uint16
 		length uint16
-		ip     net.IP
-		ips    []string
+		data   []byte
+		name   string
 		err    error
 	)
 
-	ips = make([]string, 0)
-	offset := message.Offset
+	answers := &dnsAnswers{}
+	msg := message.Data[base:]
+	offset := message.Offset - base
 	for i := 0; i < int(answerCount); i++ {
 		/*
-			uint16 name
+			string name
 			uint16 type
 			uint16 class
 			uint32 ttl
 			uint16 rdlength
 			string rdata
 		*/
-		offset += 2
-		aType, err = message.ReadUInt16(offset)
+		_, offset, err = unpackDomainName(msg, offset)
 		if err != nil {
+			answers.truncated = true
 			break
 		}
-
-		offset += 8
-		length, err = message.ReadUInt16(offset)
+		aType, err = message.ReadUInt16(base + offset)
+		if err != nil {
+			answers.truncated = true
+			break
+		}
+		length, err = message.ReadUInt16(base + offset + 8)
 		if err != nil {
+			answers.truncated = true
 			break
 		}
+		offset += 10
+		if offset+int(length) > len(msg) {
+			answers.truncated = true
+			break
+		}
+		data = msg[offset : offset+int(length)]
 
-		offset += 2
-		if aType == TypeA {
-			offset, ip, err = message.ReadBytes(offset, int(length))
-			if err != nil {
-				break
+		switch aType {
+		case TypeA, TypeAAAA:
+			if len(data) == net.IPv4len || len(data) == net.IPv6len {
+				answers.ips = append(answers.ips, net.IP(data).String())
+			}
+		case TypeCNAME:
+			if name, _, err = unpackDomainName(msg, offset); err == nil {
+				answers.cnames = append(answers.cnames, name)
 			}
-			ips = append(ips, ip.String())
+		case TypeSRV:
+			/*
+				uint16 priority
+				uint16 weight
+				uint16 port
+				string target
+			*/
+			if len(data) > 6 {
+				port := binary.BigEndian.Uint16(data[4:6])
+				if name, _, err = unpackDomainName(msg, offset+6); err == nil {
+					answers.srvs = append(answers.srvs, name+":"+strconv.Itoa(int(port)))
+				}
+			}
+		case TypeTXT:
+			answers.txts = append(answers.txts, readTxt(data))
 		}
 		offset += int(length)
 	}
-	message.Offset = offset
-	if len(ips) == 0 {
-		return ""
-	}
+	message.Offset = base + offset
+	return answers
+}
 
-	return strings.Join(ips, ",")
+// readTxt concatenates the character-strings of the TXT record.
+func readTxt(data []byte) string {
+	var txt strings.Builder
+	for offset := 0; offset < len(data); {
+		length := int(data[offset])
+		offset++
+		if offset+length > len(data) {
+			length = len(data) - offset
+		}
+		txt.Write(data[offset : offset+length])
+		offset += length
+	}
+	return txt.String()
 }
@@ -0,0 +1,99 @@
+package dubbo
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/hessian"
+)
+
+var primitiveTypes = map[byte]string{
+	'B': "byte",
+	'C': "char",
+	'D': "double",
+	'F': "float",
+	'I': "int",
+	'J': "long",
+	'S': "short",
+	'Z': "boolean",
+	'V': "void",
+}
+
+// getArguments returns the Java types of the arguments and the sizes they are serialized in. The body of
+// a request consists of the dubbo version, the service, the service version, the method, the descriptor
+// of the parameter types, the arguments and the attachments. The size of an argument is -1 if it is
+// truncated. It returns false if the request is not serialized in Hessian2 or the descriptor is truncated.
+func getArguments(requestData []byte) (types []string, sizes []int, ok bool) {
+	if len(requestData) < 16 || requestData[2]&SerialMask != SerialHessian2 ||
+		requestData[2]&FlagRequest == Zero || requestData[2]&FlagEvent != Zero {
+		return nil, nil, false
+	}
+	decoder := hessian.NewDecoder(requestData, 16)
+	var descriptor string
+	for i := 0; i < 5; i++ {
+		value, err := decoder.ReadString()
+		if err != nil {
+			return nil, nil, false
+		}
+		descriptor = value
+	}
+	types = parseParameterTypes(descriptor)
+	sizes = make([]int, len(types))
+	truncated := false
+	for i := range types {
+		sizes[i] = -1
+		if truncated {
+			continue
+		}
+		value, err := decoder.Next()
+		if err != nil {
+			truncated = true
+			continue
+		}
+		sizes[i] = value.Size
+	}
+	return types, sizes, true
+}
+
+// parseParameterTypes converts the descriptor of the parameter types in JVM like "Ljava/lang/String;[I"
+// into the Java types like ["java.lang.String", "int[]"]. The invalid descriptors are ignored.
+func parseParameterTypes(descriptor string) []string {
+	types := make([]string, 0)
+	for i := 0; i < len(descriptor); {
+		dimensions := 0
+		for i < len(descriptor) && descriptor[i] == '[' {
+			dimensions++
+			i++
+		}
+		if i >= len(descriptor) {
+			break
+		}
+		var typ string
+		if descriptor[i] == 'L' {
+			end := strings.IndexByte(descriptor[i:], ';')
+			if end == -1 {
+				break
+			}
+			typ = strings.ReplaceAll(descriptor[i+1:i+end], "/", ".")
+			i += end + 1
+		} else if primitive, ok := primitiveTypes[descriptor[i]]; ok {
+			typ = primitive
+			i++
+		} else {
+			break
+		}
+		types = append(types, typ+strings.Repeat("[]", dimensions))
+	}
+	return types
+}
+
+func joinSizes(sizes []int) string {
+	var builder strings.Builder
+	for i, size := range sizes {
+		if i > 0 {
+			builder.WriteByte(',')
+		}
+		builder.WriteString(strconv.Itoa(size))
+	}
+	return builder.String()
+}
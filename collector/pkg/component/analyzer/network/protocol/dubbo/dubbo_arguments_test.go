@@ -0,0 +1,60 @@
+package dubbo
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func hessianString(s string) []byte {
+	if len(s) < 32 {
+		return append([]byte{byte(len(s))}, s...)
+	}
+	return append([]byte{0x30, byte(len(s))}, s...)
+}
+
+func newHessianRequest(descriptor string, arguments ...string) []byte {
+	data, _ := hex.DecodeString("dabbc20000000000000001d200000000")
+	for _, s := range []string{"2.0.2", "io.kindling.OrderService", "0.0.0", "order", descriptor} {
+		data = append(data, hessianString(s)...)
+	}
+	for _, argument := range arguments {
+		value, _ := hex.DecodeString(argument)
+		data = append(data, value...)
+	}
+	return data
+}
+
+func TestGetArguments(t *testing.T) {
+	// "order-1", 300, and an object of io.kindling.Order { id }
+	data := newHessianRequest("Ljava/lang/String;I[JLio/kindling/Order;",
+		"076f726465722d31", "c92c", "7991", "4311696f2e6b696e646c696e672e4f7264657291026964609a")
+	data = append(data, 'H', 'Z')
+	types, sizes, ok := getArguments(data)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"java.lang.String", "int", "long[]", "io.kindling.Order"}, types)
+	assert.Equal(t, []int{8, 2, 2, 25}, sizes)
+	assert.Equal(t, "8,2,2,25", joinSizes(sizes))
+
+	// The object is truncated.
+	types, sizes, ok = getArguments(data[:len(data)-5])
+	assert.True(t, ok)
+	assert.Len(t, types, 4)
+	assert.Equal(t, []int{8, 2, 2, -1}, sizes)
+
+	// The descriptor is truncated.
+	_, _, ok = getArguments(data[:90])
+	assert.False(t, ok)
+	// Not serialized in Hessian2
+	data[2] = 0xc6
+	_, _, ok = getArguments(data)
+	assert.False(t, ok)
+}
+
+func TestParseParameterTypes(t *testing.T) {
+	assert.Equal(t, []string{}, parseParameterTypes(""))
+	assert.Equal(t, []string{"boolean", "java.lang.Object[][]", "double"}, parseParameterTypes("Z[[Ljava/lang/Object;D"))
+	// The types before the invalid part are kept.
+	assert.Equal(t, []string{"int"}, parseParameterTypes("ILjava/lang/String"))
+}
@@ -1,6 +1,8 @@
 package dubbo
 
 import (
+	"strings"
+
 	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
 	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
 )
@@ -19,6 +21,13 @@ func parseDubboRequest() protocol.ParsePkgFn {
 		}
 
 		message.AddStringAttribute(constlabels.ContentKey, contentKey)
+		if version := getVersion(message.Data); version != "" {
+			message.AddStringAttribute(constlabels.ProtocolVersion, version)
+		}
+		if types, sizes, ok := getArguments(message.Data); ok {
+			message.AddStringAttribute(constlabels.DubboArgumentTypes, strings.Join(types, ","))
+			message.AddStringAttribute(constlabels.DubboArgumentSizes, joinSizes(sizes))
+		}
 		return true, true
 	}
 }
@@ -64,3 +73,30 @@ func getContentKey(requestData []byte) string {
 
 	return service + "#" + method
 }
+
+// maxVersionLength limits the length of the version, e.g. "2.0.2", which is dropped if longer.
+const maxVersionLength = 16
+
+// getVersion returns the version of the Dubbo protocol, which is the first field of the body of the
+// two-way requests. Only the digits and the dots are accepted.
+func getVersion(requestData []byte) string {
+	serialID := requestData[2] & SerialMask
+	if serialID == Zero || (requestData[2]&FlagEvent) != Zero ||
+		(requestData[2]&FlagRequest) == Zero || (requestData[2]&FlagTwoWay) == Zero {
+		return ""
+	}
+	serializer := GetSerializer(serialID)
+	if serializer == serialUnsupport {
+		return ""
+	}
+	_, version := serializer.getStringValue(requestData, 16)
+	if version == "" || len(version) > maxVersionLength {
+		return ""
+	}
+	for i := 0; i < len(version); i++ {
+		if (version[i] < '0' || version[i] > '9') && version[i] != '.' {
+			return ""
+		}
+	}
+	return version
+}
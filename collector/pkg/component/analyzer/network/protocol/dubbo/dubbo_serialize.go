@@ -142,6 +142,10 @@ func (json *dubboFastJson) getStringValue(data []byte, offset int) (int, string)
 	*/
 	for i := offset + 1; i < dataLength; i++ {
 		if data[i] == JsonNextLine {
+			if i-1 <= offset+1 {
+				// No quoted value before the line break.
+				return i + 1, ""
+			}
 			return i + 1, string(data[offset+1 : i-1])
 		}
 	}
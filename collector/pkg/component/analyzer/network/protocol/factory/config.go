@@ -1,13 +1,22 @@
 package factory
 
 type config struct {
-	urlClusteringMethod string
+	urlClusteringMethod  string
 	ignoreDnsRcode3Error bool
+	httpSessionCookie    string
+	httpMaskedHeaders    []string
+	httpSoapOperation    bool
+	httpElasticsearch    bool
+	httpInfluxdb         bool
+	httpGraphqlPaths     []string
+	maskMysqlLiterals    bool
+	maskRedisAuth        bool
+	maskLdapBind         bool
 }
 
 func newDefaultConfig() *config {
 	return &config{
-		urlClusteringMethod: "alphabet",
+		urlClusteringMethod:  "alphabet",
 		ignoreDnsRcode3Error: false,
 	}
 }
@@ -25,3 +34,65 @@ func WithIgnoreDnsRcode3Error(ignoreDnsRcode3Error bool) Option {
 		cfg.ignoreDnsRcode3Error = ignoreDnsRcode3Error
 	}
 }
+
+func WithHttpSessionCookie(httpSessionCookie string) Option {
+	return func(cfg *config) {
+		cfg.httpSessionCookie = httpSessionCookie
+	}
+}
+
+// WithHttpSoapOperation adds the operations of the SOAP and XML-RPC requests to the content key.
+func WithHttpSoapOperation(enabled bool) Option {
+	return func(cfg *config) {
+		cfg.httpSoapOperation = enabled
+	}
+}
+
+// WithHttpElasticsearch takes the operations and the indices of the Elasticsearch requests as the content key.
+func WithHttpElasticsearch(enabled bool) Option {
+	return func(cfg *config) {
+		cfg.httpElasticsearch = enabled
+	}
+}
+
+// WithHttpInfluxdb reads the measurements and the points of the InfluxDB writes.
+func WithHttpInfluxdb(enabled bool) Option {
+	return func(cfg *config) {
+		cfg.httpInfluxdb = enabled
+	}
+}
+
+// WithHttpGraphqlPaths reads the GraphQL operations of the requests sent to the paths.
+func WithHttpGraphqlPaths(paths []string) Option {
+	return func(cfg *config) {
+		cfg.httpGraphqlPaths = paths
+	}
+}
+
+// WithHttpMaskedHeaders masks the values of the HTTP request headers with the names.
+func WithHttpMaskedHeaders(headers []string) Option {
+	return func(cfg *config) {
+		cfg.httpMaskedHeaders = headers
+	}
+}
+
+// WithMysqlLiteralsMasked masks the literals of the MySQL statements.
+func WithMysqlLiteralsMasked(masked bool) Option {
+	return func(cfg *config) {
+		cfg.maskMysqlLiterals = masked
+	}
+}
+
+// WithRedisAuthMasked masks the arguments of the Redis AUTH command.
+func WithRedisAuthMasked(masked bool) Option {
+	return func(cfg *config) {
+		cfg.maskRedisAuth = masked
+	}
+}
+
+// WithLdapBindMasked masks the passwords of the LDAP simple binds.
+func WithLdapBindMasked(masked bool) Option {
+	return func(cfg *config) {
+		cfg.maskLdapBind = masked
+	}
+}
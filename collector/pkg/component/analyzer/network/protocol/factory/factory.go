@@ -4,15 +4,29 @@ import (
 	"sync"
 
 	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/rocketmq"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/tars"
 
 	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/bolt"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/brpc"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/cassandra"
 	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/dns"
 	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/dubbo"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/ftp"
 	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/generic"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/grpc"
 	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/http"
 	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/kafka"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/ldap"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/mongodb"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/mqtt"
 	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/mysql"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/oracle"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/postgresql"
 	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/redis"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/ssh"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/tls"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/websocket"
 )
 
 type ParserFactory struct {
@@ -33,13 +47,31 @@ func NewParserFactory(options ...Option) *ParserFactory {
 	for _, option := range options {
 		option(factory.config)
 	}
-	factory.protocolParsers[protocol.HTTP] = http.NewHttpParser(factory.config.urlClusteringMethod)
+	factory.protocolParsers[protocol.HTTP] = http.NewHttpParser(factory.config.urlClusteringMethod, factory.config.httpSessionCookie,
+		factory.config.httpMaskedHeaders, factory.config.httpSoapOperation, factory.config.httpElasticsearch,
+		factory.config.httpInfluxdb, factory.config.httpGraphqlPaths)
 	factory.protocolParsers[protocol.KAFKA] = kafka.NewKafkaParser()
-	factory.protocolParsers[protocol.MYSQL] = mysql.NewMysqlParser()
-	factory.protocolParsers[protocol.REDIS] = redis.NewRedisParser()
+	factory.protocolParsers[protocol.MYSQL] = mysql.NewMysqlParser(factory.config.maskMysqlLiterals)
+	factory.protocolParsers[protocol.REDIS] = redis.NewRedisParser(factory.config.maskRedisAuth)
 	factory.protocolParsers[protocol.DUBBO] = dubbo.NewDubboParser()
 	factory.protocolParsers[protocol.DNS] = dns.NewTcpDnsParser(factory.config.ignoreDnsRcode3Error)
 	factory.protocolParsers[protocol.ROCKETMQ] = rocketmq.NewRocketMQParser()
+	factory.protocolParsers[protocol.MONGODB] = mongodb.NewMongodbParser()
+	factory.protocolParsers[protocol.TARS] = tars.NewTarsParser()
+	factory.protocolParsers[protocol.GRPC] = grpc.NewGrpcParser()
+	factory.protocolParsers[protocol.HTTP2] = grpc.NewHttp2Parser(factory.config.urlClusteringMethod)
+	factory.protocolParsers[protocol.TRIPLE] = grpc.NewTripleParser()
+	factory.protocolParsers[protocol.BRPC] = brpc.NewBrpcParser()
+	factory.protocolParsers[protocol.BOLT] = bolt.NewBoltParser()
+	factory.protocolParsers[protocol.CASSANDRA] = cassandra.NewCassandraParser()
+	factory.protocolParsers[protocol.FTP] = ftp.NewFtpParser()
+	factory.protocolParsers[protocol.SSH] = ssh.NewSshParser()
+	factory.protocolParsers[protocol.MQTT] = mqtt.NewMqttParser()
+	factory.protocolParsers[protocol.LDAP] = ldap.NewLdapParser(factory.config.maskLdapBind)
+	factory.protocolParsers[protocol.ORACLE] = oracle.NewOracleParser()
+	factory.protocolParsers[protocol.POSTGRESQL] = postgresql.NewPostgresqlParser()
+	factory.protocolParsers[protocol.WEBSOCKET] = websocket.NewWebsocketParser()
+	factory.protocolParsers[protocol.TLS] = tls.NewTlsParser()
 	factory.protocolParsers[protocol.NOSUPPORT] = generic.NewGenericParser()
 
 	factory.udpDnsParser = dns.NewUdpDnsParser(factory.config.ignoreDnsRcode3Error)
@@ -108,3 +140,37 @@ func (f *ParserFactory) RemoveCachedParser(port uint32, parser *protocol.Protoco
 	}
 	f.mutex.Unlock()
 }
+
+// RemoveParserFromCache removes the parser from the parsers cached for all the ports.
+func (f *ParserFactory) RemoveParserFromCache(parser *protocol.ProtocolParser) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	for port, parsers := range f.cachePortParsersMap {
+		remaining := make([]*protocol.ProtocolParser, 0, len(parsers))
+		for _, value := range parsers {
+			if value != parser {
+				remaining = append(remaining, value)
+			}
+		}
+		if len(remaining) == 0 {
+			delete(f.cachePortParsersMap, port)
+		} else {
+			f.cachePortParsersMap[port] = remaining
+		}
+	}
+}
+
+// GetCachedProtocols returns the protocols cached for each port in the order they are tried.
+func (f *ParserFactory) GetCachedProtocols() map[uint32][]string {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	ret := make(map[uint32][]string, len(f.cachePortParsersMap))
+	for port, parsers := range f.cachePortParsersMap {
+		protocols := make([]string, 0, len(parsers))
+		for _, parser := range parsers {
+			protocols = append(protocols, parser.GetProtocol())
+		}
+		ret[port] = protocols
+	}
+	return ret
+}
@@ -0,0 +1,211 @@
+package factory_test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/factory"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/ntp"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/quic"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/sip"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/testbed"
+)
+
+// The payloads recorded under ../testdata are the seed corpus of the fuzz targets. The inputs
+// that once crashed the parsers are kept under testdata/fuzz and replayed by "go test".
+// Run a target with "go test -run=^$ -fuzz=FuzzHttp ./pkg/component/analyzer/network/protocol/factory".
+
+func FuzzHttp(f *testing.F) {
+	fuzzParser(f, protocol.HTTP, "http")
+}
+
+func FuzzMysql(f *testing.F) {
+	fuzzParser(f, protocol.MYSQL, "mysql")
+}
+
+func FuzzRedis(f *testing.F) {
+	fuzzParser(f, protocol.REDIS, "redis")
+}
+
+func FuzzKafka(f *testing.F) {
+	fuzzParser(f, protocol.KAFKA, "kafka")
+}
+
+func FuzzDubbo(f *testing.F) {
+	fuzzParser(f, protocol.DUBBO, "dubbo")
+}
+
+func FuzzRocketMQ(f *testing.F) {
+	fuzzParser(f, protocol.ROCKETMQ, "rocketmq")
+}
+
+func FuzzMongodb(f *testing.F) {
+	fuzzParser(f, protocol.MONGODB, "mongodb")
+}
+
+func FuzzTars(f *testing.F) {
+	fuzzParser(f, protocol.TARS, "tars")
+}
+
+func FuzzGrpc(f *testing.F) {
+	fuzzParser(f, protocol.GRPC, "grpc")
+}
+
+func FuzzBrpc(f *testing.F) {
+	fuzzParser(f, protocol.BRPC, "brpc")
+}
+
+func FuzzBolt(f *testing.F) {
+	fuzzParser(f, protocol.BOLT, "bolt")
+}
+
+func FuzzCassandra(f *testing.F) {
+	fuzzParser(f, protocol.CASSANDRA, "cassandra")
+}
+
+func FuzzFtp(f *testing.F) {
+	fuzzParser(f, protocol.FTP, "ftp")
+}
+
+func FuzzSsh(f *testing.F) {
+	fuzzParser(f, protocol.SSH, "ssh")
+}
+
+func FuzzMqtt(f *testing.F) {
+	fuzzParser(f, protocol.MQTT, "mqtt")
+}
+
+func FuzzLdap(f *testing.F) {
+	fuzzParser(f, protocol.LDAP, "ldap")
+}
+
+func FuzzOracle(f *testing.F) {
+	fuzzParser(f, protocol.ORACLE, "oracle")
+}
+
+func FuzzPostgresql(f *testing.F) {
+	fuzzParser(f, protocol.POSTGRESQL, "postgresql")
+}
+
+func FuzzWebsocket(f *testing.F) {
+	fuzzParser(f, protocol.WEBSOCKET, "websocket")
+}
+
+func FuzzHttp2(f *testing.F) {
+	// The h2c connections with prior knowledge start with the preface and the SETTINGS frame.
+	f.Add([]byte("PRI * HTTP/2.0\r\n\r\nSM\r\n\r\n\x00\x00\x00\x04\x00\x00\x00\x00\x00"))
+	fuzzParser(f, protocol.HTTP2, "http2")
+}
+
+func FuzzTls(f *testing.F) {
+	fuzzParser(f, protocol.TLS, "tls")
+}
+
+func FuzzTriple(f *testing.F) {
+	fuzzParser(f, protocol.TRIPLE, "triple")
+}
+
+func FuzzTcpDns(f *testing.F) {
+	fuzzParser(f, protocol.DNS, "dns")
+}
+
+func FuzzUdpDns(f *testing.F) {
+	addCorpus(f, "dns")
+	fuzzParse(f, factory.NewParserFactory().GetUdpDnsParser())
+}
+
+// FuzzQuic decrypts the Initial packets and parses the ClientHello reassembled from their CRYPTO frames.
+func FuzzQuic(f *testing.F) {
+	addCorpus(f, "quic")
+	f.Fuzz(func(t *testing.T, data []byte) {
+		packet, ok := quic.ParseInitialPacket(data)
+		if !ok {
+			return
+		}
+		var handshake quic.Handshake
+		handshake.Add(packet)
+		handshake.ClientHello()
+	})
+}
+
+func FuzzNtp(f *testing.F) {
+	addCorpus(f, "ntp")
+	f.Fuzz(func(t *testing.T, data []byte) {
+		packet, ok := ntp.ParsePacket(data)
+		if !ok {
+			return
+		}
+		ntp.ModeString(packet.Mode)
+		if packet.IsKissOfDeath() {
+			packet.KissCode()
+		}
+		ntp.ClockOffset(packet, 0, 0)
+	})
+}
+
+func FuzzSip(f *testing.F) {
+	addCorpus(f, "sip")
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if message, ok := sip.ParseMessage(data); ok {
+			message.IsFailure()
+		}
+	})
+}
+
+func FuzzGeneric(f *testing.F) {
+	fuzzParser(f, protocol.NOSUPPORT, "nosupport")
+}
+
+func fuzzParser(f *testing.F, protocolName string, corpusDir string) {
+	addCorpus(f, corpusDir)
+	fuzzParse(f, factory.NewParserFactory().GetParser(protocolName))
+}
+
+// fuzzParse feeds the input to the parser as a request, as the response of that request and as
+// a response without request. The parsers must not panic whatever the input is.
+func fuzzParse(f *testing.F, parser *protocol.ProtocolParser) {
+	f.Fuzz(func(t *testing.T, data []byte) {
+		request := protocol.NewRequestMessage(data)
+		if parser.ParseRequest(request) {
+			response := protocol.NewResponseMessage(data, request.GetAttributes())
+			if parser.ParseResponse(response) {
+				parser.PairMatch([]*protocol.PayloadMessage{request}, response)
+			}
+		}
+		parser.ParseResponse(protocol.NewResponseMessage(data, model.NewAttributeMap()))
+	})
+}
+
+// addCorpus adds the payloads of the recorded traces under ../testdata/<corpusDir> to the seed corpus.
+func addCorpus(f *testing.F, corpusDir string) {
+	dir := filepath.Join("..", "testdata", corpusDir)
+	paths, err := filepath.Glob(filepath.Join(dir, "*.yml"))
+	if err != nil {
+		f.Fatal(err)
+	}
+	eventPaths, err := filepath.Glob(filepath.Join(dir, "*-event*.yml"))
+	if err != nil || len(eventPaths) == 0 {
+		f.Fatalf("no event file under %s", dir)
+	}
+	for _, path := range paths {
+		// The 1k traces are only used by the benchmarks and repeat the payloads of the others.
+		if name := filepath.Base(path); strings.Contains(name, "-event") || strings.HasPrefix(name, "1k-") {
+			continue
+		}
+		// Only the payloads are used, so any event file works.
+		fixture, err := testbed.LoadFixture(eventPaths[0], path)
+		if err != nil {
+			f.Fatal(err)
+		}
+		events, err := fixture.Events()
+		if err != nil {
+			f.Fatal(err)
+		}
+		for _, event := range events {
+			f.Add(event.GetData())
+		}
+	}
+}
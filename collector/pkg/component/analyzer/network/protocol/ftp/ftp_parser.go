@@ -0,0 +1,32 @@
+package ftp
+
+import (
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+)
+
+// maxLineLength is the max length of the command lines, which are short in practice.
+const maxLineLength = 512
+
+// commands are the commands of the control channel, see RFC 959, RFC 2228, RFC 2428 and RFC 3659.
+var commands = map[string]bool{
+	"USER": true, "PASS": true, "ACCT": true, "CWD": true, "CDUP": true, "SMNT": true, "QUIT": true,
+	"REIN": true, "PORT": true, "PASV": true, "TYPE": true, "STRU": true, "MODE": true, "RETR": true,
+	"STOR": true, "STOU": true, "APPE": true, "ALLO": true, "REST": true, "RNFR": true, "RNTO": true,
+	"ABOR": true, "DELE": true, "RMD": true, "MKD": true, "PWD": true, "LIST": true, "NLST": true,
+	"SITE": true, "SYST": true, "STAT": true, "HELP": true, "NOOP": true, "FEAT": true, "OPTS": true,
+	"AUTH": true, "PBSZ": true, "PROT": true, "CCC": true, "EPSV": true, "EPRT": true, "MDTM": true,
+	"SIZE": true, "MLSD": true, "MLST": true, "LANG": true, "XCWD": true, "XMKD": true, "XPWD": true,
+	"XRMD": true,
+}
+
+// secretCommands are the commands whose arguments are the credentials.
+var secretCommands = map[string]bool{
+	"PASS": true,
+	"ACCT": true,
+}
+
+func NewFtpParser() *protocol.ProtocolParser {
+	requestParser := protocol.CreatePkgParser(fastfailFtpRequest(), parseFtpRequest())
+	responseParser := protocol.CreatePkgParser(fastfailFtpResponse(), parseFtpResponse())
+	return protocol.NewProtocolParser(protocol.FTP, requestParser, responseParser, nil)
+}
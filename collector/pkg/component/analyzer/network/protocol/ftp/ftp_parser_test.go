@@ -0,0 +1,89 @@
+package ftp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+func TestParseFtpRequest(t *testing.T) {
+	parser := NewFtpParser()
+
+	request := protocol.NewRequestMessage([]byte("retr /outbox/orders-20221011.csv\r\n"))
+	assert.True(t, parser.ParseRequest(request))
+	assert.Equal(t, "RETR", request.GetStringAttribute(constlabels.FtpCommand))
+	assert.Equal(t, "/outbox/orders-20221011.csv", request.GetStringAttribute(constlabels.FtpArgument))
+	assert.Equal(t, "RETR", request.GetStringAttribute(constlabels.ContentKey))
+
+	request = protocol.NewRequestMessage([]byte("PASS secret\r\n"))
+	assert.True(t, parser.ParseRequest(request))
+	assert.Equal(t, "PASS", request.GetStringAttribute(constlabels.FtpCommand))
+	assert.False(t, request.HasAttribute(constlabels.FtpArgument))
+
+	request = protocol.NewRequestMessage([]byte("PWD\r\n"))
+	assert.True(t, parser.ParseRequest(request))
+	assert.False(t, request.HasAttribute(constlabels.FtpArgument))
+
+	for _, data := range []string{"GET / HTTP/1.1\r\n", "RETR file", "PWD"} {
+		assert.False(t, parser.ParseRequest(protocol.NewRequestMessage([]byte(data))), data)
+	}
+}
+
+func TestParseFtpResponse(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        string
+		wantCode    int64
+		wantMessage string
+		wantError   bool
+	}{
+		{
+			name:        "single line",
+			data:        "331 Please specify the password.\r\n",
+			wantCode:    331,
+			wantMessage: "Please specify the password.",
+		},
+		{
+			name:        "preliminary and completion",
+			data:        "150 Opening BINARY mode data connection.\r\n226 Transfer complete.\r\n",
+			wantCode:    226,
+			wantMessage: "Transfer complete.",
+		},
+		{
+			name:        "multi line",
+			data:        "211-Features:\r\n EPRT\r\n 211 hidden\r\n MDTM\r\n211 End\r\n",
+			wantCode:    211,
+			wantMessage: "Features:",
+		},
+		{
+			name:        "failed transfer",
+			data:        "150 Opening data connection.\r\n426 Failure writing network stream.\r\n",
+			wantCode:    426,
+			wantMessage: "Failure writing network stream.",
+			wantError:   true,
+		},
+		{
+			name:        "permanent error",
+			data:        "550 Failed to open file.\r\n",
+			wantCode:    550,
+			wantMessage: "Failed to open file.",
+			wantError:   true,
+		},
+	}
+	parser := NewFtpParser()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := protocol.NewResponseMessage([]byte(tt.data), protocol.NewRequestMessage(nil).GetAttributes())
+			assert.True(t, parser.ParseResponse(response))
+			assert.Equal(t, tt.wantCode, response.GetIntAttribute(constlabels.FtpReplyCode))
+			assert.Equal(t, tt.wantMessage, response.GetStringAttribute(constlabels.FtpReplyMessage))
+			assert.Equal(t, tt.wantError, response.GetBoolAttribute(constlabels.IsError))
+		})
+	}
+
+	response := protocol.NewResponseMessage([]byte("HTTP/1.1 200 OK\r\n"), protocol.NewRequestMessage(nil).GetAttributes())
+	assert.False(t, parser.ParseResponse(response))
+}
@@ -0,0 +1,39 @@
+package ftp
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+func fastfailFtpRequest() protocol.FastFailFn {
+	return func(message *protocol.PayloadMessage) bool {
+		// The shortest commands like "PWD\r\n"
+		return len(message.Data) < 5
+	}
+}
+
+// parseFtpRequest reads the command line, which is the command and the optional argument ended with CRLF.
+// The arguments of PASS and ACCT are never recorded.
+func parseFtpRequest() protocol.ParsePkgFn {
+	return func(message *protocol.PayloadMessage) (bool, bool) {
+		end := bytes.Index(message.Data, []byte("\r\n"))
+		if end < 0 || end > maxLineLength {
+			return false, true
+		}
+		name, argument, _ := strings.Cut(string(message.Data[:end]), " ")
+		command := strings.ToUpper(name)
+		if !commands[command] {
+			return false, true
+		}
+
+		message.AddStringAttribute(constlabels.FtpCommand, command)
+		if argument != "" && !secretCommands[command] {
+			message.AddUtf8StringAttribute(constlabels.FtpArgument, argument)
+		}
+		message.AddStringAttribute(constlabels.ContentKey, command)
+		return true, true
+	}
+}
@@ -0,0 +1,84 @@
+package ftp
+
+import (
+	"bytes"
+	"strconv"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+func fastfailFtpResponse() protocol.FastFailFn {
+	return func(message *protocol.PayloadMessage) bool {
+		return len(message.Data) < 4 || !isReplyLine(message.Data)
+	}
+}
+
+// parseFtpResponse reads the replies, each of which is a line "ddd text" or the lines from "ddd-text"
+// to "ddd text". The transfer commands like RETR are replied with a preliminary reply 1yz and then a
+// completion reply, which are merged into one response, so the last reply is taken.
+func parseFtpResponse() protocol.ParsePkgFn {
+	return func(message *protocol.PayloadMessage) (bool, bool) {
+		code, text, ok := readLastReply(message.Data)
+		if !ok {
+			return false, true
+		}
+
+		message.AddIntAttribute(constlabels.FtpReplyCode, int64(code))
+		if text != "" {
+			message.AddUtf8StringAttribute(constlabels.FtpReplyMessage, text)
+		}
+		// 4yz is the transient negative completion reply and 5yz is the permanent one.
+		if code >= 400 {
+			message.AddBoolAttribute(constlabels.IsError, true)
+			message.AddIntAttribute(constlabels.ErrorType, int64(constlabels.ProtocolError))
+		}
+		return true, true
+	}
+}
+
+// readLastReply returns the code and the text of the last reply. The text of the multi-line reply is its
+// first line. The reply truncated in the middle is taken as well.
+func readLastReply(data []byte) (int, string, bool) {
+	var (
+		code      int
+		text      string
+		multiLine bool
+	)
+	for len(data) > 0 {
+		line := data
+		if end := bytes.Index(data, []byte("\r\n")); end >= 0 {
+			line, data = data[:end], data[end+2:]
+		} else {
+			data = nil
+		}
+		if !isReplyLine(line) {
+			// The lines inside the multi-line reply could be anything.
+			if multiLine {
+				continue
+			}
+			return code, text, code != 0
+		}
+		lineCode, _ := strconv.Atoi(string(line[:3]))
+		if multiLine {
+			// Only the same code ends the multi-line reply.
+			if lineCode == code && line[3] == ' ' {
+				multiLine = false
+			}
+			continue
+		}
+		code, text = lineCode, string(bytes.TrimSpace(line[4:]))
+		multiLine = line[3] == '-'
+	}
+	return code, text, code != 0
+}
+
+// isReplyLine returns whether the line starts with the reply code, whose first digit is from 1 to 5.
+func isReplyLine(line []byte) bool {
+	return len(line) >= 4 && '1' <= line[0] && line[0] <= '5' && isDigit(line[1]) && isDigit(line[2]) &&
+		(line[3] == ' ' || line[3] == '-')
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
@@ -0,0 +1,207 @@
+package grpc
+
+import (
+	"golang.org/x/net/http2/hpack"
+)
+
+type headerField struct {
+	name  string
+	value string
+}
+
+// staticTable is the static table of HPACK, see https://www.rfc-editor.org/rfc/rfc7541#appendix-A
+var staticTable = [...]headerField{
+	{":authority", ""},
+	{":method", "GET"},
+	{":method", "POST"},
+	{":path", "/"},
+	{":path", "/index.html"},
+	{":scheme", "http"},
+	{":scheme", "https"},
+	{":status", "200"},
+	{":status", "204"},
+	{":status", "206"},
+	{":status", "304"},
+	{":status", "400"},
+	{":status", "404"},
+	{":status", "500"},
+	{"accept-charset", ""},
+	{"accept-encoding", "gzip, deflate"},
+	{"accept-language", ""},
+	{"accept-ranges", ""},
+	{"accept", ""},
+	{"access-control-allow-origin", ""},
+	{"age", ""},
+	{"allow", ""},
+	{"authorization", ""},
+	{"cache-control", ""},
+	{"content-disposition", ""},
+	{"content-encoding", ""},
+	{"content-language", ""},
+	{"content-length", ""},
+	{"content-location", ""},
+	{"content-range", ""},
+	{"content-type", ""},
+	{"cookie", ""},
+	{"date", ""},
+	{"etag", ""},
+	{"expect", ""},
+	{"expires", ""},
+	{"from", ""},
+	{"host", ""},
+	{"if-match", ""},
+	{"if-modified-since", ""},
+	{"if-none-match", ""},
+	{"if-range", ""},
+	{"if-unmodified-since", ""},
+	{"last-modified", ""},
+	{"link", ""},
+	{"location", ""},
+	{"max-forwards", ""},
+	{"proxy-authenticate", ""},
+	{"proxy-authorization", ""},
+	{"range", ""},
+	{"referer", ""},
+	{"refresh", ""},
+	{"retry-after", ""},
+	{"server", ""},
+	{"set-cookie", ""},
+	{"strict-transport-security", ""},
+	{"transfer-encoding", ""},
+	{"user-agent", ""},
+	{"vary", ""},
+	{"via", ""},
+	{"www-authenticate", ""},
+}
+
+// headerDecoder decodes the header blocks of HPACK. The parsers see the payloads of the messages one by one,
+// so the dynamic table only holds the fields inserted by the blocks of the same payload. The fields indexed
+// by the earlier messages of the connection are unknown and skipped, as well as the fields truncated.
+type headerDecoder struct {
+	// dynamicTable holds the newest field first. The unknown fields are kept as the empty ones so that
+	// the indexes of the known fields are still right.
+	dynamicTable []headerField
+}
+
+// decode returns the fields of the block it knows and the number of the fields unknown, and false if the block is invalid.
+func (d *headerDecoder) decode(block []byte) ([]headerField, int, bool) {
+	fields := make([]headerField, 0, 8)
+	unknown := 0
+	for offset := 0; offset < len(block); {
+		b := block[offset]
+		switch {
+		case b&0x80 != 0:
+			// Indexed Header Field
+			index, n, ok := readInteger(block[offset:], 7)
+			if !ok {
+				return fields, unknown, true
+			}
+			offset += n
+			if index == 0 {
+				return fields, unknown, false
+			}
+			if field, known := d.lookup(index); known {
+				fields = append(fields, field)
+			} else {
+				unknown++
+			}
+		case b&0xe0 == 0x20:
+			// Dynamic Table Size Update, which evicts the fields we may not know.
+			_, n, ok := readInteger(block[offset:], 5)
+			if !ok {
+				return fields, unknown, true
+			}
+			offset += n
+		default:
+			// Literal Header Field with Incremental Indexing, without Indexing or Never Indexed
+			prefix := uint8(4)
+			indexing := b&0xc0 == 0x40
+			if indexing {
+				prefix = 6
+			}
+			index, n, ok := readInteger(block[offset:], prefix)
+			if !ok {
+				return fields, unknown, true
+			}
+			offset += n
+			var field headerField
+			known := true
+			if index == 0 {
+				if field.name, n, ok = readString(block[offset:]); !ok {
+					return fields, unknown, true
+				}
+				offset += n
+			} else {
+				field, known = d.lookup(index)
+			}
+			if field.value, n, ok = readString(block[offset:]); !ok {
+				return fields, unknown, true
+			}
+			offset += n
+			if indexing {
+				if !known {
+					field = headerField{}
+				}
+				d.dynamicTable = append([]headerField{field}, d.dynamicTable...)
+			}
+			if known {
+				fields = append(fields, field)
+			} else {
+				unknown++
+			}
+		}
+	}
+	return fields, unknown, true
+}
+
+func (d *headerDecoder) lookup(index uint64) (headerField, bool) {
+	if index <= uint64(len(staticTable)) {
+		return staticTable[index-1], true
+	}
+	index -= uint64(len(staticTable)) + 1
+	if index < uint64(len(d.dynamicTable)) && d.dynamicTable[index].name != "" {
+		return d.dynamicTable[index], true
+	}
+	return headerField{}, false
+}
+
+// readInteger reads the integer with the prefix of n bits, and returns the number of the bytes read.
+func readInteger(data []byte, n uint8) (uint64, int, bool) {
+	if len(data) == 0 {
+		return 0, 0, false
+	}
+	max := uint64(1)<<n - 1
+	value := uint64(data[0]) & max
+	if value < max {
+		return value, 1, true
+	}
+	var shift uint
+	for i := 1; i < len(data) && i <= 8; i++ {
+		value += uint64(data[i]&0x7f) << shift
+		if data[i]&0x80 == 0 {
+			return value, i + 1, true
+		}
+		shift += 7
+	}
+	return 0, 0, false
+}
+
+// readString reads the string literal, which may be encoded with the Huffman code.
+func readString(data []byte) (string, int, bool) {
+	if len(data) == 0 {
+		return "", 0, false
+	}
+	length, n, ok := readInteger(data, 7)
+	if !ok || uint64(len(data)-n) < length {
+		return "", 0, false
+	}
+	value := data[n : n+int(length)]
+	if data[0]&0x80 == 0 {
+		return string(value), n + int(length), true
+	}
+	s, err := hpack.HuffmanDecodeToString(value)
+	if err != nil {
+		return "", 0, false
+	}
+	return s, n + int(length), true
+}
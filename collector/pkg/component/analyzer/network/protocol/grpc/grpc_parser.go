@@ -0,0 +1,174 @@
+package grpc
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+)
+
+// protocolVersion is the version of HTTP carrying gRPC.
+const protocolVersion = "2"
+
+// clientPreface is sent by the clients at the start of the HTTP/2 connections.
+var clientPreface = []byte("PRI * HTTP/2.0\r\n\r\nSM\r\n\r\n")
+
+const frameHeaderLength = 9
+
+// The types of the HTTP/2 frames, see https://www.rfc-editor.org/rfc/rfc9113#section-6
+const (
+	frameHeaders      = 0x1
+	frameSettings     = 0x4
+	framePing         = 0x6
+	frameGoAway       = 0x7
+	frameContinuation = 0x9
+)
+
+// The flags of the HEADERS frames.
+const (
+	flagEndStream  = 0x1
+	flagEndHeaders = 0x4
+	flagPadded     = 0x8
+	flagPriority   = 0x20
+)
+
+// maxFrameSize is the largest SETTINGS_MAX_FRAME_SIZE allowed by HTTP/2.
+const maxFrameSize = 1<<24 - 1
+
+type frameHeader struct {
+	length   int
+	typ      uint8
+	flags    uint8
+	streamId uint32
+}
+
+func readFrameHeader(data []byte) (frameHeader, bool) {
+	if len(data) < frameHeaderLength {
+		return frameHeader{}, false
+	}
+	header := frameHeader{
+		length:   int(data[0])<<16 | int(data[1])<<8 | int(data[2]),
+		typ:      data[3],
+		flags:    data[4],
+		streamId: binary.BigEndian.Uint32(data[5:]) & 0x7fffffff,
+	}
+	if header.typ > frameContinuation || header.length > maxFrameSize {
+		return frameHeader{}, false
+	}
+	// The frames of the connection are on the stream 0, and the others are on the streams.
+	onConnection := header.typ == frameSettings || header.typ == framePing || header.typ == frameGoAway
+	if onConnection != (header.streamId == 0) {
+		return frameHeader{}, false
+	}
+	return header, true
+}
+
+// isHttp2Frames checks the first frame, which may follow the client preface.
+func isHttp2Frames(data []byte) bool {
+	_, ok := readFrameHeader(bytes.TrimPrefix(data, clientPreface))
+	return ok
+}
+
+// headerBlock is the header fields of a stream sent in a HEADERS frame and its CONTINUATION frames.
+type headerBlock struct {
+	streamId  uint32
+	fields    []headerField
+	endStream bool
+	// unknown is the number of the fields indexed by the earlier messages of the connection.
+	unknown int
+}
+
+func (b *headerBlock) get(name string) (string, bool) {
+	for _, field := range b.fields {
+		if field.name == name {
+			return field.value, true
+		}
+	}
+	return "", false
+}
+
+// readHeaderBlocks reads the header blocks of the frames in the payload, which may be truncated.
+// It returns false if the frames are invalid.
+func readHeaderBlocks(data []byte) ([]*headerBlock, bool) {
+	data = bytes.TrimPrefix(data, clientPreface)
+	decoder := &headerDecoder{}
+	blocks := make([]*headerBlock, 0)
+	var (
+		fragments []byte
+		current   *headerBlock
+	)
+	for offset := 0; offset < len(data); {
+		header, ok := readFrameHeader(data[offset:])
+		if !ok {
+			// The truncated frame header is ignored.
+			if len(data)-offset < frameHeaderLength {
+				break
+			}
+			return blocks, false
+		}
+		offset += frameHeaderLength
+		end := offset + header.length
+		if end > len(data) {
+			end = len(data)
+		}
+		payload := data[offset:end]
+		offset = end
+
+		switch header.typ {
+		case frameHeaders:
+			if payload, ok = headersFragment(header, payload); !ok {
+				return blocks, false
+			}
+			current = &headerBlock{streamId: header.streamId, endStream: header.flags&flagEndStream != 0}
+			fragments = append(fragments[:0], payload...)
+		case frameContinuation:
+			if current == nil || current.streamId != header.streamId {
+				return blocks, false
+			}
+			fragments = append(fragments, payload...)
+		default:
+			continue
+		}
+		if header.flags&flagEndHeaders != 0 || offset >= len(data) {
+			if current.fields, current.unknown, ok = decoder.decode(fragments); !ok {
+				return blocks, false
+			}
+			blocks = append(blocks, current)
+			current = nil
+		}
+	}
+	return blocks, true
+}
+
+// headersFragment returns the header block fragment of a HEADERS frame without the padding and the priority.
+// The payload of the frame may be truncated.
+func headersFragment(header frameHeader, payload []byte) ([]byte, bool) {
+	fragmentLength := header.length
+	if header.flags&flagPadded != 0 {
+		if len(payload) < 1 {
+			return nil, true
+		}
+		fragmentLength -= 1 + int(payload[0])
+		payload = payload[1:]
+	}
+	if header.flags&flagPriority != 0 {
+		if len(payload) < 5 {
+			return nil, true
+		}
+		fragmentLength -= 5
+		payload = payload[5:]
+	}
+	if fragmentLength < 0 {
+		return nil, false
+	}
+	if fragmentLength < len(payload) {
+		payload = payload[:fragmentLength]
+	}
+	return payload, true
+}
+
+func NewGrpcParser() *protocol.ProtocolParser {
+	requestParser := protocol.CreatePkgParser(fastfailGrpcRequest(), parseGrpcRequest())
+	responseParser := protocol.CreatePkgParser(fastfailGrpcResponse(), parseGrpcResponse())
+	return protocol.NewProtocolParser(protocol.GRPC, requestParser, responseParser, nil)
+}
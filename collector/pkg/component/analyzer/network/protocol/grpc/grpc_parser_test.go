@@ -0,0 +1,146 @@
+package grpc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/http2/hpack"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+func newFrame(typ uint8, flags uint8, streamId uint32, payload []byte) []byte {
+	frame := []byte{byte(len(payload) >> 16), byte(len(payload) >> 8), byte(len(payload)), typ, flags}
+	frame = binary.BigEndian.AppendUint32(frame, streamId)
+	return append(frame, payload...)
+}
+
+// encodeHeaders encodes the fields with the encoder, which keeps the dynamic table across the calls.
+func encodeHeaders(encoder *hpack.Encoder, buf *bytes.Buffer, fields ...string) []byte {
+	buf.Reset()
+	for i := 0; i+1 < len(fields); i += 2 {
+		_ = encoder.WriteField(hpack.HeaderField{Name: fields[i], Value: fields[i+1]})
+	}
+	return append([]byte(nil), buf.Bytes()...)
+}
+
+type connection struct {
+	buf     bytes.Buffer
+	encoder *hpack.Encoder
+}
+
+func newConnection() *connection {
+	c := &connection{}
+	c.encoder = hpack.NewEncoder(&c.buf)
+	return c
+}
+
+func (c *connection) request(streamId uint32, path string) []byte {
+	block := encodeHeaders(c.encoder, &c.buf, ":method", "POST", ":scheme", "http", ":path", path,
+		":authority", "localhost:50051", "content-type", "application/grpc", "te", "trailers")
+	frames := newFrame(frameHeaders, flagEndHeaders, streamId, block)
+	// The length-prefixed message
+	return append(frames, newFrame(0, flagEndStream, streamId, []byte{0, 0, 0, 0, 2, 0x0a, 0x00})...)
+}
+
+func (c *connection) response(streamId uint32, status string, message string) []byte {
+	frames := newFrame(frameHeaders, flagEndHeaders, streamId,
+		encodeHeaders(c.encoder, &c.buf, ":status", "200", "content-type", "application/grpc"))
+	frames = append(frames, newFrame(0, 0, streamId, []byte{0, 0, 0, 0, 0})...)
+	trailers := []string{"grpc-status", status}
+	if message != "" {
+		trailers = append(trailers, "grpc-message", message)
+	}
+	return append(frames, newFrame(frameHeaders, flagEndHeaders|flagEndStream, streamId,
+		encodeHeaders(c.encoder, &c.buf, trailers...))...)
+}
+
+func TestParseGrpc(t *testing.T) {
+	client, server := newConnection(), newConnection()
+	settings := newFrame(frameSettings, 0, 0, []byte{0, 4, 0, 0, 0xff, 0xff})
+	firstRequest := append(append(append([]byte(nil), clientPreface...), settings...), client.request(1, "/helloworld.Greeter/SayHello")...)
+
+	parser := NewGrpcParser()
+	request := protocol.NewRequestMessage(firstRequest)
+	assert.True(t, parser.ParseRequest(request))
+	assert.Equal(t, int64(1), request.GetIntAttribute(constlabels.GrpcStreamId))
+	assert.Equal(t, "/helloworld.Greeter/SayHello", request.GetStringAttribute(constlabels.ContentKey))
+
+	response := protocol.NewResponseMessage(append(settings, server.response(1, "0", "")...), request.GetAttributes())
+	assert.True(t, parser.ParseResponse(response))
+	assert.False(t, response.GetBoolAttribute(constlabels.IsError))
+	assert.Equal(t, int64(200), response.GetIntAttribute(constlabels.HttpStatusCode))
+	assert.Equal(t, int64(0), response.GetIntAttribute(constlabels.GrpcStatusCode))
+
+	// The path is indexed in the dynamic table by the first request, so it is unknown now.
+	request = protocol.NewRequestMessage(client.request(3, "/helloworld.Greeter/SayHello"))
+	assert.True(t, parser.ParseRequest(request))
+	assert.Equal(t, int64(3), request.GetIntAttribute(constlabels.GrpcStreamId))
+	assert.False(t, request.HasAttribute(constlabels.ContentKey))
+
+	// The responses sent by another server connection whose dynamic table is empty
+	response = protocol.NewResponseMessage(newConnection().response(3, "5", "order%20not%20found"), request.GetAttributes())
+	assert.True(t, parser.ParseResponse(response))
+	assert.True(t, response.GetBoolAttribute(constlabels.IsError))
+	assert.Equal(t, int64(5), response.GetIntAttribute(constlabels.GrpcStatusCode))
+	assert.Equal(t, "order not found", response.GetStringAttribute(constlabels.GrpcMessage))
+}
+
+func TestParseTrailersOnly(t *testing.T) {
+	client, server := newConnection(), newConnection()
+	parser := NewGrpcParser()
+	request := protocol.NewRequestMessage(client.request(1, "/helloworld.Greeter/SayHello"))
+	assert.True(t, parser.ParseRequest(request))
+
+	block := encodeHeaders(server.encoder, &server.buf, ":status", "200", "content-type", "application/grpc",
+		"grpc-status", "14", "grpc-message", "unavailable")
+	// The block is split into a HEADERS frame and a CONTINUATION frame.
+	data := append(newFrame(frameHeaders, flagEndStream, 1, block[:5]), newFrame(frameContinuation, flagEndHeaders, 1, block[5:])...)
+	response := protocol.NewResponseMessage(data, request.GetAttributes())
+	assert.True(t, parser.ParseResponse(response))
+	assert.True(t, response.GetBoolAttribute(constlabels.IsError))
+	assert.Equal(t, int64(14), response.GetIntAttribute(constlabels.GrpcStatusCode))
+}
+
+func TestParseTruncatedOrMismatched(t *testing.T) {
+	client, server := newConnection(), newConnection()
+	parser := NewGrpcParser()
+	data := client.request(1, "/helloworld.Greeter/SayHello")
+	// The message and the headers after :path are truncated.
+	request := protocol.NewRequestMessage(data[:frameHeaderLength+30])
+	assert.True(t, parser.ParseRequest(request))
+	assert.Equal(t, "/helloworld.Greeter/SayHello", request.GetStringAttribute(constlabels.ContentKey))
+
+	// The trailers are truncated.
+	data = server.response(1, "0", "")
+	response := protocol.NewResponseMessage(data[:len(data)-5], request.GetAttributes())
+	assert.True(t, parser.ParseResponse(response))
+	assert.False(t, response.GetBoolAttribute(constlabels.IsError))
+	assert.False(t, response.HasAttribute(constlabels.GrpcStatusCode))
+
+	// The response of another stream
+	assert.False(t, parser.ParseResponse(protocol.NewResponseMessage(server.response(3, "0", ""), request.GetAttributes())))
+}
+
+func TestParseInvalid(t *testing.T) {
+	parser := NewGrpcParser()
+	// Not gRPC
+	buf := &bytes.Buffer{}
+	block := encodeHeaders(hpack.NewEncoder(buf), buf, ":method", "GET", ":path", "/", "content-type", "text/html")
+	assert.False(t, parser.ParseRequest(protocol.NewRequestMessage(newFrame(frameHeaders, flagEndHeaders, 1, block))))
+	// Not POST
+	buf.Reset()
+	block = encodeHeaders(hpack.NewEncoder(buf), buf, ":method", "GET", ":path", "/helloworld.Greeter/SayHello")
+	assert.False(t, parser.ParseRequest(protocol.NewRequestMessage(newFrame(frameHeaders, flagEndHeaders, 1, block))))
+	// Only the frames of the connection
+	assert.False(t, parser.ParseRequest(protocol.NewRequestMessage(newFrame(frameSettings, 0, 0, nil))))
+	// The SETTINGS frame on a stream
+	assert.False(t, parser.ParseRequest(protocol.NewRequestMessage(newFrame(frameSettings, 0, 1, nil))))
+	// The invalid index 0
+	assert.False(t, parser.ParseRequest(protocol.NewRequestMessage(newFrame(frameHeaders, flagEndHeaders, 1, []byte{0x80}))))
+	// HTTP/1.1
+	assert.False(t, parser.ParseRequest(protocol.NewRequestMessage([]byte("GET /index.html HTTP/1.1\r\nHost: localhost\r\n\r\n"))))
+}
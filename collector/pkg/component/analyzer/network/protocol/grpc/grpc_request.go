@@ -0,0 +1,50 @@
+package grpc
+
+import (
+	"strings"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+func fastfailGrpcRequest() protocol.FastFailFn {
+	return func(message *protocol.PayloadMessage) bool {
+		return !isHttp2Frames(message.Data)
+	}
+}
+
+func parseGrpcRequest() protocol.ParsePkgFn {
+	return func(message *protocol.PayloadMessage) (bool, bool) {
+		blocks, ok := readHeaderBlocks(message.Data)
+		if !ok {
+			return false, true
+		}
+		// The first stream started in the payload is the request, whose headers hold the pseudo-header :method.
+		var request *headerBlock
+		for _, block := range blocks {
+			if _, found := block.get(":method"); found && block.streamId%2 == 1 {
+				request = block
+				break
+			}
+		}
+		if request == nil {
+			return false, true
+		}
+		// gRPC is always sent by POST, so the other requests are left to the parser of HTTP/2.
+		if method, _ := request.get(":method"); method != "POST" {
+			return false, true
+		}
+		// The content-type is unknown if it is indexed by the earlier requests.
+		if contentType, found := request.get("content-type"); found && !strings.HasPrefix(contentType, "application/grpc") {
+			return false, true
+		}
+
+		message.AddIntAttribute(constlabels.GrpcStreamId, int64(request.streamId))
+		message.AddStringAttribute(constlabels.ProtocolVersion, protocolVersion)
+		if path, found := request.get(":path"); found {
+			message.AddUtf8StringAttribute(constlabels.GrpcPath, path)
+			message.AddUtf8StringAttribute(constlabels.ContentKey, path)
+		}
+		return true, true
+	}
+}
@@ -0,0 +1,75 @@
+package grpc
+
+import (
+	"net/url"
+	"strconv"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+func fastfailGrpcResponse() protocol.FastFailFn {
+	return func(message *protocol.PayloadMessage) bool {
+		return !isHttp2Frames(message.Data)
+	}
+}
+
+func parseGrpcResponse() protocol.ParsePkgFn {
+	return func(message *protocol.PayloadMessage) (bool, bool) {
+		if !message.HasAttribute(constlabels.GrpcStreamId) {
+			return false, true
+		}
+		blocks, ok := readHeaderBlocks(message.Data)
+		if !ok {
+			return false, true
+		}
+
+		// The headers are followed by the messages and the trailers holding grpc-status, which are
+		// missing if the payload is truncated. The errors are usually responded in the trailers only.
+		streamId := uint32(message.GetIntAttribute(constlabels.GrpcStreamId))
+		var (
+			found         bool
+			statusCode    int64 = 200
+			grpcStatus    int64
+			hasGrpcStatus bool
+			grpcMessage   string
+		)
+		for _, block := range blocks {
+			if block.streamId != streamId {
+				continue
+			}
+			found = true
+			if value, ok := block.get(":status"); ok {
+				statusCode, _ = strconv.ParseInt(value, 10, 64)
+			}
+			if value, ok := block.get("grpc-status"); ok {
+				grpcStatus, _ = strconv.ParseInt(value, 10, 64)
+				hasGrpcStatus = true
+			}
+			if value, ok := block.get("grpc-message"); ok {
+				// The message is percent-encoded.
+				if unescaped, err := url.PathUnescape(value); err == nil {
+					grpcMessage = unescaped
+				} else {
+					grpcMessage = value
+				}
+			}
+		}
+		if !found {
+			return false, true
+		}
+
+		message.AddIntAttribute(constlabels.HttpStatusCode, statusCode)
+		if hasGrpcStatus {
+			message.AddIntAttribute(constlabels.GrpcStatusCode, grpcStatus)
+		}
+		if grpcMessage != "" {
+			message.AddUtf8StringAttribute(constlabels.GrpcMessage, grpcMessage)
+		}
+		if grpcStatus != 0 || statusCode != 200 {
+			message.AddBoolAttribute(constlabels.IsError, true)
+			message.AddIntAttribute(constlabels.ErrorType, int64(constlabels.ProtocolError))
+		}
+		return true, true
+	}
+}
@@ -0,0 +1,108 @@
+package grpc
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/urlclustering"
+)
+
+// NewHttp2Parser parses the requests of HTTP/2 in cleartext (h2c) sent with the prior knowledge, which share
+// the frames and the header compression with gRPC. The gRPC requests are left to the parser of gRPC.
+func NewHttp2Parser(urlClusteringMethod string) *protocol.ProtocolParser {
+	method := urlclustering.NewMethod(urlClusteringMethod)
+	requestParser := protocol.CreatePkgParser(fastfailHttp2Request(), parseHttp2Request(method))
+	responseParser := protocol.CreatePkgParser(fastfailHttp2Response(), parseHttp2Response())
+	return protocol.NewProtocolParser(protocol.HTTP2, requestParser, responseParser, nil)
+}
+
+func fastfailHttp2Request() protocol.FastFailFn {
+	return func(message *protocol.PayloadMessage) bool {
+		return !isHttp2Frames(message.Data)
+	}
+}
+
+func parseHttp2Request(urlClusteringMethod urlclustering.ClusteringMethod) protocol.ParsePkgFn {
+	return func(message *protocol.PayloadMessage) (bool, bool) {
+		blocks, ok := readHeaderBlocks(message.Data)
+		if !ok {
+			return false, true
+		}
+		// The first stream started in the payload is the request, whose headers hold the pseudo-header :method.
+		var (
+			request *headerBlock
+			method  string
+		)
+		for _, block := range blocks {
+			if value, found := block.get(":method"); found && block.streamId%2 == 1 {
+				request, method = block, value
+				break
+			}
+		}
+		if request == nil {
+			return false, true
+		}
+		if contentType, found := request.get("content-type"); found && strings.HasPrefix(contentType, "application/grpc") {
+			return false, true
+		}
+
+		message.AddIntAttribute(constlabels.Http2StreamId, int64(request.streamId))
+		message.AddStringAttribute(constlabels.ProtocolVersion, protocolVersion)
+		message.AddStringAttribute(constlabels.HttpMethod, method)
+		// The path is unknown if it is indexed by the earlier requests.
+		if path, found := request.get(":path"); found {
+			message.AddUtf8StringAttribute(constlabels.HttpUrl, path)
+			contentKey := urlClusteringMethod.Clustering(path)
+			if len(contentKey) == 0 {
+				contentKey = "*"
+			}
+			message.AddUtf8StringAttribute(constlabels.ContentKey, contentKey)
+		}
+		return true, true
+	}
+}
+
+func fastfailHttp2Response() protocol.FastFailFn {
+	return func(message *protocol.PayloadMessage) bool {
+		return !isHttp2Frames(message.Data)
+	}
+}
+
+func parseHttp2Response() protocol.ParsePkgFn {
+	return func(message *protocol.PayloadMessage) (bool, bool) {
+		if !message.HasAttribute(constlabels.Http2StreamId) {
+			return false, true
+		}
+		blocks, ok := readHeaderBlocks(message.Data)
+		if !ok {
+			return false, true
+		}
+
+		// The response of the stream is paired by the stream id, as the responses of the other streams
+		// may be sent before it. The interim responses 1xx are followed by the final one.
+		streamId := uint32(message.GetIntAttribute(constlabels.Http2StreamId))
+		var statusCode int64
+		for _, block := range blocks {
+			if block.streamId != streamId {
+				continue
+			}
+			if value, found := block.get(":status"); found {
+				if code, err := strconv.ParseInt(value, 10, 64); err == nil && statusCode < 200 {
+					statusCode = code
+				}
+			}
+		}
+		if statusCode == 0 {
+			return false, true
+		}
+
+		message.AddIntAttribute(constlabels.HttpStatusCode, statusCode)
+		if statusCode >= 400 {
+			message.AddBoolAttribute(constlabels.IsError, true)
+			message.AddIntAttribute(constlabels.ErrorType, int64(constlabels.ProtocolError))
+		}
+		return true, true
+	}
+}
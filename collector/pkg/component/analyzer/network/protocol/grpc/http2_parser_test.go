@@ -0,0 +1,79 @@
+package grpc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+func (c *connection) http2Request(streamId uint32, method string, path string) []byte {
+	return newFrame(frameHeaders, flagEndHeaders|flagEndStream, streamId, encodeHeaders(c.encoder, &c.buf,
+		":method", method, ":scheme", "http", ":path", path, ":authority", "localhost:8080"))
+}
+
+func (c *connection) http2Response(streamId uint32, status string) []byte {
+	frames := newFrame(frameHeaders, flagEndHeaders, streamId,
+		encodeHeaders(c.encoder, &c.buf, ":status", status, "content-type", "text/plain"))
+	return append(frames, newFrame(0, flagEndStream, streamId, []byte("hello"))...)
+}
+
+func TestParseHttp2(t *testing.T) {
+	client, server := newConnection(), newConnection()
+	settings := newFrame(frameSettings, 0, 0, []byte{0, 3, 0, 0, 0, 100})
+	firstRequest := append(append(append([]byte(nil), clientPreface...), settings...), client.http2Request(1, "GET", "/users/1024?verbose=true")...)
+
+	parser := NewHttp2Parser("alphabet")
+	request := protocol.NewRequestMessage(firstRequest)
+	assert.True(t, parser.ParseRequest(request))
+	assert.Equal(t, int64(1), request.GetIntAttribute(constlabels.Http2StreamId))
+	assert.Equal(t, "GET", request.GetStringAttribute(constlabels.HttpMethod))
+	assert.Equal(t, "/users/1024?verbose=true", request.GetStringAttribute(constlabels.HttpUrl))
+	assert.Equal(t, "/users/*", request.GetStringAttribute(constlabels.ContentKey))
+	assert.Equal(t, "2", request.GetStringAttribute(constlabels.ProtocolVersion))
+
+	response := protocol.NewResponseMessage(append(settings, server.http2Response(1, "200")...), request.GetAttributes())
+	assert.True(t, parser.ParseResponse(response))
+	assert.False(t, response.GetBoolAttribute(constlabels.IsError))
+	assert.Equal(t, int64(200), response.GetIntAttribute(constlabels.HttpStatusCode))
+
+	// The response of the stream 5 is sent before the response of the stream 3.
+	request = protocol.NewRequestMessage(client.http2Request(3, "POST", "/orders"))
+	assert.True(t, parser.ParseRequest(request))
+	assert.Equal(t, int64(3), request.GetIntAttribute(constlabels.Http2StreamId))
+	data := append(server.http2Response(5, "200"), server.http2Response(3, "503")...)
+	response = protocol.NewResponseMessage(data, request.GetAttributes())
+	assert.True(t, parser.ParseResponse(response))
+	assert.True(t, response.GetBoolAttribute(constlabels.IsError))
+	assert.Equal(t, int64(503), response.GetIntAttribute(constlabels.HttpStatusCode))
+
+	// Only the response of another stream
+	assert.False(t, parser.ParseResponse(protocol.NewResponseMessage(server.http2Response(5, "200"), request.GetAttributes())))
+}
+
+func TestParseHttp2Interim(t *testing.T) {
+	client, server := newConnection(), newConnection()
+	parser := NewHttp2Parser("noparam")
+	request := protocol.NewRequestMessage(client.http2Request(1, "PUT", "/files/report"))
+	assert.True(t, parser.ParseRequest(request))
+
+	data := append(newFrame(frameHeaders, flagEndHeaders, 1, encodeHeaders(server.encoder, &server.buf, ":status", "100")),
+		server.http2Response(1, "404")...)
+	response := protocol.NewResponseMessage(data, request.GetAttributes())
+	assert.True(t, parser.ParseResponse(response))
+	assert.Equal(t, int64(404), response.GetIntAttribute(constlabels.HttpStatusCode))
+	assert.True(t, response.GetBoolAttribute(constlabels.IsError))
+}
+
+func TestParseHttp2Invalid(t *testing.T) {
+	parser := NewHttp2Parser("alphabet")
+	// gRPC
+	assert.False(t, parser.ParseRequest(protocol.NewRequestMessage(newConnection().request(1, "/helloworld.Greeter/SayHello"))))
+	// Only the connection preface and the frames of the connection
+	data := append(append([]byte(nil), clientPreface...), newFrame(frameSettings, 0, 0, nil)...)
+	assert.False(t, parser.ParseRequest(protocol.NewRequestMessage(data)))
+	// HTTP/1.1
+	assert.False(t, parser.ParseRequest(protocol.NewRequestMessage([]byte("GET /index.html HTTP/1.1\r\nHost: localhost\r\n\r\n"))))
+}
@@ -0,0 +1,99 @@
+package grpc
+
+import (
+	"strings"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+)
+
+// tripleHeaderPrefix is the prefix of the headers added by Triple, e.g. tri-service-version and tri-service-group.
+const tripleHeaderPrefix = "tri-"
+
+// NewTripleParser parses Triple, the protocol of Dubbo3 over HTTP/2 which is compatible with gRPC. The requests
+// are recognized by the headers of Triple or its own content-types, so list it before "grpc" and "http2" in the
+// "protocol_parser" array.
+func NewTripleParser() *protocol.ProtocolParser {
+	requestParser := protocol.CreatePkgParser(fastfailTripleRequest(), parseTripleRequest())
+	responseParser := protocol.CreatePkgParser(fastfailTripleResponse(), parseTripleResponse())
+	return protocol.NewProtocolParser(protocol.TRIPLE, requestParser, responseParser, nil)
+}
+
+// isTripleContentType checks the content-types of the gRPC-compatible messages and the ones of Triple
+// which are not sent by gRPC, e.g. application/json for the unary calls.
+func isTripleContentType(contentType string) (valid bool, tripleOnly bool) {
+	if strings.HasPrefix(contentType, "application/grpc") {
+		return true, false
+	}
+	if strings.HasPrefix(contentType, "application/json") || strings.HasPrefix(contentType, "application/proto") {
+		return true, true
+	}
+	return false, false
+}
+
+// splitServicePath splits the path "/{service}/{method}" of the request.
+func splitServicePath(path string) (string, string, bool) {
+	path = strings.TrimPrefix(path, "/")
+	index := strings.LastIndexByte(path, '/')
+	if index <= 0 || index == len(path)-1 {
+		return "", "", false
+	}
+	return path[:index], path[index+1:], true
+}
+
+// The status codes of gRPC, which are shared by Triple.
+var grpcStatusNames = []string{
+	"OK",
+	"CANCELLED",
+	"UNKNOWN",
+	"INVALID_ARGUMENT",
+	"DEADLINE_EXCEEDED",
+	"NOT_FOUND",
+	"ALREADY_EXISTS",
+	"PERMISSION_DENIED",
+	"RESOURCE_EXHAUSTED",
+	"FAILED_PRECONDITION",
+	"ABORTED",
+	"OUT_OF_RANGE",
+	"UNIMPLEMENTED",
+	"INTERNAL",
+	"UNAVAILABLE",
+	"DATA_LOSS",
+	"UNAUTHENTICATED",
+}
+
+const (
+	grpcStatusUnknown         = 2
+	grpcStatusPermission      = 7
+	grpcStatusUnimplemented   = 12
+	grpcStatusInternal        = 13
+	grpcStatusUnavailable     = 14
+	grpcStatusUnauthenticated = 16
+)
+
+func grpcStatusName(code int64) string {
+	if code < 0 || code >= int64(len(grpcStatusNames)) {
+		return "UNKNOWN"
+	}
+	return grpcStatusNames[code]
+}
+
+// httpToGrpcStatus maps the HTTP status of the responses without grpc-status, see
+// https://github.com/grpc/grpc/blob/master/doc/http-grpc-status-mapping.md
+func httpToGrpcStatus(statusCode int64) int64 {
+	switch statusCode {
+	case 200:
+		return 0
+	case 400:
+		return grpcStatusInternal
+	case 401:
+		return grpcStatusUnauthenticated
+	case 403:
+		return grpcStatusPermission
+	case 404:
+		return grpcStatusUnimplemented
+	case 429, 502, 503, 504:
+		return grpcStatusUnavailable
+	default:
+		return grpcStatusUnknown
+	}
+}
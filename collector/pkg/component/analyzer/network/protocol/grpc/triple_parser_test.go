@@ -0,0 +1,84 @@
+package grpc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+func (c *connection) tripleRequest(streamId uint32, path string, contentType string) []byte {
+	block := encodeHeaders(c.encoder, &c.buf, ":method", "POST", ":scheme", "http", ":path", path,
+		":authority", "localhost:50051", "content-type", contentType, "te", "trailers",
+		"tri-service-version", "1.0.0", "tri-service-group", "order", "tri-consumer-appname", "shop")
+	frames := newFrame(frameHeaders, flagEndHeaders, streamId, block)
+	return append(frames, newFrame(0, flagEndStream, streamId, []byte{0, 0, 0, 0, 2, 0x0a, 0x00})...)
+}
+
+func TestParseTriple(t *testing.T) {
+	client, server := newConnection(), newConnection()
+	parser := NewTripleParser()
+	request := protocol.NewRequestMessage(client.tripleRequest(1, "/org.apache.dubbo.demo.OrderService/getOrder", "application/grpc+proto"))
+	assert.True(t, parser.ParseRequest(request))
+	assert.Equal(t, int64(1), request.GetIntAttribute(constlabels.Http2StreamId))
+	assert.Equal(t, "org.apache.dubbo.demo.OrderService", request.GetStringAttribute(constlabels.TripleService))
+	assert.Equal(t, "getOrder", request.GetStringAttribute(constlabels.TripleMethod))
+	assert.Equal(t, "org.apache.dubbo.demo.OrderService#getOrder", request.GetStringAttribute(constlabels.ContentKey))
+	assert.Equal(t, "1.0.0", request.GetStringAttribute(constlabels.TripleVersion))
+	assert.Equal(t, "order", request.GetStringAttribute(constlabels.TripleGroup))
+
+	response := protocol.NewResponseMessage(server.response(1, "0", ""), request.GetAttributes())
+	assert.True(t, parser.ParseResponse(response))
+	assert.False(t, response.GetBoolAttribute(constlabels.IsError))
+	assert.Equal(t, int64(0), response.GetIntAttribute(constlabels.TripleStatusCode))
+	assert.Equal(t, "OK", response.GetStringAttribute(constlabels.TripleStatus))
+
+	// The headers are indexed by the first request, so the request is only known by the unknown fields.
+	request = protocol.NewRequestMessage(client.tripleRequest(3, "/org.apache.dubbo.demo.OrderService/getOrder", "application/grpc+proto"))
+	assert.True(t, parser.ParseRequest(request))
+	assert.False(t, request.HasAttribute(constlabels.ContentKey))
+
+	// The responses sent by another server connection whose dynamic table is empty
+	response = protocol.NewResponseMessage(newConnection().response(3, "4", "timeout%20after%203000ms"), request.GetAttributes())
+	assert.True(t, parser.ParseResponse(response))
+	assert.True(t, response.GetBoolAttribute(constlabels.IsError))
+	assert.Equal(t, int64(4), response.GetIntAttribute(constlabels.TripleStatusCode))
+	assert.Equal(t, "DEADLINE_EXCEEDED", response.GetStringAttribute(constlabels.TripleStatus))
+	assert.Equal(t, "timeout after 3000ms", response.GetStringAttribute(constlabels.TripleMessage))
+}
+
+func TestParseTripleStatusMapping(t *testing.T) {
+	client, server := newConnection(), newConnection()
+	parser := NewTripleParser()
+	// The unary call in JSON is responded without the trailers.
+	request := protocol.NewRequestMessage(client.tripleRequest(1, "/org.apache.dubbo.demo.OrderService/getOrder", "application/json"))
+	assert.True(t, parser.ParseRequest(request))
+	data := newFrame(frameHeaders, flagEndHeaders|flagEndStream, 1,
+		encodeHeaders(server.encoder, &server.buf, ":status", "503", "content-type", "application/json"))
+	response := protocol.NewResponseMessage(data, request.GetAttributes())
+	assert.True(t, parser.ParseResponse(response))
+	assert.True(t, response.GetBoolAttribute(constlabels.IsError))
+	assert.Equal(t, int64(503), response.GetIntAttribute(constlabels.HttpStatusCode))
+	assert.Equal(t, int64(14), response.GetIntAttribute(constlabels.TripleStatusCode))
+	assert.Equal(t, "UNAVAILABLE", response.GetStringAttribute(constlabels.TripleStatus))
+
+	// The exception thrown by the provider
+	request = protocol.NewRequestMessage(client.tripleRequest(3, "/org.apache.dubbo.demo.OrderService/getOrder", "application/grpc"))
+	assert.True(t, parser.ParseRequest(request))
+	data = newFrame(frameHeaders, flagEndHeaders|flagEndStream, 3, encodeHeaders(server.encoder, &server.buf,
+		":status", "200", "grpc-status", "2", "tri-exception-code", "5"))
+	response = protocol.NewResponseMessage(data, request.GetAttributes())
+	assert.True(t, parser.ParseResponse(response))
+	assert.True(t, response.GetBoolAttribute(constlabels.IsError))
+	assert.Equal(t, int64(2), response.GetIntAttribute(constlabels.TripleStatusCode))
+	assert.Equal(t, int64(5), response.GetIntAttribute(constlabels.TripleExceptionCode))
+}
+
+func TestParseTripleLeavesGrpc(t *testing.T) {
+	// The gRPC request whose fields are all known is left to the parser of gRPC.
+	data := newConnection().request(1, "/helloworld.Greeter/SayHello")
+	assert.False(t, NewTripleParser().ParseRequest(protocol.NewRequestMessage(data)))
+	assert.True(t, NewGrpcParser().ParseRequest(protocol.NewRequestMessage(data)))
+}
@@ -0,0 +1,76 @@
+package grpc
+
+import (
+	"strings"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+func fastfailTripleRequest() protocol.FastFailFn {
+	return func(message *protocol.PayloadMessage) bool {
+		return !isHttp2Frames(message.Data)
+	}
+}
+
+func parseTripleRequest() protocol.ParsePkgFn {
+	return func(message *protocol.PayloadMessage) (bool, bool) {
+		blocks, ok := readHeaderBlocks(message.Data)
+		if !ok {
+			return false, true
+		}
+		// The first stream started in the payload is the request, whose headers hold the pseudo-header :method.
+		var request *headerBlock
+		for _, block := range blocks {
+			if _, found := block.get(":method"); found && block.streamId%2 == 1 {
+				request = block
+				break
+			}
+		}
+		if request == nil {
+			return false, true
+		}
+		if method, _ := request.get(":method"); method != "POST" {
+			return false, true
+		}
+		isTriple := false
+		if contentType, found := request.get("content-type"); found {
+			valid, tripleOnly := isTripleContentType(contentType)
+			if !valid {
+				return false, true
+			}
+			isTriple = tripleOnly
+		}
+		for _, field := range request.fields {
+			if strings.HasPrefix(field.name, tripleHeaderPrefix) {
+				isTriple = true
+				break
+			}
+		}
+		// The headers of Triple are indexed by the earlier requests on the same connection, so the requests
+		// with the unknown fields are taken as Triple as well. The ones of gRPC are left to the parser of gRPC.
+		if !isTriple && request.unknown == 0 {
+			return false, true
+		}
+
+		message.AddIntAttribute(constlabels.Http2StreamId, int64(request.streamId))
+		message.AddStringAttribute(constlabels.ProtocolVersion, protocolVersion)
+		if path, found := request.get(":path"); found {
+			if service, method, ok := splitServicePath(path); ok {
+				message.AddUtf8StringAttribute(constlabels.TripleService, service)
+				message.AddUtf8StringAttribute(constlabels.TripleMethod, method)
+				// Like Dubbo2, the content key is "{service}#{method}".
+				message.AddUtf8StringAttribute(constlabels.ContentKey, service+"#"+method)
+			} else {
+				message.AddUtf8StringAttribute(constlabels.ContentKey, path)
+			}
+		}
+		if version, found := request.get("tri-service-version"); found && version != "" {
+			message.AddUtf8StringAttribute(constlabels.TripleVersion, version)
+		}
+		if group, found := request.get("tri-service-group"); found && group != "" {
+			message.AddUtf8StringAttribute(constlabels.TripleGroup, group)
+		}
+		return true, true
+	}
+}
@@ -0,0 +1,87 @@
+package grpc
+
+import (
+	"net/url"
+	"strconv"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+func fastfailTripleResponse() protocol.FastFailFn {
+	return func(message *protocol.PayloadMessage) bool {
+		return !isHttp2Frames(message.Data)
+	}
+}
+
+func parseTripleResponse() protocol.ParsePkgFn {
+	return func(message *protocol.PayloadMessage) (bool, bool) {
+		if !message.HasAttribute(constlabels.Http2StreamId) {
+			return false, true
+		}
+		blocks, ok := readHeaderBlocks(message.Data)
+		if !ok {
+			return false, true
+		}
+
+		// Like gRPC, the status is responded in the trailers, which are missing if the payload is truncated.
+		// The unary calls of Triple in application/json are responded without the trailers, whose status is
+		// mapped from the HTTP status.
+		streamId := uint32(message.GetIntAttribute(constlabels.Http2StreamId))
+		var (
+			found         bool
+			statusCode    int64 = 200
+			grpcStatus    int64
+			hasGrpcStatus bool
+			grpcMessage   string
+			exceptionCode int64
+		)
+		for _, block := range blocks {
+			if block.streamId != streamId {
+				continue
+			}
+			found = true
+			if value, ok := block.get(":status"); ok {
+				statusCode, _ = strconv.ParseInt(value, 10, 64)
+			}
+			if value, ok := block.get("grpc-status"); ok {
+				grpcStatus, _ = strconv.ParseInt(value, 10, 64)
+				hasGrpcStatus = true
+			}
+			if value, ok := block.get("grpc-message"); ok {
+				// The message is percent-encoded.
+				if unescaped, err := url.PathUnescape(value); err == nil {
+					grpcMessage = unescaped
+				} else {
+					grpcMessage = value
+				}
+			}
+			if value, ok := block.get("tri-exception-code"); ok {
+				exceptionCode, _ = strconv.ParseInt(value, 10, 64)
+			}
+		}
+		if !found {
+			return false, true
+		}
+
+		message.AddIntAttribute(constlabels.HttpStatusCode, statusCode)
+		if !hasGrpcStatus && statusCode != 200 {
+			grpcStatus, hasGrpcStatus = httpToGrpcStatus(statusCode), true
+		}
+		if hasGrpcStatus {
+			message.AddIntAttribute(constlabels.TripleStatusCode, grpcStatus)
+			message.AddStringAttribute(constlabels.TripleStatus, grpcStatusName(grpcStatus))
+		}
+		if grpcMessage != "" {
+			message.AddUtf8StringAttribute(constlabels.TripleMessage, grpcMessage)
+		}
+		if exceptionCode != 0 {
+			message.AddIntAttribute(constlabels.TripleExceptionCode, exceptionCode)
+		}
+		if grpcStatus != 0 || exceptionCode != 0 {
+			message.AddBoolAttribute(constlabels.IsError, true)
+			message.AddIntAttribute(constlabels.ErrorType, int64(constlabels.ProtocolError))
+		}
+		return true, true
+	}
+}
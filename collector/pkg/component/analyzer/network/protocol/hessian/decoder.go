@@ -0,0 +1,458 @@
+// Package hessian decodes the payloads serialized in Hessian 2.0, which is the default serialization of Dubbo.
+// The values are skipped instead of being deserialized, so only their types and encoded sizes are known.
+// See http://hessian.caucho.com/doc/hessian-serialization.html for the grammar.
+package hessian
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// maxDepth limits the nesting of the lists, maps and objects, so the malformed payloads can't exhaust the stack.
+const maxDepth = 32
+
+var (
+	ErrTruncated = errors.New("hessian: the data is truncated")
+	ErrInvalid   = errors.New("hessian: the data is invalid")
+)
+
+// Kind is the type of a Hessian value.
+type Kind uint8
+
+const (
+	KindNull Kind = iota
+	KindBool
+	KindInt
+	KindLong
+	KindDouble
+	KindDate
+	KindString
+	KindBinary
+	KindList
+	KindMap
+	KindObject
+	// KindRef refers to a list, map or object decoded before.
+	KindRef
+)
+
+var kindNames = [...]string{"null", "bool", "int", "long", "double", "date", "string", "binary", "list", "map", "object", "ref"}
+
+func (k Kind) String() string {
+	if int(k) < len(kindNames) {
+		return kindNames[k]
+	}
+	return "unknown"
+}
+
+// Value is the summary of a top-level value.
+type Value struct {
+	Kind Kind
+	// Type is the class of the objects, or the type of the typed lists and maps. It is empty if unknown.
+	Type string
+	// Size is the number of the bytes the value is encoded in, including the class definitions before it.
+	Size int
+}
+
+type classDef struct {
+	name   string
+	fields int
+}
+
+// Decoder reads the values one by one. The class definitions and the types are shared by all the values
+// of a stream, so the values must be read in order with the same Decoder.
+type Decoder struct {
+	data    []byte
+	offset  int
+	classes []classDef
+	types   []string
+}
+
+// NewDecoder creates a Decoder reading the data from the offset.
+func NewDecoder(data []byte, offset int) *Decoder {
+	return &Decoder{data: data, offset: offset}
+}
+
+// Offset returns the offset of the next value.
+func (d *Decoder) Offset() int {
+	return d.offset
+}
+
+// Next skips the next value and returns its summary. The offset is not changed if an error is returned.
+func (d *Decoder) Next() (Value, error) {
+	start := d.offset
+	kind, typ, err := d.skipValue(0)
+	if err != nil {
+		d.offset = start
+		return Value{}, err
+	}
+	return Value{Kind: kind, Type: typ, Size: d.offset - start}, nil
+}
+
+// ReadString reads the next value as a string. The offset is not changed if an error is returned.
+func (d *Decoder) ReadString() (string, error) {
+	start := d.offset
+	value, err := d.readString(true)
+	if err != nil {
+		d.offset = start
+		return "", err
+	}
+	return value, nil
+}
+
+func (d *Decoder) peekByte() (byte, error) {
+	if d.offset >= len(d.data) {
+		return 0, ErrTruncated
+	}
+	return d.data[d.offset], nil
+}
+
+func (d *Decoder) readByte() (byte, error) {
+	b, err := d.peekByte()
+	if err == nil {
+		d.offset++
+	}
+	return b, err
+}
+
+func (d *Decoder) skip(n int) error {
+	if d.offset+n > len(d.data) {
+		return ErrTruncated
+	}
+	d.offset += n
+	return nil
+}
+
+func (d *Decoder) readUint16() (int, error) {
+	if d.offset+2 > len(d.data) {
+		return 0, ErrTruncated
+	}
+	value := binary.BigEndian.Uint16(d.data[d.offset:])
+	d.offset += 2
+	return int(value), nil
+}
+
+func (d *Decoder) readInt() (int32, error) {
+	tag, err := d.readByte()
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case tag >= 0x80 && tag <= 0xbf:
+		return int32(tag) - 0x90, nil
+	case tag >= 0xc0 && tag <= 0xcf:
+		b, err := d.readByte()
+		if err != nil {
+			return 0, err
+		}
+		return (int32(tag)-0xc8)<<8 + int32(b), nil
+	case tag >= 0xd0 && tag <= 0xd7:
+		value, err := d.readUint16()
+		if err != nil {
+			return 0, err
+		}
+		return (int32(tag)-0xd4)<<16 + int32(value), nil
+	case tag == 'I':
+		if d.offset+4 > len(d.data) {
+			return 0, ErrTruncated
+		}
+		value := int32(binary.BigEndian.Uint32(d.data[d.offset:]))
+		d.offset += 4
+		return value, nil
+	}
+	return 0, ErrInvalid
+}
+
+func isStringTag(tag byte) bool {
+	return tag <= 0x1f || (tag >= 0x30 && tag <= 0x33) || tag == 'S' || tag == 'R'
+}
+
+func isBinaryTag(tag byte) bool {
+	return (tag >= 0x20 && tag <= 0x2f) || (tag >= 0x34 && tag <= 0x37) || tag == 'B' || tag == 'A'
+}
+
+// readString reads the chunks of a string, whose lengths are the numbers of the UTF-16 characters.
+func (d *Decoder) readString(collect bool) (string, error) {
+	var value []byte
+	for {
+		tag, err := d.readByte()
+		if err != nil {
+			return "", err
+		}
+		var length int
+		final := true
+		switch {
+		case tag <= 0x1f:
+			length = int(tag)
+		case tag >= 0x30 && tag <= 0x33:
+			b, err := d.readByte()
+			if err != nil {
+				return "", err
+			}
+			length = int(tag-0x30)<<8 + int(b)
+		case tag == 'S' || tag == 'R':
+			if length, err = d.readUint16(); err != nil {
+				return "", err
+			}
+			final = tag == 'S'
+		default:
+			return "", ErrInvalid
+		}
+		start := d.offset
+		if err = d.skipChars(length); err != nil {
+			return "", err
+		}
+		if collect {
+			value = append(value, d.data[start:d.offset]...)
+		}
+		if final {
+			return string(value), nil
+		}
+	}
+}
+
+func (d *Decoder) skipChars(n int) error {
+	for i := 0; i < n; i++ {
+		b, err := d.peekByte()
+		if err != nil {
+			return err
+		}
+		switch {
+		case b < 0x80:
+			err = d.skip(1)
+		case b < 0xe0:
+			err = d.skip(2)
+		case b < 0xf0:
+			err = d.skip(3)
+		default:
+			// The 4-byte UTF-8 is a surrogate pair in UTF-16.
+			err = d.skip(4)
+			i++
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *Decoder) skipBinary() error {
+	for {
+		tag, err := d.readByte()
+		if err != nil {
+			return err
+		}
+		var length int
+		final := true
+		switch {
+		case tag >= 0x20 && tag <= 0x2f:
+			length = int(tag - 0x20)
+		case tag >= 0x34 && tag <= 0x37:
+			b, err := d.readByte()
+			if err != nil {
+				return err
+			}
+			length = int(tag-0x34)<<8 + int(b)
+		case tag == 'B' || tag == 'A':
+			if length, err = d.readUint16(); err != nil {
+				return err
+			}
+			final = tag == 'B'
+		default:
+			return ErrInvalid
+		}
+		if err = d.skip(length); err != nil {
+			return err
+		}
+		if final {
+			return nil
+		}
+	}
+}
+
+// readType reads the type of a list or map, which is either a string or the index of a type read before.
+func (d *Decoder) readType() (string, error) {
+	tag, err := d.peekByte()
+	if err != nil {
+		return "", err
+	}
+	if isStringTag(tag) {
+		typ, err := d.readString(true)
+		if err != nil {
+			return "", err
+		}
+		d.types = append(d.types, typ)
+		return typ, nil
+	}
+	index, err := d.readInt()
+	if err != nil {
+		return "", err
+	}
+	if index < 0 || int(index) >= len(d.types) {
+		return "", ErrInvalid
+	}
+	return d.types[index], nil
+}
+
+func (d *Decoder) readLength() (int, error) {
+	length, err := d.readInt()
+	if err != nil {
+		return 0, err
+	}
+	if length < 0 {
+		return 0, ErrInvalid
+	}
+	return int(length), nil
+}
+
+func (d *Decoder) skipValues(n int, depth int) error {
+	for i := 0; i < n; i++ {
+		if _, _, err := d.skipValue(depth); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// skipUntilEnd skips the values until the end tag 'Z'.
+func (d *Decoder) skipUntilEnd(depth int) error {
+	for {
+		tag, err := d.peekByte()
+		if err != nil {
+			return err
+		}
+		if tag == 'Z' {
+			d.offset++
+			return nil
+		}
+		if _, _, err = d.skipValue(depth); err != nil {
+			return err
+		}
+	}
+}
+
+func (d *Decoder) skipValue(depth int) (Kind, string, error) {
+	if depth > maxDepth {
+		return 0, "", ErrInvalid
+	}
+	tag, err := d.peekByte()
+	if err != nil {
+		return 0, "", err
+	}
+	switch {
+	case tag == 'N':
+		return KindNull, "", d.skip(1)
+	case tag == 'T' || tag == 'F':
+		return KindBool, "", d.skip(1)
+	case (tag >= 0x80 && tag <= 0xd7) || tag == 'I':
+		_, err = d.readInt()
+		return KindInt, "", err
+	case tag >= 0xd8 && tag <= 0xef:
+		return KindLong, "", d.skip(1)
+	case tag >= 0xf0:
+		return KindLong, "", d.skip(2)
+	case tag >= 0x38 && tag <= 0x3f:
+		return KindLong, "", d.skip(3)
+	case tag == 'Y':
+		return KindLong, "", d.skip(5)
+	case tag == 'L':
+		return KindLong, "", d.skip(9)
+	case tag == 0x5b || tag == 0x5c:
+		return KindDouble, "", d.skip(1)
+	case tag == 0x5d:
+		return KindDouble, "", d.skip(2)
+	case tag == 0x5e:
+		return KindDouble, "", d.skip(3)
+	case tag == 0x5f:
+		return KindDouble, "", d.skip(5)
+	case tag == 'D':
+		return KindDouble, "", d.skip(9)
+	case tag == 0x4a:
+		return KindDate, "", d.skip(9)
+	case tag == 0x4b:
+		return KindDate, "", d.skip(5)
+	case isStringTag(tag):
+		_, err = d.readString(false)
+		return KindString, "", err
+	case isBinaryTag(tag):
+		return KindBinary, "", d.skipBinary()
+	}
+
+	d.offset++
+	switch {
+	case tag == 'U' || tag == 'V' || (tag >= 0x70 && tag <= 0x77):
+		typ, err := d.readType()
+		if err != nil {
+			return 0, "", err
+		}
+		switch tag {
+		case 'U':
+			err = d.skipUntilEnd(depth + 1)
+		case 'V':
+			var length int
+			if length, err = d.readLength(); err == nil {
+				err = d.skipValues(length, depth+1)
+			}
+		default:
+			err = d.skipValues(int(tag-0x70), depth+1)
+		}
+		return KindList, typ, err
+	case tag == 'W':
+		return KindList, "", d.skipUntilEnd(depth + 1)
+	case tag == 'X':
+		length, err := d.readLength()
+		if err == nil {
+			err = d.skipValues(length, depth+1)
+		}
+		return KindList, "", err
+	case tag >= 0x78 && tag <= 0x7f:
+		return KindList, "", d.skipValues(int(tag-0x78), depth+1)
+	case tag == 'M':
+		typ, err := d.readType()
+		if err != nil {
+			return 0, "", err
+		}
+		return KindMap, typ, d.skipUntilEnd(depth + 1)
+	case tag == 'H':
+		return KindMap, "", d.skipUntilEnd(depth + 1)
+	case tag == 'C':
+		if err = d.readClassDef(); err != nil {
+			return 0, "", err
+		}
+		// The definition is followed by the object using it.
+		return d.skipValue(depth + 1)
+	case tag == 'O' || (tag >= 0x60 && tag <= 0x6f):
+		index := int(tag - 0x60)
+		if tag == 'O' {
+			if index, err = d.readLength(); err != nil {
+				return 0, "", err
+			}
+		}
+		if index >= len(d.classes) {
+			return 0, "", ErrInvalid
+		}
+		class := d.classes[index]
+		return KindObject, class.name, d.skipValues(class.fields, depth+1)
+	case tag == 'Q':
+		_, err = d.readInt()
+		return KindRef, "", err
+	}
+	return 0, "", ErrInvalid
+}
+
+func (d *Decoder) readClassDef() error {
+	name, err := d.readString(true)
+	if err != nil {
+		return err
+	}
+	fields, err := d.readLength()
+	if err != nil {
+		return err
+	}
+	for i := 0; i < fields; i++ {
+		if _, err = d.readString(false); err != nil {
+			return err
+		}
+	}
+	d.classes = append(d.classes, classDef{name: name, fields: fields})
+	return nil
+}
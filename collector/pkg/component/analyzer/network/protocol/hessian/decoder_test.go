@@ -0,0 +1,113 @@
+package hessian
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func mustDecodeHex(t *testing.T, s string) []byte {
+	data, err := hex.DecodeString(strings.ReplaceAll(s, " ", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestNext(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want Value
+	}{
+		{name: "null", data: "4e", want: Value{Kind: KindNull, Size: 1}},
+		{name: "true", data: "54", want: Value{Kind: KindBool, Size: 1}},
+		{name: "compact int", data: "90", want: Value{Kind: KindInt, Size: 1}},
+		{name: "2-byte int", data: "c8 30", want: Value{Kind: KindInt, Size: 2}},
+		{name: "3-byte int", data: "d4 00 30", want: Value{Kind: KindInt, Size: 3}},
+		{name: "int", data: "49 00 00 01 2c", want: Value{Kind: KindInt, Size: 5}},
+		{name: "compact long", data: "e0", want: Value{Kind: KindLong, Size: 1}},
+		{name: "3-byte long", data: "3c 00 30", want: Value{Kind: KindLong, Size: 3}},
+		{name: "long", data: "4c 00 00 00 00 00 00 01 2c", want: Value{Kind: KindLong, Size: 9}},
+		{name: "double 1.0", data: "5c", want: Value{Kind: KindDouble, Size: 1}},
+		{name: "double", data: "44 40 28 80 00 00 00 00 00", want: Value{Kind: KindDouble, Size: 9}},
+		{name: "date", data: "4a 00 00 00 d0 4b 92 84 b8", want: Value{Kind: KindDate, Size: 9}},
+		{name: "short string", data: "05 68 65 6c 6c 6f", want: Value{Kind: KindString, Size: 6}},
+		// The length is in UTF-16, so the 3-byte character is counted once.
+		{name: "utf8 string", data: "02 c3 83 e4 b8 ad", want: Value{Kind: KindString, Size: 6}},
+		{name: "chunked string", data: "52 00 02 61 62 53 00 01 63", want: Value{Kind: KindString, Size: 9}},
+		{name: "binary", data: "23 01 02 03", want: Value{Kind: KindBinary, Size: 4}},
+		{name: "typed fixed list", data: "72 04 5b 69 6e 74 90 91", want: Value{Kind: KindList, Type: "[int", Size: 8}},
+		{name: "untyped variable list", data: "57 90 91 92 5a", want: Value{Kind: KindList, Size: 5}},
+		{name: "untyped fixed list", data: "58 93 90 91 92", want: Value{Kind: KindList, Size: 5}},
+		{name: "untyped map", data: "48 91 03 66 65 65 5a", want: Value{Kind: KindMap, Size: 7}},
+		{name: "typed map", data: "4d 08 6a 61 76 61 2e 4d 61 70 91 92 5a", want: Value{Kind: KindMap, Type: "java.Map", Size: 13}},
+		// class Car { color, model }
+		{name: "object with definition", data: "43 0b 65 78 61 6d 70 6c 65 2e 43 61 72 92 05 63 6f 6c 6f 72 05 6d 6f 64 65 6c 60 03 72 65 64 08 63 6f 72 76 65 74 74 65",
+			want: Value{Kind: KindObject, Type: "example.Car", Size: 40}},
+		{name: "ref", data: "51 90", want: Value{Kind: KindRef, Size: 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := mustDecodeHex(t, tt.data)
+			decoder := NewDecoder(data, 0)
+			value, err := decoder.Next()
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, value)
+			assert.Equal(t, len(data), decoder.Offset())
+
+			if len(data) > 1 {
+				decoder = NewDecoder(data[:len(data)-1], 0)
+				_, err = decoder.Next()
+				assert.ErrorIs(t, err, ErrTruncated)
+				assert.Equal(t, 0, decoder.Offset())
+			}
+		})
+	}
+}
+
+func TestNextWithSharedDefinitions(t *testing.T) {
+	// Two objects of the same class, and two lists of the same type
+	data := mustDecodeHex(t, "43 03 43 61 72 91 05 63 6f 6c 6f 72 60 03 72 65 64 60 04 62 6c 75 65"+
+		" 71 04 5b 69 6e 74 90 71 90 91")
+	decoder := NewDecoder(data, 0)
+	for _, want := range []Value{
+		{Kind: KindObject, Type: "Car", Size: 17},
+		{Kind: KindObject, Type: "Car", Size: 6},
+		{Kind: KindList, Type: "[int", Size: 7},
+		{Kind: KindList, Type: "[int", Size: 3},
+	} {
+		value, err := decoder.Next()
+		assert.NoError(t, err)
+		assert.Equal(t, want, value)
+	}
+}
+
+func TestNextInvalid(t *testing.T) {
+	for _, data := range []string{
+		// undefined class
+		"60 90",
+		// undefined type
+		"71 90 90",
+		// reserved tag
+		"40",
+		// too deep
+		strings.Repeat("79 ", maxDepth+2) + "90",
+	} {
+		_, err := NewDecoder(mustDecodeHex(t, data), 0).Next()
+		assert.ErrorIs(t, err, ErrInvalid, data)
+	}
+}
+
+func TestReadString(t *testing.T) {
+	decoder := NewDecoder(mustDecodeHex(t, "05 32 2e 30 2e 32 30 05 61"), 0)
+	value, err := decoder.ReadString()
+	assert.NoError(t, err)
+	assert.Equal(t, "2.0.2", value)
+	_, err = decoder.ReadString()
+	assert.ErrorIs(t, err, ErrTruncated)
+	_, err = NewDecoder([]byte{'N'}, 0).ReadString()
+	assert.ErrorIs(t, err, ErrInvalid)
+}
@@ -0,0 +1,25 @@
+package http
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// getCookie returns the value of the cookie from the value of the Cookie header.
+func getCookie(header string, name string) (string, bool) {
+	for _, pair := range strings.Split(header, ";") {
+		key, value, found := strings.Cut(strings.TrimSpace(pair), "=")
+		if found && key == name {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// hashSessionId returns the first 16 hex characters of the SHA-256 of the session ID,
+// which is enough to tell the sessions apart without storing the raw ID.
+func hashSessionId(id string) string {
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:8])
+}
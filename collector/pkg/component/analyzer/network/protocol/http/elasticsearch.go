@@ -0,0 +1,188 @@
+package http
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+// maxEsIndexLength limits the length of the normalized indices, which are dropped if longer.
+const maxEsIndexLength = 128
+
+// esIndexEndpoints are the endpoints of Elasticsearch following the indices, e.g. "/logs/_search".
+// Most of them can also be requested without the indices.
+var esIndexEndpoints = map[string]string{
+	"_search":          "search",
+	"_msearch":         "msearch",
+	"_count":           "count",
+	"_bulk":            "bulk",
+	"_mget":            "mget",
+	"_update":          "update",
+	"_create":          "create",
+	"_delete_by_query": "delete_by_query",
+	"_update_by_query": "update_by_query",
+	"_explain":         "explain",
+	"_validate":        "validate",
+	"_field_caps":      "field_caps",
+	"_refresh":         "refresh",
+	"_flush":           "flush",
+	"_forcemerge":      "forcemerge",
+	"_mapping":         "mapping",
+	"_settings":        "settings",
+	"_alias":           "alias",
+	"_aliases":         "alias",
+	"_analyze":         "analyze",
+	"_rollover":        "rollover",
+	"_open":            "open",
+	"_close":           "close",
+	"_stats":           "stats",
+	"_pit":             "pit",
+}
+
+// esClusterEndpoints are the endpoints of Elasticsearch not related to any index.
+var esClusterEndpoints = map[string]string{
+	"_cat":            "cat",
+	"_cluster":        "cluster",
+	"_nodes":          "nodes",
+	"_tasks":          "tasks",
+	"_snapshot":       "snapshot",
+	"_ingest":         "ingest",
+	"_template":       "template",
+	"_index_template": "index_template",
+	"_data_stream":    "data_stream",
+	"_ilm":            "ilm",
+	"_security":       "security",
+	"_license":        "license",
+	"_xpack":          "xpack",
+	"_sql":            "sql",
+}
+
+var (
+	// esTook matches "took" of the search and the bulk responses, which is the first field of the body.
+	esTook = regexp.MustCompile(`^\{\s*"took"\s*:\s*(\d+)`)
+	// esErrorStatus matches "status" of the error responses like {"error":{...},"status":404}.
+	esErrorStatus = regexp.MustCompile(`"status"\s*:\s*(\d+)\s*}\s*$`)
+)
+
+// getEsRequest returns the operation and the normalized indices of the Elasticsearch request. The path
+// is taken as Elasticsearch only if it holds the endpoints starting with "_", so "/users/123" is not.
+// The documents are requested with "/{index}/_doc/{id}", whose operation depends on the method.
+func getEsRequest(method string, url string) (string, string) {
+	path := getContentKey(url)
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if strings.HasPrefix(segments[0], "_") {
+		if segments[0] == "_search" && len(segments) > 1 && segments[1] == "scroll" {
+			return "scroll", ""
+		}
+		if operation, ok := esIndexEndpoints[segments[0]]; ok {
+			return operation, ""
+		}
+		return esClusterEndpoints[segments[0]], ""
+	}
+	if len(segments) < 2 {
+		return "", ""
+	}
+
+	var operation string
+	if segments[1] == "_doc" {
+		switch method {
+		case "GET":
+			operation = "get"
+		case "HEAD":
+			operation = "exists"
+		case "DELETE":
+			operation = "delete"
+		case "PUT", "POST":
+			operation = "index"
+		}
+	} else {
+		operation = esIndexEndpoints[segments[1]]
+	}
+	if operation == "" {
+		return "", ""
+	}
+	return operation, normalizeEsIndex(segments[0])
+}
+
+// normalizeEsIndex replaces the parts of the index names made of digits with "*", so the time-based
+// indices like "logs-2024.01.15" and the rollover indices like "logs-000001" are both "logs-*". The
+// indices holding the characters not allowed by Elasticsearch, e.g. the date math, are dropped.
+func normalizeEsIndex(index string) string {
+	if index == "" || len(index) > maxEsIndexLength {
+		return ""
+	}
+	names := strings.Split(index, ",")
+	for i, name := range names {
+		if name == "" || !isValidEsIndex(name) {
+			return ""
+		}
+		parts := strings.Split(name, "-")
+		normalized := parts[:0]
+		for _, part := range parts {
+			if isEsIndexSuffix(part) {
+				part = "*"
+			}
+			if part == "*" && len(normalized) > 0 && normalized[len(normalized)-1] == "*" {
+				continue
+			}
+			normalized = append(normalized, part)
+		}
+		names[i] = strings.Join(normalized, "-")
+	}
+	return strings.Join(names, ",")
+}
+
+func isValidEsIndex(name string) bool {
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if !('a' <= c && c <= 'z') && !('0' <= c && c <= '9') && c != '-' && c != '_' && c != '.' && c != '*' && c != '+' {
+			return false
+		}
+	}
+	return true
+}
+
+// isEsIndexSuffix returns whether the part is a date or a sequence, e.g. "2024.01.15" and "000001".
+func isEsIndexSuffix(part string) bool {
+	if part == "" || part[0] < '0' || part[0] > '9' {
+		return false
+	}
+	for i := 0; i < len(part); i++ {
+		if (part[i] < '0' || part[i] > '9') && part[i] != '.' && part[i] != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+// addEsResponseAttributes reads "took" of the search and the bulk responses, and "status" of the error
+// responses. The body may be truncated, so the status at the end of the error may not be found.
+func addEsResponseAttributes(message *protocol.PayloadMessage) {
+	bodyStart := bytes.Index(message.Data, []byte("\r\n\r\n"))
+	if bodyStart < 0 {
+		return
+	}
+	body := message.Data[bodyStart+4:]
+	// Skip the size of the first chunk if the body is chunked.
+	if start := bytes.IndexByte(body, '{'); start > 0 && start <= 10 {
+		body = body[start:]
+	}
+	if matches := esTook.FindSubmatch(body); matches != nil {
+		if took, err := strconv.ParseInt(string(matches[1]), 10, 64); err == nil {
+			message.AddIntAttribute(constlabels.EsTook, took)
+		}
+		return
+	}
+	if !bytes.HasPrefix(body, []byte(`{"error"`)) {
+		return
+	}
+	if matches := esErrorStatus.FindSubmatch(bytes.TrimRight(body, "\r\n0")); matches != nil {
+		if status, err := strconv.ParseInt(string(matches[1]), 10, 64); err == nil {
+			message.AddIntAttribute(constlabels.EsStatus, status)
+		}
+	}
+}
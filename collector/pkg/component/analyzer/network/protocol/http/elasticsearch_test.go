@@ -0,0 +1,148 @@
+package http
+
+import (
+	"testing"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+func TestParseHttpRequest_Elasticsearch(t *testing.T) {
+	tests := []struct {
+		name           string
+		data           string
+		wantOperation  string
+		wantIndex      string
+		wantContentKey string
+	}{
+		{
+			name:           "search with time-based index",
+			data:           "POST /logs-2024.01.15/_search?size=10 HTTP/1.1\r\nContent-Type: application/json\r\n\r\n{}",
+			wantOperation:  "search",
+			wantIndex:      "logs-*",
+			wantContentKey: "search logs-*",
+		},
+		{
+			name:           "bulk without index",
+			data:           "POST /_bulk HTTP/1.1\r\nContent-Type: application/x-ndjson\r\n\r\n",
+			wantOperation:  "bulk",
+			wantContentKey: "bulk",
+		},
+		{
+			name:           "get document of rollover index",
+			data:           "GET /orders-000042/_doc/9f3a HTTP/1.1\r\n\r\n",
+			wantOperation:  "get",
+			wantIndex:      "orders-*",
+			wantContentKey: "get orders-*",
+		},
+		{
+			name:           "index document",
+			data:           "PUT /users/_doc/1 HTTP/1.1\r\n\r\n",
+			wantOperation:  "index",
+			wantIndex:      "users",
+			wantContentKey: "index users",
+		},
+		{
+			name:           "multiple indices",
+			data:           "GET /logs-2024.01.15-000001,metrics-*/_count HTTP/1.1\r\n\r\n",
+			wantOperation:  "count",
+			wantIndex:      "logs-*,metrics-*",
+			wantContentKey: "count logs-*,metrics-*",
+		},
+		{
+			name:           "scroll",
+			data:           "POST /_search/scroll HTTP/1.1\r\n\r\n",
+			wantOperation:  "scroll",
+			wantContentKey: "scroll",
+		},
+		{
+			name:           "cat",
+			data:           "GET /_cat/indices?v HTTP/1.1\r\n\r\n",
+			wantOperation:  "cat",
+			wantContentKey: "cat",
+		},
+		{
+			name:           "invalid index",
+			data:           "GET /Users/_search HTTP/1.1\r\n\r\n",
+			wantOperation:  "search",
+			wantContentKey: "search",
+		},
+		{
+			name:           "not elasticsearch",
+			data:           "GET /users/123 HTTP/1.1\r\n\r\n",
+			wantContentKey: "/users/*",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message := protocol.NewRequestMessage([]byte(tt.data))
+			NewHttpParser("alphabet", "", nil, false, true, false, nil).ParseRequest(message)
+			attributes := message.GetAttributes()
+			if got := attributes.GetStringValue(constlabels.EsOperation); got != tt.wantOperation {
+				t.Errorf("es_operation = %v, want %v", got, tt.wantOperation)
+			}
+			if got := attributes.GetStringValue(constlabels.EsIndex); got != tt.wantIndex {
+				t.Errorf("es_index = %v, want %v", got, tt.wantIndex)
+			}
+			if got := attributes.GetStringValue(constlabels.ContentKey); got != tt.wantContentKey {
+				t.Errorf("content_key = %v, want %v", got, tt.wantContentKey)
+			}
+		})
+	}
+
+	message := protocol.NewRequestMessage([]byte(tests[0].data))
+	NewHttpParser("alphabet", "", nil, false, false, false, nil).ParseRequest(message)
+	if message.GetAttributes().HasAttribute(constlabels.EsOperation) {
+		t.Errorf("es_operation should not be added if it is not enabled")
+	}
+}
+
+func TestParseHttpResponse_Elasticsearch(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       string
+		wantTook   int64
+		wantStatus int64
+	}{
+		{
+			name:     "search",
+			data:     "HTTP/1.1 200 OK\r\ncontent-type: application/json\r\n\r\n{\"took\":12,\"timed_out\":false,\"_shards\":{}}",
+			wantTook: 12,
+		},
+		{
+			name:     "chunked bulk",
+			data:     "HTTP/1.1 200 OK\r\ntransfer-encoding: chunked\r\n\r\n1f\r\n{\"took\":30,\"errors\":false}\r\n0\r\n\r\n",
+			wantTook: 30,
+		},
+		{
+			name: "error",
+			data: "HTTP/1.1 404 Not Found\r\ncontent-type: application/json\r\n\r\n" +
+				"{\"error\":{\"type\":\"index_not_found_exception\"},\"status\":404}",
+			wantStatus: 404,
+		},
+		{
+			name: "truncated error",
+			data: "HTTP/1.1 400 Bad Request\r\n\r\n{\"error\":{\"root_cause\":[{\"type\":\"parsing_exception\"",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := NewHttpParser("alphabet", "", nil, false, true, false, nil)
+			request := protocol.NewRequestMessage([]byte("POST /logs/_search HTTP/1.1\r\n\r\n"))
+			if !parser.ParseRequest(request) {
+				t.Fatal("failed to parse the request")
+			}
+			response := protocol.NewResponseMessage([]byte(tt.data), request.GetAttributes())
+			if !parser.ParseResponse(response) {
+				t.Fatal("failed to parse the response")
+			}
+			attributes := response.GetAttributes()
+			if got := attributes.GetIntValue(constlabels.EsTook); got != tt.wantTook {
+				t.Errorf("es_took = %v, want %v", got, tt.wantTook)
+			}
+			if got := attributes.GetIntValue(constlabels.EsStatus); got != tt.wantStatus {
+				t.Errorf("es_status = %v, want %v", got, tt.wantStatus)
+			}
+		})
+	}
+}
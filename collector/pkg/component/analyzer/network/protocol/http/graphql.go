@@ -0,0 +1,188 @@
+package http
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+)
+
+// maxGraphqlQueryPrefix limits the beginning of the query decoded to find the operation type and name.
+const maxGraphqlQueryPrefix = 256
+
+var graphqlOperationTypes = map[string]bool{
+	"query":        true,
+	"mutation":     true,
+	"subscription": true,
+}
+
+// graphqlOperation is the operation of the GraphQL request. The name is empty if the operation is anonymous.
+type graphqlOperation struct {
+	operationType string
+	name          string
+}
+
+// getGraphqlOperation returns the operation of the GraphQL request sent with POST to one of the paths.
+// The body is scanned instead of being unmarshalled, so the operation is found in the truncated bodies as
+// well. The first operation is returned for the batched requests.
+func getGraphqlOperation(message *protocol.PayloadMessage, method string, url string, paths map[string]bool) (*graphqlOperation, bool) {
+	if method != "POST" {
+		return nil, false
+	}
+	if index := strings.IndexByte(url, '?'); index >= 0 {
+		url = url[:index]
+	}
+	if !paths[url] {
+		return nil, false
+	}
+	bodyStart := bytes.Index(message.Data, []byte("\r\n\r\n"))
+	if bodyStart < 0 {
+		return nil, false
+	}
+	body := message.Data[bodyStart+4:]
+	var operation *graphqlOperation
+	// The variables may hold a field with the same key, which is not a document.
+	for rest := body; operation == nil; {
+		var query string
+		var ok bool
+		if query, rest, ok = readJsonStringField(rest, "query"); !ok {
+			return nil, false
+		}
+		operation = parseGraphqlQuery(query)
+	}
+	// The operationName selects the operation to execute if the document holds several of them.
+	if name, _, ok := readJsonStringField(body, "operationName"); ok && isGraphqlName(name) {
+		operation.name = name
+	}
+	return operation, true
+}
+
+// parseGraphqlQuery reads the type and the name of the first operation in the document, e.g.
+// "query" and "GetUser" of "query GetUser($id: ID!) { ... }". The shorthand "{ ... }" is an anonymous query.
+func parseGraphqlQuery(query string) *graphqlOperation {
+	query = skipGraphqlIgnored(query)
+	if strings.HasPrefix(query, "{") {
+		return &graphqlOperation{operationType: "query"}
+	}
+	keyword := readGraphqlName(query)
+	if !graphqlOperationTypes[keyword] {
+		return nil
+	}
+	operation := &graphqlOperation{operationType: keyword}
+	if name := readGraphqlName(skipGraphqlIgnored(query[len(keyword):])); isGraphqlName(name) {
+		operation.name = name
+	}
+	return operation
+}
+
+// skipGraphqlIgnored skips the whitespaces, the commas and the comments at the beginning of the document.
+func skipGraphqlIgnored(query string) string {
+	for len(query) > 0 {
+		switch query[0] {
+		case ' ', '\t', '\r', '\n', ',':
+			query = query[1:]
+		case '#':
+			if end := strings.IndexByte(query, '\n'); end >= 0 {
+				query = query[end+1:]
+			} else {
+				query = ""
+			}
+		default:
+			return query
+		}
+	}
+	return query
+}
+
+func readGraphqlName(query string) string {
+	end := 0
+	for end < len(query) && isGraphqlNameChar(query[end], end == 0) {
+		end++
+	}
+	return query[:end]
+}
+
+func isGraphqlName(name string) bool {
+	if name == "" || len(name) > maxOperationLength {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		if !isGraphqlNameChar(name[i], i == 0) {
+			return false
+		}
+	}
+	return true
+}
+
+func isGraphqlNameChar(c byte, first bool) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (!first && c >= '0' && c <= '9')
+}
+
+// readJsonStringField returns the beginning of the string value of the first field with the key and the
+// data after the key. At most maxGraphqlQueryPrefix bytes are decoded, and the value truncated by the
+// capture is returned as it is.
+func readJsonStringField(body []byte, key string) (string, []byte, bool) {
+	pattern := []byte(`"` + key + `"`)
+	for {
+		index := bytes.Index(body, pattern)
+		if index < 0 {
+			return "", nil, false
+		}
+		body = body[index+len(pattern):]
+		value := bytes.TrimLeft(body, " \t\r\n")
+		if len(value) == 0 || value[0] != ':' {
+			// The key is a part of another string.
+			continue
+		}
+		value = bytes.TrimLeft(value[1:], " \t\r\n")
+		if len(value) == 0 || value[0] != '"' {
+			// The value is not a string, e.g. null.
+			continue
+		}
+		return decodeJsonString(value[1:]), body, true
+	}
+}
+
+// decodeJsonString decodes the string until the closing quote. The escapes other than the whitespaces,
+// the quote and the backslash are replaced with a space, as they are never a part of the names.
+func decodeJsonString(data []byte) string {
+	var decoded strings.Builder
+	for i := 0; i < len(data) && decoded.Len() < maxGraphqlQueryPrefix; i++ {
+		c := data[i]
+		if c == '"' {
+			break
+		}
+		if c != '\\' {
+			decoded.WriteByte(c)
+			continue
+		}
+		if i++; i >= len(data) {
+			break
+		}
+		switch data[i] {
+		case 'n':
+			decoded.WriteByte('\n')
+		case 't':
+			decoded.WriteByte('\t')
+		case 'r':
+			decoded.WriteByte('\r')
+		case '"', '\\', '/':
+			decoded.WriteByte(data[i])
+		case 'u':
+			// Skip the 4 hex digits.
+			i += 4
+			decoded.WriteByte(' ')
+		default:
+			decoded.WriteByte(' ')
+		}
+	}
+	return decoded.String()
+}
+
+// getContentKeySuffix returns the operation appended to the content key, e.g. "query GetUser".
+func (o *graphqlOperation) getContentKeySuffix() string {
+	if o.name == "" {
+		return o.operationType
+	}
+	return o.operationType + " " + o.name
+}
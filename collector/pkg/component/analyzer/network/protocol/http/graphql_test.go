@@ -0,0 +1,98 @@
+package http
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+func TestParseHttpRequest_Graphql(t *testing.T) {
+	header := "POST /graphql HTTP/1.1\r\nContent-Type: application/json\r\n\r\n"
+	tests := []struct {
+		name           string
+		data           string
+		wantType       string
+		wantOperation  string
+		wantContentKey string
+	}{
+		{
+			name:           "named query",
+			data:           header + `{"query":"query GetUser($id: ID!) {\n  user(id: $id) { name }\n}","variables":{"id":"1"}}`,
+			wantType:       "query",
+			wantOperation:  "GetUser",
+			wantContentKey: "/graphql#query GetUser",
+		},
+		{
+			name:           "operation name selects the operation",
+			data:           header + `{"query": "# comment\nquery A { a } mutation B { b }", "operationName": "B"}`,
+			wantType:       "query",
+			wantOperation:  "B",
+			wantContentKey: "/graphql#query B",
+		},
+		{
+			name:           "mutation with the operation name first",
+			data:           header + `{"operationName":"AddTodo","variables":{"query":"x"},"query":"mutation AddTodo($text: String!) { addTodo(text: $text) { id } }"}`,
+			wantType:       "mutation",
+			wantOperation:  "AddTodo",
+			wantContentKey: "/graphql#mutation AddTodo",
+		},
+		{
+			name:           "anonymous shorthand",
+			data:           header + `{"query":"{ viewer { login } }","operationName":null}`,
+			wantType:       "query",
+			wantContentKey: "/graphql#query",
+		},
+		{
+			name:           "batched",
+			data:           header + `[{"query":"subscription OnEvent { event { id } }"},{"query":"query Other { a }"}]`,
+			wantType:       "subscription",
+			wantOperation:  "OnEvent",
+			wantContentKey: "/graphql#subscription OnEvent",
+		},
+		{
+			name:           "truncated body",
+			data:           header + `{"query":"query Search { search(text: \"` + strings.Repeat("a", 100),
+			wantType:       "query",
+			wantOperation:  "Search",
+			wantContentKey: "/graphql#query Search",
+		},
+		{
+			name:           "not graphql",
+			data:           header + `{"name":"kindling"}`,
+			wantContentKey: "/graphql",
+		},
+		{
+			name:           "other path",
+			data:           "POST /api/users HTTP/1.1\r\n\r\n" + `{"query":"query GetUser { user { name } }"}`,
+			wantContentKey: "/api/users",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message := protocol.NewRequestMessage([]byte(tt.data))
+			if !NewHttpParser("alphabet", "", nil, false, false, false, []string{"/graphql"}).ParseRequest(message) {
+				t.Fatal("failed to parse the request")
+			}
+			attributes := message.GetAttributes()
+			if got := attributes.GetStringValue(constlabels.GraphqlOperationType); got != tt.wantType {
+				t.Errorf("graphql_operation_type = %v, want %v", got, tt.wantType)
+			}
+			if got := attributes.GetStringValue(constlabels.GraphqlOperation); got != tt.wantOperation {
+				t.Errorf("graphql_operation = %v, want %v", got, tt.wantOperation)
+			}
+			if got := attributes.GetStringValue(constlabels.ContentKey); got != tt.wantContentKey {
+				t.Errorf("content_key = %v, want %v", got, tt.wantContentKey)
+			}
+		})
+	}
+}
+
+func TestParseHttpRequest_GraphqlDisabled(t *testing.T) {
+	message := protocol.NewRequestMessage([]byte("POST /graphql HTTP/1.1\r\n\r\n" + `{"query":"query GetUser { user { name } }"}`))
+	NewHttpParser("alphabet", "", nil, false, false, false, nil).ParseRequest(message)
+	if message.GetAttributes().HasAttribute(constlabels.GraphqlOperationType) {
+		t.Errorf("graphql_operation_type should not be added if it is disabled")
+	}
+}
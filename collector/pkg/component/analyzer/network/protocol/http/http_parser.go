@@ -7,12 +7,46 @@ import (
 	"github.com/Kindling-project/kindling/collector/pkg/urlclustering"
 )
 
-func NewHttpParser(urlClusteringMethod string) *protocol.ProtocolParser {
+// minCaptureLength is the length of the payloads holding the request line and the headers in most cases.
+const minCaptureLength = 1024
+
+// NewHttpParser creates the parser of HTTP. If sessionCookie is not empty, the hash of the cookie
+// with the name is added as the label "http_session_hash". The values of the maskedHeaders are
+// masked in the request payload. If soapOperation is true, the operations of the SOAP and XML-RPC
+// requests are added to the content key, and the faults of the responses are taken as errors. If
+// elasticsearch is true, the operations and the indices of the Elasticsearch requests are taken as the
+// content key. If influxdb is true, the measurements and the points of the InfluxDB writes are read from
+// the line protocol in the body. The type and the name of the GraphQL operations sent to the graphqlPaths
+// are added to the content key.
+func NewHttpParser(urlClusteringMethod string, sessionCookie string, maskedHeaders []string, soapOperation bool,
+	elasticsearch bool, influxdb bool, graphqlPaths []string) *protocol.ProtocolParser {
 	method := urlclustering.NewMethod(urlClusteringMethod)
-	requestParser := protocol.CreatePkgParser(fastfailHttpRequest(), parseHttpRequest(method))
-	responseParser := protocol.CreatePkgParser(fastfailHttpResponse(), parseHttpResponse())
+	var maskedHeaderSet map[string]bool
+	if len(maskedHeaders) > 0 {
+		maskedHeaderSet = make(map[string]bool, len(maskedHeaders))
+		for _, name := range maskedHeaders {
+			maskedHeaderSet[strings.ToLower(name)] = true
+		}
+	}
+	var graphqlPathSet map[string]bool
+	if len(graphqlPaths) > 0 {
+		graphqlPathSet = make(map[string]bool, len(graphqlPaths))
+		for _, path := range graphqlPaths {
+			graphqlPathSet[path] = true
+		}
+	}
+	requestParser := protocol.CreatePkgParser(fastfailHttpRequest(), parseHttpRequest(method, sessionCookie, maskedHeaderSet, soapOperation,
+		elasticsearch, influxdb, graphqlPathSet))
+	responseParser := protocol.CreatePkgParser(fastfailHttpResponse(), parseHttpResponse(soapOperation))
 
-	return protocol.NewProtocolParser(protocol.HTTP, requestParser, responseParser, nil)
+	parser := protocol.NewProtocolParser(protocol.HTTP, requestParser, responseParser, nil)
+	// The request line and the headers are parsed, as well as the beginning of the Elasticsearch responses.
+	// The operations of SOAP may be deep in the body, and the line protocol of InfluxDB fills the body.
+	// The operationName of GraphQL may follow a long query.
+	if !soapOperation && !influxdb && graphqlPathSet == nil {
+		parser.SetMinCaptureLength(minCaptureLength)
+	}
+	return parser
 }
 
 /*
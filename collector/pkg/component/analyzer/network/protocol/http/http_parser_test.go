@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
 )
 
 func Test_urlMerge(t *testing.T) {
@@ -140,3 +141,57 @@ func Test_parseHeaders(t *testing.T) {
 		})
 	}
 }
+
+func TestGetCookie(t *testing.T) {
+	header := "theme=dark; JSESSIONID=5F2A9C; route=backend-2"
+	tests := []struct {
+		name      string
+		cookie    string
+		wantValue string
+		wantOk    bool
+	}{
+		{name: "first", cookie: "theme", wantValue: "dark", wantOk: true},
+		{name: "middle", cookie: "JSESSIONID", wantValue: "5F2A9C", wantOk: true},
+		{name: "last", cookie: "route", wantValue: "backend-2", wantOk: true},
+		{name: "prefix of another cookie", cookie: "JSESSION", wantOk: false},
+		{name: "not found", cookie: "SESSION", wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, ok := getCookie(header, tt.cookie)
+			if value != tt.wantValue || ok != tt.wantOk {
+				t.Errorf("getCookie() = (%v, %v), want (%v, %v)", value, ok, tt.wantValue, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestParseHttpRequest_SessionHash(t *testing.T) {
+	data := []byte("GET /cart HTTP/1.1\r\nHost: shop\r\nCookie: theme=dark; JSESSIONID=5F2A9C\r\n\r\n")
+	message := protocol.NewRequestMessage(data)
+	NewHttpParser("alphabet", "JSESSIONID", nil, false, false, false, nil).ParseRequest(message)
+	got := message.GetAttributes().GetStringValue(constlabels.HttpSessionHash)
+	if got != hashSessionId("5F2A9C") || len(got) != 16 {
+		t.Errorf("http_session_hash = %v, want %v", got, hashSessionId("5F2A9C"))
+	}
+
+	message = protocol.NewRequestMessage(data)
+	NewHttpParser("alphabet", "", nil, false, false, false, nil).ParseRequest(message)
+	if message.GetAttributes().HasAttribute(constlabels.HttpSessionHash) {
+		t.Errorf("http_session_hash should not be added if the cookie is not configured")
+	}
+}
+
+func TestParseHttpRequest_MaskHeaders(t *testing.T) {
+	data := []byte("GET /cart HTTP/1.1\r\nHost: shop\r\nauthorization: Bearer abc\r\nCookie: JSESSIONID=5F2A9C\r\n\r\n")
+	message := protocol.NewRequestMessage(data)
+	NewHttpParser("alphabet", "JSESSIONID", []string{"Authorization", "Cookie"}, false, false, false, nil).ParseRequest(message)
+	want := "GET /cart HTTP/1.1\r\nHost: shop\r\nauthorization: **********\r\nCookie: *****************\r\n\r\n"
+	if string(data) != want {
+		t.Errorf("payload = %q, want %q", data, want)
+	}
+	// The session is hashed before the cookie is masked.
+	if got := message.GetAttributes().GetStringValue(constlabels.HttpSessionHash); got != hashSessionId("5F2A9C") {
+		t.Errorf("http_session_hash = %v, want %v", got, hashSessionId("5F2A9C"))
+	}
+}
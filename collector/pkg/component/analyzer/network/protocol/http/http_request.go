@@ -29,7 +29,8 @@ Request line
 Request header
 Request body
 */
-func parseHttpRequest(urlClusteringMethod urlclustering.ClusteringMethod) protocol.ParsePkgFn {
+func parseHttpRequest(urlClusteringMethod urlclustering.ClusteringMethod, sessionCookie string, maskedHeaders map[string]bool,
+	soapOperation bool, elasticsearch bool, influxdb bool, graphqlPaths map[string]bool) protocol.ParsePkgFn {
 	return func(message *protocol.PayloadMessage) (bool, bool) {
 		offset, method := message.ReadUntilBlankWithLength(message.Offset, 8)
 
@@ -45,22 +46,77 @@ func parseHttpRequest(urlClusteringMethod urlclustering.ClusteringMethod) protoc
 			}
 		}
 
-		_, url := message.ReadUntilBlank(offset)
+		offset, url := message.ReadUntilBlank(offset)
+		if _, version := message.ReadUntilCRLF(offset); httpVersoinList[string(version)] {
+			message.AddStringAttribute(constlabels.ProtocolVersion, strings.TrimPrefix(string(version), "HTTP/"))
+		}
 
 		headers := parseHeaders(message)
+		if maskedHeaders != nil {
+			maskHeaders(message, maskedHeaders)
+		}
 		traceType, traceId := tools.ParseTraceHeader(headers)
 		if len(traceType) > 0 && len(traceId) > 0 {
 			message.AddStringAttribute(constlabels.HttpApmTraceType, traceType)
 			message.AddStringAttribute(constlabels.HttpApmTraceId, traceId)
 		}
+		if contentType := protocol.NormalizeContentType(headers["content-type"]); contentType != "" {
+			message.AddStringAttribute(constlabels.ContentType, contentType)
+		}
+		if userAgent, ok := headers["user-agent"]; ok {
+			message.AddStringAttribute(constlabels.HttpUserAgent, userAgent)
+		}
+		if sessionCookie != "" {
+			if sessionId, ok := getCookie(headers["cookie"], sessionCookie); ok {
+				message.AddStringAttribute(constlabels.HttpSessionHash, hashSessionId(sessionId))
+			}
+		}
 
 		message.AddStringAttribute(constlabels.HttpMethod, string(method))
 		message.AddByteArrayUtf8Attribute(constlabels.HttpUrl, url)
+		addServiceDiscoveryAttributes(message, string(method), string(url))
 
 		contentKey := urlClusteringMethod.Clustering(string(url))
 		if len(contentKey) == 0 {
 			contentKey = "*"
 		}
+		if soapOperation {
+			// The SOAP services usually share one endpoint, so the operation tells the requests apart.
+			if operation := getSoapOperation(message, string(method), headers); operation != "" {
+				message.AddUtf8StringAttribute(constlabels.HttpSoapOperation, operation)
+				contentKey += "#" + operation
+			}
+		}
+		if graphqlPaths != nil {
+			// The GraphQL requests share one endpoint, so the operation tells the requests apart.
+			if operation, ok := getGraphqlOperation(message, string(method), string(url), graphqlPaths); ok {
+				message.AddStringAttribute(constlabels.GraphqlOperationType, operation.operationType)
+				if operation.name != "" {
+					message.AddStringAttribute(constlabels.GraphqlOperation, operation.name)
+				}
+				contentKey += "#" + operation.getContentKeySuffix()
+			}
+		}
+		if elasticsearch {
+			// The indices are normalized, so the latency of the clients can be aggregated by the indices.
+			if operation, index := getEsRequest(string(method), string(url)); operation != "" {
+				message.AddStringAttribute(constlabels.EsOperation, operation)
+				contentKey = operation
+				if index != "" {
+					message.AddUtf8StringAttribute(constlabels.EsIndex, index)
+					contentKey += " " + index
+				}
+			}
+		}
+		if influxdb {
+			if write, ok := getInfluxWrite(message, string(method), string(url), headers); ok {
+				message.AddIntAttribute(constlabels.InfluxdbPoints, int64(write.points))
+				if len(write.measurements) > 0 {
+					message.AddUtf8StringAttribute(constlabels.InfluxdbMeasurements, write.getMeasurements())
+				}
+				contentKey = "write " + write.getMeasurementKey()
+			}
+		}
 		message.AddUtf8StringAttribute(constlabels.ContentKey, contentKey)
 		return true, true
 	}
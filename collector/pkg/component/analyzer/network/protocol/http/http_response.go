@@ -2,6 +2,7 @@ package http
 
 import (
 	"strconv"
+	"strings"
 
 	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
 	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/tools"
@@ -38,7 +39,9 @@ func fastfailHttpResponse() protocol.FastFailFn {
 	}
 }
 
-func parseHttpResponse() protocol.ParsePkgFn {
+// parseHttpResponse parses the status line and the headers. If soapOperation is true, the SOAP and
+// XML-RPC faults are taken as errors and their codes are added as the label "http_soap_fault".
+func parseHttpResponse(soapOperation bool) protocol.ParsePkgFn {
 	return func(message *protocol.PayloadMessage) (bool, bool) {
 		_, statusCode := message.ReadUntilBlankWithLength(message.Offset, 6)
 		statusCodeI, err := strconv.ParseInt(string(statusCode), 10, 0)
@@ -56,8 +59,24 @@ func parseHttpResponse() protocol.ParsePkgFn {
 			message.AddBoolAttribute(constlabels.HttpContinue, true)
 		}
 
+		var headers map[string]string
+		hasContentType := message.HasAttribute(constlabels.ContentType)
+		if !message.HasAttribute(constlabels.HttpApmTraceType) || !hasContentType || soapOperation || statusCodeI == 101 {
+			headers = parseHeaders(message)
+		}
+		if !hasContentType {
+			// The format of the response is taken if the request has no body, e.g. GET.
+			if contentType := protocol.NormalizeContentType(headers["content-type"]); contentType != "" {
+				message.AddStringAttribute(constlabels.ContentType, contentType)
+			}
+		}
+		if statusCodeI == 101 {
+			// The connection is parsed as the protocol switched to after the response.
+			if upgrade := strings.ToLower(strings.TrimSpace(headers["upgrade"])); upgrade != "" {
+				message.AddStringAttribute(constlabels.HttpUpgrade, upgrade)
+			}
+		}
 		if !message.HasAttribute(constlabels.HttpApmTraceType) {
-			headers := parseHeaders(message)
 			traceType, traceId := tools.ParseTraceHeader(headers)
 			if len(traceType) > 0 && len(traceId) > 0 {
 				message.AddStringAttribute(constlabels.HttpApmTraceType, traceType)
@@ -65,8 +84,20 @@ func parseHttpResponse() protocol.ParsePkgFn {
 			}
 		}
 
+		if message.HasAttribute(constlabels.EsOperation) {
+			addEsResponseAttributes(message)
+		}
+
+		isFault := false
+		if soapOperation {
+			var faultCode string
+			if faultCode, isFault = getSoapFault(message, headers); faultCode != "" {
+				message.AddUtf8StringAttribute(constlabels.HttpSoapFault, faultCode)
+			}
+		}
+
 		message.AddIntAttribute(constlabels.HttpStatusCode, statusCodeI)
-		if statusCodeI >= 400 {
+		if statusCodeI >= 400 || isFault {
 			message.AddBoolAttribute(constlabels.IsError, true)
 			message.AddIntAttribute(constlabels.ErrorType, int64(constlabels.ProtocolError))
 		}
@@ -0,0 +1,134 @@
+package http
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+)
+
+const (
+	// maxMeasurements limits the measurements recorded for a write, and the rest are omitted.
+	maxMeasurements = 10
+	// maxMeasurementLength limits the length of the measurement, which is dropped if longer.
+	maxMeasurementLength = 128
+	// maxInflatedLength limits the line protocol inflated from the gzipped body.
+	maxInflatedLength = 64 * 1024
+)
+
+// influxWritePaths are the write endpoints of InfluxDB 1.x and 2.x. The endpoint of 1.x is also served by 2.x.
+var influxWritePaths = map[string]bool{
+	"/write":        true,
+	"/api/v2/write": true,
+}
+
+// influxWrite is the summary of the line protocol written.
+type influxWrite struct {
+	// measurements are the distinct measurements in order.
+	measurements []string
+	points       int
+}
+
+// getInfluxWrite reads the line protocol of the write request sent with POST. The body gzipped by the clients
+// like Telegraf is inflated. Only the captured body is read, so the points are counted in it if the body is
+// truncated, and the last line truncated is counted as well.
+func getInfluxWrite(message *protocol.PayloadMessage, method string, url string, headers map[string]string) (*influxWrite, bool) {
+	if method != "POST" {
+		return nil, false
+	}
+	if index := strings.IndexByte(url, '?'); index >= 0 {
+		url = url[:index]
+	}
+	if !influxWritePaths[url] {
+		return nil, false
+	}
+	var body []byte
+	if bodyStart := bytes.Index(message.Data, []byte("\r\n\r\n")); bodyStart >= 0 {
+		body = message.Data[bodyStart+4:]
+	}
+	if strings.EqualFold(headers["content-encoding"], "gzip") {
+		body = inflate(body)
+	}
+	return parseLineProtocol(body), true
+}
+
+// inflate returns the data inflated before the gzip stream ends or is truncated.
+func inflate(body []byte) []byte {
+	reader, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		return nil
+	}
+	var inflated bytes.Buffer
+	// The error is ignored as the stream is usually truncated.
+	_, _ = io.Copy(&inflated, io.LimitReader(reader, maxInflatedLength))
+	return inflated.Bytes()
+}
+
+// parseLineProtocol counts the points and collects the measurements, e.g. "cpu" of
+// "cpu,host=a usage_idle=90 1700000000000000000". The comments and the empty lines are skipped.
+func parseLineProtocol(data []byte) *influxWrite {
+	write := &influxWrite{}
+	seen := make(map[string]bool)
+	for len(data) > 0 {
+		var line []byte
+		if end := bytes.IndexByte(data, '\n'); end >= 0 {
+			line, data = data[:end], data[end+1:]
+		} else {
+			line, data = data, nil
+		}
+		line = bytes.TrimLeft(line, " \t\r")
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+		write.points++
+		measurement := readMeasurement(line)
+		if measurement == "" || seen[measurement] {
+			continue
+		}
+		seen[measurement] = true
+		if len(write.measurements) < maxMeasurements {
+			write.measurements = append(write.measurements, measurement)
+		}
+	}
+	return write
+}
+
+// readMeasurement returns the measurement before the first unescaped comma or space.
+func readMeasurement(line []byte) string {
+	var measurement []byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		if c == '\\' && i+1 < len(line) {
+			i++
+			measurement = append(measurement, line[i])
+			continue
+		}
+		if c == ',' || c == ' ' {
+			break
+		}
+		measurement = append(measurement, c)
+	}
+	if len(measurement) > maxMeasurementLength {
+		return ""
+	}
+	return string(measurement)
+}
+
+// getMeasurementKey returns the measurement of the write for the content key, or "*" if multiple
+// measurements are written at once, which keeps the cardinality of the batches low.
+func (w *influxWrite) getMeasurementKey() string {
+	if len(w.measurements) == 1 {
+		return w.measurements[0]
+	}
+	return "*"
+}
+
+// getMeasurements returns the sorted measurements joined with ",".
+func (w *influxWrite) getMeasurements() string {
+	measurements := append([]string(nil), w.measurements...)
+	sort.Strings(measurements)
+	return strings.Join(measurements, ",")
+}
@@ -0,0 +1,104 @@
+package http
+
+import (
+	"bytes"
+	"compress/gzip"
+	"strings"
+	"testing"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+func gzipped(t *testing.T, data string) string {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write([]byte(data)); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestParseHttpRequest_Influxdb(t *testing.T) {
+	batch := strings.Repeat("cpu,host=a usage_idle=90 1700000000000000000\nmem,host=a used=1024i 1700000000000000000\n", 50)
+	compressed := gzipped(t, batch)
+	tests := []struct {
+		name             string
+		data             string
+		wantPoints       int64
+		wantMeasurements string
+		wantContentKey   string
+	}{
+		{
+			name: "v1 write",
+			data: "POST /write?db=telegraf&precision=ns HTTP/1.1\r\nContent-Type: text/plain\r\n\r\n" +
+				"cpu,host=a usage_idle=90 1700000000000000000\n\n# comment\ncpu,host=b usage_idle=80 1700000000000000000\n",
+			wantPoints:       2,
+			wantMeasurements: "cpu",
+			wantContentKey:   "write cpu",
+		},
+		{
+			name: "v2 write with escaped measurement",
+			data: "POST /api/v2/write?org=ops&bucket=metrics HTTP/1.1\r\n\r\n" +
+				"disk\\ io,dev=sda reads=1i\nnet\\,stat bytes=2i\ndisk\\ io,dev=sdb reads=3i",
+			wantPoints:       3,
+			wantMeasurements: "disk io,net,stat",
+			wantContentKey:   "write *",
+		},
+		{
+			name:             "gzipped",
+			data:             "POST /api/v2/write?bucket=metrics HTTP/1.1\r\nContent-Encoding: gzip\r\n\r\n" + compressed,
+			wantPoints:       100,
+			wantMeasurements: "cpu,mem",
+			wantContentKey:   "write *",
+		},
+		{
+			name:           "empty body",
+			data:           "POST /write?db=telegraf HTTP/1.1\r\nContent-Length: 0\r\n\r\n",
+			wantContentKey: "write *",
+		},
+		{
+			name:           "query",
+			data:           "GET /query?db=telegraf&q=SHOW+DATABASES HTTP/1.1\r\n\r\n",
+			wantContentKey: "/query",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message := protocol.NewRequestMessage([]byte(tt.data))
+			if !NewHttpParser("alphabet", "", nil, false, false, true, nil).ParseRequest(message) {
+				t.Fatal("failed to parse the request")
+			}
+			attributes := message.GetAttributes()
+			if got := attributes.GetIntValue(constlabels.InfluxdbPoints); got != tt.wantPoints {
+				t.Errorf("influxdb_points = %v, want %v", got, tt.wantPoints)
+			}
+			if got := attributes.GetStringValue(constlabels.InfluxdbMeasurements); got != tt.wantMeasurements {
+				t.Errorf("influxdb_measurements = %v, want %v", got, tt.wantMeasurements)
+			}
+			if got := attributes.GetStringValue(constlabels.ContentKey); got != tt.wantContentKey {
+				t.Errorf("content_key = %v, want %v", got, tt.wantContentKey)
+			}
+		})
+	}
+}
+
+func TestParseHttpRequest_InfluxdbDisabled(t *testing.T) {
+	message := protocol.NewRequestMessage([]byte("POST /write?db=telegraf HTTP/1.1\r\n\r\ncpu usage_idle=90\n"))
+	NewHttpParser("alphabet", "", nil, false, false, false, nil).ParseRequest(message)
+	if message.GetAttributes().HasAttribute(constlabels.InfluxdbPoints) {
+		t.Errorf("influxdb_points should not be added if it is disabled")
+	}
+}
+
+func TestInflateTruncated(t *testing.T) {
+	data := strings.Repeat("cpu,host=a usage_idle=90 1700000000000000000\n", 2000)
+	compressed := gzipped(t, data)
+	inflated := inflate([]byte(compressed[:len(compressed)/2]))
+	if len(inflated) == 0 || !strings.HasPrefix(data, string(inflated)) {
+		t.Errorf("inflated %d bytes, want the prefix of the data", len(inflated))
+	}
+}
@@ -0,0 +1,29 @@
+package http
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+)
+
+// maskHeaders masks the values of the headers whose lower-case names are in the set.
+func maskHeaders(message *protocol.PayloadMessage, names map[string]bool) {
+	from, data := message.ReadUntilCRLF(0)
+	if data == nil {
+		return
+	}
+	for {
+		from, data = message.ReadUntilCRLF(from)
+		if data == nil {
+			return
+		}
+		position := bytes.IndexByte(data, ':')
+		if position <= 0 {
+			return
+		}
+		if names[strings.ToLower(string(data[:position]))] {
+			protocol.MaskBytes(bytes.TrimLeft(data[position+1:], " "))
+		}
+	}
+}
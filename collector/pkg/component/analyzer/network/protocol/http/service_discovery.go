@@ -0,0 +1,122 @@
+package http
+
+import (
+	"strings"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+const (
+	consul = "consul"
+	etcd   = "etcd"
+
+	opCatalogQuery = "catalog_query"
+	opCatalogWrite = "catalog_write"
+	opHealthQuery  = "health_query"
+	opAgent        = "agent"
+	opSession      = "session"
+	opKvGet        = "kv_get"
+	opKvPut        = "kv_put"
+	opKvDelete     = "kv_delete"
+	opKvTxn        = "kv_txn"
+	opLease        = "lease"
+	opWatch        = "watch"
+)
+
+// addServiceDiscoveryAttributes labels the requests sent to the HTTP APIs of Consul and etcd with the
+// system and the operation, so the control-plane dependencies are distinct from the application traffic.
+// The native gRPC API of etcd v3 is HTTP/2, which is not parsed here. Only its JSON gateway is recognized.
+func addServiceDiscoveryAttributes(message *protocol.PayloadMessage, method string, url string) {
+	system, op := classifyServiceDiscovery(method, url)
+	if system == "" {
+		return
+	}
+	message.AddStringAttribute(constlabels.ServiceDiscovery, system)
+	message.AddStringAttribute(constlabels.ServiceDiscoveryOp, op)
+}
+
+func classifyServiceDiscovery(method string, url string) (system string, op string) {
+	path, query := url, ""
+	if index := strings.Index(url, "?"); index != -1 {
+		path, query = url[:index], url[index+1:]
+	}
+	switch {
+	case strings.HasPrefix(path, "/v1/"):
+		return classifyConsul(method, path, query)
+	case strings.HasPrefix(path, "/v2/keys"):
+		return etcd, classifyKv(method, hasQueryParam(query, "wait=true"))
+	case strings.HasPrefix(path, "/v3/"), strings.HasPrefix(path, "/v3beta/"), strings.HasPrefix(path, "/v3alpha/"):
+		return classifyEtcdGateway(path[strings.Index(path[1:], "/")+1:])
+	}
+	return "", ""
+}
+
+// classifyConsul recognizes the Consul APIs. The blocking queries with the parameter "index" are watches.
+func classifyConsul(method string, path string, query string) (string, string) {
+	// Other systems like Vault use the prefix "/v1/" as well, so only the known endpoints are recognized.
+	var op string
+	switch {
+	case strings.HasPrefix(path, "/v1/catalog/register"), strings.HasPrefix(path, "/v1/catalog/deregister"):
+		op = opCatalogWrite
+	case strings.HasPrefix(path, "/v1/catalog/"):
+		op = opCatalogQuery
+	case strings.HasPrefix(path, "/v1/health/"):
+		op = opHealthQuery
+	case strings.HasPrefix(path, "/v1/kv/"):
+		op = classifyKv(method, false)
+	case strings.HasPrefix(path, "/v1/agent/"):
+		op = opAgent
+	case strings.HasPrefix(path, "/v1/session/"):
+		op = opSession
+	case path == "/v1/txn":
+		op = opKvTxn
+	default:
+		return "", ""
+	}
+	if method == "GET" && hasQueryParam(query, "index") {
+		op = opWatch
+	}
+	return consul, op
+}
+
+// classifyEtcdGateway recognizes the JSON gateway of etcd v3, whose path is without the version prefix.
+func classifyEtcdGateway(path string) (string, string) {
+	switch {
+	case path == "/kv/range":
+		return etcd, opKvGet
+	case path == "/kv/put":
+		return etcd, opKvPut
+	case path == "/kv/deleterange":
+		return etcd, opKvDelete
+	case path == "/kv/txn":
+		return etcd, opKvTxn
+	case path == "/watch":
+		return etcd, opWatch
+	case strings.HasPrefix(path, "/lease/"), strings.HasPrefix(path, "/kv/lease/"):
+		return etcd, opLease
+	}
+	return "", ""
+}
+
+func classifyKv(method string, watch bool) string {
+	switch method {
+	case "PUT", "POST":
+		return opKvPut
+	case "DELETE":
+		return opKvDelete
+	}
+	if watch {
+		return opWatch
+	}
+	return opKvGet
+}
+
+func hasQueryParam(query string, name string) bool {
+	for _, param := range strings.Split(query, "&") {
+		if param == name || strings.HasPrefix(param, name+"=") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package http
+
+import (
+	"testing"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+func TestClassifyServiceDiscovery(t *testing.T) {
+	tests := []struct {
+		method     string
+		url        string
+		wantSystem string
+		wantOp     string
+	}{
+		{"GET", "/v1/catalog/service/web", consul, opCatalogQuery},
+		{"PUT", "/v1/catalog/register", consul, opCatalogWrite},
+		{"GET", "/v1/health/service/web?passing=1", consul, opHealthQuery},
+		{"GET", "/v1/health/service/web?index=1024&wait=5m", consul, opWatch},
+		{"GET", "/v1/kv/config/app", consul, opKvGet},
+		{"PUT", "/v1/kv/config/app", consul, opKvPut},
+		{"DELETE", "/v1/kv/config/app?recurse", consul, opKvDelete},
+		{"PUT", "/v1/agent/service/register", consul, opAgent},
+		{"GET", "/v1/secret/data/app", "", ""},
+		{"GET", "/v2/keys/config", etcd, opKvGet},
+		{"GET", "/v2/keys/config?wait=true", etcd, opWatch},
+		{"PUT", "/v2/keys/config", etcd, opKvPut},
+		{"POST", "/v3/kv/range", etcd, opKvGet},
+		{"POST", "/v3beta/kv/put", etcd, opKvPut},
+		{"POST", "/v3/kv/deleterange", etcd, opKvDelete},
+		{"POST", "/v3/watch", etcd, opWatch},
+		{"POST", "/v3/lease/grant", etcd, opLease},
+		{"GET", "/v3/users", "", ""},
+		{"GET", "/api/v1/namespaces", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.url, func(t *testing.T) {
+			system, op := classifyServiceDiscovery(tt.method, tt.url)
+			if system != tt.wantSystem || op != tt.wantOp {
+				t.Errorf("classifyServiceDiscovery() = (%v, %v), want (%v, %v)", system, op, tt.wantSystem, tt.wantOp)
+			}
+		})
+	}
+}
+
+func TestParseHttpRequest_ServiceDiscovery(t *testing.T) {
+	message := protocol.NewRequestMessage([]byte("GET /v1/catalog/services HTTP/1.1\r\nHost: consul:8500\r\n\r\n"))
+	NewHttpParser("alphabet", "", nil, false, false, false, nil).ParseRequest(message)
+	attributes := message.GetAttributes()
+	if attributes.GetStringValue(constlabels.ServiceDiscovery) != consul ||
+		attributes.GetStringValue(constlabels.ServiceDiscoveryOp) != opCatalogQuery {
+		t.Errorf("service discovery labels = %v", attributes.ToStringMap())
+	}
+
+	message = protocol.NewRequestMessage([]byte("GET /cart HTTP/1.1\r\nHost: shop\r\n\r\n"))
+	NewHttpParser("alphabet", "", nil, false, false, false, nil).ParseRequest(message)
+	if message.GetAttributes().HasAttribute(constlabels.ServiceDiscovery) {
+		t.Errorf("service_discovery should not be added to the application requests")
+	}
+}
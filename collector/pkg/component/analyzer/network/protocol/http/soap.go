@@ -0,0 +1,136 @@
+package http
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+)
+
+// maxOperationLength limits the length of the operation, which is dropped if longer.
+const maxOperationLength = 128
+
+var (
+	// soapBodyChild matches the first child of the SOAP Body, which is the operation in the document/literal
+	// wrapped and RPC styles. The namespace prefixes are ignored.
+	soapBodyChild = regexp.MustCompile(`<(?:[\w.-]+:)?Body\b[^>]*>\s*<(?:[\w.-]+:)?([\w.-]+)`)
+	// xmlRpcMethodName matches the methodName of the XML-RPC methodCall.
+	xmlRpcMethodName = regexp.MustCompile(`<methodName>\s*([^<\s]+)\s*</methodName>`)
+
+	// soapFault matches the Fault of the SOAP Body.
+	soapFault = regexp.MustCompile(`<(?:[\w.-]+:)?Body\b[^>]*>\s*<(?:[\w.-]+:)?Fault\b`)
+	// soap11FaultCode and soap12FaultCode match the qualified fault codes of SOAP 1.1 and SOAP 1.2.
+	soap11FaultCode = regexp.MustCompile(`<faultcode\b[^>]*>\s*([^<\s]+)\s*</faultcode>`)
+	soap12FaultCode = regexp.MustCompile(`<(?:[\w.-]+:)?Code\b[^>]*>\s*<(?:[\w.-]+:)?Value\b[^>]*>\s*([^<\s]+)`)
+	// xmlRpcFault matches the fault of the XML-RPC methodResponse and xmlRpcFaultCode matches its faultCode.
+	xmlRpcFault     = regexp.MustCompile(`<methodResponse>\s*<fault>`)
+	xmlRpcFaultCode = regexp.MustCompile(`<name>\s*faultCode\s*</name>\s*<value>\s*(?:<(?:int|i4)>)?\s*(-?\d+)`)
+)
+
+// getSoapOperation returns the operation of the SOAP or XML-RPC request sent with POST. The action of
+// SOAP 1.1 is in the header SOAPAction and the one of SOAP 1.2 is the parameter "action" of Content-Type.
+// The last segment of the action URI is the operation. The body is read if the action is absent.
+func getSoapOperation(message *protocol.PayloadMessage, method string, headers map[string]string) string {
+	if method != "POST" {
+		return ""
+	}
+	contentType := strings.ToLower(headers["content-type"])
+	if !strings.Contains(contentType, "xml") {
+		return ""
+	}
+	if action, ok := headers["soapaction"]; ok {
+		if operation := getActionOperation(action); operation != "" {
+			return operation
+		}
+	}
+	if strings.Contains(contentType, "application/soap+xml") {
+		if operation := getActionOperation(getContentTypeParam(headers["content-type"], "action")); operation != "" {
+			return operation
+		}
+	}
+
+	bodyStart := bytes.Index(message.Data, []byte("\r\n\r\n"))
+	if bodyStart < 0 {
+		return ""
+	}
+	body := message.Data[bodyStart+4:]
+	if matches := soapBodyChild.FindSubmatch(body); matches != nil {
+		return validOperation(string(matches[1]))
+	}
+	if matches := xmlRpcMethodName.FindSubmatch(body); matches != nil {
+		return validOperation(string(matches[1]))
+	}
+	return ""
+}
+
+// getActionOperation returns the last segment of the action URI, e.g. "GetQuote" for
+// "http://example.com/StockQuote#GetQuote" and "urn:GetQuote".
+func getActionOperation(action string) string {
+	action = strings.Trim(strings.TrimSpace(action), `"`)
+	if index := strings.LastIndexAny(action, "/#:"); index >= 0 {
+		action = action[index+1:]
+	}
+	return validOperation(action)
+}
+
+// getContentTypeParam returns the value of the parameter of Content-Type, e.g. the action of
+// `application/soap+xml; charset=utf-8; action="urn:GetQuote"`.
+func getContentTypeParam(contentType string, name string) string {
+	for _, param := range strings.Split(contentType, ";") {
+		key, value, found := strings.Cut(strings.TrimSpace(param), "=")
+		if found && strings.EqualFold(key, name) {
+			return strings.Trim(value, `"`)
+		}
+	}
+	return ""
+}
+
+// validOperation drops the operations holding other characters than the ones of the XML names,
+// so the label is not polluted by the malformed requests.
+func validOperation(operation string) string {
+	if operation == "" || len(operation) > maxOperationLength {
+		return ""
+	}
+	for i := 0; i < len(operation); i++ {
+		c := operation[i]
+		if !('a' <= c && c <= 'z') && !('A' <= c && c <= 'Z') && !('0' <= c && c <= '9') && c != '_' && c != '-' && c != '.' {
+			return ""
+		}
+	}
+	return operation
+}
+
+// getSoapFault returns the fault code of the SOAP or XML-RPC response if the response is a fault, e.g.
+// "Server" of SOAP 1.1, "Receiver" of SOAP 1.2 and "4" of XML-RPC. The namespace prefixes of the SOAP
+// codes are dropped. The code is empty if it is not found in the captured body. The faults of XML-RPC are
+// sent with the status 200, so they are found by the body only.
+func getSoapFault(message *protocol.PayloadMessage, headers map[string]string) (string, bool) {
+	if !strings.Contains(strings.ToLower(headers["content-type"]), "xml") {
+		return "", false
+	}
+	bodyStart := bytes.Index(message.Data, []byte("\r\n\r\n"))
+	if bodyStart < 0 {
+		return "", false
+	}
+	body := message.Data[bodyStart+4:]
+	if soapFault.Match(body) {
+		for _, pattern := range []*regexp.Regexp{soap11FaultCode, soap12FaultCode} {
+			if matches := pattern.FindSubmatch(body); matches != nil {
+				code := string(matches[1])
+				if index := strings.LastIndexByte(code, ':'); index >= 0 {
+					code = code[index+1:]
+				}
+				return validOperation(code), true
+			}
+		}
+		return "", true
+	}
+	if xmlRpcFault.Match(body) {
+		if matches := xmlRpcFaultCode.FindSubmatch(body); matches != nil {
+			return string(matches[1]), true
+		}
+		return "", true
+	}
+	return "", false
+}
@@ -0,0 +1,159 @@
+package http
+
+import (
+	"testing"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+func TestParseHttpRequest_SoapOperation(t *testing.T) {
+	tests := []struct {
+		name           string
+		data           string
+		wantOperation  string
+		wantContentKey string
+	}{
+		{
+			name: "SOAP 1.1 action",
+			data: "POST /ws/StockService HTTP/1.1\r\nContent-Type: text/xml; charset=utf-8\r\n" +
+				"SOAPAction: \"http://example.com/StockQuote#GetQuote\"\r\n\r\n<soap:Envelope>",
+			wantOperation:  "GetQuote",
+			wantContentKey: "/ws/StockService#GetQuote",
+		},
+		{
+			name: "SOAP 1.1 empty action",
+			data: "POST /ws HTTP/1.1\r\nContent-Type: text/xml\r\nSOAPAction: \"\"\r\n\r\n" +
+				"<soapenv:Envelope xmlns:soapenv=\"http://schemas.xmlsoap.org/soap/envelope/\"><soapenv:Header/>\n" +
+				"<soapenv:Body xmlns:m=\"http://example.com/stock\">\n  <m:GetPrice><m:Item>Apple</m:Item></m:GetPrice>",
+			wantOperation:  "GetPrice",
+			wantContentKey: "/ws#GetPrice",
+		},
+		{
+			name: "SOAP 1.2 action",
+			data: "POST /ws HTTP/1.1\r\nContent-Type: application/soap+xml; charset=utf-8; action=\"urn:CancelOrder\"\r\n\r\n" +
+				"<env:Envelope>",
+			wantOperation:  "CancelOrder",
+			wantContentKey: "/ws#CancelOrder",
+		},
+		{
+			name: "XML-RPC",
+			data: "POST /RPC2 HTTP/1.0\r\nContent-Type: text/xml\r\n\r\n" +
+				"<?xml version=\"1.0\"?>\n<methodCall>\n  <methodName>examples.getStateName</methodName>",
+			wantOperation:  "examples.getStateName",
+			wantContentKey: "/*#examples.getStateName",
+		},
+		{
+			name:           "JSON",
+			data:           "POST /ws HTTP/1.1\r\nContent-Type: application/json\r\n\r\n{\"methodName\":\"a\"}",
+			wantContentKey: "/ws",
+		},
+		{
+			name:           "GET",
+			data:           "GET /ws?wsdl HTTP/1.1\r\nContent-Type: text/xml\r\nSOAPAction: GetQuote\r\n\r\n",
+			wantContentKey: "/ws",
+		},
+		{
+			name:           "invalid operation",
+			data:           "POST /ws HTTP/1.1\r\nContent-Type: text/xml\r\nSOAPAction: \"Get Quote\"\r\n\r\n",
+			wantContentKey: "/ws",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message := protocol.NewRequestMessage([]byte(tt.data))
+			NewHttpParser("alphabet", "", nil, true, false, false, nil).ParseRequest(message)
+			attributes := message.GetAttributes()
+			if got := attributes.GetStringValue(constlabels.HttpSoapOperation); got != tt.wantOperation {
+				t.Errorf("http_soap_operation = %v, want %v", got, tt.wantOperation)
+			}
+			if got := attributes.GetStringValue(constlabels.ContentKey); got != tt.wantContentKey {
+				t.Errorf("content_key = %v, want %v", got, tt.wantContentKey)
+			}
+		})
+	}
+
+	message := protocol.NewRequestMessage([]byte(tests[0].data))
+	NewHttpParser("alphabet", "", nil, false, false, false, nil).ParseRequest(message)
+	if message.GetAttributes().HasAttribute(constlabels.HttpSoapOperation) {
+		t.Errorf("http_soap_operation should not be added if it is not enabled")
+	}
+}
+
+func TestParseHttpResponse_SoapFault(t *testing.T) {
+	tests := []struct {
+		name          string
+		data          string
+		wantFault     string
+		wantError     bool
+		soapOperation bool
+	}{
+		{
+			name: "SOAP 1.1 fault",
+			data: "HTTP/1.1 500 Internal Server Error\r\nContent-Type: text/xml; charset=utf-8\r\n\r\n" +
+				"<soap:Envelope xmlns:soap=\"http://schemas.xmlsoap.org/soap/envelope/\"><soap:Body>\n" +
+				"<soap:Fault><faultcode>soap:Server</faultcode><faultstring>Timeout</faultstring></soap:Fault>",
+			wantFault:     "Server",
+			wantError:     true,
+			soapOperation: true,
+		},
+		{
+			name: "SOAP 1.2 fault",
+			data: "HTTP/1.1 500 Internal Server Error\r\nContent-Type: application/soap+xml\r\n\r\n" +
+				"<env:Envelope><env:Body><env:Fault><env:Code><env:Value>env:Sender</env:Value>",
+			wantFault:     "Sender",
+			wantError:     true,
+			soapOperation: true,
+		},
+		{
+			name: "truncated SOAP fault",
+			data: "HTTP/1.1 200 OK\r\nContent-Type: text/xml\r\n\r\n" +
+				"<soap:Envelope><soap:Body><soap:Fault>",
+			wantError:     true,
+			soapOperation: true,
+		},
+		{
+			name: "XML-RPC fault",
+			data: "HTTP/1.1 200 OK\r\nContent-Type: text/xml\r\n\r\n<?xml version=\"1.0\"?>\n<methodResponse>\n  <fault>\n" +
+				"<value><struct><member><name>faultCode</name><value><int>4</int></value></member>",
+			wantFault:     "4",
+			wantError:     true,
+			soapOperation: true,
+		},
+		{
+			name: "SOAP response",
+			data: "HTTP/1.1 200 OK\r\nContent-Type: text/xml\r\n\r\n" +
+				"<soap:Envelope><soap:Body><m:GetPriceResponse><m:Fault>none</m:Fault>",
+			soapOperation: true,
+		},
+		{
+			name: "JSON",
+			data: "HTTP/1.1 200 OK\r\nContent-Type: application/json\r\n\r\n" +
+				"{\"body\":\"<soap:Body><soap:Fault>\"}",
+			soapOperation: true,
+		},
+		{
+			name: "not enabled",
+			data: "HTTP/1.1 200 OK\r\nContent-Type: text/xml\r\n\r\n" +
+				"<methodResponse><fault><value><struct><member><name>faultCode</name><value><int>4</int></value>",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := NewHttpParser("alphabet", "", nil, tt.soapOperation, false, false, nil)
+			request := protocol.NewRequestMessage([]byte("POST /ws HTTP/1.1\r\nContent-Type: text/xml\r\n\r\n"))
+			parser.ParseRequest(request)
+			response := protocol.NewResponseMessage([]byte(tt.data), request.GetAttributes())
+			if !parser.ParseResponse(response) {
+				t.Fatalf("failed to parse the response")
+			}
+			attributes := response.GetAttributes()
+			if got := attributes.GetStringValue(constlabels.HttpSoapFault); got != tt.wantFault {
+				t.Errorf("http_soap_fault = %v, want %v", got, tt.wantFault)
+			}
+			if got := attributes.GetBoolValue(constlabels.IsError); got != tt.wantError {
+				t.Errorf("is_error = %v, want %v", got, tt.wantError)
+			}
+		})
+	}
+}
@@ -130,3 +130,20 @@ func IsValidVersion(_api int, ver int) bool {
 	}
 	return version.minVersion <= ver && ver <= version.maxVersion
 }
+
+// The first flexible versions of the APIs, since which the strings and the arrays are compact and
+// the headers have the tagged fields. The APIs not listed here are not parsed beyond the headers.
+var flexibleVersions = map[int]int{
+	_apiProduce:      9,
+	_apiFetch:        12,
+	_apiMetadata:     9,
+	_apiOffsetCommit: 8,
+	_apiJoinGroup:    6,
+	_apiHeartbeat:    4,
+	_apiApiVersions:  3,
+}
+
+func isFlexible(_api int, ver int) bool {
+	version, ok := flexibleVersions[_api]
+	return ok && ver >= version
+}
@@ -5,19 +5,26 @@ import (
 )
 
 /*
-      Request                                         Response
-       /            \                                            /          \
-fetch   produce                               fetch   produce
+                       Request                                                    Response
+    /       /       |         |         \       \               /       /        |         \       \
+fetch produce metadata offset_commit group other           fetch produce offset_commit group other
+
+The group parsers read JoinGroup and Heartbeat.
 */
 func NewKafkaParser() *protocol.ProtocolParser {
 	requestParser := protocol.CreatePkgParser(fastfailRequest(), parseRequest())
 	requestParser.Add(fastfailRequestFetch(), parseRequestFetch())
 	requestParser.Add(fastfailRequestProduce(), parseRequestProduce())
+	requestParser.Add(fastfailRequestMetadata(), parseRequestMetadata())
+	requestParser.Add(fastfailRequestOffsetCommit(), parseRequestOffsetCommit())
+	requestParser.Add(fastfailRequestGroup(), parseRequestGroup())
 	requestParser.Add(fastfailRequestOther(), parseRequestOther())
 
 	responseParser := protocol.CreatePkgParser(fastfailResponse(), parseResponse())
 	responseParser.Add(fastfailResponseFetch(), parseResponseFetch())
 	responseParser.Add(fastfailResponseProduce(), parseResponseProduce())
+	responseParser.Add(fastfailResponseOffsetCommit(), parseResponseOffsetCommit())
+	responseParser.Add(fastfailResponseGroup(), parseResponseGroup())
 	responseParser.Add(fastfailResponseOther(), parseResponseOther())
 
 	parser := protocol.NewProtocolParser(protocol.KAFKA, requestParser, responseParser, nil)
@@ -1,6 +1,8 @@
 package kafka
 
 import (
+	"strconv"
+
 	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
 	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
 )
@@ -40,10 +42,57 @@ func parseRequest() protocol.ParsePkgFn {
 		if len(message.Data) < offset {
 			return false, true
 		}
+		// The request header v2 of the flexible versions has the tagged fields after the client_id.
+		if isFlexible(int(apiKey), int(apiVersion)) && len(message.Data) > offset {
+			var err error
+			if offset, err = skipTaggedFields(message, offset); err != nil {
+				return false, true
+			}
+		}
 		message.Offset = offset
 		message.AddIntAttribute(constlabels.KafkaApi, int64(apiKey))
 		message.AddIntAttribute(constlabels.KafkaVersion, int64(apiVersion))
+		message.AddStringAttribute(constlabels.ProtocolVersion, strconv.Itoa(int(apiVersion)))
 		message.AddIntAttribute(constlabels.KafkaCorrelationId, int64(correlationId))
 		return true, false
 	}
 }
+
+func skipTaggedFields(message *protocol.PayloadMessage, offset int) (int, error) {
+	var (
+		err      error
+		fieldNum uint64
+		tag      uint64
+		size     uint64
+	)
+	if offset, err = message.ReadUnsignedVarInt(offset, &fieldNum); err != nil {
+		return offset, err
+	}
+	for i := uint64(0); i < fieldNum; i++ {
+		if offset, err = message.ReadUnsignedVarInt(offset, &tag); err != nil {
+			return offset, err
+		}
+		if offset, err = message.ReadUnsignedVarInt(offset, &size); err != nil {
+			return offset, err
+		}
+		offset += int(size)
+	}
+	return offset, nil
+}
+
+// addFirstPartition reads the partitions of a topic and labels the first one, as only the first topic is read.
+// Nothing is labeled if the payload is truncated.
+func addFirstPartition(message *protocol.PayloadMessage, offset int, compact bool) {
+	var (
+		err          error
+		partitionNum int32
+		partition    int32
+	)
+	if offset, err = message.ReadArraySize(offset, compact, &partitionNum); err != nil || partitionNum <= 0 {
+		return
+	}
+	if _, err = message.ReadInt32(offset, &partition); err != nil {
+		return
+	}
+	message.AddIntAttribute(constlabels.KafkaPartition, int64(partition))
+}
@@ -38,7 +38,7 @@ func parseRequestFetch() protocol.ParsePkgFn {
 			return false, true
 		}
 		if topicNum > 0 {
-			if _, err = message.ReadString(offset, compact, &topicName); err != nil {
+			if offset, err = message.ReadString(offset, compact, &topicName); err != nil {
 				return false, true
 			}
 			/*
@@ -51,6 +51,8 @@ func parseRequestFetch() protocol.ParsePkgFn {
 				Since version 13, topicName will be repalced with topicId as uuid, therefore topicName is not able to be got.
 			*/
 			message.AddUtf8StringAttribute(constlabels.KafkaTopic, topicName)
+			message.AddUtf8StringAttribute(constlabels.ContentKey, topicName)
+			addFirstPartition(message, offset, compact)
 		}
 
 		return true, true
@@ -0,0 +1,28 @@
+package kafka
+
+import (
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+func fastfailRequestGroup() protocol.FastFailFn {
+	return func(message *protocol.PayloadMessage) bool {
+		api := message.GetIntAttribute(constlabels.KafkaApi)
+		return api != _apiJoinGroup && api != _apiHeartbeat
+	}
+}
+
+// parseRequestGroup reads the group_id, which is the first field of JoinGroup and Heartbeat.
+func parseRequestGroup() protocol.ParsePkgFn {
+	return func(message *protocol.PayloadMessage) (bool, bool) {
+		var groupId string
+		api := message.GetIntAttribute(constlabels.KafkaApi)
+		compact := isFlexible(int(api), int(message.GetIntAttribute(constlabels.KafkaVersion)))
+		if _, err := message.ReadString(message.Offset, compact, &groupId); err != nil {
+			return false, true
+		}
+		message.AddUtf8StringAttribute(constlabels.KafkaGroupId, groupId)
+		message.AddUtf8StringAttribute(constlabels.ContentKey, groupId)
+		return true, true
+	}
+}
@@ -0,0 +1,47 @@
+package kafka
+
+import (
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+const topicIdLength = 16
+
+func fastfailRequestMetadata() protocol.FastFailFn {
+	return func(message *protocol.PayloadMessage) bool {
+		return message.GetIntAttribute(constlabels.KafkaApi) != _apiMetadata
+	}
+}
+
+func parseRequestMetadata() protocol.ParsePkgFn {
+	return func(message *protocol.PayloadMessage) (bool, bool) {
+		var (
+			offset    int
+			err       error
+			topicNum  int32
+			topicName string
+		)
+		version := message.GetIntAttribute(constlabels.KafkaVersion)
+		compact := version >= 9
+		// The topics are null since version 1 if the metadata of all topics is requested.
+		if offset, err = message.ReadArraySize(message.Offset, compact, &topicNum); err != nil {
+			return false, true
+		}
+		if topicNum > 0 {
+			if version >= 10 {
+				offset += topicIdLength
+				// The name is null if the topic is specified by the topic_id.
+				if _, err = message.ReadNullableString(offset, compact, &topicName); err != nil {
+					return false, true
+				}
+			} else if _, err = message.ReadString(offset, compact, &topicName); err != nil {
+				return false, true
+			}
+			if topicName != "" {
+				message.AddUtf8StringAttribute(constlabels.KafkaTopic, topicName)
+				message.AddUtf8StringAttribute(constlabels.ContentKey, topicName)
+			}
+		}
+		return true, true
+	}
+}
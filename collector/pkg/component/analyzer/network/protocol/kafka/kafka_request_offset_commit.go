@@ -0,0 +1,60 @@
+package kafka
+
+import (
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+func fastfailRequestOffsetCommit() protocol.FastFailFn {
+	return func(message *protocol.PayloadMessage) bool {
+		return message.GetIntAttribute(constlabels.KafkaApi) != _apiOffsetCommit
+	}
+}
+
+func parseRequestOffsetCommit() protocol.ParsePkgFn {
+	return func(message *protocol.PayloadMessage) (bool, bool) {
+		var (
+			offset    int
+			err       error
+			groupId   string
+			memberId  string
+			topicNum  int32
+			topicName string
+		)
+		version := message.GetIntAttribute(constlabels.KafkaVersion)
+		compact := version >= 8
+		if offset, err = message.ReadString(message.Offset, compact, &groupId); err != nil {
+			return false, true
+		}
+		message.AddUtf8StringAttribute(constlabels.KafkaGroupId, groupId)
+		message.AddUtf8StringAttribute(constlabels.ContentKey, groupId)
+
+		if version >= 1 {
+			offset += 4 // generation_id
+			if offset, err = message.ReadString(offset, compact, &memberId); err != nil {
+				// The topics are not read if the payload is truncated.
+				return true, true
+			}
+		}
+		if version >= 7 {
+			var groupInstanceId string
+			if offset, err = message.ReadNullableString(offset, compact, &groupInstanceId); err != nil {
+				return true, true
+			}
+		}
+		if version >= 2 && version <= 4 {
+			offset += 8 // retention_time_ms
+		}
+		if offset, err = message.ReadArraySize(offset, compact, &topicNum); err != nil {
+			return true, true
+		}
+		if topicNum > 0 {
+			if offset, err = message.ReadString(offset, compact, &topicName); err != nil {
+				return true, true
+			}
+			message.AddUtf8StringAttribute(constlabels.KafkaTopic, topicName)
+			addFirstPartition(message, offset, compact)
+		}
+		return true, true
+	}
+}
@@ -1,6 +1,8 @@
 package kafka
 
 import (
+	"strings"
+
 	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
 	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
 )
@@ -35,12 +37,144 @@ func parseRequestProduce() protocol.ParsePkgFn {
 			return false, true
 		}
 		if topicNum > 0 {
-			if _, err = message.ReadString(offset, compact, &topicName); err != nil {
+			if offset, err = message.ReadString(offset, compact, &topicName); err != nil {
 				return false, true
 			}
 			// Get TopicName
 			message.AddUtf8StringAttribute(constlabels.KafkaTopic, topicName)
+			message.AddUtf8StringAttribute(constlabels.ContentKey, topicName)
+			addFirstPartition(message, offset, compact)
+			addRecordContentType(message, offset, compact)
 		}
 		return true, true
 	}
 }
+
+// The record batch v2 is compressed if any of the lowest 3 bits of the attributes is set.
+const recordBatchCompressionMask = 0x07
+
+/*
+addRecordContentType labels the content-type header of the first record in the first partition.
+Nothing is labeled if the records are compressed or the headers are beyond the captured payload.
+
+	int32 partition_index
+	bytes records
+		int64 base_offset
+		int32 batch_length
+		int32 partition_leader_epoch
+		int8  magic
+		int32 crc
+		int16 attributes
+		int32 last_offset_delta
+		int64 first_timestamp
+		int64 max_timestamp
+		int64 producer_id
+		int16 producer_epoch
+		int32 base_sequence
+		int32 records_count
+		records
+*/
+func addRecordContentType(message *protocol.PayloadMessage, offset int, compact bool) {
+	var (
+		err          error
+		partitionNum int32
+		recordsSize  int32
+	)
+	if offset, err = message.ReadArraySize(offset, compact, &partitionNum); err != nil || partitionNum <= 0 {
+		return
+	}
+	// partition_index
+	offset += 4
+	if compact {
+		var size uint64
+		if offset, err = message.ReadUnsignedVarInt(offset, &size); err != nil || size == 0 {
+			return
+		}
+		recordsSize = int32(size - 1)
+	} else if offset, err = message.ReadInt32(offset, &recordsSize); err != nil {
+		return
+	}
+	if recordsSize < 61 || offset+61 > len(message.Data) {
+		return
+	}
+	if message.Data[offset+16] != 2 || message.Data[offset+22]&recordBatchCompressionMask != 0 {
+		return
+	}
+	if contentType := protocol.NormalizeContentType(readRecordContentType(message, offset+61)); contentType != "" {
+		message.AddStringAttribute(constlabels.ContentType, contentType)
+	}
+}
+
+/*
+readRecordContentType returns the value of the content-type header of the record, which is named
+"content-type" or "contentType" by the clients.
+
+	varint length
+	int8   attributes
+	varint timestamp_delta
+	varint offset_delta
+	varint key_length
+	bytes  key
+	varint value_length
+	bytes  value
+	varint headers_count
+	headers
+		varint header_key_length
+		string header_key
+		varint header_value_length
+		bytes  header_value
+*/
+func readRecordContentType(message *protocol.PayloadMessage, offset int) string {
+	var (
+		err     error
+		value   int64
+		key     []byte
+		headers int64
+	)
+	// length
+	if offset, err = message.ReadVarInt(offset, &value); err != nil {
+		return ""
+	}
+	// attributes
+	offset += 1
+	// timestamp_delta, offset_delta
+	for i := 0; i < 2; i++ {
+		if offset, err = message.ReadVarInt(offset, &value); err != nil {
+			return ""
+		}
+	}
+	// key and value
+	for i := 0; i < 2; i++ {
+		if offset, err = message.ReadVarInt(offset, &value); err != nil {
+			return ""
+		}
+		if value > 0 {
+			offset += int(value)
+		}
+	}
+	if offset, err = message.ReadVarInt(offset, &headers); err != nil {
+		return ""
+	}
+	for i := int64(0); i < headers; i++ {
+		if offset, err = message.ReadVarInt(offset, &value); err != nil {
+			return ""
+		}
+		if offset, key, err = message.ReadBytes(offset, int(value)); err != nil {
+			return ""
+		}
+		if offset, err = message.ReadVarInt(offset, &value); err != nil {
+			return ""
+		}
+		if value < 0 {
+			continue
+		}
+		var headerValue []byte
+		if offset, headerValue, err = message.ReadBytes(offset, int(value)); err != nil {
+			return ""
+		}
+		if name := strings.ToLower(string(key)); name == "content-type" || name == "contenttype" {
+			return string(headerValue)
+		}
+	}
+	return ""
+}
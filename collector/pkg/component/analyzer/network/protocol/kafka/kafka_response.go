@@ -29,6 +29,17 @@ func parseResponse() protocol.ParsePkgFn {
 			return false, true
 		}
 		message.Offset = 8
+		// The response header v1 of the flexible versions has the tagged fields, except ApiVersions
+		// whose response header is always v0.
+		api := int(message.GetIntAttribute(constlabels.KafkaApi))
+		if api != _apiApiVersions && isFlexible(api, int(message.GetIntAttribute(constlabels.KafkaVersion))) &&
+			len(message.Data) > message.Offset {
+			offset, err := skipTaggedFields(message, message.Offset)
+			if err != nil {
+				return false, true
+			}
+			message.Offset = offset
+		}
 		return true, false
 	}
 }
@@ -0,0 +1,34 @@
+package kafka
+
+import (
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+func fastfailResponseGroup() protocol.FastFailFn {
+	return func(message *protocol.PayloadMessage) bool {
+		api := message.GetIntAttribute(constlabels.KafkaApi)
+		return api != _apiJoinGroup && api != _apiHeartbeat
+	}
+}
+
+// parseResponseGroup reads the error_code of JoinGroup and Heartbeat, which follows the throttle_time_ms.
+func parseResponseGroup() protocol.ParsePkgFn {
+	return func(message *protocol.PayloadMessage) (bool, bool) {
+		var errorCode int16
+		offset := message.Offset
+		version := message.GetIntAttribute(constlabels.KafkaVersion)
+		if message.GetIntAttribute(constlabels.KafkaApi) == _apiJoinGroup {
+			if version >= 2 {
+				offset += 4 // throttle_time_ms
+			}
+		} else if version >= 1 {
+			offset += 4 // throttle_time_ms
+		}
+		if _, err := message.ReadInt16(offset, &errorCode); err != nil {
+			return false, true
+		}
+		message.AddIntAttribute(constlabels.KafkaErrorCode, int64(errorCode))
+		return true, true
+	}
+}
@@ -0,0 +1,51 @@
+package kafka
+
+import (
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+func fastfailResponseOffsetCommit() protocol.FastFailFn {
+	return func(message *protocol.PayloadMessage) bool {
+		return message.GetIntAttribute(constlabels.KafkaApi) != _apiOffsetCommit
+	}
+}
+
+func parseResponseOffsetCommit() protocol.ParsePkgFn {
+	return func(message *protocol.PayloadMessage) (bool, bool) {
+		var (
+			offset       int
+			err          error
+			topicNum     int32
+			topicName    string
+			partitionNum int32
+			errorCode    int16
+		)
+		version := message.GetIntAttribute(constlabels.KafkaVersion)
+		compact := version >= 8
+		offset = message.Offset
+		if version >= 3 {
+			offset += 4 // throttle_time_ms
+		}
+		if offset, err = message.ReadArraySize(offset, compact, &topicNum); err != nil {
+			return false, true
+		}
+		if topicNum > 0 {
+			if offset, err = message.ReadString(offset, compact, &topicName); err != nil {
+				return false, true
+			}
+			if offset, err = message.ReadArraySize(offset, compact, &partitionNum); err != nil {
+				return false, true
+			}
+			if partitionNum > 0 {
+				offset += 4 // partition_index
+				// Read ErrorCode in First Partition
+				if _, err = message.ReadInt16(offset, &errorCode); err != nil {
+					return false, true
+				}
+			}
+		}
+		message.AddIntAttribute(constlabels.KafkaErrorCode, int64(errorCode))
+		return true, true
+	}
+}
@@ -0,0 +1,199 @@
+package ldap
+
+import (
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+// The BER tags used by LDAP.
+const (
+	tagInteger     = 0x02
+	tagOctetString = 0x04
+	tagEnumerated  = 0x0a
+	tagSequence    = 0x30
+	// The requestName of ExtendedRequest is the context-specific [0].
+	tagExtendedName = 0x80
+	// The authentication of BindRequest is the context-specific [0] for the simple one.
+	tagSimpleAuth = 0x80
+)
+
+// The protocolOp of LDAPMessage is a [APPLICATION n] tag, which is primitive for UnbindRequest,
+// DelRequest and AbandonRequest.
+const (
+	opBindRequest      = 0x60
+	opBindResponse     = 0x61
+	opUnbindRequest    = 0x42
+	opSearchRequest    = 0x63
+	opSearchEntry      = 0x64
+	opSearchDone       = 0x65
+	opModifyRequest    = 0x66
+	opModifyResponse   = 0x67
+	opAddRequest       = 0x68
+	opAddResponse      = 0x69
+	opDelRequest       = 0x4a
+	opDelResponse      = 0x6b
+	opModifyDNRequest  = 0x6c
+	opModifyDNResponse = 0x6d
+	opCompareRequest   = 0x6e
+	opCompareResponse  = 0x6f
+	opAbandonRequest   = 0x50
+	opSearchReference  = 0x73
+	opExtendedRequest  = 0x77
+	opExtendedResponse = 0x78
+	opIntermediate     = 0x79
+)
+
+var requestOperations = map[byte]string{
+	opBindRequest:     "bind",
+	opUnbindRequest:   "unbind",
+	opSearchRequest:   "search",
+	opModifyRequest:   "modify",
+	opAddRequest:      "add",
+	opDelRequest:      "delete",
+	opModifyDNRequest: "modify_dn",
+	opCompareRequest:  "compare",
+	opAbandonRequest:  "abandon",
+	opExtendedRequest: "extended",
+}
+
+// responseOperations maps the responses carrying LDAPResult to the operations of their requests.
+var responseOperations = map[byte]string{
+	opBindResponse:     "bind",
+	opSearchDone:       "search",
+	opModifyResponse:   "modify",
+	opAddResponse:      "add",
+	opDelResponse:      "delete",
+	opModifyDNResponse: "modify_dn",
+	opCompareResponse:  "compare",
+	opExtendedResponse: "extended",
+}
+
+// The result codes that don't mean a failure of the operation.
+var successResultCodes = map[int64]bool{
+	0:  true, // success
+	5:  true, // compareFalse
+	6:  true, // compareTrue
+	10: true, // referral
+	14: true, // saslBindInProgress
+}
+
+const (
+	// maxMessageLength is the max length of the LDAPMessage accepted, which is the default max PDU size
+	// of Active Directory and far above the one of OpenLDAP.
+	maxMessageLength = 1 << 24
+	maxIntegerLength = 4
+	maxDnLength      = 512
+)
+
+type message struct {
+	messageId int64
+	op        byte
+	// body is the content of the protocolOp, which may be truncated.
+	body []byte
+	// end is the offset after the LDAPMessage, which may exceed the data if it is truncated.
+	end int
+}
+
+// readMessage reads the LDAPMessage at the offset, which is a SEQUENCE of the messageID and the protocolOp.
+func readMessage(data []byte, offset int) (*message, bool) {
+	tag, length, contentOffset, ok := readHeader(data, offset)
+	if !ok || tag != tagSequence {
+		return nil, false
+	}
+	end := contentOffset + length
+	messageId, opOffset, ok := readInteger(data, contentOffset, tagInteger)
+	if !ok || messageId < 0 {
+		return nil, false
+	}
+	op, opLength, bodyOffset, ok := readHeader(data, opOffset)
+	if !ok || bodyOffset+opLength > end {
+		return nil, false
+	}
+	if _, found := requestOperations[op]; !found {
+		if _, found = responseOperations[op]; !found && op != opSearchEntry && op != opSearchReference && op != opIntermediate {
+			return nil, false
+		}
+	}
+	body := data[bodyOffset:]
+	if len(body) > opLength {
+		body = body[:opLength]
+	}
+	return &message{messageId: messageId, op: op, body: body, end: end}, true
+}
+
+// readHeader reads the tag and the definite length of the BER element at the offset, and returns them
+// with the offset of the content. The content may be truncated.
+func readHeader(data []byte, offset int) (byte, int, int, bool) {
+	if offset+2 > len(data) {
+		return 0, 0, offset, false
+	}
+	tag := data[offset]
+	length := int(data[offset+1])
+	offset += 2
+	if length&0x80 != 0 {
+		// The long form, and the indefinite form 0x80 is not allowed by LDAP.
+		n := length & 0x7f
+		if n == 0 || n > 4 || offset+n > len(data) {
+			return 0, 0, offset, false
+		}
+		length = 0
+		for i := 0; i < n; i++ {
+			length = length<<8 | int(data[offset+i])
+		}
+		offset += n
+	}
+	if length > maxMessageLength {
+		return 0, 0, offset, false
+	}
+	return tag, length, offset, true
+}
+
+// readInteger reads the INTEGER or ENUMERATED at the offset and returns it with the offset after it.
+func readInteger(data []byte, offset int, expectedTag byte) (int64, int, bool) {
+	tag, length, contentOffset, ok := readHeader(data, offset)
+	if !ok || tag != expectedTag || length == 0 || length > maxIntegerLength || contentOffset+length > len(data) {
+		return 0, offset, false
+	}
+	// The integers are two's complement.
+	value := int64(int8(data[contentOffset]))
+	for i := 1; i < length; i++ {
+		value = value<<8 | int64(data[contentOffset+i])
+	}
+	return value, contentOffset + length, true
+}
+
+// readString reads the OCTET STRING of the tag at the offset and returns it with the offset after it.
+// The string truncated is returned as well.
+func readString(data []byte, offset int, expectedTag byte) (string, int, bool) {
+	tag, length, contentOffset, ok := readHeader(data, offset)
+	if !ok || tag != expectedTag {
+		return "", offset, false
+	}
+	if contentOffset+length > len(data) {
+		return string(data[contentOffset:]), len(data), false
+	}
+	return string(data[contentOffset : contentOffset+length]), contentOffset + length, true
+}
+
+// NewLdapParser creates the parser of LDAP. If maskBind is true, the passwords of the simple binds are masked.
+func NewLdapParser(maskBind bool) *protocol.ProtocolParser {
+	requestParser := protocol.CreatePkgParser(fastfailLdapRequest(), parseLdapRequest(maskBind))
+	responseParser := protocol.CreatePkgParser(fastfailLdapResponse(), parseLdapResponse())
+	return protocol.NewProtocolParser(protocol.LDAP, requestParser, responseParser, ldapPair())
+}
+
+// ldapPair matches the responses with the requests by the messageID, as the clients could send
+// multiple requests, e.g. the asynchronous searches, without waiting for the responses.
+func ldapPair() protocol.PairMatch {
+	return func(requests []*protocol.PayloadMessage, response *protocol.PayloadMessage) int {
+		messageId := response.GetIntAttribute(constlabels.LdapMessageId)
+		operation := response.GetStringAttribute(constlabels.LdapOperation)
+		for i, request := range requests {
+			if request.GetIntAttribute(constlabels.LdapMessageId) == messageId &&
+				request.GetStringAttribute(constlabels.LdapOperation) == operation {
+				return i
+			}
+		}
+		return -1
+	}
+}
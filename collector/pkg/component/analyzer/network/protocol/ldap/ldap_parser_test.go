@@ -0,0 +1,169 @@
+package ldap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+func newElement(tag byte, content []byte) []byte {
+	length := len(content)
+	switch {
+	case length < 0x80:
+		return append([]byte{tag, byte(length)}, content...)
+	case length < 0x100:
+		return append([]byte{tag, 0x81, byte(length)}, content...)
+	default:
+		return append([]byte{tag, 0x82, byte(length >> 8), byte(length)}, content...)
+	}
+}
+
+func concat(elements ...[]byte) []byte {
+	data := make([]byte, 0)
+	for _, element := range elements {
+		data = append(data, element...)
+	}
+	return data
+}
+
+func newMessage(messageId byte, op []byte) []byte {
+	return newElement(tagSequence, concat(newElement(tagInteger, []byte{messageId}), op))
+}
+
+func newBind(name string, password string) []byte {
+	return newElement(opBindRequest, concat(newElement(tagInteger, []byte{3}), newElement(tagOctetString, []byte(name)),
+		newElement(tagSimpleAuth, []byte(password))))
+}
+
+func newSearch(base string, scope byte) []byte {
+	return newElement(opSearchRequest, concat(newElement(tagOctetString, []byte(base)), newElement(tagEnumerated, []byte{scope}),
+		newElement(tagEnumerated, []byte{0}), newElement(tagInteger, []byte{0}), newElement(tagInteger, []byte{0}),
+		newElement(0x01, []byte{0}), newElement(0x87, []byte("objectClass")), newElement(tagSequence, nil)))
+}
+
+func newResult(op byte, resultCode byte, diagnosticMessage string) []byte {
+	return newElement(op, concat(newElement(tagEnumerated, []byte{resultCode}), newElement(tagOctetString, nil),
+		newElement(tagOctetString, []byte(diagnosticMessage))))
+}
+
+func newEntry(dn string) []byte {
+	return newElement(opSearchEntry, concat(newElement(tagOctetString, []byte(dn)), newElement(tagSequence, nil)))
+}
+
+func newResponse(data []byte) *protocol.PayloadMessage {
+	return protocol.NewResponseMessage(data, protocol.NewRequestMessage(nil).GetAttributes())
+}
+
+func TestParseLdapRequest(t *testing.T) {
+	tests := []struct {
+		name       string
+		data       []byte
+		operation  string
+		dn         string
+		contentKey string
+		oneway     bool
+	}{
+		{name: "bind", data: newMessage(1, newBind("cn=admin,dc=example,dc=com", "secret")), operation: "bind",
+			dn: "cn=admin,dc=example,dc=com", contentKey: "bind cn=*,dc=example,dc=com"},
+		{name: "anonymous bind", data: newMessage(1, newBind("", "")), operation: "bind", contentKey: "bind"},
+		{name: "down-level logon name", data: newMessage(1, newBind("EXAMPLE\\alice", "secret")), operation: "bind",
+			dn: "EXAMPLE\\alice", contentKey: "bind EXAMPLE\\*"},
+		{name: "search subtree", data: newMessage(2, newSearch("ou=people,dc=example,dc=com", 2)), operation: "search",
+			dn: "ou=people,dc=example,dc=com", contentKey: "search ou=people,dc=example,dc=com"},
+		{name: "search base object", data: newMessage(2, newSearch("uid=a\\,b+cn=x,ou=people,dc=example,dc=com", 0)), operation: "search",
+			dn: "uid=a\\,b+cn=x,ou=people,dc=example,dc=com", contentKey: "search uid=*+cn=x,ou=people,dc=example,dc=com"},
+		{name: "root DSE", data: newMessage(2, newSearch("", 0)), operation: "search", contentKey: "search"},
+		{name: "modify", data: newMessage(3, newElement(opModifyRequest, concat(newElement(tagOctetString, []byte("uid=alice,dc=example,dc=com")),
+			newElement(tagSequence, nil)))), operation: "modify", dn: "uid=alice,dc=example,dc=com", contentKey: "modify uid=*,dc=example,dc=com"},
+		{name: "delete", data: newMessage(4, newElement(opDelRequest, []byte("uid=bob,dc=example,dc=com"))), operation: "delete",
+			dn: "uid=bob,dc=example,dc=com", contentKey: "delete uid=*,dc=example,dc=com"},
+		{name: "start tls", data: newMessage(5, newElement(opExtendedRequest, newElement(tagExtendedName, []byte("1.3.6.1.4.1.1466.20037")))),
+			operation: "extended", contentKey: "extended 1.3.6.1.4.1.1466.20037"},
+		{name: "unbind", data: newMessage(6, newElement(opUnbindRequest, nil)), operation: "unbind", contentKey: "unbind", oneway: true},
+	}
+	parser := NewLdapParser(false)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message := protocol.NewRequestMessage(tt.data)
+			assert.True(t, parser.ParseRequest(message))
+			assert.Equal(t, tt.operation, message.GetStringAttribute(constlabels.LdapOperation))
+			assert.Equal(t, tt.dn, message.GetStringAttribute(constlabels.LdapDn))
+			assert.Equal(t, tt.contentKey, message.GetStringAttribute(constlabels.ContentKey))
+			assert.Equal(t, tt.oneway, message.GetBoolAttribute(constlabels.Oneway))
+		})
+	}
+}
+
+func TestParseLdapRequest_Invalid(t *testing.T) {
+	parser := NewLdapParser(false)
+	for _, data := range [][]byte{
+		[]byte("0\x02abcdefg"),
+		// SearchResultEntry is only sent by the servers.
+		newMessage(2, newEntry("uid=alice,dc=example,dc=com")),
+		// The indefinite length is not allowed.
+		{0x30, 0x80, 0x02, 0x01, 0x01, 0x42, 0x00, 0x00, 0x00},
+	} {
+		assert.False(t, parser.ParseRequest(protocol.NewRequestMessage(data)))
+	}
+}
+
+func TestParseLdapRequest_MaskBind(t *testing.T) {
+	data := newMessage(1, newBind("uid=alice,dc=example,dc=com", "secret"))
+	message := protocol.NewRequestMessage(data)
+	assert.True(t, NewLdapParser(true).ParseRequest(message))
+	assert.Contains(t, string(data), "uid=alice,dc=example,dc=com\x80\x06******")
+	assert.Equal(t, "3", message.GetStringAttribute(constlabels.ProtocolVersion))
+}
+
+func TestParseLdapResponse(t *testing.T) {
+	parser := NewLdapParser(false)
+
+	message := newResponse(newMessage(1, newResult(opBindResponse, 49, "invalid credentials")))
+	assert.True(t, parser.ParseResponse(message))
+	assert.Equal(t, int64(49), message.GetIntAttribute(constlabels.LdapResultCode))
+	assert.Equal(t, "invalid credentials", message.GetStringAttribute(constlabels.LdapErrorMessage))
+	assert.True(t, message.GetBoolAttribute(constlabels.IsError))
+
+	// compareFalse is not a failure.
+	message = newResponse(newMessage(3, newResult(opCompareResponse, 5, "")))
+	assert.True(t, parser.ParseResponse(message))
+	assert.Equal(t, "compare", message.GetStringAttribute(constlabels.LdapOperation))
+	assert.False(t, message.GetBoolAttribute(constlabels.IsError))
+
+	// The entries written with SearchResultDone are skipped.
+	message = newResponse(concat(newMessage(2, newEntry("uid=alice,dc=example,dc=com")), newMessage(2, newResult(opSearchDone, 0, ""))))
+	assert.True(t, parser.ParseResponse(message))
+	assert.Equal(t, "search", message.GetStringAttribute(constlabels.LdapOperation))
+	assert.Equal(t, int64(2), message.GetIntAttribute(constlabels.LdapMessageId))
+	assert.False(t, message.GetBoolAttribute(constlabels.Oneway))
+
+	// The entries written without SearchResultDone are not responses.
+	message = newResponse(newMessage(2, newEntry("uid=alice,dc=example,dc=com")))
+	assert.True(t, parser.ParseResponse(message))
+	assert.True(t, message.GetBoolAttribute(constlabels.Oneway))
+
+	// The notice of disconnection
+	message = newResponse(newMessage(0, newResult(opExtendedResponse, 52, "")))
+	assert.True(t, parser.ParseResponse(message))
+	assert.True(t, message.GetBoolAttribute(constlabels.Oneway))
+}
+
+func TestLdapPair(t *testing.T) {
+	parser := NewLdapParser(false)
+	requests := make([]*protocol.PayloadMessage, 0)
+	for _, data := range [][]byte{newMessage(1, newSearch("ou=people,dc=example,dc=com", 2)), newMessage(2, newSearch("ou=groups,dc=example,dc=com", 2))} {
+		request := protocol.NewRequestMessage(data)
+		assert.True(t, parser.ParseRequest(request))
+		requests = append(requests, request)
+	}
+	response := newResponse(newMessage(2, newResult(opSearchDone, 0, "")))
+	assert.True(t, parser.ParseResponse(response))
+	assert.Equal(t, 1, parser.PairMatch(requests, response))
+
+	response = newResponse(newMessage(2, newResult(opBindResponse, 0, "")))
+	assert.True(t, parser.ParseResponse(response))
+	assert.Equal(t, -1, parser.PairMatch(requests, response))
+}
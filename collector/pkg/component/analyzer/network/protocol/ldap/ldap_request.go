@@ -0,0 +1,128 @@
+package ldap
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+// minMessageLength is the length of UnbindRequest, the shortest LDAPMessage.
+const minMessageLength = 7
+
+// scopeBaseObject is the scope of the searches reading a single entry.
+const scopeBaseObject = 0
+
+func fastfailLdapRequest() protocol.FastFailFn {
+	return func(message *protocol.PayloadMessage) bool {
+		return len(message.Data) < minMessageLength || message.Data[0] != tagSequence
+	}
+}
+
+// parseLdapRequest reads the first LDAPMessage sent by the client. UnbindRequest and AbandonRequest
+// are not responded.
+func parseLdapRequest(maskBind bool) protocol.ParsePkgFn {
+	return func(message *protocol.PayloadMessage) (bool, bool) {
+		m, ok := readMessage(message.Data, 0)
+		if !ok {
+			return false, true
+		}
+		operation, ok := requestOperations[m.op]
+		if !ok {
+			return false, true
+		}
+		contentKey := operation
+		var dn string
+		// The entries named by the DNs are masked in the content key, e.g. the users binding.
+		maskEntry := true
+		switch m.op {
+		case opUnbindRequest, opAbandonRequest:
+			message.AddBoolAttribute(constlabels.Oneway, true)
+		case opBindRequest:
+			version, offset, ok := readInteger(m.body, 0, tagInteger)
+			if !ok {
+				return false, true
+			}
+			message.AddStringAttribute(constlabels.ProtocolVersion, strconv.FormatInt(version, 10))
+			dn, offset, _ = readString(m.body, offset, tagOctetString)
+			if maskBind {
+				maskSimplePassword(m.body, offset)
+			}
+		case opSearchRequest:
+			var offset int
+			dn, offset, ok = readString(m.body, 0, tagOctetString)
+			// The bases of the searches through the subtrees are the containers, which are kept.
+			scope, _, scopeOk := readInteger(m.body, offset, tagEnumerated)
+			maskEntry = ok && scopeOk && scope == scopeBaseObject
+		case opDelRequest:
+			dn = string(m.body)
+		case opModifyRequest, opAddRequest, opModifyDNRequest, opCompareRequest:
+			dn, _, _ = readString(m.body, 0, tagOctetString)
+		case opExtendedRequest:
+			// The OID of the operation, e.g. 1.3.6.1.4.1.1466.20037 for StartTLS
+			if name, _, ok := readString(m.body, 0, tagExtendedName); ok && name != "" {
+				contentKey += " " + name
+			}
+		}
+		if len(dn) > maxDnLength {
+			dn = dn[:maxDnLength]
+		}
+		if dn != "" {
+			message.AddUtf8StringAttribute(constlabels.LdapDn, dn)
+			if maskEntry {
+				contentKey += " " + maskEntryName(dn)
+			} else {
+				contentKey += " " + dn
+			}
+		}
+		message.AddIntAttribute(constlabels.LdapMessageId, m.messageId)
+		message.AddStringAttribute(constlabels.LdapOperation, operation)
+		message.AddUtf8StringAttribute(constlabels.ContentKey, contentKey)
+		return true, true
+	}
+}
+
+// maskSimplePassword masks the password of the simple authentication at the offset of BindRequest.
+// The SASL credentials are left as they are exchanged in multiple steps.
+func maskSimplePassword(body []byte, offset int) {
+	tag, length, contentOffset, ok := readHeader(body, offset)
+	if !ok || tag != tagSimpleAuth || contentOffset >= len(body) {
+		return
+	}
+	end := contentOffset + length
+	if end > len(body) {
+		end = len(body)
+	}
+	protocol.MaskBytes(body[contentOffset:end])
+}
+
+// maskEntryName replaces the value of the leading RDN naming the entry, e.g. "uid=*,ou=people,dc=example,dc=com"
+// for "uid=alice,ou=people,dc=example,dc=com". The bind names of Active Directory in the form of
+// "alice@example.com" and "EXAMPLE\alice" are masked as "*@example.com" and "EXAMPLE\*".
+func maskEntryName(dn string) string {
+	if i := strings.IndexByte(dn, '='); i > 0 {
+		return dn[:i+1] + "*" + dn[rdnValueEnd(dn, i+1):]
+	}
+	if i := strings.LastIndexByte(dn, '@'); i >= 0 {
+		return "*" + dn[i:]
+	}
+	if i := strings.IndexByte(dn, '\\'); i >= 0 {
+		return dn[:i+1] + "*"
+	}
+	return "*"
+}
+
+// rdnValueEnd returns the end of the attribute value starting at the offset, where the "," and "+"
+// escaped with "\" are part of the value.
+func rdnValueEnd(dn string, offset int) int {
+	for i := offset; i < len(dn); i++ {
+		switch dn[i] {
+		case '\\':
+			i++
+		case ',', '+':
+			return i
+		}
+	}
+	return len(dn)
+}
@@ -0,0 +1,64 @@
+package ldap
+
+import (
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+// unsolicitedMessageId is the messageID of the notifications sent by the servers, e.g. the notice of disconnection.
+const unsolicitedMessageId = 0
+
+func fastfailLdapResponse() protocol.FastFailFn {
+	return func(message *protocol.PayloadMessage) bool {
+		return len(message.Data) < minMessageLength || message.Data[0] != tagSequence
+	}
+}
+
+// parseLdapResponse reads the LDAPResult sent by the server. The entries and the references of a search
+// precede SearchResultDone, so they are skipped and the search is recorded when it is done. The data
+// holding only them is not a response of any request.
+func parseLdapResponse() protocol.ParsePkgFn {
+	return func(message *protocol.PayloadMessage) (bool, bool) {
+		m, ok := readMessage(message.Data, 0)
+		if !ok {
+			return false, true
+		}
+		for {
+			if _, found := responseOperations[m.op]; found {
+				break
+			}
+			if _, found := requestOperations[m.op]; found {
+				// Only sent by the clients
+				return false, true
+			}
+			next, ok := readMessage(message.Data, m.end)
+			if !ok {
+				message.AddBoolAttribute(constlabels.Oneway, true)
+				return true, true
+			}
+			m = next
+		}
+		if m.messageId == unsolicitedMessageId {
+			message.AddBoolAttribute(constlabels.Oneway, true)
+			return true, true
+		}
+		resultCode, offset, ok := readInteger(m.body, 0, tagEnumerated)
+		if !ok {
+			return false, true
+		}
+		message.AddIntAttribute(constlabels.LdapMessageId, m.messageId)
+		message.AddStringAttribute(constlabels.LdapOperation, responseOperations[m.op])
+		message.AddIntAttribute(constlabels.LdapResultCode, resultCode)
+		if !successResultCodes[resultCode] {
+			// The matchedDN precedes the diagnosticMessage, e.g. "80090308: LdapErr: ... data 52e" of
+			// Active Directory for the invalid credentials.
+			_, offset, _ = readString(m.body, offset, tagOctetString)
+			if diagnosticMessage, _, _ := readString(m.body, offset, tagOctetString); diagnosticMessage != "" {
+				message.AddUtf8StringAttribute(constlabels.LdapErrorMessage, diagnosticMessage)
+			}
+			message.AddBoolAttribute(constlabels.IsError, true)
+			message.AddIntAttribute(constlabels.ErrorType, int64(constlabels.ProtocolError))
+		}
+		return true, true
+	}
+}
@@ -0,0 +1,13 @@
+package protocol
+
+// MaskByte replaces the sensitive data in the payloads.
+const MaskByte = '*'
+
+// MaskBytes masks the data in place. The parsers mask the payload before the attributes are set,
+// so the sensitive data is neither in the labels nor in the payloads. The length of the payload
+// is kept so the offsets parsed before are still valid.
+func MaskBytes(data []byte) {
+	for i := range data {
+		data[i] = MaskByte
+	}
+}
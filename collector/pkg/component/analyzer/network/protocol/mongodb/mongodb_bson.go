@@ -0,0 +1,181 @@
+package mongodb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+)
+
+// BSON element types, see https://bsonspec.org/spec.html
+const (
+	bsonDouble     = 0x01
+	bsonString     = 0x02
+	bsonDocument   = 0x03
+	bsonArray      = 0x04
+	bsonBinary     = 0x05
+	bsonUndefined  = 0x06
+	bsonObjectId   = 0x07
+	bsonBoolean    = 0x08
+	bsonDateTime   = 0x09
+	bsonNull       = 0x0a
+	bsonRegex      = 0x0b
+	bsonDbPointer  = 0x0c
+	bsonJavaScript = 0x0d
+	bsonSymbol     = 0x0e
+	bsonCodeScope  = 0x0f
+	bsonInt32      = 0x10
+	bsonTimestamp  = 0x11
+	bsonInt64      = 0x12
+	bsonDecimal128 = 0x13
+	bsonMinKey     = 0xff
+	bsonMaxKey     = 0x7f
+)
+
+// bsonElement is an element of a BSON document. The value refers to the payload without copying.
+type bsonElement struct {
+	kind  byte
+	key   string
+	value []byte
+}
+
+// readBsonDocument reads the elements of the document at the offset. The payload may be truncated
+// by the snaplen, so the elements before the truncation are returned. It returns false if the data
+// is not a valid BSON document.
+func readBsonDocument(data []byte, offset int) ([]bsonElement, bool) {
+	if offset < 0 || offset+5 > len(data) {
+		return nil, false
+	}
+	length := int(int32(binary.LittleEndian.Uint32(data[offset:])))
+	if length < 5 {
+		return nil, false
+	}
+	end := offset + length
+	if end > len(data) {
+		end = len(data)
+	}
+	elements := make([]bsonElement, 0)
+	for pos := offset + 4; pos < end; {
+		kind := data[pos]
+		if kind == 0 {
+			return elements, pos == offset+length-1
+		}
+		keyEnd := bytes.IndexByte(data[pos+1:end], 0)
+		if keyEnd == -1 {
+			return elements, true
+		}
+		key := string(data[pos+1 : pos+1+keyEnd])
+		pos += keyEnd + 2
+		size, ok := bsonValueSize(kind, data[pos:end])
+		if !ok {
+			return elements, false
+		}
+		if size < 0 {
+			// The value is truncated.
+			return elements, true
+		}
+		elements = append(elements, bsonElement{kind: kind, key: key, value: data[pos : pos+size]})
+		pos += size
+	}
+	return elements, true
+}
+
+// bsonValueSize returns the size of the value, or -1 if the value is truncated. It returns false
+// if the type is unknown.
+func bsonValueSize(kind byte, data []byte) (int, bool) {
+	size := -1
+	switch kind {
+	case bsonUndefined, bsonNull, bsonMinKey, bsonMaxKey:
+		size = 0
+	case bsonBoolean:
+		size = 1
+	case bsonInt32:
+		size = 4
+	case bsonDouble, bsonDateTime, bsonTimestamp, bsonInt64:
+		size = 8
+	case bsonObjectId:
+		size = 12
+	case bsonDecimal128:
+		size = 16
+	case bsonString, bsonJavaScript, bsonSymbol:
+		if len(data) >= 4 {
+			size = 4 + int(int32(binary.LittleEndian.Uint32(data)))
+			if size < 5 {
+				return 0, false
+			}
+		}
+	case bsonDbPointer:
+		if len(data) >= 4 {
+			size = 4 + int(int32(binary.LittleEndian.Uint32(data))) + 12
+			if size < 17 {
+				return 0, false
+			}
+		}
+	case bsonDocument, bsonArray, bsonCodeScope:
+		if len(data) >= 4 {
+			size = int(int32(binary.LittleEndian.Uint32(data)))
+			if size < 5 {
+				return 0, false
+			}
+		}
+	case bsonBinary:
+		if len(data) >= 4 {
+			size = 5 + int(int32(binary.LittleEndian.Uint32(data)))
+			if size < 5 {
+				return 0, false
+			}
+		}
+	case bsonRegex:
+		pattern := bytes.IndexByte(data, 0)
+		if pattern != -1 {
+			if options := bytes.IndexByte(data[pattern+1:], 0); options != -1 {
+				size = pattern + options + 2
+			}
+		}
+	default:
+		return 0, false
+	}
+	if size > len(data) {
+		size = -1
+	}
+	return size, true
+}
+
+func (e bsonElement) stringValue() string {
+	if e.kind != bsonString || len(e.value) < 5 {
+		return ""
+	}
+	return string(e.value[4 : len(e.value)-1])
+}
+
+// intValue converts the numeric values, which are used interchangeably by the drivers, into int64.
+// The doubles that int64 can't hold are rejected, as converting them depends on the architecture,
+// e.g. NaN becomes math.MinInt64 on amd64 but 0 on arm64.
+func (e bsonElement) intValue() (int64, bool) {
+	switch e.kind {
+	case bsonInt32:
+		return int64(int32(binary.LittleEndian.Uint32(e.value))), true
+	case bsonInt64:
+		return int64(binary.LittleEndian.Uint64(e.value)), true
+	case bsonDouble:
+		value := math.Float64frombits(binary.LittleEndian.Uint64(e.value))
+		if math.IsNaN(value) || value < math.MinInt64 || value >= math.MaxInt64 {
+			return 0, false
+		}
+		return int64(value), true
+	case bsonBoolean:
+		if e.value[0] != 0 {
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+func findBsonElement(elements []bsonElement, key string) (bsonElement, bool) {
+	for _, element := range elements {
+		if element.key == key {
+			return element, true
+		}
+	}
+	return bsonElement{}, false
+}
@@ -0,0 +1,62 @@
+package mongodb
+
+import (
+	"encoding/binary"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+)
+
+// The opcodes of the wire protocol, see https://www.mongodb.com/docs/manual/reference/mongodb-wire-protocol/
+const (
+	opReply = 1
+	opQuery = 2004
+	opMsg   = 2013
+)
+
+const (
+	headerLength = 16
+	// maxMessageSize is the default maxMessageSizeBytes of the MongoDB servers.
+	maxMessageSize = 48000000
+)
+
+// The flag bits of OP_MSG. The unknown bits in the lowest 16 bits are required to be zero.
+const (
+	flagChecksumPresent = 1 << 0
+	flagMoreToCome      = 1 << 1
+	flagExhaustAllowed  = 1 << 16
+	flagRequiredMask    = 0xffff
+)
+
+// The response flag of OP_REPLY set if the query failed.
+const replyFlagQueryFailure = 1 << 1
+
+type messageHeader struct {
+	length     int32
+	requestId  int32
+	responseTo int32
+	opCode     int32
+}
+
+// readHeader reads the header, whose fields are little-endian. The payload may be truncated,
+// so the length is only checked against the limits of MongoDB.
+func readHeader(data []byte) (messageHeader, bool) {
+	if len(data) < headerLength {
+		return messageHeader{}, false
+	}
+	header := messageHeader{
+		length:     int32(binary.LittleEndian.Uint32(data)),
+		requestId:  int32(binary.LittleEndian.Uint32(data[4:])),
+		responseTo: int32(binary.LittleEndian.Uint32(data[8:])),
+		opCode:     int32(binary.LittleEndian.Uint32(data[12:])),
+	}
+	if header.length <= headerLength || header.length > maxMessageSize {
+		return messageHeader{}, false
+	}
+	return header, true
+}
+
+func NewMongodbParser() *protocol.ProtocolParser {
+	requestParser := protocol.CreatePkgParser(fastfailMongodbRequest(), parseMongodbRequest())
+	responseParser := protocol.CreatePkgParser(fastfailMongodbResponse(), parseMongodbResponse())
+	return protocol.NewProtocolParser(protocol.MONGODB, requestParser, responseParser, nil)
+}
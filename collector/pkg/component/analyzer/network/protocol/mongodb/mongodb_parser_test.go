@@ -0,0 +1,225 @@
+package mongodb
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+type bsonField struct {
+	key   string
+	value interface{}
+}
+
+func newBson(fields ...bsonField) []byte {
+	doc := []byte{0, 0, 0, 0}
+	for _, field := range fields {
+		var kind byte
+		var value []byte
+		switch v := field.value.(type) {
+		case string:
+			kind = bsonString
+			value = binary.LittleEndian.AppendUint32(nil, uint32(len(v)+1))
+			value = append(append(value, v...), 0)
+		case int32:
+			kind = bsonInt32
+			value = binary.LittleEndian.AppendUint32(nil, uint32(v))
+		case int64:
+			kind = bsonInt64
+			value = binary.LittleEndian.AppendUint64(nil, uint64(v))
+		case float64:
+			kind = bsonDouble
+			value = binary.LittleEndian.AppendUint64(nil, math.Float64bits(v))
+		case []byte:
+			kind = bsonDocument
+			value = v
+		case [][]byte:
+			kind = bsonArray
+			elements := make([]bsonField, 0, len(v))
+			for _, element := range v {
+				elements = append(elements, bsonField{key: "0", value: element})
+			}
+			value = newBson(elements...)
+		}
+		doc = append(doc, kind)
+		doc = append(append(doc, field.key...), 0)
+		doc = append(doc, value...)
+	}
+	doc = append(doc, 0)
+	binary.LittleEndian.PutUint32(doc, uint32(len(doc)))
+	return doc
+}
+
+func newMessage(requestId int32, responseTo int32, opCode int32, body []byte) []byte {
+	data := binary.LittleEndian.AppendUint32(nil, uint32(headerLength+len(body)))
+	data = binary.LittleEndian.AppendUint32(data, uint32(requestId))
+	data = binary.LittleEndian.AppendUint32(data, uint32(responseTo))
+	data = binary.LittleEndian.AppendUint32(data, uint32(opCode))
+	return append(data, body...)
+}
+
+func newOpMsg(requestId int32, responseTo int32, doc []byte) []byte {
+	body := []byte{0, 0, 0, 0, 0}
+	return newMessage(requestId, responseTo, opMsg, append(body, doc...))
+}
+
+func TestParseOpMsg(t *testing.T) {
+	// The documents to insert are sent in the section of kind 1 before the body.
+	documents := newBson(bsonField{"_id", int32(1)})
+	sequence := binary.LittleEndian.AppendUint32(nil, uint32(4+len("documents")+1+len(documents)))
+	sequence = append(append(sequence, "documents"...), 0)
+	sequence = append(sequence, documents...)
+	insertBody := append([]byte{0, 0, 0, 0, 1}, sequence...)
+	insertBody = append(append(insertBody, 0), newBson(bsonField{"insert", "orders"}, bsonField{"$db", "shop"})...)
+
+	tests := []struct {
+		name       string
+		request    []byte
+		response   []byte
+		command    string
+		database   string
+		collection string
+		contentKey string
+		errCode    int64
+		errMsg     string
+	}{
+		{
+			name:       "find",
+			request:    newOpMsg(7, 0, newBson(bsonField{"find", "orders"}, bsonField{"filter", newBson()}, bsonField{"$db", "shop"})),
+			response:   newOpMsg(100, 7, newBson(bsonField{"cursor", newBson()}, bsonField{"ok", 1.0})),
+			command:    "find",
+			database:   "shop",
+			collection: "orders",
+			contentKey: "find orders",
+		},
+		{
+			name:       "getMore",
+			request:    newOpMsg(8, 0, newBson(bsonField{"getMore", int64(123)}, bsonField{"collection", "orders"}, bsonField{"$db", "shop"})),
+			response:   newOpMsg(101, 8, newBson(bsonField{"ok", 1.0})),
+			command:    "getMore",
+			database:   "shop",
+			collection: "orders",
+			contentKey: "getMore orders",
+		},
+		{
+			name:       "insert with document sequence and write errors",
+			request:    newMessage(9, 0, opMsg, insertBody),
+			response:   newOpMsg(102, 9, newBson(bsonField{"n", int32(0)}, bsonField{"writeErrors", [][]byte{newBson(bsonField{"index", int32(0)}, bsonField{"code", int32(11000)}, bsonField{"errmsg", "E11000 duplicate key error"})}}, bsonField{"ok", 1.0})),
+			command:    "insert",
+			database:   "shop",
+			collection: "orders",
+			contentKey: "insert orders",
+			errCode:    11000,
+			errMsg:     "E11000 duplicate key error",
+		},
+		{
+			name:       "command error",
+			request:    newOpMsg(10, 0, newBson(bsonField{"ping", int32(1)}, bsonField{"$db", "admin"})),
+			response:   newOpMsg(103, 10, newBson(bsonField{"ok", 0.0}, bsonField{"errmsg", "command ping requires authentication"}, bsonField{"code", int32(13)})),
+			command:    "ping",
+			database:   "admin",
+			contentKey: "ping",
+			errCode:    13,
+			errMsg:     "command ping requires authentication",
+		},
+		{
+			// The conversions of the doubles out of the range of int64 differ between amd64 and arm64.
+			name:       "ok is not a number",
+			request:    newOpMsg(11, 0, newBson(bsonField{"ping", int32(1)}, bsonField{"$db", "admin"})),
+			response:   newOpMsg(104, 11, newBson(bsonField{"ok", math.NaN()})),
+			command:    "ping",
+			database:   "admin",
+			contentKey: "ping",
+		},
+		{
+			name:       "code out of range",
+			request:    newOpMsg(12, 0, newBson(bsonField{"ping", int32(1)}, bsonField{"$db", "admin"})),
+			response:   newOpMsg(105, 12, newBson(bsonField{"ok", 0.0}, bsonField{"errmsg", "unknown error"}, bsonField{"code", 1e300})),
+			command:    "ping",
+			database:   "admin",
+			contentKey: "ping",
+			errMsg:     "unknown error",
+		},
+	}
+	parser := NewMongodbParser()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := protocol.NewRequestMessage(tt.request)
+			assert.True(t, parser.ParseRequest(request))
+			attributes := request.GetAttributes()
+			assert.Equal(t, tt.command, attributes.GetStringValue(constlabels.MongodbCommand))
+			assert.Equal(t, tt.database, attributes.GetStringValue(constlabels.MongodbDatabase))
+			assert.Equal(t, tt.collection, attributes.GetStringValue(constlabels.MongodbCollection))
+			assert.Equal(t, tt.contentKey, attributes.GetStringValue(constlabels.ContentKey))
+
+			response := protocol.NewResponseMessage(tt.response, attributes)
+			assert.True(t, parser.ParseResponse(response))
+			attributes = response.GetAttributes()
+			assert.Equal(t, tt.errMsg != "", attributes.GetBoolValue(constlabels.IsError))
+			assert.Equal(t, tt.errCode, attributes.GetIntValue(constlabels.MongodbErrCode))
+			assert.Equal(t, tt.errMsg, attributes.GetStringValue(constlabels.MongodbErrMsg))
+		})
+	}
+}
+
+func TestParseOpQuery(t *testing.T) {
+	newOpQuery := func(requestId int32, fullCollectionName string, query []byte) []byte {
+		body := append([]byte{0, 0, 0, 0}, fullCollectionName...)
+		body = append(body, 0, 0, 0, 0, 0, 0xff, 0xff, 0xff, 0xff)
+		return newMessage(requestId, 0, opQuery, append(body, query...))
+	}
+	newOpReply := func(responseTo int32, flags uint32, doc []byte) []byte {
+		body := binary.LittleEndian.AppendUint32(nil, flags)
+		body = append(body, make([]byte, 16)...)
+		return newMessage(200, responseTo, opReply, append(body, doc...))
+	}
+	parser := NewMongodbParser()
+
+	request := protocol.NewRequestMessage(newOpQuery(1, "admin.$cmd", newBson(bsonField{"isMaster", int32(1)})))
+	assert.True(t, parser.ParseRequest(request))
+	assert.Equal(t, "isMaster", request.GetStringAttribute(constlabels.ContentKey))
+	assert.Equal(t, "admin", request.GetStringAttribute(constlabels.MongodbDatabase))
+	response := protocol.NewResponseMessage(newOpReply(1, 0, newBson(bsonField{"ismaster", int32(1)}, bsonField{"ok", 1.0})), request.GetAttributes())
+	assert.True(t, parser.ParseResponse(response))
+	assert.False(t, response.GetBoolAttribute(constlabels.IsError))
+
+	request = protocol.NewRequestMessage(newOpQuery(2, "shop.orders", newBson(bsonField{"status", "paid"})))
+	assert.True(t, parser.ParseRequest(request))
+	assert.Equal(t, "query orders", request.GetStringAttribute(constlabels.ContentKey))
+	response = protocol.NewResponseMessage(newOpReply(2, replyFlagQueryFailure, newBson(bsonField{"$err", "not authorized"}, bsonField{"code", int32(13)})), request.GetAttributes())
+	assert.True(t, parser.ParseResponse(response))
+	assert.True(t, response.GetBoolAttribute(constlabels.IsError))
+	assert.Equal(t, int64(13), response.GetIntAttribute(constlabels.MongodbErrCode))
+	assert.Equal(t, "not authorized", response.GetStringAttribute(constlabels.MongodbErrMsg))
+}
+
+func TestParseMismatchedOrInvalid(t *testing.T) {
+	parser := NewMongodbParser()
+	request := protocol.NewRequestMessage(newOpMsg(7, 0, newBson(bsonField{"find", "orders"})))
+	assert.True(t, parser.ParseRequest(request))
+	// The response to another request
+	assert.False(t, parser.ParseResponse(protocol.NewResponseMessage(newOpMsg(100, 8, newBson(bsonField{"ok", 1.0})), request.GetAttributes())))
+
+	// A response is not a request.
+	assert.False(t, parser.ParseRequest(protocol.NewRequestMessage(newOpMsg(100, 7, newBson(bsonField{"ok", 1.0})))))
+	// Unknown required flag bits
+	invalid := newOpMsg(7, 0, newBson(bsonField{"find", "orders"}))
+	invalid[headerLength] = 0x04
+	assert.False(t, parser.ParseRequest(protocol.NewRequestMessage(invalid)))
+	// HTTP
+	assert.False(t, parser.ParseRequest(protocol.NewRequestMessage([]byte("GET /index.html HTTP/1.1\r\nHost: localhost\r\n\r\n"))))
+}
+
+func TestParseTruncatedRequest(t *testing.T) {
+	data := newOpMsg(7, 0, newBson(bsonField{"find", "orders"}, bsonField{"filter", newBson(bsonField{"status", "paid"})}, bsonField{"$db", "shop"}))
+	request := protocol.NewRequestMessage(data[:len(data)-20])
+	assert.True(t, NewMongodbParser().ParseRequest(request))
+	assert.Equal(t, "find orders", request.GetStringAttribute(constlabels.ContentKey))
+	// The database is not known as "$db" is truncated.
+	assert.False(t, request.HasAttribute(constlabels.MongodbDatabase))
+}
@@ -0,0 +1,147 @@
+package mongodb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+func fastfailMongodbRequest() protocol.FastFailFn {
+	return func(message *protocol.PayloadMessage) bool {
+		header, ok := readHeader(message.Data)
+		// The requests are never responses to other messages.
+		return !ok || header.responseTo != 0 || (header.opCode != opMsg && header.opCode != opQuery)
+	}
+}
+
+func parseMongodbRequest() protocol.ParsePkgFn {
+	return func(message *protocol.PayloadMessage) (bool, bool) {
+		header, _ := readHeader(message.Data)
+		var (
+			command *commandInfo
+			ok      bool
+		)
+		if header.opCode == opMsg {
+			command, ok = parseOpMsgCommand(message.Data)
+		} else {
+			command, ok = parseOpQueryCommand(message.Data)
+		}
+		if !ok {
+			return false, true
+		}
+
+		message.AddIntAttribute(constlabels.MongodbRequestId, int64(header.requestId))
+		message.AddUtf8StringAttribute(constlabels.MongodbCommand, command.name)
+		if command.database != "" {
+			message.AddUtf8StringAttribute(constlabels.MongodbDatabase, command.database)
+		}
+		if command.collection != "" {
+			message.AddUtf8StringAttribute(constlabels.MongodbCollection, command.collection)
+			message.AddUtf8StringAttribute(constlabels.ContentKey, command.name+" "+command.collection)
+		} else {
+			message.AddUtf8StringAttribute(constlabels.ContentKey, command.name)
+		}
+		return true, true
+	}
+}
+
+type commandInfo struct {
+	name       string
+	database   string
+	collection string
+}
+
+// parseOpMsgCommand reads the command from the body section, which is the only section of kind 0.
+// The sections of kind 1 hold the documents of the bulk writes and are skipped.
+func parseOpMsgCommand(data []byte) (*commandInfo, bool) {
+	if len(data) < headerLength+5 {
+		return nil, false
+	}
+	flags := binary.LittleEndian.Uint32(data[headerLength:])
+	if flags&flagRequiredMask&^(flagChecksumPresent|flagMoreToCome) != 0 ||
+		flags&^flagRequiredMask&^flagExhaustAllowed != 0 {
+		return nil, false
+	}
+	offset := headerLength + 4
+	for offset < len(data) {
+		kind := data[offset]
+		offset++
+		switch kind {
+		case 0:
+			elements, ok := readBsonDocument(data, offset)
+			if !ok || len(elements) == 0 {
+				return nil, false
+			}
+			return newCommandInfo(elements, ""), true
+		case 1:
+			if offset+4 > len(data) {
+				return nil, false
+			}
+			size := int(int32(binary.LittleEndian.Uint32(data[offset:])))
+			if size < 5 {
+				return nil, false
+			}
+			offset += size
+		default:
+			return nil, false
+		}
+	}
+	return nil, false
+}
+
+// parseOpQueryCommand reads the legacy OP_QUERY, which is still used by the handshakes of the old drivers.
+// The commands are sent to the collection "<db>.$cmd", while the others are the queries on the collection.
+func parseOpQueryCommand(data []byte) (*commandInfo, bool) {
+	offset := headerLength + 4
+	if offset >= len(data) {
+		return nil, false
+	}
+	nameEnd := bytes.IndexByte(data[offset:], 0)
+	if nameEnd <= 0 {
+		return nil, false
+	}
+	fullCollectionName := string(data[offset : offset+nameEnd])
+	database, collection, found := strings.Cut(fullCollectionName, ".")
+	if !found || database == "" {
+		return nil, false
+	}
+	// Skip numberToSkip and numberToReturn
+	elements, ok := readBsonDocument(data, offset+nameEnd+1+8)
+	if !ok {
+		return nil, false
+	}
+	if collection != "$cmd" {
+		return &commandInfo{name: "query", database: database, collection: collection}, true
+	}
+	if len(elements) == 0 {
+		return nil, false
+	}
+	// The command may be wrapped in "$query" with the read preference.
+	if query, ok := findBsonElement(elements, "$query"); ok && query.kind == bsonDocument {
+		if wrapped, ok := readBsonDocument(query.value, 0); ok && len(wrapped) > 0 {
+			elements = wrapped
+		}
+	}
+	return newCommandInfo(elements, database), true
+}
+
+// newCommandInfo takes the name of the first element as the command, whose value is the collection
+// for the commands on collections like "find" and "insert". The database is in "$db" for OP_MSG.
+func newCommandInfo(elements []bsonElement, database string) *commandInfo {
+	info := &commandInfo{
+		name:       elements[0].key,
+		database:   database,
+		collection: elements[0].stringValue(),
+	}
+	// getMore takes the cursor ID as the value and the collection in another field.
+	if collection, ok := findBsonElement(elements, "collection"); ok && info.collection == "" {
+		info.collection = collection.stringValue()
+	}
+	if db, ok := findBsonElement(elements, "$db"); ok {
+		info.database = db.stringValue()
+	}
+	return info
+}
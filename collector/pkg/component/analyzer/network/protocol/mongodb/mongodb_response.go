@@ -0,0 +1,87 @@
+package mongodb
+
+import (
+	"encoding/binary"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+func fastfailMongodbResponse() protocol.FastFailFn {
+	return func(message *protocol.PayloadMessage) bool {
+		header, ok := readHeader(message.Data)
+		return !ok || (header.opCode != opMsg && header.opCode != opReply)
+	}
+}
+
+func parseMongodbResponse() protocol.ParsePkgFn {
+	return func(message *protocol.PayloadMessage) (bool, bool) {
+		header, _ := readHeader(message.Data)
+		if !message.HasAttribute(constlabels.MongodbRequestId) ||
+			message.GetIntAttribute(constlabels.MongodbRequestId) != int64(header.responseTo) {
+			return false, true
+		}
+
+		var (
+			elements []bsonElement
+			failed   bool
+		)
+		if header.opCode == opMsg {
+			// The reply holds only the body section.
+			if len(message.Data) > headerLength+5 && message.Data[headerLength+4] == 0 {
+				elements, _ = readBsonDocument(message.Data, headerLength+5)
+			}
+		} else if len(message.Data) >= headerLength+20 {
+			flags := binary.LittleEndian.Uint32(message.Data[headerLength:])
+			failed = flags&replyFlagQueryFailure != 0
+			// Skip responseFlags, cursorID, startingFrom and numberReturned
+			elements, _ = readBsonDocument(message.Data, headerLength+20)
+		}
+
+		code, errMsg, ok := readError(elements)
+		if failed && !ok {
+			// The error document of OP_REPLY may not have "ok".
+			code, errMsg = readErrorFields(elements)
+		}
+		if ok || failed {
+			message.AddIntAttribute(constlabels.MongodbErrCode, code)
+			if errMsg != "" {
+				message.AddUtf8StringAttribute(constlabels.MongodbErrMsg, errMsg)
+			}
+			message.AddBoolAttribute(constlabels.IsError, true)
+			message.AddIntAttribute(constlabels.ErrorType, int64(constlabels.ProtocolError))
+		}
+		return true, true
+	}
+}
+
+// readError returns the error of the command if "ok" is 0, or the first error of the bulk writes,
+// which are reported in "writeErrors" even if "ok" is 1.
+func readError(elements []bsonElement) (code int64, errMsg string, failed bool) {
+	if ok, found := findBsonElement(elements, "ok"); found {
+		if value, isNumber := ok.intValue(); isNumber && value == 0 {
+			code, errMsg = readErrorFields(elements)
+			return code, errMsg, true
+		}
+	}
+	if writeErrors, found := findBsonElement(elements, "writeErrors"); found && writeErrors.kind == bsonArray {
+		if errs, _ := readBsonDocument(writeErrors.value, 0); len(errs) > 0 && errs[0].kind == bsonDocument {
+			first, _ := readBsonDocument(errs[0].value, 0)
+			code, errMsg = readErrorFields(first)
+			return code, errMsg, true
+		}
+	}
+	return 0, "", false
+}
+
+func readErrorFields(elements []bsonElement) (code int64, errMsg string) {
+	if element, ok := findBsonElement(elements, "code"); ok {
+		code, _ = element.intValue()
+	}
+	if element, ok := findBsonElement(elements, "errmsg"); ok {
+		errMsg = element.stringValue()
+	} else if element, ok := findBsonElement(elements, "$err"); ok {
+		errMsg = element.stringValue()
+	}
+	return code, errMsg
+}
@@ -0,0 +1,215 @@
+package mqtt
+
+import (
+	"encoding/binary"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+// The control packet types of MQTT 3.1.1 and 5.0. AUTH is only defined in 5.0.
+const (
+	packetConnect     = 1
+	packetConnack     = 2
+	packetPublish     = 3
+	packetPuback      = 4
+	packetPubrec      = 5
+	packetPubrel      = 6
+	packetPubcomp     = 7
+	packetSubscribe   = 8
+	packetSuback      = 9
+	packetUnsubscribe = 10
+	packetUnsuback    = 11
+	packetPingreq     = 12
+	packetPingresp    = 13
+	packetDisconnect  = 14
+	packetAuth        = 15
+)
+
+var packetNames = map[uint8]string{
+	packetConnect:     "CONNECT",
+	packetConnack:     "CONNACK",
+	packetPublish:     "PUBLISH",
+	packetPuback:      "PUBACK",
+	packetPubrec:      "PUBREC",
+	packetPubrel:      "PUBREL",
+	packetPubcomp:     "PUBCOMP",
+	packetSubscribe:   "SUBSCRIBE",
+	packetSuback:      "SUBACK",
+	packetUnsubscribe: "UNSUBSCRIBE",
+	packetUnsuback:    "UNSUBACK",
+	packetPingreq:     "PINGREQ",
+	packetPingresp:    "PINGRESP",
+	packetDisconnect:  "DISCONNECT",
+	packetAuth:        "AUTH",
+}
+
+const (
+	// maxRemainingLength is the max value of the 4-byte Variable Byte Integer.
+	maxRemainingLength = 268435455
+	// The flags of PUBREL, SUBSCRIBE and UNSUBSCRIBE are reserved as 0b0010, and the others except
+	// PUBLISH are 0.
+	reservedFlags = 0x02
+	// The QoS of PUBLISH is in the bits 1 and 2 of the flags, and 3 is malformed.
+	qosMask  = 0x06
+	qosShift = 1
+)
+
+// reasonCodeFailure is the lowest reason code of the failures in MQTT 5.0. SUBACK of MQTT 3.1.1 uses it
+// as the failure return code as well.
+const reasonCodeFailure = 0x80
+
+// protocolLevels maps the protocol level of CONNECT to the version of MQTT.
+var protocolLevels = map[uint8]string{
+	3: "3.1",
+	4: "3.1.1",
+	5: "5.0",
+}
+
+type packet struct {
+	packetType uint8
+	flags      uint8
+	// remainingLength is the length of the variable header and the payload.
+	remainingLength int
+	// body is the variable header and the payload, which may be truncated.
+	body []byte
+}
+
+// readPacket reads the fixed header of the first control packet in the data.
+func readPacket(data []byte) (*packet, bool) {
+	if len(data) < 2 {
+		return nil, false
+	}
+	p := &packet{packetType: data[0] >> 4, flags: data[0] & 0x0f}
+	if _, ok := packetNames[p.packetType]; !ok {
+		return nil, false
+	}
+	switch p.packetType {
+	case packetPublish:
+		if p.flags&qosMask == qosMask {
+			return nil, false
+		}
+	case packetPubrel, packetSubscribe, packetUnsubscribe:
+		if p.flags != reservedFlags {
+			return nil, false
+		}
+	default:
+		if p.flags != 0 {
+			return nil, false
+		}
+	}
+
+	// The remaining length is a Variable Byte Integer of at most 4 bytes.
+	multiplier := 1
+	offset := 1
+	for {
+		if offset >= len(data) || offset > 4 {
+			return nil, false
+		}
+		b := data[offset]
+		offset++
+		p.remainingLength += int(b&0x7f) * multiplier
+		if b&0x80 == 0 {
+			break
+		}
+		multiplier *= 128
+	}
+	if p.remainingLength > maxRemainingLength || !validLength(p.packetType, p.remainingLength) {
+		return nil, false
+	}
+	p.body = data[offset:]
+	if len(p.body) > p.remainingLength {
+		p.body = p.body[:p.remainingLength]
+	}
+	return p, true
+}
+
+// validLength checks the remaining length against the fixed lengths of the packets.
+func validLength(packetType uint8, length int) bool {
+	switch packetType {
+	case packetPingreq, packetPingresp:
+		return length == 0
+	case packetConnack, packetPuback, packetPubrec, packetPubrel, packetPubcomp, packetUnsuback:
+		return length >= 2
+	case packetConnect, packetSubscribe, packetSuback, packetUnsubscribe:
+		return length >= 3
+	case packetPublish:
+		return length >= 2
+	}
+	return true
+}
+
+func (p *packet) qos() uint8 {
+	return (p.flags & qosMask) >> qosShift
+}
+
+// readPacketId returns the Packet Identifier at the beginning of the variable header.
+func (p *packet) readPacketId() (uint16, bool) {
+	if len(p.body) < 2 {
+		return 0, false
+	}
+	return binary.BigEndian.Uint16(p.body), true
+}
+
+// readString reads the UTF-8 Encoded String at the offset and returns it with the offset after it.
+// The string truncated is returned as well.
+func readString(data []byte, offset int) (string, int, bool) {
+	if offset+2 > len(data) {
+		return "", offset, false
+	}
+	length := int(binary.BigEndian.Uint16(data[offset:]))
+	offset += 2
+	if offset+length > len(data) {
+		return string(data[offset:]), len(data), false
+	}
+	return string(data[offset : offset+length]), offset + length, true
+}
+
+func NewMqttParser() *protocol.ProtocolParser {
+	requestParser := protocol.CreatePkgParser(fastfailMqttRequest(), parseMqttRequest())
+	responseParser := protocol.CreatePkgParser(fastfailMqttResponse(), parseMqttResponse())
+	return protocol.NewProtocolParser(protocol.MQTT, requestParser, responseParser, mqttPair())
+}
+
+// mqttPair matches the acknowledgements with the requests by the packet type and the Packet Identifier,
+// as the messages of different QoS are acknowledged out of order.
+func mqttPair() protocol.PairMatch {
+	return func(requests []*protocol.PayloadMessage, response *protocol.PayloadMessage) int {
+		responseType := response.GetStringAttribute(constlabels.MqttResponseType)
+		for i, request := range requests {
+			if !isAcknowledgement(request, responseType) {
+				continue
+			}
+			if !request.HasAttribute(constlabels.MqttPacketId) ||
+				request.GetIntAttribute(constlabels.MqttPacketId) == response.GetIntAttribute(constlabels.MqttPacketId) {
+				return i
+			}
+		}
+		return -1
+	}
+}
+
+// isAcknowledgement returns whether the response of the type acknowledges the request.
+func isAcknowledgement(request *protocol.PayloadMessage, responseType string) bool {
+	switch request.GetStringAttribute(constlabels.MqttPacketType) {
+	case "CONNECT", "AUTH":
+		// The enhanced authentication of MQTT 5.0 exchanges AUTH before CONNACK.
+		return responseType == "CONNACK" || responseType == "AUTH"
+	case "PUBLISH":
+		if request.GetIntAttribute(constlabels.MqttQos) == 2 {
+			return responseType == "PUBREC"
+		}
+		return responseType == "PUBACK"
+	case "PUBREC":
+		return responseType == "PUBREL"
+	case "PUBREL":
+		return responseType == "PUBCOMP"
+	case "SUBSCRIBE":
+		return responseType == "SUBACK"
+	case "UNSUBSCRIBE":
+		return responseType == "UNSUBACK"
+	case "PINGREQ":
+		return responseType == "PINGRESP"
+	}
+	return false
+}
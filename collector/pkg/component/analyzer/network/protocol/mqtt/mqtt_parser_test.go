@@ -0,0 +1,174 @@
+package mqtt
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+func newPacket(header byte, body []byte) []byte {
+	data := []byte{header}
+	length := len(body)
+	for {
+		b := byte(length % 128)
+		length /= 128
+		if length > 0 {
+			b |= 0x80
+		}
+		data = append(data, b)
+		if length == 0 {
+			break
+		}
+	}
+	return append(data, body...)
+}
+
+func appendString(data []byte, s string) []byte {
+	data = binary.BigEndian.AppendUint16(data, uint16(len(s)))
+	return append(data, s...)
+}
+
+func newConnect(level byte, clientId string) []byte {
+	body := appendString(nil, "MQTT")
+	// Clean session and keep alive 60s
+	body = append(body, level, 0x02, 0, 60)
+	if level == 5 {
+		// Session Expiry Interval
+		body = append(body, 5, 0x11, 0, 0, 0, 10)
+	}
+	return newPacket(packetConnect<<4, appendString(body, clientId))
+}
+
+func newPublish(qos byte, topic string, packetId uint16, payload string) []byte {
+	body := appendString(nil, topic)
+	if qos > 0 {
+		body = binary.BigEndian.AppendUint16(body, packetId)
+	}
+	return newPacket(packetPublish<<4|qos<<qosShift, append(body, payload...))
+}
+
+func newAck(packetType byte, packetId uint16, codes ...byte) []byte {
+	return newPacket(packetType<<4, append(binary.BigEndian.AppendUint16(nil, packetId), codes...))
+}
+
+func newResponse(data []byte) *protocol.PayloadMessage {
+	return protocol.NewResponseMessage(data, protocol.NewRequestMessage(nil).GetAttributes())
+}
+
+func TestParseConnect(t *testing.T) {
+	parser := NewMqttParser()
+	for level, version := range map[byte]string{4: "3.1.1", 5: "5.0"} {
+		request := protocol.NewRequestMessage(newConnect(level, "gateway-01"))
+		assert.True(t, parser.ParseRequest(request))
+		assert.Equal(t, version, request.GetStringAttribute(constlabels.ProtocolVersion))
+		assert.Equal(t, "CONNECT", request.GetStringAttribute(constlabels.MqttPacketType))
+		assert.Equal(t, "gateway-01", request.GetStringAttribute(constlabels.MqttClientId))
+		assert.Equal(t, "CONNECT", request.GetStringAttribute(constlabels.ContentKey))
+	}
+
+	requests := []*protocol.PayloadMessage{protocol.NewRequestMessage(newConnect(4, "gateway-01"))}
+	assert.True(t, parser.ParseRequest(requests[0]))
+	// Connection Refused, not authorized
+	response := newResponse(newPacket(packetConnack<<4, []byte{0, 5}))
+	assert.True(t, parser.ParseResponse(response))
+	assert.Equal(t, 0, parser.PairMatch(requests, response))
+	assert.Equal(t, int64(5), response.GetIntAttribute(constlabels.MqttReasonCode))
+	assert.True(t, response.GetBoolAttribute(constlabels.IsError))
+}
+
+func TestParsePublish(t *testing.T) {
+	parser := NewMqttParser()
+	first := protocol.NewRequestMessage(newPublish(1, "devices/7f3a9/telemetry", 10, `{"t":21.5}`))
+	assert.True(t, parser.ParseRequest(first))
+	assert.Equal(t, "devices/7f3a9/telemetry", first.GetStringAttribute(constlabels.MqttTopic))
+	assert.Equal(t, int64(1), first.GetIntAttribute(constlabels.MqttQos))
+	assert.Equal(t, int64(10), first.GetIntAttribute(constlabels.MqttPacketId))
+	assert.Equal(t, "PUBLISH devices/*/telemetry", first.GetStringAttribute(constlabels.ContentKey))
+
+	second := protocol.NewRequestMessage(newPublish(2, "alarms", 11, "fire"))
+	assert.True(t, parser.ParseRequest(second))
+	third := protocol.NewRequestMessage(newPublish(1, "alarms", 12, "smoke"))
+	assert.True(t, parser.ParseRequest(third))
+	requests := []*protocol.PayloadMessage{first, second, third}
+
+	// QoS 2 is acknowledged by PUBREC.
+	response := newResponse(newAck(packetPubrec, 11))
+	assert.True(t, parser.ParseResponse(response))
+	assert.Equal(t, 1, parser.PairMatch(requests, response))
+	assert.False(t, response.GetBoolAttribute(constlabels.IsError))
+
+	// The reason code of MQTT 5.0, Not authorized
+	response = newResponse(newAck(packetPuback, 12, 0x87))
+	assert.True(t, parser.ParseResponse(response))
+	assert.Equal(t, 2, parser.PairMatch(requests, response))
+	assert.Equal(t, int64(0x87), response.GetIntAttribute(constlabels.MqttReasonCode))
+	assert.True(t, response.GetBoolAttribute(constlabels.IsError))
+
+	response = newResponse(newAck(packetPuback, 10))
+	assert.True(t, parser.ParseResponse(response))
+	assert.Equal(t, 0, parser.PairMatch(requests, response))
+	assert.Equal(t, int64(0), response.GetIntAttribute(constlabels.MqttReasonCode))
+
+	request := protocol.NewRequestMessage(newPublish(0, "devices/7f3a9/status", 0, "online"))
+	assert.True(t, parser.ParseRequest(request))
+	assert.True(t, request.GetBoolAttribute(constlabels.Oneway))
+	assert.False(t, request.HasAttribute(constlabels.MqttPacketId))
+
+	// The messages delivered to the subscribers
+	response = newResponse(newPublish(1, "commands/7f3a9", 3, "reboot"))
+	assert.True(t, parser.ParseResponse(response))
+	assert.True(t, response.GetBoolAttribute(constlabels.Oneway))
+}
+
+func TestParseSubscribe(t *testing.T) {
+	parser := NewMqttParser()
+	body := binary.BigEndian.AppendUint16(nil, 7)
+	body = appendString(body, "devices/+/commands")
+	request := protocol.NewRequestMessage(newPacket(packetSubscribe<<4|reservedFlags, append(body, 1)))
+	assert.True(t, parser.ParseRequest(request))
+	assert.Equal(t, "devices/+/commands", request.GetStringAttribute(constlabels.MqttTopic))
+	assert.Equal(t, "SUBSCRIBE devices/*/commands", request.GetStringAttribute(constlabels.ContentKey))
+
+	// MQTT 5.0 with the Subscription Identifier
+	body = binary.BigEndian.AppendUint16(nil, 8)
+	body = append(body, 2, 0x0b, 1)
+	body = appendString(body, "alarms/#")
+	v5Request := protocol.NewRequestMessage(newPacket(packetSubscribe<<4|reservedFlags, append(body, 2)))
+	assert.True(t, parser.ParseRequest(v5Request))
+	assert.Equal(t, "alarms/#", v5Request.GetStringAttribute(constlabels.MqttTopic))
+	requests := []*protocol.PayloadMessage{request, v5Request}
+
+	response := newResponse(newAck(packetSuback, 8, 0, 0x80))
+	assert.True(t, parser.ParseResponse(response))
+	assert.Equal(t, 1, parser.PairMatch(requests, response))
+	assert.Equal(t, int64(0x80), response.GetIntAttribute(constlabels.MqttReasonCode))
+	assert.True(t, response.GetBoolAttribute(constlabels.IsError))
+
+	response = newResponse(newAck(packetSuback, 7, 1))
+	assert.True(t, parser.ParseResponse(response))
+	assert.Equal(t, 0, parser.PairMatch(requests, response))
+	assert.Equal(t, int64(1), response.GetIntAttribute(constlabels.MqttReasonCode))
+	assert.False(t, response.GetBoolAttribute(constlabels.IsError))
+}
+
+func TestParseInvalid(t *testing.T) {
+	parser := NewMqttParser()
+	for _, data := range [][]byte{
+		[]byte("GET / HTTP/1.1\r\n"),
+		// Reserved flags of SUBSCRIBE
+		newPacket(packetSubscribe<<4, []byte{0, 1, 0, 1, 'a', 0}),
+		// PINGREQ with the body
+		newPacket(packetPingreq<<4, []byte{0}),
+		// QoS 3
+		newPublish(3, "a", 1, ""),
+		// The packets of the servers
+		newAck(packetSuback, 1, 0),
+	} {
+		assert.False(t, parser.ParseRequest(protocol.NewRequestMessage(data)), data)
+	}
+	assert.False(t, parser.ParseResponse(newResponse(newConnect(4, "a"))))
+}
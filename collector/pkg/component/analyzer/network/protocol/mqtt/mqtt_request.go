@@ -0,0 +1,134 @@
+package mqtt
+
+import (
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/urlclustering"
+)
+
+// The levels of the topics are separated by "/" like the URLs, so the topics are clustered in the same
+// way to keep the cardinality of the content key low, e.g. "devices/*/telemetry" for
+// "devices/7f3a9/telemetry".
+var topicClustering = urlclustering.NewAlphabeticalClusteringMethod()
+
+func fastfailMqttRequest() protocol.FastFailFn {
+	return func(message *protocol.PayloadMessage) bool {
+		return len(message.Data) < 2
+	}
+}
+
+// parseMqttRequest reads the first control packet sent by the client. PUBLISH of QoS 0, DISCONNECT and
+// the acknowledgements of the messages received are not responded.
+func parseMqttRequest() protocol.ParsePkgFn {
+	return func(message *protocol.PayloadMessage) (bool, bool) {
+		p, ok := readPacket(message.Data)
+		if !ok {
+			return false, true
+		}
+		packetType := packetNames[p.packetType]
+		contentKey := packetType
+		switch p.packetType {
+		case packetConnect:
+			clientId, level, ok := readClientId(p.body)
+			if !ok {
+				return false, true
+			}
+			if version, found := protocolLevels[level]; found {
+				message.AddStringAttribute(constlabels.ProtocolVersion, version)
+			}
+			if clientId != "" {
+				message.AddUtf8StringAttribute(constlabels.MqttClientId, clientId)
+			}
+		case packetPublish:
+			topic, offset, _ := readString(p.body, 0)
+			if offset < 2 {
+				return false, true
+			}
+			// The topic is empty if the Topic Alias of MQTT 5.0 is used instead.
+			if topic != "" {
+				message.AddUtf8StringAttribute(constlabels.MqttTopic, topic)
+			}
+			message.AddIntAttribute(constlabels.MqttQos, int64(p.qos()))
+			if p.qos() == 0 {
+				message.AddBoolAttribute(constlabels.Oneway, true)
+			} else if offset+2 <= len(p.body) {
+				message.AddIntAttribute(constlabels.MqttPacketId, int64(uint16(p.body[offset])<<8|uint16(p.body[offset+1])))
+			}
+			if topic != "" {
+				contentKey += " " + topicClustering.Clustering(topic)
+			}
+		case packetSubscribe, packetUnsubscribe:
+			packetId, _ := p.readPacketId()
+			message.AddIntAttribute(constlabels.MqttPacketId, int64(packetId))
+			if filter, ok := readTopicFilter(p.body); ok {
+				message.AddUtf8StringAttribute(constlabels.MqttTopic, filter)
+				contentKey += " " + topicClustering.Clustering(filter)
+			}
+		case packetPubrec, packetPubrel:
+			packetId, _ := p.readPacketId()
+			message.AddIntAttribute(constlabels.MqttPacketId, int64(packetId))
+		case packetPuback, packetPubcomp, packetDisconnect:
+			message.AddBoolAttribute(constlabels.Oneway, true)
+		case packetConnack, packetSuback, packetUnsuback, packetPingresp:
+			// Only sent by the servers
+			return false, true
+		}
+		message.AddStringAttribute(constlabels.MqttPacketType, packetType)
+		message.AddUtf8StringAttribute(constlabels.ContentKey, contentKey)
+		return true, true
+	}
+}
+
+// readClientId reads the Client Identifier and the protocol level of CONNECT. The protocol name is
+// "MQTT" since 3.1.1 and "MQIsdp" in 3.1.
+func readClientId(body []byte) (string, byte, bool) {
+	name, offset, ok := readString(body, 0)
+	if !ok || (name != "MQTT" && name != "MQIsdp") {
+		return "", 0, false
+	}
+	// The protocol level, the connect flags and the keep alive
+	if offset+4 > len(body) {
+		return "", 0, true
+	}
+	level := body[offset]
+	offset += 4
+	if level == 5 {
+		propertiesLength, n, ok := readVariableInt(body[offset:])
+		if !ok {
+			return "", level, true
+		}
+		offset += n + propertiesLength
+	}
+	clientId, _, _ := readString(body, offset)
+	return clientId, level, true
+}
+
+// readTopicFilter reads the first topic filter of SUBSCRIBE or UNSUBSCRIBE. The properties of MQTT 5.0
+// precede the filters, so the layout of MQTT 3.1.1 is tried first.
+func readTopicFilter(body []byte) (string, bool) {
+	if len(body) < 3 {
+		return "", false
+	}
+	if filter, _, ok := readString(body, 2); ok && filter != "" {
+		return filter, true
+	}
+	propertiesLength, n, ok := readVariableInt(body[2:])
+	if !ok {
+		return "", false
+	}
+	filter, _, ok := readString(body, 2+n+propertiesLength)
+	return filter, ok && filter != ""
+}
+
+// readVariableInt reads the Variable Byte Integer and returns it with the number of the bytes.
+func readVariableInt(data []byte) (int, int, bool) {
+	value, multiplier := 0, 1
+	for i := 0; i < len(data) && i < 4; i++ {
+		value += int(data[i]&0x7f) * multiplier
+		if data[i]&0x80 == 0 {
+			return value, i + 1, true
+		}
+		multiplier *= 128
+	}
+	return 0, 0, false
+}
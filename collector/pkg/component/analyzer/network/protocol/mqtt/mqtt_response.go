@@ -0,0 +1,87 @@
+package mqtt
+
+import (
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+func fastfailMqttResponse() protocol.FastFailFn {
+	return func(message *protocol.PayloadMessage) bool {
+		return len(message.Data) < 2
+	}
+}
+
+// parseMqttResponse reads the first control packet sent by the server. PUBLISH delivered to the
+// subscribers and DISCONNECT are pushed by the server and not paired with any request.
+func parseMqttResponse() protocol.ParsePkgFn {
+	return func(message *protocol.PayloadMessage) (bool, bool) {
+		p, ok := readPacket(message.Data)
+		if !ok {
+			return false, true
+		}
+		var reasonCode uint8
+		switch p.packetType {
+		case packetPublish, packetDisconnect:
+			message.AddBoolAttribute(constlabels.Oneway, true)
+			return true, true
+		case packetConnack:
+			// The Connect Acknowledge Flags and the return code. Any code other than 0 refuses the
+			// connection, e.g. 5 "not authorized" of 3.1.1 and 0x87 of 5.0.
+			if len(p.body) < 2 {
+				return false, true
+			}
+			reasonCode = p.body[1]
+			message.AddIntAttribute(constlabels.MqttReasonCode, int64(reasonCode))
+			if reasonCode != 0 {
+				addError(message)
+			}
+		case packetPuback, packetPubrec, packetPubrel, packetPubcomp:
+			packetId, _ := p.readPacketId()
+			message.AddIntAttribute(constlabels.MqttPacketId, int64(packetId))
+			// The reason code of MQTT 5.0 is omitted if it is 0 and there are no properties.
+			if p.remainingLength > 2 && len(p.body) > 2 {
+				reasonCode = p.body[2]
+			}
+			message.AddIntAttribute(constlabels.MqttReasonCode, int64(reasonCode))
+			if reasonCode >= reasonCodeFailure {
+				addError(message)
+			}
+		case packetSuback, packetUnsuback:
+			packetId, _ := p.readPacketId()
+			message.AddIntAttribute(constlabels.MqttPacketId, int64(packetId))
+			// Each topic filter has a code, which is the granted QoS or a failure.
+			if len(p.body) > 2 {
+				reasonCode = readSubscriptionCode(p.body[2:], p.remainingLength-2)
+			}
+			message.AddIntAttribute(constlabels.MqttReasonCode, int64(reasonCode))
+			if reasonCode >= reasonCodeFailure {
+				addError(message)
+			}
+		case packetConnect, packetSubscribe, packetUnsubscribe, packetPingreq:
+			// Only sent by the clients
+			return false, true
+		}
+		message.AddStringAttribute(constlabels.MqttResponseType, packetNames[p.packetType])
+		return true, true
+	}
+}
+
+// readSubscriptionCode returns the first failure code of SUBACK or UNSUBACK, or the last code if all
+// the subscriptions succeed. The codes are only read if the packet is complete. The properties of
+// MQTT 5.0 preceding the codes are rarely sent by the servers, so all the bytes are taken as the codes.
+func readSubscriptionCode(codes []byte, length int) uint8 {
+	if len(codes) < length {
+		return 0
+	}
+	for _, code := range codes {
+		if code >= reasonCodeFailure {
+			return code
+		}
+	}
+	return codes[len(codes)-1]
+}
+
+func addError(message *protocol.PayloadMessage) {
+	message.AddBoolAttribute(constlabels.IsError, true)
+	message.AddIntAttribute(constlabels.ErrorType, int64(constlabels.ProtocolError))
+}
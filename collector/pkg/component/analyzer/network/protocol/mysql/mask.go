@@ -0,0 +1,57 @@
+package mysql
+
+import (
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+)
+
+// maskSqlLiterals masks the string and numeric literals of the statement in place.
+// The identifiers quoted with backticks are kept.
+func maskSqlLiterals(sql []byte) {
+	for i := 0; i < len(sql); i++ {
+		switch c := sql[i]; {
+		case c == '`':
+			i = skipQuoted(sql, i, false)
+		case c == '\'' || c == '"':
+			end := skipQuoted(sql, i, true)
+			protocol.MaskBytes(sql[i+1 : end])
+			i = end
+		case isDigit(c) && (i == 0 || !isIdentifierByte(sql[i-1])):
+			end := i + 1
+			for end < len(sql) && (isIdentifierByte(sql[end]) || sql[end] == '.') {
+				end++
+			}
+			protocol.MaskBytes(sql[i:end])
+			i = end - 1
+		}
+	}
+}
+
+// skipQuoted returns the index of the closing quote of the quoted text starting at start, or the
+// length of sql if it is not closed. The backslash escapes the next byte if escapable is true.
+func skipQuoted(sql []byte, start int, escapable bool) int {
+	quote := sql[start]
+	for i := start + 1; i < len(sql); i++ {
+		switch sql[i] {
+		case '\\':
+			if escapable {
+				i++
+			}
+		case quote:
+			// Two quotes in a row are an escaped quote.
+			if i+1 < len(sql) && sql[i+1] == quote {
+				i++
+				continue
+			}
+			return i
+		}
+	}
+	return len(sql)
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func isIdentifierByte(c byte) bool {
+	return isDigit(c) || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || c == '_' || c == '$'
+}
@@ -0,0 +1,43 @@
+package mysql
+
+import (
+	"testing"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+func TestMaskSqlLiterals(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{sql: "SELECT * FROM user WHERE name = 'alice' AND age > 30", want: "SELECT * FROM user WHERE name = '*****' AND age > **"},
+		{sql: `UPDATE t1 SET pwd = "it's \"x\"" WHERE id=2.5`, want: `UPDATE t1 SET pwd = "**********" WHERE id=***`},
+		{sql: "INSERT INTO `order2` VALUES ('a''b', 0x1F)", want: "INSERT INTO `order2` VALUES ('****', ****)"},
+		{sql: "SELECT * FROM t WHERE name = 'unclosed", want: "SELECT * FROM t WHERE name = '********"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sql, func(t *testing.T) {
+			sql := []byte(tt.sql)
+			maskSqlLiterals(sql)
+			if string(sql) != tt.want {
+				t.Errorf("maskSqlLiterals() = %v, want %v", string(sql), tt.want)
+			}
+		})
+	}
+}
+
+func TestParseMysqlQuery_MaskLiterals(t *testing.T) {
+	data := append([]byte{0x1a, 0x00, 0x00, 0x00, 0x03}, "SELECT * FROM user WHERE id=1"...)
+	message := protocol.NewRequestMessage(data)
+	if !NewMysqlParser(true).ParseRequest(message) {
+		t.Fatal("failed to parse the query")
+	}
+	if got := message.GetStringAttribute(constlabels.Sql); got != "SELECT * FROM user WHERE id=*" {
+		t.Errorf("sql = %v", got)
+	}
+	if got := string(data[5:]); got != "SELECT * FROM user WHERE id=*" {
+		t.Errorf("payload = %v", got)
+	}
+}
@@ -4,15 +4,23 @@ import (
 	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
 )
 
+// protocolVersion is the version of the client/server protocol.
+const protocolVersion = "10"
+
+// minCaptureLength is the length of the payloads holding the beginning of the statements.
+const minCaptureLength = 512
+
 /*
 		    Request                                       Response
 		/     |       \                                 /     |    \
 	 prepare query   quit                              err   ok    eof
 */
-func NewMysqlParser() *protocol.ProtocolParser {
+// NewMysqlParser creates the parser of MySQL. If maskLiterals is true, the literals of the
+// statements are masked.
+func NewMysqlParser(maskLiterals bool) *protocol.ProtocolParser {
 	requestParser := protocol.CreatePkgParser(fastfailMysqlRequest(), parseMysqlRequest())
-	requestParser.Add(fastfailMysqlPrepare(), parseMysqlPrepare())
-	requestParser.Add(fastfailMysqlQuery(), parseMysqlQuery())
+	requestParser.Add(fastfailMysqlPrepare(), parseMysqlPrepare(maskLiterals))
+	requestParser.Add(fastfailMysqlQuery(), parseMysqlQuery(maskLiterals))
 	requestParser.Add(fastfailMysqlQuit(), parseMysqlQuit())
 
 	responseParser := protocol.CreatePkgParser(fastfailMysqlResponse(), parseMysqlResponse())
@@ -21,5 +29,8 @@ func NewMysqlParser() *protocol.ProtocolParser {
 	responseParser.Add(fastfailMysqlEof(), parseMysqlEof())
 	responseParser.Add(fastfailMysqlResultSet(), parseMysqlResultSet())
 
-	return protocol.NewProtocolParser(protocol.MYSQL, requestParser, responseParser, nil)
+	parser := protocol.NewProtocolParser(protocol.MYSQL, requestParser, responseParser, nil)
+	// Only the first packet is parsed, whose statement is still merged if it is truncated.
+	parser.SetMinCaptureLength(minCaptureLength)
+	return parser
 }
@@ -21,6 +21,8 @@ func fastfailMysqlRequest() protocol.FastFailFn {
 
 func parseMysqlRequest() protocol.ParsePkgFn {
 	return func(message *protocol.PayloadMessage) (bool, bool) {
+		// The commands do not carry the version, which is 10 in the handshake of all the servers since 3.21.
+		message.AddStringAttribute(constlabels.ProtocolVersion, protocolVersion)
 		return true, false
 	}
 }
@@ -36,12 +38,16 @@ func fastfailMysqlPrepare() protocol.FastFailFn {
 	}
 }
 
-func parseMysqlPrepare() protocol.ParsePkgFn {
+func parseMysqlPrepare(maskLiterals bool) protocol.ParsePkgFn {
 	return func(message *protocol.PayloadMessage) (bool, bool) {
 		sql := string(message.Data[5:])
 		if !isSql(sql) {
 			return false, true
 		}
+		if maskLiterals {
+			maskSqlLiterals(message.Data[5:])
+			sql = string(message.Data[5:])
+		}
 		message.AddUtf8StringAttribute(constlabels.Sql, sql)
 		message.AddUtf8StringAttribute(constlabels.ContentKey, tools.SQL_MERGER.ParseStatement(sql))
 		return true, true
@@ -65,17 +71,22 @@ func fastfailMysqlQuery() protocol.FastFailFn {
 	}
 }
 
-func parseMysqlQuery() protocol.ParsePkgFn {
+func parseMysqlQuery(maskLiterals bool) protocol.ParsePkgFn {
 	return func(message *protocol.PayloadMessage) (bool, bool) {
-		sql := string(message.Data[5:])
-		if len(sql) > 2 && sql[0] == 0x00 && sql[1] == 0x01 {
+		sqlData := message.Data[5:]
+		if len(sqlData) > 2 && sqlData[0] == 0x00 && sqlData[1] == 0x01 {
 			// Only Fix Zero params Case.
 			// TODO Fix One more params case.
-			sql = sql[2:]
+			sqlData = sqlData[2:]
 		}
+		sql := string(sqlData)
 		if !isSql(sql) {
 			return false, true
 		}
+		if maskLiterals {
+			maskSqlLiterals(sqlData)
+			sql = string(sqlData)
+		}
 
 		message.AddUtf8StringAttribute(constlabels.Sql, sql)
 		message.AddUtf8StringAttribute(constlabels.ContentKey, tools.SQL_MERGER.ParseStatement(sql))
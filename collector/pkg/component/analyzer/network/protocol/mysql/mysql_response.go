@@ -36,7 +36,7 @@ string<EOF>	error_message
 */
 func fastfailMysqlErr() protocol.FastFailFn {
 	return func(message *protocol.PayloadMessage) bool {
-		return message.Data[4] != 0xff
+		return len(message.Data) < 7 || message.Data[4] != 0xff
 	}
 }
 
@@ -47,7 +47,7 @@ func parseMysqlErr() protocol.ParsePkgFn {
 		var errorMessage string
 		if len(message.Data) > 14 && message.Data[8] == '#' {
 			errorMessage = string(message.Data[8:13]) + ":" + string(message.Data[13:])
-		} else {
+		} else if len(message.Data) > 8 {
 			errorMessage = string(message.Data[8:])
 		}
 
@@ -89,10 +89,51 @@ func fastfailMysqlOk() protocol.FastFailFn {
 
 func parseMysqlOk() protocol.ParsePkgFn {
 	return func(message *protocol.PayloadMessage) (bool, bool) {
+		// The EOF packet in the place of the OK packet carries no affected rows.
+		if message.Data[4] != 0x00 {
+			return true, true
+		}
+		if affectedRows, ok := readLengthEncodedInt(message.Data[5:]); ok {
+			message.AddIntAttribute(constlabels.SqlAffectedRows, int64(affectedRows))
+		}
 		return true, true
 	}
 }
 
+/*
+int<lenenc>
+	< 0xfb	1-byte integer
+	0xfc	2-byte integer follows
+	0xfd	3-byte integer follows
+	0xfe	8-byte integer follows
+*/
+func readLengthEncodedInt(data []byte) (uint64, bool) {
+	if len(data) == 0 {
+		return 0, false
+	}
+	switch data[0] {
+	case 0xfb, 0xff:
+		return 0, false
+	case 0xfc:
+		if len(data) < 3 {
+			return 0, false
+		}
+		return uint64(binary.LittleEndian.Uint16(data[1:3])), true
+	case 0xfd:
+		if len(data) < 4 {
+			return 0, false
+		}
+		return uint64(data[1]) | uint64(data[2])<<8 | uint64(data[3])<<16, true
+	case 0xfe:
+		if len(data) < 9 {
+			return 0, false
+		}
+		return binary.LittleEndian.Uint64(data[1:9]), true
+	default:
+		return uint64(data[0]), true
+	}
+}
+
 /*
 ===== PayLoad =====
 int<1>	header(0xFE)
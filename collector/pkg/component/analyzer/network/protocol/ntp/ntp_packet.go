@@ -0,0 +1,116 @@
+package ntp
+
+import (
+	"encoding/binary"
+	"strings"
+)
+
+// The modes of the NTP packets, see RFC 5905 section 7.3.
+const (
+	ModeSymmetricActive  = 1
+	ModeSymmetricPassive = 2
+	ModeClient           = 3
+	ModeServer           = 4
+	ModeBroadcast        = 5
+
+	// headerLength is the length of the packet without the extension fields and the MAC.
+	headerLength = 48
+	// unixEpochOffset is the seconds from the NTP era 0 (1900-01-01) to the Unix epoch.
+	unixEpochOffset = 2208988800
+)
+
+// Packet is the header of the NTP packet.
+type Packet struct {
+	Version uint8
+	Mode    uint8
+	// Stratum is 1 for the primary servers and 0 for the Kiss-o'-Death packets.
+	Stratum uint8
+	// ReferenceId is the reference clock of the stratum 1 servers, the IPv4 address of the upstream of
+	// the others, or the kiss code of the Kiss-o'-Death packets.
+	ReferenceId [4]byte
+	// OriginTimestamp is the transmit timestamp of the request echoed by the server, which pairs the
+	// response with the request.
+	OriginTimestamp   uint64
+	ReceiveTimestamp  uint64
+	TransmitTimestamp uint64
+}
+
+// ParsePacket returns the header of the NTP packet, or false if the data is not an NTP packet.
+//
+//	uint8  leap indicator (2 bits), version (3 bits) and mode (3 bits)
+//	uint8  stratum
+//	int8   poll
+//	int8   precision
+//	uint32 root delay
+//	uint32 root dispersion
+//	uint32 reference id
+//	uint64 reference timestamp
+//	uint64 origin timestamp
+//	uint64 receive timestamp
+//	uint64 transmit timestamp
+func ParsePacket(data []byte) (*Packet, bool) {
+	if len(data) < headerLength {
+		return nil, false
+	}
+	packet := &Packet{
+		Version:           (data[0] >> 3) & 0x07,
+		Mode:              data[0] & 0x07,
+		Stratum:           data[1],
+		OriginTimestamp:   binary.BigEndian.Uint64(data[24:]),
+		ReceiveTimestamp:  binary.BigEndian.Uint64(data[32:]),
+		TransmitTimestamp: binary.BigEndian.Uint64(data[40:]),
+	}
+	copy(packet.ReferenceId[:], data[12:16])
+	if packet.Version < 1 || packet.Version > 4 || packet.Mode < ModeSymmetricActive || packet.Mode > ModeBroadcast {
+		return nil, false
+	}
+	// The stratum 16 is unsynchronized and the greater ones are reserved.
+	if packet.Stratum > 16 {
+		return nil, false
+	}
+	return packet, true
+}
+
+// IsKissOfDeath returns true if the server refuses to serve the client, e.g. "RATE" for the clients
+// sending too fast and "DENY" for the denied ones.
+func (p *Packet) IsKissOfDeath() bool {
+	return p.Mode == ModeServer && p.Stratum == 0
+}
+
+// KissCode returns the ASCII kiss code of the Kiss-o'-Death packet.
+func (p *Packet) KissCode() string {
+	return strings.TrimRight(string(p.ReferenceId[:]), "\x00")
+}
+
+// ModeString returns the name of the mode, e.g. "client" and "server".
+func ModeString(mode uint8) string {
+	switch mode {
+	case ModeSymmetricActive:
+		return "symmetric_active"
+	case ModeSymmetricPassive:
+		return "symmetric_passive"
+	case ModeClient:
+		return "client"
+	case ModeServer:
+		return "server"
+	case ModeBroadcast:
+		return "broadcast"
+	}
+	return "unknown"
+}
+
+// ToUnixNano converts the NTP timestamp of 32-bit seconds and 32-bit fraction to the nanoseconds since the
+// Unix epoch. The timestamps in the era 1 after 2036 are not converted correctly.
+func ToUnixNano(timestamp uint64) int64 {
+	seconds := int64(timestamp>>32) - unixEpochOffset
+	fraction := int64((timestamp & 0xffffffff) * 1e9 >> 32)
+	return seconds*1e9 + fraction
+}
+
+// ClockOffset returns the offset of the local clock against the server in nanoseconds, which is positive
+// if the local clock is behind. The request is sent at requestTime and the response is received at
+// responseTime by the local clock, which are taken from the events instead of the packets, as the clients
+// like chrony randomize the transmit timestamps of the requests.
+func ClockOffset(response *Packet, requestTime int64, responseTime int64) int64 {
+	return ((ToUnixNano(response.ReceiveTimestamp) - requestTime) + (ToUnixNano(response.TransmitTimestamp) - responseTime)) / 2
+}
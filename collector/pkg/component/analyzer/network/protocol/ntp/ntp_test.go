@@ -0,0 +1,62 @@
+package ntp
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fromUnixNano converts the nanoseconds since the Unix epoch to the NTP timestamp.
+func fromUnixNano(nanos int64) uint64 {
+	seconds := uint64(nanos/1e9 + unixEpochOffset)
+	fraction := (uint64(nanos%1e9)<<32 + 1e9 - 1) / 1e9
+	return seconds<<32 | fraction
+}
+
+func newPacket(version uint8, mode uint8, stratum uint8, referenceId string, origin, receive, transmit uint64) []byte {
+	data := make([]byte, headerLength)
+	data[0] = version<<3 | mode
+	data[1] = stratum
+	copy(data[12:16], referenceId)
+	binary.BigEndian.PutUint64(data[24:], origin)
+	binary.BigEndian.PutUint64(data[32:], receive)
+	binary.BigEndian.PutUint64(data[40:], transmit)
+	return data
+}
+
+func TestParsePacket(t *testing.T) {
+	packet, ok := ParsePacket(newPacket(4, ModeServer, 2, "\x0a\x00\x00\x01", 1, 2, 3))
+	if assert.True(t, ok) {
+		assert.Equal(t, uint8(4), packet.Version)
+		assert.Equal(t, "server", ModeString(packet.Mode))
+		assert.Equal(t, uint8(2), packet.Stratum)
+		assert.Equal(t, uint64(1), packet.OriginTimestamp)
+		assert.False(t, packet.IsKissOfDeath())
+	}
+
+	packet, ok = ParsePacket(newPacket(4, ModeServer, 0, "RATE", 1, 0, 0))
+	if assert.True(t, ok) {
+		assert.True(t, packet.IsKissOfDeath())
+		assert.Equal(t, "RATE", packet.KissCode())
+	}
+
+	_, ok = ParsePacket(newPacket(4, ModeClient, 0, "", 0, 0, 1)[:47])
+	assert.False(t, ok)
+	_, ok = ParsePacket(newPacket(0, ModeClient, 0, "", 0, 0, 1))
+	assert.False(t, ok)
+	_, ok = ParsePacket(newPacket(4, 7, 0, "", 0, 0, 1))
+	assert.False(t, ok)
+}
+
+func TestClockOffset(t *testing.T) {
+	const second = int64(1e9)
+	requestTime := 1700000000 * second
+	// The server is 3s ahead of the local clock, and each direction takes 10ms.
+	response := &Packet{
+		ReceiveTimestamp:  fromUnixNano(requestTime + 3*second + 10e6),
+		TransmitTimestamp: fromUnixNano(requestTime + 3*second + 12e6),
+	}
+	assert.Equal(t, requestTime+3*second+10e6, ToUnixNano(response.ReceiveTimestamp))
+	assert.Equal(t, 3*second, ClockOffset(response, requestTime, requestTime+22e6))
+}
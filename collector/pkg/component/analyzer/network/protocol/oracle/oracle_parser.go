@@ -0,0 +1,105 @@
+package oracle
+
+import (
+	"encoding/binary"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+)
+
+// The packet types of TNS.
+const (
+	packetConnect  = 1
+	packetAccept   = 2
+	packetRefuse   = 4
+	packetRedirect = 5
+	packetData     = 6
+	packetResend   = 11
+	packetMarker   = 12
+)
+
+// The message types of TTC carried by the data packets.
+const (
+	ttcProtocol          = 1
+	ttcDataTypes         = 2
+	ttcFunction          = 3
+	ttcPiggyback         = 17
+	ttcOnewayFunction    = 26
+	dataFlagEndOfSession = 0x0040
+)
+
+// The function codes of the TTC calls.
+var functions = map[byte]string{
+	4:   "reexecute",
+	5:   "fetch",
+	9:   "logoff",
+	14:  "commit",
+	15:  "rollback",
+	59:  "version",
+	78:  "reexecute",
+	94:  "execute",
+	96:  "lob",
+	105: "close_cursors",
+	115: "auth",
+	118: "auth",
+	135: "set_attributes",
+	147: "ping",
+	162: "session_get",
+	163: "session_release",
+}
+
+const (
+	headerLength = 8
+	// minVersion and maxVersion bound the versions of TNS in the connect packets, e.g. 314 of 11g and 319 of 19c.
+	minVersion = 300
+	maxVersion = 400
+	// maxPacketLength is the max SDU, 2MB since 19c.
+	maxPacketLength = 1 << 21
+)
+
+type packet struct {
+	packetType byte
+	// body is the content after the header, which may be truncated.
+	body []byte
+	// end is the offset after the packet, which may exceed the data if it is truncated.
+	end int
+}
+
+// readPacket reads the TNS packet at the offset. The length of the packet takes 2 bytes followed by the
+// checksum of the packet, which is always 0, or 4 bytes once a large SDU is negotiated by TNS 315 and later.
+func readPacket(data []byte, offset int) (*packet, bool) {
+	if offset+headerLength > len(data) {
+		return nil, false
+	}
+	header := data[offset : offset+headerLength]
+	var length int
+	if binary.BigEndian.Uint16(header[0:2]) == 0 {
+		length = int(binary.BigEndian.Uint32(header[0:4]))
+	} else if binary.BigEndian.Uint16(header[2:4]) == 0 {
+		length = int(binary.BigEndian.Uint16(header[0:2]))
+	} else {
+		return nil, false
+	}
+	// The checksum of the header is always 0.
+	if length < headerLength || length > maxPacketLength || binary.BigEndian.Uint16(header[6:8]) != 0 {
+		return nil, false
+	}
+	switch header[4] {
+	case packetConnect, packetAccept, packetRefuse, packetRedirect, packetData, packetResend, packetMarker:
+	default:
+		return nil, false
+	}
+	end := offset + length
+	body := data[offset+headerLength:]
+	if len(body) > length-headerLength {
+		body = body[:length-headerLength]
+	}
+	return &packet{packetType: header[4], body: body, end: end}, true
+}
+
+// NewOracleParser creates the parser of Oracle TNS. The calls of a session are synchronous, so the responses
+// are paired with the requests in order.
+func NewOracleParser() *protocol.ProtocolParser {
+	requestParser := protocol.CreatePkgParser(fastfailOracleRequest(), parseOracleRequest())
+	responseParser := protocol.CreatePkgParser(fastfailOracleResponse(), parseOracleResponse())
+	return protocol.NewProtocolParser(protocol.ORACLE, requestParser, responseParser, nil)
+}
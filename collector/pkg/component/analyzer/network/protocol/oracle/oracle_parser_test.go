@@ -0,0 +1,121 @@
+package oracle
+
+import (
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+func newPacket(packetType byte, body []byte) []byte {
+	header := make([]byte, headerLength)
+	binary.BigEndian.PutUint16(header[0:2], uint16(headerLength+len(body)))
+	header[4] = packetType
+	return append(header, body...)
+}
+
+// newLargePacket creates the packet whose length takes 4 bytes, which is sent after a large SDU is negotiated.
+func newLargePacket(packetType byte, body []byte) []byte {
+	header := make([]byte, headerLength)
+	binary.BigEndian.PutUint32(header[0:4], uint32(headerLength+len(body)))
+	header[4] = packetType
+	return append(header, body...)
+}
+
+func newConnect(version uint16, connectData string) []byte {
+	body := make([]byte, 20)
+	binary.BigEndian.PutUint16(body[0:2], version)
+	binary.BigEndian.PutUint16(body[16:18], uint16(len(connectData)))
+	binary.BigEndian.PutUint16(body[18:20], minConnectLength)
+	return newPacket(packetConnect, append(body, connectData...))
+}
+
+func newCall(ttc ...byte) []byte {
+	return newLargePacket(packetData, append([]byte{0, 0}, ttc...))
+}
+
+func newResponse(data []byte) *protocol.PayloadMessage {
+	return protocol.NewResponseMessage(data, protocol.NewRequestMessage(nil).GetAttributes())
+}
+
+func TestParseOracleRequest(t *testing.T) {
+	sql := "UPDATE employees SET salary = :1 WHERE employee_id = :2"
+	longSql := "SELECT " + strings.Repeat("first_name, ", 30) + "last_name FROM employees"
+	chunked := append([]byte{3, 94, 1, 0xfe, 200}, longSql[:200]...)
+	chunked = append(append(append(chunked, byte(len(longSql)-200)), longSql[200:]...), 0)
+	tests := []struct {
+		name     string
+		data     []byte
+		ok       bool
+		function string
+		sql      string
+		key      string
+		oneway   bool
+	}{
+		{name: "connect", data: newConnect(318, "(DESCRIPTION=(CONNECT_DATA=(SID=ORCL)))"), ok: true,
+			function: "connect", key: "connect ORCL"},
+		{name: "unknown version", data: newConnect(12, "(DESCRIPTION=(CONNECT_DATA=(SID=ORCL)))")},
+		{name: "execute", data: newCall(append([]byte{3, 94, 2, 0x80, byte(len(sql))}, sql...)...), ok: true,
+			function: "execute", sql: sql, key: "update employees *"},
+		{name: "chunked", data: newCall(chunked...), ok: true, function: "execute", sql: longSql, key: "select employees *"},
+		{name: "truncated", data: newCall(append([]byte{3, 94, 2, 0x80, 0x60}, "SELECT 1 FROM dual"...)...), ok: true,
+			function: "execute", sql: "SELECT 1 FROM dual", key: "select dual *"},
+		{name: "piggyback", data: newCall(17, 105, 1, 1, 3, 5, 3, 0), ok: true, function: "fetch", key: "fetch"},
+		{name: "commit", data: newCall(3, 14, 4), ok: true, function: "commit", key: "commit"},
+		{name: "end of session", data: newLargePacket(packetData, []byte{0, 0x40, 0}), ok: true, oneway: true},
+		{name: "not tns", data: []byte("GET / HTTP/1.1\r\nHost: localhost\r\n\r\n")},
+		{name: "nonzero header checksum", data: append([]byte{0, 12, 0, 0, 6, 0, 1, 1}, 0, 0, 3, 5)},
+	}
+	parser := NewOracleParser()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message := protocol.NewRequestMessage(tt.data)
+			if !assert.Equal(t, tt.ok, parser.ParseRequest(message)) || !tt.ok {
+				return
+			}
+			assert.Equal(t, tt.oneway, message.GetBoolAttribute(constlabels.Oneway))
+			assert.Equal(t, tt.function, message.GetStringAttribute(constlabels.OracleFunction))
+			assert.Equal(t, tt.sql, message.GetStringAttribute(constlabels.Sql))
+			assert.Equal(t, tt.key, message.GetStringAttribute(constlabels.ContentKey))
+		})
+	}
+}
+
+func TestParseOracleResponse(t *testing.T) {
+	marker := newPacket(packetMarker, []byte{1, 0, 1})
+	tests := []struct {
+		name    string
+		data    []byte
+		ok      bool
+		isError bool
+		code    int64
+		errMsg  string
+	}{
+		{name: "accept", data: newPacket(packetAccept, make([]byte, 24)), ok: true},
+		{name: "refuse", data: newPacket(packetRefuse, append([]byte{4, 4, 0, 50},
+			"(DESCRIPTION=(TMP=)(VSNNUM=0)(ERR=12514)(ERROR_STACK=(ERROR=(CODE=12514)(EMFI=4))))"...)),
+			ok: true, isError: true, code: 12514, errMsg: "TNS-12514"},
+		{name: "no data found", data: newCall(append([]byte{4, 1, 0}, "ORA-01403: no data found\n"...)...), ok: true},
+		{name: "error after markers", data: append(append(marker, marker...),
+			newCall(append([]byte{4, 1, 0}, "ORA-01017: invalid username/password; logon denied\n"...)...)...),
+			ok: true, isError: true, code: 1017, errMsg: "ORA-01017: invalid username/password; logon denied"},
+		{name: "rows", data: newCall(6, 1, 2, 3), ok: true},
+		{name: "not tns", data: []byte("HTTP/1.1 200 OK\r\n\r\n")},
+	}
+	parser := NewOracleParser()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message := newResponse(tt.data)
+			if !assert.Equal(t, tt.ok, parser.ParseResponse(message)) || !tt.ok {
+				return
+			}
+			assert.Equal(t, tt.isError, message.GetBoolAttribute(constlabels.IsError))
+			assert.Equal(t, tt.code, message.GetIntAttribute(constlabels.SqlErrCode))
+			assert.Equal(t, tt.errMsg, message.GetStringAttribute(constlabels.SqlErrMsg))
+		})
+	}
+}
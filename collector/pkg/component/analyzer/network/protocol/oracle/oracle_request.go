@@ -0,0 +1,241 @@
+package oracle
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strconv"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/mysql/tools"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+// minConnectLength is the length of the fixed fields of the connect packet, up to the offset of the connect data.
+const minConnectLength = headerLength + 20
+
+// maxServiceLength is the max length of the service name or the SID accepted.
+const maxServiceLength = 128
+
+func fastfailOracleRequest() protocol.FastFailFn {
+	return func(message *protocol.PayloadMessage) bool {
+		return len(message.Data) < headerLength+2 ||
+			(message.Data[4] != packetConnect && message.Data[4] != packetData)
+	}
+}
+
+// parseOracleRequest reads the connect packet or the TTC call sent by the client.
+func parseOracleRequest() protocol.ParsePkgFn {
+	return func(message *protocol.PayloadMessage) (bool, bool) {
+		p, ok := readPacket(message.Data, 0)
+		if !ok {
+			return false, true
+		}
+		if p.packetType == packetConnect {
+			return parseConnect(message, p)
+		}
+		return parseCall(message, p)
+	}
+}
+
+/*
+===== Connect =====
+2       version
+2       lowest compatible version
+2       service options
+2       SDU size
+2       TDU size
+2       protocol characteristics
+2       line turnaround
+2       value of 1 in hardware
+2       length of the connect data
+2       offset of the connect data from the start of the packet
+...
+string  the connect data, e.g. (DESCRIPTION=(CONNECT_DATA=(SERVICE_NAME=ORCL))(ADDRESS=...))
+*/
+func parseConnect(message *protocol.PayloadMessage, p *packet) (bool, bool) {
+	if len(p.body) < minConnectLength-headerLength {
+		return false, true
+	}
+	version := binary.BigEndian.Uint16(p.body[0:2])
+	if version < minVersion || version >= maxVersion {
+		return false, true
+	}
+	message.AddStringAttribute(constlabels.ProtocolVersion, strconv.Itoa(int(version)))
+	message.AddStringAttribute(constlabels.OracleFunction, "connect")
+	contentKey := "connect"
+	// The connect data longer than 230 bytes is sent in a following data packet, which is not read.
+	dataLength := int(binary.BigEndian.Uint16(p.body[16:18]))
+	dataOffset := int(binary.BigEndian.Uint16(p.body[18:20]))
+	if dataOffset >= minConnectLength && dataOffset < len(message.Data) {
+		connectData := message.Data[dataOffset:]
+		if len(connectData) > dataLength {
+			connectData = connectData[:dataLength]
+		}
+		if service := readService(connectData); service != "" {
+			message.AddUtf8StringAttribute(constlabels.OracleService, service)
+			contentKey += " " + service
+		}
+	}
+	message.AddUtf8StringAttribute(constlabels.ContentKey, contentKey)
+	return true, true
+}
+
+// readService reads the SERVICE_NAME, or the SID of the old clients, from the connect data.
+func readService(connectData []byte) string {
+	upper := bytes.ToUpper(connectData)
+	for _, key := range [][]byte{[]byte("(SERVICE_NAME="), []byte("(SID=")} {
+		start := bytes.Index(upper, key)
+		if start < 0 {
+			continue
+		}
+		value := connectData[start+len(key):]
+		if end := bytes.IndexByte(value, ')'); end >= 0 {
+			value = value[:end]
+		}
+		value = bytes.TrimSpace(value)
+		if len(value) > maxServiceLength {
+			value = value[:maxServiceLength]
+		}
+		return string(value)
+	}
+	return ""
+}
+
+/*
+===== Data =====
+2       data flags
+1       TTC message type
+...     the message, e.g. the function code and the sequence number followed by the arguments of a call
+*/
+func parseCall(message *protocol.PayloadMessage, p *packet) (bool, bool) {
+	if len(p.body) < 3 {
+		return false, true
+	}
+	flags := binary.BigEndian.Uint16(p.body[0:2])
+	if flags&dataFlagEndOfSession != 0 {
+		message.AddBoolAttribute(constlabels.Oneway, true)
+		return true, true
+	}
+	ttc := p.body[2:]
+	var function string
+	switch ttc[0] {
+	case ttcProtocol, ttcDataTypes:
+		function = "negotiate"
+	case ttcFunction, ttcPiggyback:
+		if len(ttc) < 2 {
+			return false, true
+		}
+		function = readFunction(ttc)
+	case ttcOnewayFunction:
+		message.AddBoolAttribute(constlabels.Oneway, true)
+		return true, true
+	default:
+		return false, true
+	}
+	message.AddStringAttribute(constlabels.OracleFunction, function)
+	contentKey := function
+	if sql := extractSql(ttc); sql != "" {
+		message.AddUtf8StringAttribute(constlabels.Sql, sql)
+		if key := tools.SQL_MERGER.ParseStatement(sql); key != "" {
+			contentKey = key
+		}
+	}
+	message.AddUtf8StringAttribute(constlabels.ContentKey, contentKey)
+	return true, true
+}
+
+// mainFunctions are the calls that the piggybacked ones, e.g. closing the cursors, may precede.
+var mainFunctions = map[byte]bool{4: true, 5: true, 14: true, 15: true, 78: true, 94: true}
+
+// readFunction names the call. The piggybacked calls precede the main call in the same packet, but their
+// lengths are not known without decoding the arguments, so the main call is searched for.
+func readFunction(ttc []byte) string {
+	code := ttc[1]
+	if ttc[0] == ttcPiggyback {
+		for i := 2; i+1 < len(ttc); i++ {
+			if ttc[i] == ttcFunction && mainFunctions[ttc[i+1]] {
+				code = ttc[i+1]
+				break
+			}
+		}
+	}
+	if function, ok := functions[code]; ok {
+		return function
+	}
+	return "unknown"
+}
+
+// sqlKeywords are the first keywords of the statements and the PL/SQL blocks searched for.
+var sqlKeywords = [][]byte{
+	[]byte("SELECT"), []byte("INSERT"), []byte("UPDATE"), []byte("DELETE"), []byte("MERGE"), []byte("WITH"),
+	[]byte("BEGIN"), []byte("DECLARE"), []byte("CALL"), []byte("CREATE"), []byte("ALTER"), []byte("DROP"),
+	[]byte("TRUNCATE"), []byte("GRANT"), []byte("REVOKE"), []byte("LOCK"), []byte("COMMIT"), []byte("ROLLBACK"),
+}
+
+// extractSql searches the arguments of the call for the statement, as decoding them depends on the versions
+// and the capabilities negotiated. The statement is a length-prefixed string, or chunks following 0xfe and
+// ending with an empty chunk if it is longer than 252 bytes.
+func extractSql(ttc []byte) string {
+	for i := 1; i < len(ttc); i++ {
+		if !isSqlStart(ttc[i:]) {
+			continue
+		}
+		if i >= 2 && ttc[i-2] == 0xfe {
+			return readChunks(ttc, i-1)
+		}
+		length := int(ttc[i-1])
+		if length > 0 && length <= 252 && i+length <= len(ttc) && isPrintable(ttc[i:i+length]) {
+			return string(ttc[i : i+length])
+		}
+		// The statement is truncated or the prefix is not known, so the printable bytes are taken.
+		end := i
+		for end < len(ttc) && isPrintableByte(ttc[end]) {
+			end++
+		}
+		return string(ttc[i:end])
+	}
+	return ""
+}
+
+func isSqlStart(data []byte) bool {
+	for _, keyword := range sqlKeywords {
+		if len(data) > len(keyword) && bytes.EqualFold(data[:len(keyword)], keyword) {
+			next := data[len(keyword)]
+			return next == ' ' || next == '\t' || next == '\n' || next == '\r' || next == '(' || next == ';'
+		}
+	}
+	return false
+}
+
+// readChunks reads the chunks of the statement at the offset, which may be truncated.
+func readChunks(data []byte, offset int) string {
+	var sql []byte
+	for offset < len(data) {
+		length := int(data[offset])
+		offset++
+		if length == 0 {
+			break
+		}
+		end := offset + length
+		if end > len(data) {
+			end = len(data)
+		}
+		sql = append(sql, data[offset:end]...)
+		offset = end
+	}
+	return string(sql)
+}
+
+func isPrintable(data []byte) bool {
+	for _, b := range data {
+		if !isPrintableByte(b) {
+			return false
+		}
+	}
+	return true
+}
+
+// isPrintableByte accepts the bytes of UTF-8 as well, which the statements may hold in the literals.
+func isPrintableByte(b byte) bool {
+	return (b >= 0x20 && b != 0x7f) || b == '\t' || b == '\n' || b == '\r'
+}
@@ -0,0 +1,113 @@
+package oracle
+
+import (
+	"bytes"
+	"strconv"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+// codeNoDataFound is ORA-01403 returned by the fetches reaching the end of the rows, which is not a failure.
+const codeNoDataFound = 1403
+
+var (
+	oraPrefix = []byte("ORA-")
+	errKey    = []byte("(ERR=")
+)
+
+func fastfailOracleResponse() protocol.FastFailFn {
+	return func(message *protocol.PayloadMessage) bool {
+		return len(message.Data) < headerLength
+	}
+}
+
+// parseOracleResponse reads the packets sent by the server. The markers of the breaks and the resets
+// precede the data packet holding the error of the call, so all the packets are read.
+func parseOracleResponse() protocol.ParsePkgFn {
+	return func(message *protocol.PayloadMessage) (bool, bool) {
+		p, ok := readPacket(message.Data, 0)
+		if !ok {
+			return false, true
+		}
+		switch p.packetType {
+		case packetAccept, packetRedirect, packetResend:
+			return true, true
+		case packetRefuse:
+			parseRefuse(message, p)
+			return true, true
+		case packetData, packetMarker:
+		default:
+			return false, true
+		}
+		for {
+			if p.packetType == packetData && parseError(message, p) {
+				return true, true
+			}
+			next, ok := readPacket(message.Data, p.end)
+			if !ok {
+				return true, true
+			}
+			p = next
+		}
+	}
+}
+
+/*
+===== Refuse =====
+1       reason of the user
+1       reason of the system
+2       length of the data
+string  the data, e.g. (DESCRIPTION=(TMP=)(VSNNUM=0)(ERR=12514)(ERROR_STACK=...))
+*/
+func parseRefuse(message *protocol.PayloadMessage, p *packet) {
+	message.AddBoolAttribute(constlabels.IsError, true)
+	message.AddIntAttribute(constlabels.ErrorType, int64(constlabels.ProtocolError))
+	start := bytes.Index(p.body, errKey)
+	if start < 0 {
+		return
+	}
+	if code, ok := readCode(p.body[start+len(errKey):]); ok {
+		message.AddIntAttribute(constlabels.SqlErrCode, code)
+		message.AddStringAttribute(constlabels.SqlErrMsg, "TNS-"+strconv.FormatInt(code, 10))
+	}
+}
+
+// parseError reads the error of the call, e.g. "ORA-00942: table or view does not exist", which ends the
+// response after the rows fetched. It returns false if the packet holds no error.
+func parseError(message *protocol.PayloadMessage, p *packet) bool {
+	start := bytes.LastIndex(p.body, oraPrefix)
+	if start < 0 {
+		return false
+	}
+	text := p.body[start:]
+	code, ok := readCode(text[len(oraPrefix):])
+	if !ok {
+		return false
+	}
+	if code == codeNoDataFound {
+		return true
+	}
+	end := 0
+	for end < len(text) && text[end] != '\n' && isPrintableByte(text[end]) {
+		end++
+	}
+	message.AddIntAttribute(constlabels.SqlErrCode, code)
+	message.AddUtf8StringAttribute(constlabels.SqlErrMsg, string(text[:end]))
+	message.AddBoolAttribute(constlabels.IsError, true)
+	message.AddIntAttribute(constlabels.ErrorType, int64(constlabels.ProtocolError))
+	return true
+}
+
+// readCode reads the code of 5 digits at most.
+func readCode(data []byte) (int64, bool) {
+	end := 0
+	for end < len(data) && end < 5 && data[end] >= '0' && data[end] <= '9' {
+		end++
+	}
+	if end == 0 {
+		return 0, false
+	}
+	code, err := strconv.ParseInt(string(data[:end]), 10, 64)
+	return code, err == nil
+}
@@ -0,0 +1,86 @@
+package protocol
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/tools"
+)
+
+// The encodings of the payload labels.
+const (
+	// PayloadEncodingText is the default one. The payloads of HTTP and Redis are the valid UTF-8 prefixes,
+	// and the bytes of the others out of the printable ASCII are replaced with '.'.
+	PayloadEncodingText = "text"
+	// PayloadEncodingHex encodes the payloads as lowercase hex strings.
+	PayloadEncodingHex = "hex"
+	// PayloadEncodingBase64 encodes the payloads with the standard base64 encoding.
+	PayloadEncodingBase64 = "base64"
+	// PayloadEncodingEscaped keeps the printable UTF-8 characters and escapes the others, e.g. \r\n and \x00.
+	PayloadEncodingEscaped = "escaped"
+)
+
+// PayloadFormat is how the payloads of a protocol are rendered as labels.
+type PayloadFormat struct {
+	Encoding string
+	// MaxLength is the maximum length of the labels after encoding. No limit if it is not positive.
+	MaxLength int
+}
+
+var payloadFormats = map[string]PayloadFormat{}
+
+// SetPayloadFormat sets the format of the payload labels of the protocol. The default text encoding
+// is used if the encoding is empty or unknown, in which case an error is returned.
+func SetPayloadFormat(protocol string, format PayloadFormat) error {
+	var err error
+	switch format.Encoding {
+	case PayloadEncodingText, PayloadEncodingHex, PayloadEncodingBase64, PayloadEncodingEscaped:
+	case "":
+		format.Encoding = PayloadEncodingText
+	default:
+		err = fmt.Errorf("unknown payload encoding of %s: %s", protocol, format.Encoding)
+		format.Encoding = PayloadEncodingText
+	}
+	payloadFormats[protocol] = format
+	return err
+}
+
+func getPayloadFormat(protocol string) PayloadFormat {
+	if format, ok := payloadFormats[protocol]; ok {
+		return format
+	}
+	return PayloadFormat{Encoding: PayloadEncodingText}
+}
+
+// encodePayload encodes the payload with the encoding other than the text.
+func encodePayload(data []byte, format PayloadFormat) string {
+	maxLength := format.MaxLength
+	switch format.Encoding {
+	case PayloadEncodingHex:
+		if maxLength > 0 && hex.EncodedLen(len(data)) > maxLength {
+			data = data[:maxLength/2]
+		}
+		return hex.EncodeToString(data)
+	case PayloadEncodingBase64:
+		// Every 3 bytes are encoded as 4 characters.
+		if maxLength > 0 && base64.StdEncoding.EncodedLen(len(data)) > maxLength {
+			data = data[:maxLength/4*3]
+		}
+		return base64.StdEncoding.EncodeToString(data)
+	default:
+		return tools.GetEscapedString(data, maxLength)
+	}
+}
+
+// truncateString returns the prefix of the string not longer than maxLength without splitting a character.
+func truncateString(s string, maxLength int) string {
+	if maxLength <= 0 || len(s) <= maxLength {
+		return s
+	}
+	for maxLength > 0 && !utf8.RuneStart(s[maxLength]) {
+		maxLength--
+	}
+	return s[:maxLength]
+}
@@ -0,0 +1,36 @@
+package protocol
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetPayloadString_Format(t *testing.T) {
+	defer func() {
+		payloadFormats = map[string]PayloadFormat{}
+	}()
+	data := []byte("+OK\r\n世界\x00")
+	tests := []struct {
+		name   string
+		format PayloadFormat
+		want   string
+	}{
+		{name: "text", format: PayloadFormat{}, want: "+OK\r\n世界\x00"},
+		{name: "text truncated", format: PayloadFormat{MaxLength: 7}, want: "+OK\r\n"},
+		{name: "hex", format: PayloadFormat{Encoding: PayloadEncodingHex}, want: "2b4f4b0d0ae4b896e7958c00"},
+		{name: "hex truncated", format: PayloadFormat{Encoding: PayloadEncodingHex, MaxLength: 7}, want: "2b4f4b"},
+		{name: "base64", format: PayloadFormat{Encoding: PayloadEncodingBase64}, want: "K09LDQrkuJbnlYwA"},
+		{name: "base64 truncated", format: PayloadFormat{Encoding: PayloadEncodingBase64, MaxLength: 10}, want: "K09LDQrk"},
+		{name: "escaped", format: PayloadFormat{Encoding: PayloadEncodingEscaped}, want: `+OK\r\n世界\x00`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.NoError(t, SetPayloadFormat(REDIS, tt.format))
+			assert.Equal(t, tt.want, GetPayloadString(data, REDIS))
+		})
+	}
+
+	assert.Error(t, SetPayloadFormat(REDIS, PayloadFormat{Encoding: "binary"}))
+	assert.Equal(t, "+OK\r\n世界\x00", GetPayloadString(data, REDIS))
+}
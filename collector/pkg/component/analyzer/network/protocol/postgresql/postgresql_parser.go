@@ -0,0 +1,102 @@
+package postgresql
+
+import (
+	"encoding/binary"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+)
+
+const (
+	// startupHeaderLength is the length and the code of the startup messages, which have no type.
+	startupHeaderLength = 8
+	// messageHeaderLength is the type and the length of the other messages.
+	messageHeaderLength = 5
+	protocolVersion3    = 196608
+	sslRequestCode      = 80877103
+	cancelRequestCode   = 80877102
+	gssEncRequestCode   = 80877104
+	// maxMessageLength bounds the length of a message, 1GB as the server does.
+	maxMessageLength = 1 << 30
+	// maxStartupLength is the max length of the startup message accepted by the server.
+	maxStartupLength = 10000
+)
+
+// frontendTypes are the types of the messages sent by the clients.
+var frontendTypes = map[byte]string{
+	'Q': "query",
+	'P': "parse",
+	'B': "bind",
+	'E': "execute",
+	'D': "describe",
+	'C': "close",
+	'S': "sync",
+	'H': "flush",
+	'F': "function_call",
+	'p': "auth",
+	'd': "copy_data",
+	'c': "copy_done",
+	'f': "copy_fail",
+	'X': "terminate",
+}
+
+// backendTypes are the types of the messages sent by the servers.
+var backendTypes = map[byte]bool{
+	'R': true, 'S': true, 'K': true, 'Z': true, 'T': true, 'D': true, 'C': true, 'E': true, 'N': true,
+	'1': true, '2': true, '3': true, 'n': true, 's': true, 'I': true, 'G': true, 'H': true, 'W': true,
+	'd': true, 'c': true, 't': true, 'A': true, 'V': true, 'v': true,
+}
+
+type pgMessage struct {
+	messageType byte
+	// body is the content after the header, which may be truncated.
+	body []byte
+	// end is the offset after the message, which may exceed the data if it is truncated.
+	end int
+}
+
+// readMessage reads the message at the offset, whose type must be one of the types given.
+func readMessage(data []byte, offset int, types func(byte) bool) (*pgMessage, bool) {
+	if offset+messageHeaderLength > len(data) || !types(data[offset]) {
+		return nil, false
+	}
+	length := int(binary.BigEndian.Uint32(data[offset+1 : offset+messageHeaderLength]))
+	if length < 4 || length > maxMessageLength {
+		return nil, false
+	}
+	end := offset + 1 + length
+	body := data[offset+messageHeaderLength:]
+	if len(body) > length-4 {
+		body = body[:length-4]
+	}
+	return &pgMessage{messageType: data[offset], body: body, end: end}, true
+}
+
+func isFrontendType(messageType byte) bool {
+	_, ok := frontendTypes[messageType]
+	return ok
+}
+
+func isBackendType(messageType byte) bool {
+	return backendTypes[messageType]
+}
+
+// readString reads the null-terminated string at the start of the data, and returns the string and the
+// data after it. The string is returned as is if it is truncated.
+func readString(data []byte) (string, []byte) {
+	for i, b := range data {
+		if b == 0 {
+			return string(data[:i]), data[i+1:]
+		}
+	}
+	return string(data), nil
+}
+
+// NewPostgresqlParser creates the parser of the PostgreSQL frontend/backend protocol version 3. The queries
+// of a connection are synchronous, so the responses are paired with the requests in order. The COPY
+// statements are followed by the streams of the rows, which are marked by the direction so they are
+// reported as the bulk transfers instead of the slow queries.
+func NewPostgresqlParser() *protocol.ProtocolParser {
+	requestParser := protocol.CreatePkgParser(fastfailPostgresqlRequest(), parsePostgresqlRequest())
+	responseParser := protocol.CreatePkgParser(fastfailPostgresqlResponse(), parsePostgresqlResponse())
+	return protocol.NewProtocolParser(protocol.POSTGRESQL, requestParser, responseParser, nil)
+}
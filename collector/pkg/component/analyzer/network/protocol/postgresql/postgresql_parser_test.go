@@ -0,0 +1,137 @@
+package postgresql
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constvalues"
+)
+
+func newMessage(messageType byte, body string) []byte {
+	data := make([]byte, messageHeaderLength)
+	data[0] = messageType
+	binary.BigEndian.PutUint32(data[1:], uint32(4+len(body)))
+	return append(data, body...)
+}
+
+func newStartup(code uint32, parameters string) []byte {
+	data := make([]byte, startupHeaderLength)
+	binary.BigEndian.PutUint32(data[0:4], uint32(startupHeaderLength+len(parameters)))
+	binary.BigEndian.PutUint32(data[4:8], code)
+	return append(data, parameters...)
+}
+
+func concat(messages ...[]byte) []byte {
+	var data []byte
+	for _, m := range messages {
+		data = append(data, m...)
+	}
+	return data
+}
+
+func newResponse(data []byte) *protocol.PayloadMessage {
+	return protocol.NewResponseMessage(data, protocol.NewRequestMessage(nil).GetAttributes())
+}
+
+func TestParsePostgresqlRequest(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      []byte
+		ok        bool
+		sql       string
+		key       string
+		database  string
+		direction string
+		oneway    bool
+	}{
+		{name: "startup", data: newStartup(protocolVersion3, "user\x00app\x00database\x00orders\x00\x00"), ok: true,
+			key: "startup orders", database: "orders"},
+		{name: "startup without database", data: newStartup(protocolVersion3, "user\x00app\x00\x00"), ok: true,
+			key: "startup app", database: "app"},
+		{name: "ssl request", data: newStartup(sslRequestCode, ""), ok: true, key: "ssl_request"},
+		{name: "cancel", data: newStartup(cancelRequestCode, "\x00\x00\x00\x01\x00\x00\x00\x02"), ok: true,
+			key: "cancel", oneway: true},
+		{name: "protocol 2.0", data: newStartup(131072, "user\x00app\x00\x00")},
+		{name: "query", data: newMessage('Q', "SELECT * FROM employees WHERE id = 1\x00"), ok: true,
+			sql: "SELECT * FROM employees WHERE id = 1", key: "select employees *"},
+		{name: "copy from stdin", data: newMessage('Q', "COPY employees(id, name) FROM STDIN\x00"), ok: true,
+			sql: "COPY employees(id, name) FROM STDIN", key: "copy employees"},
+		{name: "copy query", data: newMessage('Q', "COPY (SELECT * FROM employees) TO STDOUT\x00"), ok: true,
+			sql: "COPY (SELECT * FROM employees) TO STDOUT", key: "copy *"},
+		{name: "extended query", data: concat(newMessage('P', "\x00UPDATE employees SET salary = $1\x00\x00\x00"),
+			newMessage('B', "\x00\x00\x00\x00\x00\x00\x00\x00"), newMessage('E', "\x00\x00\x00\x00\x00"),
+			newMessage('S', "")), ok: true, sql: "UPDATE employees SET salary = $1", key: "update employees *"},
+		{name: "prepared", data: concat(newMessage('B', "\x00s1\x00\x00\x00\x00\x00\x00\x00"),
+			newMessage('E', "\x00\x00\x00\x00\x00"), newMessage('S', "")), ok: true, key: "execute"},
+		{name: "copy data", data: concat(newMessage('d', "1\tAlice\n"), newMessage('d', "2\tBob\n")), ok: true,
+			key: "copy", direction: constvalues.BulkTransferIn},
+		{name: "copy data truncated", data: newMessage('d', "1\tAlice\n2\tBob\n")[:12], ok: true,
+			key: "copy", direction: constvalues.BulkTransferIn},
+		{name: "terminate", data: newMessage('X', ""), ok: true, key: "terminate", oneway: true},
+		{name: "invalid next message", data: concat(newMessage('Q', "SELECT 1\x00"), []byte("GET / HTTP/1.1\r\n"))},
+		{name: "not postgresql", data: []byte("GET / HTTP/1.1\r\nHost: localhost\r\n\r\n")},
+	}
+	parser := NewPostgresqlParser()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message := protocol.NewRequestMessage(tt.data)
+			if !assert.Equal(t, tt.ok, parser.ParseRequest(message)) || !tt.ok {
+				return
+			}
+			assert.Equal(t, tt.sql, message.GetStringAttribute(constlabels.Sql))
+			assert.Equal(t, tt.key, message.GetStringAttribute(constlabels.ContentKey))
+			assert.Equal(t, tt.database, message.GetStringAttribute(constlabels.PostgresqlDatabase))
+			assert.Equal(t, tt.direction, message.GetStringAttribute(constlabels.BulkTransferDirection))
+			assert.Equal(t, tt.oneway, message.GetBoolAttribute(constlabels.Oneway))
+		})
+	}
+}
+
+func TestParsePostgresqlResponse(t *testing.T) {
+	ready := newMessage('Z', "I")
+	tests := []struct {
+		name         string
+		data         []byte
+		ok           bool
+		isError      bool
+		sqlState     string
+		errMsg       string
+		affectedRows int64
+		direction    string
+		pending      bool
+		copyRows     int64
+	}{
+		{name: "ssl accepted", data: []byte("S"), ok: true},
+		{name: "rows", data: concat(newMessage('T', "\x00\x01id\x00"), newMessage('D', "\x00\x01\x00\x00\x00\x011"),
+			newMessage('C', "SELECT 1\x00"), ready), ok: true},
+		{name: "insert", data: concat(newMessage('C', "INSERT 0 5\x00"), ready), ok: true, affectedRows: 5},
+		{name: "error", data: concat(newMessage('E', "SERROR\x00VERROR\x00C42P01\x00Mrelation \"employee\" does not exist\x00\x00"),
+			ready), ok: true, isError: true, sqlState: "42P01", errMsg: "relation \"employee\" does not exist"},
+		{name: "copy in", data: newMessage('G', "\x00\x00\x02\x00\x00\x00\x00"), ok: true, direction: constvalues.BulkTransferIn, pending: true},
+		{name: "copy in done", data: concat(newMessage('C', "COPY 1000\x00"), ready), ok: true, copyRows: 1000},
+		{name: "copy out", data: concat(newMessage('H', "\x00\x00\x02\x00\x00\x00\x00"), newMessage('d', "1\tAlice\n"),
+			newMessage('d', "2\tBob\n"), newMessage('c', ""), newMessage('C', "COPY 2\x00"), ready), ok: true,
+			direction: constvalues.BulkTransferOut, copyRows: 2},
+		{name: "not postgresql", data: []byte("HTTP/1.1 200 OK\r\n\r\n")},
+	}
+	parser := NewPostgresqlParser()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message := newResponse(tt.data)
+			if !assert.Equal(t, tt.ok, parser.ParseResponse(message)) || !tt.ok {
+				return
+			}
+			assert.Equal(t, tt.isError, message.GetBoolAttribute(constlabels.IsError))
+			assert.Equal(t, tt.sqlState, message.GetStringAttribute(constlabels.PostgresqlSqlState))
+			assert.Equal(t, tt.errMsg, message.GetStringAttribute(constlabels.SqlErrMsg))
+			assert.Equal(t, tt.affectedRows, message.GetIntAttribute(constlabels.SqlAffectedRows))
+			assert.Equal(t, tt.direction, message.GetStringAttribute(constlabels.BulkTransferDirection))
+			assert.Equal(t, tt.pending, message.GetBoolAttribute(constlabels.BulkTransferPending))
+			assert.Equal(t, tt.copyRows, message.GetIntAttribute(constlabels.BulkTransferRows))
+		})
+	}
+}
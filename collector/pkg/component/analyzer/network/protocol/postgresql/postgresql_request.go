@@ -0,0 +1,169 @@
+package postgresql
+
+import (
+	"encoding/binary"
+	"strings"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/mysql/tools"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constvalues"
+)
+
+const (
+	cancelRequestLength = 16
+	// maxDatabaseLength is the max length of the database name, NAMEDATALEN-1 of the server.
+	maxDatabaseLength = 63
+)
+
+func fastfailPostgresqlRequest() protocol.FastFailFn {
+	return func(message *protocol.PayloadMessage) bool {
+		return len(message.Data) < messageHeaderLength
+	}
+}
+
+// parsePostgresqlRequest reads the startup message or the messages sent by the client. The startup
+// messages have no type, and their lengths always start with 0.
+func parsePostgresqlRequest() protocol.ParsePkgFn {
+	return func(message *protocol.PayloadMessage) (bool, bool) {
+		if message.Data[0] == 0 {
+			return parseStartup(message)
+		}
+		m, ok := readMessage(message.Data, 0, isFrontendType)
+		if !ok || !followedByMessage(message.Data, m.end, isFrontendType) {
+			return false, true
+		}
+		contentKey := frontendTypes[m.messageType]
+		switch m.messageType {
+		case 'Q':
+			sql, _ := readString(m.body)
+			addSql(message, sql)
+			return true, true
+		case 'P':
+			_, rest := readString(m.body)
+			sql, _ := readString(rest)
+			addSql(message, sql)
+			return true, true
+		case 'B':
+			// The prepared statement is executed again without being parsed.
+			contentKey = "execute"
+		case 'd', 'c', 'f':
+			// The rows of COPY FROM STDIN, whose statement is known from the previous request.
+			message.AddStringAttribute(constlabels.BulkTransferDirection, constvalues.BulkTransferIn)
+			contentKey = "copy"
+		case 'X':
+			message.AddBoolAttribute(constlabels.Oneway, true)
+		}
+		message.AddUtf8StringAttribute(constlabels.ContentKey, contentKey)
+		return true, true
+	}
+}
+
+// followedByMessage checks the message after the first one in the data, if any, to reject the payloads of
+// the other protocols starting with a valid type.
+func followedByMessage(data []byte, offset int, types func(byte) bool) bool {
+	if offset+messageHeaderLength > len(data) {
+		return true
+	}
+	_, ok := readMessage(data, offset, types)
+	return ok
+}
+
+func addSql(message *protocol.PayloadMessage, sql string) {
+	message.AddUtf8StringAttribute(constlabels.Sql, sql)
+	message.AddUtf8StringAttribute(constlabels.ContentKey, contentKey(sql))
+}
+
+// contentKey merges the statement like MySQL. The COPY statements are merged by their tables, e.g.
+// "copy employees", and "copy *" for the results of the queries.
+func contentKey(sql string) string {
+	fields := strings.Fields(sql)
+	if len(fields) >= 2 && strings.EqualFold(fields[0], "copy") {
+		table := fields[1]
+		if strings.HasPrefix(table, "(") {
+			return "copy *"
+		}
+		// The columns may follow the table without a space, e.g. "employees(id, name)".
+		if end := strings.IndexByte(table, '('); end > 0 {
+			table = table[:end]
+		}
+		return "copy " + table
+	}
+	if key := tools.SQL_MERGER.ParseStatement(sql); key != "" {
+		return key
+	}
+	return "query"
+}
+
+/*
+===== Startup =====
+4       length
+4       code, the protocol version 3.0 or the special codes of the requests
+...     the pairs of the null-terminated names and values of the parameters, ending with an empty name
+*/
+func parseStartup(message *protocol.PayloadMessage) (bool, bool) {
+	data := message.Data
+	if len(data) < startupHeaderLength {
+		return false, true
+	}
+	length := int(binary.BigEndian.Uint32(data[0:4]))
+	switch binary.BigEndian.Uint32(data[4:8]) {
+	case sslRequestCode:
+		if length != startupHeaderLength {
+			return false, true
+		}
+		message.AddUtf8StringAttribute(constlabels.ContentKey, "ssl_request")
+	case gssEncRequestCode:
+		if length != startupHeaderLength {
+			return false, true
+		}
+		message.AddUtf8StringAttribute(constlabels.ContentKey, "gssenc_request")
+	case cancelRequestCode:
+		// The server closes the connection without replying.
+		if length != cancelRequestLength {
+			return false, true
+		}
+		message.AddBoolAttribute(constlabels.Oneway, true)
+		message.AddUtf8StringAttribute(constlabels.ContentKey, "cancel")
+	case protocolVersion3:
+		if length <= startupHeaderLength || length > maxStartupLength {
+			return false, true
+		}
+		message.AddStringAttribute(constlabels.ProtocolVersion, "3.0")
+		contentKey := "startup"
+		if database := readDatabase(data[startupHeaderLength:]); database != "" {
+			message.AddUtf8StringAttribute(constlabels.PostgresqlDatabase, database)
+			contentKey += " " + database
+		}
+		message.AddUtf8StringAttribute(constlabels.ContentKey, contentKey)
+	default:
+		return false, true
+	}
+	return true, true
+}
+
+// readDatabase reads the database from the parameters, which is the name of the user if it is not given.
+func readDatabase(parameters []byte) string {
+	var user, database string
+	for len(parameters) > 0 {
+		var name, value string
+		name, parameters = readString(parameters)
+		if name == "" {
+			break
+		}
+		value, parameters = readString(parameters)
+		switch name {
+		case "user":
+			user = value
+		case "database":
+			database = value
+		}
+	}
+	if database == "" {
+		database = user
+	}
+	if len(database) > maxDatabaseLength {
+		database = database[:maxDatabaseLength]
+	}
+	return database
+}
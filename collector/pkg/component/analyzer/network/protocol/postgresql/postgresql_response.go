@@ -0,0 +1,95 @@
+package postgresql
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constvalues"
+)
+
+func fastfailPostgresqlResponse() protocol.FastFailFn {
+	return func(message *protocol.PayloadMessage) bool {
+		return len(message.Data) < messageHeaderLength && !isSslResponse(message.Data)
+	}
+}
+
+// isSslResponse checks the single byte replying SSLRequest or GSSENCRequest, 'S' if the encryption is
+// accepted, 'S' for SSL and 'G' for GSSAPI, and 'N' otherwise.
+func isSslResponse(data []byte) bool {
+	return len(data) == 1 && (data[0] == 'S' || data[0] == 'N' || data[0] == 'G')
+}
+
+// parsePostgresqlResponse reads the messages sent by the server until the data is exhausted, as the errors
+// and the tags of the commands follow the rows.
+func parsePostgresqlResponse() protocol.ParsePkgFn {
+	return func(message *protocol.PayloadMessage) (bool, bool) {
+		if isSslResponse(message.Data) {
+			return true, true
+		}
+		m, ok := readMessage(message.Data, 0, isBackendType)
+		if !ok || !followedByMessage(message.Data, m.end, isBackendType) {
+			return false, true
+		}
+		for {
+			switch m.messageType {
+			case 'E':
+				parseError(message, m)
+			case 'C':
+				parseCommandComplete(message, m)
+			case 'G':
+				// The rows are sent in the next request, so the statement is only the start of the transfer.
+				message.AddStringAttribute(constlabels.BulkTransferDirection, constvalues.BulkTransferIn)
+				message.AddBoolAttribute(constlabels.BulkTransferPending, true)
+			case 'H':
+				message.AddStringAttribute(constlabels.BulkTransferDirection, constvalues.BulkTransferOut)
+			}
+			next, ok := readMessage(message.Data, m.end, isBackendType)
+			if !ok {
+				return true, true
+			}
+			m = next
+		}
+	}
+}
+
+/*
+===== ErrorResponse =====
+...     the fields, each of which is a byte of the type followed by a null-terminated string, ending with 0
+*/
+func parseError(message *protocol.PayloadMessage, m *pgMessage) {
+	message.AddBoolAttribute(constlabels.IsError, true)
+	message.AddIntAttribute(constlabels.ErrorType, int64(constlabels.ProtocolError))
+	fields := m.body
+	for len(fields) > 0 && fields[0] != 0 {
+		var value string
+		fieldType := fields[0]
+		value, fields = readString(fields[1:])
+		switch fieldType {
+		case 'C':
+			message.AddStringAttribute(constlabels.PostgresqlSqlState, value)
+		case 'M':
+			message.AddUtf8StringAttribute(constlabels.SqlErrMsg, value)
+		}
+	}
+}
+
+// parseCommandComplete reads the number of the rows from the tag, e.g. "INSERT 0 5" or "COPY 1000".
+func parseCommandComplete(message *protocol.PayloadMessage, m *pgMessage) {
+	tag, _ := readString(m.body)
+	fields := strings.Fields(tag)
+	if len(fields) < 2 {
+		return
+	}
+	rows, err := strconv.ParseInt(fields[len(fields)-1], 10, 64)
+	if err != nil {
+		return
+	}
+	switch fields[0] {
+	case "COPY":
+		message.AddIntAttribute(constlabels.BulkTransferRows, rows)
+	case "INSERT", "UPDATE", "DELETE", "MERGE":
+		message.AddIntAttribute(constlabels.SqlAffectedRows, rows)
+	}
+}
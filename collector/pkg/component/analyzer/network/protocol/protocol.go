@@ -1,14 +1,35 @@
 package protocol
 
 const (
-	HTTP      = "http"
-	DNS       = "dns"
-	KAFKA     = "kafka"
-	MYSQL     = "mysql"
-	REDIS     = "redis"
-	DUBBO     = "dubbo"
-	ROCKETMQ  = "rocketmq"
-	NOSUPPORT = "NOSUPPORT"
+	HTTP       = "http"
+	DNS        = "dns"
+	MDNS       = "mdns"
+	LLMNR      = "llmnr"
+	KAFKA      = "kafka"
+	MYSQL      = "mysql"
+	REDIS      = "redis"
+	DUBBO      = "dubbo"
+	ROCKETMQ   = "rocketmq"
+	MONGODB    = "mongodb"
+	TARS       = "tars"
+	GRPC       = "grpc"
+	HTTP2      = "http2"
+	BRPC       = "brpc"
+	BOLT       = "bolt"
+	CASSANDRA  = "cassandra"
+	FTP        = "ftp"
+	SSH        = "ssh"
+	MQTT       = "mqtt"
+	LDAP       = "ldap"
+	ORACLE     = "oracle"
+	POSTGRESQL = "postgresql"
+	WEBSOCKET  = "websocket"
+	QUIC       = "quic"
+	NTP        = "ntp"
+	SIP        = "sip"
+	TLS        = "tls"
+	TRIPLE     = "triple"
+	NOSUPPORT  = "NOSUPPORT"
 )
 
 var payloadLength map[string]int = map[string]int{}
@@ -374,13 +374,20 @@ type FastFailFn func(message *PayloadMessage) bool
 type ParsePkgFn func(message *PayloadMessage) (success bool, complete bool)
 type PairMatch func(requests []*PayloadMessage, response *PayloadMessage) int
 
+// SplitFn splits the payload of one syscall into the messages it carries.
+type SplitFn func(data []byte) [][]byte
+
 type ProtocolParser struct {
 	protocol       string
 	multiFrames    bool
 	requestParser  PkgParser
 	responseParser PkgParser
 	pairMatch      PairMatch
-	portCounter    cmap.ConcurrentMap
+	// splitter is nil if each payload carries one message.
+	splitter    SplitFn
+	portCounter cmap.ConcurrentMap
+	// minCaptureLength is the number of the leading bytes of the payloads the parser needs.
+	minCaptureLength int
 }
 
 func NewProtocolParser(protocol string, requestParser PkgParser, responseParser PkgParser, pairMatch PairMatch) *ProtocolParser {
@@ -397,6 +404,32 @@ func (parser *ProtocolParser) EnableMultiFrame() {
 	parser.multiFrames = true
 }
 
+// SetSplitter declares that one payload may carry multiple messages, e.g. the pipelined DNS queries over TCP.
+// It only applies to the parsers pairing the responses by PairMatch.
+func (parser *ProtocolParser) SetSplitter(splitter SplitFn) {
+	parser.splitter = splitter
+}
+
+// Split returns the messages carried by the payload.
+func (parser *ProtocolParser) Split(data []byte) [][]byte {
+	if parser.splitter == nil {
+		return [][]byte{data}
+	}
+	return parser.splitter(data)
+}
+
+// SetMinCaptureLength declares that the parser only needs the leading bytes of the payloads, e.g. the
+// headers of HTTP, so the payloads could be truncated to the length without failing the parsing.
+func (parser *ProtocolParser) SetMinCaptureLength(length int) {
+	parser.minCaptureLength = length
+}
+
+// GetMinCaptureLength returns the length declared by SetMinCaptureLength, or 0 if the parser may need
+// the whole payloads.
+func (parser *ProtocolParser) GetMinCaptureLength() int {
+	return parser.minCaptureLength
+}
+
 func (parser *ProtocolParser) GetProtocol() string {
 	return parser.protocol
 }
@@ -498,6 +531,14 @@ func (parser *ProtocolParser) ResetPort(port uint32) {
 }
 
 func GetPayloadString(data []byte, protocolName string) string {
+	format := getPayloadFormat(protocolName)
+	if format.Encoding != PayloadEncodingText {
+		return encodePayload(getSubstrBytes(data, protocolName, 0), format)
+	}
+	return truncateString(getTextPayloadString(data, protocolName), format.MaxLength)
+}
+
+func getTextPayloadString(data []byte, protocolName string) string {
 	switch protocolName {
 	case HTTP, REDIS:
 		return tools.FormatByteArrayToUtf8(getSubstrBytes(data, protocolName, 0))
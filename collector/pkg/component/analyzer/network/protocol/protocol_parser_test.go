@@ -38,6 +38,37 @@ func TestReadBytes(t *testing.T) {
 	}
 }
 
+func TestReadUInt16(t *testing.T) {
+	// ff 0 4 t e s t
+	data := []byte{0xff, 0x00, 0x04, 0x74, 0x65, 0x73, 0x74}
+	message := NewRequestMessage(data)
+
+	// The values are always read in network byte order regardless of the host architecture.
+	tests := []struct {
+		name   string
+		offset int
+		expect uint16
+		err    error
+	}{
+		{"Invalid Index", -1, 0, ErrArgumentInvalid},
+		{"Large Integer", 0, 65280, nil},
+		{"Positive Integer", 1, 4, nil},
+		{"Network Byte Order", 2, 1140, nil},
+		{"Overflow Index", 6, 0, ErrMessageShort},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			realValue, err := message.ReadUInt16(test.offset)
+			if err != nil {
+				assert.Equal(t, test.err, err)
+				return
+			}
+			assert.Equal(t, test.expect, realValue)
+		})
+	}
+}
+
 func TestReadInt16(t *testing.T) {
 	// ff 0 4 t e s t
 	data := []byte{0xff, 0x00, 0x04, 0x74, 0x65, 0x73, 0x74}
@@ -0,0 +1,43 @@
+package quic
+
+import (
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/tls"
+)
+
+// maxClientHelloLength limits the data buffered for the ClientHello split across the Initial packets.
+const maxClientHelloLength = 16 * 1024
+
+// Handshake reassembles the ClientHello, which is split across multiple Initial packets when it carries
+// the large key shares, and whose CRYPTO frames may be reordered.
+type Handshake struct {
+	data []byte
+	// frames are the data received beyond the contiguous bytes.
+	frames []CryptoFrame
+}
+
+// Add appends the CRYPTO frames of the Initial packet.
+func (h *Handshake) Add(packet *InitialPacket) {
+	h.frames = append(h.frames, packet.Crypto...)
+	for progress := true; progress; {
+		progress = false
+		remaining := h.frames[:0]
+		for _, frame := range h.frames {
+			end := frame.Offset + uint64(len(frame.Data))
+			switch {
+			case end <= uint64(len(h.data)):
+			case frame.Offset <= uint64(len(h.data)) && end <= maxClientHelloLength:
+				h.data = append(h.data, frame.Data[uint64(len(h.data))-frame.Offset:]...)
+				progress = true
+			case frame.Offset < maxClientHelloLength:
+				remaining = append(remaining, frame)
+			}
+		}
+		h.frames = remaining
+	}
+}
+
+// ClientHello parses the contiguous data received, which returns false if it is not a ClientHello. The
+// extensions beyond the data are missing in the result.
+func (h *Handshake) ClientHello() (*tls.ClientHello, bool) {
+	return tls.ParseClientHello(h.data)
+}
@@ -0,0 +1,274 @@
+package quic
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"strconv"
+)
+
+const (
+	Version1 = 0x00000001
+	// Version2 is the version of QUIC v2, see RFC 9369.
+	Version2 = 0x6b3343cf
+
+	// maxConnectionIdLength is the max length of the connection ids of QUIC v1 and v2.
+	maxConnectionIdLength = 20
+	// sampleLength is the length of the ciphertext sampled for the header protection.
+	sampleLength = 16
+)
+
+// The salts of the initial secrets, see RFC 9001 section 5.2 and RFC 9369 section 3.3.1.
+var (
+	initialSaltV1 = []byte{0x38, 0x76, 0x2c, 0xf7, 0xf5, 0x59, 0x34, 0xb3, 0x4d, 0x17,
+		0x9a, 0xe6, 0xa4, 0xc8, 0x0c, 0xad, 0xcc, 0xbb, 0x7f, 0x0a}
+	initialSaltV2 = []byte{0x0d, 0xed, 0xe3, 0xde, 0xf7, 0x00, 0xa6, 0xdb, 0x81, 0x93,
+		0x81, 0xbe, 0x6e, 0x26, 0x9d, 0xcb, 0xf9, 0xbd, 0x2e, 0xd9}
+)
+
+// The frames which may be sent in the Initial packets, see RFC 9000 section 12.4.
+const (
+	framePadding         = 0x00
+	framePing            = 0x01
+	frameAck             = 0x02
+	frameAckEcn          = 0x03
+	frameCrypto          = 0x06
+	frameConnectionClose = 0x1c
+)
+
+// CryptoFrame is the data of the TLS handshake carried by the CRYPTO frame.
+type CryptoFrame struct {
+	Offset uint64
+	Data   []byte
+}
+
+// InitialPacket is the Initial packet sent by the client to open the connection.
+type InitialPacket struct {
+	Version uint32
+	// Dcid is the destination connection id chosen by the client, which the keys are derived from.
+	Dcid   []byte
+	Crypto []CryptoFrame
+}
+
+// VersionString returns the name of the version, e.g. "v1".
+func VersionString(version uint32) string {
+	switch version {
+	case Version1:
+		return "v1"
+	case Version2:
+		return "v2"
+	}
+	return "0x" + strconv.FormatUint(uint64(version), 16)
+}
+
+// IsLongHeader returns true if the datagram starts with a packet with the long header, which is sent
+// before the 1-RTT keys are available.
+func IsLongHeader(data []byte) bool {
+	return len(data) >= 7 && data[0]&0xc0 == 0xc0
+}
+
+// ParseInitialPacket decrypts the Initial packet at the start of the datagram sent by the client. The
+// keys of the Initial packets are derived from the destination connection id, so they are known to the
+// observers. The payload is decrypted without the authentication as the datagram may be truncated, so
+// only the frames in the captured bytes are returned.
+func ParseInitialPacket(data []byte) (*InitialPacket, bool) {
+	if !IsLongHeader(data) {
+		return nil, false
+	}
+	version := binary.BigEndian.Uint32(data[1:5])
+	packetType := (data[0] >> 4) & 0x03
+	// The Initial packets are of the type 0 in v1, but 1 in v2.
+	if (version == Version1 && packetType != 0) || (version == Version2 && packetType != 1) ||
+		(version != Version1 && version != Version2) {
+		return nil, false
+	}
+	offset := 5
+	dcidLength := int(data[offset])
+	offset++
+	if dcidLength > maxConnectionIdLength || offset+dcidLength >= len(data) {
+		return nil, false
+	}
+	dcid := data[offset : offset+dcidLength]
+	offset += dcidLength
+	scidLength := int(data[offset])
+	offset++
+	if scidLength > maxConnectionIdLength || offset+scidLength > len(data) {
+		return nil, false
+	}
+	offset += scidLength
+	tokenLength, ok := readVarint(data, &offset)
+	if !ok || tokenLength > uint64(len(data)-offset) {
+		return nil, false
+	}
+	offset += int(tokenLength)
+	length, ok := readVarint(data, &offset)
+	if !ok {
+		return nil, false
+	}
+	pnOffset := offset
+	if pnOffset+4+sampleLength > len(data) {
+		return nil, false
+	}
+
+	keys := newInitialKeys(version, dcid)
+	// Remove the header protection, see RFC 9001 section 5.4.
+	mask := make([]byte, aes.BlockSize)
+	keys.hp.Encrypt(mask, data[pnOffset+4:pnOffset+4+sampleLength])
+	firstByte := data[0] ^ (mask[0] & 0x0f)
+	pnLength := int(firstByte&0x03) + 1
+	if uint64(pnLength) > length {
+		return nil, false
+	}
+	var packetNumber uint64
+	for i := 0; i < pnLength; i++ {
+		packetNumber = packetNumber<<8 | uint64(data[pnOffset+i]^mask[1+i])
+	}
+
+	// The payload is encrypted with AES-GCM, whose ciphertext is the keystream of AES-CTR starting at
+	// the counter 2 XORed with the plaintext.
+	payloadOffset := pnOffset + pnLength
+	payloadEnd := len(data)
+	if end := pnOffset + int(length) - aes.BlockSize; end < payloadEnd {
+		payloadEnd = end
+	}
+	if payloadEnd <= payloadOffset {
+		return nil, false
+	}
+	counter := make([]byte, aes.BlockSize)
+	copy(counter, keys.iv)
+	for i := 0; i < 8; i++ {
+		counter[len(keys.iv)-1-i] ^= byte(packetNumber >> (8 * i))
+	}
+	counter[aes.BlockSize-1] = 2
+	payload := make([]byte, payloadEnd-payloadOffset)
+	cipher.NewCTR(keys.key, counter).XORKeyStream(payload, data[payloadOffset:payloadEnd])
+
+	frames, ok := readCryptoFrames(payload)
+	if !ok {
+		return nil, false
+	}
+	return &InitialPacket{
+		Version: version,
+		Dcid:    append([]byte(nil), dcid...),
+		Crypto:  frames,
+	}, true
+}
+
+// readCryptoFrames returns the CRYPTO frames of the payload. The frames after the truncated one are
+// dropped, while the other frames not allowed in the Initial packets mean the payload is not decrypted.
+func readCryptoFrames(payload []byte) ([]CryptoFrame, bool) {
+	frames := make([]CryptoFrame, 0)
+	offset := 0
+	for offset < len(payload) {
+		frameType := payload[offset]
+		offset++
+		switch frameType {
+		case framePadding, framePing:
+		case frameAck, frameAckEcn:
+			// Largest Acknowledged, ACK Delay, ACK Range Count and First ACK Range
+			var rangeCount uint64
+			for i := 0; i < 4; i++ {
+				value, ok := readVarint(payload, &offset)
+				if !ok {
+					return frames, true
+				}
+				if i == 2 {
+					rangeCount = value
+				}
+			}
+			fields := rangeCount * 2
+			if frameType == frameAckEcn {
+				fields += 3
+			}
+			for i := uint64(0); i < fields; i++ {
+				if _, ok := readVarint(payload, &offset); !ok {
+					return frames, true
+				}
+			}
+		case frameCrypto:
+			cryptoOffset, ok := readVarint(payload, &offset)
+			if !ok {
+				return frames, true
+			}
+			length, ok := readVarint(payload, &offset)
+			if !ok {
+				return frames, true
+			}
+			end := len(payload)
+			if length < uint64(end-offset) {
+				end = offset + int(length)
+			}
+			frames = append(frames, CryptoFrame{Offset: cryptoOffset, Data: payload[offset:end]})
+			offset = end
+		case frameConnectionClose:
+			return frames, true
+		default:
+			return nil, false
+		}
+	}
+	return frames, len(frames) > 0
+}
+
+// readVarint reads the variable-length integer, see RFC 9000 section 16.
+func readVarint(data []byte, offset *int) (uint64, bool) {
+	if *offset >= len(data) {
+		return 0, false
+	}
+	length := 1 << (data[*offset] >> 6)
+	if *offset+length > len(data) {
+		return 0, false
+	}
+	value := uint64(data[*offset] & 0x3f)
+	for i := 1; i < length; i++ {
+		value = value<<8 | uint64(data[*offset+i])
+	}
+	*offset += length
+	return value, true
+}
+
+type initialKeys struct {
+	key cipher.Block
+	iv  []byte
+	hp  cipher.Block
+}
+
+// newInitialKeys derives the keys of the Initial packets sent by the client.
+func newInitialKeys(version uint32, dcid []byte) *initialKeys {
+	salt, labelPrefix := initialSaltV1, "quic "
+	if version == Version2 {
+		salt, labelPrefix = initialSaltV2, "quicv2 "
+	}
+	initialSecret := hkdfExtract(salt, dcid)
+	clientSecret := hkdfExpandLabel(initialSecret, "client in", sha256.Size)
+	key, _ := aes.NewCipher(hkdfExpandLabel(clientSecret, labelPrefix+"key", 16))
+	hp, _ := aes.NewCipher(hkdfExpandLabel(clientSecret, labelPrefix+"hp", 16))
+	return &initialKeys{
+		key: key,
+		iv:  hkdfExpandLabel(clientSecret, labelPrefix+"iv", 12),
+		hp:  hp,
+	}
+}
+
+func hkdfExtract(salt []byte, secret []byte) []byte {
+	mac := hmac.New(sha256.New, salt)
+	mac.Write(secret)
+	return mac.Sum(nil)
+}
+
+// hkdfExpandLabel is HKDF-Expand-Label of TLS 1.3 with the empty context, see RFC 8446 section 7.1.
+// The length never exceeds the size of the hash.
+func hkdfExpandLabel(secret []byte, label string, length int) []byte {
+	var info bytes.Buffer
+	_ = binary.Write(&info, binary.BigEndian, uint16(length))
+	info.WriteByte(byte(len("tls13 ") + len(label)))
+	info.WriteString("tls13 ")
+	info.WriteString(label)
+	info.WriteByte(0)
+	info.WriteByte(1)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(info.Bytes())
+	return mac.Sum(nil)[:length]
+}
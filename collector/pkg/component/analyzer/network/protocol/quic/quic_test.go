@@ -0,0 +1,175 @@
+package quic
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func appendVarint(data []byte, value uint64) []byte {
+	switch {
+	case value < 1<<6:
+		return append(data, byte(value))
+	case value < 1<<14:
+		return append(data, byte(value>>8)|0x40, byte(value))
+	default:
+		return append(data, byte(value>>24)|0x80, byte(value>>16), byte(value>>8), byte(value))
+	}
+}
+
+func appendVector(data []byte, lengthSize int, value []byte) []byte {
+	for i := lengthSize - 1; i >= 0; i-- {
+		data = append(data, byte(len(value)>>(8*i)))
+	}
+	return append(data, value...)
+}
+
+func newClientHello(serverName string, alpn ...string) []byte {
+	body := []byte{0x03, 0x03}
+	body = append(body, make([]byte, 32)...)
+	body = appendVector(body, 1, nil)
+	body = appendVector(body, 2, []byte{0x13, 0x01})
+	body = appendVector(body, 1, []byte{0})
+
+	extensions := make([]byte, 0)
+	// A large key share is sent before the server_name.
+	extensions = append(extensions, 0x00, 0x33)
+	extensions = appendVector(extensions, 2, make([]byte, 1200))
+	var names []byte
+	names = append(names, 0)
+	names = appendVector(names, 2, []byte(serverName))
+	// server_name
+	extensions = append(extensions, 0x00, 0x00)
+	extensions = appendVector(extensions, 2, appendVector(nil, 2, names))
+	var protocols []byte
+	for _, protocol := range alpn {
+		protocols = appendVector(protocols, 1, []byte(protocol))
+	}
+	// application_layer_protocol_negotiation
+	extensions = append(extensions, 0x00, 0x10)
+	extensions = appendVector(extensions, 2, appendVector(nil, 2, protocols))
+	body = appendVector(body, 2, extensions)
+	// ClientHello
+	return append([]byte{0x01}, appendVector(nil, 3, body)...)
+}
+
+func newCryptoFrame(offset uint64, data []byte) []byte {
+	frame := appendVarint([]byte{frameCrypto}, offset)
+	frame = appendVarint(frame, uint64(len(data)))
+	return append(frame, data...)
+}
+
+// newInitialPacket encrypts the frames into the Initial packet padded to 1200 bytes.
+func newInitialPacket(version uint32, dcid []byte, packetNumber uint32, frames []byte) []byte {
+	packetType := byte(0)
+	if version == Version2 {
+		packetType = 1
+	}
+	header := []byte{0xc0 | packetType<<4 | 0x03}
+	header = binary.BigEndian.AppendUint32(header, version)
+	header = appendVector(header, 1, dcid)
+	header = appendVector(header, 1, []byte{0x01, 0x02, 0x03, 0x04})
+	// The empty token
+	header = appendVarint(header, 0)
+	payloadLength := 1200 - len(header) - 2 - 4 - aes.BlockSize
+	plaintext := append(frames, make([]byte, payloadLength-len(frames))...)
+	header = appendVarint(header, uint64(4+len(plaintext)+aes.BlockSize))
+	pnOffset := len(header)
+	header = binary.BigEndian.AppendUint32(header, packetNumber)
+
+	keys := newInitialKeys(version, dcid)
+	aead, _ := cipher.NewGCM(keys.key)
+	nonce := append([]byte(nil), keys.iv...)
+	for i := 0; i < 4; i++ {
+		nonce[len(nonce)-1-i] ^= byte(packetNumber >> (8 * i))
+	}
+	packet := aead.Seal(header, nonce, plaintext, header)
+	mask := make([]byte, aes.BlockSize)
+	keys.hp.Encrypt(mask, packet[pnOffset+4:pnOffset+4+sampleLength])
+	packet[0] ^= mask[0] & 0x0f
+	for i := 0; i < 4; i++ {
+		packet[pnOffset+i] ^= mask[1+i]
+	}
+	return packet
+}
+
+func TestInitialKeys(t *testing.T) {
+	// RFC 9001 appendix A.1
+	dcid, _ := hex.DecodeString("8394c8f03e515708")
+	keys := newInitialKeys(Version1, dcid)
+	assert.Equal(t, "fa044b2f42a3fd3b46fb255c", hex.EncodeToString(keys.iv))
+	initialSecret := hkdfExtract(initialSaltV1, dcid)
+	clientSecret := hkdfExpandLabel(initialSecret, "client in", 32)
+	assert.Equal(t, "1f369613dd76d5467730efcbe3b1a22d", hex.EncodeToString(hkdfExpandLabel(clientSecret, "quic key", 16)))
+	assert.Equal(t, "9f50449e04a0e810283a1e9933adedd2", hex.EncodeToString(hkdfExpandLabel(clientSecret, "quic hp", 16)))
+
+	// RFC 9369 appendix A.1
+	keys = newInitialKeys(Version2, dcid)
+	assert.Equal(t, "91f73e2351d8fa91660e909f", hex.EncodeToString(keys.iv))
+}
+
+func TestParseInitialPacket(t *testing.T) {
+	dcid := []byte{0x83, 0x94, 0xc8, 0xf0, 0x3e, 0x51, 0x57, 0x08}
+	hello := newClientHello("example.com", "h3")
+	for _, version := range []uint32{Version1, Version2} {
+		packet := newInitialPacket(version, dcid, 2, newCryptoFrame(0, hello[:900]))
+		initial, ok := ParseInitialPacket(packet)
+		assert.True(t, ok)
+		assert.Equal(t, version, initial.Version)
+		assert.Equal(t, dcid, initial.Dcid)
+		assert.Equal(t, 1, len(initial.Crypto))
+		assert.Equal(t, hello[:900], initial.Crypto[0].Data)
+
+		// The datagram truncated by the snaplen
+		initial, ok = ParseInitialPacket(packet[:300])
+		assert.True(t, ok)
+		assert.Equal(t, hello[:len(initial.Crypto[0].Data)], initial.Crypto[0].Data)
+	}
+
+	// The Handshake packet of v1
+	packet := newInitialPacket(Version1, dcid, 0, newCryptoFrame(0, hello[:900]))
+	packet[0] |= 0x20
+	_, ok := ParseInitialPacket(packet)
+	assert.False(t, ok)
+	// The short header
+	_, ok = ParseInitialPacket([]byte{0x40, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07})
+	assert.False(t, ok)
+	// The unknown version
+	packet = newInitialPacket(0xff00001d, dcid, 0, newCryptoFrame(0, hello[:900]))
+	_, ok = ParseInitialPacket(packet)
+	assert.False(t, ok)
+}
+
+func TestHandshake(t *testing.T) {
+	dcid := []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08}
+	hello := newClientHello("www.example.com", "h3", "h3-29")
+
+	// The ClientHello is split across two packets and the frames of the first one are reordered.
+	first, _ := ParseInitialPacket(newInitialPacket(Version1, dcid, 0,
+		append(newCryptoFrame(500, hello[500:1000]), newCryptoFrame(0, hello[:500])...)))
+	second, _ := ParseInitialPacket(newInitialPacket(Version1, dcid, 1, newCryptoFrame(1000, hello[1000:])))
+
+	handshake := &Handshake{}
+	handshake.Add(first)
+	clientHello, ok := handshake.ClientHello()
+	assert.True(t, ok)
+	assert.Equal(t, "", clientHello.ServerName)
+	assert.False(t, clientHello.Complete)
+
+	handshake.Add(second)
+	clientHello, ok = handshake.ClientHello()
+	assert.True(t, ok)
+	assert.True(t, clientHello.Complete)
+	assert.Equal(t, "www.example.com", clientHello.ServerName)
+	assert.Equal(t, []string{"h3", "h3-29"}, clientHello.Alpn)
+	assert.True(t, clientHello.HasAlpn("h3"))
+	assert.False(t, clientHello.HasAlpn("h2"))
+
+	// Not a ClientHello
+	_, ok = (&Handshake{data: []byte{0x02, 0x00, 0x00, 0x10}}).ClientHello()
+	assert.False(t, ok)
+}
@@ -13,7 +13,7 @@ import (
  */
 func fastfailRedisArray() protocol.FastFailFn {
 	return func(message *protocol.PayloadMessage) bool {
-		return message.Data[message.Offset] != '*'
+		return message.IsComplete() || message.Data[message.Offset] != '*'
 	}
 }
 
@@ -2,6 +2,7 @@ package redis
 
 import (
 	"strconv"
+	"strings"
 
 	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
 	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
@@ -12,11 +13,11 @@ $3\r\nbar\r\n
 */
 func fastfailRedisBulkString() protocol.FastFailFn {
 	return func(message *protocol.PayloadMessage) bool {
-		return message.Data[message.Offset] != '$'
+		return message.IsComplete() || message.Data[message.Offset] != '$'
 	}
 }
 
-func parseRedisBulkString() protocol.ParsePkgFn {
+func parseRedisBulkString(maskAuth bool) protocol.ParsePkgFn {
 	return func(message *protocol.PayloadMessage) (bool, bool) {
 		offset, data := message.ReadUntilCRLF(message.Offset + 1)
 		if data == nil {
@@ -47,6 +48,13 @@ func parseRedisBulkString() protocol.ParsePkgFn {
 			return false, true
 		}
 
+		if maskAuth && strings.EqualFold(message.GetStringAttribute(constlabels.RedisCommand), "AUTH") {
+			// The arguments of AUTH are the username and the password.
+			protocol.MaskBytes(data)
+			message.Offset = offset
+			return true, message.IsComplete()
+		}
+
 		command := string(data)
 		if !message.HasAttribute(command) && IsRedisCommand(data) {
 			message.AddUtf8StringAttribute(constlabels.ContentKey, command)
@@ -1,16 +1,25 @@
 package redis
 
 import (
+	"bytes"
+
 	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
 	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
 )
 
+// The errors of Redis Cluster redirecting the keys to the other nodes, e.g. "-MOVED 3999 127.0.0.1:6381".
+// ASK is sent while the slot is migrating, and MOVED after the slot is moved.
+var redirectPrefixes = map[string]string{
+	"MOVED ": "moved",
+	"ASK ":   "ask",
+}
+
 /**
 -Error message\r\n
 */
 func fastfailRedisError() protocol.FastFailFn {
 	return func(message *protocol.PayloadMessage) bool {
-		return message.Data[message.Offset] != '-'
+		return message.IsComplete() || message.Data[message.Offset] != '-'
 	}
 }
 
@@ -26,7 +35,25 @@ func parseRedisError() protocol.ParsePkgFn {
 			message.AddByteArrayUtf8Attribute(constlabels.RedisErrMsg, data)
 			message.AddBoolAttribute(constlabels.IsError, true)
 			message.AddIntAttribute(constlabels.ErrorType, int64(constlabels.ProtocolError))
+			addRedirect(message, data)
 		}
 		return true, message.IsComplete()
 	}
 }
+
+// addRedirect labels the redirection with its kind and the address of the target node.
+func addRedirect(message *protocol.PayloadMessage, data []byte) {
+	for prefix, redirect := range redirectPrefixes {
+		if !bytes.HasPrefix(data, []byte(prefix)) {
+			continue
+		}
+		// <slot> <host>:<port>
+		fields := bytes.Fields(data[len(prefix):])
+		if len(fields) != 2 {
+			return
+		}
+		message.AddStringAttribute(constlabels.RedisRedirect, redirect)
+		message.AddByteArrayUtf8Attribute(constlabels.RedisRedirectTarget, fields[1])
+		return
+	}
+}
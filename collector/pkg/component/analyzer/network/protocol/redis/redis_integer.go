@@ -11,7 +11,7 @@ import (
 */
 func fastfailRedisInteger() protocol.FastFailFn {
 	return func(message *protocol.PayloadMessage) bool {
-		return message.Data[message.Offset] != ':'
+		return message.IsComplete() || message.Data[message.Offset] != ':'
 	}
 }
 
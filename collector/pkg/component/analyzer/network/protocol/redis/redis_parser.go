@@ -4,18 +4,20 @@ import (
 	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
 )
 
-func NewRedisParser() *protocol.ProtocolParser {
+// NewRedisParser creates the parser of Redis. If maskAuth is true, the arguments of AUTH are masked.
+func NewRedisParser(maskAuth bool) *protocol.ProtocolParser {
 	requestParser := protocol.CreatePkgParser(fastfailRedisRequest(), parseRedisRequest())
 	requestParser.Add(fastfailRedisArray(), parseRedisArray())
-	requestParser.Add(fastfailRedisBulkString(), parseRedisBulkString())
+	requestParser.Add(fastfailRedisBulkString(), parseRedisBulkString(maskAuth))
 	requestParser.Add(fastfailRedisInteger(), parseRedisInteger())
 
 	responseParser := protocol.CreatePkgParser(fastfailResponse(), parseResponse())
 	responseParser.Add(fastfailRedisArray(), parseRedisArray())
-	responseParser.Add(fastfailRedisBulkString(), parseRedisBulkString())
+	responseParser.Add(fastfailRedisBulkString(), parseRedisBulkString(false))
 	responseParser.Add(fastfailRedisInteger(), parseRedisInteger())
 	responseParser.Add(fastfailRedisSimpleString(), parseRedisSimpleString())
 	responseParser.Add(fastfailRedisError(), parseRedisError())
+	responseParser.Add(fastfailRedisResp3(), parseRedisResp3())
 
 	redisParser := protocol.NewProtocolParser(protocol.REDIS, requestParser, responseParser, nil)
 	redisParser.EnableMultiFrame()
@@ -0,0 +1,105 @@
+package redis
+
+import (
+	"testing"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+func TestParseRedisRequest_MaskAuth(t *testing.T) {
+	tests := []struct {
+		name     string
+		maskAuth bool
+		data     string
+		want     string
+	}{
+		{name: "auth", maskAuth: true, data: "*3\r\n$4\r\nAUTH\r\n$5\r\nadmin\r\n$6\r\nsecret\r\n", want: "*3\r\n$4\r\nAUTH\r\n$5\r\n*****\r\n$6\r\n******\r\n"},
+		{name: "other command", maskAuth: true, data: "*2\r\n$3\r\nGET\r\n$3\r\nkey\r\n", want: "*2\r\n$3\r\nGET\r\n$3\r\nkey\r\n"},
+		{name: "disabled", maskAuth: false, data: "*2\r\n$4\r\nauth\r\n$6\r\nsecret\r\n", want: "*2\r\n$4\r\nauth\r\n$6\r\nsecret\r\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := []byte(tt.data)
+			message := protocol.NewRequestMessage(data)
+			if !NewRedisParser(tt.maskAuth).ParseRequest(message) {
+				t.Fatal("failed to parse the request")
+			}
+			if string(data) != tt.want {
+				t.Errorf("payload = %q, want %q", data, tt.want)
+			}
+			if message.GetStringAttribute(constlabels.RedisCommand) == "" {
+				t.Errorf("the command is not parsed")
+			}
+		})
+	}
+}
+
+func TestParseRedisResponse_ProtocolVersion(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        string
+		wantVersion string
+		wantErrMsg  string
+	}{
+		{name: "RESP2 bulk string", data: "$3\r\nbar\r\n", wantVersion: "2"},
+		{name: "RESP2 array", data: "*2\r\n$1\r\na\r\n:1\r\n", wantVersion: "2"},
+		{name: "RESP3 map", data: "%1\r\n$6\r\nserver\r\n$5\r\nredis\r\n", wantVersion: "3"},
+		{name: "RESP3 null", data: "_\r\n", wantVersion: "3"},
+		{name: "RESP3 double in array", data: "*2\r\n,3.14\r\n#t\r\n", wantVersion: "3"},
+		{name: "RESP3 blob error", data: "!21\r\nSYNTAX invalid syntax\r\n", wantVersion: "3", wantErrMsg: "SYNTAX invalid syntax"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := NewRedisParser(false)
+			request := protocol.NewRequestMessage([]byte("*2\r\n$3\r\nGET\r\n$3\r\nfoo\r\n"))
+			if !parser.ParseRequest(request) {
+				t.Fatal("failed to parse the request")
+			}
+			response := protocol.NewResponseMessage([]byte(tt.data), request.GetAttributes())
+			if !parser.ParseResponse(response) {
+				t.Fatal("failed to parse the response")
+			}
+			if got := response.GetStringAttribute(constlabels.ProtocolVersion); got != tt.wantVersion {
+				t.Errorf("protocol_version = %v, want %v", got, tt.wantVersion)
+			}
+			if got := response.GetStringAttribute(constlabels.RedisErrMsg); got != tt.wantErrMsg {
+				t.Errorf("redis_msg = %v, want %v", got, tt.wantErrMsg)
+			}
+		})
+	}
+}
+
+func TestParseRedisResponse_Redirect(t *testing.T) {
+	tests := []struct {
+		name         string
+		data         string
+		wantRedirect string
+		wantTarget   string
+	}{
+		{name: "moved", data: "-MOVED 3999 127.0.0.1:6381\r\n", wantRedirect: "moved", wantTarget: "127.0.0.1:6381"},
+		{name: "ask", data: "-ASK 3999 10.0.0.12:6379\r\n", wantRedirect: "ask", wantTarget: "10.0.0.12:6379"},
+		{name: "other error", data: "-ERR unknown command 'FOO'\r\n"},
+		{name: "malformed", data: "-MOVED 3999\r\n"},
+		{name: "RESP3 push", data: ">3\r\n$7\r\nmessage\r\n$4\r\nnews\r\n$5\r\nhello\r\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := NewRedisParser(false)
+			request := protocol.NewRequestMessage([]byte("*2\r\n$3\r\nGET\r\n$3\r\nfoo\r\n"))
+			if !parser.ParseRequest(request) {
+				t.Fatal("failed to parse the request")
+			}
+			response := protocol.NewResponseMessage([]byte(tt.data), request.GetAttributes())
+			if !parser.ParseResponse(response) {
+				t.Fatal("failed to parse the response")
+			}
+			if got := response.GetStringAttribute(constlabels.RedisRedirect); got != tt.wantRedirect {
+				t.Errorf("redis_redirect = %v, want %v", got, tt.wantRedirect)
+			}
+			if got := response.GetStringAttribute(constlabels.RedisRedirectTarget); got != tt.wantTarget {
+				t.Errorf("redis_redirect_target = %v, want %v", got, tt.wantTarget)
+			}
+		})
+	}
+}
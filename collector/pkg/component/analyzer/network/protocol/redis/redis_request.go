@@ -11,6 +11,9 @@ For Arrays the first byte of the reply is "*"
 */
 func fastfailRedisRequest() protocol.FastFailFn {
 	return func(message *protocol.PayloadMessage) bool {
+		if message.IsComplete() {
+			return true
+		}
 		keyword := message.Data[message.Offset]
 		return keyword != '*' &&
 			keyword != '$' &&
@@ -0,0 +1,69 @@
+package redis
+
+import (
+	"strconv"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+// The versions of the protocol. RESP3 is used after the client switches to it with "HELLO 3".
+const (
+	resp2 = "2"
+	resp3 = "3"
+)
+
+/*
+The types added by RESP3
+
+	%2\r\n, ~2\r\n, >2\r\n and |1\r\n are the headers of the maps, the sets, the pushes and the attributes
+	_\r\n, #t\r\n, ,1.23\r\n and (3492890328409238509324850943850943825024385\r\n are the simple types
+	=15\r\ntxt:Some string\r\n and !21\r\nSYNTAX invalid syntax\r\n are the verbatim strings and the blob errors
+*/
+func isResp3Type(keyword byte) bool {
+	switch keyword {
+	case '%', '~', '>', '|', '_', '#', ',', '(', '=', '!':
+		return true
+	}
+	return false
+}
+
+func fastfailRedisResp3() protocol.FastFailFn {
+	return func(message *protocol.PayloadMessage) bool {
+		return message.IsComplete() || !isResp3Type(message.Data[message.Offset])
+	}
+}
+
+func parseRedisResp3() protocol.ParsePkgFn {
+	return func(message *protocol.PayloadMessage) (bool, bool) {
+		keyword := message.Data[message.Offset]
+		offset, data := message.ReadUntilCRLF(message.Offset + 1)
+		if data == nil {
+			return false, true
+		}
+
+		switch keyword {
+		case '%', '~', '>', '|':
+			if _, err := strconv.Atoi(string(data)); err != nil {
+				return false, true
+			}
+		case '=', '!':
+			size, err := strconv.Atoi(string(data))
+			if err != nil {
+				return false, true
+			}
+			offset, data = message.ReadUntilCRLF(offset)
+			if data == nil || len(data) != size {
+				return false, true
+			}
+			if keyword == '!' && !message.HasAttribute(constlabels.RedisErrMsg) {
+				message.AddByteArrayUtf8Attribute(constlabels.RedisErrMsg, data)
+				message.AddBoolAttribute(constlabels.IsError, true)
+				message.AddIntAttribute(constlabels.ErrorType, int64(constlabels.ProtocolError))
+			}
+		}
+		message.AddStringAttribute(constlabels.ProtocolVersion, resp3)
+		message.Offset = offset
+		return true, message.IsComplete()
+	}
+}
@@ -2,6 +2,7 @@ package redis
 
 import (
 	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
 )
 
 /*
@@ -13,17 +14,26 @@ For Arrays the first byte of the reply is "*"
 */
 func fastfailResponse() protocol.FastFailFn {
 	return func(message *protocol.PayloadMessage) bool {
+		if message.IsComplete() {
+			return true
+		}
 		keyword := message.Data[message.Offset]
 		return keyword != '+' &&
 			keyword != '-' &&
 			keyword != '*' &&
 			keyword != '$' &&
-			keyword != ':'
+			keyword != ':' &&
+			!isResp3Type(keyword)
 	}
 }
 
+// parseResponse takes the response as RESP2 unless any type of RESP3 is found, so the replies of RESP3
+// only holding the types shared with RESP2 are taken as RESP2.
 func parseResponse() protocol.ParsePkgFn {
 	return func(message *protocol.PayloadMessage) (bool, bool) {
+		if !message.HasAttribute(constlabels.ProtocolVersion) {
+			message.AddStringAttribute(constlabels.ProtocolVersion, resp2)
+		}
 		return true, false
 	}
 }
@@ -9,7 +9,7 @@ import (
 */
 func fastfailRedisSimpleString() protocol.FastFailFn {
 	return func(message *protocol.PayloadMessage) bool {
-		return message.Data[message.Offset] != '+'
+		return message.IsComplete() || message.Data[message.Offset] != '+'
 	}
 }
 
@@ -3,6 +3,7 @@ package rocketmq
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
 
 	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
 	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
@@ -43,6 +44,8 @@ func parseRocketMQRequest() protocol.ParsePkgFn {
 		// Store the parsed attribute via AddStringAttribute() or AttIntAttribute()
 		message.AddStringAttribute(constlabels.RocketMQRequestMsg, requestMsgMap[header.Code])
 		message.AddIntAttribute(constlabels.RocketMQOpaque, int64(header.Opaque))
+		// The version is the code of the client release, e.g. 373 for V4_9_1.
+		message.AddStringAttribute(constlabels.ProtocolVersion, strconv.Itoa(int(header.Version)))
 
 		//topicName maybe be stored in key `topic` or `b`
 		if header.ExtFields["topic"] != "" {
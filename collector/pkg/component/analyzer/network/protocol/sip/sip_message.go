@@ -0,0 +1,129 @@
+package sip
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+// maxLineLength is the max length of the start line and the headers read, which are short in practice.
+const maxLineLength = 1024
+
+// methods are the methods of the requests, see RFC 3261 and its extensions.
+var methods = map[string]bool{
+	"INVITE": true, "ACK": true, "BYE": true, "CANCEL": true, "REGISTER": true, "OPTIONS": true,
+	"PRACK": true, "SUBSCRIBE": true, "NOTIFY": true, "PUBLISH": true, "INFO": true, "REFER": true,
+	"MESSAGE": true, "UPDATE": true,
+}
+
+// Message is the start line and the headers of the SIP message which pair the response with the request.
+type Message struct {
+	// Method is the method of the request, which is empty for the responses.
+	Method     string
+	StatusCode int
+	// CallId identifies the dialog or the registrations of a client.
+	CallId string
+	// CseqNumber and CseqMethod are the CSeq header, e.g. "1 INVITE", which identify the transaction in the
+	// dialog together with the Call-ID. The CANCEL and the ACK of an INVITE share its number with their own
+	// methods.
+	CseqNumber uint32
+	CseqMethod string
+}
+
+// ParseMessage returns the start line and the headers of the SIP message, or false if the data is not a
+// SIP message or the Call-ID and the CSeq headers are not found.
+//
+//	INVITE sip:bob@biloxi.example.com SIP/2.0
+//	Call-ID: a84b4c76e66710@pc33.atlanta.example.com
+//	CSeq: 314159 INVITE
+//
+//	SIP/2.0 180 Ringing
+//	i: a84b4c76e66710@pc33.atlanta.example.com
+//	CSeq: 314159 INVITE
+func ParseMessage(data []byte) (*Message, bool) {
+	line, rest, ok := readLine(data)
+	if !ok {
+		return nil, false
+	}
+	message := &Message{}
+	if !message.parseStartLine(line) {
+		return nil, false
+	}
+	for len(rest) > 0 {
+		if line, rest, ok = readLine(rest); !ok || len(line) == 0 {
+			// The headers end with an empty line, or are truncated.
+			break
+		}
+		// The folded lines are the continuations of the previous headers.
+		if line[0] == ' ' || line[0] == '\t' {
+			continue
+		}
+		name, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "call-id", "i":
+			message.CallId = value
+		case "cseq":
+			number, method, _ := strings.Cut(value, " ")
+			cseq, err := strconv.ParseUint(number, 10, 32)
+			if err != nil {
+				return nil, false
+			}
+			message.CseqNumber = uint32(cseq)
+			message.CseqMethod = strings.ToUpper(strings.TrimSpace(method))
+		}
+	}
+	if message.CallId == "" || message.CseqMethod == "" {
+		return nil, false
+	}
+	return message, true
+}
+
+func (m *Message) parseStartLine(line string) bool {
+	if strings.HasPrefix(line, "SIP/2.0 ") {
+		codeString, _, _ := strings.Cut(line[len("SIP/2.0 "):], " ")
+		code, err := strconv.Atoi(codeString)
+		if err != nil || code < 100 || code > 699 {
+			return false
+		}
+		m.StatusCode = code
+		return true
+	}
+	method, rest, found := strings.Cut(line, " ")
+	if !found || !methods[method] || !strings.HasSuffix(rest, " SIP/2.0") {
+		return false
+	}
+	m.Method = method
+	return true
+}
+
+// readLine returns the line ended with CRLF or LF and the data after it.
+func readLine(data []byte) (string, []byte, bool) {
+	end := bytes.IndexByte(data, '\n')
+	if end < 0 || end > maxLineLength {
+		return "", nil, false
+	}
+	return string(bytes.TrimSuffix(data[:end], []byte("\r"))), data[end+1:], true
+}
+
+func (m *Message) IsRequest() bool {
+	return m.Method != ""
+}
+
+// IsProvisional returns true for the 1xx responses, which are followed by the final response.
+func (m *Message) IsProvisional() bool {
+	return m.StatusCode >= 100 && m.StatusCode < 200
+}
+
+// IsFailure returns true for the final responses failing the requests. The challenges 401 and 407 asking
+// the client to authenticate, and 487 answering the INVITE canceled by the client, are the normal flows.
+func (m *Message) IsFailure() bool {
+	switch m.StatusCode {
+	case 401, 407, 487:
+		return false
+	}
+	return m.StatusCode >= 400
+}
@@ -0,0 +1,52 @@
+package sip
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMessage(t *testing.T) {
+	message, ok := ParseMessage([]byte("INVITE sip:bob@biloxi.example.com SIP/2.0\r\n" +
+		"Via: SIP/2.0/UDP pc33.atlanta.example.com;branch=z9hG4bK776asdhds\r\n" +
+		"Call-ID: a84b4c76e66710@pc33.atlanta.example.com\r\n" +
+		"CSeq: 314159 INVITE\r\n" +
+		"Content-Type: application/sdp\r\n" +
+		"\r\n" +
+		"v=0\r\n"))
+	if assert.True(t, ok) {
+		assert.True(t, message.IsRequest())
+		assert.Equal(t, "INVITE", message.Method)
+		assert.Equal(t, "a84b4c76e66710@pc33.atlanta.example.com", message.CallId)
+		assert.Equal(t, uint32(314159), message.CseqNumber)
+		assert.Equal(t, "INVITE", message.CseqMethod)
+	}
+
+	// The compact form of Call-ID
+	message, ok = ParseMessage([]byte("SIP/2.0 180 Ringing\r\ni: a84b4c76e66710\r\nCSeq: 314159 INVITE\r\n\r\n"))
+	if assert.True(t, ok) {
+		assert.False(t, message.IsRequest())
+		assert.Equal(t, 180, message.StatusCode)
+		assert.True(t, message.IsProvisional())
+		assert.Equal(t, "a84b4c76e66710", message.CallId)
+	}
+
+	for _, data := range []string{
+		"GET / HTTP/1.1\r\nHost: example.com\r\n\r\n",
+		"HTTP/1.1 200 OK\r\n\r\n",
+		"SIP/2.0 99 Unknown\r\nCall-ID: a\r\nCSeq: 1 INVITE\r\n\r\n",
+		"INVITE sip:bob@biloxi.example.com SIP/2.0\r\nCSeq: 1 INVITE\r\n\r\n",
+		"REGISTER sip:registrar.biloxi.example.com SIP/2.0\r\nCall-ID: a\r\nCSeq: x REGISTER\r\n\r\n",
+		"INVITE sip:bob@biloxi.example.com",
+	} {
+		_, ok := ParseMessage([]byte(data))
+		assert.False(t, ok, data)
+	}
+}
+
+func TestMessage_IsFailure(t *testing.T) {
+	for code, want := range map[int]bool{200: false, 302: false, 401: false, 404: true, 407: false, 486: true,
+		487: false, 503: true, 603: true} {
+		assert.Equal(t, want, (&Message{StatusCode: code}).IsFailure(), code)
+	}
+}
@@ -0,0 +1,146 @@
+package ssh
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+)
+
+// The identification string starts with "SSH-protoversion-", and "SSH-1.99-" is sent by the servers
+// compatible with the version 1, see RFC 4253.
+var identificationPrefixes = [][]byte{[]byte("SSH-2.0-"), []byte("SSH-1.99-")}
+
+const (
+	// maxIdentificationLength is the max length of the identification string including CRLF.
+	maxIdentificationLength = 255
+	// maxPacketLength is PACKET_MAX_SIZE of OpenSSH.
+	maxPacketLength = 256 * 1024
+	// minPaddingLength is the min length of the random padding of the binary packets.
+	minPaddingLength = 4
+)
+
+// The messages sent in plain text before the keys are taken into use.
+const (
+	msgDisconnect = 1
+	msgKexInit    = 20
+	msgNewKeys    = 21
+	// The messages from 30 to 49 are specific to the key exchange methods, e.g. SSH_MSG_KEX_ECDH_INIT.
+	msgKexMethodFirst = 30
+	msgKexMethodLast  = 49
+)
+
+const (
+	contentIdentification = "identification"
+	contentKeyExchange    = "key_exchange"
+)
+
+// fileTransferClients are the prefixes of the software of the clients used for SFTP or SCP only. The
+// subsystem "sftp" is requested in the encrypted channel, so the software is the only hint.
+var fileTransferClients = []string{
+	"winscp", "filezilla", "jsch", "sshj", "cyberduck", "moveit", "goanywhere", "coreftp", "rebex", "ws_ftp",
+}
+
+// readIdentification returns the software of the identification string, e.g. "OpenSSH_8.9p1" of
+// "SSH-2.0-OpenSSH_8.9p1 Ubuntu-3\r\n", and the length of the line.
+func readIdentification(data []byte) (string, int, bool) {
+	var prefix []byte
+	for _, p := range identificationPrefixes {
+		if bytes.HasPrefix(data, p) {
+			prefix = p
+			break
+		}
+	}
+	if prefix == nil {
+		return "", 0, false
+	}
+	end := bytes.IndexByte(data, '\n')
+	if end < 0 {
+		if len(data) > maxIdentificationLength {
+			return "", 0, false
+		}
+		// The line is truncated.
+		end = len(data) - 1
+	}
+	if end+1 > maxIdentificationLength {
+		return "", 0, false
+	}
+	line := bytes.TrimRight(data[len(prefix):end+1], "\r\n")
+	software, _, _ := bytes.Cut(line, []byte(" "))
+	if len(software) == 0 {
+		return "", 0, false
+	}
+	for _, c := range software {
+		if c < 0x21 || c > 0x7e {
+			return "", 0, false
+		}
+	}
+	return string(software), end + 1, true
+}
+
+// identificationVersion returns the protocol version of the identification string. "1.99" is sent by
+// the servers which also accept the clients of SSH 1.
+func identificationVersion(data []byte) string {
+	if bytes.HasPrefix(data, identificationPrefixes[1]) {
+		return "1.99"
+	}
+	return "2.0"
+}
+
+// readPacketMessage returns the message number of the binary packet sent in plain text.
+func readPacketMessage(data []byte) (byte, []byte, bool) {
+	if len(data) < 6 {
+		return 0, nil, false
+	}
+	packetLength := binary.BigEndian.Uint32(data)
+	paddingLength := uint32(data[4])
+	if packetLength > maxPacketLength || paddingLength < minPaddingLength || paddingLength+2 > packetLength {
+		return 0, nil, false
+	}
+	msg := data[5]
+	if msg != msgDisconnect && msg != msgKexInit && msg != msgNewKeys && (msg < msgKexMethodFirst || msg > msgKexMethodLast) {
+		return 0, nil, false
+	}
+	payload := data[6:]
+	if payloadLength := packetLength - paddingLength - 2; uint32(len(payload)) > payloadLength {
+		payload = payload[:payloadLength]
+	}
+	return msg, payload, true
+}
+
+// hasNewKeys returns true if SSH_MSG_NEWKEYS is found in the binary packets sent in plain text, which
+// ends the key exchange. The packets after it are encrypted and not read.
+func hasNewKeys(data []byte) bool {
+	for len(data) > 0 {
+		msg, _, ok := readPacketMessage(data)
+		if !ok {
+			return false
+		}
+		if msg == msgNewKeys {
+			return true
+		}
+		next := 4 + uint64(binary.BigEndian.Uint32(data))
+		if next > uint64(len(data)) {
+			return false
+		}
+		data = data[next:]
+	}
+	return false
+}
+
+func isFileTransferClient(software string) bool {
+	software = strings.ToLower(software)
+	for _, client := range fileTransferClients {
+		if strings.HasPrefix(software, client) {
+			return true
+		}
+	}
+	return false
+}
+
+func NewSshParser() *protocol.ProtocolParser {
+	requestParser := protocol.CreatePkgParser(fastfailSshRequest(), parseSshRequest())
+	responseParser := protocol.CreatePkgParser(fastfailSshResponse(), parseSshResponse())
+	return protocol.NewProtocolParser(protocol.SSH, requestParser, responseParser, nil)
+}
@@ -0,0 +1,89 @@
+package ssh
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+// newPacket returns the binary packet sent in plain text, whose padding is aligned to 8 bytes.
+func newPacket(payload []byte) []byte {
+	paddingLength := 8 - (5+len(payload))%8
+	if paddingLength < minPaddingLength {
+		paddingLength += 8
+	}
+	data := binary.BigEndian.AppendUint32(nil, uint32(1+len(payload)+paddingLength))
+	data = append(data, byte(paddingLength))
+	data = append(data, payload...)
+	return append(data, make([]byte, paddingLength)...)
+}
+
+func newDisconnect(reason uint32, description string) []byte {
+	payload := binary.BigEndian.AppendUint32([]byte{msgDisconnect}, reason)
+	payload = binary.BigEndian.AppendUint32(payload, uint32(len(description)))
+	payload = append(payload, description...)
+	return newPacket(binary.BigEndian.AppendUint32(payload, 0))
+}
+
+func TestParseSshRequest(t *testing.T) {
+	parser := NewSshParser()
+
+	request := protocol.NewRequestMessage([]byte("SSH-2.0-OpenSSH_8.9p1 Ubuntu-3ubuntu0.1\r\n"))
+	assert.True(t, parser.ParseRequest(request))
+	assert.Equal(t, "OpenSSH_8.9p1", request.GetStringAttribute(constlabels.SshClientSoftware))
+	assert.Equal(t, "2.0", request.GetStringAttribute(constlabels.ProtocolVersion))
+	assert.False(t, request.HasAttribute(constlabels.SshFileTransfer))
+	assert.Equal(t, "identification", request.GetStringAttribute(constlabels.ContentKey))
+
+	request = protocol.NewRequestMessage([]byte("SSH-2.0-JSCH-0.1.54\r\n"))
+	assert.True(t, parser.ParseRequest(request))
+	assert.Equal(t, "JSCH-0.1.54", request.GetStringAttribute(constlabels.SshClientSoftware))
+	assert.True(t, request.GetBoolAttribute(constlabels.SshFileTransfer))
+
+	request = protocol.NewRequestMessage(newPacket([]byte{msgKexInit, 1, 2, 3, 4}))
+	assert.True(t, parser.ParseRequest(request))
+	assert.Equal(t, "key_exchange", request.GetStringAttribute(constlabels.ContentKey))
+	assert.False(t, request.HasAttribute(constlabels.SshNewKeys))
+
+	// SSH_MSG_NEWKEYS follows SSH_MSG_KEX_ECDH_INIT, and the encrypted packets follow it.
+	data := append(newPacket([]byte{30, 1, 2, 3, 4}), newPacket([]byte{msgNewKeys})...)
+	request = protocol.NewRequestMessage(append(data, 0x8f, 0x3a, 0x11, 0x00, 0x5c, 0x21))
+	assert.True(t, parser.ParseRequest(request))
+	assert.True(t, request.GetBoolAttribute(constlabels.SshNewKeys))
+
+	// The encrypted packets
+	for _, data := range [][]byte{
+		{0x8f, 0x3a, 0x11, 0x00, 0x5c, 0x21, 0x07, 0x9e},
+		newPacket([]byte{94, 0, 0, 0, 0}),
+		[]byte("SSH-2.0- \r\n"),
+	} {
+		assert.False(t, parser.ParseRequest(protocol.NewRequestMessage(data)))
+	}
+}
+
+func TestParseSshResponse(t *testing.T) {
+	parser := NewSshParser()
+
+	data := append([]byte("SSH-1.99-OpenSSH_7.4\r\n"), newPacket([]byte{msgKexInit, 1, 2, 3, 4})...)
+	response := protocol.NewResponseMessage(data, protocol.NewRequestMessage(nil).GetAttributes())
+	assert.True(t, parser.ParseResponse(response))
+	assert.Equal(t, "OpenSSH_7.4", response.GetStringAttribute(constlabels.SshServerSoftware))
+	assert.Equal(t, "1.99", response.GetStringAttribute(constlabels.ProtocolVersion))
+	assert.False(t, response.GetBoolAttribute(constlabels.IsError))
+
+	response = protocol.NewResponseMessage(append(newPacket([]byte{31, 0, 0, 0, 0}), newPacket([]byte{msgNewKeys})...),
+		protocol.NewRequestMessage(nil).GetAttributes())
+	assert.True(t, parser.ParseResponse(response))
+	assert.False(t, response.HasAttribute(constlabels.SshServerSoftware))
+	assert.True(t, response.GetBoolAttribute(constlabels.SshNewKeys))
+
+	// SSH_DISCONNECT_KEY_EXCHANGE_FAILED
+	response = protocol.NewResponseMessage(newDisconnect(3, "no matching host key type found"), protocol.NewRequestMessage(nil).GetAttributes())
+	assert.True(t, parser.ParseResponse(response))
+	assert.Equal(t, int64(3), response.GetIntAttribute(constlabels.SshDisconnectReason))
+	assert.True(t, response.GetBoolAttribute(constlabels.IsError))
+}
@@ -0,0 +1,40 @@
+package ssh
+
+import (
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+func fastfailSshRequest() protocol.FastFailFn {
+	return func(message *protocol.PayloadMessage) bool {
+		return len(message.Data) < 6
+	}
+}
+
+// parseSshRequest reads the identification string or the key exchange messages of the client. The
+// packets after SSH_MSG_NEWKEYS are encrypted and not recognized, so SSH_MSG_NEWKEYS is marked to end
+// the handshake.
+func parseSshRequest() protocol.ParsePkgFn {
+	return func(message *protocol.PayloadMessage) (bool, bool) {
+		if software, length, ok := readIdentification(message.Data); ok {
+			message.AddUtf8StringAttribute(constlabels.SshClientSoftware, software)
+			message.AddStringAttribute(constlabels.ProtocolVersion, identificationVersion(message.Data))
+			if isFileTransferClient(software) {
+				message.AddBoolAttribute(constlabels.SshFileTransfer, true)
+			}
+			message.AddStringAttribute(constlabels.ContentKey, contentIdentification)
+			if hasNewKeys(message.Data[length:]) {
+				message.AddBoolAttribute(constlabels.SshNewKeys, true)
+			}
+			return true, true
+		}
+		if msg, _, ok := readPacketMessage(message.Data); !ok || msg == msgDisconnect {
+			return false, true
+		}
+		message.AddStringAttribute(constlabels.ContentKey, contentKeyExchange)
+		if hasNewKeys(message.Data) {
+			message.AddBoolAttribute(constlabels.SshNewKeys, true)
+		}
+		return true, true
+	}
+}
@@ -0,0 +1,45 @@
+package ssh
+
+import (
+	"encoding/binary"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+func fastfailSshResponse() protocol.FastFailFn {
+	return func(message *protocol.PayloadMessage) bool {
+		return len(message.Data) < 6
+	}
+}
+
+// parseSshResponse reads the identification string or the key exchange messages of the server, which
+// may follow the identification string in the same response. SSH_MSG_DISCONNECT sent before the keys
+// are taken into use, e.g. for no matching algorithms, is an error.
+func parseSshResponse() protocol.ParsePkgFn {
+	return func(message *protocol.PayloadMessage) (bool, bool) {
+		data := message.Data
+		software, length, ok := readIdentification(data)
+		if ok {
+			message.AddUtf8StringAttribute(constlabels.SshServerSoftware, software)
+			message.AddStringAttribute(constlabels.ProtocolVersion, identificationVersion(data))
+			data = data[length:]
+		}
+		msg, payload, isPacket := readPacketMessage(data)
+		if !ok && !isPacket {
+			return false, true
+		}
+		if isPacket && msg == msgDisconnect {
+			// uint32 reason code, string description and string language tag
+			if len(payload) >= 4 {
+				message.AddIntAttribute(constlabels.SshDisconnectReason, int64(binary.BigEndian.Uint32(payload)))
+			}
+			message.AddBoolAttribute(constlabels.IsError, true)
+			message.AddIntAttribute(constlabels.ErrorType, int64(constlabels.ProtocolError))
+		}
+		if isPacket && hasNewKeys(data) {
+			message.AddBoolAttribute(constlabels.SshNewKeys, true)
+		}
+		return true, true
+	}
+}
@@ -0,0 +1,241 @@
+package tars
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// The types of the fields in the Tars encoding.
+const (
+	typeInt8        = 0
+	typeInt16       = 1
+	typeInt32       = 2
+	typeInt64       = 3
+	typeFloat       = 4
+	typeDouble      = 5
+	typeString1     = 6
+	typeString4     = 7
+	typeMap         = 8
+	typeList        = 9
+	typeStructBegin = 10
+	typeStructEnd   = 11
+	typeZero        = 12
+	typeSimpleList  = 13
+)
+
+// maxDepth limits the nesting of the structs and containers skipped.
+const maxDepth = 16
+
+var (
+	errTruncated = errors.New("tars: the data is truncated")
+	errInvalid   = errors.New("tars: the data is invalid")
+)
+
+// tarsReader reads the fields encoded in Tars, each of which starts with a head holding the tag and the type.
+type tarsReader struct {
+	data   []byte
+	offset int
+}
+
+func (r *tarsReader) skip(n int) error {
+	if n < 0 {
+		return errInvalid
+	}
+	if r.offset+n > len(r.data) {
+		return errTruncated
+	}
+	r.offset += n
+	return nil
+}
+
+// readHead reads the head, whose tag is in the high 4 bits or in the next byte if the bits are 15.
+func (r *tarsReader) readHead() (tag int, typ byte, err error) {
+	if r.offset >= len(r.data) {
+		return 0, 0, errTruncated
+	}
+	b := r.data[r.offset]
+	r.offset++
+	typ = b & 0x0f
+	tag = int(b >> 4)
+	if tag == 15 {
+		if r.offset >= len(r.data) {
+			return 0, 0, errTruncated
+		}
+		tag = int(r.data[r.offset])
+		r.offset++
+	}
+	return tag, typ, nil
+}
+
+// readInt reads the integers, which are encoded in the shortest type holding them.
+func (r *tarsReader) readInt(typ byte) (int64, error) {
+	var size int
+	switch typ {
+	case typeZero:
+		return 0, nil
+	case typeInt8:
+		size = 1
+	case typeInt16:
+		size = 2
+	case typeInt32:
+		size = 4
+	case typeInt64:
+		size = 8
+	default:
+		return 0, errInvalid
+	}
+	if r.offset+size > len(r.data) {
+		return 0, errTruncated
+	}
+	data := r.data[r.offset : r.offset+size]
+	r.offset += size
+	switch size {
+	case 1:
+		return int64(int8(data[0])), nil
+	case 2:
+		return int64(int16(binary.BigEndian.Uint16(data))), nil
+	case 4:
+		return int64(int32(binary.BigEndian.Uint32(data))), nil
+	default:
+		return int64(binary.BigEndian.Uint64(data)), nil
+	}
+}
+
+// readLength reads the length of the containers, which is encoded as an integer field with the tag 0.
+func (r *tarsReader) readLength() (int, error) {
+	_, typ, err := r.readHead()
+	if err != nil {
+		return 0, err
+	}
+	length, err := r.readInt(typ)
+	if err != nil {
+		return 0, err
+	}
+	if length < 0 || length > int64(len(r.data)) {
+		return 0, errInvalid
+	}
+	return int(length), nil
+}
+
+func (r *tarsReader) readString(typ byte) (string, error) {
+	var length int
+	switch typ {
+	case typeString1:
+		if r.offset >= len(r.data) {
+			return "", errTruncated
+		}
+		length = int(r.data[r.offset])
+		r.offset++
+	case typeString4:
+		if r.offset+4 > len(r.data) {
+			return "", errTruncated
+		}
+		length = int(int32(binary.BigEndian.Uint32(r.data[r.offset:])))
+		r.offset += 4
+	default:
+		return "", errInvalid
+	}
+	start := r.offset
+	if err := r.skip(length); err != nil {
+		return "", err
+	}
+	return string(r.data[start:r.offset]), nil
+}
+
+// readStringMap reads a map<string, string>, e.g. the status and the context of the packets.
+func (r *tarsReader) readStringMap(typ byte) (map[string]string, error) {
+	if typ != typeMap {
+		return nil, errInvalid
+	}
+	length, err := r.readLength()
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]string, length)
+	for i := 0; i < length; i++ {
+		var key, value string
+		for j := 0; j < 2; j++ {
+			_, typ, err = r.readHead()
+			if err != nil {
+				return nil, err
+			}
+			if j == 0 {
+				key, err = r.readString(typ)
+			} else {
+				value, err = r.readString(typ)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		values[key] = value
+	}
+	return values, nil
+}
+
+func (r *tarsReader) skipField(typ byte, depth int) error {
+	if depth > maxDepth {
+		return errInvalid
+	}
+	switch typ {
+	case typeZero:
+		return nil
+	case typeInt8:
+		return r.skip(1)
+	case typeInt16:
+		return r.skip(2)
+	case typeInt32, typeFloat:
+		return r.skip(4)
+	case typeInt64, typeDouble:
+		return r.skip(8)
+	case typeString1, typeString4:
+		_, err := r.readString(typ)
+		return err
+	case typeMap, typeList:
+		length, err := r.readLength()
+		if err != nil {
+			return err
+		}
+		if typ == typeMap {
+			length *= 2
+		}
+		for i := 0; i < length; i++ {
+			if err = r.skipNextField(depth + 1); err != nil {
+				return err
+			}
+		}
+		return nil
+	case typeSimpleList:
+		// The head of the element type, which is always int8
+		if _, _, err := r.readHead(); err != nil {
+			return err
+		}
+		length, err := r.readLength()
+		if err != nil {
+			return err
+		}
+		return r.skip(length)
+	case typeStructBegin:
+		for {
+			_, typ, err := r.readHead()
+			if err != nil {
+				return err
+			}
+			if typ == typeStructEnd {
+				return nil
+			}
+			if err = r.skipField(typ, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+	return errInvalid
+}
+
+func (r *tarsReader) skipNextField(depth int) error {
+	_, typ, err := r.readHead()
+	if err != nil {
+		return err
+	}
+	return r.skipField(typ, depth)
+}
@@ -0,0 +1,149 @@
+package tars
+
+import (
+	"encoding/binary"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+)
+
+// The versions of the packets. TUP and JSON packets use the layout of the requests for the responses too.
+const (
+	versionTars = 1
+	versionTup  = 2
+	versionJson = 3
+)
+
+// The packet type of the one-way requests, which have no responses.
+const packetTypeOneway = 1
+
+const (
+	// The packet starts with its length of 4 bytes, which includes the length itself.
+	headerLength = 4
+	// maxPacketSize is the default limit of the packets in the Tars frameworks.
+	maxPacketSize = 10000000
+)
+
+// The keys of the status in the TUP responses.
+const (
+	statusResultCode = "STATUS_RESULT_CODE"
+	statusResultDesc = "STATUS_RESULT_DESC"
+)
+
+type packet struct {
+	version     int64
+	packetType  int64
+	requestId   int64
+	servantName string
+	funcName    string
+	ret         int64
+	resultDesc  string
+	status      map[string]string
+}
+
+// The tags of the fields in RequestPacket.
+const (
+	requestTagVersion     = 1
+	requestTagPacketType  = 2
+	requestTagMessageType = 3
+	requestTagRequestId   = 4
+	requestTagServantName = 5
+	requestTagFuncName    = 6
+	requestTagStatus      = 10
+)
+
+// The tags of the fields in ResponsePacket.
+const (
+	responseTagRequestId  = 3
+	responseTagRet        = 5
+	responseTagResultDesc = 8
+)
+
+// isTarsPacket checks the length and the version, which is the first field of all the packets.
+func isTarsPacket(data []byte) bool {
+	if len(data) < headerLength+2 {
+		return false
+	}
+	length := binary.BigEndian.Uint32(data)
+	if length <= headerLength+2 || length > maxPacketSize {
+		return false
+	}
+	// The version is encoded as int8 with the tag 1.
+	return data[headerLength] == requestTagVersion<<4|typeInt8 &&
+		data[headerLength+1] >= versionTars && data[headerLength+1] <= versionJson
+}
+
+// readRequestPacket reads the fields of RequestPacket until sBuffer, which holds the arguments and may
+// be truncated. The status after it is read if the payload is complete, which holds the result of TUP.
+func readRequestPacket(data []byte) (*packet, bool) {
+	reader := &tarsReader{data: data, offset: headerLength}
+	p := &packet{}
+	found := 0
+	for {
+		tag, typ, err := reader.readHead()
+		if err != nil {
+			break
+		}
+		switch tag {
+		case requestTagVersion:
+			p.version, err = reader.readInt(typ)
+		case requestTagPacketType:
+			p.packetType, err = reader.readInt(typ)
+		case requestTagMessageType:
+			_, err = reader.readInt(typ)
+		case requestTagRequestId:
+			p.requestId, err = reader.readInt(typ)
+		case requestTagServantName:
+			p.servantName, err = reader.readString(typ)
+		case requestTagFuncName:
+			p.funcName, err = reader.readString(typ)
+		case requestTagStatus:
+			p.status, err = reader.readStringMap(typ)
+		default:
+			err = reader.skipField(typ, 0)
+		}
+		if err != nil {
+			break
+		}
+		if tag <= requestTagFuncName {
+			found++
+		}
+	}
+	return p, found == requestTagFuncName && p.servantName != "" && p.funcName != ""
+}
+
+// readResponsePacket reads the fields of ResponsePacket until iRet. The description of the result
+// after sBuffer is read if the payload is complete.
+func readResponsePacket(data []byte) (*packet, bool) {
+	reader := &tarsReader{data: data, offset: headerLength}
+	p := &packet{version: versionTars}
+	found := 0
+	for {
+		tag, typ, err := reader.readHead()
+		if err != nil {
+			break
+		}
+		switch tag {
+		case responseTagRequestId:
+			p.requestId, err = reader.readInt(typ)
+		case responseTagRet:
+			p.ret, err = reader.readInt(typ)
+		case responseTagResultDesc:
+			p.resultDesc, err = reader.readString(typ)
+		default:
+			err = reader.skipField(typ, 0)
+		}
+		if err != nil {
+			break
+		}
+		if tag <= responseTagRet {
+			found++
+		}
+	}
+	return p, found == responseTagRet
+}
+
+func NewTarsParser() *protocol.ProtocolParser {
+	requestParser := protocol.CreatePkgParser(fastfailTarsRequest(), parseTarsRequest())
+	responseParser := protocol.CreatePkgParser(fastfailTarsResponse(), parseTarsResponse())
+	return protocol.NewProtocolParser(protocol.TARS, requestParser, responseParser, nil)
+}
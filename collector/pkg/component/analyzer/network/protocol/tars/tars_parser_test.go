@@ -0,0 +1,213 @@
+package tars
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+type tarsEncoder struct {
+	data []byte
+}
+
+func (e *tarsEncoder) head(tag int, typ byte) *tarsEncoder {
+	if tag < 15 {
+		e.data = append(e.data, byte(tag<<4)|typ)
+	} else {
+		e.data = append(e.data, 0xf0|typ, byte(tag))
+	}
+	return e
+}
+
+func (e *tarsEncoder) int(tag int, value int64) *tarsEncoder {
+	switch {
+	case value == 0:
+		e.head(tag, typeZero)
+	case value >= -128 && value <= 127:
+		e.head(tag, typeInt8)
+		e.data = append(e.data, byte(value))
+	case value >= -32768 && value <= 32767:
+		e.head(tag, typeInt16)
+		e.data = binary.BigEndian.AppendUint16(e.data, uint16(value))
+	default:
+		e.head(tag, typeInt32)
+		e.data = binary.BigEndian.AppendUint32(e.data, uint32(value))
+	}
+	return e
+}
+
+func (e *tarsEncoder) string(tag int, value string) *tarsEncoder {
+	if len(value) < 256 {
+		e.head(tag, typeString1)
+		e.data = append(e.data, byte(len(value)))
+	} else {
+		e.head(tag, typeString4)
+		e.data = binary.BigEndian.AppendUint32(e.data, uint32(len(value)))
+	}
+	e.data = append(e.data, value...)
+	return e
+}
+
+func (e *tarsEncoder) bytes(tag int, value []byte) *tarsEncoder {
+	e.head(tag, typeSimpleList).head(0, typeInt8).int(0, int64(len(value)))
+	e.data = append(e.data, value...)
+	return e
+}
+
+func (e *tarsEncoder) stringMap(tag int, values map[string]string) *tarsEncoder {
+	e.head(tag, typeMap).int(0, int64(len(values)))
+	for key, value := range values {
+		e.string(0, key).string(1, value)
+	}
+	return e
+}
+
+func (e *tarsEncoder) packet() []byte {
+	return append(binary.BigEndian.AppendUint32(nil, uint32(len(e.data)+headerLength)), e.data...)
+}
+
+func newRequest(version int64, requestId int64, servant string, function string) *tarsEncoder {
+	e := &tarsEncoder{}
+	return e.int(1, version).int(2, 0).int(3, 0).int(4, requestId).string(5, servant).string(6, function).
+		bytes(7, []byte("arguments")).int(8, 3000).stringMap(9, nil).stringMap(10, nil)
+}
+
+func newResponse(requestId int64, ret int64, resultDesc string) *tarsEncoder {
+	e := &tarsEncoder{}
+	return e.int(1, versionTars).int(2, 0).int(3, requestId).int(4, 0).int(5, ret).
+		bytes(6, []byte("result")).stringMap(7, nil).string(8, resultDesc)
+}
+
+func newTupResponse(requestId int64, servant string, function string, status map[string]string) *tarsEncoder {
+	e := &tarsEncoder{}
+	return e.int(1, versionTup).int(2, 0).int(3, 0).int(4, requestId).string(5, servant).string(6, function).
+		bytes(7, []byte("result")).int(8, 0).stringMap(9, nil).stringMap(10, status)
+}
+
+func TestParseTars(t *testing.T) {
+	tests := []struct {
+		name       string
+		request    []byte
+		response   []byte
+		contentKey string
+		ret        int64
+		resultDesc string
+	}{
+		{
+			name:       "tars",
+			request:    newRequest(versionTars, 1, "TestApp.HelloServer.HelloObj", "sayHello").packet(),
+			response:   newResponse(1, 0, "").packet(),
+			contentKey: "TestApp.HelloServer.HelloObj#sayHello",
+		},
+		{
+			name:       "tars error",
+			request:    newRequest(versionTars, 70000, "TestApp.HelloServer.HelloObj", "sayHello").packet(),
+			response:   newResponse(70000, -3, "no function").packet(),
+			contentKey: "TestApp.HelloServer.HelloObj#sayHello",
+			ret:        -3,
+			resultDesc: "no function",
+		},
+		{
+			name:       "tup",
+			request:    newRequest(versionTup, 2, "TestApp.HelloServer.HelloObj", "sayHello").packet(),
+			response:   newTupResponse(2, "TestApp.HelloServer.HelloObj", "sayHello", map[string]string{statusResultCode: "0"}).packet(),
+			contentKey: "TestApp.HelloServer.HelloObj#sayHello",
+		},
+		{
+			name:    "tup error",
+			request: newRequest(versionTup, 3, "TestApp.HelloServer.HelloObj", "sayHello").packet(),
+			response: newTupResponse(3, "TestApp.HelloServer.HelloObj", "sayHello",
+				map[string]string{statusResultCode: "-1", statusResultDesc: "decode error"}).packet(),
+			contentKey: "TestApp.HelloServer.HelloObj#sayHello",
+			ret:        -1,
+			resultDesc: "decode error",
+		},
+	}
+	parser := NewTarsParser()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := protocol.NewRequestMessage(tt.request)
+			assert.True(t, parser.ParseRequest(request))
+			attributes := request.GetAttributes()
+			assert.Equal(t, "TestApp.HelloServer.HelloObj", attributes.GetStringValue(constlabels.TarsServantName))
+			assert.Equal(t, "sayHello", attributes.GetStringValue(constlabels.TarsFuncName))
+			assert.Equal(t, tt.contentKey, attributes.GetStringValue(constlabels.ContentKey))
+
+			response := protocol.NewResponseMessage(tt.response, attributes)
+			assert.True(t, parser.ParseResponse(response))
+			attributes = response.GetAttributes()
+			assert.Equal(t, tt.ret != 0, attributes.GetBoolValue(constlabels.IsError))
+			assert.Equal(t, tt.ret, attributes.GetIntValue(constlabels.TarsRetCode))
+			assert.Equal(t, tt.resultDesc, attributes.GetStringValue(constlabels.TarsResultDesc))
+		})
+	}
+}
+
+func TestParseTruncated(t *testing.T) {
+	parser := NewTarsParser()
+	data := newRequest(versionTars, 1, "TestApp.HelloServer.HelloObj", "sayHello").packet()
+	// The arguments are truncated.
+	request := protocol.NewRequestMessage(data[:len(data)-20])
+	assert.True(t, parser.ParseRequest(request))
+	assert.Equal(t, "sayHello", request.GetStringAttribute(constlabels.TarsFuncName))
+	// The function name is truncated.
+	assert.False(t, parser.ParseRequest(protocol.NewRequestMessage(data[:40])))
+
+	data = newResponse(1, -7, "timeout").packet()
+	response := protocol.NewResponseMessage(data[:len(data)-10], request.GetAttributes())
+	assert.True(t, parser.ParseResponse(response))
+	assert.True(t, response.GetBoolAttribute(constlabels.IsError))
+	assert.Equal(t, int64(-7), response.GetIntAttribute(constlabels.TarsRetCode))
+}
+
+func TestParseMismatchedOrInvalid(t *testing.T) {
+	parser := NewTarsParser()
+	request := protocol.NewRequestMessage(newRequest(versionTars, 1, "TestApp.HelloServer.HelloObj", "sayHello").packet())
+	assert.True(t, parser.ParseRequest(request))
+	// The response to another request
+	assert.False(t, parser.ParseResponse(protocol.NewResponseMessage(newResponse(2, 0, "").packet(), request.GetAttributes())))
+
+	// One-way requests
+	oneway := (&tarsEncoder{}).int(1, versionTars).int(2, packetTypeOneway).int(3, 0).int(4, 1).
+		string(5, "TestApp.HelloServer.HelloObj").string(6, "sayHello").packet()
+	assert.False(t, parser.ParseRequest(protocol.NewRequestMessage(oneway)))
+	// Unknown version
+	invalid := newRequest(versionTars, 1, "TestApp.HelloServer.HelloObj", "sayHello").packet()
+	invalid[headerLength+1] = 4
+	assert.False(t, parser.ParseRequest(protocol.NewRequestMessage(invalid)))
+	// HTTP
+	assert.False(t, parser.ParseRequest(protocol.NewRequestMessage([]byte("GET /index.html HTTP/1.1\r\nHost: localhost\r\n\r\n"))))
+}
+
+func TestSkipField(t *testing.T) {
+	e := &tarsEncoder{}
+	// A struct holding a list of a double and a nested struct, and a tag larger than 14
+	e.head(1, typeStructBegin).head(0, typeList).int(0, 2).head(0, typeDouble)
+	e.data = append(e.data, make([]byte, 8)...)
+	e.head(0, typeStructBegin).string(20, "nested").head(0, typeStructEnd)
+	e.head(0, typeStructEnd).int(2, 5)
+
+	reader := &tarsReader{data: e.data}
+	_, typ, err := reader.readHead()
+	assert.NoError(t, err)
+	assert.NoError(t, reader.skipField(typ, 0))
+	tag, typ, err := reader.readHead()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, tag)
+	value, err := reader.readInt(typ)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), value)
+
+	// Too deep
+	e = &tarsEncoder{}
+	for i := 0; i < maxDepth+2; i++ {
+		e.head(0, typeStructBegin)
+	}
+	reader = &tarsReader{data: e.data}
+	_, typ, _ = reader.readHead()
+	assert.ErrorIs(t, reader.skipField(typ, 0), errInvalid)
+}
@@ -0,0 +1,31 @@
+package tars
+
+import (
+	"strconv"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+func fastfailTarsRequest() protocol.FastFailFn {
+	return func(message *protocol.PayloadMessage) bool {
+		return !isTarsPacket(message.Data)
+	}
+}
+
+func parseTarsRequest() protocol.ParsePkgFn {
+	return func(message *protocol.PayloadMessage) (bool, bool) {
+		request, ok := readRequestPacket(message.Data)
+		// The one-way requests are not paired with responses.
+		if !ok || request.packetType == packetTypeOneway {
+			return false, true
+		}
+
+		message.AddIntAttribute(constlabels.TarsRequestId, request.requestId)
+		message.AddStringAttribute(constlabels.ProtocolVersion, strconv.FormatInt(request.version, 10))
+		message.AddUtf8StringAttribute(constlabels.TarsServantName, request.servantName)
+		message.AddUtf8StringAttribute(constlabels.TarsFuncName, request.funcName)
+		message.AddUtf8StringAttribute(constlabels.ContentKey, request.servantName+"#"+request.funcName)
+		return true, true
+	}
+}
@@ -0,0 +1,50 @@
+package tars
+
+import (
+	"strconv"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+func fastfailTarsResponse() protocol.FastFailFn {
+	return func(message *protocol.PayloadMessage) bool {
+		return !isTarsPacket(message.Data)
+	}
+}
+
+func parseTarsResponse() protocol.ParsePkgFn {
+	return func(message *protocol.PayloadMessage) (bool, bool) {
+		if !message.HasAttribute(constlabels.TarsRequestId) {
+			return false, true
+		}
+
+		var (
+			response *packet
+			ok       bool
+		)
+		if message.Data[headerLength+1] == versionTars {
+			response, ok = readResponsePacket(message.Data)
+		} else {
+			// The result of TUP is in the status, which is missing if the payload is truncated.
+			response, ok = readRequestPacket(message.Data)
+			if ok && response.status != nil {
+				response.ret, _ = strconv.ParseInt(response.status[statusResultCode], 10, 64)
+				response.resultDesc = response.status[statusResultDesc]
+			}
+		}
+		if !ok || response.requestId != message.GetIntAttribute(constlabels.TarsRequestId) {
+			return false, true
+		}
+
+		message.AddIntAttribute(constlabels.TarsRetCode, response.ret)
+		if response.ret != 0 {
+			if response.resultDesc != "" {
+				message.AddUtf8StringAttribute(constlabels.TarsResultDesc, response.resultDesc)
+			}
+			message.AddBoolAttribute(constlabels.IsError, true)
+			message.AddIntAttribute(constlabels.ErrorType, int64(constlabels.ProtocolError))
+		}
+		return true, true
+	}
+}
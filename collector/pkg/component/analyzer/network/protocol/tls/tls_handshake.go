@@ -0,0 +1,214 @@
+package tls
+
+import (
+	"encoding/binary"
+	"strings"
+)
+
+const (
+	handshakeClientHello = 1
+	handshakeServerHello = 2
+
+	extensionServerName        = 0
+	extensionAlpn              = 16
+	extensionSupportedVersions = 43
+)
+
+// ClientHello is the metadata of the ClientHello, which is sent in plain text by all the versions.
+type ClientHello struct {
+	ServerName string
+	// Alpn is the protocols offered by the client, e.g. "h2" and "http/1.1".
+	Alpn []string
+	// Complete is true if all the extensions are read.
+	Complete bool
+}
+
+// ServerHello is the parameters chosen by the server.
+type ServerHello struct {
+	// Version is the negotiated version, which is taken from the supported_versions extension since
+	// TLS 1.3, as the legacy_version is always TLS 1.2.
+	Version     uint16
+	CipherSuite uint16
+}
+
+// ParseClientHello parses the handshake message, which returns false if it is not a ClientHello. The
+// extensions beyond the data are missing in the result.
+func ParseClientHello(data []byte) (*ClientHello, bool) {
+	body, ok := handshakeBody(data, handshakeClientHello)
+	if !ok {
+		return nil, false
+	}
+	reader := &handshakeReader{data: body}
+	// legacy_version and random
+	reader.skip(2 + 32)
+	// legacy_session_id, cipher_suites and legacy_compression_methods
+	reader.skipVector(1)
+	reader.skipVector(2)
+	reader.skipVector(1)
+	hello := &ClientHello{}
+	hello.Complete = reader.extensions(func(extensionType uint16, extension []byte) {
+		switch extensionType {
+		case extensionServerName:
+			hello.ServerName = parseServerName(extension)
+		case extensionAlpn:
+			hello.Alpn = parseAlpn(extension)
+		}
+	})
+	return hello, true
+}
+
+// ParseServerHello parses the handshake message, which returns false if it is not a ServerHello.
+func ParseServerHello(data []byte) (*ServerHello, bool) {
+	body, ok := handshakeBody(data, handshakeServerHello)
+	if !ok {
+		return nil, false
+	}
+	reader := &handshakeReader{data: body}
+	legacyVersion, ok := reader.uint16()
+	if !ok || legacyVersion < VersionSsl3 || legacyVersion > VersionTls12 {
+		return nil, false
+	}
+	// random and legacy_session_id_echo
+	reader.skip(32)
+	reader.skipVector(1)
+	cipherSuite, ok := reader.uint16()
+	if !ok {
+		return nil, false
+	}
+	hello := &ServerHello{Version: legacyVersion, CipherSuite: cipherSuite}
+	// legacy_compression_method
+	reader.skip(1)
+	reader.extensions(func(extensionType uint16, extension []byte) {
+		if extensionType == extensionSupportedVersions && len(extension) == 2 {
+			hello.Version = binary.BigEndian.Uint16(extension)
+		}
+	})
+	return hello, true
+}
+
+// handshakeBody returns the body of the handshake message of the type, which is cut to its length.
+func handshakeBody(data []byte, messageType byte) ([]byte, bool) {
+	if len(data) < 4 || data[0] != messageType {
+		return nil, false
+	}
+	length := int(data[1])<<16 | int(data[2])<<8 | int(data[3])
+	body := data[4:]
+	if len(body) > length {
+		body = body[:length]
+	}
+	return body, true
+}
+
+// parseServerName returns the host name of the server_name extension, see RFC 6066 section 3.
+func parseServerName(extension []byte) string {
+	reader := &handshakeReader{data: extension}
+	list, ok := reader.vector(2)
+	if !ok {
+		return ""
+	}
+	reader = &handshakeReader{data: list}
+	for reader.offset < len(list) {
+		nameType := list[reader.offset]
+		reader.skip(1)
+		name, ok := reader.vector(2)
+		if !ok {
+			return ""
+		}
+		if nameType == 0 {
+			return string(name)
+		}
+	}
+	return ""
+}
+
+// parseAlpn returns the protocol names of the application_layer_protocol_negotiation extension.
+func parseAlpn(extension []byte) []string {
+	reader := &handshakeReader{data: extension}
+	list, ok := reader.vector(2)
+	if !ok {
+		return nil
+	}
+	reader = &handshakeReader{data: list}
+	protocols := make([]string, 0)
+	for reader.offset < len(list) {
+		name, ok := reader.vector(1)
+		if !ok {
+			break
+		}
+		protocols = append(protocols, string(name))
+	}
+	return protocols
+}
+
+// HasAlpn returns true if the client offers the protocol or its drafts, e.g. "h3-29" for "h3".
+func (hello *ClientHello) HasAlpn(protocol string) bool {
+	for _, alpn := range hello.Alpn {
+		if alpn == protocol || strings.HasPrefix(alpn, protocol+"-") {
+			return true
+		}
+	}
+	return false
+}
+
+type handshakeReader struct {
+	data   []byte
+	offset int
+}
+
+func (r *handshakeReader) skip(length int) {
+	r.offset += length
+}
+
+func (r *handshakeReader) uint16() (uint16, bool) {
+	if r.offset+2 > len(r.data) {
+		return 0, false
+	}
+	value := binary.BigEndian.Uint16(r.data[r.offset:])
+	r.offset += 2
+	return value, true
+}
+
+// vector reads the data prefixed by its length of lengthSize bytes.
+func (r *handshakeReader) vector(lengthSize int) ([]byte, bool) {
+	if r.offset < 0 || r.offset+lengthSize > len(r.data) {
+		return nil, false
+	}
+	length := 0
+	for i := 0; i < lengthSize; i++ {
+		length = length<<8 | int(r.data[r.offset+i])
+	}
+	r.offset += lengthSize
+	if r.offset+length > len(r.data) {
+		return nil, false
+	}
+	value := r.data[r.offset : r.offset+length]
+	r.offset += length
+	return value, true
+}
+
+func (r *handshakeReader) skipVector(lengthSize int) {
+	if _, ok := r.vector(lengthSize); !ok {
+		r.offset = len(r.data)
+	}
+}
+
+// extensions calls onExtension with each extension, and returns true if all of them are read.
+func (r *handshakeReader) extensions(onExtension func(extensionType uint16, extension []byte)) bool {
+	extensionsLength, ok := r.uint16()
+	if !ok {
+		return false
+	}
+	extensionsEnd := r.offset + int(extensionsLength)
+	for r.offset < extensionsEnd {
+		extensionType, ok := r.uint16()
+		if !ok {
+			return false
+		}
+		extension, ok := r.vector(2)
+		if !ok {
+			return false
+		}
+		onExtension(extensionType, extension)
+	}
+	return extensionsEnd <= len(r.data)
+}
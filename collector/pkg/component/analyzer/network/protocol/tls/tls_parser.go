@@ -0,0 +1,91 @@
+package tls
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+)
+
+const (
+	VersionSsl3  = 0x0300
+	VersionTls10 = 0x0301
+	VersionTls11 = 0x0302
+	VersionTls12 = 0x0303
+	VersionTls13 = 0x0304
+)
+
+// The content types of the records, see RFC 8446 section 5.1.
+const (
+	recordChangeCipherSpec = 20
+	recordAlert            = 21
+	recordHandshake        = 22
+	recordApplicationData  = 23
+
+	recordHeaderLength = 5
+	// maxRecordLength is the max length of the encrypted fragment, which is 2^14 + 2048.
+	maxRecordLength = 16384 + 2048
+)
+
+const alertLevelFatal = 2
+
+// VersionString returns the version like "1.3", or the hex code of the unknown versions.
+func VersionString(version uint16) string {
+	switch version {
+	case VersionSsl3:
+		return "ssl3.0"
+	case VersionTls10:
+		return "1.0"
+	case VersionTls11:
+		return "1.1"
+	case VersionTls12:
+		return "1.2"
+	case VersionTls13:
+		return "1.3"
+	}
+	return fmt.Sprintf("0x%04x", version)
+}
+
+// readRecord returns the content type and the fragment of the first record, whose fragment is cut if
+// the data is truncated, and the data after the record.
+func readRecord(data []byte) (byte, []byte, []byte, bool) {
+	if len(data) < recordHeaderLength {
+		return 0, nil, nil, false
+	}
+	contentType := data[0]
+	version := binary.BigEndian.Uint16(data[1:])
+	length := int(binary.BigEndian.Uint16(data[3:]))
+	if contentType < recordChangeCipherSpec || contentType > recordApplicationData ||
+		version < VersionSsl3 || version > VersionTls12 || length == 0 || length > maxRecordLength {
+		return 0, nil, nil, false
+	}
+	end := recordHeaderLength + length
+	if end > len(data) {
+		return contentType, data[recordHeaderLength:], nil, true
+	}
+	return contentType, data[recordHeaderLength:end], data[end:], true
+}
+
+// readHandshake returns the handshake messages of the leading handshake records. A message longer
+// than a record is fragmented across the following records.
+func readHandshake(data []byte) ([]byte, bool) {
+	contentType, fragment, rest, ok := readRecord(data)
+	if !ok || contentType != recordHandshake {
+		return nil, false
+	}
+	handshake := fragment
+	for len(rest) > 0 {
+		contentType, fragment, rest, ok = readRecord(rest)
+		if !ok || contentType != recordHandshake {
+			break
+		}
+		handshake = append(handshake[:len(handshake):len(handshake)], fragment...)
+	}
+	return handshake, true
+}
+
+func NewTlsParser() *protocol.ProtocolParser {
+	requestParser := protocol.CreatePkgParser(fastfailTlsRequest(), parseTlsRequest())
+	responseParser := protocol.CreatePkgParser(fastfailTlsResponse(), parseTlsResponse())
+	return protocol.NewProtocolParser(protocol.TLS, requestParser, responseParser, nil)
+}
@@ -0,0 +1,137 @@
+package tls
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+func appendVector(data []byte, lengthSize int, value []byte) []byte {
+	for i := lengthSize - 1; i >= 0; i-- {
+		data = append(data, byte(len(value)>>(8*i)))
+	}
+	return append(data, value...)
+}
+
+func appendExtension(data []byte, extensionType uint16, extension []byte) []byte {
+	data = append(data, byte(extensionType>>8), byte(extensionType))
+	return appendVector(data, 2, extension)
+}
+
+func newRecord(contentType byte, fragment []byte) []byte {
+	return appendVector([]byte{contentType, 0x03, 0x01}, 2, fragment)
+}
+
+func newClientHello(serverName string, alpn ...string) []byte {
+	body := []byte{0x03, 0x03}
+	body = append(body, make([]byte, 32)...)
+	body = appendVector(body, 1, nil)
+	body = appendVector(body, 2, []byte{0x13, 0x01, 0xc0, 0x2f})
+	body = appendVector(body, 1, []byte{0})
+
+	extensions := make([]byte, 0)
+	if serverName != "" {
+		names := appendVector([]byte{0}, 2, []byte(serverName))
+		extensions = appendExtension(extensions, extensionServerName, appendVector(nil, 2, names))
+	}
+	if len(alpn) > 0 {
+		var protocols []byte
+		for _, protocol := range alpn {
+			protocols = appendVector(protocols, 1, []byte(protocol))
+		}
+		extensions = appendExtension(extensions, extensionAlpn, appendVector(nil, 2, protocols))
+	}
+	body = appendVector(body, 2, extensions)
+	return append([]byte{handshakeClientHello}, appendVector(nil, 3, body)...)
+}
+
+func newServerHello(cipherSuite uint16, supportedVersion uint16) []byte {
+	body := []byte{0x03, 0x03}
+	body = append(body, make([]byte, 32)...)
+	body = appendVector(body, 1, make([]byte, 32))
+	body = append(body, byte(cipherSuite>>8), byte(cipherSuite), 0)
+	extensions := make([]byte, 0)
+	if supportedVersion != 0 {
+		extensions = appendExtension(extensions, extensionSupportedVersions,
+			[]byte{byte(supportedVersion >> 8), byte(supportedVersion)})
+	}
+	body = appendVector(body, 2, extensions)
+	return append([]byte{handshakeServerHello}, appendVector(nil, 3, body)...)
+}
+
+func TestParseTlsRequest(t *testing.T) {
+	parser := NewTlsParser()
+
+	request := protocol.NewRequestMessage(newRecord(recordHandshake, newClientHello("api.example.com", "h2", "http/1.1")))
+	assert.True(t, parser.ParseRequest(request))
+	assert.Equal(t, "api.example.com", request.GetStringAttribute(constlabels.TlsSni))
+	assert.Equal(t, "h2,http/1.1", request.GetStringAttribute(constlabels.TlsAlpn))
+	assert.Equal(t, "api.example.com", request.GetStringAttribute(constlabels.ContentKey))
+
+	// The ClientHello is fragmented across two records.
+	hello := newClientHello("www.example.com")
+	data := append(newRecord(recordHandshake, hello[:20]), newRecord(recordHandshake, hello[20:])...)
+	request = protocol.NewRequestMessage(data)
+	assert.True(t, parser.ParseRequest(request))
+	assert.Equal(t, "www.example.com", request.GetStringAttribute(constlabels.TlsSni))
+
+	// No server name is sent to the IP addresses.
+	request = protocol.NewRequestMessage(newRecord(recordHandshake, newClientHello("")))
+	assert.True(t, parser.ParseRequest(request))
+	assert.False(t, request.HasAttribute(constlabels.TlsSni))
+	assert.Equal(t, "*", request.GetStringAttribute(constlabels.ContentKey))
+
+	for _, data := range [][]byte{
+		newRecord(recordApplicationData, []byte{0x8f, 0x3a, 0x11, 0x00}),
+		newRecord(recordHandshake, newServerHello(0x1301, VersionTls13)),
+		[]byte("GET / HTTP/1.1\r\n\r\n"),
+		{recordHandshake, 0x02, 0x00, 0x00, 0x10, handshakeClientHello},
+	} {
+		assert.False(t, parser.ParseRequest(protocol.NewRequestMessage(data)))
+	}
+}
+
+func TestParseTlsResponse(t *testing.T) {
+	parser := NewTlsParser()
+
+	// TLS 1.3 is negotiated with the supported_versions extension, and the encrypted records follow.
+	data := newRecord(recordHandshake, newServerHello(0x1301, VersionTls13))
+	data = append(data, newRecord(recordChangeCipherSpec, []byte{1})...)
+	data = append(data, newRecord(recordApplicationData, make([]byte, 32))...)
+	response := protocol.NewResponseMessage(data, protocol.NewRequestMessage(nil).GetAttributes())
+	assert.True(t, parser.ParseResponse(response))
+	assert.Equal(t, "1.3", response.GetStringAttribute(constlabels.ProtocolVersion))
+	assert.Equal(t, "TLS_AES_128_GCM_SHA256", response.GetStringAttribute(constlabels.TlsCipherSuite))
+	assert.False(t, response.HasAttribute(constlabels.IsError))
+
+	response = protocol.NewResponseMessage(newRecord(recordHandshake, newServerHello(0xc02f, 0)),
+		protocol.NewRequestMessage(nil).GetAttributes())
+	assert.True(t, parser.ParseResponse(response))
+	assert.Equal(t, "1.2", response.GetStringAttribute(constlabels.ProtocolVersion))
+	assert.Equal(t, "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256", response.GetStringAttribute(constlabels.TlsCipherSuite))
+
+	// handshake_failure
+	response = protocol.NewResponseMessage(newRecord(recordAlert, []byte{alertLevelFatal, 40}),
+		protocol.NewRequestMessage(nil).GetAttributes())
+	assert.True(t, parser.ParseResponse(response))
+	assert.Equal(t, int64(40), response.GetIntAttribute(constlabels.TlsAlert))
+	assert.True(t, response.GetBoolAttribute(constlabels.IsError))
+	assert.Equal(t, int64(constlabels.ProtocolError), response.GetIntAttribute(constlabels.ErrorType))
+
+	for _, data := range [][]byte{
+		newRecord(recordApplicationData, make([]byte, 32)),
+		newRecord(recordHandshake, newClientHello("www.example.com")),
+		{0x48, 0x54, 0x54, 0x50, 0x2f, 0x31},
+	} {
+		assert.False(t, parser.ParseResponse(protocol.NewResponseMessage(data, protocol.NewRequestMessage(nil).GetAttributes())))
+	}
+}
+
+func TestVersionString(t *testing.T) {
+	assert.Equal(t, "ssl3.0", VersionString(VersionSsl3))
+	assert.Equal(t, "1.2", VersionString(VersionTls12))
+	assert.Equal(t, "0x7f1c", VersionString(0x7f1c))
+}
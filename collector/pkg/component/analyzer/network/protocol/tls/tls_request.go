@@ -0,0 +1,39 @@
+package tls
+
+import (
+	"strings"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+func fastfailTlsRequest() protocol.FastFailFn {
+	return func(message *protocol.PayloadMessage) bool {
+		return len(message.Data) < recordHeaderLength || message.Data[0] != recordHandshake
+	}
+}
+
+// parseTlsRequest reads the ClientHello of the handshake. The records after the handshake are encrypted
+// and not recognized, so the server name is the content key of the connection.
+func parseTlsRequest() protocol.ParsePkgFn {
+	return func(message *protocol.PayloadMessage) (bool, bool) {
+		handshake, ok := readHandshake(message.Data)
+		if !ok {
+			return false, true
+		}
+		hello, ok := ParseClientHello(handshake)
+		if !ok {
+			return false, true
+		}
+		contentKey := "*"
+		if hello.ServerName != "" {
+			message.AddUtf8StringAttribute(constlabels.TlsSni, hello.ServerName)
+			contentKey = hello.ServerName
+		}
+		if len(hello.Alpn) > 0 {
+			message.AddUtf8StringAttribute(constlabels.TlsAlpn, strings.Join(hello.Alpn, ","))
+		}
+		message.AddUtf8StringAttribute(constlabels.ContentKey, contentKey)
+		return true, true
+	}
+}
@@ -0,0 +1,48 @@
+package tls
+
+import (
+	cryptotls "crypto/tls"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+func fastfailTlsResponse() protocol.FastFailFn {
+	return func(message *protocol.PayloadMessage) bool {
+		return len(message.Data) < recordHeaderLength
+	}
+}
+
+// parseTlsResponse reads the ServerHello, or the alert sent instead when the handshake fails, e.g.
+// handshake_failure(40) for no common cipher suites. The fatal alerts are errors.
+func parseTlsResponse() protocol.ParsePkgFn {
+	return func(message *protocol.PayloadMessage) (bool, bool) {
+		contentType, fragment, _, ok := readRecord(message.Data)
+		if !ok {
+			return false, true
+		}
+		switch contentType {
+		case recordAlert:
+			// AlertLevel level and AlertDescription description
+			if len(fragment) < 2 {
+				return false, true
+			}
+			message.AddIntAttribute(constlabels.TlsAlert, int64(fragment[1]))
+			if fragment[0] == alertLevelFatal {
+				message.AddBoolAttribute(constlabels.IsError, true)
+				message.AddIntAttribute(constlabels.ErrorType, int64(constlabels.ProtocolError))
+			}
+			return true, true
+		case recordHandshake:
+			handshake, _ := readHandshake(message.Data)
+			hello, ok := ParseServerHello(handshake)
+			if !ok {
+				return false, true
+			}
+			message.AddStringAttribute(constlabels.ProtocolVersion, VersionString(hello.Version))
+			message.AddStringAttribute(constlabels.TlsCipherSuite, cryptotls.CipherSuiteName(hello.CipherSuite))
+			return true, true
+		}
+		return false, true
+	}
+}
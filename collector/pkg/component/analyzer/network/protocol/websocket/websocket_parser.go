@@ -0,0 +1,136 @@
+package websocket
+
+import (
+	"encoding/binary"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+)
+
+// The opcodes of the frames defined in RFC 6455.
+const (
+	opcodeContinuation = 0x0
+	opcodeText         = 0x1
+	opcodeBinary       = 0x2
+	opcodeClose        = 0x8
+	opcodePing         = 0x9
+	opcodePong         = 0xa
+)
+
+var opcodeNames = map[uint8]string{
+	opcodeContinuation: "continuation",
+	opcodeText:         "text",
+	opcodeBinary:       "binary",
+	opcodeClose:        "close",
+	opcodePing:         "ping",
+	opcodePong:         "pong",
+}
+
+const (
+	// The frames sent by the clients are masked, and the ones sent by the servers are not.
+	directionClientToServer = "client_to_server"
+	directionServerToClient = "server_to_client"
+
+	// maxControlPayloadLength is the max payload length of the control frames, i.e. close, ping and pong.
+	maxControlPayloadLength = 125
+	// The close codes of the normal closure and the endpoint going away, e.g. a browser leaving the page.
+	closeNormal    = 1000
+	closeGoingAway = 1001
+)
+
+type frame struct {
+	fin    bool
+	opcode uint8
+	masked bool
+	// payloadLength is the length declared in the header, and payload is the unmasked part captured.
+	payloadLength uint64
+	payload       []byte
+}
+
+// readFrame reads the header of the first frame in the data. RSV1 is allowed as it is set by the
+// extension permessage-deflate, and the frames with the other reserved bits are malformed.
+func readFrame(data []byte) (*frame, bool) {
+	if len(data) < 2 || data[0]&0x30 != 0 {
+		return nil, false
+	}
+	f := &frame{
+		fin:    data[0]&0x80 != 0,
+		opcode: data[0] & 0x0f,
+		masked: data[1]&0x80 != 0,
+	}
+	if _, ok := opcodeNames[f.opcode]; !ok {
+		return nil, false
+	}
+	offset := 2
+	switch length := data[1] & 0x7f; length {
+	case 126:
+		if len(data) < offset+2 {
+			return nil, false
+		}
+		f.payloadLength = uint64(binary.BigEndian.Uint16(data[offset:]))
+		offset += 2
+	case 127:
+		if len(data) < offset+8 {
+			return nil, false
+		}
+		f.payloadLength = binary.BigEndian.Uint64(data[offset:])
+		// The most significant bit must be 0.
+		if f.payloadLength>>63 != 0 {
+			return nil, false
+		}
+		offset += 8
+	default:
+		f.payloadLength = uint64(length)
+	}
+	if f.isControl() && (!f.fin || f.payloadLength > maxControlPayloadLength) {
+		return nil, false
+	}
+	var maskingKey []byte
+	if f.masked {
+		if len(data) < offset+4 {
+			return nil, false
+		}
+		maskingKey = data[offset : offset+4]
+		offset += 4
+	}
+	payload := data[offset:]
+	if uint64(len(payload)) > f.payloadLength {
+		payload = payload[:f.payloadLength]
+	}
+	if f.masked {
+		unmasked := make([]byte, len(payload))
+		for i := range payload {
+			unmasked[i] = payload[i] ^ maskingKey[i%4]
+		}
+		payload = unmasked
+	}
+	f.payload = payload
+	return f, true
+}
+
+func (f *frame) isControl() bool {
+	return f.opcode >= opcodeClose
+}
+
+func (f *frame) direction() string {
+	if f.masked {
+		return directionClientToServer
+	}
+	return directionServerToClient
+}
+
+// closeCode returns the status code of the close frame, which is optional.
+func (f *frame) closeCode() (uint16, bool) {
+	if f.opcode != opcodeClose || len(f.payload) < 2 {
+		return 0, false
+	}
+	return binary.BigEndian.Uint16(f.payload), true
+}
+
+// NewWebsocketParser creates the parser of the WebSocket frames. It is not used to classify the
+// connections, as the headers of the frames are too short to be recognized reliably. The connections
+// are parsed as WebSocket after they are upgraded by HTTP.
+func NewWebsocketParser() *protocol.ProtocolParser {
+	requestParser := protocol.CreatePkgParser(fastfailWebsocketRequest(), parseWebsocketRequest())
+	responseParser := protocol.CreatePkgParser(fastfailWebsocketResponse(), parseWebsocketResponse())
+	return protocol.NewProtocolParser(protocol.WEBSOCKET, requestParser, responseParser, nil)
+}
@@ -0,0 +1,115 @@
+package websocket
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+func decodeHex(t *testing.T, s string) []byte {
+	data, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestReadFrame(t *testing.T) {
+	tests := []struct {
+		name          string
+		data          string
+		wantOk        bool
+		wantOpcode    uint8
+		wantMasked    bool
+		wantLength    uint64
+		wantPayload   string
+		wantDirection string
+	}{
+		{
+			name:          "masked text",
+			data:          "818537fa213d7f9f4d5158",
+			wantOk:        true,
+			wantOpcode:    opcodeText,
+			wantMasked:    true,
+			wantLength:    5,
+			wantPayload:   "Hello",
+			wantDirection: directionClientToServer,
+		},
+		{
+			name:          "16-bit length",
+			data:          "827e0100" + "0102",
+			wantOk:        true,
+			wantOpcode:    opcodeBinary,
+			wantLength:    256,
+			wantPayload:   "\x01\x02",
+			wantDirection: directionServerToClient,
+		},
+		{
+			name:          "64-bit length",
+			data:          "027f0000000000010000",
+			wantOk:        true,
+			wantOpcode:    opcodeBinary,
+			wantLength:    65536,
+			wantDirection: directionServerToClient,
+		},
+		{
+			name:          "compressed",
+			data:          "c103f248cd",
+			wantOk:        true,
+			wantOpcode:    opcodeText,
+			wantLength:    3,
+			wantPayload:   "\xf2\x48\xcd",
+			wantDirection: directionServerToClient,
+		},
+		{name: "reserved bits", data: "b10548656c6c6f"},
+		{name: "reserved opcode", data: "830548656c6c6f"},
+		{name: "fragmented ping", data: "090548656c6c6f"},
+		{name: "long ping", data: "897e0100"},
+		{name: "truncated length", data: "817e01"},
+		{name: "truncated masking key", data: "818537fa"},
+		{name: "too short", data: "81"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, ok := readFrame(decodeHex(t, tt.data))
+			assert.Equal(t, tt.wantOk, ok)
+			if !ok {
+				return
+			}
+			assert.Equal(t, tt.wantOpcode, f.opcode)
+			assert.Equal(t, tt.wantMasked, f.masked)
+			assert.Equal(t, tt.wantLength, f.payloadLength)
+			assert.Equal(t, tt.wantPayload, string(f.payload))
+			assert.Equal(t, tt.wantDirection, f.direction())
+		})
+	}
+}
+
+func TestParseWebsocket(t *testing.T) {
+	parser := NewWebsocketParser()
+	request := protocol.NewRequestMessage(decodeHex(t, "89803d4f2a1b"))
+	assert.True(t, parser.ParseRequest(request))
+	assert.Equal(t, "ping", request.GetStringAttribute(constlabels.ContentKey))
+	assert.Equal(t, int64(0), request.GetIntAttribute(constlabels.WebsocketPayloadSize))
+
+	response := protocol.NewResponseMessage(decodeHex(t, "8a00"), request.GetAttributes())
+	assert.True(t, parser.ParseResponse(response))
+	assert.Equal(t, "pong", response.GetStringAttribute(constlabels.WebsocketResponseOpcode))
+	assert.False(t, response.GetAttributes().GetBoolValue(constlabels.IsError))
+
+	// The rest of a large message is accepted as the response.
+	response = protocol.NewResponseMessage(decodeHex(t, "7b2269"), request.GetAttributes())
+	assert.True(t, parser.ParseResponse(response))
+
+	// The normal closure is not an error.
+	request = protocol.NewRequestMessage(decodeHex(t, "880203e9"))
+	assert.True(t, parser.ParseRequest(request))
+	assert.Equal(t, int64(1001), request.GetIntAttribute(constlabels.WebsocketCloseCode))
+	assert.False(t, request.GetAttributes().GetBoolValue(constlabels.IsError))
+
+	assert.False(t, parser.ParseRequest(protocol.NewRequestMessage([]byte("GET / HTTP/1.1\r\n"))))
+}
@@ -0,0 +1,43 @@
+package websocket
+
+import (
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+func fastfailWebsocketRequest() protocol.FastFailFn {
+	return func(message *protocol.PayloadMessage) bool {
+		return len(message.Data) < 2
+	}
+}
+
+// parseWebsocketRequest reads the first frame of the message. The opcode is taken as the content key.
+func parseWebsocketRequest() protocol.ParsePkgFn {
+	return func(message *protocol.PayloadMessage) (bool, bool) {
+		f, ok := readFrame(message.Data)
+		if !ok {
+			return false, true
+		}
+		opcode := opcodeNames[f.opcode]
+		message.AddStringAttribute(constlabels.WebsocketOpcode, opcode)
+		message.AddStringAttribute(constlabels.WebsocketDirection, f.direction())
+		message.AddIntAttribute(constlabels.WebsocketPayloadSize, int64(f.payloadLength))
+		addCloseCode(message, f)
+		message.AddStringAttribute(constlabels.ContentKey, opcode)
+		return true, true
+	}
+}
+
+// addCloseCode adds the status code of the close frame. The closures other than the normal ones are
+// taken as errors.
+func addCloseCode(message *protocol.PayloadMessage, f *frame) {
+	code, ok := f.closeCode()
+	if !ok {
+		return
+	}
+	message.AddIntAttribute(constlabels.WebsocketCloseCode, int64(code))
+	if code != closeNormal && code != closeGoingAway {
+		message.AddBoolAttribute(constlabels.IsError, true)
+		message.AddIntAttribute(constlabels.ErrorType, int64(constlabels.ProtocolError))
+	}
+}
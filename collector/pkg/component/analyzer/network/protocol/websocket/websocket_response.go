@@ -0,0 +1,28 @@
+package websocket
+
+import (
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+func fastfailWebsocketResponse() protocol.FastFailFn {
+	return func(message *protocol.PayloadMessage) bool {
+		return false
+	}
+}
+
+// parseWebsocketResponse reads the first frame sent back. The messages of WebSocket are not answered
+// in general, so the data that does not start with a frame, e.g. the rest of a large message, is
+// accepted without the attributes of the frame.
+func parseWebsocketResponse() protocol.ParsePkgFn {
+	return func(message *protocol.PayloadMessage) (bool, bool) {
+		f, ok := readFrame(message.Data)
+		if !ok {
+			return true, true
+		}
+		message.AddStringAttribute(constlabels.WebsocketResponseOpcode, opcodeNames[f.opcode])
+		message.AddIntAttribute(constlabels.WebsocketResponsePayloadSize, int64(f.payloadLength))
+		addCloseCode(message, f)
+		return true, true
+	}
+}
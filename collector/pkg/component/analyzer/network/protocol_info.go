@@ -0,0 +1,101 @@
+package network
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+)
+
+// protocolInfoKey is a protocol spoken by a container on a port. The port is the one the server
+// listens on, which is the destination port of the requests on both sides.
+type protocolInfoKey struct {
+	containerId string
+	protocol    string
+	port        int64
+	isServer    bool
+}
+
+// protocolInfoTracker records when each container was last observed speaking each protocol.
+// It is safe for concurrent use.
+type protocolInfoTracker struct {
+	expiration time.Duration
+	mutex      sync.Mutex
+	lastSeen   map[protocolInfoKey]time.Time
+}
+
+func newProtocolInfoTracker(expiration time.Duration) *protocolInfoTracker {
+	return &protocolInfoTracker{
+		expiration: expiration,
+		lastSeen:   make(map[protocolInfoKey]time.Time),
+	}
+}
+
+// observe records the protocol of the request. The requests not from containers and those
+// of the unknown protocols are ignored.
+func (t *protocolInfoTracker) observe(record *model.DataGroup, now time.Time) {
+	labels := record.Labels
+	containerId := labels.GetStringValue(constlabels.ContainerId)
+	protocolName := labels.GetStringValue(constlabels.Protocol)
+	if containerId == "" || protocolName == "" || protocolName == protocol.NOSUPPORT {
+		return
+	}
+	key := protocolInfoKey{
+		containerId: containerId,
+		protocol:    protocolName,
+		port:        labels.GetIntValue(constlabels.DstPort),
+		isServer:    labels.GetBoolValue(constlabels.IsServer),
+	}
+	t.mutex.Lock()
+	t.lastSeen[key] = now
+	t.mutex.Unlock()
+}
+
+// flush removes the protocols not observed within the expiration and returns the rest,
+// each of which is a record whose metric is always 1.
+func (t *protocolInfoTracker) flush(now time.Time) []*model.DataGroup {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	timestamp := uint64(now.UnixNano())
+	records := make([]*model.DataGroup, 0, len(t.lastSeen))
+	for key, lastSeen := range t.lastSeen {
+		if now.Sub(lastSeen) >= t.expiration {
+			delete(t.lastSeen, key)
+			continue
+		}
+		labels := model.NewAttributeMap()
+		labels.AddStringValue(constlabels.ContainerId, key.containerId)
+		labels.AddStringValue(constlabels.Protocol, key.protocol)
+		labels.AddIntValue(constlabels.Port, key.port)
+		labels.AddBoolValue(constlabels.IsServer, key.isServer)
+		records = append(records, model.NewDataGroup(constnames.ContainerProtocolMetricGroupName, labels, timestamp,
+			model.NewIntMetric(constnames.ContainerProtocolInfoMetric, 1)))
+	}
+	return records
+}
+
+func (na *NetworkAnalyzer) observeProtocol(record *model.DataGroup) {
+	if na.protocolInfoTracker != nil {
+		na.protocolInfoTracker.observe(record, time.Now())
+	}
+}
+
+// reportProtocolInfo reports the protocols spoken by the containers periodically, so the info
+// metrics stay present as long as the protocols are observed.
+func (na *NetworkAnalyzer) reportProtocolInfo() {
+	timer := time.NewTicker(na.cfg.getProtocolInfoInterval())
+	for {
+		select {
+		case <-timer.C:
+			for _, record := range na.protocolInfoTracker.flush(time.Now()) {
+				na.consume(record)
+			}
+		case <-na.stopChan:
+			timer.Stop()
+			return
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+)
+
+func newProtocolRecord(containerId string, protocolName string, dstPort int64, isServer bool) *model.DataGroup {
+	labels := model.NewAttributeMap()
+	labels.AddStringValue(constlabels.ContainerId, containerId)
+	labels.AddStringValue(constlabels.Protocol, protocolName)
+	labels.AddIntValue(constlabels.DstPort, dstPort)
+	labels.AddBoolValue(constlabels.IsServer, isServer)
+	return model.NewDataGroup(constnames.NetRequestMetricGroupName, labels, 0)
+}
+
+func TestProtocolInfoTracker(t *testing.T) {
+	now := time.Now()
+	expiration := time.Hour
+	tracker := newProtocolInfoTracker(expiration)
+
+	tracker.observe(newProtocolRecord("c1", protocol.HTTP, 8080, true), now)
+	tracker.observe(newProtocolRecord("c1", protocol.HTTP, 8080, true), now.Add(time.Minute))
+	tracker.observe(newProtocolRecord("c1", protocol.MYSQL, 3306, false), now)
+	// Neither the unknown protocols nor the requests outside containers are reported.
+	tracker.observe(newProtocolRecord("c1", protocol.NOSUPPORT, 9999, true), now)
+	tracker.observe(newProtocolRecord("", protocol.REDIS, 6379, false), now)
+
+	records := tracker.flush(now.Add(time.Minute))
+	assert.Len(t, records, 2)
+	for _, record := range records {
+		assert.Equal(t, constnames.ContainerProtocolMetricGroupName, record.Name)
+		assert.Equal(t, "c1", record.Labels.GetStringValue(constlabels.ContainerId))
+		metric, ok := record.GetMetric(constnames.ContainerProtocolInfoMetric)
+		if assert.True(t, ok) {
+			assert.Equal(t, int64(1), metric.GetInt().Value)
+		}
+		switch record.Labels.GetStringValue(constlabels.Protocol) {
+		case protocol.HTTP:
+			assert.Equal(t, int64(8080), record.Labels.GetIntValue(constlabels.Port))
+			assert.True(t, record.Labels.GetBoolValue(constlabels.IsServer))
+		case protocol.MYSQL:
+			assert.Equal(t, int64(3306), record.Labels.GetIntValue(constlabels.Port))
+			assert.False(t, record.Labels.GetBoolValue(constlabels.IsServer))
+		default:
+			t.Errorf("unexpected protocol %s", record.Labels.GetStringValue(constlabels.Protocol))
+		}
+	}
+
+	// MySQL expires while HTTP is still within the expiration since it was last observed.
+	records = tracker.flush(now.Add(expiration + time.Second))
+	if assert.Len(t, records, 1) {
+		assert.Equal(t, protocol.HTTP, records[0].Labels.GetStringValue(constlabels.Protocol))
+	}
+	assert.Empty(t, tracker.flush(now.Add(expiration+2*time.Minute)))
+}
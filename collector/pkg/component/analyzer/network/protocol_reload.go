@@ -0,0 +1,69 @@
+package network
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+)
+
+// ReloadProtocols applies the protocol settings of cfg, i.e. protocol_parser and protocol_config, without
+// restarting the analyzer. It returns before the settings take effect, as they are applied in the
+// goroutine consuming the events. Only the latest settings are kept if the previous ones are still pending.
+func (na *NetworkAnalyzer) ReloadProtocols(cfg *Config) {
+	for {
+		select {
+		case na.reloadChan <- cfg:
+			return
+		default:
+		}
+		// Drop the pending settings, which are outdated.
+		select {
+		case <-na.reloadChan:
+		default:
+		}
+	}
+}
+
+// reloadProtocols rebuilds the parsers with the new settings. The learned states of the unchanged
+// parsers are preserved, including the ports and connections they are cached for and their tiers.
+// The states of the removed parsers are dropped, so the message pairs in flight that were classified
+// as the removed protocols are classified again with the new parsers when they are completed.
+func (na *NetworkAnalyzer) reloadProtocols(cfg *Config, now time.Time) {
+	na.protocolMutex.Lock()
+	defer na.protocolMutex.Unlock()
+
+	oldParsers := na.parsers
+	oldTiers := na.parserTiers
+	na.cfg.ProtocolParser = cfg.ProtocolParser
+	na.cfg.ProtocolConfigs = cfg.ProtocolConfigs
+	na.initProtocols(now)
+	if oldTiers != nil && na.parserTiers != nil {
+		na.parserTiers.inherit(oldTiers)
+	}
+
+	remaining := make(map[*protocol.ProtocolParser]bool, len(na.parsers))
+	for _, parser := range na.parsers {
+		remaining[parser] = true
+	}
+	removed := make([]string, 0)
+	for _, parser := range oldParsers {
+		if remaining[parser] {
+			continue
+		}
+		na.parserFactory.RemoveParserFromCache(parser)
+		na.forgetConnectionParser(parser)
+		removed = append(removed, parser.GetProtocol())
+	}
+	// The parsers of the upgraded connections are not in the classification.
+	for protocolName := range upgradedProtocols {
+		if _, ok := na.protocolMap[protocolName]; !ok {
+			if parser := na.parserFactory.GetParser(protocolName); parser != nil {
+				na.forgetConnectionParser(parser)
+			}
+		}
+	}
+	na.telemetry.Logger.Info("The protocol settings are reloaded", zap.Strings("protocol_parser", na.cfg.ProtocolParser),
+		zap.Strings("removed", removed))
+}
@@ -0,0 +1,66 @@
+package network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/factory"
+)
+
+func TestReloadProtocols(t *testing.T) {
+	cfg := NewDefaultConfig()
+	cfg.ProtocolParser = []string{protocol.HTTP, protocol.KAFKA, protocol.MYSQL}
+	cfg.ParserTiering = &ParserTieringConfig{Enable: true, ColdAfter: 600, ColdCheckInterval: 100}
+	na := &NetworkAnalyzer{
+		cfg:           cfg,
+		parserFactory: factory.NewParserFactory(),
+		telemetry:     component.NewDefaultTelemetryTools(),
+		reloadChan:    make(chan *Config, 1),
+	}
+	start := time.Unix(1000, 0)
+	na.initProtocols(start)
+
+	httpParser := na.parserFactory.GetParser(protocol.HTTP)
+	kafkaParser := na.parserFactory.GetParser(protocol.KAFKA)
+	na.parserFactory.AddCachedParser(8080, httpParser)
+	na.parserFactory.AddCachedParser(9092, kafkaParser)
+	httpConn := connectionKey{pid: 1, fd: 3, sport: 40000, dport: 8080}
+	kafkaConn := connectionKey{pid: 1, fd: 4, sport: 40001, dport: 9092}
+	na.setConnectionParser(httpConn, httpParser, start)
+	na.setConnectionParser(kafkaConn, kafkaParser, start)
+	na.parserTiers.hit(httpParser, start.Add(time.Hour))
+
+	newCfg := NewDefaultConfig()
+	newCfg.ProtocolParser = []string{protocol.HTTP, protocol.MYSQL, protocol.REDIS}
+	na.ReloadProtocols(newCfg)
+	na.reloadProtocols(<-na.reloadChan, start.Add(2*time.Hour))
+
+	var protocols []string
+	for _, parser := range na.parsers {
+		protocols = append(protocols, parser.GetProtocol())
+	}
+	assert.Equal(t, []string{protocol.HTTP, protocol.MYSQL, protocol.REDIS, protocol.NOSUPPORT}, protocols)
+	// The states learned by the unchanged parser are preserved.
+	assert.Equal(t, map[uint32][]string{8080: {protocol.HTTP}}, na.parserFactory.GetCachedProtocols())
+	parser, ok := na.getConnectionParser(httpConn, start)
+	assert.True(t, ok)
+	assert.Equal(t, httpParser, parser)
+	assert.Equal(t, start.Add(time.Hour).UnixNano(), *na.parserTiers.lastHits[httpParser])
+	// The connections recognized by the removed parser are classified again.
+	_, ok = na.getConnectionParser(kafkaConn, start)
+	assert.False(t, ok)
+}
+
+func TestReloadProtocols_KeepLatest(t *testing.T) {
+	na := &NetworkAnalyzer{reloadChan: make(chan *Config, 1)}
+	first := NewDefaultConfig()
+	second := NewDefaultConfig()
+	na.ReloadProtocols(first)
+	na.ReloadProtocols(second)
+	assert.Same(t, second, <-na.reloadChan)
+	assert.Len(t, na.reloadChan, 0)
+}
@@ -0,0 +1,167 @@
+package network
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/quic"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+	"github.com/Kindling-project/kindling/collector/pkg/model/timeunit"
+)
+
+// quicConnection is the QUIC connection opened by the Initial packets of the client. It is recorded once
+// with the latency from the first Initial packet to the first packet sent back by the server, which
+// covers the round trip of the handshake instead of the requests encrypted in the 1-RTT packets.
+type quicConnection struct {
+	mutex     sync.Mutex
+	request   *model.KindlingEvent
+	initial   *quic.InitialPacket
+	handshake quic.Handshake
+	// answered is true after the first packet of the server is recorded. The connection is kept until
+	// it is idle so its later packets are not taken as new connections.
+	answered      bool
+	lastTimestamp uint64
+}
+
+// isUdpQuicEvent returns true if the UDP event is sent to the ports configured with the QUIC key.
+func (na *NetworkAnalyzer) isUdpQuicEvent(evt *model.KindlingEvent) bool {
+	return na.quicPorts[evt.GetDport()]
+}
+
+func (na *NetworkAnalyzer) analyseQuic(evt *model.KindlingEvent) error {
+	if evt.GetDataLen() <= 0 || evt.GetResVal() < 0 {
+		return nil
+	}
+	isRequest, err := evt.IsRequest()
+	if err != nil {
+		return err
+	}
+	key := getUdpKey(evt)
+	if isRequest {
+		// The datagrams could be sent in batches with "sendmmsg" like DNS.
+		if evt.Name == constnames.SendMMsgEvent {
+			for _, e := range model.ConvertSendmmsg(evt) {
+				na.consumeQuicInitial(e, key)
+			}
+		} else {
+			na.consumeQuicInitial(evt, key)
+		}
+		return nil
+	}
+
+	value, ok := na.quicMonitor.Load(key)
+	if !ok {
+		return nil
+	}
+	connection := value.(*quicConnection)
+	connection.mutex.Lock()
+	connection.lastTimestamp = evt.Timestamp
+	if connection.answered || connection.request == nil {
+		connection.mutex.Unlock()
+		return nil
+	}
+	connection.answered = true
+	mp := &messagePair{
+		request:  connection.request,
+		response: evt,
+	}
+	attributes := connection.attributes()
+	// The server answers the unsupported version with the Version Negotiation packet.
+	if quic.IsLongHeader(evt.GetData()) && bytes.Equal(evt.GetData()[1:5], []byte{0, 0, 0, 0}) {
+		attributes.AddBoolValue(constlabels.IsError, true)
+		attributes.AddIntValue(constlabels.ErrorType, int64(constlabels.ProtocolError))
+	}
+	connection.mutex.Unlock()
+	return na.distributeQuicRecord(mp, attributes)
+}
+
+func (na *NetworkAnalyzer) distributeQuicRecord(mp *messagePair, attributes *model.AttributeMap) error {
+	record := na.getRecordWithSinglePair(mp, protocol.QUIC, attributes)
+	if record == nil {
+		return nil
+	}
+	// The payloads are encrypted except the Initial packets, which are decrypted above.
+	record.Labels.UpdateAddStringValue(constlabels.RequestPayload, "")
+	record.Labels.UpdateAddStringValue(constlabels.ResponsePayload, "")
+	return na.distributeRecords([]*model.DataGroup{record})
+}
+
+// consumeQuicInitial starts the connection with the Initial packet of the client. The ClientHello split
+// across the Initial packets of the same connection is reassembled.
+func (na *NetworkAnalyzer) consumeQuicInitial(evt *model.KindlingEvent, key udpKey) {
+	initial, ok := quic.ParseInitialPacket(evt.GetData())
+	value, exist := na.quicMonitor.Load(key)
+	if !exist {
+		if !ok {
+			return
+		}
+		value, _ = na.quicMonitor.LoadOrStore(key, &quicConnection{})
+	}
+	connection := value.(*quicConnection)
+	connection.mutex.Lock()
+	defer connection.mutex.Unlock()
+	connection.lastTimestamp = evt.Timestamp
+	if !ok || connection.answered {
+		return
+	}
+	if connection.request == nil {
+		connection.request = evt
+		connection.initial = initial
+	} else if !bytes.Equal(connection.initial.Dcid, initial.Dcid) {
+		return
+	}
+	connection.handshake.Add(initial)
+}
+
+// attributes returns the labels of the connection taken from the ClientHello. The server name is the
+// content key, as the requests are encrypted.
+func (connection *quicConnection) attributes() *model.AttributeMap {
+	attributes := model.NewAttributeMap()
+	attributes.AddStringValue(constlabels.ProtocolVersion, quic.VersionString(connection.initial.Version))
+	contentKey := "*"
+	if hello, ok := connection.handshake.ClientHello(); ok {
+		if hello.ServerName != "" {
+			attributes.AddStringValue(constlabels.QuicSni, hello.ServerName)
+			contentKey = hello.ServerName
+		}
+		if len(hello.Alpn) > 0 {
+			attributes.AddStringValue(constlabels.QuicAlpn, strings.Join(hello.Alpn, ","))
+		}
+	}
+	attributes.AddStringValue(constlabels.ContentKey, contentKey)
+	return attributes
+}
+
+// cleanQuicConnections records the connections not answered within the no-response threshold, and
+// removes the answered ones which are idle for the threshold.
+func (na *NetworkAnalyzer) cleanQuicConnections() {
+	na.quicMonitor.Range(func(k, v interface{}) bool {
+		connection := v.(*quicConnection)
+		connection.mutex.Lock()
+		request := connection.request
+		threshold := timeunit.FromSeconds(na.getNoResponseThreshold(k.(udpKey).dport, protocol.QUIC))
+		if request == nil || timeunit.Now().Sub(timeunit.Timestamp(connection.lastTimestamp)) < threshold {
+			connection.mutex.Unlock()
+			return true
+		}
+		na.quicMonitor.Delete(k)
+		answered := connection.answered
+		var attributes *model.AttributeMap
+		if !answered {
+			attributes = connection.attributes()
+		}
+		connection.mutex.Unlock()
+		if !answered {
+			// No Response Request
+			mp := &messagePair{
+				request: request,
+			}
+			_ = na.distributeQuicRecord(mp, attributes)
+		}
+		return true
+	})
+}
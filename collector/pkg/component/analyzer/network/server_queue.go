@@ -0,0 +1,59 @@
+package network
+
+import (
+	"time"
+
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+)
+
+// recordAccept stores the time when the server accepted the connection. The accepted connection
+// is identified by the fd returned, so the first read from it can be matched later.
+func (na *NetworkAnalyzer) recordAccept(evt *model.KindlingEvent) {
+	// The accept events carry the new fd in the parameter "fd" instead of "res".
+	if newFd := evt.GetUserAttribute("fd"); newFd != nil && newFd.GetIntValue() < 0 {
+		return
+	}
+	na.getGeneration(evt, true)
+	na.acceptMonitor.Store(getMessagePairKey(evt), evt.Timestamp)
+}
+
+// consumeFirstRead emits the time between accepting the connection and the application starting
+// to read the first request from it. A long queue time indicates the worker threads are exhausted.
+func (na *NetworkAnalyzer) consumeFirstRead(evt *model.KindlingEvent) {
+	acceptTs, ok := na.acceptMonitor.LoadAndDelete(getMessagePairKey(evt))
+	if !ok {
+		return
+	}
+	startTime := evt.GetStartTime()
+	if startTime < acceptTs.(uint64) {
+		return
+	}
+	dataGroup := newServerQueueDataGroup(evt, startTime-acceptTs.(uint64))
+	na.consume(dataGroup)
+}
+
+// cleanAccepts removes the connections from which nothing has been read within the threshold.
+func (na *NetworkAnalyzer) cleanAccepts(now time.Time) {
+	threshold := uint64(na.cfg.getNoResponseThreshold()) * uint64(time.Second)
+	na.acceptMonitor.Range(func(k, v interface{}) bool {
+		if uint64(now.UnixNano())-v.(uint64) >= threshold {
+			na.acceptMonitor.Delete(k)
+		}
+		return true
+	})
+}
+
+func newServerQueueDataGroup(evt *model.KindlingEvent, queueTime uint64) *model.DataGroup {
+	labels := model.NewAttributeMap()
+	labels.AddIntValue(constlabels.Pid, int64(evt.GetPid()))
+	labels.AddStringValue(constlabels.Comm, evt.GetComm())
+	labels.AddStringValue(constlabels.ContainerId, evt.GetContainerId())
+	labels.AddBoolValue(constlabels.IsServer, true)
+	labels.AddStringValue(constlabels.SrcIp, evt.GetSip())
+	labels.AddStringValue(constlabels.DstIp, evt.GetDip())
+	labels.AddIntValue(constlabels.DstPort, int64(evt.GetDport()))
+	return model.NewDataGroup(constnames.ServerQueueMetricGroupName, labels, evt.GetStartTime(),
+		model.NewIntMetric(constnames.ServerQueueTimeMetric, int64(queueTime)))
+}
@@ -0,0 +1,85 @@
+package network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+)
+
+type queueTimeConsumer struct {
+	dataGroups []*model.DataGroup
+}
+
+func (c *queueTimeConsumer) Consume(dataGroup *model.DataGroup) error {
+	c.dataGroups = append(c.dataGroups, dataGroup)
+	return nil
+}
+
+func newServerEvent(name string, fd int32, timestamp uint64, latency uint64) *model.KindlingEvent {
+	return &model.KindlingEvent{
+		Name:      name,
+		Category:  model.Category_CAT_NET,
+		Timestamp: timestamp,
+		Latency:   latency,
+		Ctx: model.Context{
+			ThreadInfo: model.Thread{Pid: 100, Tid: 101, Comm: "java"},
+			FdInfo: model.Fd{
+				Num:      fd,
+				Protocol: model.L4Proto_TCP,
+				Role:     true,
+				Sip:      model.IPs{16777226},
+				Dip:      model.IPs{33554442},
+				Sport:    40000,
+				Dport:    8080,
+			},
+		},
+	}
+}
+
+func TestServerQueueTime(t *testing.T) {
+	c := &queueTimeConsumer{}
+	na := &NetworkAnalyzer{
+		cfg:           NewDefaultConfig(),
+		nextConsumers: []consumer.Consumer{c},
+	}
+
+	na.recordAccept(newServerEvent(constnames.Accept4Event, 5, 1000, 10))
+	// The read from another connection doesn't match.
+	na.consumeFirstRead(newServerEvent(constnames.ReadEvent, 6, 3000, 100))
+	assert.Empty(t, c.dataGroups)
+
+	na.consumeFirstRead(newServerEvent(constnames.ReadEvent, 5, 5000, 500))
+	if assert.Len(t, c.dataGroups, 1) {
+		dataGroup := c.dataGroups[0]
+		assert.Equal(t, constnames.ServerQueueMetricGroupName, dataGroup.Name)
+		queueTime, ok := dataGroup.GetMetric(constnames.ServerQueueTimeMetric)
+		assert.True(t, ok)
+		assert.Equal(t, int64(3500), queueTime.GetInt().Value)
+		assert.True(t, dataGroup.Labels.GetBoolValue(constlabels.IsServer))
+		assert.Equal(t, int64(8080), dataGroup.Labels.GetIntValue(constlabels.DstPort))
+	}
+
+	// Only the first read is measured.
+	na.consumeFirstRead(newServerEvent(constnames.ReadEvent, 5, 9000, 500))
+	assert.Len(t, c.dataGroups, 1)
+}
+
+func TestCleanAccepts(t *testing.T) {
+	na := &NetworkAnalyzer{cfg: NewDefaultConfig()}
+	now := time.Now()
+	threshold := time.Duration(na.cfg.getNoResponseThreshold()) * time.Second
+	na.recordAccept(newServerEvent(constnames.AcceptEvent, 5, uint64(now.Add(-threshold).UnixNano()), 0))
+	na.recordAccept(newServerEvent(constnames.AcceptEvent, 6, uint64(now.UnixNano()), 0))
+
+	na.cleanAccepts(now)
+	_, ok := na.acceptMonitor.Load(messagePairKey{pid: 100, fd: 5})
+	assert.False(t, ok)
+	_, ok = na.acceptMonitor.Load(messagePairKey{pid: 100, fd: 6})
+	assert.True(t, ok)
+}
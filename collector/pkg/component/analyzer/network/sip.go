@@ -0,0 +1,129 @@
+package network
+
+import (
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/sip"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+	"github.com/Kindling-project/kindling/collector/pkg/model/timeunit"
+)
+
+// sipKey pairs the final response with the request of the same transaction, as the user agents and the
+// proxies send the requests of many dialogs over the same socket and both of the peers send requests.
+type sipKey struct {
+	udpKey
+	callId     string
+	cseqNumber uint32
+	cseqMethod string
+}
+
+type sipRequest struct {
+	event   *model.KindlingEvent
+	message *sip.Message
+}
+
+// isUdpSipEvent returns true if the UDP event is sent from or to the ports configured with the SIP key, as
+// the user agents usually send both the requests and the responses from 5060.
+func (na *NetworkAnalyzer) isUdpSipEvent(evt *model.KindlingEvent) bool {
+	return na.sipPorts[evt.GetDport()] || na.sipPorts[evt.GetSport()]
+}
+
+func (na *NetworkAnalyzer) analyseSip(evt *model.KindlingEvent) error {
+	if evt.GetDataLen() <= 0 || evt.GetResVal() < 0 {
+		return nil
+	}
+	if evt.Name == constnames.SendMMsgEvent {
+		for _, e := range model.ConvertSendmmsg(evt) {
+			if err := na.consumeSipMessage(e); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return na.consumeSipMessage(evt)
+}
+
+// consumeSipMessage tells the requests from the responses by their start lines instead of the direction,
+// as both of the peers send requests, e.g. the BYE could be sent by the callee.
+func (na *NetworkAnalyzer) consumeSipMessage(evt *model.KindlingEvent) error {
+	message, ok := sip.ParseMessage(evt.GetData())
+	if !ok {
+		return nil
+	}
+	key := sipKey{
+		udpKey:     getUdpKey(evt),
+		callId:     message.CallId,
+		cseqNumber: message.CseqNumber,
+		cseqMethod: message.CseqMethod,
+	}
+	if message.IsRequest() {
+		// The ACK of the final response is never answered.
+		if message.Method == "ACK" {
+			return nil
+		}
+		// The requests retransmitted over UDP are ignored, so the latency starts from the first one.
+		na.sipMonitor.LoadOrStore(key, &sipRequest{event: evt, message: message})
+		return nil
+	}
+	// The provisional responses like "180 Ringing" are followed by the final response, and the final
+	// responses retransmitted are not paired again as the request is removed.
+	if message.IsProvisional() {
+		return nil
+	}
+	value, ok := na.sipMonitor.LoadAndDelete(key)
+	if !ok {
+		return nil
+	}
+	request := value.(*sipRequest)
+	mp := &messagePair{
+		request:  request.event,
+		response: evt,
+	}
+	return na.distributeSipRecord(mp, getSipAttributes(request.message, message))
+}
+
+func (na *NetworkAnalyzer) distributeSipRecord(mp *messagePair, attributes *model.AttributeMap) error {
+	return na.distributeRecords([]*model.DataGroup{na.getRecordWithSinglePair(mp, protocol.SIP, attributes)})
+}
+
+// getSipAttributes returns the labels of the request and the response, which is nil if there is no response.
+func getSipAttributes(request *sip.Message, response *sip.Message) *model.AttributeMap {
+	attributes := model.NewAttributeMap()
+	attributes.AddStringValue(constlabels.SipMethod, request.Method)
+	attributes.AddStringValue(constlabels.SipCallId, request.CallId)
+	attributes.AddIntValue(constlabels.SipCseq, int64(request.CseqNumber))
+	attributes.AddStringValue(constlabels.ContentKey, request.Method)
+	if response == nil {
+		return attributes
+	}
+	attributes.AddIntValue(constlabels.SipStatusCode, int64(response.StatusCode))
+	if response.IsFailure() {
+		attributes.AddBoolValue(constlabels.IsError, true)
+		attributes.AddIntValue(constlabels.ErrorType, int64(constlabels.ProtocolError))
+	}
+	return attributes
+}
+
+// cleanSipRequests records the requests not answered within the no-response threshold.
+func (na *NetworkAnalyzer) cleanSipRequests() {
+	na.sipMonitor.Range(func(k, v interface{}) bool {
+		request := v.(*sipRequest)
+		key := k.(sipKey)
+		port := key.dport
+		if !na.sipPorts[port] {
+			port = key.sport
+		}
+		threshold := timeunit.FromSeconds(na.getNoResponseThreshold(port, protocol.SIP))
+		if timeunit.Now().Sub(timeunit.Timestamp(request.event.Timestamp)) < threshold {
+			return true
+		}
+		na.sipMonitor.Delete(k)
+		// No Response Request
+		mp := &messagePair{
+			request: request.event,
+		}
+		_ = na.distributeSipRecord(mp, getSipAttributes(request.message, nil))
+		return true
+	})
+}
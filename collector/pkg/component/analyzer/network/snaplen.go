@@ -0,0 +1,67 @@
+package network
+
+import (
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+)
+
+// captureLength returns the max length of the payloads kept for the message pairs on the port. It is
+// the snaplen unless the adaptive snaplen is enabled and the port is known to carry a protocol whose
+// parser only needs the leading bytes. It is called in the goroutine consuming the events.
+func (na *NetworkAnalyzer) captureLength(port uint32) int {
+	if !na.cfg.AdaptiveSnaplen {
+		return na.snaplen
+	}
+	var parser *protocol.ProtocolParser
+	if protocolName, ok := na.staticPortMap[port]; ok {
+		parser = na.protocolMap[protocolName]
+	} else if cachedParsers, ok := na.parserFactory.GetCachedParsersByPort(port); ok && len(cachedParsers) == 1 {
+		// The port carrying multiple protocols keeps the snaplen.
+		parser = cachedParsers[0]
+	}
+	if parser == nil {
+		return na.snaplen
+	}
+	return na.parserCaptureLength(parser)
+}
+
+// parserCaptureLength returns the length declared by the parser, which is extended to the payload
+// length of the protocol so the payloads exported are not shortened. It never exceeds the snaplen.
+func (na *NetworkAnalyzer) parserCaptureLength(parser *protocol.ProtocolParser) int {
+	length := parser.GetMinCaptureLength()
+	if length <= 0 {
+		return na.snaplen
+	}
+	if payloadLength := protocol.GetPayLoadLength(parser.GetProtocol()); payloadLength > length {
+		length = payloadLength
+	}
+	if length > na.snaplen {
+		return na.snaplen
+	}
+	return length
+}
+
+// CaptureLength returns the snaplen needed by the enabled parsers, which could be applied in the kernel
+// instead of the configured one. The kernel truncates the payloads of all the ports with the same length,
+// so it is the max length needed by the parsers. It is 0 if the adaptive snaplen is disabled or any parser
+// may need the whole payloads. The generic parser only needs the payload length of NOSUPPORT.
+func (na *NetworkAnalyzer) CaptureLength() int {
+	if !na.cfg.AdaptiveSnaplen {
+		return 0
+	}
+	na.protocolMutex.RLock()
+	defer na.protocolMutex.RUnlock()
+	length := protocol.GetPayLoadLength(protocol.NOSUPPORT)
+	for _, parser := range na.protocolMap {
+		parserLength := na.parserCaptureLength(parser)
+		if parserLength >= na.snaplen {
+			return 0
+		}
+		if parserLength > length {
+			length = parserLength
+		}
+	}
+	if length >= na.snaplen {
+		return 0
+	}
+	return length
+}
@@ -0,0 +1,51 @@
+package network
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/factory"
+)
+
+func TestCaptureLength(t *testing.T) {
+	protocol.SetPayLoadLength(protocol.HTTP, 200)
+	protocol.SetPayLoadLength(protocol.MYSQL, 1000)
+	protocol.SetPayLoadLength(protocol.REDIS, 200)
+	protocol.SetPayLoadLength(protocol.NOSUPPORT, 200)
+	cfg := NewDefaultConfig()
+	cfg.AdaptiveSnaplen = true
+	na := &NetworkAnalyzer{
+		cfg:           cfg,
+		snaplen:       4096,
+		parserFactory: factory.NewParserFactory(),
+		staticPortMap: map[uint32]string{80: protocol.HTTP, 3306: protocol.MYSQL, 6379: protocol.REDIS},
+	}
+	na.protocolMap = map[string]*protocol.ProtocolParser{
+		protocol.HTTP:  na.parserFactory.GetParser(protocol.HTTP),
+		protocol.MYSQL: na.parserFactory.GetParser(protocol.MYSQL),
+	}
+
+	assert.Equal(t, 1024, na.captureLength(80))
+	// The payload length exported is longer than the one the parser declares.
+	assert.Equal(t, 1000, na.captureLength(3306))
+	// The parser disabled and the unknown port keep the snaplen.
+	assert.Equal(t, 4096, na.captureLength(6379))
+	assert.Equal(t, 4096, na.captureLength(8080))
+	assert.Equal(t, 1024, na.CaptureLength())
+
+	na.snaplen = 800
+	assert.Equal(t, 800, na.captureLength(80))
+	assert.Equal(t, 0, na.CaptureLength())
+
+	// Redis needs the whole payloads.
+	na.snaplen = 4096
+	na.protocolMap[protocol.REDIS] = na.parserFactory.GetParser(protocol.REDIS)
+	assert.Equal(t, 4096, na.captureLength(6379))
+	assert.Equal(t, 0, na.CaptureLength())
+
+	cfg.AdaptiveSnaplen = false
+	assert.Equal(t, 4096, na.captureLength(80))
+	assert.Equal(t, 0, na.CaptureLength())
+}
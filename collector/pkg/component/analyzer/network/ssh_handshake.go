@@ -0,0 +1,146 @@
+package network
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constvalues"
+)
+
+const (
+	// sshIdentificationContent is the content key of the records of the identification strings.
+	sshIdentificationContent = "identification"
+	// sshHandshakeContent is the content key of the records of the whole handshakes.
+	sshHandshakeContent = "handshake"
+)
+
+type sshSessionKey struct {
+	pid     int64
+	srcIp   string
+	srcPort int64
+	dstIp   string
+	dstPort int64
+}
+
+// sshSession collects the records of an SSH connection from the identification strings to
+// SSH_MSG_NEWKEYS, as the software of the client and the server are usually found in different records.
+type sshSession struct {
+	startTimestamp uint64
+	clientSoftware string
+	serverSoftware string
+	version        string
+	fileTransfer   bool
+	requestIo      int64
+	responseIo     int64
+	lastSeen       time.Time
+}
+
+type sshSessionTracker struct {
+	mutex    sync.Mutex
+	sessions map[sshSessionKey]*sshSession
+}
+
+func newSshSessionTracker() *sshSessionTracker {
+	return &sshSessionTracker{sessions: make(map[sshSessionKey]*sshSession)}
+}
+
+// observeSshHandshake returns the record of the handshake if the SSH record ends the key exchange,
+// otherwise nil. The record of the handshake takes the software of both sides, and its latency is from
+// the first identification string to SSH_MSG_NEWKEYS. The caller owns the returned record.
+func (na *NetworkAnalyzer) observeSshHandshake(record *model.DataGroup, now time.Time) *model.DataGroup {
+	labels := record.Labels
+	if na.sshSessions == nil || labels.GetStringValue(constlabels.Protocol) != protocol.SSH {
+		return nil
+	}
+	key := sshSessionKey{
+		pid:     labels.GetIntValue(constlabels.Pid),
+		srcIp:   labels.GetStringValue(constlabels.SrcIp),
+		srcPort: labels.GetIntValue(constlabels.SrcPort),
+		dstIp:   labels.GetStringValue(constlabels.DstIp),
+		dstPort: labels.GetIntValue(constlabels.DstPort),
+	}
+	tracker := na.sshSessions
+	tracker.mutex.Lock()
+	defer tracker.mutex.Unlock()
+	session, ok := tracker.sessions[key]
+	if !ok {
+		// The connections observed after the identification strings are not tracked.
+		if labels.GetStringValue(constlabels.ContentKey) != sshIdentificationContent {
+			return nil
+		}
+		session = &sshSession{startTimestamp: record.Timestamp}
+		tracker.sessions[key] = session
+	}
+	session.lastSeen = now
+	if software := labels.GetStringValue(constlabels.SshClientSoftware); software != "" {
+		session.clientSoftware = software
+	}
+	if software := labels.GetStringValue(constlabels.SshServerSoftware); software != "" {
+		session.serverSoftware = software
+	}
+	if version := labels.GetStringValue(constlabels.ProtocolVersion); version != "" {
+		session.version = version
+	}
+	session.fileTransfer = session.fileTransfer || labels.GetBoolValue(constlabels.SshFileTransfer)
+	session.requestIo += getIntMetric(record, constvalues.RequestIo)
+	session.responseIo += getIntMetric(record, constvalues.ResponseIo)
+	if !labels.GetBoolValue(constlabels.SshNewKeys) {
+		return nil
+	}
+	delete(tracker.sessions, key)
+
+	endTimestamp := record.Timestamp + uint64(getIntMetric(record, constvalues.RequestTotalTime))
+	duration := endTimestamp - session.startTimestamp
+	handshakeLabels := labels.Clone()
+	handshakeLabels.UpdateAddStringValue(constlabels.ContentKey, sshHandshakeContent)
+	if session.clientSoftware != "" {
+		handshakeLabels.UpdateAddStringValue(constlabels.SshClientSoftware, session.clientSoftware)
+	}
+	if session.serverSoftware != "" {
+		handshakeLabels.UpdateAddStringValue(constlabels.SshServerSoftware, session.serverSoftware)
+	}
+	if session.version != "" {
+		handshakeLabels.UpdateAddStringValue(constlabels.ProtocolVersion, session.version)
+	}
+	if session.fileTransfer {
+		handshakeLabels.UpdateAddBoolValue(constlabels.SshFileTransfer, true)
+	}
+	handshakeLabels.UpdateAddBoolValue(constlabels.IsSlow, na.isSlow(duration, protocol.SSH))
+	handshakeLabels.UpdateAddIntValue(constlabels.EndTimestamp, int64(endTimestamp))
+	handshakeLabels.UpdateAddStringValue(constlabels.RequestPayload, "")
+	handshakeLabels.UpdateAddStringValue(constlabels.ResponsePayload, "")
+	return model.NewDataGroup(constnames.NetRequestMetricGroupName, handshakeLabels, session.startTimestamp,
+		model.NewIntMetric(constvalues.ConnectTime, 0),
+		model.NewIntMetric(constvalues.RequestSentTime, 0),
+		model.NewIntMetric(constvalues.WaitingTtfbTime, 0),
+		model.NewIntMetric(constvalues.ContentDownloadTime, 0),
+		model.NewIntMetric(constvalues.RequestTotalTime, int64(duration)),
+		model.NewIntMetric(constvalues.RequestIo, session.requestIo),
+		model.NewIntMetric(constvalues.ResponseIo, session.responseIo))
+}
+
+// cleanSshSessions removes the sessions whose key exchanges have not ended within the no-response threshold.
+func (na *NetworkAnalyzer) cleanSshSessions(now time.Time) {
+	if na.sshSessions == nil {
+		return
+	}
+	threshold := time.Duration(na.cfg.getNoResponseThreshold()) * time.Second
+	na.sshSessions.mutex.Lock()
+	defer na.sshSessions.mutex.Unlock()
+	for key, session := range na.sshSessions.sessions {
+		if now.Sub(session.lastSeen) >= threshold {
+			delete(na.sshSessions.sessions, key)
+		}
+	}
+}
+
+func getIntMetric(record *model.DataGroup, name string) int64 {
+	if metric, ok := record.GetMetric(name); ok {
+		return metric.GetInt().Value
+	}
+	return 0
+}
@@ -0,0 +1,71 @@
+package network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constvalues"
+)
+
+func newSshRecord(srcPort int64, timestamp uint64, duration time.Duration, contentKey string) *model.DataGroup {
+	labels := model.NewAttributeMap()
+	labels.AddIntValue(constlabels.Pid, 1024)
+	labels.AddStringValue(constlabels.SrcIp, "10.0.0.1")
+	labels.AddIntValue(constlabels.SrcPort, srcPort)
+	labels.AddStringValue(constlabels.DstIp, "10.0.0.2")
+	labels.AddIntValue(constlabels.DstPort, 22)
+	labels.AddStringValue(constlabels.Protocol, protocol.SSH)
+	labels.AddStringValue(constlabels.ContentKey, contentKey)
+	labels.AddBoolValue(constlabels.IsSlow, false)
+	labels.AddStringValue(constlabels.RequestPayload, "SSH-2.0-OpenSSH_9.0")
+	return model.NewDataGroup(constnames.NetRequestMetricGroupName, labels, timestamp,
+		model.NewIntMetric(constvalues.RequestTotalTime, int64(duration)),
+		model.NewIntMetric(constvalues.RequestIo, 100),
+		model.NewIntMetric(constvalues.ResponseIo, 200))
+}
+
+func TestObserveSshHandshake(t *testing.T) {
+	na := &NetworkAnalyzer{cfg: NewDefaultConfig(), sshSessions: newSshSessionTracker()}
+	now := time.Now()
+
+	identification := newSshRecord(52380, 100000000, time.Millisecond, sshIdentificationContent)
+	identification.Labels.AddStringValue(constlabels.SshClientSoftware, "OpenSSH_9.0")
+	identification.Labels.AddStringValue(constlabels.SshServerSoftware, "OpenSSH_8.9p1")
+	identification.Labels.AddStringValue(constlabels.ProtocolVersion, "2.0")
+	assert.Nil(t, na.observeSshHandshake(identification, now))
+	assert.Nil(t, na.observeSshHandshake(newSshRecord(52380, 102000000, time.Millisecond, "key_exchange"), now))
+	newKeys := newSshRecord(52380, 104000000, 600*time.Millisecond, "key_exchange")
+	newKeys.Labels.AddBoolValue(constlabels.SshNewKeys, true)
+	handshake := na.observeSshHandshake(newKeys, now)
+	assert.NotNil(t, handshake)
+	assert.Equal(t, uint64(100000000), handshake.Timestamp)
+	assert.Equal(t, "handshake", handshake.Labels.GetStringValue(constlabels.ContentKey))
+	assert.Equal(t, "OpenSSH_9.0", handshake.Labels.GetStringValue(constlabels.SshClientSoftware))
+	assert.Equal(t, "OpenSSH_8.9p1", handshake.Labels.GetStringValue(constlabels.SshServerSoftware))
+	assert.Equal(t, "2.0", handshake.Labels.GetStringValue(constlabels.ProtocolVersion))
+	assert.Equal(t, "", handshake.Labels.GetStringValue(constlabels.RequestPayload))
+	assert.True(t, handshake.Labels.GetBoolValue(constlabels.IsSlow))
+	assert.Equal(t, int64(604000000), getIntMetric(handshake, constvalues.RequestTotalTime))
+	assert.Equal(t, int64(300), getIntMetric(handshake, constvalues.RequestIo))
+	assert.Equal(t, int64(600), getIntMetric(handshake, constvalues.ResponseIo))
+	// The record of SSH_MSG_NEWKEYS is not changed.
+	assert.Equal(t, "key_exchange", newKeys.Labels.GetStringValue(constlabels.ContentKey))
+	assert.Empty(t, na.sshSessions.sessions)
+
+	// The connections observed after the identification strings are not tracked.
+	assert.Nil(t, na.observeSshHandshake(newKeys, now))
+	assert.Empty(t, na.sshSessions.sessions)
+
+	// The sessions whose key exchanges never end are removed.
+	assert.Nil(t, na.observeSshHandshake(newSshRecord(52381, 100000000, time.Millisecond, sshIdentificationContent), now))
+	na.cleanSshSessions(now.Add(time.Second))
+	assert.Len(t, na.sshSessions.sessions, 1)
+	na.cleanSshSessions(now.Add(time.Duration(na.cfg.getNoResponseThreshold()) * time.Second))
+	assert.Empty(t, na.sshSessions.sessions)
+}
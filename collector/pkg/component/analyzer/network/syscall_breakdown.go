@@ -0,0 +1,139 @@
+package network
+
+import (
+	"sync"
+
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+)
+
+type syscallKind int
+
+const (
+	syscallRead syscallKind = iota
+	syscallWrite
+	syscallPoll
+)
+
+func getSyscallKind(name string) (syscallKind, bool) {
+	switch name {
+	case constnames.ReadEvent, constnames.ReadvEvent, constnames.RecvFromEvent, constnames.RecvMsgEvent:
+		return syscallRead, true
+	case constnames.WriteEvent, constnames.WritevEvent, constnames.SendToEvent, constnames.SendMsgEvent, constnames.SendMMsgEvent:
+		return syscallWrite, true
+	case constnames.EpollWaitEvent, constnames.PollEvent, constnames.PpollEvent, constnames.SelectEvent:
+		return syscallPoll, true
+	default:
+		return 0, false
+	}
+}
+
+type syscallSpan struct {
+	kind  syscallKind
+	start uint64
+	end   uint64
+}
+
+// threadSyscalls is a ring buffer holding the latest syscalls of a thread.
+type threadSyscalls struct {
+	spans  []syscallSpan
+	next   int
+	lastTs uint64
+}
+
+// syscallTracker keeps the recent syscalls of each thread, so the time a thread spent in the
+// syscalls during a request can be calculated when the request is found slow.
+// It is safe for concurrent use.
+type syscallTracker struct {
+	maxSpansPerThread int
+	mutex             sync.Mutex
+	threads           map[uint32]*threadSyscalls
+}
+
+func newSyscallTracker(maxSpansPerThread int) *syscallTracker {
+	return &syscallTracker{
+		maxSpansPerThread: maxSpansPerThread,
+		threads:           make(map[uint32]*threadSyscalls),
+	}
+}
+
+// record stores the syscall if it is a read, write or poll.
+func (t *syscallTracker) record(evt *model.KindlingEvent) {
+	kind, ok := getSyscallKind(evt.Name)
+	if !ok {
+		return
+	}
+	tid := evt.GetTid()
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	thread, ok := t.threads[tid]
+	if !ok {
+		thread = &threadSyscalls{spans: make([]syscallSpan, 0, t.maxSpansPerThread)}
+		t.threads[tid] = thread
+	}
+	span := syscallSpan{kind: kind, start: evt.GetStartTime(), end: evt.Timestamp}
+	if len(thread.spans) < t.maxSpansPerThread {
+		thread.spans = append(thread.spans, span)
+	} else {
+		thread.spans[thread.next] = span
+		thread.next = (thread.next + 1) % t.maxSpansPerThread
+	}
+	thread.lastTs = evt.Timestamp
+}
+
+// breakdown returns the time the thread spent in each kind of syscalls within [start, end].
+func (t *syscallTracker) breakdown(tid uint32, start uint64, end uint64) (read uint64, write uint64, poll uint64) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	thread, ok := t.threads[tid]
+	if !ok {
+		return
+	}
+	for _, span := range thread.spans {
+		spanStart, spanEnd := span.start, span.end
+		if spanStart < start {
+			spanStart = start
+		}
+		if spanEnd > end {
+			spanEnd = end
+		}
+		if spanEnd <= spanStart {
+			continue
+		}
+		switch span.kind {
+		case syscallRead:
+			read += spanEnd - spanStart
+		case syscallWrite:
+			write += spanEnd - spanStart
+		case syscallPoll:
+			poll += spanEnd - spanStart
+		}
+	}
+	return
+}
+
+// clean removes the threads that have no syscalls since the timestamp.
+func (t *syscallTracker) clean(beforeTs uint64) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	for tid, thread := range t.threads {
+		if thread.lastTs < beforeTs {
+			delete(t.threads, tid)
+		}
+	}
+}
+
+// addSyscallBreakdown adds the time the request thread spent in read, write and poll syscalls
+// between receiving the request and sending the response. The rest of the duration is spent on
+// the computation of the application or on the syscalls not subscribed.
+func (na *NetworkAnalyzer) addSyscallBreakdown(labels *model.AttributeMap, mps *messagePairs) {
+	if mps.requests == nil || mps.responses == nil {
+		return
+	}
+	requestEvt := mps.requests.event
+	read, write, poll := na.syscallTracker.breakdown(requestEvt.GetTid(), requestEvt.GetStartTime(), mps.responses.getLastTimestamp())
+	labels.UpdateAddIntValue(constlabels.SyscallReadTime, int64(read))
+	labels.UpdateAddIntValue(constlabels.SyscallWriteTime, int64(write))
+	labels.UpdateAddIntValue(constlabels.SyscallPollTime, int64(poll))
+}
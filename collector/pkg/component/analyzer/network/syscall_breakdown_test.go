@@ -0,0 +1,60 @@
+package network
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+)
+
+func newSyscallEvent(name string, tid uint32, end uint64, latency uint64) *model.KindlingEvent {
+	return &model.KindlingEvent{
+		Name:      name,
+		Timestamp: end,
+		Latency:   latency,
+		Ctx: model.Context{
+			ThreadInfo: model.Thread{Pid: 100, Tid: tid},
+		},
+	}
+}
+
+func TestSyscallTracker_Breakdown(t *testing.T) {
+	tracker := newSyscallTracker(10)
+	// The request is read by the thread 101 in [100, 200].
+	tracker.record(newSyscallEvent(constnames.ReadEvent, 101, 200, 100))
+	// The thread waits for the database in [300, 800] and reads the result in [800, 850].
+	tracker.record(newSyscallEvent(constnames.WriteEvent, 101, 300, 50))
+	tracker.record(newSyscallEvent(constnames.EpollWaitEvent, 101, 800, 500))
+	tracker.record(newSyscallEvent(constnames.RecvFromEvent, 101, 850, 50))
+	// The syscalls of other threads or the other kinds are not counted.
+	tracker.record(newSyscallEvent(constnames.WriteEvent, 102, 500, 100))
+	tracker.record(newSyscallEvent(constnames.ConnectEvent, 101, 900, 10))
+
+	read, write, poll := tracker.breakdown(101, 150, 1000)
+	assert.Equal(t, uint64(50+50), read)
+	assert.Equal(t, uint64(50), write)
+	assert.Equal(t, uint64(500), poll)
+
+	read, write, poll = tracker.breakdown(103, 0, 1000)
+	assert.Zero(t, read+write+poll)
+}
+
+func TestSyscallTracker_RingBuffer(t *testing.T) {
+	tracker := newSyscallTracker(2)
+	tracker.record(newSyscallEvent(constnames.ReadEvent, 101, 100, 10))
+	tracker.record(newSyscallEvent(constnames.ReadEvent, 101, 200, 10))
+	tracker.record(newSyscallEvent(constnames.WriteEvent, 101, 300, 10))
+
+	// The oldest syscall is overwritten.
+	read, write, _ := tracker.breakdown(101, 0, 1000)
+	assert.Equal(t, uint64(10), read)
+	assert.Equal(t, uint64(10), write)
+
+	tracker.clean(250)
+	_, ok := tracker.threads[101]
+	assert.True(t, ok)
+	tracker.clean(301)
+	assert.Empty(t, tracker.threads)
+}
@@ -0,0 +1,83 @@
+package network
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+// threadNameTTL is how long a resolved thread name is trusted. The tid could be reused by
+// another thread after the thread exits, so the names are re-read periodically.
+const threadNameTTL = 60 * time.Second
+
+type threadKey struct {
+	pid uint32
+	tid uint32
+}
+
+type threadNameEntry struct {
+	name     string
+	expireAt time.Time
+}
+
+// threadNameResolver reads the thread names from /proc/<pid>/task/<tid>/comm and caches them.
+// It is safe for concurrent use.
+type threadNameResolver struct {
+	procRoot string
+	cache    sync.Map
+}
+
+func newThreadNameResolver(procRoot string) *threadNameResolver {
+	return &threadNameResolver{procRoot: procRoot}
+}
+
+// resolve returns the name of the thread, or an empty string if the thread doesn't exist.
+func (r *threadNameResolver) resolve(pid uint32, tid uint32, now time.Time) string {
+	key := threadKey{pid: pid, tid: tid}
+	if entry, ok := r.cache.Load(key); ok && now.Before(entry.(*threadNameEntry).expireAt) {
+		return entry.(*threadNameEntry).name
+	}
+	// The failures are cached as well to avoid reading the exited threads repeatedly.
+	var name string
+	commPath := filepath.Join(r.procRoot, strconv.Itoa(int(pid)), "task", strconv.Itoa(int(tid)), "comm")
+	if content, err := os.ReadFile(commPath); err == nil {
+		name = strings.TrimSpace(string(content))
+	}
+	r.cache.Store(key, &threadNameEntry{name: name, expireAt: now.Add(threadNameTTL)})
+	return name
+}
+
+// clean removes the expired names.
+func (r *threadNameResolver) clean(now time.Time) {
+	r.cache.Range(func(k, v interface{}) bool {
+		if !now.Before(v.(*threadNameEntry).expireAt) {
+			r.cache.Delete(k)
+		}
+		return true
+	})
+}
+
+// addThreadNames adds the names of the threads that read the request and wrote the response.
+// It does nothing if the thread name resolution is disabled.
+func (na *NetworkAnalyzer) addThreadNames(labels *model.AttributeMap, request *model.KindlingEvent, response *model.KindlingEvent) {
+	if na.threadNameResolver == nil {
+		return
+	}
+	now := time.Now()
+	if request != nil {
+		labels.UpdateAddStringValue(constlabels.RequestThreadName, na.threadNameResolver.resolve(request.GetPid(), request.GetTid(), now))
+	} else {
+		labels.UpdateAddStringValue(constlabels.RequestThreadName, constlabels.STR_EMPTY)
+	}
+	if response != nil {
+		labels.UpdateAddStringValue(constlabels.ResponseThreadName, na.threadNameResolver.resolve(response.GetPid(), response.GetTid(), now))
+	} else {
+		labels.UpdateAddStringValue(constlabels.ResponseThreadName, constlabels.STR_EMPTY)
+	}
+}
@@ -0,0 +1,59 @@
+package network
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+func writeThreadComm(t *testing.T, procRoot string, pid string, tid string, comm string) {
+	taskPath := filepath.Join(procRoot, pid, "task", tid)
+	assert.NoError(t, os.MkdirAll(taskPath, 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(taskPath, "comm"), []byte(comm+"\n"), 0644))
+}
+
+func TestThreadNameResolver(t *testing.T) {
+	procRoot := t.TempDir()
+	writeThreadComm(t, procRoot, "100", "101", "nioEventLoop-1")
+	r := newThreadNameResolver(procRoot)
+	now := time.Now()
+
+	assert.Equal(t, "nioEventLoop-1", r.resolve(100, 101, now))
+	assert.Equal(t, "", r.resolve(100, 102, now))
+
+	// The cached name is used until it expires.
+	writeThreadComm(t, procRoot, "100", "101", "worker-2")
+	assert.Equal(t, "nioEventLoop-1", r.resolve(100, 101, now.Add(time.Second)))
+	assert.Equal(t, "worker-2", r.resolve(100, 101, now.Add(threadNameTTL)))
+
+	r.clean(now.Add(2 * threadNameTTL))
+	count := 0
+	r.cache.Range(func(_, _ interface{}) bool {
+		count++
+		return true
+	})
+	assert.Equal(t, 0, count)
+}
+
+func TestAddThreadNames(t *testing.T) {
+	procRoot := t.TempDir()
+	writeThreadComm(t, procRoot, "100", "101", "http-nio-exec-1")
+	na := &NetworkAnalyzer{threadNameResolver: newThreadNameResolver(procRoot)}
+
+	request := &model.KindlingEvent{Ctx: model.Context{ThreadInfo: model.Thread{Pid: 100, Tid: 101}}}
+	labels := model.NewAttributeMap()
+	na.addThreadNames(labels, request, nil)
+	assert.Equal(t, "http-nio-exec-1", labels.GetStringValue(constlabels.RequestThreadName))
+	assert.Equal(t, "", labels.GetStringValue(constlabels.ResponseThreadName))
+
+	// Nothing is added if it is disabled.
+	labels = model.NewAttributeMap()
+	(&NetworkAnalyzer{}).addThreadNames(labels, request, nil)
+	assert.False(t, labels.HasAttribute(constlabels.RequestThreadName))
+}
@@ -0,0 +1,116 @@
+package network
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constvalues"
+)
+
+type threadPoolKey struct {
+	pid         int64
+	comm        string
+	containerId string
+}
+
+type threadPoolStats struct {
+	tids map[int64]struct{}
+	// busyTime is the total latency of the requests in nanoseconds.
+	busyTime int64
+}
+
+// threadPoolTracker collects the threads handling the server-side requests of each process within an
+// interval. The worker pool is saturated if its threads are busy with the requests all the time, i.e.
+// the total latency of the requests is close to the interval multiplied by the number of the threads.
+// It is safe for concurrent use.
+type threadPoolTracker struct {
+	mutex sync.Mutex
+	stats map[threadPoolKey]*threadPoolStats
+}
+
+func newThreadPoolTracker() *threadPoolTracker {
+	return &threadPoolTracker{
+		stats: make(map[threadPoolKey]*threadPoolStats),
+	}
+}
+
+// observe records the threads reading and writing the server-side request.
+func (t *threadPoolTracker) observe(record *model.DataGroup) {
+	labels := record.Labels
+	if !labels.GetBoolValue(constlabels.IsServer) {
+		return
+	}
+	key := threadPoolKey{
+		pid:         labels.GetIntValue(constlabels.Pid),
+		comm:        labels.GetStringValue(constlabels.Comm),
+		containerId: labels.GetStringValue(constlabels.ContainerId),
+	}
+	var latency int64
+	if metric, ok := record.GetMetric(constvalues.RequestTotalTime); ok {
+		latency = metric.GetInt().Value
+	}
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	stats, ok := t.stats[key]
+	if !ok {
+		stats = &threadPoolStats{tids: make(map[int64]struct{})}
+		t.stats[key] = stats
+	}
+	for _, tid := range []int64{labels.GetIntValue(constlabels.RequestTid), labels.GetIntValue(constlabels.ResponseTid)} {
+		if tid > 0 {
+			stats.tids[tid] = struct{}{}
+		}
+	}
+	stats.busyTime += latency
+}
+
+// flush returns the records of the processes observed within the interval and resets the stats.
+// The saturation is the percentage of the interval the threads are busy; it exceeds 100 if the
+// requests overlap on the threads, e.g. the event loops of the asynchronous servers.
+func (t *threadPoolTracker) flush(now time.Time, interval time.Duration) []*model.DataGroup {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	timestamp := uint64(now.UnixNano())
+	records := make([]*model.DataGroup, 0, len(t.stats))
+	for key, stats := range t.stats {
+		threads := int64(len(stats.tids))
+		if threads == 0 {
+			continue
+		}
+		labels := model.NewAttributeMap()
+		labels.AddIntValue(constlabels.Pid, key.pid)
+		labels.AddStringValue(constlabels.Comm, key.comm)
+		labels.AddStringValue(constlabels.ContainerId, key.containerId)
+		records = append(records, model.NewDataGroup(constnames.ThreadPoolMetricGroupName, labels, timestamp,
+			model.NewIntMetric(constnames.ProcessHandlingThreadsMetric, threads),
+			model.NewIntMetric(constnames.ProcessThreadSaturationMetric, stats.busyTime*100/(int64(interval)*threads))))
+	}
+	t.stats = make(map[threadPoolKey]*threadPoolStats)
+	return records
+}
+
+func (na *NetworkAnalyzer) observeThreadPool(record *model.DataGroup) {
+	if na.threadPoolTracker != nil {
+		na.threadPoolTracker.observe(record)
+	}
+}
+
+// reportThreadPool reports the handling threads and the saturation of each process periodically.
+func (na *NetworkAnalyzer) reportThreadPool() {
+	interval := na.cfg.getThreadPoolInterval()
+	timer := time.NewTicker(interval)
+	for {
+		select {
+		case <-timer.C:
+			for _, record := range na.threadPoolTracker.flush(time.Now(), interval) {
+				na.consume(record)
+			}
+		case <-na.stopChan:
+			timer.Stop()
+			return
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constvalues"
+)
+
+func newThreadPoolRecord(isServer bool, requestTid int64, responseTid int64, latency time.Duration) *model.DataGroup {
+	labels := model.NewAttributeMap()
+	labels.AddIntValue(constlabels.Pid, 100)
+	labels.AddStringValue(constlabels.Comm, "java")
+	labels.AddStringValue(constlabels.ContainerId, "c1")
+	labels.AddIntValue(constlabels.RequestTid, requestTid)
+	labels.AddIntValue(constlabels.ResponseTid, responseTid)
+	labels.AddBoolValue(constlabels.IsServer, isServer)
+	return model.NewDataGroup(constnames.NetRequestMetricGroupName, labels, 0,
+		model.NewIntMetric(constvalues.RequestTotalTime, int64(latency)))
+}
+
+func TestThreadPoolTracker(t *testing.T) {
+	tracker := newThreadPoolTracker()
+	tracker.observe(newThreadPoolRecord(true, 11, 11, 4*time.Second))
+	tracker.observe(newThreadPoolRecord(true, 12, 13, 6*time.Second))
+	tracker.observe(newThreadPoolRecord(true, 11, 11, 5*time.Second))
+	// The client-side requests are not handled by the worker pool.
+	tracker.observe(newThreadPoolRecord(false, 14, 14, 10*time.Second))
+
+	records := tracker.flush(time.Now(), 5*time.Second)
+	if assert.Len(t, records, 1) {
+		record := records[0]
+		assert.Equal(t, constnames.ThreadPoolMetricGroupName, record.Name)
+		assert.Equal(t, int64(100), record.Labels.GetIntValue(constlabels.Pid))
+		assert.Equal(t, "c1", record.Labels.GetStringValue(constlabels.ContainerId))
+		threads, ok := record.GetMetric(constnames.ProcessHandlingThreadsMetric)
+		if assert.True(t, ok) {
+			assert.Equal(t, int64(3), threads.GetInt().Value)
+		}
+		// 15s of requests over 3 threads in 5s.
+		saturation, ok := record.GetMetric(constnames.ProcessThreadSaturationMetric)
+		if assert.True(t, ok) {
+			assert.Equal(t, int64(100), saturation.GetInt().Value)
+		}
+	}
+	// The stats are reset after each interval.
+	assert.Empty(t, tracker.flush(time.Now(), 5*time.Second))
+}
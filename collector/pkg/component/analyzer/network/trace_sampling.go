@@ -0,0 +1,31 @@
+package network
+
+import (
+	"math/rand"
+
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+// SetNormalSamplingRate makes the analyzer decide whether the normal requests are sampled as traces
+// with the rate of the aggregateprocessor, ranging from 0 to 100. The decision is made before the
+// records are built, so the payloads of the requests sampled away are never built, and is passed to
+// the aggregateprocessor with the label "trace_sampled". It must be called before the analyzer starts.
+func (na *NetworkAnalyzer) SetNormalSamplingRate(rate int) {
+	na.sampleNormalRequests = true
+	na.normalSamplingRate = rate
+}
+
+// sampleNormalRequest decides whether the normal request is sampled as a trace and returns false if
+// it is sampled away, in which case its payloads are not needed. The labels must carry the slowness
+// and the protocol errors. Only the normal requests are decided here, as the slow and failed ones are
+// sampled by their own rates later.
+func (na *NetworkAnalyzer) sampleNormalRequest(labels *model.AttributeMap, noResponse bool) bool {
+	if !na.sampleNormalRequests || noResponse || labels.GetBoolValue(constlabels.IsSlow) ||
+		labels.GetBoolValue(constlabels.IsError) || labels.GetIntValue(constlabels.ErrorType) > constlabels.NoError {
+		return true
+	}
+	sampled := rand.Intn(100) < na.normalSamplingRate
+	labels.UpdateAddBoolValue(constlabels.TraceSampled, sampled)
+	return sampled
+}
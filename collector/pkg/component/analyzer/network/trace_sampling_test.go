@@ -0,0 +1,49 @@
+package network
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+)
+
+func TestGetRecords_NormalSampling(t *testing.T) {
+	na := &NetworkAnalyzer{cfg: NewDefaultConfig(), dataGroupPool: &NoCacheDataGroupPool{}}
+	mps := &messagePairs{
+		requests:  newEvents(newServerEvent(constnames.ReadEvent, 5, 1000, 10), 1000),
+		responses: newEvents(newServerEvent(constnames.WriteEvent, 5, 2000, 10), 1000),
+	}
+
+	// The payloads are built and the aggregator decides if the analyzer doesn't sample.
+	records := na.getRecords(mps, protocol.HTTP, model.NewAttributeMap())
+	assert.True(t, records[0].Labels.HasAttribute(constlabels.RequestPayload))
+	assert.False(t, records[0].Labels.HasAttribute(constlabels.TraceSampled))
+
+	na.SetNormalSamplingRate(0)
+	records = na.getRecords(mps, protocol.HTTP, model.NewAttributeMap())
+	assert.False(t, records[0].Labels.HasAttribute(constlabels.RequestPayload))
+	assert.False(t, records[0].Labels.HasAttribute(constlabels.ResponsePayload))
+	assert.True(t, records[0].Labels.HasAttribute(constlabels.TraceSampled))
+	assert.False(t, records[0].Labels.GetBoolValue(constlabels.TraceSampled))
+
+	// The failed requests are sampled by the aggregator with their own rate.
+	failed := model.NewAttributeMap()
+	failed.AddBoolValue(constlabels.IsError, true)
+	failed.AddIntValue(constlabels.ErrorType, int64(constlabels.ProtocolError))
+	records = na.getRecords(mps, protocol.HTTP, failed)
+	assert.True(t, records[0].Labels.HasAttribute(constlabels.RequestPayload))
+	assert.False(t, records[0].Labels.HasAttribute(constlabels.TraceSampled))
+	records = na.getRecords(&messagePairs{requests: mps.requests}, protocol.HTTP, model.NewAttributeMap())
+	assert.True(t, records[0].Labels.HasAttribute(constlabels.RequestPayload))
+	assert.False(t, records[0].Labels.HasAttribute(constlabels.TraceSampled))
+
+	na.SetNormalSamplingRate(100)
+	mp := &messagePair{request: mps.requests.event, response: mps.responses.event}
+	record := na.getRecordWithSinglePair(mp, protocol.HTTP, model.NewAttributeMap())
+	assert.True(t, record.Labels.HasAttribute(constlabels.RequestPayload))
+	assert.True(t, record.Labels.GetBoolValue(constlabels.TraceSampled))
+}
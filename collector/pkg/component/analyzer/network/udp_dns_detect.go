@@ -0,0 +1,65 @@
+package network
+
+import (
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/dns"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+)
+
+const (
+	mdnsPort  = 5353
+	llmnrPort = 5355
+)
+
+// getMulticastDnsProtocol returns MDNS or LLMNR if the UDP event is sent from or to their ports. Their
+// queries are multicast to the local link, e.g. 224.0.0.251:5353, instead of being sent to a resolver.
+func getMulticastDnsProtocol(evt *model.KindlingEvent) string {
+	switch {
+	case evt.GetDport() == mdnsPort || evt.GetSport() == mdnsPort:
+		return protocol.MDNS
+	case evt.GetDport() == llmnrPort || evt.GetSport() == llmnrPort:
+		return protocol.LLMNR
+	}
+	return ""
+}
+
+// getDnsRecordProtocol returns the protocol label of the record built from the DNS event.
+func getDnsRecordProtocol(evt *model.KindlingEvent) string {
+	if multicastProtocol := getMulticastDnsProtocol(evt); multicastProtocol != "" {
+		return multicastProtocol
+	}
+	return protocol.DNS
+}
+
+// getUdpDnsProtocol returns the protocol the UDP event is parsed as, i.e. DNS, MDNS or LLMNR, or the empty
+// string if it is not parsed as DNS. The mDNS and LLMNR events are dropped unless KeepMulticastDns is enabled,
+// as they are not resolved by the DNS servers.
+func (na *NetworkAnalyzer) getUdpDnsProtocol(evt *model.KindlingEvent) string {
+	if multicastProtocol := getMulticastDnsProtocol(evt); multicastProtocol != "" {
+		if !na.cfg.KeepMulticastDns {
+			return ""
+		}
+		return multicastProtocol
+	}
+	if na.isUdpDnsEvent(evt) {
+		return protocol.DNS
+	}
+	return ""
+}
+
+// isUdpDnsEvent returns true if the UDP event should be parsed as DNS. The ports configured with the
+// DNS key are always DNS, while the ones configured with other keys never are. The payloads on the
+// other ports are detected by their content if DetectUdpDns is enabled, e.g. Consul DNS on 8600.
+func (na *NetworkAnalyzer) isUdpDnsEvent(evt *model.KindlingEvent) bool {
+	if protocolName, ok := na.staticPortMap[evt.GetDport()]; ok {
+		return protocolName == protocol.DNS
+	}
+	if !na.cfg.DetectUdpDns {
+		return false
+	}
+	isRequest, err := evt.IsRequest()
+	if err != nil {
+		return false
+	}
+	return dns.IsDnsMessage(evt.GetData(), !isRequest)
+}
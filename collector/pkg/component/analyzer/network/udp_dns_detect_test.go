@@ -0,0 +1,118 @@
+package network
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol/factory"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+)
+
+var (
+	// The query of "consul.service.consul." A.
+	dnsQuery = []byte("\x12\x34\x01\x00\x00\x01\x00\x00\x00\x00\x00\x00" +
+		"\x06consul\x07service\x06consul\x00\x00\x01\x00\x01")
+	dnsAnswer = []byte("\x12\x34\x81\x80\x00\x01\x00\x01\x00\x00\x00\x00" +
+		"\x06consul\x07service\x06consul\x00\x00\x01\x00\x01" +
+		"\xc0\x0c\x00\x01\x00\x01\x00\x00\x00\x3c\x00\x04\x0a\x00\x00\x01")
+)
+
+func newUdpClientEvent(name string, dport uint32, timestamp uint64, data []byte) *model.KindlingEvent {
+	evt := newServerEvent(name, 7, timestamp, 10)
+	evt.Ctx.FdInfo.Protocol = model.L4Proto_UDP
+	evt.Ctx.FdInfo.Role = false
+	evt.Ctx.FdInfo.Dport = dport
+	evt.UserAttributes[0] = model.KeyValue{Key: "res", ValueType: model.ValueType_INT64, Value: Int64ToBytes(int64(len(data)))}
+	evt.UserAttributes[1] = model.KeyValue{Key: "data", ValueType: model.ValueType_BYTEBUF, Value: data}
+	evt.ParamsNumber = 2
+	return evt
+}
+
+func TestIsUdpDnsEvent(t *testing.T) {
+	cfg := NewDefaultConfig()
+	na := &NetworkAnalyzer{
+		cfg:           cfg,
+		staticPortMap: map[uint32]string{53: protocol.DNS, 8125: protocol.NOSUPPORT},
+	}
+	assert.True(t, na.isUdpDnsEvent(newUdpClientEvent(constnames.SendToEvent, 53, 1000, []byte("not dns"))))
+	assert.False(t, na.isUdpDnsEvent(newUdpClientEvent(constnames.SendToEvent, 8600, 1000, dnsQuery)))
+
+	cfg.DetectUdpDns = true
+	assert.True(t, na.isUdpDnsEvent(newUdpClientEvent(constnames.SendToEvent, 8600, 1000, dnsQuery)))
+	assert.True(t, na.isUdpDnsEvent(newUdpClientEvent(constnames.RecvFromEvent, 8600, 2000, dnsAnswer)))
+	// The direction of the message must match the QR flag.
+	assert.False(t, na.isUdpDnsEvent(newUdpClientEvent(constnames.RecvFromEvent, 8600, 2000, dnsQuery)))
+	assert.False(t, na.isUdpDnsEvent(newUdpClientEvent(constnames.SendToEvent, 8600, 1000, []byte("foo.bar:1|c|#tag:value"))))
+	// The ports configured with other protocols are never detected.
+	assert.False(t, na.isUdpDnsEvent(newUdpClientEvent(constnames.SendToEvent, 8125, 1000, dnsQuery)))
+}
+
+func TestDetectUdpDns(t *testing.T) {
+	c := &queueTimeConsumer{}
+	cfg := NewDefaultConfig()
+	cfg.EnableConntrack = false
+	cfg.DetectUdpDns = true
+	na := &NetworkAnalyzer{
+		cfg:               cfg,
+		nextConsumers:     []consumer.Consumer{c},
+		dataGroupPool:     &NoCacheDataGroupPool{},
+		parserFactory:     factory.NewParserFactory(),
+		parserCostSampler: analyzer.NewCostSampler(analyzer.DefaultCostSampleRate),
+		telemetry:         component.NewDefaultTelemetryTools(),
+		staticPortMap:     map[uint32]string{},
+	}
+	newSelfMetrics(na.telemetry.MeterProvider, na)
+	na.udpDnsParser = na.parserFactory.GetUdpDnsParser()
+
+	assert.NoError(t, na.processEvent(newUdpClientEvent(constnames.SendToEvent, 8600, 1000, dnsQuery)))
+	assert.NoError(t, na.processEvent(newUdpClientEvent(constnames.RecvFromEvent, 8600, 2000, dnsAnswer)))
+	if assert.Len(t, c.dataGroups, 1) {
+		labels := c.dataGroups[0].Labels
+		assert.Equal(t, protocol.DNS, labels.GetStringValue(constlabels.Protocol))
+		assert.Equal(t, "consul.service.consul.", labels.GetStringValue(constlabels.DnsDomain))
+		assert.Equal(t, "10.0.0.1", labels.GetStringValue(constlabels.DnsIp))
+		assert.Equal(t, "consul", labels.GetStringValue(constlabels.ServiceDiscovery))
+		assert.Equal(t, "dns_query", labels.GetStringValue(constlabels.ServiceDiscoveryOp))
+	}
+}
+
+func TestMulticastDns(t *testing.T) {
+	c := &queueTimeConsumer{}
+	cfg := NewDefaultConfig()
+	cfg.EnableConntrack = false
+	na := &NetworkAnalyzer{
+		cfg:               cfg,
+		nextConsumers:     []consumer.Consumer{c},
+		dataGroupPool:     &NoCacheDataGroupPool{},
+		parserFactory:     factory.NewParserFactory(),
+		parserCostSampler: analyzer.NewCostSampler(analyzer.DefaultCostSampleRate),
+		telemetry:         component.NewDefaultTelemetryTools(),
+		staticPortMap:     map[uint32]string{53: protocol.DNS, 5353: protocol.DNS},
+	}
+	newSelfMetrics(na.telemetry.MeterProvider, na)
+	na.udpDnsParser = na.parserFactory.GetUdpDnsParser()
+
+	// The multicast queries are dropped by default, even if the port is configured as DNS.
+	assert.Equal(t, "", na.getUdpDnsProtocol(newUdpClientEvent(constnames.SendToEvent, 5353, 1000, dnsQuery)))
+	assert.NoError(t, na.processEvent(newUdpClientEvent(constnames.SendToEvent, 5353, 1000, dnsQuery)))
+	assert.NoError(t, na.processEvent(newUdpClientEvent(constnames.RecvFromEvent, 5353, 2000, dnsAnswer)))
+	assert.Empty(t, c.dataGroups)
+
+	cfg.KeepMulticastDns = true
+	assert.Equal(t, protocol.LLMNR, na.getUdpDnsProtocol(newUdpClientEvent(constnames.SendToEvent, 5355, 1000, dnsQuery)))
+	assert.Equal(t, protocol.DNS, na.getUdpDnsProtocol(newUdpClientEvent(constnames.SendToEvent, 53, 1000, dnsQuery)))
+	assert.NoError(t, na.processEvent(newUdpClientEvent(constnames.SendToEvent, 5353, 1000, dnsQuery)))
+	assert.NoError(t, na.processEvent(newUdpClientEvent(constnames.RecvFromEvent, 5353, 2000, dnsAnswer)))
+	if assert.Len(t, c.dataGroups, 1) {
+		labels := c.dataGroups[0].Labels
+		assert.Equal(t, protocol.MDNS, labels.GetStringValue(constlabels.Protocol))
+		assert.Equal(t, "consul.service.consul.", labels.GetStringValue(constlabels.DnsDomain))
+	}
+}
@@ -0,0 +1,47 @@
+package network
+
+import (
+	"github.com/Kindling-project/kindling/collector/pkg/metadata/kubernetes"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constvalues"
+)
+
+type WorkloadOverrideConfig struct {
+	Enable bool `mapstructure:"enable"`
+}
+
+// applyWorkloadOverrides applies the annotations of the pod the record is observed in, see
+// kubernetes.AnalyzerOverrides. The slow threshold is compared with the latency excluding the
+// connection time like the thresholds of the config file.
+func (na *NetworkAnalyzer) applyWorkloadOverrides(record *model.DataGroup) {
+	if na.podMetadata == nil {
+		return
+	}
+	containerId := record.Labels.GetStringValue(constlabels.ContainerId)
+	if containerId == "" {
+		return
+	}
+	pod, ok := na.podMetadata.GetPodByContainerId(containerId)
+	if !ok || pod.AnalyzerOverrides == nil {
+		return
+	}
+	overrides := pod.AnalyzerOverrides
+	// The requests without responses are never slow.
+	if overrides.SlowThreshold > 0 && record.Labels.HasAttribute(constlabels.EndTimestamp) {
+		duration := getIntMetric(record, constvalues.RequestTotalTime) - getIntMetric(record, constvalues.ConnectTime)
+		record.Labels.UpdateAddBoolValue(constlabels.IsSlow, duration >= int64(overrides.SlowThreshold))
+	}
+	if overrides.DisablePayload {
+		record.Labels.UpdateAddStringValue(constlabels.RequestPayload, "")
+		record.Labels.UpdateAddStringValue(constlabels.ResponsePayload, "")
+	}
+}
+
+// newPodMetadata returns the metadata of the pods if the overrides are enabled, otherwise nil.
+func newPodMetadata(config *WorkloadOverrideConfig) *kubernetes.K8sMetaDataCache {
+	if config == nil || !config.Enable {
+		return nil
+	}
+	return kubernetes.MetaDataCache
+}
@@ -0,0 +1,70 @@
+package network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/metadata/kubernetes"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constvalues"
+)
+
+func newOverrideRecord(containerId string, connectTime time.Duration, duration time.Duration, answered bool) *model.DataGroup {
+	labels := model.NewAttributeMap()
+	labels.AddStringValue(constlabels.ContainerId, containerId)
+	labels.AddBoolValue(constlabels.IsSlow, false)
+	labels.AddStringValue(constlabels.RequestPayload, "GET /orders HTTP/1.1")
+	labels.AddStringValue(constlabels.ResponsePayload, "HTTP/1.1 200 OK")
+	if answered {
+		labels.AddIntValue(constlabels.EndTimestamp, 1000)
+	}
+	return model.NewDataGroup(constnames.NetRequestMetricGroupName, labels, 0,
+		model.NewIntMetric(constvalues.ConnectTime, int64(connectTime)),
+		model.NewIntMetric(constvalues.RequestTotalTime, int64(connectTime+duration)))
+}
+
+func TestApplyWorkloadOverrides(t *testing.T) {
+	metadata := kubernetes.New()
+	metadata.AddByContainerId("aaaaaaaaaaaa", &kubernetes.K8sContainerInfo{RefPodInfo: &kubernetes.K8sPodInfo{
+		AnalyzerOverrides: &kubernetes.AnalyzerOverrides{SlowThreshold: 200 * time.Millisecond},
+	}})
+	metadata.AddByContainerId("bbbbbbbbbbbb", &kubernetes.K8sContainerInfo{RefPodInfo: &kubernetes.K8sPodInfo{
+		AnalyzerOverrides: &kubernetes.AnalyzerOverrides{DisablePayload: true},
+	}})
+	metadata.AddByContainerId("cccccccccccc", &kubernetes.K8sContainerInfo{RefPodInfo: &kubernetes.K8sPodInfo{}})
+	na := &NetworkAnalyzer{podMetadata: metadata}
+
+	// The connection time is excluded.
+	record := newOverrideRecord("aaaaaaaaaaaa", 150*time.Millisecond, 250*time.Millisecond, true)
+	na.applyWorkloadOverrides(record)
+	assert.True(t, record.Labels.GetBoolValue(constlabels.IsSlow))
+	assert.Equal(t, "GET /orders HTTP/1.1", record.Labels.GetStringValue(constlabels.RequestPayload))
+	record = newOverrideRecord("aaaaaaaaaaaa", 150*time.Millisecond, 100*time.Millisecond, true)
+	na.applyWorkloadOverrides(record)
+	assert.False(t, record.Labels.GetBoolValue(constlabels.IsSlow))
+	record = newOverrideRecord("aaaaaaaaaaaa", 0, 300*time.Millisecond, false)
+	na.applyWorkloadOverrides(record)
+	assert.False(t, record.Labels.GetBoolValue(constlabels.IsSlow))
+
+	record = newOverrideRecord("bbbbbbbbbbbb", 0, time.Second, true)
+	na.applyWorkloadOverrides(record)
+	assert.False(t, record.Labels.GetBoolValue(constlabels.IsSlow))
+	assert.Equal(t, "", record.Labels.GetStringValue(constlabels.RequestPayload))
+	assert.Equal(t, "", record.Labels.GetStringValue(constlabels.ResponsePayload))
+
+	for _, containerId := range []string{"cccccccccccc", "dddddddddddd", ""} {
+		record = newOverrideRecord(containerId, 0, time.Second, true)
+		na.applyWorkloadOverrides(record)
+		assert.False(t, record.Labels.GetBoolValue(constlabels.IsSlow))
+		assert.Equal(t, "HTTP/1.1 200 OK", record.Labels.GetStringValue(constlabels.ResponsePayload))
+	}
+
+	// Nothing is done if it is disabled.
+	record = newOverrideRecord("bbbbbbbbbbbb", 0, time.Second, true)
+	(&NetworkAnalyzer{podMetadata: newPodMetadata(NewDefaultConfig().WorkloadOverride)}).applyWorkloadOverrides(record)
+	assert.Equal(t, "HTTP/1.1 200 OK", record.Labels.GetStringValue(constlabels.ResponsePayload))
+}
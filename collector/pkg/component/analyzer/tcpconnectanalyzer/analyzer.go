@@ -25,6 +25,8 @@ type TcpConnectAnalyzer struct {
 
 	eventChannel   chan *model.KindlingEvent
 	connectMonitor *internal.ConnectMonitor
+	// poolMonitor is nil if the connection pool metrics are disabled.
+	poolMonitor *internal.PoolMonitor
 
 	stopCh chan bool
 
@@ -42,6 +44,9 @@ func New(cfg interface{}, telemetry *component.TelemetryTools, consumers []consu
 
 		connectMonitor: internal.NewConnectMonitor(telemetry.Logger),
 	}
+	if config.isConnectionPoolEnabled() {
+		ret.poolMonitor = internal.NewPoolMonitor(config.NeedProcessInfo)
+	}
 	conntracker, err := conntrackerpackge.NewConntracker(nil)
 	if err != nil {
 		telemetry.Logger.Warn("Conntracker cannot work as expected:", zap.Error(err))
@@ -56,6 +61,7 @@ func (a *TcpConnectAnalyzer) ConsumableEvents() []string {
 		constnames.ConnectEvent,
 		constnames.TcpConnectEvent,
 		constnames.TcpSetStateEvent,
+		constnames.TcpDropEvent,
 		constnames.WriteEvent,
 		constnames.WritevEvent,
 		constnames.SendMsgEvent,
@@ -67,10 +73,19 @@ func (a *TcpConnectAnalyzer) ConsumableEvents() []string {
 func (a *TcpConnectAnalyzer) Start() error {
 	go func() {
 		scanTcpStateTicker := time.NewTicker(time.Duration(a.config.WaitEventSecond/3) * time.Second)
+		// The channel is nil and never ready if the connection pool metrics are disabled.
+		var poolTickerCh <-chan time.Time
+		if a.poolMonitor != nil {
+			poolTicker := time.NewTicker(time.Duration(a.config.ConnectionPool.Interval) * time.Second)
+			defer poolTicker.Stop()
+			poolTickerCh = poolTicker.C
+		}
 		for {
 			select {
 			case <-scanTcpStateTicker.C:
 				a.trimConnectionsWithTcpStat()
+			case <-poolTickerCh:
+				a.flushConnectionPool()
 			case event := <-a.eventChannel:
 				a.consumeChannelEvent(event)
 			case <-a.stopCh:
@@ -106,6 +121,8 @@ func (a *TcpConnectAnalyzer) consumeChannelEvent(event *model.KindlingEvent) {
 		connectStats, err = a.connectMonitor.ReadInTcpConnect(event)
 	case constnames.TcpSetStateEvent:
 		connectStats, err = a.connectMonitor.ReadInTcpSetState(event)
+	case constnames.TcpDropEvent:
+		err = a.connectMonitor.ReadInTcpDrop(event)
 	case constnames.WriteEvent:
 		fallthrough
 	case constnames.WritevEvent:
@@ -116,6 +133,9 @@ func (a *TcpConnectAnalyzer) consumeChannelEvent(event *model.KindlingEvent) {
 		if filterRequestEvent(event) {
 			return
 		}
+		if a.poolMonitor != nil && !event.GetCtx().GetFdInfo().GetRole() {
+			a.poolMonitor.AddRequest(event.GetPid(), event.GetComm(), event.GetContainerId(), event.GetDip(), event.GetDport())
+		}
 		connectStats, err = a.connectMonitor.ReadSendRequestSyscall(event)
 	}
 
@@ -128,10 +148,17 @@ func (a *TcpConnectAnalyzer) consumeChannelEvent(event *model.KindlingEvent) {
 		return
 	}
 
+	a.recordConnect(connectStats)
 	dataGroup := a.generateDataGroup(connectStats)
 	a.passThroughConsumers(dataGroup)
 }
 
+func (a *TcpConnectAnalyzer) recordConnect(connectStats *internal.ConnectionStats) {
+	if a.poolMonitor != nil && connectStats.StateMachine.GetCurrentState() == internal.Success {
+		a.poolMonitor.AddConnect(connectStats)
+	}
+}
+
 func filterRequestEvent(event *model.KindlingEvent) bool {
 	if event.Category != model.Category_CAT_NET {
 		return true
@@ -158,11 +185,54 @@ func filterRequestEvent(event *model.KindlingEvent) bool {
 func (a *TcpConnectAnalyzer) trimConnectionsWithTcpStat() {
 	connStats := a.connectMonitor.TrimConnectionsWithTcpStat(a.config.WaitEventSecond)
 	for _, connStat := range connStats {
+		a.recordConnect(connStat)
 		dataGroup := a.generateDataGroup(connStat)
 		a.passThroughConsumers(dataGroup)
 	}
 }
 
+// flushConnectionPool sends the connection reuse of each destination and the open sockets of
+// each process that has sent requests within the interval.
+func (a *TcpConnectAnalyzer) flushConnectionPool() {
+	stats, processes := a.poolMonitor.Flush()
+	timestamp := uint64(time.Now().UnixNano())
+	for key, stat := range stats {
+		a.passThroughConsumers(a.generatePoolDataGroup(key, stat, timestamp))
+	}
+	for _, process := range processes {
+		sockets, err := a.poolMonitor.CountOpenSockets(process.Pid)
+		if err != nil {
+			a.telemetry.Logger.Debug("Cannot count the open sockets:", zap.Uint32("pid", process.Pid), zap.Error(err))
+			continue
+		}
+		labels := model.NewAttributeMap()
+		labels.AddIntValue(constlabels.Pid, int64(process.Pid))
+		labels.AddStringValue(constlabels.Comm, process.Comm)
+		labels.AddStringValue(constlabels.ContainerId, process.ContainerId)
+		a.passThroughConsumers(model.NewDataGroup(constnames.ProcessSocketMetricGroupName, labels, timestamp,
+			model.NewIntMetric(constnames.ProcessOpenSocketsMetric, sockets)))
+	}
+}
+
+func (a *TcpConnectAnalyzer) generatePoolDataGroup(key internal.PoolKey, stat *internal.PoolStats, timestamp uint64) *model.DataGroup {
+	labels := model.NewAttributeMap()
+	labels.AddBoolValue(constlabels.IsServer, false)
+	if a.config.NeedProcessInfo {
+		labels.AddIntValue(constlabels.Pid, int64(key.Pid))
+		labels.AddStringValue(constlabels.Comm, key.Comm)
+	}
+	labels.AddStringValue(constlabels.ContainerId, key.ContainerId)
+	labels.AddStringValue(constlabels.DstIp, key.DstIP)
+	labels.AddIntValue(constlabels.DstPort, int64(key.DstPort))
+	metrics := make([]*model.Metric, 0, 3)
+	metrics = append(metrics, model.NewIntMetric(constnames.ConnectionPoolConnectTotalMetric, stat.Connects))
+	metrics = append(metrics, model.NewIntMetric(constnames.ConnectionPoolRequestTotalMetric, stat.Requests))
+	if ratio := stat.ReuseRatio(); ratio >= 0 {
+		metrics = append(metrics, model.NewIntMetric(constnames.ConnectionReuseRatioMetric, ratio))
+	}
+	return model.NewDataGroup(constnames.ConnectionPoolMetricGroupName, labels, timestamp, metrics...)
+}
+
 func (a *TcpConnectAnalyzer) passThroughConsumers(dataGroup *model.DataGroup) {
 	var retError error
 	for _, nextConsumer := range a.nextConsumers {
@@ -209,6 +279,7 @@ func (a *TcpConnectAnalyzer) generateLabels(connectStats *internal.ConnectionSta
 	} else {
 		labels.AddBoolValue(constlabels.Success, false)
 	}
+	labels.AddStringValue(constlabels.FailureReason, connectStats.GetFailureReason())
 
 	srcIp := connectStats.ConnKey.SrcIP
 	dstIp := connectStats.ConnKey.DstIP
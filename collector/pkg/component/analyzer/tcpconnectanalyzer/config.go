@@ -4,6 +4,14 @@ type Config struct {
 	ChannelSize     int  `mapstructure:"channel_size"`
 	WaitEventSecond int  `mapstructure:"wait_event_second"`
 	NeedProcessInfo bool `mapstructure:"need_process_info"`
+	// ConnectionPool reports the connection reuse of each destination and the open sockets of each process.
+	ConnectionPool *ConnectionPoolConfig `mapstructure:"connection_pool"`
+}
+
+type ConnectionPoolConfig struct {
+	Enable bool `mapstructure:"enable"`
+	// The unit is second.
+	Interval int `mapstructure:"interval"`
 }
 
 func NewDefaultConfig() *Config {
@@ -11,5 +19,13 @@ func NewDefaultConfig() *Config {
 		ChannelSize:     2000,
 		WaitEventSecond: 10,
 		NeedProcessInfo: false,
+		ConnectionPool: &ConnectionPoolConfig{
+			Enable:   false,
+			Interval: 15,
+		},
 	}
 }
+
+func (c *Config) isConnectionPoolEnabled() bool {
+	return c.ConnectionPool != nil && c.ConnectionPool.Enable && c.ConnectionPool.Interval > 0
+}
@@ -3,13 +3,13 @@ package internal
 import (
 	"fmt"
 	"os"
-	"time"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 
 	"github.com/Kindling-project/kindling/collector/pkg/component"
 	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/timeunit"
 )
 
 // ConnectMonitor reads in events related to TCP connect operations and updates its
@@ -155,6 +155,29 @@ func (c *ConnectMonitor) ReadInTcpConnect(event *model.KindlingEvent) (*Connecti
 	return connStats.StateMachine.ReceiveEvent(eventType, c.connMap)
 }
 
+// ReadInTcpDrop marks the connection in progress as dropped if the packet dropped belongs to it.
+func (c *ConnectMonitor) ReadInTcpDrop(event *model.KindlingEvent) error {
+	connKey, err := getConnKeyForTcpConnect(event)
+	if err != nil {
+		return err
+	}
+	// The sender of the packet dropped may be either side of the connection.
+	connStats, ok := c.connMap[connKey]
+	if !ok {
+		connStats, ok = c.connMap[connKey.reverse()]
+	}
+	if !ok {
+		return nil
+	}
+	if ce := c.logger.Check(zapcore.DebugLevel, "Receive tcp_drop event:"); ce != nil {
+		ce.Write(
+			zap.String("ConnKey", connStats.ConnKey.String()),
+		)
+	}
+	connStats.Dropped = true
+	return nil
+}
+
 const (
 	establishedState = 1
 )
@@ -219,13 +242,13 @@ func (c *ConnectMonitor) TrimConnectionsWithTcpStat(waitForEventSecond int) []*C
 	ret := make([]*ConnectionStats, 0, len(c.connMap))
 	// Only scan once for each Pid
 	pidTcpStateMap := make(map[uint32]NetSocketStateMap)
-	waitForEventNano := int64(waitForEventSecond) * 1000000000
-	timeNow := time.Now().UnixNano()
+	waitForEvent := timeunit.FromSeconds(waitForEventSecond)
+	timeNow := timeunit.Now()
 	for key, connStat := range c.connMap {
 		if connStat.Pid == 0 {
 			continue
 		}
-		if timeNow-int64(connStat.InitialTimestamp) < waitForEventNano {
+		if timeNow.Sub(timeunit.Timestamp(connStat.InitialTimestamp)) < waitForEvent {
 			// Still waiting for other events
 			continue
 		}
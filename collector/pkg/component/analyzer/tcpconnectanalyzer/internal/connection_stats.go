@@ -6,10 +6,33 @@ import (
 
 const (
 	// See <errno.h> in Linux
-	einprogress = -115
-	ealready    = -114
-	eisconn     = -106
-	eintr       = -4
+	einprogress  = -115
+	ealready     = -114
+	ehostunreach = -113
+	econnrefused = -111
+	etimedout    = -110
+	eisconn      = -106
+	enetunreach  = -101
+	eacces       = -13
+	eintr        = -4
+	eperm        = -1
+)
+
+// The probable causes of the failed connections.
+const (
+	// FailureRefused means the peer answered the SYN with a RST, e.g. no process listens on the port.
+	FailureRefused = "refused"
+	// FailureNoRoute means there is no route to the destination network or host.
+	FailureNoRoute = "no_route"
+	// FailurePolicyDenied means the connection is rejected locally, e.g. by a NetworkPolicy
+	// enforced with iptables REJECT or eBPF programs.
+	FailurePolicyDenied = "policy_denied"
+	// FailureDropped means the kernel dropped the packets of the connection.
+	FailureDropped = "dropped"
+	// FailureTimeout means the SYN was not answered, which is usually caused by a NetworkPolicy
+	// or a firewall dropping the packets silently.
+	FailureTimeout = "timeout"
+	FailureUnknown = "unknown"
 )
 
 const (
@@ -35,12 +58,38 @@ type ConnectionStats struct {
 	InitialTimestamp uint64
 	EndTimestamp     uint64
 	Code             int
+	// Dropped is true if the kernel dropped the packets of the connection before it was established.
+	Dropped bool
 }
 
 func (c *ConnectionStats) GetConnectDuration() int64 {
 	return int64(c.EndTimestamp - c.InitialTimestamp)
 }
 
+// GetFailureReason returns the probable cause of the failed connection, or an empty string
+// if the connection is established successfully.
+func (c *ConnectionStats) GetFailureReason() string {
+	if c.StateMachine.GetCurrentState() == Success {
+		return ""
+	}
+	switch c.Code {
+	case econnrefused:
+		return FailureRefused
+	case enetunreach, ehostunreach:
+		return FailureNoRoute
+	case eperm, eacces:
+		return FailurePolicyDenied
+	}
+	if c.Dropped {
+		return FailureDropped
+	}
+	if c.Code == etimedout || c.Code == 0 {
+		// No error is returned by the syscall, but the connection is never established.
+		return FailureTimeout
+	}
+	return FailureUnknown
+}
+
 type ConnKey struct {
 	SrcIP   string
 	SrcPort uint32
@@ -57,6 +106,15 @@ func (k *ConnKey) toSocketKey() SocketKey {
 	}
 }
 
+func (k *ConnKey) reverse() ConnKey {
+	return ConnKey{
+		SrcIP:   k.DstIP,
+		SrcPort: k.DstPort,
+		DstIP:   k.SrcIP,
+		DstPort: k.SrcPort,
+	}
+}
+
 func (k *ConnKey) String() string {
 	return fmt.Sprintf("src: %s:%d, dst: %s:%d", k.SrcIP, k.SrcPort, k.DstIP, k.DstPort)
 }
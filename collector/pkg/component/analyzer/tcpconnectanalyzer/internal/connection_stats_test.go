@@ -0,0 +1,74 @@
+package internal
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+)
+
+func TestConnectionStats_GetFailureReason(t *testing.T) {
+	tests := []struct {
+		name    string
+		state   StateType
+		code    int
+		dropped bool
+		want    string
+	}{
+		{name: "success", state: Success, code: 0, want: ""},
+		{name: "refused", state: Failure, code: econnrefused, want: FailureRefused},
+		{name: "network unreachable", state: Failure, code: enetunreach, want: FailureNoRoute},
+		{name: "host unreachable", state: Failure, code: ehostunreach, want: FailureNoRoute},
+		{name: "rejected by policy", state: Failure, code: eperm, want: FailurePolicyDenied},
+		{name: "refused but dropped", state: Failure, code: econnrefused, dropped: true, want: FailureRefused},
+		{name: "dropped", state: Failure, code: 0, dropped: true, want: FailureDropped},
+		{name: "timeout", state: Failure, code: etimedout, want: FailureTimeout},
+		{name: "no response", state: Failure, code: 0, want: FailureTimeout},
+		{name: "unknown", state: Failure, code: eintr, want: FailureUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			connStats := &ConnectionStats{Code: tt.code, Dropped: tt.dropped}
+			connStats.StateMachine = NewStateMachine(tt.state, createStatesResource(), connStats)
+			assert.Equal(t, tt.want, connStats.GetFailureReason())
+		})
+	}
+}
+
+func uint32ToBytes(value uint32) []byte {
+	bytes := make([]byte, 4)
+	binary.LittleEndian.PutUint32(bytes, value)
+	return bytes
+}
+
+func newTcpDropEvent(sip uint32, sport uint32, dip uint32, dport uint32) *model.KindlingEvent {
+	return &model.KindlingEvent{
+		Name: "tcp_drop",
+		UserAttributes: [16]model.KeyValue{
+			{Key: "sip", ValueType: model.ValueType_UINT32, Value: uint32ToBytes(sip)},
+			{Key: "sport", ValueType: model.ValueType_UINT32, Value: uint32ToBytes(sport)},
+			{Key: "dip", ValueType: model.ValueType_UINT32, Value: uint32ToBytes(dip)},
+			{Key: "dport", ValueType: model.ValueType_UINT32, Value: uint32ToBytes(dport)},
+		},
+		ParamsNumber: 4,
+	}
+}
+
+func TestConnectMonitor_ReadInTcpDrop(t *testing.T) {
+	monitor := NewConnectMonitor(component.NewDefaultTelemetryTools().Logger)
+	connKey := ConnKey{SrcIP: "10.0.0.1", SrcPort: 40000, DstIP: "10.0.0.2", DstPort: 80}
+	connStats := &ConnectionStats{ConnKey: connKey}
+	monitor.connMap[connKey] = connStats
+
+	// The packets sent by the peer don't match.
+	assert.NoError(t, monitor.ReadInTcpDrop(newTcpDropEvent(33554442, 80, 16777226, 40001)))
+	assert.False(t, connStats.Dropped)
+	// The SYN-ACK sent by the peer is dropped.
+	assert.NoError(t, monitor.ReadInTcpDrop(newTcpDropEvent(33554442, 80, 16777226, 40000)))
+	assert.True(t, connStats.Dropped)
+
+	assert.Error(t, monitor.ReadInTcpDrop(&model.KindlingEvent{Name: "tcp_drop"}))
+}
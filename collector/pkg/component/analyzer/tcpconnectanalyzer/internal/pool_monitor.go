@@ -0,0 +1,126 @@
+package internal
+
+import (
+	"os"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// PoolKey identifies the connections from one process to one destination.
+// Pid and Comm are empty if the process information is not needed.
+type PoolKey struct {
+	Pid         uint32
+	Comm        string
+	ContainerId string
+	DstIP       string
+	DstPort     uint32
+}
+
+// PoolStats counts the new connections and the requests sent to one destination.
+type PoolStats struct {
+	Connects int64
+	Requests int64
+}
+
+// ReuseRatio returns the percentage of the requests that are sent over the reused connections.
+// It is -1 if there is no request.
+func (s *PoolStats) ReuseRatio() int64 {
+	if s.Requests <= 0 {
+		return -1
+	}
+	if s.Connects >= s.Requests {
+		return 0
+	}
+	return (s.Requests - s.Connects) * 100 / s.Requests
+}
+
+// ProcessInfo is the process that has sent requests within the interval.
+type ProcessInfo struct {
+	Pid         uint32
+	Comm        string
+	ContainerId string
+}
+
+// PoolMonitor records the connection reuse of each destination and the processes
+// that have sent requests within an interval.
+// This is not thread safe to use.
+type PoolMonitor struct {
+	needProcessInfo bool
+	hostProcPath    string
+	stats           map[PoolKey]*PoolStats
+	processes       map[uint32]*ProcessInfo
+}
+
+func NewPoolMonitor(needProcessInfo bool) *PoolMonitor {
+	path, ok := os.LookupEnv(HostProc)
+	if !ok {
+		path = "/proc"
+	}
+	return &PoolMonitor{
+		needProcessInfo: needProcessInfo,
+		hostProcPath:    path,
+		stats:           make(map[PoolKey]*PoolStats),
+		processes:       make(map[uint32]*ProcessInfo),
+	}
+}
+
+func (m *PoolMonitor) getStats(pid uint32, comm string, containerId string, dstIp string, dstPort uint32) *PoolStats {
+	key := PoolKey{
+		ContainerId: containerId,
+		DstIP:       dstIp,
+		DstPort:     dstPort,
+	}
+	if m.needProcessInfo {
+		key.Pid = pid
+		key.Comm = comm
+	}
+	stats, ok := m.stats[key]
+	if !ok {
+		stats = &PoolStats{}
+		m.stats[key] = stats
+	}
+	if _, ok := m.processes[pid]; !ok {
+		m.processes[pid] = &ProcessInfo{Pid: pid, Comm: comm, ContainerId: containerId}
+	}
+	return stats
+}
+
+// AddConnect records a connection established successfully.
+func (m *PoolMonitor) AddConnect(connStats *ConnectionStats) {
+	m.getStats(connStats.Pid, connStats.Comm, connStats.ContainerId, connStats.ConnKey.DstIP, connStats.ConnKey.DstPort).Connects++
+}
+
+// AddRequest records a request sent from the client side. Each send syscall is treated as a request.
+func (m *PoolMonitor) AddRequest(pid uint32, comm string, containerId string, dstIp string, dstPort uint32) {
+	m.getStats(pid, comm, containerId, dstIp, dstPort).Requests++
+}
+
+// Flush returns the stats and the processes recorded since the last flush and resets them.
+func (m *PoolMonitor) Flush() (map[PoolKey]*PoolStats, map[uint32]*ProcessInfo) {
+	stats, processes := m.stats, m.processes
+	m.stats = make(map[PoolKey]*PoolStats)
+	m.processes = make(map[uint32]*ProcessInfo)
+	return stats, processes
+}
+
+// CountOpenSockets returns the number of the sockets opened by the process.
+func (m *PoolMonitor) CountOpenSockets(pid uint32) (int64, error) {
+	fdPath := path.Join(m.hostProcPath, strconv.Itoa(int(pid)), "fd")
+	entries, err := os.ReadDir(fdPath)
+	if err != nil {
+		return 0, err
+	}
+	var count int64
+	for _, entry := range entries {
+		link, err := os.Readlink(path.Join(fdPath, entry.Name()))
+		if err != nil {
+			// The fd may be closed after reading the directory.
+			continue
+		}
+		if strings.HasPrefix(link, "socket:") {
+			count++
+		}
+	}
+	return count, nil
+}
@@ -0,0 +1,69 @@
+package internal
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPoolStats_ReuseRatio(t *testing.T) {
+	tests := []struct {
+		name  string
+		stats PoolStats
+		want  int64
+	}{
+		{name: "no request", stats: PoolStats{Connects: 1, Requests: 0}, want: -1},
+		{name: "no reuse", stats: PoolStats{Connects: 10, Requests: 10}, want: 0},
+		{name: "more connects than requests", stats: PoolStats{Connects: 12, Requests: 10}, want: 0},
+		{name: "keep-alive", stats: PoolStats{Connects: 1, Requests: 100}, want: 99},
+		{name: "half", stats: PoolStats{Connects: 5, Requests: 10}, want: 50},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.stats.ReuseRatio())
+		})
+	}
+}
+
+func TestPoolMonitor_Flush(t *testing.T) {
+	m := NewPoolMonitor(false)
+	m.AddConnect(&ConnectionStats{Pid: 1, Comm: "java", ContainerId: "c1", ConnKey: ConnKey{DstIP: "10.0.0.2", DstPort: 80}})
+	for i := 0; i < 4; i++ {
+		m.AddRequest(1, "java", "c1", "10.0.0.2", 80)
+	}
+	m.AddRequest(2, "curl", "c1", "10.0.0.2", 80)
+
+	stats, processes := m.Flush()
+	// The process information is not a part of the key.
+	assert.Len(t, stats, 1)
+	stat := stats[PoolKey{ContainerId: "c1", DstIP: "10.0.0.2", DstPort: 80}]
+	if assert.NotNil(t, stat) {
+		assert.Equal(t, int64(1), stat.Connects)
+		assert.Equal(t, int64(5), stat.Requests)
+	}
+	assert.Len(t, processes, 2)
+
+	stats, processes = m.Flush()
+	assert.Empty(t, stats)
+	assert.Empty(t, processes)
+}
+
+func TestPoolMonitor_CountOpenSockets(t *testing.T) {
+	hostProc := t.TempDir()
+	fdPath := path.Join(hostProc, "100", "fd")
+	assert.NoError(t, os.MkdirAll(fdPath, 0755))
+	assert.NoError(t, os.Symlink("socket:[1001]", path.Join(fdPath, "3")))
+	assert.NoError(t, os.Symlink("socket:[1002]", path.Join(fdPath, "4")))
+	assert.NoError(t, os.Symlink("/var/log/app.log", path.Join(fdPath, "5")))
+
+	m := NewPoolMonitor(true)
+	m.hostProcPath = hostProc
+	count, err := m.CountOpenSockets(100)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	_, err = m.CountOpenSockets(200)
+	assert.Error(t, err)
+}
@@ -0,0 +1,40 @@
+package tools
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+/*
+ * Get the printable UTF-8 string. The control characters are escaped like Go, e.g. \r, \n and \x00,
+ * and the invalid bytes are escaped as \xff. The backslashes are escaped as well, so the string is lossless.
+ * The string is not longer than maxLength if it is positive, and the escape sequences are never split.
+ */
+func GetEscapedString(data []byte, maxLength int) string {
+	var builder strings.Builder
+	builder.Grow(len(data))
+	for len(data) > 0 {
+		r, size := utf8.DecodeRune(data)
+		var escaped string
+		switch {
+		case r == utf8.RuneError && size == 1:
+			escaped = `\x` + strconv.FormatUint(uint64(data[0])|0x100, 16)[1:]
+		case r == '\\':
+			escaped = `\\`
+		case unicode.IsPrint(r) || r == ' ':
+			escaped = string(data[:size])
+		default:
+			// The quotes added by QuoteRune are trimmed.
+			escaped = strconv.QuoteRuneToASCII(r)
+			escaped = escaped[1 : len(escaped)-1]
+		}
+		if maxLength > 0 && builder.Len()+len(escaped) > maxLength {
+			break
+		}
+		builder.WriteString(escaped)
+		data = data[size:]
+	}
+	return builder.String()
+}
@@ -0,0 +1,33 @@
+package tools
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func TestGetEscapedString(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      []byte
+		maxLength int
+		want      string
+	}{
+		{name: "printable", data: []byte("GET /orders 世界"), want: "GET /orders 世界"},
+		{name: "control characters", data: []byte("PING\r\n\t\x00\x1b"), want: `PING\r\n\t\x00\x1b`},
+		{name: "backslash", data: []byte(`a\b`), want: `a\\b`},
+		{name: "invalid bytes", data: []byte{'a', 0xff, 0xe4, 0xb8}, want: `a\xff\xe4\xb8`},
+		{name: "truncated", data: []byte("PING\r\n"), maxLength: 5, want: `PING`},
+		{name: "truncated rune", data: []byte("ab世界"), maxLength: 6, want: "ab世"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := GetEscapedString(tt.data, tt.maxLength)
+			if got != tt.want {
+				t.Errorf("GetEscapedString() = %q, want %q", got, tt.want)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("GetEscapedString() = %q is not valid UTF-8", got)
+			}
+		})
+	}
+}
@@ -5,3 +5,16 @@ import "github.com/Kindling-project/kindling/collector/pkg/model"
 type Consumer interface {
 	Consume(dataGroup *model.DataGroup) error
 }
+
+// Fanout passes the data groups to all the consumers in order, and returns the first error.
+type Fanout []Consumer
+
+func (f Fanout) Consume(dataGroup *model.DataGroup) error {
+	var firstErr error
+	for _, next := range f {
+		if err := next.Consume(dataGroup); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,34 @@
+package forwardexporter
+
+import "github.com/Kindling-project/kindling/collector/pkg/forward"
+
+type Config struct {
+	// Enable makes the agent forward the records of the network analyzers to the aggregator, instead of
+	// processing them on the node.
+	Enable bool `mapstructure:"enable"`
+	// Endpoint is the address of the gRPC receiver of the aggregator.
+	Endpoint string `mapstructure:"endpoint"`
+	// BatchSize is the max number of the records sent in a request.
+	BatchSize int `mapstructure:"batch_size"`
+	// FlushInterval is the max time the records wait before being sent. The unit is milliseconds.
+	FlushInterval int `mapstructure:"flush_interval"`
+	// QueueSize is the max number of the records waiting to be sent. The new records are dropped
+	// if the queue is full, e.g. the aggregator is unavailable.
+	QueueSize int `mapstructure:"queue_size"`
+	// Timeout is how long a batch waits for the window granted by the aggregator before being dropped.
+	// The unit is seconds.
+	Timeout int                `mapstructure:"timeout"`
+	TLS     *forward.TLSConfig `mapstructure:"tls"`
+}
+
+func NewDefaultConfig() *Config {
+	return &Config{
+		Enable:        false,
+		Endpoint:      "kindling-aggregator.kindling:9600",
+		BatchSize:     500,
+		FlushInterval: 1000,
+		QueueSize:     10000,
+		Timeout:       5,
+		TLS:           &forward.TLSConfig{},
+	}
+}
@@ -0,0 +1,93 @@
+package forwardexporter
+
+import (
+	"context"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/exporter"
+	"github.com/Kindling-project/kindling/collector/pkg/forward"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+)
+
+const Type = "forwardexporter"
+
+// ForwardExporter sends the records to the aggregator in batches, so the node agent doesn't add the
+// Kubernetes metadata or aggregate the records itself.
+type ForwardExporter struct {
+	config    *Config
+	client    *forward.Client
+	queue     chan *forward.Record
+	dropped   uint64
+	telemetry *component.TelemetryTools
+}
+
+func New(config interface{}, telemetry *component.TelemetryTools) exporter.Exporter {
+	cfg, _ := config.(*Config)
+	client, err := forward.NewClient(forward.ClientConfig{
+		Endpoint: cfg.Endpoint,
+		TLS:      cfg.TLS,
+		NodeName: os.Getenv("MY_NODE_NAME"),
+		NodeIp:   os.Getenv("MY_NODE_IP"),
+		Logger:   telemetry.GetZapLogger(),
+	})
+	if err != nil {
+		telemetry.Logger.Panicf("Can't create new forwardexporter: %v", err)
+	}
+	e := &ForwardExporter{
+		config:    cfg,
+		client:    client,
+		queue:     make(chan *forward.Record, cfg.QueueSize),
+		telemetry: telemetry,
+	}
+	go e.run()
+	return e
+}
+
+func (e *ForwardExporter) Consume(dataGroup *model.DataGroup) error {
+	select {
+	case e.queue <- forward.NewRecord(dataGroup):
+	default:
+		atomic.AddUint64(&e.dropped, 1)
+	}
+	return nil
+}
+
+// run sends a batch once it is full or the flush interval elapses.
+func (e *ForwardExporter) run() {
+	ticker := time.NewTicker(time.Duration(e.config.FlushInterval) * time.Millisecond)
+	defer ticker.Stop()
+	records := make([]*forward.Record, 0, e.config.BatchSize)
+	for {
+		select {
+		case record := <-e.queue:
+			records = append(records, record)
+			if len(records) < e.config.BatchSize {
+				continue
+			}
+		case <-ticker.C:
+			if len(records) == 0 {
+				continue
+			}
+		}
+		e.send(records)
+		records = make([]*forward.Record, 0, e.config.BatchSize)
+	}
+}
+
+func (e *ForwardExporter) send(records []*forward.Record) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(e.config.Timeout)*time.Second)
+	defer cancel()
+	// The batch is kept by the client and sent again after reconnecting until it is acknowledged.
+	// It is dropped only if the aggregator doesn't grant the window in time.
+	if err := e.client.Send(ctx, records); err != nil {
+		e.telemetry.Logger.Warn("Failed to forward the records", zap.String("endpoint", e.config.Endpoint), zap.Int("records", len(records)), zap.Error(err))
+	}
+	if dropped := atomic.SwapUint64(&e.dropped, 0); dropped > 0 {
+		e.telemetry.Logger.Warn("The records are dropped because the queue is full", zap.Uint64("records", dropped))
+	}
+}
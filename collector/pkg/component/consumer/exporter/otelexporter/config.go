@@ -2,6 +2,8 @@ package otelexporter
 
 import (
 	"time"
+
+	"go.opentelemetry.io/otel/sdk/resource"
 )
 
 type Config struct {
@@ -12,28 +14,74 @@ type Config struct {
 	CustomLabels         map[string]string                `mapstructure:"custom_labels"`
 	MetricAggregationMap map[string]MetricAggregationKind `mapstructure:"metric_aggregation_map"`
 	AdapterConfig        *AdapterConfig                   `mapstructure:"adapter_config"`
+	// MapSpanResource maps the workloads of the spans into the resource attributes "service.name",
+	// "k8s.namespace.name", "k8s.pod.name", etc., so the spans work with the service graphs of Grafana Tempo.
+	MapSpanResource bool `mapstructure:"map_span_resource"`
+	// MetricNaming is one of "legacy", "base_units" and "both". The legacy names are used if it is empty.
+	MetricNaming string `mapstructure:"metric_naming"`
+	// DeletedPodGracePeriod drops the aggregation state of the series referring to the deleted pods and their
+	// containers after the duration, which bounds the memory of the agent on the nodes where the pods come
+	// and go. The state is kept if it is 0.
+	DeletedPodGracePeriod time.Duration `mapstructure:"deleted_pod_grace_period"`
+	// detectedResource is the attributes detected by the application, which are merged into the resource.
+	detectedResource *resource.Resource
+}
+
+// SetResource implements resourcedetection.ResourceConfig.
+func (c *Config) SetResource(res *resource.Resource) {
+	c.detectedResource = res
 }
 
 type PrometheusConfig struct {
 	Port             string            `mapstructure:"port,omitempty"`
 	WithMemory       bool              `mapstructure:"with_memory,omitempty"`
 	MemCleanUpConfig *MemCleanUpConfig `mapstructure:"memcleanup"`
+	// Shards split the metrics into multiple paths or listeners, so each scrape gets a part of the series.
+	Shards []ShardConfig `mapstructure:"shards"`
+	// StaleTimeout hides the counters and the histograms not increased for the duration, so Prometheus
+	// marks them stale. They are never hidden if it is 0.
+	StaleTimeout time.Duration `mapstructure:"stale_timeout"`
+	// CreatedSeries adds the gauge "<name>_created" holding the creation time of each counter and histogram.
+	CreatedSeries bool `mapstructure:"created_series"`
+}
+
+// ShardConfig serves the series of the metric groups and the metric prefixes on the path.
+type ShardConfig struct {
+	Path string `mapstructure:"path"`
+	// Port is the address of the listener serving the path. The port of the exporter is used if it is empty.
+	Port string `mapstructure:"port"`
+	// MetricGroups are some of "topology", "red", "dns", "tcp" and "trace".
+	MetricGroups   []string `mapstructure:"metric_groups"`
+	MetricPrefixes []string `mapstructure:"metric_prefixes"`
 }
 
 type OtlpGrpcConfig struct {
 	CollectPeriod time.Duration `mapstructure:"collect_period,omitempty"`
 	Endpoint      string        `mapstructure:"endpoint,omitempty"`
+	// Temporality is either "cumulative" or "delta". Cumulative is used if it is empty.
+	Temporality string `mapstructure:"temporality,omitempty"`
 }
 
 type StdoutConfig struct {
 	CollectPeriod time.Duration `mapstructure:"collect_period,omitempty"`
+	// Temporality is either "cumulative" or "delta". Stateless is used if it is empty.
+	Temporality string `mapstructure:"temporality,omitempty"`
 }
 
 type AdapterConfig struct {
-	NeedTraceAsResourceSpan bool `mapstructure:"need_trace_as_span"`
-	NeedTraceAsMetric       bool `mapstructure:"need_trace_as_metric"`
-	NeedPodDetail           bool `mapstructure:"need_pod_detail"`
-	StoreExternalSrcIP      bool `mapstructure:"store_external_src_ip"`
+	NeedTraceAsResourceSpan  bool `mapstructure:"need_trace_as_span"`
+	NeedTraceAsMetric        bool `mapstructure:"need_trace_as_metric"`
+	NeedPodDetail            bool `mapstructure:"need_pod_detail"`
+	StoreExternalSrcIP       bool `mapstructure:"store_external_src_ip"`
+	NeedAggregationWindow    bool `mapstructure:"need_aggregation_window"`
+	NeedHealthCheckLabel     bool `mapstructure:"need_health_check_label"`
+	NeedProtocolVersionLabel bool `mapstructure:"need_protocol_version_label"`
+	// TimestampUnit is the unit of the timestamps in the spans, one of "ns", "us", "ms" and "s".
+	// If it is empty, "timestamp" is in milliseconds and "end_timestamp" is in nanoseconds.
+	TimestampUnit string `mapstructure:"timestamp_unit"`
+	// DurationUnit is the unit of the durations in the spans, one of "ns", "us", "ms" and "s".
+	// The unit is also the suffix of the keys, like "request_total_ms". It is "ns" if it is empty.
+	DurationUnit string `mapstructure:"duration_unit"`
 }
 
 type MemCleanUpConfig struct {
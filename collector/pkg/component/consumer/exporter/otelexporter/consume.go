@@ -59,10 +59,19 @@ func (e *OtelExporter) exportTrace(result *adapter.AdaptedResult) {
 		e.telemetry.Logger.Error("Send span failed: this exporter doesn't support Span Data", zap.String("exporter", e.cfg.ExportKind))
 		return
 	}
-	_, span := e.defaultTracer.Start(
+	tracer := e.defaultTracer
+	options := []apitrace.SpanStartOption{apitrace.WithAttributes(result.AttrsList...)}
+	if e.spanTracers != nil {
+		resourceAttrs, kind, spanAttrs := mapSpanResource(result.AttrsList)
+		if serviceTracer := e.spanTracers.get(resourceAttrs); serviceTracer != nil {
+			tracer = serviceTracer
+		}
+		options = append(options, apitrace.WithSpanKind(kind), apitrace.WithAttributes(spanAttrs...))
+	}
+	_, span := tracer.Start(
 		context.Background(),
 		constvalues.SpanInfo,
-		apitrace.WithAttributes(result.AttrsList...),
+		options...,
 	)
 	span.End()
 }
@@ -87,7 +96,9 @@ func (e *OtelExporter) exportMetric(result *adapter.AdaptedResult) {
 				e.telemetry.Logger.Error("Failed to record lastValue of Metric", zap.String("MetricName", metric.Name), zap.Error(err))
 			}
 		} else if ok && metric.DataType() == model.IntMetricType {
-			measurements = append(measurements, e.instrumentFactory.getInstrument(metric.Name, metricKind).Measurement(metric.GetInt().Value))
+			for _, ins := range e.instrumentFactory.getInstruments(metric.Name, metricKind) {
+				measurements = append(measurements, ins.Measurement(metric.GetInt().Value))
+			}
 		} else if metric.DataType() == model.HistogramMetricType {
 			e.telemetry.Logger.Warn("Failed to exporter Metric: can not use otlp-exporter to export histogram Data", zap.String("MetricName", metric.Name))
 		} else {
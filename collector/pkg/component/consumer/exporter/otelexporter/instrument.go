@@ -26,6 +26,8 @@ type instrumentFactory struct {
 	meter        metric.Meter
 	customLabels []attribute.KeyValue
 	telemetry    *component.TelemetryTools
+	// metricNaming decides the names the metrics are exported with. See BaseUnitMetricNaming.
+	metricNaming string
 
 	aggregator *defaultaggregator.DefaultAggregator
 
@@ -34,12 +36,13 @@ type instrumentFactory struct {
 	K8sWorkloadSelector   *aggregator.LabelSelectors
 }
 
-func newInstrumentFactory(meter metric.Meter, telemetry *component.TelemetryTools, customLabels []attribute.KeyValue) *instrumentFactory {
+func newInstrumentFactory(meter metric.Meter, telemetry *component.TelemetryTools, customLabels []attribute.KeyValue, metricNaming string) *instrumentFactory {
 	return &instrumentFactory{
 		instruments:  sync.Map{},
 		meter:        meter,
 		customLabels: customLabels,
 		telemetry:    telemetry,
+		metricNaming: metricNaming,
 		aggregator: defaultaggregator.NewDefaultAggregator(&defaultaggregator.AggregatedConfig{
 			KindMap: map[string][]defaultaggregator.KindConfig{
 				constnames.TcpRttMetricName: {
@@ -59,44 +62,60 @@ func newInstrumentFactory(meter metric.Meter, telemetry *component.TelemetryTool
 		K8sWorkloadSelector:   newK8sWorkloadSelector(),
 	}
 }
-func (i *instrumentFactory) getInstrument(metricName string, kind MetricAggregationKind) instrument {
+
+// getInstruments returns the instruments the metric is recorded into, which are more than one
+// if the metric is exported with both the legacy name and the new one.
+func (i *instrumentFactory) getInstruments(metricName string, kind MetricAggregationKind) []instrument {
 	if ins, ok := i.instruments.Load(metricName); ok {
-		return ins.(instrument)
+		return ins.([]instrument)
 	} else {
-		newIns := i.createNewInstrument(metricName, kind)
+		newIns := i.createNewInstruments(metricName, kind)
 		i.instruments.Store(metricName, newIns)
 		return newIns
 	}
 }
 
-func (i *instrumentFactory) createNewInstrument(metricName string, kind MetricAggregationKind) instrument {
+func (i *instrumentFactory) createNewInstruments(metricName string, kind MetricAggregationKind) []instrument {
 	switch kind {
-	case MACounterKind:
-		ins := metric.Must(i.meter).NewInt64Counter(metricName)
-		return &counterInstrument{instrument: &ins}
 	case MAHistogramKind:
-		ins := metric.Must(i.meter).NewInt64Histogram(metricName)
-		return &histogramInstrument{instrument: &ins}
+		naming := resolveMetricNaming(metricName, false)
+		ins := metric.Must(i.meter).NewInt64Histogram(naming.name, naming.options()...)
+		return []instrument{&histogramInstrument{instrument: &ins}}
 	default:
-		ins := metric.Must(i.meter).NewInt64Counter(metricName)
-		return &counterInstrument{instrument: &ins}
+		namings := resolveMetricNamings(metricName, i.metricNaming)
+		ret := make([]instrument, 0, len(namings))
+		for _, naming := range namings {
+			if naming.scale != 0 {
+				ins := metric.Must(i.meter).NewFloat64Counter(naming.name, naming.options()...)
+				ret = append(ret, &floatCounterInstrument{instrument: &ins, scale: naming.scale})
+				continue
+			}
+			ins := metric.Must(i.meter).NewInt64Counter(naming.name, naming.options()...)
+			ret = append(ret, &counterInstrument{instrument: &ins})
+		}
+		return ret
 	}
 }
 
 // recordLastValue Only support TraceAsMetric and TcpRttMills now
 func (i *instrumentFactory) recordLastValue(metricName string, singleMetric *model.DataGroup) error {
 	if !i.aggregator.CheckExist(singleMetric.Name) {
-		metric.Must(i.meter).NewInt64GaugeObserver(metricName, func(ctx context.Context, result metric.Int64ObserverResult) {
-			dumps := i.aggregator.DumpSingle(singleMetric.Name)
-			if dumps == nil {
-				return
-			}
-			for s := 0; s < len(dumps); s++ {
-				if len(dumps[s].Metrics) > 0 {
-					result.Observe(dumps[s].Metrics[0].GetInt().Value, adapter.GetLabels(dumps[s].Labels, i.customLabels)...)
-				}
+		for _, naming := range resolveMetricNamings(metricName, i.metricNaming) {
+			scale := naming.scale
+			if scale != 0 {
+				metric.Must(i.meter).NewFloat64GaugeObserver(naming.name, func(ctx context.Context, result metric.Float64ObserverResult) {
+					i.observeLastValue(singleMetric.Name, func(value int64, labels []attribute.KeyValue) {
+						result.Observe(float64(value)*scale, labels...)
+					})
+				}, naming.options()...)
+			} else {
+				metric.Must(i.meter).NewInt64GaugeObserver(naming.name, func(ctx context.Context, result metric.Int64ObserverResult) {
+					i.observeLastValue(singleMetric.Name, func(value int64, labels []attribute.KeyValue) {
+						result.Observe(value, labels...)
+					})
+				}, naming.options()...)
 			}
-		}, WithDescription(metricName))
+		}
 	}
 
 	if selector := i.getSelector(metricName); selector != nil {
@@ -107,15 +126,16 @@ func (i *instrumentFactory) recordLastValue(metricName string, singleMetric *mod
 	}
 }
 
-func WithDescription(metricName string) metric.InstrumentOption {
-	var option metric.InstrumentOption
-	switch metricName {
-	case constnames.TraceAsMetric:
-		option = metric.WithDescription(traceAsMetricHelp)
-	default:
-		option = metric.WithDescription("")
+func (i *instrumentFactory) observeLastValue(metricName string, observe func(value int64, labels []attribute.KeyValue)) {
+	dumps := i.aggregator.DumpSingle(metricName)
+	if dumps == nil {
+		return
+	}
+	for s := 0; s < len(dumps); s++ {
+		if len(dumps[s].Metrics) > 0 {
+			observe(dumps[s].Metrics[0].GetInt().Value, adapter.GetLabels(dumps[s].Labels, i.customLabels))
+		}
 	}
-	return option
 }
 
 func (i *instrumentFactory) getSelector(metricName string) *aggregator.LabelSelectors {
@@ -217,6 +237,16 @@ func (c *counterInstrument) Measurement(value int64) metric.Measurement {
 	return c.instrument.Measurement(value)
 }
 
+// floatCounterInstrument converts the values into the base unit by the scale.
+type floatCounterInstrument struct {
+	instrument *metric.Float64Counter
+	scale      float64
+}
+
+func (c *floatCounterInstrument) Measurement(value int64) metric.Measurement {
+	return c.instrument.Measurement(float64(value) * c.scale)
+}
+
 type histogramInstrument struct {
 	instrument *metric.Int64Histogram
 }
@@ -64,7 +64,7 @@ func Test_instrumentFactory_recordLastValue(t *testing.T) {
 
 	_ = cont.Start(context.Background())
 
-	ins := newInstrumentFactory(cont.Meter("test"), component.NewDefaultTelemetryTools(), nil)
+	ins := newInstrumentFactory(cont.Meter("test"), component.NewDefaultTelemetryTools(), nil, "")
 
 	for i := 0; i < 10000; i++ {
 		time.Sleep(1 * time.Second)
@@ -150,7 +150,7 @@ func makeTraceAsMetricGroup(requestLatency int64, timestamp uint64, dstIp string
 }
 
 func Test_instrumentFactory_recordTraceAsMetric(t *testing.T) {
-	ins := newInstrumentFactory(metric.Meter{}, component.NewDefaultTelemetryTools(), nil)
+	ins := newInstrumentFactory(metric.Meter{}, component.NewDefaultTelemetryTools(), nil, "")
 	metricName := constnames.TraceAsMetric
 	var randTime int64
 	var timestamp uint64
@@ -0,0 +1,151 @@
+package otelexporter
+
+import (
+	"strings"
+
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/unit"
+
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+)
+
+const (
+	// LegacyMetricNaming keeps the metric names of the previous versions, whose units are various.
+	LegacyMetricNaming = "legacy"
+	// BaseUnitMetricNaming exports the durations in seconds, which is the base unit of Prometheus,
+	// and renames the metrics accordingly, e.g. "*_duration_nanoseconds_total" to "*_duration_seconds_total".
+	BaseUnitMetricNaming = "base_units"
+	// BothMetricNaming exports the metrics with both the legacy names and the base-unit ones, so the
+	// dashboards built on the legacy names keep working during the migration.
+	BothMetricNaming = "both"
+)
+
+var metricHelps = map[string]string{
+	"kindling_entity_request_total":                          "Total number of the requests received by the server",
+	"kindling_entity_request_duration_nanoseconds_total":     "Total duration of the requests received by the server",
+	"kindling_entity_request_average_duration_nanoseconds":   "Average duration of the requests received by the server",
+	"kindling_entity_request_send_bytes_total":               "Total size of the payload sent by the server",
+	"kindling_entity_request_receive_bytes_total":            "Total size of the payload received by the server",
+	"kindling_topology_request_total":                        "Total number of the requests sent by the client",
+	"kindling_topology_request_duration_nanoseconds_total":   "Total duration of the requests sent by the client",
+	"kindling_topology_request_average_duration_nanoseconds": "Average duration of the requests sent by the client",
+	"kindling_topology_request_request_bytes_total":          "Total size of the payload of the requests sent by the client",
+	"kindling_topology_request_response_bytes_total":         "Total size of the payload of the responses received by the client",
+	constnames.TraceAsMetric:                                 traceAsMetricHelp,
+	constnames.TcpRttMetricName:                              "Smoothed round trip time of the TCP connection",
+	constnames.TcpRetransmitMetricName:                       "Total number of the retransmitted TCP segments",
+	constnames.TcpDropMetricName:                             "Total number of the TCP packets dropped by the kernel",
+	constnames.TcpConnectTotalMetric:                         "Total number of the TCP connection attempts",
+	constnames.TcpConnectDurationMetric:                      "Total time spent on establishing the TCP connections",
+	constnames.ConnectionPoolConnectTotalMetric:              "Total number of the connections established to the destination",
+	constnames.ConnectionPoolRequestTotalMetric:              "Total number of the requests sent to the destination",
+	constnames.ConnectionReuseRatioMetric:                    "Percentage of the requests sent over the reused connections",
+	constnames.ProcessOpenSocketsMetric:                      "Current number of the sockets opened by the process",
+	constnames.ServerQueueTimeTotalMetric:                    "Total time between accepting the connections and reading the first requests from them",
+	constnames.ServerQueueTotalMetric:                        "Total number of the accepted connections with the first requests read",
+	constnames.ServerQueueTimeMetric + "_max":                "Maximum time between accepting a connection and reading the first request from it",
+	constnames.BulkTransferBytesTotalMetric:                  "Total bytes streamed by the bulk loads and exports, e.g. COPY of PostgreSQL",
+	constnames.BulkTransferRowsTotalMetric:                   "Total number of the rows streamed by the bulk loads and exports",
+	constnames.BulkTransferDurationTotalMetric:               "Total time spent on streaming the rows of the bulk loads and exports",
+	constnames.BulkTransferTotalMetric:                       "Total number of the bulk loads and exports",
+	constnames.K8sWorkLoadMetricName:                         "Information of the Kubernetes workloads, whose value is always 1",
+	constnames.ContainerProtocolInfoMetric:                   "Information of the protocols spoken by the containers, whose value is always 1",
+	constnames.ProcessHandlingThreadsMetric:                  "Number of the threads handling the requests received by the process",
+	constnames.ProcessThreadSaturationMetric:                 "Percentage of the time the threads handling the requests are busy",
+	constnames.K8sContainerEventMetricName:                   "Total number of the container events, e.g. restarts and image pulls",
+	constnames.WorkloadRequestTotalMetric:                    "Total number of the requests received by the workload",
+	constnames.WorkloadRequestErrorTotalMetric:               "Total number of the failed requests received by the workload",
+	constnames.WorkloadRequestDurationTotalMetric:            "Total duration of the requests received by the workload",
+
+	// The phases of the requests
+	"kindling_entity_request_connect_average_duration_nanoseconds":        "Average time of establishing the connections of the requests received by the server",
+	"kindling_entity_request_sent_average_duration_nanoseconds":           "Average time of receiving the requests by the server",
+	"kindling_entity_request_waiting_ttfb_average_duration_nanoseconds":   "Average time from receiving the requests to sending the first bytes of the responses by the server",
+	"kindling_entity_request_download_average_duration_nanoseconds":       "Average time of sending the responses by the server",
+	"kindling_topology_request_connect_average_duration_nanoseconds":      "Average time of establishing the connections of the requests sent by the client",
+	"kindling_topology_request_sent_average_duration_nanoseconds":         "Average time of sending the requests by the client",
+	"kindling_topology_request_waiting_ttfb_average_duration_nanoseconds": "Average time from sending the requests to receiving the first bytes of the responses by the client",
+	"kindling_topology_request_download_average_duration_nanoseconds":     "Average time of receiving the responses by the client",
+}
+
+// unitSuffixes maps the unit in the legacy names to the unit of OpenTelemetry and the scale to the base unit.
+var unitSuffixes = []struct {
+	suffix string
+	unit   unit.Unit
+	scale  float64
+}{
+	{suffix: "_nanoseconds", unit: "ns", scale: 1e-9},
+	{suffix: "_microseconds", unit: "us", scale: 1e-6},
+	{suffix: "_bytes", unit: unit.Bytes},
+	{suffix: "_ratio", unit: unit.Dimensionless},
+}
+
+// metricNaming is how a metric is exported.
+type metricNaming struct {
+	name string
+	help string
+	unit unit.Unit
+	// scale converts the values into the base unit. It is 0 if the values are exported as they are.
+	scale float64
+}
+
+// resolveMetricNaming returns the naming of the metric whose legacy name is given. The histograms are never
+// converted, as their boundaries are in the legacy units.
+func resolveMetricNaming(legacyName string, baseUnits bool) metricNaming {
+	naming := metricNaming{name: legacyName, help: metricHelps[legacyName], unit: unit.Dimensionless}
+	for _, suffix := range unitSuffixes {
+		index := unitSuffixIndex(legacyName, suffix.suffix)
+		if index == -1 {
+			continue
+		}
+		naming.unit = suffix.unit
+		if baseUnits && suffix.scale != 0 {
+			naming.name = legacyName[:index] + "_seconds" + legacyName[index+len(suffix.suffix):]
+			naming.unit = "s"
+			naming.scale = suffix.scale
+		}
+		break
+	}
+	if baseUnits && !strings.HasPrefix(naming.name, constnames.NPMPrefixKindling+"_") {
+		naming.name = constnames.NPMPrefixKindling + "_" + naming.name
+	}
+	return naming
+}
+
+// resolveMetricNamings returns the namings the metric is exported with according to the metric_naming option.
+// The base-unit naming is omitted if it is the same as the legacy one.
+func resolveMetricNamings(legacyName string, option string) []metricNaming {
+	switch option {
+	case BaseUnitMetricNaming:
+		return []metricNaming{resolveMetricNaming(legacyName, true)}
+	case BothMetricNaming:
+		legacy := resolveMetricNaming(legacyName, false)
+		baseUnit := resolveMetricNaming(legacyName, true)
+		if baseUnit.name == legacy.name {
+			return []metricNaming{legacy}
+		}
+		return []metricNaming{legacy, baseUnit}
+	default:
+		return []metricNaming{resolveMetricNaming(legacyName, false)}
+	}
+}
+
+// unitSuffixIndex returns the index of the unit in the name, which is followed by the end or another suffix like "_total".
+func unitSuffixIndex(name string, suffix string) int {
+	for start := 0; ; {
+		index := strings.Index(name[start:], suffix)
+		if index == -1 {
+			return -1
+		}
+		index += start
+		end := index + len(suffix)
+		if end == len(name) || name[end] == '_' {
+			return index
+		}
+		start = end
+	}
+}
+
+func (n metricNaming) options() []metric.InstrumentOption {
+	return []metric.InstrumentOption{metric.WithDescription(n.help), metric.WithUnit(n.unit)}
+}
@@ -0,0 +1,85 @@
+package otelexporter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/metric/unit"
+)
+
+func TestResolveMetricNaming(t *testing.T) {
+	tests := []struct {
+		legacyName string
+		baseUnits  bool
+		want       metricNaming
+	}{
+		{
+			legacyName: "kindling_entity_request_duration_nanoseconds_total",
+			want:       metricNaming{name: "kindling_entity_request_duration_nanoseconds_total", help: "Total duration of the requests received by the server", unit: "ns"},
+		},
+		{
+			legacyName: "kindling_entity_request_duration_nanoseconds_total",
+			baseUnits:  true,
+			want:       metricNaming{name: "kindling_entity_request_duration_seconds_total", help: "Total duration of the requests received by the server", unit: "s", scale: 1e-9},
+		},
+		{
+			legacyName: "kindling_trace_request_duration_nanoseconds",
+			baseUnits:  true,
+			want:       metricNaming{name: "kindling_trace_request_duration_seconds", help: traceAsMetricHelp, unit: "s", scale: 1e-9},
+		},
+		{
+			legacyName: "kindling_server_queue_time_nanoseconds_max",
+			baseUnits:  true,
+			want: metricNaming{name: "kindling_server_queue_time_seconds_max",
+				help: "Maximum time between accepting a connection and reading the first request from it", unit: "s", scale: 1e-9},
+		},
+		{
+			legacyName: "kindling_tcp_srtt_microseconds",
+			baseUnits:  true,
+			want:       metricNaming{name: "kindling_tcp_srtt_seconds", help: "Smoothed round trip time of the TCP connection", unit: "s", scale: 1e-6},
+		},
+		{
+			legacyName: "kindling_topology_request_request_bytes_total",
+			baseUnits:  true,
+			want:       metricNaming{name: "kindling_topology_request_request_bytes_total", help: "Total size of the payload of the requests sent by the client", unit: unit.Bytes},
+		},
+		{
+			legacyName: "kindling_tcp_connect_total",
+			baseUnits:  true,
+			want:       metricNaming{name: "kindling_tcp_connect_total", help: "Total number of the TCP connection attempts", unit: unit.Dimensionless},
+		},
+		{
+			legacyName: "custom_latency_nanoseconds_total",
+			baseUnits:  true,
+			want:       metricNaming{name: "kindling_custom_latency_seconds_total", unit: "s", scale: 1e-9},
+		},
+		{
+			// The unit must be a whole word in the name.
+			legacyName: "kindling_bytesize_total",
+			baseUnits:  true,
+			want:       metricNaming{name: "kindling_bytesize_total", unit: unit.Dimensionless},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, resolveMetricNaming(tt.legacyName, tt.baseUnits))
+		})
+	}
+}
+
+func TestResolveMetricNamings(t *testing.T) {
+	names := func(namings []metricNaming) []string {
+		ret := make([]string, 0, len(namings))
+		for _, naming := range namings {
+			ret = append(ret, naming.name)
+		}
+		return ret
+	}
+	legacyName := "kindling_entity_request_duration_nanoseconds_total"
+	assert.Equal(t, []string{legacyName}, names(resolveMetricNamings(legacyName, "")))
+	assert.Equal(t, []string{legacyName}, names(resolveMetricNamings(legacyName, LegacyMetricNaming)))
+	assert.Equal(t, []string{"kindling_entity_request_duration_seconds_total"}, names(resolveMetricNamings(legacyName, BaseUnitMetricNaming)))
+	assert.Equal(t, []string{legacyName, "kindling_entity_request_duration_seconds_total"}, names(resolveMetricNamings(legacyName, BothMetricNaming)))
+	// The metric is exported only once if its name is not changed.
+	assert.Equal(t, []string{"kindling_entity_request_total"}, names(resolveMetricNamings("kindling_entity_request_total", BothMetricNaming)))
+}
@@ -8,7 +8,9 @@ import (
 	"sync"
 	"time"
 
+	promclient "github.com/prometheus/client_golang/prometheus"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric"
 	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
 	"go.opentelemetry.io/otel/exporters/prometheus"
@@ -30,7 +32,9 @@ import (
 	"github.com/Kindling-project/kindling/collector/pkg/component"
 	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/exporter"
 	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/exporter/tools/adapter"
+	"github.com/Kindling-project/kindling/collector/pkg/metadata/kubernetes"
 	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+	"github.com/Kindling-project/kindling/collector/pkg/model/timeunit"
 )
 
 const (
@@ -49,6 +53,8 @@ var serviceName string
 type OtelOutputExporters struct {
 	metricExporter exportmetric.Exporter
 	traceExporter  sdktrace.SpanExporter
+	// temporalitySelector decides whether the metrics are exported as delta or cumulative.
+	temporalitySelector aggregation.TemporalitySelector
 }
 
 type OtelExporter struct {
@@ -65,6 +71,10 @@ type OtelExporter struct {
 	mu                   sync.Mutex
 
 	adapters []adapter.Adapter
+	// podCheckpointers is not nil if the aggregation state of the deleted pods is dropped.
+	podCheckpointers *podCheckpointerFactory
+	// spanTracers is not nil if the workloads of the spans are mapped into the resource attributes.
+	spanTracers *spanTracers
 }
 
 func NewExporter(config interface{}, telemetry *component.TelemetryTools) exporter.Exporter {
@@ -73,6 +83,13 @@ func NewExporter(config interface{}, telemetry *component.TelemetryTools) export
 	if !ok {
 		telemetry.Logger.Panic("Cannot convert Component config", zap.String("componentType", Otel))
 	}
+	switch cfg.MetricNaming {
+	case "", LegacyMetricNaming, BaseUnitMetricNaming, BothMetricNaming:
+	default:
+		telemetry.Logger.Warn("Unknown metric_naming, the legacy names are used", zap.String("metric_naming", cfg.MetricNaming))
+	}
+	timestampUnit := parseSpanUnit(cfg.AdapterConfig.TimestampUnit, "timestamp_unit", telemetry)
+	durationUnit := parseSpanUnit(cfg.AdapterConfig.DurationUnit, "duration_unit", telemetry)
 	customLabels := make([]attribute.KeyValue, 0, len(cfg.CustomLabels))
 	for k, v := range cfg.CustomLabels {
 		customLabels = append(customLabels, attribute.String(k, v))
@@ -106,21 +123,31 @@ func NewExporter(config interface{}, telemetry *component.TelemetryTools) export
 			semconv.ServiceInstanceIDKey.String(hostName),
 		),
 	)
+	if cfg.detectedResource != nil {
+		// The attributes above take precedence over the detected ones.
+		if merged, err := resource.Merge(cfg.detectedResource, rs); err != nil {
+			telemetry.Logger.Warn("Failed to merge the detected resource attributes", zap.Error(err))
+		} else {
+			rs = merged
+		}
+	}
 
 	var otelexporter *OtelExporter
 	var cont *controller.Controller
 
 	if cfg.ExportKind == PrometheusKindExporter {
-		config := prometheus.Config{}
+		registry := promclient.NewRegistry()
+		config := prometheus.Config{Registry: registry}
+		checkpointerFactory, podCheckpointers := withPodCheckpointers(otelprocessor.NewFactory(
+			selector.NewWithHistogramDistribution(
+				histogram.WithExplicitBoundaries(exponentialInt64NanosecondsBoundaries),
+			),
+			aggregation.CumulativeTemporalitySelector(),
+			otelprocessor.WithMemory(cfg.PromCfg.WithMemory),
+		), cfg.DeletedPodGracePeriod)
 		// Create a meter
 		c := controller.New(
-			otelprocessor.NewFactory(
-				selector.NewWithHistogramDistribution(
-					histogram.WithExplicitBoundaries(exponentialInt64NanosecondsBoundaries),
-				),
-				aggregation.CumulativeTemporalitySelector(),
-				otelprocessor.WithMemory(cfg.PromCfg.WithMemory),
-			),
+			checkpointerFactory,
 			controller.WithResource(rs),
 		)
 		exp, err := prometheus.New(config, c)
@@ -136,25 +163,34 @@ func NewExporter(config interface{}, telemetry *component.TelemetryTools) export
 			traceProvider:        nil,
 			defaultTracer:        nil,
 			customLabels:         customLabels,
-			instrumentFactory:    newInstrumentFactory(exp.MeterProvider().Meter(MeterName), telemetry, customLabels),
+			instrumentFactory:    newInstrumentFactory(exp.MeterProvider().Meter(MeterName), telemetry, customLabels, cfg.MetricNaming),
 			metricAggregationMap: cfg.MetricAggregationMap,
 			telemetry:            telemetry,
 			exp:                  exp,
 			rs:                   rs,
+			podCheckpointers:     podCheckpointers,
 			adapters: []adapter.Adapter{
 				adapter.NewNetAdapter(customLabels, &adapter.NetAdapterConfig{
-					StoreTraceAsMetric: cfg.AdapterConfig.NeedTraceAsMetric,
-					StoreTraceAsSpan:   cfg.AdapterConfig.NeedTraceAsResourceSpan,
-					StorePodDetail:     cfg.AdapterConfig.NeedPodDetail,
-					StoreExternalSrcIP: cfg.AdapterConfig.StoreExternalSrcIP,
+					StoreTraceAsMetric:     cfg.AdapterConfig.NeedTraceAsMetric,
+					StoreTraceAsSpan:       cfg.AdapterConfig.NeedTraceAsResourceSpan,
+					StorePodDetail:         cfg.AdapterConfig.NeedPodDetail,
+					StoreExternalSrcIP:     cfg.AdapterConfig.StoreExternalSrcIP,
+					StoreAggregationWindow: cfg.AdapterConfig.NeedAggregationWindow,
+					StoreHealthCheck:       cfg.AdapterConfig.NeedHealthCheckLabel,
+					StoreProtocolVersion:   cfg.AdapterConfig.NeedProtocolVersionLabel,
+					TimestampUnit:          timestampUnit,
+					DurationUnit:           durationUnit,
 				}),
 				adapter.NewSimpleAdapter([]string{constnames.TcpRttMetricGroupName, constnames.TcpRetransmitMetricGroupName,
-					constnames.TcpDropMetricGroupName, constnames.TcpConnectMetricGroupName, constnames.K8sWorkloadMetricGroupName},
+					constnames.TcpDropMetricGroupName, constnames.TcpConnectMetricGroupName, constnames.K8sWorkloadMetricGroupName,
+					constnames.K8sContainerEventGroupName, constnames.WorkloadRequestMetricGroupName,
+					constnames.ConnectionPoolMetricGroupName, constnames.ProcessSocketMetricGroupName, constnames.ServerQueueMetricGroupName,
+					constnames.ContainerProtocolMetricGroupName, constnames.ThreadPoolMetricGroupName, constnames.BulkTransferMetricGroupName},
 					customLabels),
 			},
 		}
 		go func() {
-			err := StartServer(exp, telemetry, cfg.PromCfg.Port)
+			err := StartServer(registry, telemetry, cfg.PromCfg)
 			if err != nil {
 				telemetry.Logger.Warn("error starting otelexporter prometheus server: ", zap.Error(err))
 			}
@@ -182,10 +218,11 @@ func NewExporter(config interface{}, telemetry *component.TelemetryTools) export
 			return nil
 		}
 
+		checkpointerFactory, podCheckpointers := withPodCheckpointers(otelprocessor.NewFactory(simple.NewWithHistogramDistribution(
+			histogram.WithExplicitBoundaries(exponentialInt64NanosecondsBoundaries),
+		), exporters.temporalitySelector), cfg.DeletedPodGracePeriod)
 		cont = controller.New(
-			otelprocessor.NewFactory(simple.NewWithHistogramDistribution(
-				histogram.WithExplicitBoundaries(exponentialInt64NanosecondsBoundaries),
-			), exporters.metricExporter),
+			checkpointerFactory,
 			controller.WithExporter(exporters.metricExporter),
 			controller.WithCollectPeriod(collectPeriod),
 			controller.WithResource(rs),
@@ -205,25 +242,39 @@ func NewExporter(config interface{}, telemetry *component.TelemetryTools) export
 		)
 
 		tracer := tracerProvider.Tracer(TracerName)
+		var tracers *spanTracers
+		if cfg.MapSpanResource {
+			tracers = newSpanTracers(rs, ssp)
+		}
 
 		otelexporter = &OtelExporter{
 			cfg:                  cfg,
 			metricController:     cont,
+			podCheckpointers:     podCheckpointers,
 			traceProvider:        tracerProvider,
 			defaultTracer:        tracer,
+			spanTracers:          tracers,
 			customLabels:         customLabels,
-			instrumentFactory:    newInstrumentFactory(cont.Meter(MeterName), telemetry, customLabels),
+			instrumentFactory:    newInstrumentFactory(cont.Meter(MeterName), telemetry, customLabels, cfg.MetricNaming),
 			metricAggregationMap: cfg.MetricAggregationMap,
 			telemetry:            telemetry,
 			adapters: []adapter.Adapter{
 				adapter.NewNetAdapter(customLabels, &adapter.NetAdapterConfig{
-					StoreTraceAsMetric: cfg.AdapterConfig.NeedTraceAsMetric,
-					StoreTraceAsSpan:   cfg.AdapterConfig.NeedTraceAsResourceSpan,
-					StorePodDetail:     cfg.AdapterConfig.NeedPodDetail,
-					StoreExternalSrcIP: cfg.AdapterConfig.StoreExternalSrcIP,
+					StoreTraceAsMetric:     cfg.AdapterConfig.NeedTraceAsMetric,
+					StoreTraceAsSpan:       cfg.AdapterConfig.NeedTraceAsResourceSpan,
+					StorePodDetail:         cfg.AdapterConfig.NeedPodDetail,
+					StoreExternalSrcIP:     cfg.AdapterConfig.StoreExternalSrcIP,
+					StoreAggregationWindow: cfg.AdapterConfig.NeedAggregationWindow,
+					StoreHealthCheck:       cfg.AdapterConfig.NeedHealthCheckLabel,
+					StoreProtocolVersion:   cfg.AdapterConfig.NeedProtocolVersionLabel,
+					TimestampUnit:          timestampUnit,
+					DurationUnit:           durationUnit,
 				}),
 				adapter.NewSimpleAdapter([]string{constnames.TcpRttMetricGroupName, constnames.TcpRetransmitMetricGroupName,
-					constnames.TcpDropMetricGroupName, constnames.TcpConnectMetricGroupName, constnames.K8sWorkloadMetricGroupName},
+					constnames.TcpDropMetricGroupName, constnames.TcpConnectMetricGroupName, constnames.K8sWorkloadMetricGroupName,
+					constnames.K8sContainerEventGroupName, constnames.WorkloadRequestMetricGroupName,
+					constnames.ConnectionPoolMetricGroupName, constnames.ProcessSocketMetricGroupName, constnames.ServerQueueMetricGroupName,
+					constnames.ContainerProtocolMetricGroupName, constnames.ThreadPoolMetricGroupName, constnames.BulkTransferMetricGroupName},
 					customLabels),
 			},
 		}
@@ -234,9 +285,35 @@ func NewExporter(config interface{}, telemetry *component.TelemetryTools) export
 		}
 	}
 
+	if cfg.DeletedPodGracePeriod > 0 {
+		kubernetes.WatchPodDeletion(otelexporter.deletePod)
+	}
 	return otelexporter
 }
 
+// deletePod drops the aggregation state of the series referring to the deleted pod after the grace period.
+func (e *OtelExporter) deletePod(pod *kubernetes.DeletedPod) {
+	e.mu.Lock()
+	podCheckpointers := e.podCheckpointers
+	e.mu.Unlock()
+	if podCheckpointers != nil {
+		podCheckpointers.deletePod(pod)
+	}
+}
+
+// parseSpanUnit returns the unit of the span attributes, or the default one if it is empty or unknown.
+func parseSpanUnit(s string, name string, telemetry *component.TelemetryTools) timeunit.Unit {
+	if s == "" {
+		return ""
+	}
+	unit, err := timeunit.Parse(s)
+	if err != nil {
+		telemetry.Logger.Warn("Unknown "+name+", the default unit is used", zap.Error(err))
+		return ""
+	}
+	return unit
+}
+
 func (e *OtelExporter) findInstrumentKind(metricName string) (MetricAggregationKind, bool) {
 	kind, find := e.metricAggregationMap[metricName]
 	return kind, find
@@ -248,6 +325,10 @@ func newExporters(context context.Context, cfg *Config, telemetry *component.Tel
 	telemetry.Logger.Infof("Initializing OpenTelemetry exporter whose type is %s", cfg.ExportKind)
 	switch cfg.ExportKind {
 	case StdoutKindExporter:
+		temporalitySelector, err := newTemporalitySelector(cfg.StdoutCfg.Temporality)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create exporter, %w", err)
+		}
 		metricExp, err := stdoutmetric.New(
 			stdoutmetric.WithPrettyPrint(),
 		)
@@ -260,12 +341,24 @@ func newExporters(context context.Context, cfg *Config, telemetry *component.Tel
 		if err != nil {
 			return nil, fmt.Errorf("failed to create exporter, %w", err)
 		}
+		if temporalitySelector == nil {
+			temporalitySelector = metricExp
+		}
 		retExporters = &OtelOutputExporters{
-			metricExporter: metricExp,
-			traceExporter:  traceExp,
+			metricExporter:      metricExp,
+			traceExporter:       traceExp,
+			temporalitySelector: temporalitySelector,
 		}
 	case OtlpGrpcKindExporter:
-		metricExporter, err := otlpmetricgrpc.New(context,
+		temporalitySelector, err := newTemporalitySelector(cfg.OtlpGrpcCfg.Temporality)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create exporter, %w", err)
+		}
+		var exporterOpts []otlpmetric.Option
+		if temporalitySelector != nil {
+			exporterOpts = append(exporterOpts, otlpmetric.WithMetricAggregationTemporalitySelector(temporalitySelector))
+		}
+		metricClient := otlpmetricgrpc.NewClient(
 			otlpmetricgrpc.WithInsecure(),
 			otlpmetricgrpc.WithEndpoint(cfg.OtlpGrpcCfg.Endpoint),
 			otlpmetricgrpc.WithRetry(otlpmetricgrpc.RetrySettings{
@@ -275,6 +368,8 @@ func newExporters(context context.Context, cfg *Config, telemetry *component.Tel
 				MaxElapsedTime:  15 * time.Second,
 			}),
 		)
+		// The exporter marks the data points with the same temporality as the processor produces.
+		metricExporter, err := otlpmetric.New(context, metricClient, exporterOpts...)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create exporter, %w", err)
 		}
@@ -292,8 +387,9 @@ func newExporters(context context.Context, cfg *Config, telemetry *component.Tel
 			return nil, fmt.Errorf("failed to create exporter, %w", err)
 		}
 		retExporters = &OtelOutputExporters{
-			metricExporter: metricExporter,
-			traceExporter:  traceExporter,
+			metricExporter:      metricExporter,
+			traceExporter:       traceExporter,
+			temporalitySelector: metricExporter,
 		}
 	default:
 		return nil, errors.New("failed to create exporter, no exporter kind is provided")
@@ -316,16 +412,18 @@ var exponentialInt64NanosecondsBoundaries = func(bounds []float64) (asint []floa
 func (e *OtelExporter) NewMeter(telemetry *component.TelemetryTools) error {
 	e.mu.Lock()
 	defer e.mu.Unlock()
-	config := prometheus.Config{}
+	registry := promclient.NewRegistry()
+	config := prometheus.Config{Registry: registry}
 
-	newController := controller.New(
-		otelprocessor.NewFactory(
-			selector.NewWithHistogramDistribution(
-				histogram.WithExplicitBoundaries(exponentialInt64NanosecondsBoundaries),
-			),
-			aggregation.CumulativeTemporalitySelector(),
-			otelprocessor.WithMemory(e.cfg.PromCfg.WithMemory),
+	checkpointerFactory, podCheckpointers := withPodCheckpointers(otelprocessor.NewFactory(
+		selector.NewWithHistogramDistribution(
+			histogram.WithExplicitBoundaries(exponentialInt64NanosecondsBoundaries),
 		),
+		aggregation.CumulativeTemporalitySelector(),
+		otelprocessor.WithMemory(e.cfg.PromCfg.WithMemory),
+	), e.cfg.DeletedPodGracePeriod)
+	newController := controller.New(
+		checkpointerFactory,
 		controller.WithResource(e.rs),
 	)
 
@@ -341,10 +439,11 @@ func (e *OtelExporter) NewMeter(telemetry *component.TelemetryTools) error {
 
 	e.exp = exp
 	e.metricController = newController
-	e.instrumentFactory = newInstrumentFactory(e.exp.MeterProvider().Meter(MeterName), e.telemetry, e.customLabels)
+	e.podCheckpointers = podCheckpointers
+	e.instrumentFactory = newInstrumentFactory(e.exp.MeterProvider().Meter(MeterName), e.telemetry, e.customLabels, e.cfg.MetricNaming)
 
 	go func() {
-		if err := StartServer(e.exp, e.telemetry, e.cfg.PromCfg.Port); err != nil {
+		if err := StartServer(registry, e.telemetry, e.cfg.PromCfg); err != nil {
 			telemetry.Logger.Warn("error starting otelexporter prometheus server: ", zap.Error(err))
 		}
 	}()
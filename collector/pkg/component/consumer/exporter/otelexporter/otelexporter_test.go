@@ -205,7 +205,7 @@ func BenchmarkOtelExporter_Consume(b *testing.B) {
 		traceProvider:        nil,
 		defaultTracer:        nil,
 		customLabels:         nil,
-		instrumentFactory:    newInstrumentFactory(cont.Meter(MeterName), telemetry, nil),
+		instrumentFactory:    newInstrumentFactory(cont.Meter(MeterName), telemetry, nil, ""),
 		metricAggregationMap: cfg.MetricAggregationMap,
 		telemetry:            component.NewDefaultTelemetryTools(),
 		adapters: []adapter.Adapter{
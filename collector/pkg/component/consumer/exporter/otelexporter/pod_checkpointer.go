@@ -0,0 +1,214 @@
+package otelexporter
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric/sdkapi"
+	export "go.opentelemetry.io/otel/sdk/export/metric"
+	"go.opentelemetry.io/otel/sdk/export/metric/aggregation"
+
+	"github.com/Kindling-project/kindling/collector/pkg/metadata/kubernetes"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+// The labels referring to the pods and the containers of the series.
+var (
+	podRefLabels = [][2]attribute.Key{
+		{constlabels.Namespace, constlabels.Pod},
+		{constlabels.SrcNamespace, constlabels.SrcPod},
+		{constlabels.DstNamespace, constlabels.DstPod},
+	}
+	containerRefLabels = []attribute.Key{constlabels.ContainerId, constlabels.SrcContainerId, constlabels.DstContainerId}
+)
+
+// withPodCheckpointers wraps the factory to drop the state of the deleted pods if the grace period is set.
+func withPodCheckpointers(factory export.CheckpointerFactory, gracePeriod time.Duration) (export.CheckpointerFactory, *podCheckpointerFactory) {
+	if gracePeriod <= 0 {
+		return factory, nil
+	}
+	podFactory := newPodCheckpointerFactory(factory, gracePeriod)
+	return podFactory, podFactory
+}
+
+// podCheckpointerFactory creates the checkpointers that keep the aggregation state of the series apart by
+// the pods and the containers they refer to, so the state of the deleted pods can be dropped.
+type podCheckpointerFactory struct {
+	factory     export.CheckpointerFactory
+	gracePeriod time.Duration
+	now         func() time.Time
+
+	mu            sync.Mutex
+	checkpointers []*podCheckpointer
+}
+
+func newPodCheckpointerFactory(factory export.CheckpointerFactory, gracePeriod time.Duration) *podCheckpointerFactory {
+	return &podCheckpointerFactory{
+		factory:     factory,
+		gracePeriod: gracePeriod,
+		now:         time.Now,
+	}
+}
+
+func (f *podCheckpointerFactory) NewCheckpointer() export.Checkpointer {
+	c := &podCheckpointer{
+		factory:    f.factory,
+		now:        f.now,
+		partitions: make(map[string]*podPartition),
+	}
+	// The default partition holds the series referring to no pod.
+	c.defaultPartition = c.newPartition("", nil)
+	f.mu.Lock()
+	f.checkpointers = append(f.checkpointers, c)
+	f.mu.Unlock()
+	return c
+}
+
+// deletePod drops the state of the series referring to the pod after the grace period.
+func (f *podCheckpointerFactory) deletePod(pod *kubernetes.DeletedPod) {
+	refs := make(map[string]bool, len(pod.ContainerIds)+1)
+	if pod.Name != "" {
+		refs[podRef(pod.Namespace, pod.Name)] = true
+	}
+	for _, containerId := range pod.ContainerIds {
+		refs[containerRef(containerId)] = true
+	}
+	expireAt := f.now().Add(f.gracePeriod)
+	f.mu.Lock()
+	checkpointers := f.checkpointers
+	f.mu.Unlock()
+	for _, c := range checkpointers {
+		c.expire(refs, expireAt)
+	}
+}
+
+type podPartition struct {
+	checkpointer export.Checkpointer
+	refs         []string
+	expireAt     time.Time
+}
+
+// podCheckpointer is a checkpointer whose series are processed by the partitions of the pods and the
+// containers they refer to. The partitions of the deleted pods are removed at the start of the
+// collections after they expire.
+type podCheckpointer struct {
+	sync.RWMutex
+	factory export.CheckpointerFactory
+	now     func() time.Time
+
+	defaultPartition *podPartition
+	partitions       map[string]*podPartition
+	collecting       bool
+	// expireMu protects expireAt of the partitions, which is set with the read lock of the reader.
+	expireMu sync.Mutex
+}
+
+var _ export.Checkpointer = (*podCheckpointer)(nil)
+
+func (c *podCheckpointer) newPartition(key string, refs []string) *podPartition {
+	partition := &podPartition{checkpointer: c.factory.NewCheckpointer(), refs: refs}
+	c.partitions[key] = partition
+	return partition
+}
+
+func (c *podCheckpointer) AggregatorFor(descriptor *sdkapi.Descriptor, aggregators ...*export.Aggregator) {
+	c.defaultPartition.checkpointer.AggregatorFor(descriptor, aggregators...)
+}
+
+func (c *podCheckpointer) Process(accum export.Accumulation) error {
+	refs := seriesRefs(accum.Labels())
+	key := strings.Join(refs, ",")
+	partition, ok := c.partitions[key]
+	if !ok {
+		partition = c.newPartition(key, refs)
+		if c.collecting {
+			partition.checkpointer.StartCollection()
+		}
+	}
+	return partition.checkpointer.Process(accum)
+}
+
+func (c *podCheckpointer) Reader() export.Reader {
+	return c
+}
+
+func (c *podCheckpointer) StartCollection() {
+	now := c.now()
+	c.expireMu.Lock()
+	for key, partition := range c.partitions {
+		if !partition.expireAt.IsZero() && !now.Before(partition.expireAt) {
+			delete(c.partitions, key)
+		}
+	}
+	c.expireMu.Unlock()
+	for _, partition := range c.partitions {
+		partition.checkpointer.StartCollection()
+	}
+	c.collecting = true
+}
+
+func (c *podCheckpointer) FinishCollection() error {
+	c.collecting = false
+	var err error
+	for _, partition := range c.partitions {
+		if finishErr := partition.checkpointer.FinishCollection(); finishErr != nil && err == nil {
+			err = finishErr
+		}
+	}
+	return err
+}
+
+func (c *podCheckpointer) ForEach(selector aggregation.TemporalitySelector, recordFunc func(export.Record) error) error {
+	for _, partition := range c.partitions {
+		if err := partition.checkpointer.Reader().ForEach(selector, recordFunc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *podCheckpointer) expire(refs map[string]bool, expireAt time.Time) {
+	// The partitions are only added and removed with the lock of the reader held by the collections.
+	c.RLock()
+	defer c.RUnlock()
+	c.expireMu.Lock()
+	defer c.expireMu.Unlock()
+	for _, partition := range c.partitions {
+		if !partition.expireAt.IsZero() {
+			continue
+		}
+		for _, ref := range partition.refs {
+			if refs[ref] {
+				partition.expireAt = expireAt
+				break
+			}
+		}
+	}
+}
+
+// seriesRefs returns the pods and the containers the series refers to.
+func seriesRefs(labels *attribute.Set) []string {
+	refs := make([]string, 0)
+	for _, keys := range podRefLabels {
+		if pod, ok := labels.Value(keys[1]); ok && pod.AsString() != "" {
+			namespace, _ := labels.Value(keys[0])
+			refs = append(refs, podRef(namespace.AsString(), pod.AsString()))
+		}
+	}
+	for _, key := range containerRefLabels {
+		if containerId, ok := labels.Value(key); ok && containerId.AsString() != "" {
+			refs = append(refs, containerRef(containerId.AsString()))
+		}
+	}
+	return refs
+}
+
+func podRef(namespace string, name string) string {
+	return "pod:" + namespace + "/" + name
+}
+
+func containerRef(containerId string) string {
+	return "container:" + containerId
+}
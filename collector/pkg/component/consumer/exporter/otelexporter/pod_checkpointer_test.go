@@ -0,0 +1,85 @@
+package otelexporter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	export "go.opentelemetry.io/otel/sdk/export/metric"
+	"go.opentelemetry.io/otel/sdk/export/metric/aggregation"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	controller "go.opentelemetry.io/otel/sdk/metric/controller/basic"
+	otelprocessor "go.opentelemetry.io/otel/sdk/metric/processor/basic"
+	"go.opentelemetry.io/otel/sdk/metric/selector/simple"
+
+	"github.com/Kindling-project/kindling/collector/pkg/metadata/kubernetes"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+func collectSeries(t *testing.T, c *controller.Controller) map[string]float64 {
+	require.NoError(t, c.Collect(context.Background()))
+	series := make(map[string]float64)
+	require.NoError(t, c.ForEach(func(_ instrumentation.Library, reader export.Reader) error {
+		return reader.ForEach(aggregation.CumulativeTemporalitySelector(), func(record export.Record) error {
+			sum, err := record.Aggregation().(aggregation.Sum).Sum()
+			if err != nil {
+				return err
+			}
+			key := record.Descriptor().Name()
+			for _, kv := range record.Labels().ToSlice() {
+				key += "," + string(kv.Key) + "=" + kv.Value.AsString()
+			}
+			series[key] = sum.CoerceToFloat64(record.Descriptor().NumberKind())
+			return nil
+		})
+	}))
+	return series
+}
+
+func TestPodCheckpointer(t *testing.T) {
+	now := time.Unix(1000, 0)
+	_, podCheckpointers := withPodCheckpointers(otelprocessor.NewFactory(simple.NewWithInexpensiveDistribution(),
+		aggregation.CumulativeTemporalitySelector(), otelprocessor.WithMemory(true)), time.Minute)
+	require.NotNil(t, podCheckpointers)
+	podCheckpointers.now = func() time.Time { return now }
+	c := controller.New(podCheckpointers, controller.WithCollectPeriod(0))
+	counter := metric.Must(c.Meter(MeterName)).NewInt64Counter("kindling_entity_request_total")
+
+	deleted := []attribute.KeyValue{attribute.String(constlabels.Namespace, "default"), attribute.String(constlabels.Pod, "web-1"),
+		attribute.String(constlabels.ContainerId, "1a2b3c4d5e6f")}
+	alive := []attribute.KeyValue{attribute.String(constlabels.Namespace, "default"), attribute.String(constlabels.Pod, "web-2")}
+	client := []attribute.KeyValue{attribute.String(constlabels.SrcContainerId, "1a2b3c4d5e6f"),
+		attribute.String(constlabels.DstNamespace, "default"), attribute.String(constlabels.DstPod, "web-2")}
+	counter.Add(context.Background(), 1, deleted...)
+	counter.Add(context.Background(), 2, alive...)
+	counter.Add(context.Background(), 3, client...)
+	counter.Add(context.Background(), 4)
+	assert.Len(t, collectSeries(t, c), 4)
+
+	// The series are kept within the grace period.
+	podCheckpointers.deletePod(&kubernetes.DeletedPod{Namespace: "default", Name: "web-1", ContainerIds: []string{"1a2b3c4d5e6f"}})
+	now = now.Add(30 * time.Second)
+	counter.Add(context.Background(), 1, alive...)
+	series := collectSeries(t, c)
+	assert.Len(t, series, 4)
+	assert.Equal(t, float64(3), series["kindling_entity_request_total,namespace=default,pod=web-2"])
+
+	// The series referring to the deleted pod or its containers are dropped.
+	now = now.Add(30 * time.Second)
+	series = collectSeries(t, c)
+	assert.Equal(t, map[string]float64{
+		"kindling_entity_request_total":                             4,
+		"kindling_entity_request_total,namespace=default,pod=web-2": 3,
+	}, series)
+}
+
+func TestWithoutPodCheckpointers(t *testing.T) {
+	factory := otelprocessor.NewFactory(simple.NewWithInexpensiveDistribution(), aggregation.CumulativeTemporalitySelector())
+	wrapped, podCheckpointers := withPodCheckpointers(factory, 0)
+	assert.Nil(t, podCheckpointers)
+	assert.Equal(t, factory, wrapped)
+}
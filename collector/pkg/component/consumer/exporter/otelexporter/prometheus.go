@@ -7,38 +7,60 @@ import (
 	"sync"
 
 	"github.com/Kindling-project/kindling/collector/pkg/component"
-	"go.opentelemetry.io/otel/exporters/prometheus"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 var (
-	mu   sync.Mutex
-	srv  *http.Server
+	mu      sync.Mutex
+	servers []*http.Server
 )
 
-func StartServer(exporter *prometheus.Exporter, telemetry *component.TelemetryTools, port string) error {
+// StartServer serves the metrics on "/metrics" of the port. If shards are configured, the series of each
+// shard are served on its own path and listener, and "/metrics" serves the series not matched by any shard.
+// The scrapes select the metric groups to serve by the parameters "collect[]". The stale counters and
+// histograms are hidden if "stale_timeout" is set.
+func StartServer(gatherer prometheus.Gatherer, telemetry *component.TelemetryTools, cfg *PrometheusConfig) error {
 	mu.Lock()
 	defer mu.Unlock()
 
-	if srv != nil {
+	for _, srv := range servers {
 		if err := srv.Shutdown(context.Background()); err != nil {
 			return fmt.Errorf("failed to stop server: %w", err)
 		}
 	}
+	servers = nil
 
-	mux := http.NewServeMux()
-	mux.HandleFunc("/metrics", exporter.ServeHTTP)
-
-	srv = &http.Server{
-		Addr:    port,
-		Handler: mux,
+	shards, err := newMetricShards(cfg.Shards, cfg.Port)
+	if err != nil {
+		return fmt.Errorf("invalid shards: %w", err)
+	}
+	gatherer = newStalenessGatherer(gatherer, cfg)
+	muxes := map[string]*http.ServeMux{cfg.Port: http.NewServeMux()}
+	muxes[cfg.Port].Handle("/metrics", newMetricsHandler(gatherer, shards, len(shards)))
+	for i, shard := range shards {
+		mux, ok := muxes[shard.port]
+		if !ok {
+			mux = http.NewServeMux()
+			muxes[shard.port] = mux
+		}
+		mux.Handle(shard.path, newMetricsHandler(gatherer, shards, i))
+		telemetry.Logger.Infof("Prometheus Server serves the shard [%s] at port: [%s]", shard.path, shard.port)
 	}
 
-	telemetry.Logger.Infof("Prometheus Server listening at port: [%s]", port)
+	for port, mux := range muxes {
+		srv := &http.Server{
+			Addr:    port,
+			Handler: mux,
+		}
+		servers = append(servers, srv)
+
+		telemetry.Logger.Infof("Prometheus Server listening at port: [%s]", port)
 
-	go func() {
-		srv.ListenAndServe()
-		telemetry.Logger.Infof("Prometheus gracefully shutdown the http server...\n")
-	}()
+		go func() {
+			srv.ListenAndServe()
+			telemetry.Logger.Infof("Prometheus gracefully shutdown the http server...\n")
+		}()
+	}
 
 	return nil
 }
@@ -0,0 +1,198 @@
+package otelexporter
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	dto "github.com/prometheus/client_model/go"
+
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constvalues"
+)
+
+// metricGroup selects the series whose names start with one of the prefixes. If protocols is not empty,
+// only the series whose label "protocol" is one of them are selected.
+type metricGroup struct {
+	prefixes  []string
+	protocols []string
+}
+
+// metricGroups are the groups of the metrics that can be served by a shard or selected by a scrape.
+var metricGroups = map[string]metricGroup{
+	"topology": {prefixes: []string{"kindling_topology_request_"}},
+	"red": {prefixes: []string{"kindling_entity_request_", "kindling_workload_request_", "kindling_server_queue_",
+		"kindling_connection_pool_", "kindling_connection_reuse_", "kindling_bulk_transfer_"}},
+	"dns": {
+		prefixes:  []string{"kindling_entity_request_", "kindling_topology_request_"},
+		protocols: []string{constvalues.ProtocolDns},
+	},
+	"tcp":   {prefixes: []string{"kindling_tcp_"}},
+	"trace": {prefixes: []string{"kindling_trace_request_"}},
+}
+
+type metricShard struct {
+	path   string
+	port   string
+	groups []metricGroup
+}
+
+func newMetricShards(configs []ShardConfig, defaultPort string) ([]*metricShard, error) {
+	shards := make([]*metricShard, 0, len(configs))
+	paths := make(map[string]bool)
+	for _, config := range configs {
+		shard := &metricShard{path: config.Path, port: config.Port}
+		if shard.port == "" {
+			shard.port = defaultPort
+		}
+		if !strings.HasPrefix(shard.path, "/") {
+			return nil, fmt.Errorf("the path of the shard must start with \"/\": %q", config.Path)
+		}
+		if key := shard.port + shard.path; paths[key] || (shard.port == defaultPort && shard.path == "/metrics") {
+			return nil, fmt.Errorf("the path %q is served more than once on the port %q", shard.path, shard.port)
+		} else {
+			paths[key] = true
+		}
+		groups, err := getMetricGroups(config.MetricGroups)
+		if err != nil {
+			return nil, fmt.Errorf("invalid shard %q: %w", config.Path, err)
+		}
+		shard.groups = groups
+		if len(config.MetricPrefixes) > 0 {
+			shard.groups = append(shard.groups, metricGroup{prefixes: config.MetricPrefixes})
+		}
+		if len(shard.groups) == 0 {
+			return nil, fmt.Errorf("no metrics are served by the shard %q", config.Path)
+		}
+		shards = append(shards, shard)
+	}
+	return shards, nil
+}
+
+func (s *metricShard) match(name string, metric *dto.Metric) bool {
+	for _, group := range s.groups {
+		if group.match(name, metric) {
+			return true
+		}
+	}
+	return false
+}
+
+func (g metricGroup) match(name string, metric *dto.Metric) bool {
+	matched := false
+	for _, prefix := range g.prefixes {
+		if strings.HasPrefix(name, prefix) {
+			matched = true
+			break
+		}
+	}
+	if !matched || len(g.protocols) == 0 {
+		return matched
+	}
+	for _, label := range metric.GetLabel() {
+		if label.GetName() != constlabels.Protocol {
+			continue
+		}
+		for _, protocol := range g.protocols {
+			if label.GetValue() == protocol {
+				return true
+			}
+		}
+		return false
+	}
+	return false
+}
+
+// getMetricGroups returns the groups of the names, which select the series to serve by the parameters
+// "collect[]" of the scrapes.
+func getMetricGroups(names []string) ([]metricGroup, error) {
+	groups := make([]metricGroup, 0, len(names))
+	for _, name := range names {
+		group, ok := metricGroups[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown metric group %q", name)
+		}
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+// shardGatherer gathers the series served by one of the shards. Each series is served by the first shard
+// matching it, so no series is served twice. If groups is not empty, only the series of the groups are
+// gathered.
+type shardGatherer struct {
+	gatherer prometheus.Gatherer
+	shards   []*metricShard
+	// index is the shard served. The series not matched by any shard are served if it is len(shards).
+	index  int
+	groups []metricGroup
+}
+
+func (g *shardGatherer) Gather() ([]*dto.MetricFamily, error) {
+	families, err := g.gatherer.Gather()
+	filtered := make([]*dto.MetricFamily, 0, len(families))
+	for _, family := range families {
+		metrics := make([]*dto.Metric, 0, len(family.Metric))
+		for _, metric := range family.Metric {
+			if g.match(family.GetName(), metric) {
+				metrics = append(metrics, metric)
+			}
+		}
+		if len(metrics) == 0 {
+			continue
+		}
+		filtered = append(filtered, &dto.MetricFamily{
+			Name:   family.Name,
+			Help:   family.Help,
+			Type:   family.Type,
+			Metric: metrics,
+		})
+	}
+	return filtered, err
+}
+
+func (g *shardGatherer) match(name string, metric *dto.Metric) bool {
+	if g.shardOf(name, metric) != g.index {
+		return false
+	}
+	if len(g.groups) == 0 {
+		return true
+	}
+	for _, group := range g.groups {
+		if group.match(name, metric) {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *shardGatherer) shardOf(name string, metric *dto.Metric) int {
+	for i, shard := range g.shards {
+		if shard.match(name, metric) {
+			return i
+		}
+	}
+	return len(g.shards)
+}
+
+// newMetricsHandler serves the series of the shard. The series are filtered by the metric groups of the
+// parameters "collect[]" if any, e.g. "/metrics?collect[]=topology&collect[]=tcp".
+func newMetricsHandler(gatherer prometheus.Gatherer, shards []*metricShard, index int) http.Handler {
+	all := promhttp.HandlerFor(&shardGatherer{gatherer: gatherer, shards: shards, index: index}, promhttp.HandlerOpts{})
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		names := r.URL.Query()["collect[]"]
+		if len(names) == 0 {
+			all.ServeHTTP(w, r)
+			return
+		}
+		groups, err := getMetricGroups(names)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		promhttp.HandlerFor(&shardGatherer{gatherer: gatherer, shards: shards, index: index, groups: groups},
+			promhttp.HandlerOpts{}).ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,109 @@
+package otelexporter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func gatherSeries(t *testing.T, gatherer prometheus.Gatherer) map[string]int {
+	families, err := gatherer.Gather()
+	require.NoError(t, err)
+	series := make(map[string]int)
+	for _, family := range families {
+		series[family.GetName()] = len(family.Metric)
+	}
+	return series
+}
+
+func TestShardGatherer(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	entity := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "kindling_entity_request_total"}, []string{"protocol"})
+	topology := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "kindling_topology_request_total"}, []string{"protocol"})
+	srtt := prometheus.NewGauge(prometheus.GaugeOpts{Name: "kindling_tcp_srtt_microseconds"})
+	info := prometheus.NewGauge(prometheus.GaugeOpts{Name: "kindling_k8s_workload_info"})
+	registry.MustRegister(entity, topology, srtt, info)
+	entity.WithLabelValues("dns").Set(1)
+	entity.WithLabelValues("http").Set(1)
+	topology.WithLabelValues("dns").Set(1)
+	topology.WithLabelValues("http").Set(1)
+	srtt.Set(1)
+	info.Set(1)
+
+	shards, err := newMetricShards([]ShardConfig{
+		{Path: "/metrics/dns", MetricGroups: []string{"dns"}},
+		{Path: "/metrics/red", Port: ":9501", MetricGroups: []string{"red"}},
+		{Path: "/metrics/network", MetricGroups: []string{"topology", "tcp"}, MetricPrefixes: []string{"kindling_k8s_"}},
+	}, ":9500")
+	require.NoError(t, err)
+	assert.Equal(t, ":9500", shards[0].port)
+	assert.Equal(t, ":9501", shards[1].port)
+
+	assert.Equal(t, map[string]int{"kindling_entity_request_total": 1, "kindling_topology_request_total": 1},
+		gatherSeries(t, &shardGatherer{gatherer: registry, shards: shards, index: 0}))
+	assert.Equal(t, map[string]int{"kindling_entity_request_total": 1},
+		gatherSeries(t, &shardGatherer{gatherer: registry, shards: shards, index: 1}))
+	assert.Equal(t, map[string]int{"kindling_topology_request_total": 1, "kindling_tcp_srtt_microseconds": 1, "kindling_k8s_workload_info": 1},
+		gatherSeries(t, &shardGatherer{gatherer: registry, shards: shards, index: 2}))
+	assert.Empty(t, gatherSeries(t, &shardGatherer{gatherer: registry, shards: shards, index: 3}))
+
+	shards, err = newMetricShards([]ShardConfig{{Path: "/metrics/tcp", MetricGroups: []string{"tcp"}}}, ":9500")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"kindling_entity_request_total": 2, "kindling_topology_request_total": 2, "kindling_k8s_workload_info": 1},
+		gatherSeries(t, &shardGatherer{gatherer: registry, shards: shards, index: 1}))
+}
+
+func TestNewMetricShardsInvalid(t *testing.T) {
+	for _, configs := range [][]ShardConfig{
+		{{Path: "/metrics", MetricGroups: []string{"tcp"}}},
+		{{Path: "metrics/tcp", MetricGroups: []string{"tcp"}}},
+		{{Path: "/tcp", MetricGroups: []string{"tcp"}}, {Path: "/tcp", MetricGroups: []string{"red"}}},
+		{{Path: "/unknown", MetricGroups: []string{"unknown"}}},
+		{{Path: "/empty"}},
+	} {
+		_, err := newMetricShards(configs, ":9500")
+		assert.Error(t, err, "%v", configs)
+	}
+	_, err := newMetricShards([]ShardConfig{{Path: "/metrics", Port: ":9501", MetricGroups: []string{"tcp"}}}, ":9500")
+	assert.NoError(t, err)
+}
+
+func TestMetricsHandlerCollect(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	topology := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "kindling_topology_request_total"}, []string{"protocol"})
+	srtt := prometheus.NewGauge(prometheus.GaugeOpts{Name: "kindling_tcp_srtt_microseconds"})
+	registry.MustRegister(topology, srtt)
+	topology.WithLabelValues("dns").Set(1)
+	topology.WithLabelValues("http").Set(1)
+	srtt.Set(1)
+	handler := newMetricsHandler(registry, nil, 0)
+
+	scrape := func(url string) (int, string) {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, url, nil))
+		return recorder.Code, recorder.Body.String()
+	}
+	code, body := scrape("/metrics")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Contains(t, body, "kindling_topology_request_total")
+	assert.Contains(t, body, "kindling_tcp_srtt_microseconds")
+
+	code, body = scrape("/metrics?collect[]=tcp")
+	assert.Equal(t, http.StatusOK, code)
+	assert.NotContains(t, body, "kindling_topology_request_total")
+	assert.Contains(t, body, "kindling_tcp_srtt_microseconds")
+
+	code, body = scrape("/metrics?collect[]=dns&collect[]=tcp")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, 1, strings.Count(body, "kindling_topology_request_total{"))
+	assert.Contains(t, body, `protocol="dns"`)
+	assert.Contains(t, body, "kindling_tcp_srtt_microseconds")
+
+	code, _ = scrape("/metrics?collect[]=unknown")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
@@ -0,0 +1,120 @@
+package otelexporter
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+// maxSpanResources limits the number of the tracers kept for the services. The spans of more
+// services are exported with the default resource.
+const maxSpanResources = 1024
+
+var workloadKindKeys = map[string]attribute.Key{
+	"deployment":  semconv.K8SDeploymentNameKey,
+	"statefulset": semconv.K8SStatefulSetNameKey,
+	"daemonset":   semconv.K8SDaemonSetNameKey,
+	"replicaset":  semconv.K8SReplicaSetNameKey,
+	"job":         semconv.K8SJobNameKey,
+	"cronjob":     semconv.K8SCronJobNameKey,
+}
+
+// spanTracers holds a tracer for each service observed in the spans. The resource is bound to the
+// TracerProvider in OpenTelemetry, so the spans of different services need different providers.
+// All the providers share the same span processor, which exports the spans in batches.
+type spanTracers struct {
+	base      *resource.Resource
+	processor sdktrace.SpanProcessor
+	tracers   map[attribute.Distinct]trace.Tracer
+}
+
+func newSpanTracers(base *resource.Resource, processor sdktrace.SpanProcessor) *spanTracers {
+	return &spanTracers{
+		base:      base,
+		processor: processor,
+		tracers:   make(map[attribute.Distinct]trace.Tracer),
+	}
+}
+
+// get returns the tracer of the service the span belongs to, or nil if the service is unknown.
+func (s *spanTracers) get(resourceAttrs []attribute.KeyValue) trace.Tracer {
+	if len(resourceAttrs) == 0 {
+		return nil
+	}
+	set := attribute.NewSet(resourceAttrs...)
+	key := set.Equivalent()
+	if tracer, ok := s.tracers[key]; ok {
+		return tracer
+	}
+	if len(s.tracers) >= maxSpanResources {
+		return nil
+	}
+	rs, err := resource.Merge(s.base, resource.NewSchemaless(resourceAttrs...))
+	if err != nil {
+		return nil
+	}
+	tracer := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(sdktrace.AlwaysSample()),
+		sdktrace.WithSpanProcessor(s.processor),
+		sdktrace.WithResource(rs),
+	).Tracer(TracerName)
+	s.tracers[key] = tracer
+	return tracer
+}
+
+// mapSpanResource maps the workload of the span into the resource attributes defined by the semantic
+// conventions of OpenTelemetry. The server-side spans belong to the destination and the client-side
+// ones belong to the source, whose destination is added as "peer.service". So the service graphs of
+// Grafana Tempo are built from the spans without any instrumentation.
+func mapSpanResource(attrs []attribute.KeyValue) (resourceAttrs []attribute.KeyValue, kind trace.SpanKind, spanAttrs []attribute.KeyValue) {
+	values := make(map[attribute.Key]attribute.Value, len(attrs))
+	for _, attr := range attrs {
+		values[attr.Key] = attr.Value
+	}
+	if values[constlabels.IsServer].AsInt64() == 1 {
+		resourceAttrs = workloadResource(values[constlabels.DstNamespace].AsString(), values[constlabels.DstWorkloadKind].AsString(),
+			values[constlabels.DstWorkloadName].AsString(), values[constlabels.DstService].AsString(), values[constlabels.DstPod].AsString())
+		return resourceAttrs, trace.SpanKindServer, nil
+	}
+	resourceAttrs = workloadResource(values[constlabels.SrcNamespace].AsString(), values[constlabels.SrcWorkloadKind].AsString(),
+		values[constlabels.SrcWorkloadName].AsString(), values[constlabels.SrcService].AsString(), values[constlabels.SrcPod].AsString())
+	if peer := workloadServiceName(values[constlabels.DstNamespace].AsString(), values[constlabels.DstWorkloadName].AsString(),
+		values[constlabels.DstService].AsString()); peer != "" {
+		spanAttrs = []attribute.KeyValue{semconv.PeerServiceKey.String(peer)}
+	}
+	return resourceAttrs, trace.SpanKindClient, spanAttrs
+}
+
+func workloadResource(namespace string, workloadKind string, workloadName string, service string, pod string) []attribute.KeyValue {
+	name := workloadServiceName(namespace, workloadName, service)
+	if name == "" {
+		return nil
+	}
+	ret := []attribute.KeyValue{
+		semconv.ServiceNameKey.String(name),
+		semconv.K8SNamespaceNameKey.String(namespace),
+	}
+	if pod != "" {
+		ret = append(ret, semconv.K8SPodNameKey.String(pod))
+	}
+	if key, ok := workloadKindKeys[workloadKind]; ok && workloadName != "" {
+		ret = append(ret, key.String(workloadName))
+	}
+	return ret
+}
+
+// workloadServiceName prefers the workload to the service, as the pods of a workload may belong to multiple services.
+// The service is unknown if the workload is outside the cluster.
+func workloadServiceName(namespace string, workloadName string, service string) string {
+	if namespace == "" || constlabels.IsNamespaceNotFound(namespace) {
+		return ""
+	}
+	if workloadName != "" {
+		return workloadName
+	}
+	return service
+}
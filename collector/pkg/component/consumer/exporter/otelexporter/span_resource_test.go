@@ -0,0 +1,82 @@
+package otelexporter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+func newSpanAttrs(isServer int64, dstNamespace string) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String(constlabels.SrcNamespace, "default"),
+		attribute.String(constlabels.SrcWorkloadKind, "deployment"),
+		attribute.String(constlabels.SrcWorkloadName, "frontend"),
+		attribute.String(constlabels.SrcService, "frontend-svc"),
+		attribute.String(constlabels.SrcPod, "frontend-6d4cf56db6-x2x7k"),
+		attribute.String(constlabels.DstNamespace, dstNamespace),
+		attribute.String(constlabels.DstWorkloadKind, "statefulset"),
+		attribute.String(constlabels.DstWorkloadName, "backend"),
+		attribute.String(constlabels.DstService, "backend-svc"),
+		attribute.String(constlabels.DstPod, "backend-0"),
+		attribute.Int64(constlabels.IsServer, isServer),
+	}
+}
+
+func TestMapSpanResource(t *testing.T) {
+	resourceAttrs, kind, spanAttrs := mapSpanResource(newSpanAttrs(1, "db"))
+	assert.Equal(t, trace.SpanKindServer, kind)
+	assert.Equal(t, []attribute.KeyValue{
+		semconv.ServiceNameKey.String("backend"),
+		semconv.K8SNamespaceNameKey.String("db"),
+		semconv.K8SPodNameKey.String("backend-0"),
+		semconv.K8SStatefulSetNameKey.String("backend"),
+	}, resourceAttrs)
+	assert.Nil(t, spanAttrs)
+
+	resourceAttrs, kind, spanAttrs = mapSpanResource(newSpanAttrs(0, "db"))
+	assert.Equal(t, trace.SpanKindClient, kind)
+	assert.Equal(t, []attribute.KeyValue{
+		semconv.ServiceNameKey.String("frontend"),
+		semconv.K8SNamespaceNameKey.String("default"),
+		semconv.K8SPodNameKey.String("frontend-6d4cf56db6-x2x7k"),
+		semconv.K8SDeploymentNameKey.String("frontend"),
+	}, resourceAttrs)
+	assert.Equal(t, []attribute.KeyValue{semconv.PeerServiceKey.String("backend")}, spanAttrs)
+
+	// The destination outside the cluster is unknown.
+	_, _, spanAttrs = mapSpanResource(newSpanAttrs(0, constlabels.ExternalClusterNamespace))
+	assert.Nil(t, spanAttrs)
+	resourceAttrs, _, _ = mapSpanResource(newSpanAttrs(1, constlabels.ExternalClusterNamespace))
+	assert.Nil(t, resourceAttrs)
+}
+
+func TestSpanTracers(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	base := resource.NewSchemaless(semconv.ServiceNameKey.String("kindling"), semconv.ServiceInstanceIDKey.String("node-1"))
+	tracers := newSpanTracers(base, sdktrace.NewSimpleSpanProcessor(exporter))
+
+	assert.Nil(t, tracers.get(nil))
+	resourceAttrs, _, _ := mapSpanResource(newSpanAttrs(1, "db"))
+	tracer := tracers.get(resourceAttrs)
+	assert.Same(t, tracer, tracers.get(resourceAttrs))
+	_, span := tracer.Start(context.Background(), "span")
+	span.End()
+
+	spans := exporter.GetSpans()
+	if assert.Len(t, spans, 1) {
+		rs := spans[0].Resource.Set()
+		name, _ := rs.Value(semconv.ServiceNameKey)
+		assert.Equal(t, "backend", name.AsString())
+		instance, _ := rs.Value(semconv.ServiceInstanceIDKey)
+		assert.Equal(t, "node-1", instance.AsString())
+	}
+}
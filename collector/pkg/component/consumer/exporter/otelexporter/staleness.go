@@ -0,0 +1,154 @@
+package otelexporter
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// seriesState is the last value of a counter or a histogram seen by the scrapes.
+type seriesState struct {
+	count   float64
+	sum     float64
+	created time.Time
+	changed time.Time
+	// seen is the number of the gathering which saw the series last.
+	seen uint64
+}
+
+// stalenessGatherer tracks the counters and the histograms across the scrapes. The series not increased
+// for staleTimeout are not served, so Prometheus writes the staleness markers for them instead of
+// showing the flat lines of the deleted pods. They are served again once they are increased. If
+// createdSeries is true, the gauge "<name>_created" holds the time when each series was created in
+// seconds, which is reset when the series is reset.
+type stalenessGatherer struct {
+	gatherer      prometheus.Gatherer
+	staleTimeout  time.Duration
+	createdSeries bool
+	now           func() time.Time
+
+	mu           sync.Mutex
+	series       map[string]*seriesState
+	gathering    uint64
+	lastGathered time.Time
+}
+
+func newStalenessGatherer(gatherer prometheus.Gatherer, cfg *PrometheusConfig) prometheus.Gatherer {
+	if cfg.StaleTimeout <= 0 && !cfg.CreatedSeries {
+		return gatherer
+	}
+	return &stalenessGatherer{
+		gatherer:      gatherer,
+		staleTimeout:  cfg.StaleTimeout,
+		createdSeries: cfg.CreatedSeries,
+		now:           time.Now,
+		series:        make(map[string]*seriesState),
+		lastGathered:  time.Now(),
+	}
+}
+
+func (g *stalenessGatherer) Gather() ([]*dto.MetricFamily, error) {
+	families, err := g.gatherer.Gather()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	now := g.now()
+	g.gathering++
+	result := make([]*dto.MetricFamily, 0, len(families))
+	for _, family := range families {
+		if family.GetType() != dto.MetricType_COUNTER && family.GetType() != dto.MetricType_HISTOGRAM {
+			result = append(result, family)
+			continue
+		}
+		metrics := make([]*dto.Metric, 0, len(family.Metric))
+		created := make([]*dto.Metric, 0, len(family.Metric))
+		for _, metric := range family.Metric {
+			state := g.update(family.GetName(), metric, now)
+			if g.staleTimeout > 0 && now.Sub(state.changed) >= g.staleTimeout {
+				continue
+			}
+			metrics = append(metrics, metric)
+			if g.createdSeries {
+				created = append(created, &dto.Metric{
+					Label: metric.Label,
+					Gauge: &dto.Gauge{Value: floatPtr(float64(state.created.UnixNano()) / 1e9)},
+				})
+			}
+		}
+		if len(metrics) == 0 {
+			continue
+		}
+		result = append(result, &dto.MetricFamily{
+			Name:   family.Name,
+			Help:   family.Help,
+			Type:   family.Type,
+			Metric: metrics,
+		})
+		if g.createdSeries {
+			result = append(result, &dto.MetricFamily{
+				Name:   stringPtr(strings.TrimSuffix(family.GetName(), "_total") + "_created"),
+				Help:   stringPtr("The creation time of the series in seconds since the epoch."),
+				Type:   dto.MetricType_GAUGE.Enum(),
+				Metric: created,
+			})
+		}
+	}
+	// Forget the series which are gone, e.g. after the meter is restarted to free up memory.
+	for key, state := range g.series {
+		if state.seen != g.gathering {
+			delete(g.series, key)
+		}
+	}
+	g.lastGathered = now
+	return result, err
+}
+
+// update records the value of the series. A series first seen or reset is created after the previous
+// gathering at the latest, so the time of that gathering is taken as its creation time.
+func (g *stalenessGatherer) update(name string, metric *dto.Metric, now time.Time) *seriesState {
+	var count, sum float64
+	if metric.Counter != nil {
+		count = metric.Counter.GetValue()
+	} else if metric.Histogram != nil {
+		count = float64(metric.Histogram.GetSampleCount())
+		sum = metric.Histogram.GetSampleSum()
+	}
+	key := seriesKey(name, metric)
+	state, ok := g.series[key]
+	switch {
+	case !ok:
+		state = &seriesState{count: count, sum: sum, created: g.lastGathered, changed: now}
+		g.series[key] = state
+	case count < state.count:
+		state.created = g.lastGathered
+		fallthrough
+	case count != state.count || sum != state.sum:
+		state.count, state.sum = count, sum
+		state.changed = now
+	}
+	state.seen = g.gathering
+	return state
+}
+
+func seriesKey(name string, metric *dto.Metric) string {
+	var builder strings.Builder
+	builder.WriteString(name)
+	for _, label := range metric.GetLabel() {
+		builder.WriteByte(0xff)
+		builder.WriteString(label.GetName())
+		builder.WriteByte('=')
+		builder.WriteString(label.GetValue())
+	}
+	return builder.String()
+}
+
+func floatPtr(value float64) *float64 {
+	return &value
+}
+
+func stringPtr(value string) *string {
+	return &value
+}
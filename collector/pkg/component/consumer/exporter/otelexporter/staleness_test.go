@@ -0,0 +1,59 @@
+package otelexporter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStalenessGatherer(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	requests := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "kindling_entity_request_total"}, []string{"dst_pod"})
+	srtt := prometheus.NewGauge(prometheus.GaugeOpts{Name: "kindling_tcp_srtt_microseconds"})
+	registry.MustRegister(requests, srtt)
+	requests.WithLabelValues("deleted").Inc()
+	requests.WithLabelValues("alive").Inc()
+	srtt.Set(1)
+
+	start := time.Unix(1000, 0)
+	now := start
+	gatherer := newStalenessGatherer(registry, &PrometheusConfig{StaleTimeout: time.Minute, CreatedSeries: true}).(*stalenessGatherer)
+	gatherer.now = func() time.Time { return now }
+	gatherer.lastGathered = start
+
+	series := gatherSeries(t, gatherer)
+	assert.Equal(t, map[string]int{"kindling_entity_request_total": 2, "kindling_entity_request_created": 2,
+		"kindling_tcp_srtt_microseconds": 1}, series)
+
+	now = start.Add(30 * time.Second)
+	requests.WithLabelValues("alive").Inc()
+	gatherSeries(t, gatherer)
+	now = start.Add(70 * time.Second)
+	requests.WithLabelValues("alive").Inc()
+	families, err := gatherer.Gather()
+	require.NoError(t, err)
+	series = make(map[string]int)
+	for _, family := range families {
+		series[family.GetName()] = len(family.Metric)
+		if family.GetName() == "kindling_entity_request_created" {
+			assert.Equal(t, "alive", family.Metric[0].Label[0].GetValue())
+			assert.Equal(t, float64(1000), family.Metric[0].Gauge.GetValue())
+		}
+	}
+	// The deleted pod is not increased for a minute, but the gauges are kept.
+	assert.Equal(t, map[string]int{"kindling_entity_request_total": 1, "kindling_entity_request_created": 1,
+		"kindling_tcp_srtt_microseconds": 1}, series)
+
+	// The series is served again once it is increased.
+	now = start.Add(80 * time.Second)
+	requests.WithLabelValues("deleted").Inc()
+	assert.Equal(t, 2, gatherSeries(t, gatherer)["kindling_entity_request_total"])
+}
+
+func TestStalenessGathererDisabled(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	assert.Equal(t, prometheus.Gatherer(registry), newStalenessGatherer(registry, &PrometheusConfig{}))
+}
@@ -0,0 +1,28 @@
+package otelexporter
+
+import (
+	"fmt"
+
+	"go.opentelemetry.io/otel/sdk/export/metric/aggregation"
+)
+
+const (
+	CumulativeTemporality = "cumulative"
+	DeltaTemporality      = "delta"
+)
+
+// newTemporalitySelector returns the selector for the configured temporality.
+// A nil selector is returned if the temporality is empty, which means the
+// default behavior of the exporter should be kept.
+func newTemporalitySelector(temporality string) (aggregation.TemporalitySelector, error) {
+	switch temporality {
+	case "":
+		return nil, nil
+	case CumulativeTemporality:
+		return aggregation.CumulativeTemporalitySelector(), nil
+	case DeltaTemporality:
+		return aggregation.DeltaTemporalitySelector(), nil
+	default:
+		return nil, fmt.Errorf("unsupported temporality %q, expected %q or %q", temporality, CumulativeTemporality, DeltaTemporality)
+	}
+}
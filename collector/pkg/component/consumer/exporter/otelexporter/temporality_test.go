@@ -0,0 +1,35 @@
+package otelexporter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/metric/sdkapi"
+	"go.opentelemetry.io/otel/sdk/export/metric/aggregation"
+)
+
+func TestNewTemporalitySelector(t *testing.T) {
+	desc := sdkapi.NewDescriptor("test", sdkapi.CounterInstrumentKind, 0, "", "")
+	tests := []struct {
+		temporality string
+		wantNil     bool
+		want        aggregation.Temporality
+		wantErr     bool
+	}{
+		{temporality: "", wantNil: true},
+		{temporality: CumulativeTemporality, want: aggregation.CumulativeTemporality},
+		{temporality: DeltaTemporality, want: aggregation.DeltaTemporality},
+		{temporality: "unknown", wantNil: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.temporality, func(t *testing.T) {
+			selector, err := newTemporalitySelector(tt.temporality)
+			assert.Equal(t, tt.wantErr, err != nil)
+			if tt.wantNil {
+				assert.Nil(t, selector)
+				return
+			}
+			assert.Equal(t, tt.want, selector.TemporalityFor(&desc, aggregation.SumKind))
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package pluginexporter
+
+import "github.com/Kindling-project/kindling/collector/pkg/component/consumer/exporter/forwardexporter"
+
+type Config struct {
+	// Enable streams the records with the Kubernetes metadata to the sidecar plugin, besides the processors.
+	Enable bool `mapstructure:"enable"`
+	// Endpoint is the address of the sidecar, usually a Unix domain socket like "unix:///var/run/kindling/plugin.sock".
+	Endpoint string `mapstructure:"endpoint"`
+	// BatchSize is the max number of the records sent in a request.
+	BatchSize int `mapstructure:"batch_size"`
+	// FlushInterval is the max time the records wait before being sent. The unit is milliseconds.
+	FlushInterval int `mapstructure:"flush_interval"`
+	// QueueSize is the max number of the records waiting to be sent. The new records are dropped
+	// if the queue is full, e.g. the sidecar is slow or unavailable.
+	QueueSize int `mapstructure:"queue_size"`
+	// Timeout is how long a batch waits for the window granted by the sidecar before being dropped.
+	// The unit is seconds.
+	Timeout int `mapstructure:"timeout"`
+}
+
+func NewDefaultConfig() *Config {
+	return &Config{
+		Enable:        false,
+		Endpoint:      "unix:///var/run/kindling/plugin.sock",
+		BatchSize:     500,
+		FlushInterval: 1000,
+		QueueSize:     10000,
+		Timeout:       5,
+	}
+}
+
+// forwardConfig returns the config of the forwardexporter sending the records, as the sidecar serves
+// the same stream as the aggregator.
+func (c *Config) forwardConfig() *forwardexporter.Config {
+	return &forwardexporter.Config{
+		Enable:        c.Enable,
+		Endpoint:      c.Endpoint,
+		BatchSize:     c.BatchSize,
+		FlushInterval: c.FlushInterval,
+		QueueSize:     c.QueueSize,
+		Timeout:       c.Timeout,
+	}
+}
@@ -0,0 +1,17 @@
+package pluginexporter
+
+import (
+	"github.com/Kindling-project/kindling/collector/pkg/component"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/exporter"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/exporter/forwardexporter"
+)
+
+const Type = "pluginexporter"
+
+// New creates the exporter streaming the records to the sidecar plugin, see pkg/plugin. The records are
+// converted once they are consumed, so the caller could reuse them. The sidecar pushes back by its window,
+// and the records are dropped instead of blocking the pipeline once the queue is full.
+func New(config interface{}, telemetry *component.TelemetryTools) exporter.Exporter {
+	cfg, _ := config.(*Config)
+	return forwardexporter.New(cfg.forwardConfig(), telemetry)
+}
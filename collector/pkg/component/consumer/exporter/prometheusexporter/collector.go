@@ -8,6 +8,7 @@ import (
 	"github.com/Kindling-project/kindling/collector/pkg/model"
 	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
 	"github.com/Kindling-project/kindling/collector/pkg/model/constvalues"
+	"github.com/Kindling-project/kindling/collector/pkg/model/timeunit"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -93,7 +94,7 @@ func newCollector(config *Config, _ *component.TelemetryLogger) *collector {
 }
 
 func getTimestamp(ts uint64) time.Time {
-	return time.UnixMicro(int64(ts / 1000))
+	return time.UnixMicro(timeunit.Timestamp(ts).In(timeunit.Microsecond))
 }
 
 // Describe is a no-op, because the collector dynamically allocates metrics.
@@ -85,6 +85,40 @@ func updateProtocolKey(key *extraLabelsKey, labels *model.AttributeMap) *extraLa
 		key.protocol = REDIS
 	case constvalues.ProtocolRocketMQ:
 		key.protocol = ROCKETMQ
+	case constvalues.ProtocolMongodb:
+		key.protocol = MONGODB
+	case constvalues.ProtocolTars:
+		key.protocol = TARS
+	case constvalues.ProtocolBrpc:
+		key.protocol = BRPC
+	case constvalues.ProtocolBolt:
+		key.protocol = BOLT
+	case constvalues.ProtocolCassandra:
+		key.protocol = CASSANDRA
+	case constvalues.ProtocolFtp:
+		key.protocol = FTP
+	case constvalues.ProtocolSsh:
+		key.protocol = SSH
+	case constvalues.ProtocolMqtt:
+		key.protocol = MQTT
+	case constvalues.ProtocolLdap:
+		key.protocol = LDAP
+	case constvalues.ProtocolOracle:
+		key.protocol = ORACLE
+	case constvalues.ProtocolPostgresql:
+		key.protocol = POSTGRESQL
+	case constvalues.ProtocolWebsocket:
+		key.protocol = WEBSOCKET
+	case constvalues.ProtocolHttp2:
+		key.protocol = HTTP2
+	case constvalues.ProtocolQuic:
+		key.protocol = QUIC
+	case constvalues.ProtocolSip:
+		key.protocol = SIP
+	case constvalues.ProtocolTls:
+		key.protocol = TLS
+	case constvalues.ProtocolTriple:
+		key.protocol = TRIPLE
 	default:
 		key.protocol = UNSUPPORTED
 	}
@@ -307,6 +341,8 @@ func (m *LabelConverter) transform(group *model.DataGroup) (*model.AttributeMap,
 			} else {
 				attrsMap.AddStringValue(attrs.metricsDicList[i].newKey, constvalues.ProtocolNoErrorStatus)
 			}
+		case FromStringOrProtocolError:
+			attrsMap.AddStringValue(attrs.metricsDicList[i].newKey, stringOrProtocolError(labels, attrs.metricsDicList[i].originKey))
 		case StrEmpty:
 			attrsMap.AddStringValue(attrs.metricsDicList[i].newKey, constlabels.STR_EMPTY)
 		}
@@ -374,6 +410,8 @@ func (m *LabelConverter) convert(group *model.DataGroup) ([]attribute.KeyValue,
 			} else {
 				attrsList[attrs.sortMap[i]].Value = attribute.StringValue(constvalues.ProtocolNoErrorStatus)
 			}
+		case FromStringOrProtocolError:
+			attrsList[attrs.sortMap[i]].Value = attribute.StringValue(stringOrProtocolError(labels, attrs.metricsDicList[i].originKey))
 		case StrEmpty:
 			attrsList[attrs.sortMap[i]].Value = attribute.StringValue(constlabels.STR_EMPTY)
 		}
@@ -438,3 +476,14 @@ func (a *attrsMapPool) Get() interface{} {
 func (a *attrsMapPool) Free(attributeMap *model.AttributeMap) {
 	a.attrsPool.Put(attributeMap)
 }
+
+// stringOrProtocolError returns the label if it is not empty, otherwise "error" or "noerror".
+func stringOrProtocolError(labels *model.AttributeMap, key string) string {
+	if value := labels.GetStringValue(key); value != "" {
+		return value
+	}
+	if labels.GetIntValue(constlabels.ErrorType) == constlabels.ProtocolError {
+		return constvalues.ProtocolError
+	}
+	return constvalues.ProtocolNoError
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
 	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
 	"github.com/Kindling-project/kindling/collector/pkg/model/constvalues"
+	"github.com/Kindling-project/kindling/collector/pkg/model/timeunit"
 	"go.opentelemetry.io/otel/attribute"
 )
 
@@ -18,6 +19,20 @@ type NetAdapterConfig struct {
 	StoreTraceAsSpan   bool
 	StorePodDetail     bool
 	StoreExternalSrcIP bool
+	// StoreAggregationWindow adds the label "aggregation_window" to the aggregated metrics
+	// so that the results of multiple aggregation windows are exported as separate series.
+	StoreAggregationWindow bool
+	// StoreHealthCheck adds the label "is_health_check" to the aggregated metrics
+	// so that the health checks are exported as separate series.
+	StoreHealthCheck bool
+	// StoreProtocolVersion adds the label "protocol_version" to the aggregated metrics
+	// so that the migrations between the protocol versions can be tracked.
+	StoreProtocolVersion bool
+	// TimestampUnit is the unit of the timestamps in the spans. The label "timestamp" is exported in
+	// milliseconds and "end_timestamp" in nanoseconds if it is empty.
+	TimestampUnit timeunit.Unit
+	// DurationUnit is the unit of the durations in the spans. It is nanoseconds if it is empty.
+	DurationUnit timeunit.Unit
 }
 
 func (n *NetMetricGroupAdapter) Adapt(dataGroup *model.DataGroup, attrType AttrType) ([]*AdaptedResult, error) {
@@ -157,66 +172,88 @@ type NetAdapterManager struct {
 	traceToMetricAdapter  *LabelConverter
 }
 
-func createNetAdapterManager(constLabels []attribute.KeyValue) *NetAdapterManager {
+func createNetAdapterManager(constLabels []attribute.KeyValue, config *NetAdapterConfig) *NetAdapterManager {
+	// metricDicts appends the optional dictionaries shared by the aggregated metrics.
+	metricDicts := func(dicts ...[]dictionary) [][]dictionary {
+		if config != nil && config.StoreAggregationWindow {
+			dicts = append(dicts, aggregationWindowDicList)
+		}
+		if config != nil && config.StoreHealthCheck {
+			dicts = append(dicts, healthCheckDicList)
+		}
+		if config != nil && config.StoreProtocolVersion {
+			dicts = append(dicts, protocolVersionDicList)
+		}
+		return dicts
+	}
+
 	// TODO deal Error
 	aggEntityAdapterWithIsSlow, _ := newAdapterBuilder(entityMetricDicList,
-		[][]dictionary{isSlowDicList}).
+		metricDicts(isSlowDicList)).
 		withExtraLabels(entityProtocol, updateProtocolKey).
 		withConstLabels(constLabels).
 		build()
 
 	detailEntityAdapterWithIsSlow, _ := newAdapterBuilder(entityMetricDicList,
-		[][]dictionary{entityInstanceMetricDicList, entityDetailMetricDicList, isSlowDicList}).
+		metricDicts(entityInstanceMetricDicList, entityDetailMetricDicList, isSlowDicList)).
 		withExtraLabels(entityProtocol, updateProtocolKey).
 		withConstLabels(constLabels).
 		build()
 
 	aggTopologyAdapterWithIsSlow, _ := newAdapterBuilder(topologyMetricDicList,
-		[][]dictionary{isSlowDicList}).
+		metricDicts(isSlowDicList)).
 		withExtraLabels(topologyProtocol, updateProtocolKey).
 		withAdjust(removeDstPodInfoForNonExternal()).
 		withConstLabels(constLabels).
 		build()
 
 	detailTopologyAdapterWithIsSlow, _ := newAdapterBuilder(topologyMetricDicList,
-		[][]dictionary{topologyInstanceMetricDicList, topologyDetailMetricDicList, isSlowDicList}).
+		metricDicts(topologyInstanceMetricDicList, topologyDetailMetricDicList, isSlowDicList)).
 		withExtraLabels(topologyProtocol, updateProtocolKey).
 		withAdjust(replaceDstIpOrDstPortByDNat()).
 		withConstLabels(constLabels).
 		build()
 
 	aggEntityAdapter, _ := newAdapterBuilder(entityMetricDicList,
-		[][]dictionary{}).
+		metricDicts()).
 		withExtraLabels(entityProtocol, updateProtocolKey).
 		withConstLabels(constLabels).
 		build()
 
 	detailEntityAdapter, _ := newAdapterBuilder(entityMetricDicList,
-		[][]dictionary{entityInstanceMetricDicList, entityDetailMetricDicList}).
+		metricDicts(entityInstanceMetricDicList, entityDetailMetricDicList)).
 		withExtraLabels(entityProtocol, updateProtocolKey).
 		withConstLabels(constLabels).
 		build()
 
 	aggTopologyAdapter, _ := newAdapterBuilder(topologyMetricDicList,
-		[][]dictionary{}).
+		metricDicts()).
 		withExtraLabels(topologyProtocol, updateProtocolKey).
 		withAdjust(removeDstPodInfoForNonExternal()).
 		withConstLabels(constLabels).
 		build()
 
 	detailTopologyAdapter, _ := newAdapterBuilder(topologyMetricDicList,
-		[][]dictionary{topologyInstanceMetricDicList, topologyDetailMetricDicList}).
+		metricDicts(topologyInstanceMetricDicList, topologyDetailMetricDicList)).
 		withExtraLabels(topologyProtocol, updateProtocolKey).
 		withAdjust(replaceDstIpOrDstPortByDNat()).
 		withConstLabels(constLabels).
 		build()
 
-	traceToSpanAdapter, _ := newAdapterBuilder(topologyMetricDicList,
-		[][]dictionary{topologyInstanceMetricDicList, SpanDicList, dNatDicList}).
+	var timestampUnit, durationUnit timeunit.Unit
+	if config != nil {
+		timestampUnit, durationUnit = config.TimestampUnit, config.DurationUnit
+	}
+	traceSpanStatus, getTraceSpanStatusLabels := newTraceSpanStatus(timestampUnit, durationUnit)
+	traceToSpanBuilder := newAdapterBuilder(topologyMetricDicList,
+		[][]dictionary{topologyInstanceMetricDicList, SpanDicList, dNatDicList, dNatChainDicList}).
 		withExtraLabels(spanProtocol, updateProtocolKey).
 		withValueToLabels(traceSpanStatus, getTraceSpanStatusLabels).
-		withConstLabels(constLabels).
-		build()
+		withConstLabels(constLabels)
+	if timestampUnit != "" {
+		traceToSpanBuilder = traceToSpanBuilder.withAdjust(convertEndTimestamp(timestampUnit))
+	}
+	traceToSpanAdapter, _ := traceToSpanBuilder.build()
 
 	traceToMetricAdapter, _ := newAdapterBuilder(topologyMetricDicList,
 		[][]dictionary{topologyInstanceMetricDicList, topologyDetailMetricDicList, dNatDicList}).
@@ -240,7 +277,7 @@ func NewNetAdapter(
 	config *NetAdapterConfig,
 ) *NetMetricGroupAdapter {
 	return &NetMetricGroupAdapter{
-		NetAdapterManager: createNetAdapterManager(customLabels),
+		NetAdapterManager: createNetAdapterManager(customLabels, config),
 		NetAdapterConfig:  config,
 	}
 }
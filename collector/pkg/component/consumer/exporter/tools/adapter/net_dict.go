@@ -1,11 +1,15 @@
 package adapter
 
 import (
+	"strings"
+	"time"
+
 	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/Kindling-project/kindling/collector/pkg/model"
 	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
 	"github.com/Kindling-project/kindling/collector/pkg/model/constvalues"
+	"github.com/Kindling-project/kindling/collector/pkg/model/timeunit"
 )
 
 type Protocol int
@@ -20,6 +24,23 @@ const (
 	DUBBO
 	REDIS
 	ROCKETMQ
+	MONGODB
+	TARS
+	BRPC
+	BOLT
+	CASSANDRA
+	FTP
+	SSH
+	MQTT
+	LDAP
+	ORACLE
+	POSTGRESQL
+	WEBSOCKET
+	HTTP2
+	QUIC
+	SIP
+	TLS
+	TRIPLE
 	UNSUPPORTED
 )
 
@@ -33,10 +54,11 @@ const (
 	FromInt64ToString
 	FromProtoclErrorToString
 	FromProtocolErrorToStatus
+	// FromStringOrProtocolError takes the label if it is not empty, otherwise it is like FromProtoclErrorToString.
+	FromStringOrProtocolError
 )
 
 const (
-	millToNano   = 1000000
 	GreenStatus  = "1"
 	YellowStatus = "2"
 	RedStatus    = "3"
@@ -52,6 +74,18 @@ var isSlowDicList = []dictionary{
 	{constlabels.IsSlow, constlabels.IsSlow, Bool},
 }
 
+var aggregationWindowDicList = []dictionary{
+	{constlabels.AggregationWindow, constlabels.AggregationWindow, String},
+}
+
+var healthCheckDicList = []dictionary{
+	{constlabels.IsHealthCheck, constlabels.IsHealthCheck, Bool},
+}
+
+var protocolVersionDicList = []dictionary{
+	{constlabels.ProtocolVersion, constlabels.ProtocolVersion, String},
+}
+
 var topologyInstanceMetricDicList = []dictionary{
 	{constlabels.SrcIp, constlabels.SrcIp, String},
 	{constlabels.DstIp, constlabels.DstIp, String},
@@ -83,6 +117,10 @@ var dNatDicList = []dictionary{
 	{constlabels.DnatPort, constlabels.DnatPort, Int64},
 }
 
+var dNatChainDicList = []dictionary{
+	{constlabels.DnatChain, constlabels.DnatChain, String},
+}
+
 var topologyDetailMetricDicList = []dictionary{
 	{constlabels.SrcContainerId, constlabels.SrcContainerId, String},
 	{constlabels.SrcContainer, constlabels.SrcContainer, String},
@@ -110,6 +148,8 @@ var SpanDicList = []dictionary{
 	{constlabels.ResponseTid, constlabels.ResponseTid, Int64},
 	{constlabels.Comm, constlabels.Comm, String},
 	{constlabels.EndTimestamp, constlabels.EndTimestamp, Int64},
+	{constlabels.PayloadTruncated, constlabels.PayloadTruncated, Bool},
+	{constlabels.SpanProtocolVersion, constlabels.ProtocolVersion, String},
 }
 
 var topologyMetricDicList = []dictionary{
@@ -193,7 +233,7 @@ var entityProtocol = []extraLabelsParam{
 		{constlabels.ResponseContent, constlabels.HttpStatusCode, FromInt64ToString},
 	}, extraLabelsKey{HTTP}},
 	{[]dictionary{
-		{constlabels.RequestContent, constlabels.KafkaTopic, String},
+		{constlabels.RequestContent, constlabels.ContentKey, String},
 		{constlabels.ResponseContent, constlabels.STR_EMPTY, StrEmpty},
 	}, extraLabelsKey{KAFKA}},
 	{[]dictionary{
@@ -202,7 +242,7 @@ var entityProtocol = []extraLabelsParam{
 	}, extraLabelsKey{MYSQL}},
 	{[]dictionary{
 		{constlabels.RequestContent, constlabels.ContentKey, String},
-		{constlabels.ResponseContent, constlabels.HttpStatusCode, FromInt64ToString},
+		{constlabels.ResponseContent, constlabels.GrpcStatusCode, FromInt64ToString},
 	}, extraLabelsKey{GRPC}},
 	{[]dictionary{
 		{constlabels.RequestContent, constlabels.DnsDomain, String},
@@ -214,12 +254,80 @@ var entityProtocol = []extraLabelsParam{
 	}, extraLabelsKey{DUBBO}},
 	{[]dictionary{
 		{constlabels.RequestContent, constlabels.ContentKey, String},
-		{constlabels.ResponseContent, constlabels.STR_EMPTY, FromProtoclErrorToString},
+		{constlabels.ResponseContent, constlabels.RedisRedirect, FromStringOrProtocolError},
 	}, extraLabelsKey{REDIS}},
 	{[]dictionary{
 		{constlabels.RequestContent, constlabels.ContentKey, String},
 		{constlabels.ResponseContent, constlabels.RocketMQErrCode, FromInt64ToString},
 	}, extraLabelsKey{ROCKETMQ}},
+	{[]dictionary{
+		{constlabels.RequestContent, constlabels.ContentKey, String},
+		{constlabels.ResponseContent, constlabels.MongodbErrCode, FromInt64ToString},
+	}, extraLabelsKey{MONGODB}},
+	{[]dictionary{
+		{constlabels.RequestContent, constlabels.ContentKey, String},
+		{constlabels.ResponseContent, constlabels.TarsRetCode, FromInt64ToString},
+	}, extraLabelsKey{TARS}},
+	{[]dictionary{
+		{constlabels.RequestContent, constlabels.ContentKey, String},
+		{constlabels.ResponseContent, constlabels.BrpcErrorCode, FromInt64ToString},
+	}, extraLabelsKey{BRPC}},
+	{[]dictionary{
+		{constlabels.RequestContent, constlabels.ContentKey, String},
+		{constlabels.ResponseContent, constlabels.BoltResponseStatus, FromInt64ToString},
+	}, extraLabelsKey{BOLT}},
+	{[]dictionary{
+		{constlabels.RequestContent, constlabels.ContentKey, String},
+		{constlabels.ResponseContent, constlabels.CassandraErrCode, FromInt64ToString},
+	}, extraLabelsKey{CASSANDRA}},
+	{[]dictionary{
+		{constlabels.RequestContent, constlabels.ContentKey, String},
+		{constlabels.ResponseContent, constlabels.FtpReplyCode, FromInt64ToString},
+	}, extraLabelsKey{FTP}},
+	{[]dictionary{
+		{constlabels.RequestContent, constlabels.ContentKey, String},
+		{constlabels.ResponseContent, constlabels.SshDisconnectReason, FromInt64ToString},
+	}, extraLabelsKey{SSH}},
+	{[]dictionary{
+		{constlabels.RequestContent, constlabels.ContentKey, String},
+		{constlabels.ResponseContent, constlabels.MqttReasonCode, FromInt64ToString},
+	}, extraLabelsKey{MQTT}},
+	{[]dictionary{
+		{constlabels.RequestContent, constlabels.ContentKey, String},
+		{constlabels.ResponseContent, constlabels.LdapResultCode, FromInt64ToString},
+	}, extraLabelsKey{LDAP}},
+	{[]dictionary{
+		{constlabels.RequestContent, constlabels.ContentKey, String},
+		{constlabels.ResponseContent, constlabels.SqlErrCode, FromInt64ToString},
+	}, extraLabelsKey{ORACLE}},
+	{[]dictionary{
+		{constlabels.RequestContent, constlabels.ContentKey, String},
+		{constlabels.ResponseContent, constlabels.PostgresqlSqlState, String},
+	}, extraLabelsKey{POSTGRESQL}},
+	{[]dictionary{
+		{constlabels.RequestContent, constlabels.ContentKey, String},
+		{constlabels.ResponseContent, constlabels.WebsocketCloseCode, FromInt64ToString},
+	}, extraLabelsKey{WEBSOCKET}},
+	{[]dictionary{
+		{constlabels.RequestContent, constlabels.ContentKey, String},
+		{constlabels.ResponseContent, constlabels.HttpStatusCode, FromInt64ToString},
+	}, extraLabelsKey{HTTP2}},
+	{[]dictionary{
+		{constlabels.RequestContent, constlabels.ContentKey, String},
+		{constlabels.ResponseContent, constlabels.STR_EMPTY, FromProtoclErrorToString},
+	}, extraLabelsKey{QUIC}},
+	{[]dictionary{
+		{constlabels.RequestContent, constlabels.ContentKey, String},
+		{constlabels.ResponseContent, constlabels.SipStatusCode, FromInt64ToString},
+	}, extraLabelsKey{SIP}},
+	{[]dictionary{
+		{constlabels.RequestContent, constlabels.ContentKey, String},
+		{constlabels.ResponseContent, constlabels.TlsAlert, FromInt64ToString},
+	}, extraLabelsKey{TLS}},
+	{[]dictionary{
+		{constlabels.RequestContent, constlabels.ContentKey, String},
+		{constlabels.ResponseContent, constlabels.TripleStatusCode, FromInt64ToString},
+	}, extraLabelsKey{TRIPLE}},
 	{[]dictionary{
 		{constlabels.RequestContent, constlabels.STR_EMPTY, StrEmpty},
 		{constlabels.ResponseContent, constlabels.STR_EMPTY, StrEmpty},
@@ -237,10 +345,27 @@ var spanProtocol = []extraLabelsParam{
 		{constlabels.SpanHttpRequestBody, constlabels.STR_EMPTY, StrEmpty},
 		{constlabels.SpanHttpResponseHeaders, constlabels.ResponsePayload, String},
 		{constlabels.SpanHttpResponseBody, constlabels.STR_EMPTY, StrEmpty},
+		{constlabels.SpanHttpSessionHash, constlabels.HttpSessionHash, String},
+		{constlabels.SpanHttpSoapOperation, constlabels.HttpSoapOperation, String},
+		{constlabels.SpanHttpSoapFault, constlabels.HttpSoapFault, String},
+		{constlabels.SpanEsOperation, constlabels.EsOperation, String},
+		{constlabels.SpanEsIndex, constlabels.EsIndex, String},
+		{constlabels.SpanEsTook, constlabels.EsTook, Int64},
+		{constlabels.SpanEsStatus, constlabels.EsStatus, Int64},
+		{constlabels.SpanInfluxdbMeasurements, constlabels.InfluxdbMeasurements, String},
+		{constlabels.SpanInfluxdbPoints, constlabels.InfluxdbPoints, Int64},
+		{constlabels.SpanGraphqlOperation, constlabels.GraphqlOperation, String},
+		{constlabels.SpanGraphqlOperationType, constlabels.GraphqlOperationType, String},
+		{constlabels.SpanServiceDiscovery, constlabels.ServiceDiscovery, String},
+		{constlabels.SpanServiceDiscoveryOp, constlabels.ServiceDiscoveryOp, String},
 	}, extraLabelsKey{HTTP}},
 	{[]dictionary{
 		{constlabels.SpanRequestPayload, constlabels.RequestPayload, String},
 		{constlabels.SpanResponsePayload, constlabels.ResponsePayload, String},
+		{constlabels.SpanKafkaApi, constlabels.KafkaApi, Int64},
+		{constlabels.SpanKafkaTopic, constlabels.KafkaTopic, String},
+		{constlabels.SpanKafkaGroupId, constlabels.KafkaGroupId, String},
+		{constlabels.SpanKafkaPartition, constlabels.KafkaPartition, Int64},
 	}, extraLabelsKey{KAFKA}},
 	{[]dictionary{
 		{constlabels.SpanMysqlSql, constlabels.Sql, String},
@@ -252,6 +377,8 @@ var spanProtocol = []extraLabelsParam{
 	{[]dictionary{
 		{constlabels.SpanDnsDomain, constlabels.DnsDomain, String},
 		{constlabels.SpanDnsRCode, constlabels.DnsRcode, FromInt64ToString},
+		{constlabels.SpanServiceDiscovery, constlabels.ServiceDiscovery, String},
+		{constlabels.SpanServiceDiscoveryOp, constlabels.ServiceDiscoveryOp, String},
 		{constlabels.SpanRequestPayload, constlabels.RequestPayload, String},
 		{constlabels.SpanResponsePayload, constlabels.ResponsePayload, String},
 	}, extraLabelsKey{DNS}},
@@ -259,10 +386,14 @@ var spanProtocol = []extraLabelsParam{
 		{constlabels.SpanDubboRequestBody, constlabels.RequestPayload, String},
 		{constlabels.SpanDubboResponseBody, constlabels.ResponsePayload, String},
 		{constlabels.SpanDubboErrorCode, constlabels.DubboErrorCode, Int64},
+		{constlabels.SpanDubboArgTypes, constlabels.DubboArgumentTypes, String},
+		{constlabels.SpanDubboArgSizes, constlabels.DubboArgumentSizes, String},
 	}, extraLabelsKey{DUBBO}},
 	{[]dictionary{
 		{constlabels.SpanRedisCommand, constlabels.RedisCommand, String},
 		{constlabels.SpanRedisErrorMsg, constlabels.RedisErrMsg, String},
+		{constlabels.SpanRedisRedirect, constlabels.RedisRedirect, String},
+		{constlabels.SpanRedisRedirectTarget, constlabels.RedisRedirectTarget, String},
 		{constlabels.SpanRedisRequestPayload, constlabels.RequestPayload, String},
 		{constlabels.SpanRedisResponsePayload, constlabels.ResponsePayload, String},
 	}, extraLabelsKey{REDIS}},
@@ -272,6 +403,152 @@ var spanProtocol = []extraLabelsParam{
 		{constlabels.SpanRequestPayload, constlabels.RequestPayload, String},
 		{constlabels.SpanResponsePayload, constlabels.ResponsePayload, String},
 	}, extraLabelsKey{ROCKETMQ}},
+	{[]dictionary{
+		{constlabels.SpanMongodbCommand, constlabels.MongodbCommand, String},
+		{constlabels.SpanMongodbDatabase, constlabels.MongodbDatabase, String},
+		{constlabels.SpanMongodbCollection, constlabels.MongodbCollection, String},
+		{constlabels.SpanMongodbErrorCode, constlabels.MongodbErrCode, Int64},
+		{constlabels.SpanMongodbErrorMsg, constlabels.MongodbErrMsg, String},
+		{constlabels.SpanRequestPayload, constlabels.RequestPayload, String},
+		{constlabels.SpanResponsePayload, constlabels.ResponsePayload, String},
+	}, extraLabelsKey{MONGODB}},
+	{[]dictionary{
+		{constlabels.SpanTarsServantName, constlabels.TarsServantName, String},
+		{constlabels.SpanTarsFuncName, constlabels.TarsFuncName, String},
+		{constlabels.SpanTarsRetCode, constlabels.TarsRetCode, Int64},
+		{constlabels.SpanTarsResultDesc, constlabels.TarsResultDesc, String},
+		{constlabels.SpanRequestPayload, constlabels.RequestPayload, String},
+		{constlabels.SpanResponsePayload, constlabels.ResponsePayload, String},
+	}, extraLabelsKey{TARS}},
+	{[]dictionary{
+		{constlabels.SpanGrpcPath, constlabels.GrpcPath, String},
+		{constlabels.SpanGrpcStatusCode, constlabels.GrpcStatusCode, Int64},
+		{constlabels.SpanGrpcMessage, constlabels.GrpcMessage, String},
+		{constlabels.SpanHttpStatusCode, constlabels.HttpStatusCode, Int64},
+		{constlabels.SpanRequestPayload, constlabels.RequestPayload, String},
+		{constlabels.SpanResponsePayload, constlabels.ResponsePayload, String},
+	}, extraLabelsKey{GRPC}},
+	{[]dictionary{
+		{constlabels.SpanBrpcService, constlabels.BrpcService, String},
+		{constlabels.SpanBrpcMethod, constlabels.BrpcMethod, String},
+		{constlabels.SpanBrpcErrorCode, constlabels.BrpcErrorCode, Int64},
+		{constlabels.SpanBrpcErrorText, constlabels.BrpcErrorText, String},
+		{constlabels.SpanRequestPayload, constlabels.RequestPayload, String},
+		{constlabels.SpanResponsePayload, constlabels.ResponsePayload, String},
+	}, extraLabelsKey{BRPC}},
+	{[]dictionary{
+		{constlabels.SpanBoltService, constlabels.BoltService, String},
+		{constlabels.SpanBoltMethod, constlabels.BoltMethod, String},
+		{constlabels.SpanBoltResponseStatus, constlabels.BoltResponseStatus, Int64},
+		{constlabels.SpanRequestPayload, constlabels.RequestPayload, String},
+		{constlabels.SpanResponsePayload, constlabels.ResponsePayload, String},
+	}, extraLabelsKey{BOLT}},
+	{[]dictionary{
+		{constlabels.SpanCassandraOpcode, constlabels.CassandraOpcode, String},
+		{constlabels.SpanCassandraKeyspace, constlabels.CassandraKeyspace, String},
+		{constlabels.SpanCassandraQuery, constlabels.CassandraQuery, String},
+		{constlabels.SpanCassandraErrorCode, constlabels.CassandraErrCode, Int64},
+		{constlabels.SpanCassandraErrorMsg, constlabels.CassandraErrMsg, String},
+		{constlabels.SpanRequestPayload, constlabels.RequestPayload, String},
+		{constlabels.SpanResponsePayload, constlabels.ResponsePayload, String},
+	}, extraLabelsKey{CASSANDRA}},
+	{[]dictionary{
+		{constlabels.SpanFtpCommand, constlabels.FtpCommand, String},
+		{constlabels.SpanFtpArgument, constlabels.FtpArgument, String},
+		{constlabels.SpanFtpReplyCode, constlabels.FtpReplyCode, Int64},
+		{constlabels.SpanFtpReplyMessage, constlabels.FtpReplyMessage, String},
+		{constlabels.SpanRequestPayload, constlabels.RequestPayload, String},
+		{constlabels.SpanResponsePayload, constlabels.ResponsePayload, String},
+	}, extraLabelsKey{FTP}},
+	{[]dictionary{
+		{constlabels.SpanSshClientSoftware, constlabels.SshClientSoftware, String},
+		{constlabels.SpanSshServerSoftware, constlabels.SshServerSoftware, String},
+		{constlabels.SpanSshFileTransfer, constlabels.SshFileTransfer, Bool},
+		{constlabels.SpanSshDisconnectReason, constlabels.SshDisconnectReason, Int64},
+		{constlabels.SpanRequestPayload, constlabels.RequestPayload, String},
+		{constlabels.SpanResponsePayload, constlabels.ResponsePayload, String},
+	}, extraLabelsKey{SSH}},
+	{[]dictionary{
+		{constlabels.SpanMqttPacketType, constlabels.MqttPacketType, String},
+		{constlabels.SpanMqttClientId, constlabels.MqttClientId, String},
+		{constlabels.SpanMqttTopic, constlabels.MqttTopic, String},
+		{constlabels.SpanMqttQos, constlabels.MqttQos, Int64},
+		{constlabels.SpanMqttReasonCode, constlabels.MqttReasonCode, Int64},
+		{constlabels.SpanRequestPayload, constlabels.RequestPayload, String},
+		{constlabels.SpanResponsePayload, constlabels.ResponsePayload, String},
+	}, extraLabelsKey{MQTT}},
+	{[]dictionary{
+		{constlabels.SpanLdapOperation, constlabels.LdapOperation, String},
+		{constlabels.SpanLdapDn, constlabels.LdapDn, String},
+		{constlabels.SpanLdapResultCode, constlabels.LdapResultCode, Int64},
+		{constlabels.SpanLdapErrorMessage, constlabels.LdapErrorMessage, String},
+		{constlabels.SpanRequestPayload, constlabels.RequestPayload, String},
+		{constlabels.SpanResponsePayload, constlabels.ResponsePayload, String},
+	}, extraLabelsKey{LDAP}},
+	{[]dictionary{
+		{constlabels.SpanOracleFunction, constlabels.OracleFunction, String},
+		{constlabels.SpanOracleService, constlabels.OracleService, String},
+		{constlabels.SpanOracleSql, constlabels.Sql, String},
+		{constlabels.SpanOracleErrorCode, constlabels.SqlErrCode, Int64},
+		{constlabels.SpanOracleErrorMsg, constlabels.SqlErrMsg, String},
+		{constlabels.SpanRequestPayload, constlabels.RequestPayload, String},
+		{constlabels.SpanResponsePayload, constlabels.ResponsePayload, String},
+	}, extraLabelsKey{ORACLE}},
+	{[]dictionary{
+		{constlabels.SpanPostgresqlDatabase, constlabels.PostgresqlDatabase, String},
+		{constlabels.SpanPostgresqlSql, constlabels.Sql, String},
+		{constlabels.SpanPostgresqlSqlState, constlabels.PostgresqlSqlState, String},
+		{constlabels.SpanPostgresqlErrorMsg, constlabels.SqlErrMsg, String},
+		{constlabels.SpanRequestPayload, constlabels.RequestPayload, String},
+		{constlabels.SpanResponsePayload, constlabels.ResponsePayload, String},
+	}, extraLabelsKey{POSTGRESQL}},
+	{[]dictionary{
+		{constlabels.SpanWebsocketOpcode, constlabels.WebsocketOpcode, String},
+		{constlabels.SpanWebsocketDirection, constlabels.WebsocketDirection, String},
+		{constlabels.SpanWebsocketPayloadSize, constlabels.WebsocketPayloadSize, Int64},
+		{constlabels.SpanWebsocketResponseOpcode, constlabels.WebsocketResponseOpcode, String},
+		{constlabels.SpanWebsocketResponsePayloadSize, constlabels.WebsocketResponsePayloadSize, Int64},
+		{constlabels.SpanWebsocketCloseCode, constlabels.WebsocketCloseCode, Int64},
+	}, extraLabelsKey{WEBSOCKET}},
+	{[]dictionary{
+		{constlabels.SpanHttpMethod, constlabels.HttpMethod, String},
+		{constlabels.SpanHttpEndpoint, constlabels.HttpUrl, String},
+		{constlabels.SpanHttpStatusCode, constlabels.HttpStatusCode, Int64},
+		{constlabels.SpanHttp2StreamId, constlabels.Http2StreamId, Int64},
+		{constlabels.SpanRequestPayload, constlabels.RequestPayload, String},
+		{constlabels.SpanResponsePayload, constlabels.ResponsePayload, String},
+	}, extraLabelsKey{HTTP2}},
+	{[]dictionary{
+		{constlabels.SpanQuicSni, constlabels.QuicSni, String},
+		{constlabels.SpanQuicAlpn, constlabels.QuicAlpn, String},
+	}, extraLabelsKey{QUIC}},
+	{[]dictionary{
+		{constlabels.SpanSipMethod, constlabels.SipMethod, String},
+		{constlabels.SpanSipCallId, constlabels.SipCallId, String},
+		{constlabels.SpanSipCseq, constlabels.SipCseq, Int64},
+		{constlabels.SpanSipStatusCode, constlabels.SipStatusCode, Int64},
+		{constlabels.SpanRequestPayload, constlabels.RequestPayload, String},
+		{constlabels.SpanResponsePayload, constlabels.ResponsePayload, String},
+	}, extraLabelsKey{SIP}},
+	{[]dictionary{
+		{constlabels.SpanTlsSni, constlabels.TlsSni, String},
+		{constlabels.SpanTlsAlpn, constlabels.TlsAlpn, String},
+		{constlabels.SpanTlsCipherSuite, constlabels.TlsCipherSuite, String},
+		{constlabels.SpanTlsAlert, constlabels.TlsAlert, Int64},
+	}, extraLabelsKey{TLS}},
+	{[]dictionary{
+		{constlabels.SpanTripleService, constlabels.TripleService, String},
+		{constlabels.SpanTripleMethod, constlabels.TripleMethod, String},
+		{constlabels.SpanTripleVersion, constlabels.TripleVersion, String},
+		{constlabels.SpanTripleGroup, constlabels.TripleGroup, String},
+		{constlabels.SpanTripleStatusCode, constlabels.TripleStatusCode, Int64},
+		{constlabels.SpanTripleStatus, constlabels.TripleStatus, String},
+		{constlabels.SpanTripleMessage, constlabels.TripleMessage, String},
+		{constlabels.SpanTripleExceptionCode, constlabels.TripleExceptionCode, Int64},
+		{constlabels.SpanHttpStatusCode, constlabels.HttpStatusCode, Int64},
+		{constlabels.SpanRequestPayload, constlabels.RequestPayload, String},
+		{constlabels.SpanResponsePayload, constlabels.ResponsePayload, String},
+	}, extraLabelsKey{TRIPLE}},
 	{[]dictionary{
 		/*
 		 * Currently we add payload span for all protocols everywhere as http\dubbo\redis has it's own key.
@@ -295,7 +572,7 @@ var topologyProtocol = []extraLabelsParam{
 		{constlabels.StatusCode, constlabels.SqlErrCode, FromInt64ToString},
 	}, extraLabelsKey{MYSQL}},
 	{[]dictionary{
-		{constlabels.StatusCode, constlabels.HttpStatusCode, FromInt64ToString},
+		{constlabels.StatusCode, constlabels.GrpcStatusCode, FromInt64ToString},
 	}, extraLabelsKey{GRPC}},
 	{[]dictionary{
 		{constlabels.StatusCode, constlabels.DnsRcode, FromInt64ToString},
@@ -309,50 +586,132 @@ var topologyProtocol = []extraLabelsParam{
 	{[]dictionary{
 		{constlabels.StatusCode, constlabels.RocketMQErrCode, FromInt64ToString},
 	}, extraLabelsKey{ROCKETMQ}},
+	{[]dictionary{
+		{constlabels.StatusCode, constlabels.MongodbErrCode, FromInt64ToString},
+	}, extraLabelsKey{MONGODB}},
+	{[]dictionary{
+		{constlabels.StatusCode, constlabels.TarsRetCode, FromInt64ToString},
+	}, extraLabelsKey{TARS}},
+	{[]dictionary{
+		{constlabels.StatusCode, constlabels.BrpcErrorCode, FromInt64ToString},
+	}, extraLabelsKey{BRPC}},
+	{[]dictionary{
+		{constlabels.StatusCode, constlabels.BoltResponseStatus, FromInt64ToString},
+	}, extraLabelsKey{BOLT}},
+	{[]dictionary{
+		{constlabels.StatusCode, constlabels.CassandraErrCode, FromInt64ToString},
+	}, extraLabelsKey{CASSANDRA}},
+	{[]dictionary{
+		{constlabels.StatusCode, constlabels.FtpReplyCode, FromInt64ToString},
+	}, extraLabelsKey{FTP}},
+	{[]dictionary{
+		{constlabels.StatusCode, constlabels.SshDisconnectReason, FromInt64ToString},
+	}, extraLabelsKey{SSH}},
+	{[]dictionary{
+		{constlabels.StatusCode, constlabels.MqttReasonCode, FromInt64ToString},
+	}, extraLabelsKey{MQTT}},
+	{[]dictionary{
+		{constlabels.StatusCode, constlabels.LdapResultCode, FromInt64ToString},
+	}, extraLabelsKey{LDAP}},
+	{[]dictionary{
+		{constlabels.StatusCode, constlabels.SqlErrCode, FromInt64ToString},
+	}, extraLabelsKey{ORACLE}},
+	{[]dictionary{
+		{constlabels.StatusCode, constlabels.PostgresqlSqlState, String},
+	}, extraLabelsKey{POSTGRESQL}},
+	{[]dictionary{
+		{constlabels.StatusCode, constlabels.WebsocketCloseCode, FromInt64ToString},
+	}, extraLabelsKey{WEBSOCKET}},
+	{[]dictionary{
+		{constlabels.StatusCode, constlabels.HttpStatusCode, FromInt64ToString},
+	}, extraLabelsKey{HTTP2}},
+	{[]dictionary{
+		{constlabels.StatusCode, constlabels.STR_EMPTY, FromProtocolErrorToStatus},
+	}, extraLabelsKey{QUIC}},
+	{[]dictionary{
+		{constlabels.StatusCode, constlabels.SipStatusCode, FromInt64ToString},
+	}, extraLabelsKey{SIP}},
+	{[]dictionary{
+		{constlabels.StatusCode, constlabels.TlsAlert, FromInt64ToString},
+	}, extraLabelsKey{TLS}},
+	{[]dictionary{
+		{constlabels.StatusCode, constlabels.TripleStatusCode, FromInt64ToString},
+	}, extraLabelsKey{TRIPLE}},
 	{[]dictionary{
 		{constlabels.StatusCode, constlabels.STR_EMPTY, StrEmpty},
 	}, extraLabelsKey{UNSUPPORTED}},
 }
 
-var traceSpanStatus = []dictionary{
-	{constlabels.RequestSentNs, constlabels.STR_EMPTY, Int64},
-	{constlabels.WaitingTTfbNs, constlabels.STR_EMPTY, Int64},
-	{constlabels.ContentDownloadNs, constlabels.STR_EMPTY, Int64},
-	{constlabels.RequestTotalNs, constlabels.STR_EMPTY, Int64},
-	{constlabels.RequestIoBytes, constlabels.STR_EMPTY, Int64},
-	{constlabels.ResponseIoBytes, constlabels.STR_EMPTY, Int64},
-	{constlabels.IsServer, constlabels.STR_EMPTY, Int64},
-	{constlabels.IsError, constlabels.STR_EMPTY, Int64},
-	{constlabels.IsSlow, constlabels.STR_EMPTY, Int64},
-	{constlabels.IsConvergent, constlabels.STR_EMPTY, Int64},
-	{constlabels.Timestamp, constlabels.STR_EMPTY, Int64},
-}
-
-func getTraceSpanStatusLabels(dataGroup *model.DataGroup) []attribute.KeyValue {
-	valueLabels := make([]attribute.KeyValue, 11)
-	for i := 0; i < len(dataGroup.Metrics); i++ {
-		switch dataGroup.Metrics[i].Name {
-		case constvalues.RequestSentTime:
-			valueLabels[0] = attribute.Int64(traceSpanStatus[0].newKey, dataGroup.Metrics[i].GetInt().Value)
-		case constvalues.WaitingTtfbTime:
-			valueLabels[1] = attribute.Int64(traceSpanStatus[1].newKey, dataGroup.Metrics[i].GetInt().Value)
-		case constvalues.ContentDownloadTime:
-			valueLabels[2] = attribute.Int64(traceSpanStatus[2].newKey, dataGroup.Metrics[i].GetInt().Value)
-		case constvalues.RequestTotalTime:
-			valueLabels[3] = attribute.Int64(traceSpanStatus[3].newKey, dataGroup.Metrics[i].GetInt().Value)
-		case constvalues.RequestIo:
-			valueLabels[4] = attribute.Int64(traceSpanStatus[4].newKey, dataGroup.Metrics[i].GetInt().Value)
-		case constvalues.ResponseIo:
-			valueLabels[5] = attribute.Int64(traceSpanStatus[5].newKey, dataGroup.Metrics[i].GetInt().Value)
+// newTraceSpanStatus returns the dictionaries and the values of the span status. The durations are
+// exported in durationUnit with the unit suffix in the keys, like "request_total_ms". The label
+// "timestamp" is exported in timestampUnit, or milliseconds if it is empty.
+func newTraceSpanStatus(timestampUnit timeunit.Unit, durationUnit timeunit.Unit) ([]dictionary, valueToLabels) {
+	if timestampUnit == "" {
+		timestampUnit = timeunit.Millisecond
+	}
+	if durationUnit == "" {
+		durationUnit = timeunit.Nanosecond
+	}
+	durationKey := func(key string) string {
+		return strings.TrimSuffix(key, timeunit.Nanosecond.Suffix()) + durationUnit.Suffix()
+	}
+	traceSpanStatus := []dictionary{
+		{durationKey(constlabels.RequestSentNs), constlabels.STR_EMPTY, Int64},
+		{durationKey(constlabels.WaitingTTfbNs), constlabels.STR_EMPTY, Int64},
+		{durationKey(constlabels.ContentDownloadNs), constlabels.STR_EMPTY, Int64},
+		{durationKey(constlabels.RequestTotalNs), constlabels.STR_EMPTY, Int64},
+		{constlabels.RequestIoBytes, constlabels.STR_EMPTY, Int64},
+		{constlabels.ResponseIoBytes, constlabels.STR_EMPTY, Int64},
+		{constlabels.IsServer, constlabels.STR_EMPTY, Int64},
+		{constlabels.IsError, constlabels.STR_EMPTY, Int64},
+		{constlabels.IsSlow, constlabels.STR_EMPTY, Int64},
+		{constlabels.IsConvergent, constlabels.STR_EMPTY, Int64},
+		{constlabels.Timestamp, constlabels.STR_EMPTY, Int64},
+	}
+	return traceSpanStatus, func(dataGroup *model.DataGroup) []attribute.KeyValue {
+		valueLabels := make([]attribute.KeyValue, 11)
+		for i := 0; i < len(dataGroup.Metrics); i++ {
+			switch dataGroup.Metrics[i].Name {
+			case constvalues.RequestSentTime:
+				valueLabels[0] = attribute.Int64(traceSpanStatus[0].newKey, timeunit.Duration(dataGroup.Metrics[i].GetInt().Value).In(durationUnit))
+			case constvalues.WaitingTtfbTime:
+				valueLabels[1] = attribute.Int64(traceSpanStatus[1].newKey, timeunit.Duration(dataGroup.Metrics[i].GetInt().Value).In(durationUnit))
+			case constvalues.ContentDownloadTime:
+				valueLabels[2] = attribute.Int64(traceSpanStatus[2].newKey, timeunit.Duration(dataGroup.Metrics[i].GetInt().Value).In(durationUnit))
+			case constvalues.RequestTotalTime:
+				valueLabels[3] = attribute.Int64(traceSpanStatus[3].newKey, timeunit.Duration(dataGroup.Metrics[i].GetInt().Value).In(durationUnit))
+			case constvalues.RequestIo:
+				valueLabels[4] = attribute.Int64(traceSpanStatus[4].newKey, dataGroup.Metrics[i].GetInt().Value)
+			case constvalues.ResponseIo:
+				valueLabels[5] = attribute.Int64(traceSpanStatus[5].newKey, dataGroup.Metrics[i].GetInt().Value)
+			}
 		}
+
+		valueLabels[6] = attribute.Int64(traceSpanStatus[6].newKey, int64(If(dataGroup.Labels.GetBoolValue(constlabels.IsServer), 1, 0).(int)))
+		valueLabels[7] = attribute.Int64(traceSpanStatus[7].newKey, int64(If(dataGroup.Labels.GetBoolValue(constlabels.IsError), 1, 0).(int)))
+		valueLabels[8] = attribute.Int64(traceSpanStatus[8].newKey, int64(If(dataGroup.Labels.GetBoolValue(constlabels.IsSlow), 1, 0).(int)))
+		valueLabels[9] = attribute.Int64(traceSpanStatus[9].newKey, 0)
+		valueLabels[10] = attribute.Int64(traceSpanStatus[10].newKey, timeunit.Timestamp(dataGroup.Timestamp).In(timestampUnit))
+		return valueLabels
 	}
+}
 
-	valueLabels[6] = attribute.Int64(traceSpanStatus[6].newKey, int64(If(dataGroup.Labels.GetBoolValue(constlabels.IsServer), 1, 0).(int)))
-	valueLabels[7] = attribute.Int64(traceSpanStatus[7].newKey, int64(If(dataGroup.Labels.GetBoolValue(constlabels.IsError), 1, 0).(int)))
-	valueLabels[8] = attribute.Int64(traceSpanStatus[8].newKey, int64(If(dataGroup.Labels.GetBoolValue(constlabels.IsSlow), 1, 0).(int)))
-	valueLabels[9] = attribute.Int64(traceSpanStatus[9].newKey, 0)
-	valueLabels[10] = attribute.Int64(traceSpanStatus[10].newKey, int64(dataGroup.Timestamp/millToNano))
-	return valueLabels
+// convertEndTimestamp exports the label "end_timestamp" in the unit instead of nanoseconds.
+func convertEndTimestamp(unit timeunit.Unit) adjustFunctions {
+	return adjustFunctions{
+		adjustAttrMaps: func(labels *model.AttributeMap, attributeMap *model.AttributeMap) *model.AttributeMap {
+			attributeMap.AddIntValue(constlabels.EndTimestamp, timeunit.Timestamp(labels.GetIntValue(constlabels.EndTimestamp)).In(unit))
+			return attributeMap
+		},
+		adjustLabels: func(labels *model.AttributeMap, attrs []attribute.KeyValue) []attribute.KeyValue {
+			for i := 0; i < len(attrs); i++ {
+				if attrs[i].Key == constlabels.EndTimestamp {
+					attrs[i].Value = attribute.Int64Value(timeunit.Timestamp(labels.GetIntValue(constlabels.EndTimestamp)).In(unit))
+				}
+			}
+			return attrs
+		},
+	}
 }
 
 var traceStatus = []dictionary{
@@ -387,9 +746,9 @@ func getTraceStatusLabels(dataGroup *model.DataGroup) []attribute.KeyValue {
 }
 
 func getRequestStatus(requestLatency int64) string {
-	if requestLatency <= 800*millToNano {
+	if timeunit.Duration(requestLatency).Std() <= 800*time.Millisecond {
 		return GreenStatus
-	} else if requestLatency >= 1500*millToNano {
+	} else if timeunit.Duration(requestLatency).Std() >= 1500*time.Millisecond {
 		return RedStatus
 	} else {
 		return YellowStatus
@@ -397,9 +756,9 @@ func getRequestStatus(requestLatency int64) string {
 }
 
 func getSubStageStatus(requestSendTime int64) string {
-	if requestSendTime <= 200*millToNano {
+	if timeunit.Duration(requestSendTime).Std() <= 200*time.Millisecond {
 		return GreenStatus
-	} else if requestSendTime >= 1000*millToNano {
+	} else if timeunit.Duration(requestSendTime).Std() >= 1000*time.Millisecond {
 		return RedStatus
 	} else {
 		return YellowStatus
@@ -0,0 +1,182 @@
+package resourcedetection
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+)
+
+const (
+	ec2Endpoint   = "http://169.254.169.254"
+	gcpEndpoint   = "http://metadata.google.internal"
+	azureEndpoint = "http://169.254.169.254"
+)
+
+// metadataClient queries the instance metadata service of the cloud providers.
+type metadataClient struct {
+	endpoint string
+	client   *http.Client
+}
+
+func newMetadataClient(endpoint string) *metadataClient {
+	return &metadataClient{
+		endpoint: endpoint,
+		client:   &http.Client{},
+	}
+}
+
+func (c *metadataClient) do(ctx context.Context, method string, path string, header map[string]string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.endpoint+path, nil)
+	if err != nil {
+		return "", err
+	}
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status code %d from %s", resp.StatusCode, path)
+	}
+	return strings.TrimSpace(string(body)), nil
+}
+
+// ec2Detector detects the attributes from AWS EC2 instance metadata service (IMDSv2).
+type ec2Detector struct {
+	client *metadataClient
+}
+
+func newEc2Detector(endpoint string) *ec2Detector {
+	return &ec2Detector{client: newMetadataClient(endpoint)}
+}
+
+type ec2IdentityDocument struct {
+	Region           string `json:"region"`
+	AvailabilityZone string `json:"availabilityZone"`
+	InstanceId       string `json:"instanceId"`
+	InstanceType     string `json:"instanceType"`
+	AccountId        string `json:"accountId"`
+}
+
+func (d *ec2Detector) Detect(ctx context.Context) (*resource.Resource, error) {
+	token, err := d.client.do(ctx, http.MethodPut, "/latest/api/token",
+		map[string]string{"X-aws-ec2-metadata-token-ttl-seconds": "60"})
+	if err != nil {
+		return nil, err
+	}
+	document, err := d.client.do(ctx, http.MethodGet, "/latest/dynamic/instance-identity/document",
+		map[string]string{"X-aws-ec2-metadata-token": token})
+	if err != nil {
+		return nil, err
+	}
+	var identity ec2IdentityDocument
+	if err = json.Unmarshal([]byte(document), &identity); err != nil {
+		return nil, err
+	}
+	return resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.CloudProviderAWS,
+		semconv.CloudPlatformAWSEC2,
+		semconv.CloudRegionKey.String(identity.Region),
+		semconv.CloudAvailabilityZoneKey.String(identity.AvailabilityZone),
+		semconv.CloudAccountIDKey.String(identity.AccountId),
+		semconv.HostIDKey.String(identity.InstanceId),
+		semconv.HostTypeKey.String(identity.InstanceType),
+	), nil
+}
+
+// gcpDetector detects the attributes from GCP Compute Engine metadata server.
+type gcpDetector struct {
+	client *metadataClient
+}
+
+func newGcpDetector(endpoint string) *gcpDetector {
+	return &gcpDetector{client: newMetadataClient(endpoint)}
+}
+
+func (d *gcpDetector) Detect(ctx context.Context) (*resource.Resource, error) {
+	header := map[string]string{"Metadata-Flavor": "Google"}
+	get := func(path string) (string, error) {
+		return d.client.do(ctx, http.MethodGet, "/computeMetadata/v1"+path, header)
+	}
+	projectId, err := get("/project/project-id")
+	if err != nil {
+		return nil, err
+	}
+	attrs := []attribute.KeyValue{
+		semconv.CloudProviderGCP,
+		semconv.CloudPlatformGCPComputeEngine,
+		semconv.CloudAccountIDKey.String(projectId),
+	}
+	// The zone and machine type are returned as "projects/<id>/zones/<zone>"
+	// and "projects/<id>/machineTypes/<type>".
+	if zone, err := get("/instance/zone"); err == nil {
+		zone = lastPathElement(zone)
+		attrs = append(attrs, semconv.CloudAvailabilityZoneKey.String(zone))
+		if idx := strings.LastIndex(zone, "-"); idx > 0 {
+			attrs = append(attrs, semconv.CloudRegionKey.String(zone[:idx]))
+		}
+	}
+	if machineType, err := get("/instance/machine-type"); err == nil {
+		attrs = append(attrs, semconv.HostTypeKey.String(lastPathElement(machineType)))
+	}
+	if instanceId, err := get("/instance/id"); err == nil {
+		attrs = append(attrs, semconv.HostIDKey.String(instanceId))
+	}
+	return resource.NewWithAttributes(semconv.SchemaURL, attrs...), nil
+}
+
+func lastPathElement(path string) string {
+	return path[strings.LastIndex(path, "/")+1:]
+}
+
+// azureDetector detects the attributes from Azure instance metadata service.
+type azureDetector struct {
+	client *metadataClient
+}
+
+func newAzureDetector(endpoint string) *azureDetector {
+	return &azureDetector{client: newMetadataClient(endpoint)}
+}
+
+type azureComputeMetadata struct {
+	Location       string `json:"location"`
+	Zone           string `json:"zone"`
+	VmId           string `json:"vmId"`
+	VmSize         string `json:"vmSize"`
+	SubscriptionId string `json:"subscriptionId"`
+}
+
+func (d *azureDetector) Detect(ctx context.Context) (*resource.Resource, error) {
+	body, err := d.client.do(ctx, http.MethodGet, "/metadata/instance/compute?api-version=2021-02-01",
+		map[string]string{"Metadata": "true"})
+	if err != nil {
+		return nil, err
+	}
+	var compute azureComputeMetadata
+	if err = json.Unmarshal([]byte(body), &compute); err != nil {
+		return nil, err
+	}
+	return resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.CloudProviderAzure,
+		semconv.CloudPlatformAzureVM,
+		semconv.CloudRegionKey.String(compute.Location),
+		semconv.CloudAvailabilityZoneKey.String(compute.Zone),
+		semconv.CloudAccountIDKey.String(compute.SubscriptionId),
+		semconv.HostIDKey.String(compute.VmId),
+		semconv.HostTypeKey.String(compute.VmSize),
+	), nil
+}
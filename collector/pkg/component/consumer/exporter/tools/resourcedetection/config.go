@@ -0,0 +1,39 @@
+package resourcedetection
+
+import (
+	"time"
+
+	"github.com/Kindling-project/kindling/collector/pkg/metadata/kubernetes"
+)
+
+const (
+	EnvDetector     = "env"
+	SystemDetector  = "system"
+	K8sNodeDetector = "k8snode"
+	Ec2Detector     = "ec2"
+	GcpDetector     = "gcp"
+	AzureDetector   = "azure"
+)
+
+type Config struct {
+	// Detectors is the list of detectors to run. The attributes detected by the
+	// former detectors take precedence over the latter ones.
+	Detectors []string `mapstructure:"detectors"`
+	// Timeout is the maximum time spent on each detector.
+	Timeout time.Duration `mapstructure:"timeout"`
+	// KubeAuthType and KubeConfigDir are how the k8snode detector connects to the API server.
+	KubeAuthType  kubernetes.AuthType `mapstructure:"kube_auth_type"`
+	KubeConfigDir string              `mapstructure:"kube_config_dir"`
+	// NodeLabels are the labels of the node added as "k8s.node.label.<key>" by the k8snode detector.
+	NodeLabels []string `mapstructure:"node_labels"`
+}
+
+func NewDefaultConfig() *Config {
+	return &Config{
+		Detectors:     []string{},
+		Timeout:       2 * time.Second,
+		KubeAuthType:  kubernetes.AuthTypeServiceAccount,
+		KubeConfigDir: "/root/.kube/config",
+		NodeLabels:    []string{},
+	}
+}
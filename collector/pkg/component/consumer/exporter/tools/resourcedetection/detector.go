@@ -0,0 +1,193 @@
+package resourcedetection
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8s "k8s.io/client-go/kubernetes"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component"
+	"github.com/Kindling-project/kindling/collector/pkg/metadata/kubernetes"
+	"github.com/Kindling-project/kindling/collector/pkg/version"
+)
+
+const (
+	nodeNameEnv = "MY_NODE_NAME"
+	nodeIpEnv   = "MY_NODE_IP"
+)
+
+// The labels of the nodes set by the cloud providers. The beta ones are deprecated but still set by
+// the clusters of the old versions.
+const (
+	regionLabel           = "topology.kubernetes.io/region"
+	betaRegionLabel       = "failure-domain.beta.kubernetes.io/region"
+	zoneLabel             = "topology.kubernetes.io/zone"
+	betaZoneLabel         = "failure-domain.beta.kubernetes.io/zone"
+	instanceTypeLabel     = "node.kubernetes.io/instance-type"
+	betaInstanceTypeLabel = "beta.kubernetes.io/instance-type"
+)
+
+var detectorFactories = map[string]func(cfg *Config) resource.Detector{
+	EnvDetector:    func(cfg *Config) resource.Detector { return &envDetector{} },
+	SystemDetector: func(cfg *Config) resource.Detector { return &systemDetector{} },
+	K8sNodeDetector: func(cfg *Config) resource.Detector {
+		return newK8sNodeDetector(func() (k8s.Interface, error) {
+			return kubernetes.NewClientSet(cfg.KubeAuthType, cfg.KubeConfigDir)
+		}, cfg.NodeLabels)
+	},
+	Ec2Detector:   func(cfg *Config) resource.Detector { return newEc2Detector(ec2Endpoint) },
+	GcpDetector:   func(cfg *Config) resource.Detector { return newGcpDetector(gcpEndpoint) },
+	AzureDetector: func(cfg *Config) resource.Detector { return newAzureDetector(azureEndpoint) },
+}
+
+// Detect runs the configured detectors in order and merges their results.
+// A detector that fails is logged and skipped, so the agent still starts
+// when it is not running on the expected platform.
+func Detect(ctx context.Context, cfg *Config, logger *component.TelemetryLogger) *resource.Resource {
+	res := resource.Empty()
+	if cfg == nil {
+		return res
+	}
+	// Iterate in reverse so the former detectors override the latter ones when merging.
+	for i := len(cfg.Detectors) - 1; i >= 0; i-- {
+		name := cfg.Detectors[i]
+		detected, err := detect(ctx, name, cfg)
+		if err != nil {
+			logger.Warn("Failed to detect resource attributes", zap.String("detector", name), zap.Error(err))
+			// The partial resource is still merged, e.g. the node name without the labels read from
+			// the API server.
+			if !errors.Is(err, resource.ErrPartialResource) || detected == nil {
+				continue
+			}
+		}
+		merged, err := resource.Merge(res, detected)
+		if err != nil {
+			logger.Warn("Failed to merge resource attributes", zap.String("detector", name), zap.Error(err))
+			continue
+		}
+		res = merged
+	}
+	return res
+}
+
+func detect(ctx context.Context, name string, cfg *Config) (*resource.Resource, error) {
+	newDetector, ok := detectorFactories[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown detector %q", name)
+	}
+	if cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.Timeout)
+		defer cancel()
+	}
+	return newDetector(cfg).Detect(ctx)
+}
+
+// envDetector detects the attributes set in OTEL_RESOURCE_ATTRIBUTES.
+type envDetector struct{}
+
+func (d *envDetector) Detect(ctx context.Context) (*resource.Resource, error) {
+	return resource.New(ctx, resource.WithFromEnv())
+}
+
+// systemDetector detects the attributes of the agent itself.
+type systemDetector struct{}
+
+func (d *systemDetector) Detect(ctx context.Context) (*resource.Resource, error) {
+	hostName, err := os.Hostname()
+	if err != nil {
+		return nil, err
+	}
+	attrs := []attribute.KeyValue{semconv.HostNameKey.String(hostName)}
+	if codeVersion := version.CodeBaseVersion(); codeVersion != "" {
+		attrs = append(attrs, semconv.ServiceVersionKey.String(codeVersion))
+	}
+	return resource.NewWithAttributes(semconv.SchemaURL, attrs...), nil
+}
+
+// k8sNodeDetector detects the node where the agent is running on, whose name and ip are injected into
+// the environment variables by the downward API. The region, the zone, the instance type and the
+// configured labels are read from the Node object on the API server.
+type k8sNodeDetector struct {
+	newClientSet func() (k8s.Interface, error)
+	labels       []string
+}
+
+func newK8sNodeDetector(newClientSet func() (k8s.Interface, error), labels []string) *k8sNodeDetector {
+	return &k8sNodeDetector{
+		newClientSet: newClientSet,
+		labels:       labels,
+	}
+}
+
+func (d *k8sNodeDetector) Detect(ctx context.Context) (*resource.Resource, error) {
+	nodeName, ok := os.LookupEnv(nodeNameEnv)
+	if !ok {
+		return nil, fmt.Errorf("[%s] is not found in env variable", nodeNameEnv)
+	}
+	attrs := []attribute.KeyValue{semconv.K8SNodeNameKey.String(nodeName)}
+	if nodeIp, ok := os.LookupEnv(nodeIpEnv); ok {
+		attrs = append(attrs, attribute.String("k8s.node.ip", nodeIp))
+	}
+	node, err := d.getNode(ctx, nodeName)
+	if err != nil {
+		return resource.NewWithAttributes(semconv.SchemaURL, attrs...),
+			fmt.Errorf("%w: cannot get the node %s: %v", resource.ErrPartialResource, nodeName, err)
+	}
+	attrs = append(attrs, nodeAttributes(node, d.labels)...)
+	return resource.NewWithAttributes(semconv.SchemaURL, attrs...), nil
+}
+
+func (d *k8sNodeDetector) getNode(ctx context.Context, name string) (*corev1.Node, error) {
+	clientSet, err := d.newClientSet()
+	if err != nil {
+		return nil, err
+	}
+	return clientSet.CoreV1().Nodes().Get(ctx, name, metav1.GetOptions{})
+}
+
+// nodeAttributes maps the well-known labels and the provider id of the node into the attributes.
+func nodeAttributes(node *corev1.Node, labels []string) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	if region := firstLabel(node.Labels, regionLabel, betaRegionLabel); region != "" {
+		attrs = append(attrs, semconv.CloudRegionKey.String(region))
+	}
+	if zone := firstLabel(node.Labels, zoneLabel, betaZoneLabel); zone != "" {
+		attrs = append(attrs, semconv.CloudAvailabilityZoneKey.String(zone))
+	}
+	if instanceType := firstLabel(node.Labels, instanceTypeLabel, betaInstanceTypeLabel); instanceType != "" {
+		attrs = append(attrs, semconv.HostTypeKey.String(instanceType))
+	}
+	// The provider id is like "aws:///us-east-1a/i-0123" or "gce://project/us-central1-a/instance".
+	if i := strings.Index(node.Spec.ProviderID, "://"); i > 0 {
+		provider := node.Spec.ProviderID[:i]
+		if provider == "gce" {
+			provider = "gcp"
+		}
+		attrs = append(attrs, semconv.CloudProviderKey.String(provider))
+	}
+	for _, label := range labels {
+		if value, ok := node.Labels[label]; ok {
+			attrs = append(attrs, attribute.String("k8s.node.label."+label, value))
+		}
+	}
+	return attrs
+}
+
+func firstLabel(labels map[string]string, keys ...string) string {
+	for _, key := range keys {
+		if value := labels[key]; value != "" {
+			return value
+		}
+	}
+	return ""
+}
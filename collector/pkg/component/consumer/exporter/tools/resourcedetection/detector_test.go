@@ -0,0 +1,126 @@
+package resourcedetection
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8s "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component"
+)
+
+func TestDetect(t *testing.T) {
+	t.Setenv(nodeNameEnv, "node-1")
+	t.Setenv("OTEL_RESOURCE_ATTRIBUTES", "k8s.node.name=node-from-env,deployment.environment=test")
+	cfg := &Config{Detectors: []string{K8sNodeDetector, EnvDetector, "unknown"}}
+	res := Detect(context.Background(), cfg, component.NewDefaultTelemetryTools().Logger)
+
+	nodeName, _ := res.Set().Value(semconv.K8SNodeNameKey)
+	assert.Equal(t, "node-1", nodeName.AsString())
+	environment, _ := res.Set().Value(attribute.Key("deployment.environment"))
+	assert.Equal(t, "test", environment.AsString())
+}
+
+func TestK8sNodeDetector(t *testing.T) {
+	t.Setenv(nodeNameEnv, "node-1")
+	t.Setenv(nodeIpEnv, "10.0.0.1")
+	clientSet := fake.NewSimpleClientset(&corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node-1",
+			Labels: map[string]string{
+				regionLabel:              "us-east-1",
+				betaZoneLabel:            "us-east-1a",
+				instanceTypeLabel:        "m5.large",
+				"kubernetes.io/os":       "linux",
+				"kubernetes.io/hostname": "node-1",
+			},
+		},
+		Spec: corev1.NodeSpec{ProviderID: "aws:///us-east-1a/i-0123"},
+	})
+	detector := newK8sNodeDetector(func() (k8s.Interface, error) { return clientSet, nil }, []string{"kubernetes.io/os"})
+	res, err := detector.Detect(context.Background())
+	assert.NoError(t, err)
+
+	expected := map[attribute.Key]string{
+		semconv.K8SNodeNameKey:            "node-1",
+		"k8s.node.ip":                     "10.0.0.1",
+		semconv.CloudRegionKey:            "us-east-1",
+		semconv.CloudAvailabilityZoneKey:  "us-east-1a",
+		semconv.HostTypeKey:               "m5.large",
+		semconv.CloudProviderKey:          "aws",
+		"k8s.node.label.kubernetes.io/os": "linux",
+	}
+	assert.Equal(t, len(expected), res.Len())
+	for key, value := range expected {
+		actual, _ := res.Set().Value(key)
+		assert.Equal(t, value, actual.AsString(), key)
+	}
+}
+
+func TestK8sNodeDetector_NodeNotFound(t *testing.T) {
+	t.Setenv(nodeNameEnv, "node-1")
+	detector := newK8sNodeDetector(func() (k8s.Interface, error) { return fake.NewSimpleClientset(), nil }, nil)
+	res, err := detector.Detect(context.Background())
+	// The node name from the environment variables is still detected.
+	assert.ErrorIs(t, err, resource.ErrPartialResource)
+	nodeName, _ := res.Set().Value(semconv.K8SNodeNameKey)
+	assert.Equal(t, "node-1", nodeName.AsString())
+}
+
+func TestEc2Detector(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/latest/api/token":
+			assert.Equal(t, http.MethodPut, r.Method)
+			_, _ = w.Write([]byte("token"))
+		case "/latest/dynamic/instance-identity/document":
+			assert.Equal(t, "token", r.Header.Get("X-aws-ec2-metadata-token"))
+			_, _ = w.Write([]byte(`{"region":"us-east-1","availabilityZone":"us-east-1a","instanceId":"i-123","instanceType":"m5.large","accountId":"1234"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	res, err := newEc2Detector(server.URL).Detect(context.Background())
+	assert.NoError(t, err)
+	region, _ := res.Set().Value(semconv.CloudRegionKey)
+	assert.Equal(t, "us-east-1", region.AsString())
+	hostType, _ := res.Set().Value(semconv.HostTypeKey)
+	assert.Equal(t, "m5.large", hostType.AsString())
+}
+
+func TestGcpDetector(t *testing.T) {
+	values := map[string]string{
+		"/computeMetadata/v1/project/project-id":    "my-project",
+		"/computeMetadata/v1/instance/zone":         "projects/123/zones/us-central1-a",
+		"/computeMetadata/v1/instance/machine-type": "projects/123/machineTypes/n1-standard-1",
+		"/computeMetadata/v1/instance/id":           "4567",
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Google", r.Header.Get("Metadata-Flavor"))
+		value, ok := values[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(value))
+	}))
+	defer server.Close()
+
+	res, err := newGcpDetector(server.URL).Detect(context.Background())
+	assert.NoError(t, err)
+	region, _ := res.Set().Value(semconv.CloudRegionKey)
+	assert.Equal(t, "us-central1", region.AsString())
+	hostType, _ := res.Set().Value(semconv.HostTypeKey)
+	assert.Equal(t, "n1-standard-1", hostType.AsString())
+}
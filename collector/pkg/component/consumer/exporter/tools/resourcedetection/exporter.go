@@ -0,0 +1,46 @@
+package resourcedetection
+
+import (
+	"go.opentelemetry.io/otel/sdk/resource"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/exporter"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+)
+
+// ResourceConfig is implemented by the configs of the exporters which export the data with a resource
+// of their own, e.g. the otelexporter. The detected attributes are merged into the resource instead of
+// being stamped on the labels of the data.
+type ResourceConfig interface {
+	SetResource(res *resource.Resource)
+}
+
+// labelExporter stamps the detected attributes on the labels of the data before exporting them.
+type labelExporter struct {
+	labels map[string]string
+	next   exporter.Exporter
+}
+
+// NewExporter returns the exporter stamping the attributes of the resource on the labels of the data.
+// The exporter is returned as is if no attribute is detected.
+func NewExporter(res *resource.Resource, next exporter.Exporter) exporter.Exporter {
+	if res == nil || res.Len() == 0 {
+		return next
+	}
+	labels := make(map[string]string, res.Len())
+	for _, attr := range res.Attributes() {
+		labels[string(attr.Key)] = attr.Value.Emit()
+	}
+	return &labelExporter{labels: labels, next: next}
+}
+
+// Consume copies the labels as the data may be shared with other consumers. The labels of the data
+// take precedence over the detected ones.
+func (e *labelExporter) Consume(dataGroup *model.DataGroup) error {
+	labels := dataGroup.Labels.Clone()
+	for key, value := range e.labels {
+		if !labels.HasAttribute(key) {
+			labels.AddStringValue(key, value)
+		}
+	}
+	return e.next.Consume(model.NewDataGroup(dataGroup.Name, labels, dataGroup.Timestamp, dataGroup.Metrics...))
+}
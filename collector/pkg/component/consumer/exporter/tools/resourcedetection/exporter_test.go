@@ -0,0 +1,39 @@
+package resourcedetection
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+)
+
+type recordExporter struct {
+	dataGroups []*model.DataGroup
+}
+
+func (e *recordExporter) Consume(dataGroup *model.DataGroup) error {
+	e.dataGroups = append(e.dataGroups, dataGroup)
+	return nil
+}
+
+func TestNewExporter(t *testing.T) {
+	next := &recordExporter{}
+	assert.Same(t, next, NewExporter(resource.Empty(), next))
+
+	res := resource.NewSchemaless(attribute.String("cloud.region", "us-east-1"), attribute.String("k8s.node.name", "node-1"))
+	exporter := NewExporter(res, next)
+	labels := model.NewAttributeMap()
+	labels.AddStringValue("k8s.node.name", "node-2")
+	dataGroup := model.NewDataGroup("request", labels, 1)
+	assert.NoError(t, exporter.Consume(dataGroup))
+
+	exported := next.dataGroups[0]
+	assert.Equal(t, "us-east-1", exported.Labels.GetStringValue("cloud.region"))
+	// The labels of the data take precedence.
+	assert.Equal(t, "node-2", exported.Labels.GetStringValue("k8s.node.name"))
+	// The data shared with other consumers are not changed.
+	assert.False(t, dataGroup.Labels.HasAttribute("cloud.region"))
+}
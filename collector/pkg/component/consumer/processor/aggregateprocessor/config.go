@@ -1,11 +1,43 @@
 package aggregateprocessor
 
+const (
+	// RollingAlignment starts the window when the processor starts.
+	RollingAlignment = "rolling"
+	// WallClockAlignment aligns the window to the multiples of its interval since the Unix epoch,
+	// e.g. a 15s window is dumped at hh:mm:00, hh:mm:15, hh:mm:30 and hh:mm:45.
+	WallClockAlignment = "wall_clock"
+)
+
 type Config struct {
 	// The unit is second.
 	TickerInterval int `mapstructure:"ticker_interval"`
+	// TickerAlignment is the alignment of the default window. Valid values: ["rolling", "wall_clock"].
+	TickerAlignment string `mapstructure:"ticker_alignment"`
+	// Windows defines multiple aggregation windows that run concurrently. If it is empty,
+	// one window is built from TickerInterval and TickerAlignment and accepts all metric groups.
+	Windows []WindowConfig `mapstructure:"windows"`
 
 	AggregateKindMap map[string][]AggregatedKindConfig `mapstructure:"aggregate_kind_map"`
 	SamplingRate     *SampleConfig                     `mapstructure:"sampling_rate"`
+	// WorkloadRollup rolls up the server-side requests by workload.
+	WorkloadRollup *WorkloadRollupConfig `mapstructure:"workload_rollup"`
+}
+
+type WorkloadRollupConfig struct {
+	Enable bool `mapstructure:"enable"`
+}
+
+type WindowConfig struct {
+	// Name is added to the aggregated data as the label "aggregation_window" so that
+	// the results of different windows are emitted as separate series. An empty name adds no label.
+	Name string `mapstructure:"name"`
+	// The unit is second. TickerInterval is used if it is not set.
+	Interval int `mapstructure:"interval"`
+	// Valid values: ["rolling", "wall_clock"]. The default value is "rolling".
+	Alignment string `mapstructure:"alignment"`
+	// MetricGroups is the list of metric groups aggregated in this window.
+	// All metric groups are accepted if it is empty.
+	MetricGroups []string `mapstructure:"metric_groups"`
 }
 
 type AggregatedKindConfig struct {
@@ -22,22 +54,69 @@ type SampleConfig struct {
 
 func NewDefaultConfig() *Config {
 	ret := &Config{
-		TickerInterval: 5,
+		TickerInterval:  5,
+		TickerAlignment: RollingAlignment,
 		AggregateKindMap: map[string][]AggregatedKindConfig{
 			"request_total_time": {{Kind: "sum"}, {Kind: "avg", OutputName: "request_total_time_avg"},
 				{Kind: "count", OutputName: "request_count"}},
 			"request_io":  {{Kind: "sum"}},
 			"response_io": {{Kind: "sum"}},
+			// the phases of the requests
+			"connect_time":          {{Kind: "avg", OutputName: "connect_time_avg"}},
+			"request_sent_time":     {{Kind: "avg", OutputName: "request_sent_time_avg"}},
+			"waiting_ttfb_time":     {{Kind: "avg", OutputName: "waiting_ttfb_time_avg"}},
+			"content_download_time": {{Kind: "avg", OutputName: "content_download_time_avg"}},
 			// tcp
 			"kindling_tcp_srtt_microseconds": {{Kind: "last"}},
 			"kindling_tcp_retransmit_total":  {{Kind: "sum"}},
 			"kindling_tcp_packet_loss_total": {{Kind: "sum"}},
+			// connection pool
+			"kindling_connection_pool_connect_total": {{Kind: "sum"}},
+			"kindling_connection_pool_request_total": {{Kind: "sum"}},
+			"kindling_connection_reuse_ratio":        {{Kind: "last"}},
+			"kindling_process_open_sockets":          {{Kind: "last"}},
+			// server queue
+			"kindling_server_queue_time_nanoseconds": {{Kind: "sum", OutputName: "kindling_server_queue_time_nanoseconds_total"},
+				{Kind: "count", OutputName: "kindling_server_queue_total"},
+				{Kind: "max", OutputName: "kindling_server_queue_time_nanoseconds_max"}},
+			// bulk transfer
+			"kindling_bulk_transfer_bytes": {{Kind: "sum", OutputName: "kindling_bulk_transfer_bytes_total"}},
+			"kindling_bulk_transfer_rows":  {{Kind: "sum", OutputName: "kindling_bulk_transfer_rows_total"}},
+			"kindling_bulk_transfer_duration_nanoseconds": {{Kind: "sum", OutputName: "kindling_bulk_transfer_duration_nanoseconds_total"},
+				{Kind: "count", OutputName: "kindling_bulk_transfer_total"}},
+			// container protocol
+			"kindling_container_protocol_info": {{Kind: "last"}},
+			// thread pool
+			"kindling_process_handling_threads":        {{Kind: "last"}},
+			"kindling_process_thread_saturation_ratio": {{Kind: "last"}},
 		},
 		SamplingRate: &SampleConfig{
 			NormalData: 0,
 			SlowData:   100,
 			ErrorData:  100,
 		},
+		WorkloadRollup: &WorkloadRollupConfig{
+			Enable: true,
+		},
 	}
 	return ret
 }
+
+// getWindowConfigs returns the configured windows, or the default window if none is configured.
+func (cfg *Config) getWindowConfigs() []WindowConfig {
+	if len(cfg.Windows) == 0 {
+		return []WindowConfig{{Interval: cfg.TickerInterval, Alignment: cfg.TickerAlignment}}
+	}
+	ret := make([]WindowConfig, len(cfg.Windows))
+	for i, w := range cfg.Windows {
+		if w.Interval <= 0 {
+			w.Interval = cfg.TickerInterval
+		}
+		ret[i] = w
+	}
+	return ret
+}
+
+func (cfg *Config) isWorkloadRollupEnabled() bool {
+	return cfg.WorkloadRollup != nil && cfg.WorkloadRollup.Enable
+}
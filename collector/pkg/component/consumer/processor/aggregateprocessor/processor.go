@@ -29,11 +29,10 @@ type AggregateProcessor struct {
 	telemetry    *component.TelemetryTools
 	nextConsumer consumer.Consumer
 
-	aggregator               aggregator.Aggregator
+	windows                  []*aggregationWindow
 	netRequestLabelSelectors *aggregator.LabelSelectors
 	tcpLabelSelectors        *aggregator.LabelSelectors
 	stopCh                   chan struct{}
-	ticker                   *time.Ticker
 }
 
 func New(config interface{}, telemetry *component.TelemetryTools, nextConsumer consumer.Consumer) processor.Processor {
@@ -43,13 +42,20 @@ func New(config interface{}, telemetry *component.TelemetryTools, nextConsumer c
 		telemetry:    telemetry,
 		nextConsumer: nextConsumer,
 
-		aggregator:               defaultaggregator.NewDefaultAggregator(toAggregatedConfig(cfg.AggregateKindMap)),
 		netRequestLabelSelectors: newNetRequestLabelSelectors(),
 		tcpLabelSelectors:        newTcpLabelSelectors(),
 		stopCh:                   make(chan struct{}),
-		ticker:                   time.NewTicker(time.Duration(cfg.TickerInterval) * time.Second),
 	}
-	go p.runTicker()
+	aggConfig := toAggregatedConfig(cfg.AggregateKindMap)
+	if cfg.isWorkloadRollupEnabled() {
+		addWorkloadRollupKinds(aggConfig)
+	}
+	for _, windowCfg := range cfg.getWindowConfigs() {
+		p.windows = append(p.windows, newAggregationWindow(windowCfg, aggConfig))
+	}
+	for _, window := range p.windows {
+		go p.runTicker(window)
+	}
 	return p
 }
 
@@ -92,51 +98,82 @@ func newKindConfig(rawConfig *AggregatedKindConfig) (kindConfig defaultaggregato
 }
 
 // TODO: Graceful shutdown
-func (p *AggregateProcessor) runTicker() {
+func (p *AggregateProcessor) runTicker(window *aggregationWindow) {
+	if delay := window.firstDelay(time.Now()); delay > 0 {
+		// Wait until the next boundary so that the window is aligned to the wall clock.
+		select {
+		case <-p.stopCh:
+			return
+		case <-time.After(delay):
+			p.dumpWindow(window)
+		}
+	}
+	ticker := time.NewTicker(window.interval)
+	defer ticker.Stop()
 	for {
 		select {
 		case <-p.stopCh:
 			return
-		case <-p.ticker.C:
-			aggResults := p.aggregator.Dump()
-			for _, agg := range aggResults {
-				err := p.nextConsumer.Consume(agg)
-				if err != nil {
-					p.telemetry.Logger.Warn("Error happened when consuming aggregated recordersMap",
-						zap.Error(err))
-				}
-			}
+		case <-ticker.C:
+			p.dumpWindow(window)
+		}
+	}
+}
+
+func (p *AggregateProcessor) dumpWindow(window *aggregationWindow) {
+	aggResults := window.dump()
+	for _, agg := range aggResults {
+		err := p.nextConsumer.Consume(agg)
+		if err != nil {
+			p.telemetry.Logger.Warn("Error happened when consuming aggregated recordersMap",
+				zap.String("window", window.name), zap.Error(err))
+		}
+	}
+}
+
+func (p *AggregateProcessor) aggregate(metricGroupName string, dataGroup *model.DataGroup, selectors *aggregator.LabelSelectors) {
+	for _, window := range p.windows {
+		if window.accept(metricGroupName) {
+			window.aggregator.Aggregate(dataGroup, selectors)
 		}
 	}
 }
 
 func (p *AggregateProcessor) Consume(dataGroup *model.DataGroup) error {
-	switch dataGroup.Name {
+	metricGroupName := dataGroup.Name
+	switch metricGroupName {
 	case constnames.NetRequestMetricGroupName:
 		var abnormalDataErr error
 		// The abnormal recordersMap will be treated as trace in later processing.
 		// Must trace be merged into metrics in this place? Yes, because we have to generate histogram metrics,
 		// trace recordersMap should not be recorded again, otherwise the percentiles will be much higher.
-		if p.isSampled(dataGroup) {
+		sampled := p.isSampled(dataGroup)
+		dataGroup.Labels.RemoveAttribute(constlabels.TraceSampled)
+		if sampled {
 			dataGroup.Name = constnames.SingleNetRequestMetricGroup
 			cpuanalyzer.ReceiveDataGroupAsSignal(dataGroup)
 			abnormalDataErr = p.nextConsumer.Consume(dataGroup)
 		}
+		if p.cfg.isWorkloadRollupEnabled() {
+			if workloadDataGroup := newWorkloadDataGroup(dataGroup); workloadDataGroup != nil {
+				p.aggregate(workloadDataGroup.Name, workloadDataGroup, workloadLabelSelectors)
+			}
+		}
 		dataGroup.Name = constnames.AggregatedNetRequestMetricGroup
-		p.aggregator.Aggregate(dataGroup, p.netRequestLabelSelectors)
+		p.aggregate(metricGroupName, dataGroup, p.netRequestLabelSelectors)
 		return abnormalDataErr
 	case constnames.TcpRttMetricGroupName:
 		fallthrough
 	case constnames.TcpRetransmitMetricGroupName:
 		fallthrough
 	case constnames.TcpDropMetricGroupName:
-		p.aggregator.Aggregate(dataGroup, p.tcpLabelSelectors)
+		p.aggregate(metricGroupName, dataGroup, p.tcpLabelSelectors)
 		return nil
 	case constnames.TcpConnectMetricGroupName:
-		p.aggregator.Aggregate(dataGroup, tcpConnectLabelSelectors)
+		p.aggregate(metricGroupName, dataGroup, tcpConnectLabelSelectors)
 		return nil
 	default:
-		p.aggregator.Aggregate(dataGroup, p.netRequestLabelSelectors)
+		p.aggregate(metricGroupName, dataGroup, p.netRequestLabelSelectors)
 		return nil
 	}
 }
@@ -179,9 +216,30 @@ func newNetRequestLabelSelectors() *aggregator.LabelSelectors {
 		aggregator.LabelSelector{Name: constlabels.DnsRcode, VType: aggregator.IntType},
 		aggregator.LabelSelector{Name: constlabels.SqlErrCode, VType: aggregator.IntType},
 		aggregator.LabelSelector{Name: constlabels.ContentKey, VType: aggregator.StringType},
+		aggregator.LabelSelector{Name: constlabels.ProtocolVersion, VType: aggregator.StringType},
 		aggregator.LabelSelector{Name: constlabels.DnsDomain, VType: aggregator.StringType},
 		aggregator.LabelSelector{Name: constlabels.KafkaTopic, VType: aggregator.StringType},
 		aggregator.LabelSelector{Name: constlabels.RocketMQErrCode, VType: aggregator.IntType},
+		aggregator.LabelSelector{Name: constlabels.MongodbErrCode, VType: aggregator.IntType},
+		aggregator.LabelSelector{Name: constlabels.TarsRetCode, VType: aggregator.IntType},
+		aggregator.LabelSelector{Name: constlabels.GrpcStatusCode, VType: aggregator.IntType},
+		aggregator.LabelSelector{Name: constlabels.BrpcErrorCode, VType: aggregator.IntType},
+		aggregator.LabelSelector{Name: constlabels.BoltResponseStatus, VType: aggregator.IntType},
+		aggregator.LabelSelector{Name: constlabels.CassandraErrCode, VType: aggregator.IntType},
+		aggregator.LabelSelector{Name: constlabels.FtpReplyCode, VType: aggregator.IntType},
+		aggregator.LabelSelector{Name: constlabels.SshDisconnectReason, VType: aggregator.IntType},
+		aggregator.LabelSelector{Name: constlabels.MqttReasonCode, VType: aggregator.IntType},
+		aggregator.LabelSelector{Name: constlabels.LdapResultCode, VType: aggregator.IntType},
+		aggregator.LabelSelector{Name: constlabels.WebsocketCloseCode, VType: aggregator.IntType},
+		aggregator.LabelSelector{Name: constlabels.QuicAlpn, VType: aggregator.StringType},
+		aggregator.LabelSelector{Name: constlabels.SipStatusCode, VType: aggregator.IntType},
+		aggregator.LabelSelector{Name: constlabels.RedisRedirect, VType: aggregator.StringType},
+		aggregator.LabelSelector{Name: constlabels.TlsCipherSuite, VType: aggregator.StringType},
+		aggregator.LabelSelector{Name: constlabels.TlsAlert, VType: aggregator.IntType},
+		aggregator.LabelSelector{Name: constlabels.TripleStatusCode, VType: aggregator.IntType},
+		aggregator.LabelSelector{Name: constlabels.PostgresqlSqlState, VType: aggregator.StringType},
+		aggregator.LabelSelector{Name: constlabels.BulkTransferDirection, VType: aggregator.StringType},
+		aggregator.LabelSelector{Name: constlabels.IsHealthCheck, VType: aggregator.BooleanType},
 	)
 }
 
@@ -243,6 +301,7 @@ func newTcpConnectLabelSelectors() *aggregator.LabelSelectors {
 		aggregator.LabelSelector{Name: constlabels.DstContainer, VType: aggregator.StringType},
 		aggregator.LabelSelector{Name: constlabels.Errno, VType: aggregator.IntType},
 		aggregator.LabelSelector{Name: constlabels.Success, VType: aggregator.BooleanType},
+		aggregator.LabelSelector{Name: constlabels.FailureReason, VType: aggregator.StringType},
 	)
 }
 
@@ -256,6 +315,10 @@ func (p *AggregateProcessor) isSampled(dataGroup *model.DataGroup) bool {
 			return true
 		}
 	} else {
+		// The network analyzer may have decided with the same rate before building the payloads.
+		if dataGroup.Labels.HasAttribute(constlabels.TraceSampled) {
+			return dataGroup.Labels.GetBoolValue(constlabels.TraceSampled)
+		}
 		if randSeed < p.cfg.SamplingRate.NormalData {
 			return true
 		}
@@ -0,0 +1,29 @@
+package aggregateprocessor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+)
+
+func TestIsSampled_DecidedByAnalyzer(t *testing.T) {
+	cfg := NewDefaultConfig()
+	cfg.SamplingRate.NormalData = 100
+	p := &AggregateProcessor{cfg: cfg}
+	newDataGroup := func(isError bool, traceSampled bool) *model.DataGroup {
+		labels := model.NewAttributeMap()
+		labels.AddBoolValue(constlabels.IsError, isError)
+		labels.AddBoolValue(constlabels.TraceSampled, traceSampled)
+		return model.NewDataGroup(constnames.NetRequestMetricGroupName, labels, 1)
+	}
+
+	assert.False(t, p.isSampled(newDataGroup(false, false)))
+	cfg.SamplingRate.NormalData = 0
+	assert.True(t, p.isSampled(newDataGroup(false, true)))
+	// The abnormal data are sampled by their own rates.
+	assert.True(t, p.isSampled(newDataGroup(true, false)))
+}
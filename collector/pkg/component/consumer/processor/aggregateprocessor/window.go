@@ -0,0 +1,67 @@
+package aggregateprocessor
+
+import (
+	"time"
+
+	"github.com/Kindling-project/kindling/collector/pkg/aggregator"
+	"github.com/Kindling-project/kindling/collector/pkg/aggregator/defaultaggregator"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+// aggregationWindow holds the aggregated results of the accepted metric groups and dumps them
+// every interval.
+type aggregationWindow struct {
+	name      string
+	interval  time.Duration
+	alignment string
+	// metricGroups is nil if all metric groups are accepted.
+	metricGroups map[string]struct{}
+	aggregator   aggregator.Aggregator
+}
+
+func newAggregationWindow(cfg WindowConfig, aggConfig *defaultaggregator.AggregatedConfig) *aggregationWindow {
+	interval := cfg.Interval
+	if interval <= 0 {
+		interval = 5
+	}
+	w := &aggregationWindow{
+		name:       cfg.Name,
+		interval:   time.Duration(interval) * time.Second,
+		alignment:  cfg.Alignment,
+		aggregator: defaultaggregator.NewDefaultAggregator(aggConfig),
+	}
+	if len(cfg.MetricGroups) > 0 {
+		w.metricGroups = make(map[string]struct{}, len(cfg.MetricGroups))
+		for _, name := range cfg.MetricGroups {
+			w.metricGroups[name] = struct{}{}
+		}
+	}
+	return w
+}
+
+func (w *aggregationWindow) accept(metricGroupName string) bool {
+	if w.metricGroups == nil {
+		return true
+	}
+	_, ok := w.metricGroups[metricGroupName]
+	return ok
+}
+
+// firstDelay returns how long to wait before the window starts ticking.
+func (w *aggregationWindow) firstDelay(now time.Time) time.Duration {
+	if w.alignment != WallClockAlignment {
+		return 0
+	}
+	return now.Truncate(w.interval).Add(w.interval).Sub(now)
+}
+
+func (w *aggregationWindow) dump() []*model.DataGroup {
+	results := w.aggregator.Dump()
+	if w.name != "" {
+		for _, result := range results {
+			result.Labels.UpdateAddStringValue(constlabels.AggregationWindow, w.name)
+		}
+	}
+	return results
+}
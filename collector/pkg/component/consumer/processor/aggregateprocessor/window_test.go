@@ -0,0 +1,86 @@
+package aggregateprocessor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constvalues"
+)
+
+func TestGetWindowConfigs(t *testing.T) {
+	cfg := NewDefaultConfig()
+	windows := cfg.getWindowConfigs()
+	assert.Equal(t, []WindowConfig{{Interval: 5, Alignment: RollingAlignment}}, windows)
+
+	cfg.Windows = []WindowConfig{
+		{Name: "alerting", Interval: 15, Alignment: WallClockAlignment},
+		{Name: "dashboard"},
+	}
+	windows = cfg.getWindowConfigs()
+	assert.Equal(t, 15, windows[0].Interval)
+	assert.Equal(t, cfg.TickerInterval, windows[1].Interval)
+}
+
+func TestAggregationWindow_FirstDelay(t *testing.T) {
+	now := time.Date(2022, 1, 1, 0, 0, 7, 0, time.UTC)
+	rolling := newAggregationWindow(WindowConfig{Interval: 15}, toAggregatedConfig(nil))
+	assert.Equal(t, time.Duration(0), rolling.firstDelay(now))
+
+	aligned := newAggregationWindow(WindowConfig{Interval: 15, Alignment: WallClockAlignment}, toAggregatedConfig(nil))
+	assert.Equal(t, 8*time.Second, aligned.firstDelay(now))
+}
+
+func TestAggregationWindow_Dump(t *testing.T) {
+	cfg := NewDefaultConfig()
+	window := newAggregationWindow(WindowConfig{
+		Name:         "alerting",
+		Interval:     15,
+		MetricGroups: []string{constnames.TcpRttMetricGroupName},
+	}, toAggregatedConfig(cfg.AggregateKindMap))
+	assert.True(t, window.accept(constnames.TcpRttMetricGroupName))
+	assert.False(t, window.accept(constnames.NetRequestMetricGroupName))
+
+	labels := model.NewAttributeMap()
+	labels.AddStringValue(constlabels.SrcIp, "10.0.0.1")
+	window.aggregator.Aggregate(model.NewDataGroup(constnames.TcpRttMetricGroupName, labels, 1,
+		model.NewIntMetric("kindling_tcp_srtt_microseconds", 100)), newTcpLabelSelectors())
+	results := window.dump()
+	assert.Equal(t, 1, len(results))
+	assert.Equal(t, "alerting", results[0].Labels.GetStringValue(constlabels.AggregationWindow))
+}
+
+func TestAggregationWindow_RequestPhases(t *testing.T) {
+	cfg := NewDefaultConfig()
+	window := newAggregationWindow(WindowConfig{Interval: 5}, toAggregatedConfig(cfg.AggregateKindMap))
+	for _, phases := range [][]int64{{0, 10, 100, 20}, {50, 30, 300, 40}} {
+		labels := model.NewAttributeMap()
+		labels.AddStringValue(constlabels.DstIp, "10.0.0.2")
+		window.aggregator.Aggregate(model.NewDataGroup(constnames.NetRequestMetricGroupName, labels, 1,
+			model.NewIntMetric(constvalues.RequestTotalTime, phases[0]+phases[1]+phases[2]+phases[3]),
+			model.NewIntMetric(constvalues.ConnectTime, phases[0]),
+			model.NewIntMetric(constvalues.RequestSentTime, phases[1]),
+			model.NewIntMetric(constvalues.WaitingTtfbTime, phases[2]),
+			model.NewIntMetric(constvalues.ContentDownloadTime, phases[3])), newNetRequestLabelSelectors())
+	}
+	results := window.dump()
+	assert.Equal(t, 1, len(results))
+	// Each phase is averaged like the duration, so the dashboards tell which one dominates the latency.
+	expects := map[string]int64{
+		constvalues.RequestTotalTime + "_avg":    275,
+		constvalues.ConnectTime + "_avg":         25,
+		constvalues.RequestSentTime + "_avg":     20,
+		constvalues.WaitingTtfbTime + "_avg":     200,
+		constvalues.ContentDownloadTime + "_avg": 30,
+	}
+	for name, expect := range expects {
+		metric, ok := results[0].GetMetric(name)
+		assert.True(t, ok, name)
+		assert.Equal(t, expect, metric.GetInt().Value, name)
+		assert.NotEmpty(t, constnames.ToKindlingNetMetricName(name, false), name)
+	}
+}
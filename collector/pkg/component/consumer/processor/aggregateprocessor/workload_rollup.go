@@ -0,0 +1,74 @@
+package aggregateprocessor
+
+import (
+	"github.com/Kindling-project/kindling/collector/pkg/aggregator"
+	"github.com/Kindling-project/kindling/collector/pkg/aggregator/defaultaggregator"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constvalues"
+)
+
+var workloadLabelSelectors = newWorkloadLabelSelectors()
+
+// newWorkloadLabelSelectors returns the labels of the workload metrics, which don't
+// contain any pod-level or port-level labels so that the series are stable.
+func newWorkloadLabelSelectors() *aggregator.LabelSelectors {
+	return aggregator.NewLabelSelectors(
+		aggregator.LabelSelector{Name: constlabels.Namespace, VType: aggregator.StringType},
+		aggregator.LabelSelector{Name: constlabels.WorkloadKind, VType: aggregator.StringType},
+		aggregator.LabelSelector{Name: constlabels.WorkloadName, VType: aggregator.StringType},
+		aggregator.LabelSelector{Name: constlabels.Service, VType: aggregator.StringType},
+		aggregator.LabelSelector{Name: constlabels.Protocol, VType: aggregator.StringType},
+	)
+}
+
+// addWorkloadRollupKinds adds the aggregation kinds of the workload metrics
+// unless they are configured by users.
+func addWorkloadRollupKinds(aggConfig *defaultaggregator.AggregatedConfig) {
+	defaultKinds := map[string][]defaultaggregator.KindConfig{
+		constnames.WorkloadRequestDurationMetric: {
+			{OutputName: constnames.WorkloadRequestDurationTotalMetric, Kind: defaultaggregator.SumKind},
+			{OutputName: constnames.WorkloadRequestTotalMetric, Kind: defaultaggregator.CountKind},
+		},
+		constnames.WorkloadRequestErrorTotalMetric: {
+			{OutputName: constnames.WorkloadRequestErrorTotalMetric, Kind: defaultaggregator.SumKind},
+		},
+	}
+	for name, kinds := range defaultKinds {
+		if _, ok := aggConfig.KindMap[name]; !ok {
+			aggConfig.KindMap[name] = kinds
+		}
+	}
+}
+
+// newWorkloadDataGroup converts the request to the dataGroup of its server-side workload.
+// Nil is returned if the request is not from the server side or the workload is unknown.
+func newWorkloadDataGroup(dataGroup *model.DataGroup) *model.DataGroup {
+	labels := dataGroup.Labels
+	if !labels.GetBoolValue(constlabels.IsServer) {
+		return nil
+	}
+	workloadName := labels.GetStringValue(constlabels.DstWorkloadName)
+	if workloadName == "" {
+		return nil
+	}
+	duration, ok := dataGroup.GetMetric(constvalues.RequestTotalTime)
+	if !ok {
+		return nil
+	}
+	var errorCount int64
+	if labels.GetBoolValue(constlabels.IsError) {
+		errorCount = 1
+	}
+	workloadLabels := model.NewAttributeMapWithValues(map[string]model.AttributeValue{
+		constlabels.Namespace:    model.NewStringValue(labels.GetStringValue(constlabels.DstNamespace)),
+		constlabels.WorkloadKind: model.NewStringValue(labels.GetStringValue(constlabels.DstWorkloadKind)),
+		constlabels.WorkloadName: model.NewStringValue(workloadName),
+		constlabels.Service:      model.NewStringValue(labels.GetStringValue(constlabels.DstService)),
+		constlabels.Protocol:     model.NewStringValue(labels.GetStringValue(constlabels.Protocol)),
+	})
+	return model.NewDataGroup(constnames.WorkloadRequestMetricGroupName, workloadLabels, dataGroup.Timestamp,
+		model.NewIntMetric(constnames.WorkloadRequestDurationMetric, duration.GetInt().Value),
+		model.NewIntMetric(constnames.WorkloadRequestErrorTotalMetric, errorCount))
+}
@@ -0,0 +1,65 @@
+package aggregateprocessor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constvalues"
+)
+
+func newRequestDataGroup(pod string, isServer bool, isError bool, duration int64) *model.DataGroup {
+	labels := model.NewAttributeMap()
+	labels.AddBoolValue(constlabels.IsServer, isServer)
+	labels.AddBoolValue(constlabels.IsError, isError)
+	labels.AddStringValue(constlabels.DstNamespace, "default")
+	labels.AddStringValue(constlabels.DstWorkloadKind, "deployment")
+	labels.AddStringValue(constlabels.DstWorkloadName, "app")
+	labels.AddStringValue(constlabels.DstPod, pod)
+	labels.AddStringValue(constlabels.Protocol, "http")
+	return model.NewDataGroup(constnames.NetRequestMetricGroupName, labels, 1,
+		model.NewIntMetric(constvalues.RequestTotalTime, duration))
+}
+
+func TestNewWorkloadDataGroup(t *testing.T) {
+	assert.Nil(t, newWorkloadDataGroup(newRequestDataGroup("app-0", false, false, 100)))
+
+	workloadDataGroup := newWorkloadDataGroup(newRequestDataGroup("app-0", true, true, 100))
+	assert.Equal(t, constnames.WorkloadRequestMetricGroupName, workloadDataGroup.Name)
+	assert.Equal(t, "app", workloadDataGroup.Labels.GetStringValue(constlabels.WorkloadName))
+	assert.False(t, workloadDataGroup.Labels.HasAttribute(constlabels.DstPod))
+	errorCount, _ := workloadDataGroup.GetMetric(constnames.WorkloadRequestErrorTotalMetric)
+	assert.Equal(t, int64(1), errorCount.GetInt().Value)
+}
+
+func TestWorkloadRollup(t *testing.T) {
+	cfg := NewDefaultConfig()
+	aggConfig := toAggregatedConfig(cfg.AggregateKindMap)
+	addWorkloadRollupKinds(aggConfig)
+	window := newAggregationWindow(WindowConfig{Interval: 5}, aggConfig)
+
+	// The requests of different pods are rolled up into one series.
+	for i, dataGroup := range []*model.DataGroup{
+		newRequestDataGroup("app-0", true, false, 100),
+		newRequestDataGroup("app-1", true, true, 300),
+	} {
+		workloadDataGroup := newWorkloadDataGroup(dataGroup)
+		assert.NotNil(t, workloadDataGroup, "request %d", i)
+		window.aggregator.Aggregate(workloadDataGroup, workloadLabelSelectors)
+	}
+	results := window.dump()
+	assert.Equal(t, 1, len(results))
+	expects := map[string]int64{
+		constnames.WorkloadRequestTotalMetric:         2,
+		constnames.WorkloadRequestErrorTotalMetric:    1,
+		constnames.WorkloadRequestDurationTotalMetric: 400,
+	}
+	for name, expect := range expects {
+		metric, ok := results[0].GetMetric(name)
+		assert.True(t, ok, name)
+		assert.Equal(t, expect, metric.GetInt().Value, name)
+	}
+}
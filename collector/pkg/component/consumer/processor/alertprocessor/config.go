@@ -0,0 +1,75 @@
+package alertprocessor
+
+type Config struct {
+	Enable bool `mapstructure:"enable"`
+	// The unit is second. The rules are evaluated once every EvaluationInterval.
+	EvaluationInterval int `mapstructure:"evaluation_interval"`
+	// AggregationWindow is the name of the aggregateprocessor window whose results are evaluated, so the
+	// requests are not counted more than once when multiple windows are configured. The results of the
+	// default window, which has no name, are evaluated if it is empty.
+	AggregationWindow string `mapstructure:"aggregation_window"`
+	// The unit is second. The firing alerts are sent again once every ResendInterval, so Alertmanager
+	// doesn't resolve them by itself. The alerts are only sent when they fire or resolve if it is 0.
+	ResendInterval int          `mapstructure:"resend_interval"`
+	Rules          []RuleConfig `mapstructure:"rules"`
+	// Webhook receives the alerts as JSON. It is disabled if the url is empty.
+	Webhook *WebhookConfig `mapstructure:"webhook"`
+	// Alertmanager receives the alerts through its v2 API. It is disabled if the endpoint is empty.
+	Alertmanager *AlertmanagerConfig `mapstructure:"alertmanager"`
+}
+
+type RuleConfig struct {
+	// Name is added to the alerts as the label "alertname".
+	Name string `mapstructure:"name"`
+	// Expr compares a metric of the requests with a threshold, e.g. `error_rate{dst_workload_name="cart"} > 0.05`.
+	// Valid metrics: ["request_count", "error_count", "error_rate", "request_rate", "latency_avg"], where
+	// request_rate is per second and latency_avg is in milliseconds. The labels are matched with "=", "!=",
+	// "=~" or "!~" like PromQL.
+	Expr string `mapstructure:"expr"`
+	// The unit is second. The metric is calculated from the requests in the last Range.
+	Range int `mapstructure:"range"`
+	// The unit is second. The alert fires after the expression has been true for For, and is pending before.
+	For int `mapstructure:"for"`
+	// GroupBy are the labels the requests are grouped by, and each group is alerted separately.
+	// The requests are grouped by the edges between the workloads if it is empty.
+	GroupBy []string `mapstructure:"group_by"`
+	// Labels are added to the alerts, e.g. {"severity": "warning"}.
+	Labels map[string]string `mapstructure:"labels"`
+	// Annotations are added to the alerts, e.g. {"summary": "Too many errors"}.
+	Annotations map[string]string `mapstructure:"annotations"`
+}
+
+type WebhookConfig struct {
+	// Url receives a POST request with the alerts that fire, resolve or are resent.
+	Url string `mapstructure:"url"`
+	// The unit is second.
+	Timeout int `mapstructure:"timeout"`
+}
+
+type AlertmanagerConfig struct {
+	// Endpoint is the address of Alertmanager, e.g. "http://alertmanager:9093".
+	Endpoint string `mapstructure:"endpoint"`
+	// The unit is second.
+	Timeout int `mapstructure:"timeout"`
+}
+
+func NewDefaultConfig() *Config {
+	return &Config{
+		Enable:             false,
+		EvaluationInterval: 15,
+		ResendInterval:     60,
+		Webhook: &WebhookConfig{
+			Timeout: 5,
+		},
+		Alertmanager: &AlertmanagerConfig{
+			Timeout: 5,
+		},
+	}
+}
+
+func (cfg *Config) getEvaluationInterval() int {
+	if cfg.EvaluationInterval > 0 {
+		return cfg.EvaluationInterval
+	}
+	return 15
+}
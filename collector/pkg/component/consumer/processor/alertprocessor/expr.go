@@ -0,0 +1,233 @@
+package alertprocessor
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// The metrics of the requests the expressions could compare.
+const (
+	requestCountMetric = "request_count"
+	errorCountMetric   = "error_count"
+	errorRateMetric    = "error_rate"
+	requestRateMetric  = "request_rate"
+	latencyAvgMetric   = "latency_avg"
+)
+
+// expression is a metric of the requests compared with a threshold, written like PromQL, e.g.
+// `error_rate{dst_workload_name="cart",protocol=~"http|grpc"} > 0.05`.
+type expression struct {
+	metric    string
+	matchers  []*matcher
+	operator  string
+	threshold float64
+}
+
+type matcher struct {
+	name     string
+	operator string
+	value    string
+	regexp   *regexp.Regexp
+}
+
+// requestStats is the sum of the aggregated requests in the range of a rule.
+type requestStats struct {
+	count     int64
+	errors    int64
+	totalTime int64
+}
+
+func parseExpr(expr string) (*expression, error) {
+	p := &exprParser{input: expr}
+	ret := &expression{metric: p.readIdentifier()}
+	switch ret.metric {
+	case requestCountMetric, errorCountMetric, errorRateMetric, requestRateMetric, latencyAvgMetric:
+	case "":
+		return nil, fmt.Errorf("no metric is found in the expression [%s]", expr)
+	default:
+		return nil, fmt.Errorf("unsupported metric [%s] in the expression [%s]", ret.metric, expr)
+	}
+	if p.consume("{") {
+		for !p.consume("}") {
+			m, err := p.readMatcher()
+			if err != nil {
+				return nil, fmt.Errorf("invalid label matcher in the expression [%s]: %w", expr, err)
+			}
+			ret.matchers = append(ret.matchers, m)
+			if !p.consume(",") && !p.peek("}") {
+				return nil, fmt.Errorf("the label matchers are not closed in the expression [%s]", expr)
+			}
+		}
+	}
+	ret.operator = p.readOperator(">=", "<=", "==", "!=", ">", "<")
+	if ret.operator == "" {
+		return nil, fmt.Errorf("no comparison operator is found in the expression [%s]", expr)
+	}
+	threshold, err := strconv.ParseFloat(strings.TrimSpace(p.input[p.pos:]), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid threshold in the expression [%s]: %w", expr, err)
+	}
+	ret.threshold = threshold
+	return ret, nil
+}
+
+// matches returns true if the labels match all the matchers. The missing labels are matched as empty.
+func (e *expression) matches(labels map[string]string) bool {
+	for _, m := range e.matchers {
+		value := labels[m.name]
+		var matched bool
+		switch m.operator {
+		case "=":
+			matched = value == m.value
+		case "!=":
+			matched = value != m.value
+		case "=~":
+			matched = m.regexp.MatchString(value)
+		case "!~":
+			matched = !m.regexp.MatchString(value)
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// value returns the metric of the requests in the range, or false if it is undefined, e.g. the error
+// rate without any requests.
+func (e *expression) value(stats requestStats, rangeSeconds int) (float64, bool) {
+	switch e.metric {
+	case requestCountMetric:
+		return float64(stats.count), true
+	case errorCountMetric:
+		return float64(stats.errors), true
+	case requestRateMetric:
+		return float64(stats.count) / float64(rangeSeconds), true
+	case errorRateMetric:
+		if stats.count == 0 {
+			return 0, false
+		}
+		return float64(stats.errors) / float64(stats.count), true
+	case latencyAvgMetric:
+		if stats.count == 0 {
+			return 0, false
+		}
+		return float64(stats.totalTime) / float64(stats.count) / 1e6, true
+	}
+	return 0, false
+}
+
+func (e *expression) compare(value float64) bool {
+	switch e.operator {
+	case ">":
+		return value > e.threshold
+	case ">=":
+		return value >= e.threshold
+	case "<":
+		return value < e.threshold
+	case "<=":
+		return value <= e.threshold
+	case "==":
+		return value == e.threshold
+	case "!=":
+		return value != e.threshold
+	}
+	return false
+}
+
+type exprParser struct {
+	input string
+	pos   int
+}
+
+func (p *exprParser) skipSpaces() {
+	for p.pos < len(p.input) && (p.input[p.pos] == ' ' || p.input[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+func (p *exprParser) peek(token string) bool {
+	p.skipSpaces()
+	return strings.HasPrefix(p.input[p.pos:], token)
+}
+
+func (p *exprParser) consume(token string) bool {
+	if !p.peek(token) {
+		return false
+	}
+	p.pos += len(token)
+	return true
+}
+
+func (p *exprParser) readIdentifier() string {
+	p.skipSpaces()
+	start := p.pos
+	for p.pos < len(p.input) {
+		c := p.input[p.pos]
+		if c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (p.pos > start && c >= '0' && c <= '9') {
+			p.pos++
+			continue
+		}
+		break
+	}
+	return p.input[start:p.pos]
+}
+
+// readOperator returns the first operator found at the position, so the longer ones must be listed first.
+func (p *exprParser) readOperator(operators ...string) string {
+	for _, operator := range operators {
+		if p.consume(operator) {
+			return operator
+		}
+	}
+	return ""
+}
+
+func (p *exprParser) readMatcher() (*matcher, error) {
+	m := &matcher{name: p.readIdentifier()}
+	if m.name == "" {
+		return nil, fmt.Errorf("no label name is found at %d", p.pos)
+	}
+	m.operator = p.readOperator("=~", "!~", "!=", "=")
+	if m.operator == "" {
+		return nil, fmt.Errorf("no matching operator is found after the label [%s]", m.name)
+	}
+	value, err := p.readString()
+	if err != nil {
+		return nil, err
+	}
+	m.value = value
+	if m.operator == "=~" || m.operator == "!~" {
+		// The regular expressions are fully anchored like PromQL.
+		if m.regexp, err = regexp.Compile("^(?:" + value + ")$"); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+func (p *exprParser) readString() (string, error) {
+	p.skipSpaces()
+	if p.pos >= len(p.input) || (p.input[p.pos] != '"' && p.input[p.pos] != '\'') {
+		return "", fmt.Errorf("no quoted value is found at %d", p.pos)
+	}
+	quote := p.input[p.pos]
+	for end := p.pos + 1; end < len(p.input); end++ {
+		if p.input[end] == '\\' {
+			end++
+			continue
+		}
+		if p.input[end] != quote {
+			continue
+		}
+		raw := p.input[p.pos+1 : end]
+		p.pos = end + 1
+		if quote == '\'' {
+			return strings.ReplaceAll(raw, `\'`, `'`), nil
+		}
+		return strconv.Unquote(`"` + raw + `"`)
+	}
+	return "", fmt.Errorf("the quoted value is not closed")
+}
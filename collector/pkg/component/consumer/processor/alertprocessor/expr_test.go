@@ -0,0 +1,63 @@
+package alertprocessor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseExpr(t *testing.T) {
+	expr, err := parseExpr(`error_rate{dst_workload_name="cart", protocol=~'http|grpc', src_namespace!="test"} > 0.05`)
+	if assert.NoError(t, err) {
+		assert.Equal(t, errorRateMetric, expr.metric)
+		assert.Equal(t, ">", expr.operator)
+		assert.Equal(t, 0.05, expr.threshold)
+		assert.Len(t, expr.matchers, 3)
+		assert.True(t, expr.matches(map[string]string{"dst_workload_name": "cart", "protocol": "grpc"}))
+		assert.False(t, expr.matches(map[string]string{"dst_workload_name": "cart", "protocol": "https"}))
+		assert.False(t, expr.matches(map[string]string{"dst_workload_name": "cart", "protocol": "http", "src_namespace": "test"}))
+	}
+
+	expr, err = parseExpr("latency_avg>=200")
+	if assert.NoError(t, err) {
+		assert.Equal(t, ">=", expr.operator)
+		assert.Empty(t, expr.matchers)
+		assert.True(t, expr.matches(map[string]string{"protocol": "http"}))
+	}
+
+	for _, invalid := range []string{
+		"",
+		"cpu_usage > 1",
+		"error_rate 0.05",
+		`error_rate{protocol="http" > 0.05`,
+		`error_rate{protocol=http} > 0.05`,
+		`error_rate{protocol=~"("} > 0.05`,
+		"error_rate > high",
+	} {
+		_, err := parseExpr(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestExpression_value(t *testing.T) {
+	stats := requestStats{count: 120, errors: 6, totalTime: 120 * 50e6}
+	tests := []struct {
+		metric string
+		want   float64
+	}{
+		{metric: requestCountMetric, want: 120},
+		{metric: errorCountMetric, want: 6},
+		{metric: errorRateMetric, want: 0.05},
+		{metric: requestRateMetric, want: 2},
+		{metric: latencyAvgMetric, want: 50},
+	}
+	for _, tt := range tests {
+		t.Run(tt.metric, func(t *testing.T) {
+			value, ok := (&expression{metric: tt.metric}).value(stats, 60)
+			assert.True(t, ok)
+			assert.InDelta(t, tt.want, value, 1e-9)
+		})
+	}
+	_, ok := (&expression{metric: errorRateMetric}).value(requestStats{}, 60)
+	assert.False(t, ok)
+}
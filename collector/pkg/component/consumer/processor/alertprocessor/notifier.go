@@ -0,0 +1,139 @@
+package alertprocessor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	FiringStatus   = "firing"
+	ResolvedStatus = "resolved"
+)
+
+// Alert is sent to the notifiers when it fires, resolves or is resent.
+type Alert struct {
+	Status      string            `json:"status"`
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// Value is the metric of the expression at the last evaluation.
+	Value    float64   `json:"value"`
+	StartsAt time.Time `json:"startsAt"`
+	// EndsAt is only set for the resolved alerts.
+	EndsAt *time.Time `json:"endsAt,omitempty"`
+}
+
+type notifier interface {
+	notify(alerts []*Alert) error
+	endpoint() string
+}
+
+func newNotifiers(cfg *Config) []notifier {
+	var notifiers []notifier
+	if cfg.Webhook != nil && cfg.Webhook.Url != "" {
+		notifiers = append(notifiers, &webhookNotifier{
+			url:    cfg.Webhook.Url,
+			client: newHttpClient(cfg.Webhook.Timeout),
+		})
+	}
+	if cfg.Alertmanager != nil && cfg.Alertmanager.Endpoint != "" {
+		notifiers = append(notifiers, &alertmanagerNotifier{
+			url:    strings.TrimRight(cfg.Alertmanager.Endpoint, "/") + "/api/v2/alerts",
+			client: newHttpClient(cfg.Alertmanager.Timeout),
+			// Alertmanager resolves the alerts not resent before they end, so they are valid for a few
+			// resends like Prometheus does.
+			validFor: 4 * time.Duration(cfg.ResendInterval) * time.Second,
+		})
+	}
+	return notifiers
+}
+
+func newHttpClient(timeout int) *http.Client {
+	if timeout <= 0 {
+		timeout = 5
+	}
+	return &http.Client{Timeout: time.Duration(timeout) * time.Second}
+}
+
+// webhookNotifier posts {"alerts": [...]} to the url.
+type webhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+type webhookMessage struct {
+	Alerts []*Alert `json:"alerts"`
+}
+
+func (n *webhookNotifier) notify(alerts []*Alert) error {
+	return postJSON(n.client, n.url, &webhookMessage{Alerts: alerts})
+}
+
+func (n *webhookNotifier) endpoint() string {
+	return n.url
+}
+
+// alertmanagerNotifier posts the alerts to the v2 API of Alertmanager, which deduplicates, groups and
+// routes them to the receivers.
+type alertmanagerNotifier struct {
+	url      string
+	client   *http.Client
+	validFor time.Duration
+}
+
+type alertmanagerAlert struct {
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	StartsAt    time.Time         `json:"startsAt"`
+	EndsAt      *time.Time        `json:"endsAt,omitempty"`
+}
+
+func (n *alertmanagerNotifier) notify(alerts []*Alert) error {
+	now := time.Now()
+	body := make([]*alertmanagerAlert, 0, len(alerts))
+	for _, alert := range alerts {
+		annotations := make(map[string]string, len(alert.Annotations)+1)
+		for k, v := range alert.Annotations {
+			annotations[k] = v
+		}
+		annotations["value"] = strconv.FormatFloat(alert.Value, 'g', -1, 64)
+		endsAt := alert.EndsAt
+		if endsAt == nil && n.validFor > 0 {
+			validUntil := now.Add(n.validFor)
+			endsAt = &validUntil
+		}
+		body = append(body, &alertmanagerAlert{
+			Labels:      alert.Labels,
+			Annotations: annotations,
+			StartsAt:    alert.StartsAt,
+			EndsAt:      endsAt,
+		})
+	}
+	return postJSON(n.client, n.url, body)
+}
+
+func (n *alertmanagerNotifier) endpoint() string {
+	return n.url
+}
+
+func postJSON(client *http.Client, url string, v interface{}) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("the notifier responded with status %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,281 @@
+package alertprocessor
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/processor"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constvalues"
+)
+
+const Type = "alertprocessor"
+
+// defaultGroupBy groups the requests by the edges between the workloads.
+var defaultGroupBy = []string{
+	constlabels.SrcNamespace, constlabels.SrcWorkloadName,
+	constlabels.DstNamespace, constlabels.DstWorkloadName,
+}
+
+// AlertProcessor evaluates the alerting rules over the aggregated requests and sends the alerts to
+// the webhook or Alertmanager, so the clusters without Prometheus could be alerted too. The alerts are
+// evaluated and sent asynchronously and all the data groups are passed to the next consumer unchanged.
+type AlertProcessor struct {
+	cfg          *Config
+	telemetry    *component.TelemetryTools
+	nextConsumer consumer.Consumer
+
+	notifiers []notifier
+	// mutex protects the series and the alerts of the rules.
+	mutex sync.Mutex
+	rules []*rule
+}
+
+type rule struct {
+	name        string
+	expr        *expression
+	rangeSecond int
+	forDuration time.Duration
+	groupBy     []string
+	labels      map[string]string
+	annotations map[string]string
+
+	series map[string]*series
+	alerts map[string]*alertState
+}
+
+// series is the aggregated requests of a group in the range of the rule.
+type series struct {
+	labels  map[string]string
+	samples []sample
+}
+
+type sample struct {
+	time  time.Time
+	stats requestStats
+}
+
+type alertState struct {
+	labels     map[string]string
+	activeAt   time.Time
+	firedAt    time.Time
+	lastSentAt time.Time
+	value      float64
+}
+
+func New(config interface{}, telemetry *component.TelemetryTools, nextConsumer consumer.Consumer) processor.Processor {
+	cfg := config.(*Config)
+	p := &AlertProcessor{
+		cfg:          cfg,
+		telemetry:    telemetry,
+		nextConsumer: nextConsumer,
+	}
+	if !cfg.Enable {
+		return p
+	}
+	p.rules = newRules(cfg.Rules, telemetry.GetZapLogger())
+	p.notifiers = newNotifiers(cfg)
+	if len(p.rules) == 0 || len(p.notifiers) == 0 {
+		telemetry.Logger.Warn("No alerting rules or notifiers are configured, the alerts are disabled")
+		p.rules = nil
+		return p
+	}
+	go p.run()
+	return p
+}
+
+// newRules compiles the rules, and the invalid ones are skipped.
+func newRules(configs []RuleConfig, logger *zap.Logger) []*rule {
+	rules := make([]*rule, 0, len(configs))
+	for _, cfg := range configs {
+		expr, err := parseExpr(cfg.Expr)
+		if err != nil {
+			logger.Error("The alerting rule is skipped", zap.String("rule", cfg.Name), zap.Error(err))
+			continue
+		}
+		r := &rule{
+			name:        cfg.Name,
+			expr:        expr,
+			rangeSecond: cfg.Range,
+			forDuration: time.Duration(cfg.For) * time.Second,
+			groupBy:     cfg.GroupBy,
+			labels:      cfg.Labels,
+			annotations: cfg.Annotations,
+			series:      make(map[string]*series),
+			alerts:      make(map[string]*alertState),
+		}
+		if r.rangeSecond <= 0 {
+			r.rangeSecond = 60
+		}
+		if len(r.groupBy) == 0 {
+			r.groupBy = defaultGroupBy
+		}
+		rules = append(rules, r)
+	}
+	return rules
+}
+
+func (p *AlertProcessor) Consume(dataGroup *model.DataGroup) error {
+	if len(p.rules) > 0 && dataGroup.Name == constnames.AggregatedNetRequestMetricGroup &&
+		dataGroup.Labels.GetStringValue(constlabels.AggregationWindow) == p.cfg.AggregationWindow {
+		p.record(dataGroup, time.Now())
+	}
+	return p.nextConsumer.Consume(dataGroup)
+}
+
+func (p *AlertProcessor) record(dataGroup *model.DataGroup, now time.Time) {
+	count, ok := dataGroup.GetMetric(constvalues.RequestCount)
+	if !ok || count.GetInt() == nil || count.GetInt().Value == 0 {
+		return
+	}
+	stats := requestStats{count: count.GetInt().Value}
+	if totalTime, ok := dataGroup.GetMetric(constvalues.RequestTotalTime); ok && totalTime.GetInt() != nil {
+		stats.totalTime = totalTime.GetInt().Value
+	}
+	if dataGroup.Labels.GetBoolValue(constlabels.IsError) {
+		stats.errors = stats.count
+	}
+	labels := dataGroup.Labels.ToStringMap()
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	for _, r := range p.rules {
+		if !r.expr.matches(labels) {
+			continue
+		}
+		key, groupLabels := r.group(labels)
+		s, ok := r.series[key]
+		if !ok {
+			s = &series{labels: groupLabels}
+			r.series[key] = s
+		}
+		s.samples = append(s.samples, sample{time: now, stats: stats})
+	}
+}
+
+// group returns the key and the labels of the group the request belongs to.
+func (r *rule) group(labels map[string]string) (string, map[string]string) {
+	values := make([]string, len(r.groupBy))
+	groupLabels := make(map[string]string, len(r.groupBy))
+	for i, name := range r.groupBy {
+		values[i] = labels[name]
+		groupLabels[name] = labels[name]
+	}
+	return strings.Join(values, "\x00"), groupLabels
+}
+
+func (p *AlertProcessor) run() {
+	ticker := time.NewTicker(time.Duration(p.cfg.getEvaluationInterval()) * time.Second)
+	defer ticker.Stop()
+	for range ticker.C {
+		alerts := p.evaluate(time.Now())
+		if len(alerts) == 0 {
+			continue
+		}
+		for _, n := range p.notifiers {
+			if err := n.notify(alerts); err != nil {
+				p.telemetry.Logger.Warn("Failed to send the alerts", zap.String("endpoint", n.endpoint()),
+					zap.Int("alerts", len(alerts)), zap.Error(err))
+			}
+		}
+	}
+}
+
+// evaluate returns the alerts that fire, resolve or should be resent at now.
+func (p *AlertProcessor) evaluate(now time.Time) []*Alert {
+	resendInterval := time.Duration(p.cfg.ResendInterval) * time.Second
+	var alerts []*Alert
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	for _, r := range p.rules {
+		for key, s := range r.series {
+			s.prune(now.Add(-time.Duration(r.rangeSecond) * time.Second))
+			if len(s.samples) == 0 {
+				delete(r.series, key)
+				continue
+			}
+			value, ok := r.expr.value(s.stats(), r.rangeSecond)
+			if !ok || !r.expr.compare(value) {
+				continue
+			}
+			state, ok := r.alerts[key]
+			if !ok {
+				state = &alertState{labels: r.alertLabels(s.labels), activeAt: now}
+				r.alerts[key] = state
+			}
+			state.value = value
+			if state.firedAt.IsZero() {
+				if now.Sub(state.activeAt) >= r.forDuration {
+					state.firedAt = now
+					state.lastSentAt = now
+					alerts = append(alerts, r.newAlert(state, FiringStatus, nil))
+				}
+			} else if resendInterval > 0 && now.Sub(state.lastSentAt) >= resendInterval {
+				state.lastSentAt = now
+				alerts = append(alerts, r.newAlert(state, FiringStatus, nil))
+			}
+		}
+		// The alerts whose expressions are no longer true are resolved, and the pending ones are dropped.
+		for key, state := range r.alerts {
+			if s, ok := r.series[key]; ok {
+				if value, ok := r.expr.value(s.stats(), r.rangeSecond); ok && r.expr.compare(value) {
+					continue
+				}
+			}
+			delete(r.alerts, key)
+			if !state.firedAt.IsZero() {
+				endsAt := now
+				alerts = append(alerts, r.newAlert(state, ResolvedStatus, &endsAt))
+			}
+		}
+	}
+	return alerts
+}
+
+func (r *rule) alertLabels(groupLabels map[string]string) map[string]string {
+	labels := make(map[string]string, len(r.labels)+len(groupLabels)+1)
+	for k, v := range r.labels {
+		labels[k] = v
+	}
+	for k, v := range groupLabels {
+		labels[k] = v
+	}
+	labels["alertname"] = r.name
+	return labels
+}
+
+func (r *rule) newAlert(state *alertState, status string, endsAt *time.Time) *Alert {
+	return &Alert{
+		Status:      status,
+		Labels:      state.labels,
+		Annotations: r.annotations,
+		Value:       state.value,
+		StartsAt:    state.firedAt,
+		EndsAt:      endsAt,
+	}
+}
+
+// prune removes the samples before the start of the range.
+func (s *series) prune(start time.Time) {
+	i := 0
+	for i < len(s.samples) && s.samples[i].time.Before(start) {
+		i++
+	}
+	s.samples = s.samples[i:]
+}
+
+func (s *series) stats() requestStats {
+	var stats requestStats
+	for _, sample := range s.samples {
+		stats.count += sample.stats.count
+		stats.errors += sample.stats.errors
+		stats.totalTime += sample.stats.totalTime
+	}
+	return stats
+}
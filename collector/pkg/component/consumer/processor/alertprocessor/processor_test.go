@@ -0,0 +1,163 @@
+package alertprocessor
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constvalues"
+)
+
+func newAggregatedDataGroup(dstWorkload string, isError bool, count int64) *model.DataGroup {
+	labels := model.NewAttributeMapWithValues(map[string]model.AttributeValue{
+		constlabels.SrcNamespace:    model.NewStringValue("default"),
+		constlabels.SrcWorkloadName: model.NewStringValue("frontend"),
+		constlabels.DstNamespace:    model.NewStringValue("default"),
+		constlabels.DstWorkloadName: model.NewStringValue(dstWorkload),
+		constlabels.Protocol:        model.NewStringValue("http"),
+		constlabels.IsError:         model.NewBoolValue(isError),
+	})
+	return model.NewDataGroup(constnames.AggregatedNetRequestMetricGroup, labels, 1_000_000_000,
+		model.NewIntMetric(constvalues.RequestCount, count),
+		model.NewIntMetric(constvalues.RequestTotalTime, count*10_000_000))
+}
+
+func newTestProcessor(resendInterval int) *AlertProcessor {
+	return &AlertProcessor{
+		cfg: &Config{ResendInterval: resendInterval},
+		rules: newRules([]RuleConfig{{
+			Name:        "HighErrorRate",
+			Expr:        `error_rate{dst_workload_name="cart"} > 0.05`,
+			Range:       60,
+			For:         120,
+			Labels:      map[string]string{"severity": "warning"},
+			Annotations: map[string]string{"summary": "Too many errors"},
+		}}, zap.NewNop()),
+	}
+}
+
+func TestAlertProcessor_evaluate(t *testing.T) {
+	p := newTestProcessor(60)
+	start := time.Unix(1700000000, 0)
+	// 10% of the requests to cart fail, and the requests to the other workloads are not matched.
+	feed := func(now time.Time) {
+		p.record(newAggregatedDataGroup("cart", false, 90), now)
+		p.record(newAggregatedDataGroup("cart", true, 10), now)
+		p.record(newAggregatedDataGroup("payment", true, 100), now)
+	}
+
+	var alerts []*Alert
+	for elapsed := time.Duration(0); elapsed < 2*time.Minute; elapsed += 15 * time.Second {
+		feed(start.Add(elapsed))
+		alerts = append(alerts, p.evaluate(start.Add(elapsed))...)
+	}
+	assert.Empty(t, alerts, "the alert is pending for 2m")
+
+	now := start.Add(2 * time.Minute)
+	feed(now)
+	alerts = p.evaluate(now)
+	require.Len(t, alerts, 1)
+	assert.Equal(t, FiringStatus, alerts[0].Status)
+	assert.Equal(t, map[string]string{
+		"alertname":                 "HighErrorRate",
+		"severity":                  "warning",
+		constlabels.SrcNamespace:    "default",
+		constlabels.SrcWorkloadName: "frontend",
+		constlabels.DstNamespace:    "default",
+		constlabels.DstWorkloadName: "cart",
+	}, alerts[0].Labels)
+	assert.InDelta(t, 0.1, alerts[0].Value, 1e-9)
+	assert.Equal(t, now, alerts[0].StartsAt)
+
+	now = now.Add(30 * time.Second)
+	feed(now)
+	assert.Empty(t, p.evaluate(now), "the alert is not resent before the resend interval")
+	now = now.Add(30 * time.Second)
+	feed(now)
+	alerts = p.evaluate(now)
+	require.Len(t, alerts, 1)
+	assert.Equal(t, FiringStatus, alerts[0].Status)
+
+	// The alert resolves once the errors are out of the range.
+	for i := 0; i < 5; i++ {
+		now = now.Add(15 * time.Second)
+		p.record(newAggregatedDataGroup("cart", false, 100), now)
+		alerts = p.evaluate(now)
+		if len(alerts) > 0 {
+			break
+		}
+	}
+	require.Len(t, alerts, 1)
+	assert.Equal(t, ResolvedStatus, alerts[0].Status)
+	if assert.NotNil(t, alerts[0].EndsAt) {
+		assert.Equal(t, now, *alerts[0].EndsAt)
+	}
+	assert.Empty(t, p.rules[0].alerts)
+}
+
+func TestAlertProcessor_evaluatePendingDropped(t *testing.T) {
+	p := newTestProcessor(0)
+	start := time.Unix(1700000000, 0)
+	p.record(newAggregatedDataGroup("cart", true, 10), start)
+	assert.Empty(t, p.evaluate(start))
+	assert.Len(t, p.rules[0].alerts, 1)
+	// No requests are left in the range, so the pending alert is dropped without any notification.
+	assert.Empty(t, p.evaluate(start.Add(2*time.Minute)))
+	assert.Empty(t, p.rules[0].alerts)
+	assert.Empty(t, p.rules[0].series)
+}
+
+func TestNotifiers(t *testing.T) {
+	var webhookMsg webhookMessage
+	var amAlerts []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/hook":
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&webhookMsg))
+		case "/api/v2/alerts":
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&amAlerts))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	notifiers := newNotifiers(&Config{
+		ResendInterval: 60,
+		Webhook:        &WebhookConfig{Url: server.URL + "/hook"},
+		Alertmanager:   &AlertmanagerConfig{Endpoint: server.URL + "/"},
+	})
+	require.Len(t, notifiers, 2)
+	alerts := []*Alert{{
+		Status:      FiringStatus,
+		Labels:      map[string]string{"alertname": "HighErrorRate"},
+		Annotations: map[string]string{"summary": "Too many errors"},
+		Value:       0.1,
+		StartsAt:    time.Unix(1700000000, 0),
+	}}
+	for _, n := range notifiers {
+		assert.NoError(t, n.notify(alerts), n.endpoint())
+	}
+
+	require.Len(t, webhookMsg.Alerts, 1)
+	assert.Equal(t, FiringStatus, webhookMsg.Alerts[0].Status)
+	assert.Equal(t, 0.1, webhookMsg.Alerts[0].Value)
+	assert.Nil(t, webhookMsg.Alerts[0].EndsAt)
+
+	require.Len(t, amAlerts, 1)
+	assert.Equal(t, map[string]interface{}{"alertname": "HighErrorRate"}, amAlerts[0]["labels"])
+	assert.Equal(t, map[string]interface{}{"summary": "Too many errors", "value": "0.1"}, amAlerts[0]["annotations"])
+	assert.NotEmpty(t, amAlerts[0]["endsAt"], "the firing alerts are valid until the next resends")
+
+	failing := &webhookNotifier{url: server.URL + "/missing", client: newHttpClient(1)}
+	assert.Error(t, failing.notify(alerts))
+}
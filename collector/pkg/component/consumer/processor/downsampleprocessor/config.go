@@ -0,0 +1,69 @@
+package downsampleprocessor
+
+import "github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+
+const (
+	SumKind  = "sum"
+	MaxKind  = "max"
+	LastKind = "last"
+	AvgKind  = "avg"
+)
+
+type Config struct {
+	Enable bool `mapstructure:"enable"`
+	// AggregationWindow is the name of the aggregateprocessor window whose results are downsampled, and the
+	// results of the other windows are passed through unchanged. The results of the default window, which
+	// has no name, are downsampled if it is empty.
+	AggregationWindow string `mapstructure:"aggregation_window"`
+	// MetricGroups are the names of the data groups downsampled, and the others are passed through unchanged.
+	MetricGroups []string `mapstructure:"metric_groups"`
+	// Resolutions are the intervals the series are downsampled to. Each series is emitted once per resolution
+	// at the end of each interval.
+	Resolutions []ResolutionConfig `mapstructure:"resolutions"`
+	// MetricKinds decide how the values of the int metrics are merged. The int metrics not listed are summed,
+	// and the histograms are always merged by their buckets.
+	MetricKinds map[string]MetricKindConfig `mapstructure:"metric_kinds"`
+}
+
+type ResolutionConfig struct {
+	// Name is added to the downsampled data as the label "aggregation_window", so the resolutions are
+	// emitted as separate series. An empty name keeps the label of the source window.
+	Name string `mapstructure:"name"`
+	// The unit is second. The intervals are aligned to the multiples of Interval since the Unix epoch,
+	// e.g. a 1m resolution is dumped at hh:mm:00.
+	Interval int `mapstructure:"interval"`
+}
+
+type MetricKindConfig struct {
+	// Valid values: ["sum", "max", "last", "avg"].
+	Kind string `mapstructure:"kind"`
+	// WeightedBy is the int metric of the same data group weighting the averages, e.g. "request_count" for
+	// the average latencies. The averages are weighted equally if it is empty.
+	WeightedBy string `mapstructure:"weighted_by"`
+}
+
+func NewDefaultConfig() *Config {
+	requestAvg := MetricKindConfig{Kind: AvgKind, WeightedBy: "request_count"}
+	return &Config{
+		Enable:       false,
+		MetricGroups: []string{constnames.AggregatedNetRequestMetricGroup},
+		Resolutions: []ResolutionConfig{
+			{Name: "1m", Interval: 60},
+			{Name: "5m", Interval: 300},
+		},
+		MetricKinds: map[string]MetricKindConfig{
+			"request_total_time_avg":                     requestAvg,
+			"connect_time_avg":                           requestAvg,
+			"request_sent_time_avg":                      requestAvg,
+			"waiting_ttfb_time_avg":                      requestAvg,
+			"content_download_time_avg":                  requestAvg,
+			"kindling_tcp_srtt_microseconds":             {Kind: LastKind},
+			"kindling_connection_reuse_ratio":            {Kind: LastKind},
+			"kindling_process_open_sockets":              {Kind: LastKind},
+			"kindling_server_queue_time_nanoseconds_max": {Kind: MaxKind},
+			"kindling_container_protocol_info":           {Kind: LastKind},
+			"kindling_process_handling_threads":          {Kind: LastKind},
+			"kindling_process_thread_saturation_ratio":   {Kind: LastKind},
+		},
+	}
+}
@@ -0,0 +1,112 @@
+package downsampleprocessor
+
+import "github.com/Kindling-project/kindling/collector/pkg/model"
+
+// mergedMetric is a metric merged from the data groups of a series in a resolution interval.
+type mergedMetric struct {
+	name string
+	kind MetricKindConfig
+	// value is the sum, the max or the last value, or the weighted sum of the averages.
+	value  int64
+	weight int64
+	// histogram is set if the metric is a histogram.
+	histogram *model.Histogram
+}
+
+// merge adds the metric of a data group to the series. weight is the value of the "weighted_by" metric
+// in the same data group, which is 1 if it is not configured.
+func (m *mergedMetric) merge(metric *model.Metric, weight int64) {
+	if histogram := metric.GetHistogram(); histogram != nil {
+		if m.histogram == nil {
+			// The buckets are copied, as the aggregator keeps reusing its own.
+			m.histogram = &model.Histogram{
+				Sum:                histogram.Sum,
+				Count:              histogram.Count,
+				ExplicitBoundaries: append([]int64(nil), histogram.ExplicitBoundaries...),
+				BucketCounts:       append([]uint64(nil), histogram.BucketCounts...),
+			}
+		} else {
+			mergeHistogram(m.histogram, histogram)
+		}
+		return
+	}
+	value := metric.GetInt()
+	if value == nil {
+		return
+	}
+	switch m.kind.Kind {
+	case MaxKind:
+		// The weight marks the first value, as the values could be negative.
+		if m.weight == 0 || value.Value > m.value {
+			m.value = value.Value
+		}
+		m.weight = 1
+	case LastKind:
+		m.value = value.Value
+	case AvgKind:
+		// The averages of no samples, e.g. no requests in the window, would drag the result down.
+		if weight > 0 {
+			m.value += value.Value * weight
+			m.weight += weight
+		}
+	default:
+		m.value += value.Value
+	}
+}
+
+func (m *mergedMetric) get() *model.Metric {
+	if m.histogram != nil {
+		return model.NewHistogramMetric(m.name, m.histogram)
+	}
+	if m.kind.Kind == AvgKind {
+		if m.weight == 0 {
+			return model.NewIntMetric(m.name, 0)
+		}
+		return model.NewIntMetric(m.name, m.value/m.weight)
+	}
+	return model.NewIntMetric(m.name, m.value)
+}
+
+// mergeHistogram adds the histogram to the target. The buckets are cumulative, i.e. each bucket counts the
+// values less than or equal to its boundary, so the buckets of the same boundaries are simply added. If the
+// boundaries differ, e.g. after the configuration is changed, the target keeps the boundaries of both and
+// drops the others, which keeps the counts exact instead of guessing how the values spread in a bucket.
+func mergeHistogram(target *model.Histogram, histogram *model.Histogram) {
+	target.Sum += histogram.Sum
+	target.Count += histogram.Count
+	if equalBoundaries(target.ExplicitBoundaries, histogram.ExplicitBoundaries) {
+		for i := range target.BucketCounts {
+			target.BucketCounts[i] += histogram.BucketCounts[i]
+		}
+		return
+	}
+	boundaries := make([]int64, 0, len(target.ExplicitBoundaries))
+	counts := make([]uint64, 0, len(target.BucketCounts))
+	for i, j := 0, 0; i < len(target.ExplicitBoundaries) && j < len(histogram.ExplicitBoundaries); {
+		switch {
+		case target.ExplicitBoundaries[i] < histogram.ExplicitBoundaries[j]:
+			i++
+		case target.ExplicitBoundaries[i] > histogram.ExplicitBoundaries[j]:
+			j++
+		default:
+			boundaries = append(boundaries, target.ExplicitBoundaries[i])
+			counts = append(counts, target.BucketCounts[i]+histogram.BucketCounts[j])
+			i++
+			j++
+		}
+	}
+	target.ExplicitBoundaries = boundaries
+	target.BucketCounts = counts
+}
+
+func equalBoundaries(a []int64, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,186 @@
+package downsampleprocessor
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/processor"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+const Type = "downsampleprocessor"
+
+// DownsampleProcessor merges the aggregated data groups of each series into coarser resolutions like 1m
+// and 5m, so the backends keeping only the coarse data receive fewer points. The sums are added, the
+// histograms are merged by their buckets and the averages are weighted, so the downsampled data equals
+// the data aggregated in the coarse windows directly.
+type DownsampleProcessor struct {
+	cfg          *Config
+	telemetry    *component.TelemetryTools
+	nextConsumer consumer.Consumer
+
+	metricGroups map[string]bool
+	resolutions  []*resolution
+	stopCh       chan struct{}
+}
+
+type resolution struct {
+	name     string
+	interval time.Duration
+	// mutex protects the series, which are replaced at the end of each interval.
+	mutex  sync.Mutex
+	series map[string]*series
+}
+
+// series is the data groups of the same name and labels merged in a resolution interval.
+type series struct {
+	name      string
+	labels    *model.AttributeMap
+	timestamp uint64
+	// metrics keeps the order of the metrics in the first data group.
+	metrics []*mergedMetric
+}
+
+func New(config interface{}, telemetry *component.TelemetryTools, nextConsumer consumer.Consumer) processor.Processor {
+	cfg := config.(*Config)
+	p := &DownsampleProcessor{
+		cfg:          cfg,
+		telemetry:    telemetry,
+		nextConsumer: nextConsumer,
+		metricGroups: make(map[string]bool, len(cfg.MetricGroups)),
+		stopCh:       make(chan struct{}),
+	}
+	if !cfg.Enable {
+		return p
+	}
+	for _, name := range cfg.MetricGroups {
+		p.metricGroups[name] = true
+	}
+	for _, resolutionConfig := range cfg.Resolutions {
+		if resolutionConfig.Interval <= 0 {
+			telemetry.Logger.Warn("The resolution without the interval is skipped", zap.String("name", resolutionConfig.Name))
+			continue
+		}
+		r := &resolution{
+			name:     resolutionConfig.Name,
+			interval: time.Duration(resolutionConfig.Interval) * time.Second,
+			series:   make(map[string]*series),
+		}
+		p.resolutions = append(p.resolutions, r)
+		go p.runTicker(r)
+	}
+	return p
+}
+
+func (p *DownsampleProcessor) Consume(dataGroup *model.DataGroup) error {
+	if len(p.resolutions) == 0 || !p.metricGroups[dataGroup.Name] ||
+		dataGroup.Labels.GetStringValue(constlabels.AggregationWindow) != p.cfg.AggregationWindow {
+		return p.nextConsumer.Consume(dataGroup)
+	}
+	key := dataGroup.Name + dataGroup.Labels.String()
+	for _, r := range p.resolutions {
+		r.merge(key, dataGroup, p.cfg.MetricKinds)
+	}
+	return nil
+}
+
+func (r *resolution) merge(key string, dataGroup *model.DataGroup, kinds map[string]MetricKindConfig) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	s, ok := r.series[key]
+	if !ok {
+		s = &series{
+			name:    dataGroup.Name,
+			labels:  dataGroup.Labels.Clone(),
+			metrics: make([]*mergedMetric, 0, len(dataGroup.Metrics)),
+		}
+		if r.name != "" {
+			s.labels.UpdateAddStringValue(constlabels.AggregationWindow, r.name)
+		}
+		r.series[key] = s
+	}
+	if dataGroup.Timestamp > s.timestamp {
+		s.timestamp = dataGroup.Timestamp
+	}
+	for _, metric := range dataGroup.Metrics {
+		merged := s.getMetric(metric.Name)
+		if merged == nil {
+			merged = &mergedMetric{name: metric.Name, kind: kinds[metric.Name]}
+			s.metrics = append(s.metrics, merged)
+		}
+		merged.merge(metric, getWeight(dataGroup, merged.kind))
+	}
+}
+
+// getWeight returns the value of the "weighted_by" metric in the data group, or 1 if it is not configured.
+func getWeight(dataGroup *model.DataGroup, kind MetricKindConfig) int64 {
+	if kind.Kind != AvgKind || kind.WeightedBy == "" {
+		return 1
+	}
+	metric, ok := dataGroup.GetMetric(kind.WeightedBy)
+	if !ok || metric.GetInt() == nil {
+		return 0
+	}
+	return metric.GetInt().Value
+}
+
+func (s *series) getMetric(name string) *mergedMetric {
+	for _, metric := range s.metrics {
+		if metric.name == name {
+			return metric
+		}
+	}
+	return nil
+}
+
+func (p *DownsampleProcessor) runTicker(r *resolution) {
+	// Wait until the next boundary so that the intervals are aligned to the wall clock.
+	now := time.Now()
+	select {
+	case <-p.stopCh:
+		return
+	case <-time.After(now.Truncate(r.interval).Add(r.interval).Sub(now)):
+		p.dump(r)
+	}
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stopCh:
+			return
+		case <-ticker.C:
+			p.dump(r)
+		}
+	}
+}
+
+func (p *DownsampleProcessor) dump(r *resolution) {
+	for _, dataGroup := range r.dump() {
+		if err := p.nextConsumer.Consume(dataGroup); err != nil {
+			p.telemetry.Logger.Warn("Error happened when consuming the downsampled data",
+				zap.String("resolution", r.name), zap.Error(err))
+		}
+	}
+}
+
+// dump returns the series merged in the interval and starts a new interval.
+func (r *resolution) dump() []*model.DataGroup {
+	r.mutex.Lock()
+	current := r.series
+	r.series = make(map[string]*series, len(current))
+	r.mutex.Unlock()
+	dataGroups := make([]*model.DataGroup, 0, len(current))
+	for _, s := range current {
+		metrics := make([]*model.Metric, 0, len(s.metrics))
+		for _, metric := range s.metrics {
+			metrics = append(metrics, metric.get())
+		}
+		dataGroups = append(dataGroups, model.NewDataGroup(s.name, s.labels, s.timestamp, metrics...))
+	}
+	return dataGroups
+}
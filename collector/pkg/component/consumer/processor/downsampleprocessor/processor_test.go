@@ -0,0 +1,135 @@
+package downsampleprocessor
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+)
+
+type recordingConsumer struct {
+	mutex      sync.Mutex
+	dataGroups []*model.DataGroup
+}
+
+func (c *recordingConsumer) Consume(dataGroup *model.DataGroup) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.dataGroups = append(c.dataGroups, dataGroup)
+	return nil
+}
+
+func newEdgeDataGroup(dstWorkload string, timestamp uint64, count int64, avg int64, buckets []uint64) *model.DataGroup {
+	labels := model.NewAttributeMapWithValues(map[string]model.AttributeValue{
+		constlabels.SrcWorkloadName: model.NewStringValue("frontend"),
+		constlabels.DstWorkloadName: model.NewStringValue(dstWorkload),
+		constlabels.Protocol:        model.NewStringValue("http"),
+	})
+	return model.NewDataGroup(constnames.AggregatedNetRequestMetricGroup, labels, timestamp,
+		model.NewIntMetric("request_count", count),
+		model.NewIntMetric("request_total_time", count*avg),
+		model.NewIntMetric("request_total_time_avg", avg),
+		model.NewIntMetric("kindling_server_queue_time_nanoseconds_max", avg*2),
+		model.NewIntMetric("kindling_process_open_sockets", count),
+		model.NewHistogramMetric("request_total_time_histogram", &model.Histogram{
+			Sum:                count * avg,
+			Count:              uint64(count),
+			ExplicitBoundaries: []int64{10, 100, 1000},
+			BucketCounts:       buckets,
+		}))
+}
+
+func newTestProcessor(next *recordingConsumer) *DownsampleProcessor {
+	cfg := NewDefaultConfig()
+	cfg.Enable = true
+	// The resolutions are dumped by the tests instead of the tickers.
+	p := New(cfg, component.NewDefaultTelemetryTools(), next).(*DownsampleProcessor)
+	close(p.stopCh)
+	return p
+}
+
+func getIntValue(t *testing.T, dataGroup *model.DataGroup, name string) int64 {
+	metric, ok := dataGroup.GetMetric(name)
+	require.True(t, ok, name)
+	return metric.GetInt().Value
+}
+
+func TestDownsampleProcessor_Consume(t *testing.T) {
+	next := &recordingConsumer{}
+	p := newTestProcessor(next)
+
+	assert.NoError(t, p.Consume(newEdgeDataGroup("cart", 1000, 10, 100, []uint64{1, 4, 10})))
+	assert.NoError(t, p.Consume(newEdgeDataGroup("cart", 3000, 30, 20, []uint64{10, 30, 30})))
+	// No requests in the window, whose average is not counted.
+	assert.NoError(t, p.Consume(newEdgeDataGroup("cart", 2000, 0, 0, []uint64{0, 0, 0})))
+	assert.NoError(t, p.Consume(newEdgeDataGroup("payment", 2000, 5, 50, []uint64{0, 5, 5})))
+	// The other data groups are passed through.
+	single := model.NewDataGroup(constnames.SingleNetRequestMetricGroup, model.NewAttributeMap(), 1000,
+		model.NewIntMetric("request_total_time", 100))
+	assert.NoError(t, p.Consume(single))
+	other := newEdgeDataGroup("cart", 1000, 1, 1, []uint64{1, 1, 1})
+	other.Labels.AddStringValue(constlabels.AggregationWindow, "15s")
+	assert.NoError(t, p.Consume(other))
+	assert.Equal(t, []*model.DataGroup{single, other}, next.dataGroups)
+
+	dataGroups := p.resolutions[0].dump()
+	require.Len(t, dataGroups, 2)
+	var cart *model.DataGroup
+	for _, dataGroup := range dataGroups {
+		assert.Equal(t, "1m", dataGroup.Labels.GetStringValue(constlabels.AggregationWindow))
+		if dataGroup.Labels.GetStringValue(constlabels.DstWorkloadName) == "cart" {
+			cart = dataGroup
+		}
+	}
+	require.NotNil(t, cart)
+	assert.Equal(t, uint64(3000), cart.Timestamp)
+	assert.Equal(t, int64(40), getIntValue(t, cart, "request_count"))
+	assert.Equal(t, int64(1600), getIntValue(t, cart, "request_total_time"))
+	// (10*100 + 30*20) / 40
+	assert.Equal(t, int64(40), getIntValue(t, cart, "request_total_time_avg"))
+	assert.Equal(t, int64(200), getIntValue(t, cart, "kindling_server_queue_time_nanoseconds_max"))
+	assert.Equal(t, int64(0), getIntValue(t, cart, "kindling_process_open_sockets"))
+	histogram, ok := cart.GetMetric("request_total_time_histogram")
+	require.True(t, ok)
+	assert.Equal(t, &model.Histogram{
+		Sum:                1600,
+		Count:              40,
+		ExplicitBoundaries: []int64{10, 100, 1000},
+		BucketCounts:       []uint64{11, 34, 40},
+	}, histogram.GetHistogram())
+
+	assert.Empty(t, p.resolutions[0].dump(), "a new interval is started")
+}
+
+func TestMergeHistogram(t *testing.T) {
+	target := &model.Histogram{Sum: 100, Count: 10, ExplicitBoundaries: []int64{10, 50, 100, 500}, BucketCounts: []uint64{2, 5, 8, 10}}
+	mergeHistogram(target, &model.Histogram{Sum: 50, Count: 5, ExplicitBoundaries: []int64{10, 100, 1000}, BucketCounts: []uint64{1, 4, 5}})
+	// Only the common boundaries are kept, whose cumulative counts are exact.
+	assert.Equal(t, &model.Histogram{Sum: 150, Count: 15, ExplicitBoundaries: []int64{10, 100}, BucketCounts: []uint64{3, 12}}, target)
+}
+
+func TestDownsampleProcessor_runTicker(t *testing.T) {
+	next := &recordingConsumer{}
+	p := &DownsampleProcessor{
+		cfg:          &Config{},
+		telemetry:    component.NewDefaultTelemetryTools(),
+		nextConsumer: next,
+		stopCh:       make(chan struct{}),
+	}
+	r := &resolution{name: "1s", interval: time.Second, series: make(map[string]*series)}
+	r.merge("key", newEdgeDataGroup("cart", 1000, 1, 1, []uint64{1, 1, 1}), nil)
+	go p.runTicker(r)
+	defer close(p.stopCh)
+	require.Eventually(t, func() bool {
+		next.mutex.Lock()
+		defer next.mutex.Unlock()
+		return len(next.dataGroups) == 1
+	}, 3*time.Second, 10*time.Millisecond)
+}
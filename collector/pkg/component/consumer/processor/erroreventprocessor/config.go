@@ -0,0 +1,86 @@
+package erroreventprocessor
+
+const (
+	FileExporter = "file"
+	LokiExporter = "loki"
+)
+
+type Config struct {
+	Enable bool `mapstructure:"enable"`
+	// QueueSize is the number of the error events buffered for the exporter. The events are dropped
+	// when the queue is full, so a slow exporter never blocks the metrics pipeline.
+	QueueSize int `mapstructure:"queue_size"`
+	// The unit is second. The buffered events are exported at least once every FlushInterval.
+	FlushInterval int `mapstructure:"flush_interval"`
+	// Rules decide which error events are kept. The first matched rule is applied, and the events
+	// matching none of the rules are dropped. All the error events are kept with the payloads if it is empty.
+	Rules []RuleConfig `mapstructure:"rules"`
+	// Exporter is where the error events are exported to. Valid values: ["file", "loki"].
+	Exporter string      `mapstructure:"exporter"`
+	File     *FileConfig `mapstructure:"file"`
+	Loki     *LokiConfig `mapstructure:"loki"`
+}
+
+type RuleConfig struct {
+	// Name is added to the events as "rule", so the retention could be configured per rule in Loki.
+	Name string `mapstructure:"name"`
+	// Protocols the rule applies to, e.g. ["http", "grpc"]. All the protocols are matched if it is empty.
+	Protocols []string `mapstructure:"protocols"`
+	// HttpStatusCodes the rule applies to, either the exact codes like "404" or the classes like "5xx".
+	// The events without the HTTP status code are not matched unless it is empty.
+	HttpStatusCodes []string `mapstructure:"http_status_codes"`
+	// SamplingRate is the percentage of the matched events that are kept, ranging from 0 to 100.
+	SamplingRate int `mapstructure:"sampling_rate"`
+	// KeepPayload keeps the request and response payloads in the events.
+	KeepPayload bool `mapstructure:"keep_payload"`
+}
+
+type FileConfig struct {
+	// Path is the file the events are written to, one JSON object per line.
+	Path string `mapstructure:"path"`
+	// The unit is MB. The file is rotated when it is larger than MaxSize.
+	MaxSize int `mapstructure:"max_size"`
+	// The unit is hour. The rotated files are removed after Retention, which is rounded up to days.
+	Retention int `mapstructure:"retention"`
+}
+
+type LokiConfig struct {
+	// Endpoint is the push API of Loki, e.g. "http://loki:3100/loki/api/v1/push".
+	Endpoint string `mapstructure:"endpoint"`
+	// Labels are the static labels added to all the streams, besides "rule" and "protocol".
+	Labels map[string]string `mapstructure:"labels"`
+	// The unit is second.
+	Timeout int `mapstructure:"timeout"`
+}
+
+func NewDefaultConfig() *Config {
+	return &Config{
+		Enable:        false,
+		QueueSize:     10000,
+		FlushInterval: 5,
+		Exporter:      FileExporter,
+		File: &FileConfig{
+			Path:      "/tmp/kindling/error_events.json",
+			MaxSize:   100,
+			Retention: 24,
+		},
+		Loki: &LokiConfig{
+			Labels:  map[string]string{"job": "kindling"},
+			Timeout: 5,
+		},
+	}
+}
+
+func (cfg *Config) getQueueSize() int {
+	if cfg.QueueSize > 0 {
+		return cfg.QueueSize
+	}
+	return 10000
+}
+
+func (cfg *Config) getFlushInterval() int {
+	if cfg.FlushInterval > 0 {
+		return cfg.FlushInterval
+	}
+	return 5
+}
@@ -0,0 +1,39 @@
+package erroreventprocessor
+
+import (
+	"time"
+
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+// ErrorEvent is a failed request kept by the rules. The data groups are reused by the analyzers,
+// so the labels and metrics are copied when the event is created.
+type ErrorEvent struct {
+	Timestamp time.Time         `json:"timestamp"`
+	Rule      string            `json:"rule"`
+	Protocol  string            `json:"protocol"`
+	Labels    map[string]string `json:"labels"`
+	Metrics   map[string]int64  `json:"metrics"`
+}
+
+func newErrorEvent(dataGroup *model.DataGroup, r *rule) *ErrorEvent {
+	labels := dataGroup.Labels.ToStringMap()
+	if !r.keepPayload {
+		delete(labels, constlabels.RequestPayload)
+		delete(labels, constlabels.ResponsePayload)
+	}
+	metrics := make(map[string]int64, len(dataGroup.Metrics))
+	for _, metric := range dataGroup.Metrics {
+		if metric.DataType() == model.IntMetricType {
+			metrics[metric.Name] = metric.GetInt().Value
+		}
+	}
+	return &ErrorEvent{
+		Timestamp: time.Unix(0, int64(dataGroup.Timestamp)),
+		Rule:      r.name,
+		Protocol:  dataGroup.Labels.GetStringValue(constlabels.Protocol),
+		Labels:    labels,
+		Metrics:   metrics,
+	}
+}
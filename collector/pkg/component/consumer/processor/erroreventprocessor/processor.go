@@ -0,0 +1,107 @@
+package erroreventprocessor
+
+import (
+	"math/rand"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/processor"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+)
+
+const Type = "erroreventprocessor"
+
+// maxBatchSize is the maximum number of the events exported at once.
+const maxBatchSize = 500
+
+// ErrorEventProcessor routes the failed requests into a separate pipeline, whose sampling and retention
+// are decoupled from the metrics. The events are exported asynchronously and all the data groups are
+// passed to the next consumer unchanged.
+type ErrorEventProcessor struct {
+	cfg          *Config
+	telemetry    *component.TelemetryTools
+	nextConsumer consumer.Consumer
+
+	rules   []*rule
+	writer  eventWriter
+	queue   chan *ErrorEvent
+	dropped int64
+	stopCh  chan struct{}
+}
+
+func New(config interface{}, telemetry *component.TelemetryTools, nextConsumer consumer.Consumer) processor.Processor {
+	cfg := config.(*Config)
+	p := &ErrorEventProcessor{
+		cfg:          cfg,
+		telemetry:    telemetry,
+		nextConsumer: nextConsumer,
+		rules:        newRules(cfg.Rules),
+		stopCh:       make(chan struct{}),
+	}
+	if !cfg.Enable {
+		return p
+	}
+	writer, err := newEventWriter(cfg)
+	if err != nil {
+		telemetry.Logger.Error("Failed to create the error event exporter, the error events are disabled", zap.Error(err))
+		return p
+	}
+	p.writer = writer
+	p.queue = make(chan *ErrorEvent, cfg.getQueueSize())
+	go p.run()
+	return p
+}
+
+func (p *ErrorEventProcessor) Consume(dataGroup *model.DataGroup) error {
+	if p.writer != nil && dataGroup.Name == constnames.NetRequestMetricGroupName &&
+		dataGroup.Labels.GetBoolValue(constlabels.IsError) {
+		if r := selectRule(p.rules, dataGroup.Labels, rand.Intn(100)); r != nil {
+			select {
+			case p.queue <- newErrorEvent(dataGroup, r):
+			default:
+				atomic.AddInt64(&p.dropped, 1)
+			}
+		}
+	}
+	return p.nextConsumer.Consume(dataGroup)
+}
+
+func (p *ErrorEventProcessor) run() {
+	ticker := time.NewTicker(time.Duration(p.cfg.getFlushInterval()) * time.Second)
+	defer ticker.Stop()
+	batch := make([]*ErrorEvent, 0, maxBatchSize)
+	for {
+		select {
+		case <-p.stopCh:
+			return
+		case event := <-p.queue:
+			batch = append(batch, event)
+			if len(batch) >= maxBatchSize {
+				batch = p.export(batch)
+			}
+		case <-ticker.C:
+			batch = p.export(batch)
+		}
+	}
+}
+
+// export writes the events and returns the emptied batch for reuse.
+func (p *ErrorEventProcessor) export(batch []*ErrorEvent) []*ErrorEvent {
+	if dropped := atomic.SwapInt64(&p.dropped, 0); dropped > 0 {
+		p.telemetry.Logger.Warn("The error event queue is full, some events are dropped", zap.Int64("dropped", dropped))
+	}
+	if len(batch) == 0 {
+		return batch
+	}
+	if err := p.writer.write(batch); err != nil {
+		p.telemetry.Logger.Warn("Failed to export the error events", zap.String("exporter", p.cfg.Exporter),
+			zap.Int("events", len(batch)), zap.Error(err))
+	}
+	return batch[:0]
+}
@@ -0,0 +1,91 @@
+package erroreventprocessor
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+const defaultRuleName = "errors"
+
+type rule struct {
+	name         string
+	protocols    map[string]bool
+	statusCodes  map[int64]bool
+	statusClass  map[int64]bool
+	samplingRate int
+	keepPayload  bool
+}
+
+// newRules compiles the rules. The invalid HTTP status codes are ignored.
+func newRules(configs []RuleConfig) []*rule {
+	if len(configs) == 0 {
+		return []*rule{{name: defaultRuleName, samplingRate: 100, keepPayload: true}}
+	}
+	rules := make([]*rule, 0, len(configs))
+	for i, cfg := range configs {
+		r := &rule{
+			name:         cfg.Name,
+			samplingRate: cfg.SamplingRate,
+			keepPayload:  cfg.KeepPayload,
+		}
+		if r.name == "" {
+			r.name = "rule" + strconv.Itoa(i)
+		}
+		if len(cfg.Protocols) > 0 {
+			r.protocols = make(map[string]bool, len(cfg.Protocols))
+			for _, protocol := range cfg.Protocols {
+				r.protocols[strings.ToLower(protocol)] = true
+			}
+		}
+		if len(cfg.HttpStatusCodes) > 0 {
+			r.statusCodes = make(map[int64]bool)
+			r.statusClass = make(map[int64]bool)
+			for _, code := range cfg.HttpStatusCodes {
+				code = strings.ToLower(code)
+				if len(code) == 3 && strings.HasSuffix(code, "xx") {
+					if class, err := strconv.ParseInt(code[:1], 10, 64); err == nil {
+						r.statusClass[class] = true
+					}
+				} else if value, err := strconv.ParseInt(code, 10, 64); err == nil {
+					r.statusCodes[value] = true
+				}
+			}
+		}
+		rules = append(rules, r)
+	}
+	return rules
+}
+
+func (r *rule) match(labels *model.AttributeMap) bool {
+	if r.protocols != nil && !r.protocols[labels.GetStringValue(constlabels.Protocol)] {
+		return false
+	}
+	if r.statusCodes != nil {
+		if !labels.HasAttribute(constlabels.HttpStatusCode) {
+			return false
+		}
+		code := labels.GetIntValue(constlabels.HttpStatusCode)
+		if !r.statusCodes[code] && !r.statusClass[code/100] {
+			return false
+		}
+	}
+	return true
+}
+
+// selectRule returns the first rule matching the labels, or nil if the event is dropped because
+// no rule matches or it is not sampled. The randSeed ranges from 0 to 99.
+func selectRule(rules []*rule, labels *model.AttributeMap, randSeed int) *rule {
+	for _, r := range rules {
+		if !r.match(labels) {
+			continue
+		}
+		if randSeed < r.samplingRate {
+			return r
+		}
+		return nil
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package erroreventprocessor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constvalues"
+)
+
+func newErrorDataGroup(protocol string, statusCode int64) *model.DataGroup {
+	labels := model.NewAttributeMapWithValues(map[string]model.AttributeValue{
+		constlabels.Protocol:        model.NewStringValue(protocol),
+		constlabels.IsError:         model.NewBoolValue(true),
+		constlabels.RequestPayload:  model.NewStringValue("GET /api"),
+		constlabels.ResponsePayload: model.NewStringValue("HTTP/1.1 500"),
+	})
+	if statusCode != 0 {
+		labels.AddIntValue(constlabels.HttpStatusCode, statusCode)
+	}
+	return model.NewDataGroup(constnames.NetRequestMetricGroupName, labels, 1_000_000_000,
+		model.NewIntMetric(constvalues.RequestTotalTime, 5_000_000))
+}
+
+func TestSelectRule(t *testing.T) {
+	rules := newRules([]RuleConfig{
+		{Name: "server_errors", Protocols: []string{"HTTP"}, HttpStatusCodes: []string{"5xx", "429"}, SamplingRate: 100, KeepPayload: true},
+		{Name: "http_sampled", Protocols: []string{"http"}, SamplingRate: 10},
+		{Protocols: []string{"mysql"}, SamplingRate: 0},
+		{SamplingRate: 50},
+	})
+	tests := []struct {
+		name       string
+		protocol   string
+		statusCode int64
+		randSeed   int
+		want       string
+	}{
+		{name: "5xx", protocol: "http", statusCode: 503, randSeed: 99, want: "server_errors"},
+		{name: "exact code", protocol: "http", statusCode: 429, randSeed: 99, want: "server_errors"},
+		{name: "4xx sampled", protocol: "http", statusCode: 404, randSeed: 5, want: "http_sampled"},
+		{name: "4xx not sampled", protocol: "http", statusCode: 404, randSeed: 10},
+		{name: "first matched rule only", protocol: "mysql", randSeed: 0},
+		{name: "fallback", protocol: "redis", randSeed: 49, want: "rule3"},
+		{name: "fallback not sampled", protocol: "redis", randSeed: 50},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := selectRule(rules, newErrorDataGroup(tt.protocol, tt.statusCode).Labels, tt.randSeed)
+			if tt.want == "" {
+				assert.Nil(t, r)
+				return
+			}
+			if assert.NotNil(t, r) {
+				assert.Equal(t, tt.want, r.name)
+			}
+		})
+	}
+}
+
+func TestNewErrorEvent(t *testing.T) {
+	rules := newRules(nil)
+	dataGroup := newErrorDataGroup("http", 500)
+	event := newErrorEvent(dataGroup, selectRule(rules, dataGroup.Labels, 99))
+	assert.Equal(t, defaultRuleName, event.Rule)
+	assert.Equal(t, "http", event.Protocol)
+	assert.Equal(t, int64(1_000_000_000), event.Timestamp.UnixNano())
+	assert.Equal(t, "GET /api", event.Labels[constlabels.RequestPayload])
+	assert.Equal(t, "500", event.Labels[constlabels.HttpStatusCode])
+	assert.Equal(t, map[string]int64{constvalues.RequestTotalTime: 5_000_000}, event.Metrics)
+
+	// The event is not changed when the data group is reused.
+	dataGroup.Labels.UpdateAddStringValue(constlabels.Protocol, "mysql")
+	assert.Equal(t, "http", event.Labels[constlabels.Protocol])
+
+	event = newErrorEvent(dataGroup, &rule{name: "no_payload"})
+	assert.NotContains(t, event.Labels, constlabels.RequestPayload)
+	assert.NotContains(t, event.Labels, constlabels.ResponsePayload)
+}
@@ -0,0 +1,149 @@
+package erroreventprocessor
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+type eventWriter interface {
+	write(events []*ErrorEvent) error
+}
+
+func newEventWriter(cfg *Config) (eventWriter, error) {
+	switch cfg.Exporter {
+	case FileExporter, "":
+		return newFileWriter(cfg.File)
+	case LokiExporter:
+		return newLokiWriter(cfg.Loki)
+	default:
+		return nil, fmt.Errorf("unsupported error event exporter: %s", cfg.Exporter)
+	}
+}
+
+// fileWriter writes one JSON object per line for each event.
+type fileWriter struct {
+	out io.Writer
+}
+
+func newFileWriter(cfg *FileConfig) (*fileWriter, error) {
+	if cfg == nil || cfg.Path == "" {
+		return nil, fmt.Errorf("the path of the error event file is not set")
+	}
+	return &fileWriter{out: &lumberjack.Logger{
+		Filename: cfg.Path,
+		MaxSize:  cfg.MaxSize,
+		MaxAge:   retentionDays(cfg.Retention),
+	}}, nil
+}
+
+// retentionDays rounds the hours up to days, as lumberjack removes the files by days.
+func retentionDays(hours int) int {
+	if hours <= 0 {
+		return 0
+	}
+	return (hours + 23) / 24
+}
+
+func (w *fileWriter) write(events []*ErrorEvent) error {
+	buf := bufio.NewWriter(w.out)
+	encoder := json.NewEncoder(buf)
+	for _, event := range events {
+		if err := encoder.Encode(event); err != nil {
+			return err
+		}
+	}
+	return buf.Flush()
+}
+
+// lokiWriter pushes the events to Loki. The events are grouped into streams by the rule and
+// the protocol, which keeps the number of the streams small.
+type lokiWriter struct {
+	endpoint string
+	labels   map[string]string
+	client   *http.Client
+}
+
+type lokiPushRequest struct {
+	Streams []*lokiStream `json:"streams"`
+}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	// Values are the pairs of the timestamp in nanoseconds and the log line.
+	Values [][2]string `json:"values"`
+}
+
+func newLokiWriter(cfg *LokiConfig) (*lokiWriter, error) {
+	if cfg == nil || cfg.Endpoint == "" {
+		return nil, fmt.Errorf("the endpoint of Loki is not set")
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = 5
+	}
+	return &lokiWriter{
+		endpoint: cfg.Endpoint,
+		labels:   cfg.Labels,
+		client:   &http.Client{Timeout: time.Duration(timeout) * time.Second},
+	}, nil
+}
+
+func (w *lokiWriter) write(events []*ErrorEvent) error {
+	body, err := json.Marshal(w.buildRequest(events))
+	if err != nil {
+		return err
+	}
+	resp, err := w.client.Post(w.endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("loki responded with status %s", resp.Status)
+	}
+	return nil
+}
+
+func (w *lokiWriter) buildRequest(events []*ErrorEvent) *lokiPushRequest {
+	streams := make(map[[2]string]*lokiStream)
+	for _, event := range events {
+		line, err := json.Marshal(event)
+		if err != nil {
+			continue
+		}
+		key := [2]string{event.Rule, event.Protocol}
+		stream, ok := streams[key]
+		if !ok {
+			labels := make(map[string]string, len(w.labels)+2)
+			for k, v := range w.labels {
+				labels[k] = v
+			}
+			labels["rule"] = event.Rule
+			labels["protocol"] = event.Protocol
+			stream = &lokiStream{Stream: labels}
+			streams[key] = stream
+		}
+		stream.Values = append(stream.Values, [2]string{strconv.FormatInt(event.Timestamp.UnixNano(), 10), string(line)})
+	}
+	request := &lokiPushRequest{Streams: make([]*lokiStream, 0, len(streams))}
+	for _, stream := range streams {
+		request.Streams = append(request.Streams, stream)
+	}
+	sort.Slice(request.Streams, func(i, j int) bool {
+		if request.Streams[i].Stream["rule"] != request.Streams[j].Stream["rule"] {
+			return request.Streams[i].Stream["rule"] < request.Streams[j].Stream["rule"]
+		}
+		return request.Streams[i].Stream["protocol"] < request.Streams[j].Stream["protocol"]
+	})
+	return request
+}
@@ -0,0 +1,59 @@
+package erroreventprocessor
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLokiWriter(t *testing.T) {
+	var request lokiPushRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	writer, err := newLokiWriter(&LokiConfig{Endpoint: server.URL, Labels: map[string]string{"job": "kindling"}})
+	assert.NoError(t, err)
+	events := []*ErrorEvent{
+		{Timestamp: time.Unix(0, 3), Rule: "server_errors", Protocol: "http"},
+		{Timestamp: time.Unix(0, 1), Rule: "errors", Protocol: "mysql"},
+		{Timestamp: time.Unix(0, 2), Rule: "server_errors", Protocol: "http"},
+	}
+	assert.NoError(t, writer.write(events))
+
+	if assert.Len(t, request.Streams, 2) {
+		assert.Equal(t, map[string]string{"job": "kindling", "rule": "errors", "protocol": "mysql"}, request.Streams[0].Stream)
+		assert.Equal(t, map[string]string{"job": "kindling", "rule": "server_errors", "protocol": "http"}, request.Streams[1].Stream)
+		if assert.Len(t, request.Streams[1].Values, 2) {
+			assert.Equal(t, "3", request.Streams[1].Values[0][0])
+			assert.Equal(t, "2", request.Streams[1].Values[1][0])
+		}
+	}
+	// The static labels are not changed by the streams.
+	assert.Equal(t, map[string]string{"job": "kindling"}, writer.labels)
+}
+
+func TestLokiWriterError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	writer, err := newLokiWriter(&LokiConfig{Endpoint: server.URL})
+	assert.NoError(t, err)
+	assert.Error(t, writer.write([]*ErrorEvent{{Rule: "errors"}}))
+}
+
+func TestRetentionDays(t *testing.T) {
+	assert.Equal(t, 0, retentionDays(0))
+	assert.Equal(t, 1, retentionDays(1))
+	assert.Equal(t, 1, retentionDays(24))
+	assert.Equal(t, 2, retentionDays(25))
+}
@@ -0,0 +1,52 @@
+package flowlogprocessor
+
+const (
+	JsonFormat  = "json"
+	IpfixFormat = "ipfix"
+)
+
+type Config struct {
+	Enable bool `mapstructure:"enable"`
+	// The unit is second. The flow logs are aligned to the multiples of the interval since the Unix epoch.
+	Interval int `mapstructure:"interval"`
+	// Format is the format of the exported flow logs. Valid values: ["json", "ipfix"].
+	Format string       `mapstructure:"format"`
+	Json   *JsonConfig  `mapstructure:"json"`
+	Ipfix  *IpfixConfig `mapstructure:"ipfix"`
+}
+
+type JsonConfig struct {
+	// Path is the file the flow logs are written to, one JSON object per line.
+	// The flow logs are written to stdout if it is empty.
+	Path string `mapstructure:"path"`
+	// The unit is MB. The file is rotated when it is larger than MaxSize.
+	MaxSize    int `mapstructure:"max_size"`
+	MaxBackups int `mapstructure:"max_backups"`
+}
+
+type IpfixConfig struct {
+	// Endpoint is the address of the IPFIX collector, e.g. "10.10.10.10:4739". Only UDP is supported.
+	Endpoint            string `mapstructure:"endpoint"`
+	ObservationDomainId uint32 `mapstructure:"observation_domain_id"`
+}
+
+func NewDefaultConfig() *Config {
+	return &Config{
+		Enable:   false,
+		Interval: 60,
+		Format:   JsonFormat,
+		Json: &JsonConfig{
+			Path:       "/tmp/kindling/flowlog.json",
+			MaxSize:    100,
+			MaxBackups: 5,
+		},
+		Ipfix: &IpfixConfig{},
+	}
+}
+
+func (cfg *Config) getInterval() int {
+	if cfg.Interval > 0 {
+		return cfg.Interval
+	}
+	return 60
+}
@@ -0,0 +1,210 @@
+package flowlogprocessor
+
+import (
+	"sort"
+	"time"
+
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constvalues"
+)
+
+const (
+	FlagError      = "error"
+	FlagSlow       = "slow"
+	FlagRetransmit = "retransmit"
+	FlagDrop       = "drop"
+)
+
+// FlowLog is the traffic between two workloads in a time window. The client port is not kept, so
+// all the connections between the same addresses and server port are merged into one flow.
+type FlowLog struct {
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	// Role is the side where the flow is observed, "client" or "server".
+	Role     string `json:"role"`
+	Protocol string `json:"protocol"`
+
+	SrcIp           string `json:"src_ip"`
+	SrcNamespace    string `json:"src_namespace,omitempty"`
+	SrcWorkloadKind string `json:"src_workload_kind,omitempty"`
+	SrcWorkloadName string `json:"src_workload_name,omitempty"`
+	SrcPod          string `json:"src_pod,omitempty"`
+	DstIp           string `json:"dst_ip"`
+	DstPort         int64  `json:"dst_port"`
+	DnatIp          string `json:"dnat_ip,omitempty"`
+	DnatPort        int64  `json:"dnat_port,omitempty"`
+	DstNamespace    string `json:"dst_namespace,omitempty"`
+	DstWorkloadKind string `json:"dst_workload_kind,omitempty"`
+	DstWorkloadName string `json:"dst_workload_name,omitempty"`
+	DstPod          string `json:"dst_pod,omitempty"`
+	DstService      string `json:"dst_service,omitempty"`
+
+	Requests      int64 `json:"requests"`
+	Errors        int64 `json:"errors"`
+	RequestBytes  int64 `json:"request_bytes"`
+	ResponseBytes int64 `json:"response_bytes"`
+	// Packets is the number of the request and response messages, as the traffic is observed
+	// from the syscalls instead of the network interfaces.
+	Packets int64    `json:"packets"`
+	Flags   []string `json:"flags"`
+}
+
+type flowKey struct {
+	srcIp    string
+	dstIp    string
+	dstPort  int64
+	protocol string
+	isServer bool
+}
+
+// tupleKey identifies the TCP flows without the client port.
+type tupleKey struct {
+	srcIp   string
+	dstIp   string
+	dstPort int64
+}
+
+type flowTable struct {
+	flows map[flowKey]*FlowLog
+	// tcpFlags holds the flags of the TCP events, which are merged into the flows when dumped.
+	tcpFlags map[tupleKey]map[string]bool
+}
+
+func newFlowTable() *flowTable {
+	return &flowTable{
+		flows:    make(map[flowKey]*FlowLog),
+		tcpFlags: make(map[tupleKey]map[string]bool),
+	}
+}
+
+func (t *flowTable) record(dataGroup *model.DataGroup) {
+	switch dataGroup.Name {
+	case constnames.NetRequestMetricGroupName:
+		t.recordRequest(dataGroup)
+	case constnames.TcpRetransmitMetricGroupName:
+		t.recordTcpFlag(dataGroup.Labels, FlagRetransmit)
+	case constnames.TcpDropMetricGroupName:
+		t.recordTcpFlag(dataGroup.Labels, FlagDrop)
+	}
+}
+
+func (t *flowTable) recordRequest(dataGroup *model.DataGroup) {
+	labels := dataGroup.Labels
+	key := flowKey{
+		srcIp:    labels.GetStringValue(constlabels.SrcIp),
+		dstIp:    labels.GetStringValue(constlabels.DstIp),
+		dstPort:  labels.GetIntValue(constlabels.DstPort),
+		protocol: labels.GetStringValue(constlabels.Protocol),
+		isServer: labels.GetBoolValue(constlabels.IsServer),
+	}
+	flow, ok := t.flows[key]
+	if !ok {
+		flow = newFlowLog(key, labels)
+		t.flows[key] = flow
+	}
+	flow.Requests++
+	flow.Packets++
+	if labels.GetBoolValue(constlabels.IsError) {
+		flow.Errors++
+		flow.addFlag(FlagError)
+	}
+	if labels.GetBoolValue(constlabels.IsSlow) {
+		flow.addFlag(FlagSlow)
+	}
+	if metric, ok := dataGroup.GetMetric(constvalues.RequestIo); ok {
+		flow.RequestBytes += metric.GetInt().Value
+	}
+	// The response is missing if the request timed out.
+	if metric, ok := dataGroup.GetMetric(constvalues.ResponseIo); ok && metric.GetInt().Value > 0 {
+		flow.ResponseBytes += metric.GetInt().Value
+		flow.Packets++
+	}
+}
+
+// recordTcpFlag records the flag for both directions, because the source of the TCP events is the
+// sender of the segment, which is the server when the response is retransmitted.
+func (t *flowTable) recordTcpFlag(labels *model.AttributeMap, flag string) {
+	srcIp := labels.GetStringValue(constlabels.SrcIp)
+	dstIp := labels.GetStringValue(constlabels.DstIp)
+	for _, key := range []tupleKey{
+		{srcIp: srcIp, dstIp: dstIp, dstPort: labels.GetIntValue(constlabels.DstPort)},
+		{srcIp: dstIp, dstIp: srcIp, dstPort: labels.GetIntValue(constlabels.SrcPort)},
+	} {
+		flags, ok := t.tcpFlags[key]
+		if !ok {
+			flags = make(map[string]bool)
+			t.tcpFlags[key] = flags
+		}
+		flags[flag] = true
+	}
+}
+
+// dump returns the flows in the window [start, end). The TCP flags without any matched flow are dropped.
+func (t *flowTable) dump(start time.Time, end time.Time) []*FlowLog {
+	ret := make([]*FlowLog, 0, len(t.flows))
+	for key, flow := range t.flows {
+		for flag := range t.tcpFlags[tupleKey{srcIp: key.srcIp, dstIp: key.dstIp, dstPort: key.dstPort}] {
+			flow.addFlag(flag)
+		}
+		sort.Strings(flow.Flags)
+		flow.StartTime = start
+		flow.EndTime = end
+		ret = append(ret, flow)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].SrcIp != ret[j].SrcIp {
+			return ret[i].SrcIp < ret[j].SrcIp
+		}
+		if ret[i].DstIp != ret[j].DstIp {
+			return ret[i].DstIp < ret[j].DstIp
+		}
+		if ret[i].DstPort != ret[j].DstPort {
+			return ret[i].DstPort < ret[j].DstPort
+		}
+		if ret[i].Protocol != ret[j].Protocol {
+			return ret[i].Protocol < ret[j].Protocol
+		}
+		return ret[i].Role < ret[j].Role
+	})
+	return ret
+}
+
+func newFlowLog(key flowKey, labels *model.AttributeMap) *FlowLog {
+	role := "client"
+	if key.isServer {
+		role = "server"
+	}
+	flow := &FlowLog{
+		Role:            role,
+		Protocol:        key.protocol,
+		SrcIp:           key.srcIp,
+		SrcNamespace:    labels.GetStringValue(constlabels.SrcNamespace),
+		SrcWorkloadKind: labels.GetStringValue(constlabels.SrcWorkloadKind),
+		SrcWorkloadName: labels.GetStringValue(constlabels.SrcWorkloadName),
+		SrcPod:          labels.GetStringValue(constlabels.SrcPod),
+		DstIp:           key.dstIp,
+		DstPort:         key.dstPort,
+		DstNamespace:    labels.GetStringValue(constlabels.DstNamespace),
+		DstWorkloadKind: labels.GetStringValue(constlabels.DstWorkloadKind),
+		DstWorkloadName: labels.GetStringValue(constlabels.DstWorkloadName),
+		DstPod:          labels.GetStringValue(constlabels.DstPod),
+		DstService:      labels.GetStringValue(constlabels.DstService),
+		Flags:           []string{},
+	}
+	if dnatIp := labels.GetStringValue(constlabels.DnatIp); dnatIp != "" {
+		flow.DnatIp = dnatIp
+		flow.DnatPort = labels.GetIntValue(constlabels.DnatPort)
+	}
+	return flow
+}
+
+func (f *FlowLog) addFlag(flag string) {
+	for _, existing := range f.Flags {
+		if existing == flag {
+			return
+		}
+	}
+	f.Flags = append(f.Flags, flag)
+}
@@ -0,0 +1,70 @@
+package flowlogprocessor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constvalues"
+)
+
+func newRequestDataGroup(srcPort int64, requestIo int64, responseIo int64, isError bool) *model.DataGroup {
+	labels := model.NewAttributeMapWithValues(map[string]model.AttributeValue{
+		constlabels.SrcIp:           model.NewStringValue("10.0.0.1"),
+		constlabels.SrcPort:         model.NewIntValue(srcPort),
+		constlabels.SrcNamespace:    model.NewStringValue("default"),
+		constlabels.SrcWorkloadKind: model.NewStringValue("deployment"),
+		constlabels.SrcWorkloadName: model.NewStringValue("frontend"),
+		constlabels.DstIp:           model.NewStringValue("10.96.0.10"),
+		constlabels.DstPort:         model.NewIntValue(8080),
+		constlabels.DnatIp:          model.NewStringValue("10.0.0.2"),
+		constlabels.DnatPort:        model.NewIntValue(80),
+		constlabels.DstWorkloadName: model.NewStringValue("backend"),
+		constlabels.Protocol:        model.NewStringValue("http"),
+		constlabels.IsServer:        model.NewBoolValue(false),
+		constlabels.IsError:         model.NewBoolValue(isError),
+	})
+	return model.NewDataGroup(constnames.NetRequestMetricGroupName, labels, 0,
+		model.NewIntMetric(constvalues.RequestIo, requestIo), model.NewIntMetric(constvalues.ResponseIo, responseIo))
+}
+
+func TestFlowTable(t *testing.T) {
+	table := newFlowTable()
+	table.record(newRequestDataGroup(40000, 100, 1000, false))
+	table.record(newRequestDataGroup(40001, 200, 0, true))
+	// The retransmission of the response is sent by the destination.
+	table.record(model.NewDataGroup(constnames.TcpRetransmitMetricGroupName, model.NewAttributeMapWithValues(map[string]model.AttributeValue{
+		constlabels.SrcIp:   model.NewStringValue("10.96.0.10"),
+		constlabels.SrcPort: model.NewIntValue(8080),
+		constlabels.DstIp:   model.NewStringValue("10.0.0.1"),
+		constlabels.DstPort: model.NewIntValue(40000),
+	}), 0, model.NewIntMetric(constnames.TcpRetransmitMetricName, 1)))
+
+	start := time.Unix(60, 0)
+	end := time.Unix(120, 0)
+	assert.Equal(t, []*FlowLog{{
+		StartTime:       start,
+		EndTime:         end,
+		Role:            "client",
+		Protocol:        "http",
+		SrcIp:           "10.0.0.1",
+		SrcNamespace:    "default",
+		SrcWorkloadKind: "deployment",
+		SrcWorkloadName: "frontend",
+		DstIp:           "10.96.0.10",
+		DstPort:         8080,
+		DnatIp:          "10.0.0.2",
+		DnatPort:        80,
+		DstWorkloadName: "backend",
+		Requests:        2,
+		Errors:          1,
+		RequestBytes:    300,
+		ResponseBytes:   1000,
+		Packets:         3,
+		Flags:           []string{FlagError, FlagRetransmit},
+	}}, table.dump(start, end))
+}
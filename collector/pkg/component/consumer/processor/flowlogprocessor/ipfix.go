@@ -0,0 +1,149 @@
+package flowlogprocessor
+
+import (
+	"encoding/binary"
+	"net"
+	"time"
+)
+
+const (
+	ipfixVersion      = 10
+	ipfixTemplateSet  = 2
+	ipfixTemplateId   = 256
+	ipfixHeaderLength = 16
+	ipfixSetHeader    = 4
+	// ipfixMaxMessage keeps the messages within the MTU of the common networks to avoid the IP fragments.
+	ipfixMaxMessage = 1400
+	ipfixVarLength  = 65535
+)
+
+// ipfixField is an Information Element of IANA used in the template.
+type ipfixField struct {
+	id     uint16
+	length uint16
+}
+
+var ipfixTemplate = []ipfixField{
+	{id: 152, length: 8},             // flowStartMilliseconds
+	{id: 153, length: 8},             // flowEndMilliseconds
+	{id: 8, length: 4},               // sourceIPv4Address
+	{id: 12, length: 4},              // destinationIPv4Address
+	{id: 11, length: 2},              // destinationTransportPort
+	{id: 226, length: 4},             // postNATDestinationIPv4Address
+	{id: 228, length: 2},             // postNAPTDestinationTransportPort
+	{id: 231, length: 8},             // initiatorOctets
+	{id: 232, length: 8},             // responderOctets
+	{id: 298, length: 8},             // initiatorPackets
+	{id: 299, length: 8},             // responderPackets
+	{id: 61, length: 1},              // flowDirection
+	{id: 96, length: ipfixVarLength}, // applicationName
+}
+
+// encodeIpfixMessages encodes the flows into IPFIX messages, each of which holds the template and as
+// many data records as fit in ipfixMaxMessage. The workloads are not exported as there are no standard
+// Information Elements for them, and the flows between IPv6 addresses are skipped.
+// The sequence number for the next message is returned.
+func encodeIpfixMessages(flows []*FlowLog, exportTime time.Time, sequenceNumber uint32, observationDomainId uint32) ([][]byte, uint32) {
+	templateSet := encodeIpfixTemplateSet()
+	messages := make([][]byte, 0)
+	var records [][]byte
+	size := ipfixHeaderLength + len(templateSet) + ipfixSetHeader
+	flush := func() {
+		if len(records) == 0 {
+			return
+		}
+		messages = append(messages, encodeIpfixMessage(templateSet, records, exportTime, sequenceNumber, observationDomainId, size))
+		sequenceNumber += uint32(len(records))
+		records = nil
+		size = ipfixHeaderLength + len(templateSet) + ipfixSetHeader
+	}
+	for _, flow := range flows {
+		record := encodeIpfixRecord(flow)
+		if record == nil {
+			continue
+		}
+		if size+len(record) > ipfixMaxMessage {
+			flush()
+		}
+		records = append(records, record)
+		size += len(record)
+	}
+	flush()
+	return messages, sequenceNumber
+}
+
+func encodeIpfixMessage(templateSet []byte, records [][]byte, exportTime time.Time, sequenceNumber uint32,
+	observationDomainId uint32, size int) []byte {
+	message := make([]byte, ipfixHeaderLength, size)
+	binary.BigEndian.PutUint16(message[0:], ipfixVersion)
+	binary.BigEndian.PutUint16(message[2:], uint16(size))
+	binary.BigEndian.PutUint32(message[4:], uint32(exportTime.Unix()))
+	binary.BigEndian.PutUint32(message[8:], sequenceNumber)
+	binary.BigEndian.PutUint32(message[12:], observationDomainId)
+	message = append(message, templateSet...)
+
+	dataSetLength := size - len(message)
+	message = binary.BigEndian.AppendUint16(message, ipfixTemplateId)
+	message = binary.BigEndian.AppendUint16(message, uint16(dataSetLength))
+	for _, record := range records {
+		message = append(message, record...)
+	}
+	return message
+}
+
+func encodeIpfixTemplateSet() []byte {
+	length := ipfixSetHeader + 4 + 4*len(ipfixTemplate)
+	set := make([]byte, 0, length)
+	set = binary.BigEndian.AppendUint16(set, ipfixTemplateSet)
+	set = binary.BigEndian.AppendUint16(set, uint16(length))
+	set = binary.BigEndian.AppendUint16(set, ipfixTemplateId)
+	set = binary.BigEndian.AppendUint16(set, uint16(len(ipfixTemplate)))
+	for _, field := range ipfixTemplate {
+		set = binary.BigEndian.AppendUint16(set, field.id)
+		set = binary.BigEndian.AppendUint16(set, field.length)
+	}
+	return set
+}
+
+// encodeIpfixRecord returns nil if the flow is not between IPv4 addresses.
+func encodeIpfixRecord(flow *FlowLog) []byte {
+	srcIp := net.ParseIP(flow.SrcIp).To4()
+	dstIp := net.ParseIP(flow.DstIp).To4()
+	if srcIp == nil || dstIp == nil {
+		return nil
+	}
+	dnatIp := net.IPv4zero.To4()
+	if ip := net.ParseIP(flow.DnatIp).To4(); ip != nil {
+		dnatIp = ip
+	}
+	var direction uint8 = 1 // egress
+	if flow.Role == "server" {
+		direction = 0 // ingress
+	}
+	record := make([]byte, 0, 64+len(flow.Protocol))
+	record = binary.BigEndian.AppendUint64(record, uint64(flow.StartTime.UnixMilli()))
+	record = binary.BigEndian.AppendUint64(record, uint64(flow.EndTime.UnixMilli()))
+	record = append(record, srcIp...)
+	record = append(record, dstIp...)
+	record = binary.BigEndian.AppendUint16(record, uint16(flow.DstPort))
+	record = append(record, dnatIp...)
+	record = binary.BigEndian.AppendUint16(record, uint16(flow.DnatPort))
+	record = binary.BigEndian.AppendUint64(record, uint64(flow.RequestBytes))
+	record = binary.BigEndian.AppendUint64(record, uint64(flow.ResponseBytes))
+	record = binary.BigEndian.AppendUint64(record, uint64(flow.Requests))
+	record = binary.BigEndian.AppendUint64(record, uint64(flow.Packets-flow.Requests))
+	record = append(record, direction)
+	return appendIpfixString(record, flow.Protocol)
+}
+
+// appendIpfixString appends the variable-length string, whose length is encoded in 1 byte,
+// or 3 bytes if it is not shorter than 255.
+func appendIpfixString(b []byte, s string) []byte {
+	if len(s) < 255 {
+		b = append(b, uint8(len(s)))
+	} else {
+		b = append(b, 255)
+		b = binary.BigEndian.AppendUint16(b, uint16(len(s)))
+	}
+	return append(b, s...)
+}
@@ -0,0 +1,64 @@
+package flowlogprocessor
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodeIpfixMessages(t *testing.T) {
+	flow := &FlowLog{
+		StartTime:     time.Unix(60, 0),
+		EndTime:       time.Unix(120, 0),
+		Role:          "server",
+		Protocol:      "http",
+		SrcIp:         "10.0.0.1",
+		DstIp:         "10.0.0.2",
+		DstPort:       8080,
+		Requests:      2,
+		RequestBytes:  300,
+		ResponseBytes: 1000,
+		Packets:       4,
+	}
+	ipv6Flow := &FlowLog{SrcIp: "fd00::1", DstIp: "fd00::2"}
+	flows := []*FlowLog{ipv6Flow}
+	for i := 0; i < 30; i++ {
+		flows = append(flows, flow)
+	}
+
+	messages, sequenceNumber := encodeIpfixMessages(flows, time.Unix(121, 0), 5, 7)
+	// Each record is 70 bytes, so 18 records fit in a message with the template.
+	assert.Len(t, messages, 2)
+	assert.Equal(t, uint32(35), sequenceNumber)
+
+	message := messages[0]
+	assert.Equal(t, uint16(ipfixVersion), binary.BigEndian.Uint16(message[0:]))
+	assert.Equal(t, len(message), int(binary.BigEndian.Uint16(message[2:])))
+	assert.LessOrEqual(t, len(message), ipfixMaxMessage)
+	assert.Equal(t, uint32(121), binary.BigEndian.Uint32(message[4:]))
+	assert.Equal(t, uint32(5), binary.BigEndian.Uint32(message[8:]))
+	assert.Equal(t, uint32(7), binary.BigEndian.Uint32(message[12:]))
+	assert.Equal(t, uint32(23), binary.BigEndian.Uint32(messages[1][8:]))
+
+	templateSet := message[ipfixHeaderLength:]
+	assert.Equal(t, uint16(ipfixTemplateSet), binary.BigEndian.Uint16(templateSet[0:]))
+	templateLength := int(binary.BigEndian.Uint16(templateSet[2:]))
+	dataSet := templateSet[templateLength:]
+	assert.Equal(t, uint16(ipfixTemplateId), binary.BigEndian.Uint16(dataSet[0:]))
+	assert.Equal(t, len(dataSet), int(binary.BigEndian.Uint16(dataSet[2:])))
+
+	record := dataSet[ipfixSetHeader:]
+	assert.Equal(t, uint64(60000), binary.BigEndian.Uint64(record[0:]))
+	assert.Equal(t, uint64(120000), binary.BigEndian.Uint64(record[8:]))
+	assert.Equal(t, []byte{10, 0, 0, 1}, record[16:20])
+	assert.Equal(t, []byte{10, 0, 0, 2}, record[20:24])
+	assert.Equal(t, uint16(8080), binary.BigEndian.Uint16(record[24:]))
+	assert.Equal(t, uint64(300), binary.BigEndian.Uint64(record[32:]))
+	assert.Equal(t, uint64(1000), binary.BigEndian.Uint64(record[40:]))
+	assert.Equal(t, uint64(2), binary.BigEndian.Uint64(record[48:]))
+	assert.Equal(t, uint64(2), binary.BigEndian.Uint64(record[56:]))
+	assert.Equal(t, uint8(0), record[64])
+	assert.Equal(t, []byte("\x04http"), record[65:70])
+}
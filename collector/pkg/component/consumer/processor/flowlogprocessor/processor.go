@@ -0,0 +1,92 @@
+package flowlogprocessor
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/processor"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+)
+
+const Type = "flowlogprocessor"
+
+// FlowLogProcessor aggregates the observed traffic into the flow logs of a time window and exports them
+// in the style of NetFlow/IPFIX. All the data groups are passed to the next consumer unchanged.
+type FlowLogProcessor struct {
+	cfg          *Config
+	telemetry    *component.TelemetryTools
+	nextConsumer consumer.Consumer
+
+	interval time.Duration
+	writer   flowWriter
+	mutex    sync.Mutex
+	table    *flowTable
+	stopCh   chan struct{}
+}
+
+func New(config interface{}, telemetry *component.TelemetryTools, nextConsumer consumer.Consumer) processor.Processor {
+	cfg := config.(*Config)
+	p := &FlowLogProcessor{
+		cfg:          cfg,
+		telemetry:    telemetry,
+		nextConsumer: nextConsumer,
+		interval:     time.Duration(cfg.getInterval()) * time.Second,
+		table:        newFlowTable(),
+		stopCh:       make(chan struct{}),
+	}
+	if !cfg.Enable {
+		return p
+	}
+	writer, err := newFlowWriter(cfg)
+	if err != nil {
+		telemetry.Logger.Error("Failed to create the flow log writer, the flow logs are disabled", zap.Error(err))
+		return p
+	}
+	p.writer = writer
+	go p.runTicker()
+	return p
+}
+
+func (p *FlowLogProcessor) Consume(dataGroup *model.DataGroup) error {
+	if p.writer != nil {
+		p.mutex.Lock()
+		p.table.record(dataGroup)
+		p.mutex.Unlock()
+	}
+	return p.nextConsumer.Consume(dataGroup)
+}
+
+// runTicker dumps the flow logs at the multiples of the interval since the Unix epoch.
+func (p *FlowLogProcessor) runTicker() {
+	start := time.Now()
+	for {
+		end := start.Truncate(p.interval).Add(p.interval)
+		select {
+		case <-p.stopCh:
+			return
+		case <-time.After(time.Until(end)):
+			p.dump(start, end)
+			start = end
+		}
+	}
+}
+
+func (p *FlowLogProcessor) dump(start time.Time, end time.Time) {
+	p.mutex.Lock()
+	table := p.table
+	p.table = newFlowTable()
+	p.mutex.Unlock()
+
+	flows := table.dump(start, end)
+	if len(flows) == 0 {
+		return
+	}
+	if err := p.writer.write(flows, time.Now()); err != nil {
+		p.telemetry.Logger.Warn("Failed to export the flow logs", zap.String("format", p.cfg.Format),
+			zap.Int("flows", len(flows)), zap.Error(err))
+	}
+}
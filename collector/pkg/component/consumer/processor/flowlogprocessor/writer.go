@@ -0,0 +1,85 @@
+package flowlogprocessor
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"time"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+type flowWriter interface {
+	write(flows []*FlowLog, exportTime time.Time) error
+}
+
+func newFlowWriter(cfg *Config) (flowWriter, error) {
+	switch cfg.Format {
+	case JsonFormat, "":
+		return newJsonWriter(cfg.Json), nil
+	case IpfixFormat:
+		return newIpfixWriter(cfg.Ipfix)
+	default:
+		return nil, fmt.Errorf("unsupported flow log format: %s", cfg.Format)
+	}
+}
+
+// jsonWriter writes one JSON object per line for each flow.
+type jsonWriter struct {
+	out io.Writer
+}
+
+func newJsonWriter(cfg *JsonConfig) *jsonWriter {
+	if cfg == nil || cfg.Path == "" {
+		return &jsonWriter{out: os.Stdout}
+	}
+	return &jsonWriter{out: &lumberjack.Logger{
+		Filename:   cfg.Path,
+		MaxSize:    cfg.MaxSize,
+		MaxBackups: cfg.MaxBackups,
+	}}
+}
+
+func (w *jsonWriter) write(flows []*FlowLog, _ time.Time) error {
+	buf := bufio.NewWriter(w.out)
+	encoder := json.NewEncoder(buf)
+	for _, flow := range flows {
+		if err := encoder.Encode(flow); err != nil {
+			return err
+		}
+	}
+	return buf.Flush()
+}
+
+// ipfixWriter sends the flows to an IPFIX collector over UDP. The template is sent in every
+// message, as required by RFC 7011 for UDP where the collector may restart at any time.
+type ipfixWriter struct {
+	conn                net.Conn
+	observationDomainId uint32
+	sequenceNumber      uint32
+}
+
+func newIpfixWriter(cfg *IpfixConfig) (*ipfixWriter, error) {
+	if cfg == nil || cfg.Endpoint == "" {
+		return nil, fmt.Errorf("the endpoint of the IPFIX collector is not set")
+	}
+	conn, err := net.Dial("udp", cfg.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the IPFIX collector %s: %w", cfg.Endpoint, err)
+	}
+	return &ipfixWriter{conn: conn, observationDomainId: cfg.ObservationDomainId}, nil
+}
+
+func (w *ipfixWriter) write(flows []*FlowLog, exportTime time.Time) error {
+	messages, sequenceNumber := encodeIpfixMessages(flows, exportTime, w.sequenceNumber, w.observationDomainId)
+	w.sequenceNumber = sequenceNumber
+	for _, message := range messages {
+		if _, err := w.conn.Write(message); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+package hubbleprocessor
+
+type Config struct {
+	Enable bool `mapstructure:"enable"`
+	// ListenAddress is the address serving the Observer API of Hubble Relay.
+	ListenAddress string `mapstructure:"listen_address"`
+	// MaxFlows is the number of the latest flows kept for the clients.
+	MaxFlows int `mapstructure:"max_flows"`
+}
+
+func NewDefaultConfig() *Config {
+	return &Config{
+		Enable:        false,
+		ListenAddress: ":4245",
+		MaxFlows:      4095,
+	}
+}
@@ -0,0 +1,165 @@
+package hubbleprocessor
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/Kindling-project/kindling/collector/pkg/hubble"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constvalues"
+)
+
+// worldLabel is the label of Cilium given to the endpoints outside the cluster.
+const worldLabel = "reserved:world"
+
+// kafkaApiKeys are the names of the Kafka APIs used by Hubble.
+var kafkaApiKeys = map[int64]string{
+	0: "produce",
+	1: "fetch",
+	2: "offsets",
+	3: "metadata",
+}
+
+// newFlow converts the request to a flow of Hubble. A flow is the response of the request, so it goes from
+// the server to the client, which is marked by is_reply. The requests of HTTP, DNS and Kafka are L7 flows,
+// and the other ones are L3_L4 flows as Hubble has no records for them.
+func newFlow(dataGroup *model.DataGroup, localNodeName string) (*hubble.Flow, bool) {
+	if dataGroup.Name != constnames.NetRequestMetricGroupName {
+		return nil, false
+	}
+	labels := dataGroup.Labels
+	isServer := labels.GetBoolValue(constlabels.IsServer)
+	var latency uint64
+	if metric, ok := dataGroup.GetMetric(constvalues.RequestTotalTime); ok && metric.GetInt().Value > 0 {
+		latency = uint64(metric.GetInt().Value)
+	}
+	endTime := uint64(labels.GetIntValue(constlabels.EndTimestamp))
+	if endTime == 0 {
+		endTime = dataGroup.Timestamp + latency
+	}
+	flow := &hubble.Flow{
+		Time:        hubble.NewTimestamp(endTime),
+		Verdict:     hubble.Verdict_FORWARDED,
+		IP:          newIp(labels.GetStringValue(constlabels.DstIp), labels.GetStringValue(constlabels.SrcIp)),
+		Source:      newEndpoint(labels, constlabels.DstNamespace, constlabels.DstPod, constlabels.DstWorkloadKind, constlabels.DstWorkloadName),
+		Destination: newEndpoint(labels, constlabels.SrcNamespace, constlabels.SrcPod, constlabels.SrcWorkloadKind, constlabels.SrcWorkloadName),
+		Type:        hubble.FlowType_L3_L4,
+		NodeName:    getNodeName(labels, isServer, localNodeName),
+		IsReply:     &hubble.BoolValue{Value: true},
+	}
+	if service := labels.GetStringValue(constlabels.DstService); service != "" {
+		flow.SourceService = &hubble.Service{Name: service, Namespace: flow.Source.Namespace}
+	}
+	if service := labels.GetStringValue(constlabels.SrcService); service != "" {
+		flow.DestinationService = &hubble.Service{Name: service, Namespace: flow.Destination.Namespace}
+	}
+	if isServer {
+		flow.TrafficDirection = hubble.TrafficDirection_INGRESS
+	} else {
+		flow.TrafficDirection = hubble.TrafficDirection_EGRESS
+	}
+
+	serverPort := uint32(labels.GetIntValue(constlabels.DstPort))
+	clientPort := uint32(labels.GetIntValue(constlabels.SrcPort))
+	protocol := labels.GetStringValue(constlabels.Protocol)
+	if protocol == constvalues.ProtocolDns {
+		flow.L4 = &hubble.Layer4{UDP: &hubble.UDP{SourcePort: serverPort, DestinationPort: clientPort}}
+	} else {
+		flow.L4 = &hubble.Layer4{TCP: &hubble.TCP{SourcePort: serverPort, DestinationPort: clientPort}}
+	}
+	if l7 := newLayer7(labels, protocol); l7 != nil {
+		l7.LatencyNs = latency
+		flow.L7 = l7
+		flow.Type = hubble.FlowType_L7
+	}
+	return flow, true
+}
+
+func newIp(source string, destination string) *hubble.IP {
+	ip := &hubble.IP{Source: source, Destination: destination, IpVersion: hubble.IPVersion_IPv4}
+	if strings.Contains(source, ":") {
+		ip.IpVersion = hubble.IPVersion_IPv6
+	}
+	return ip
+}
+
+func newEndpoint(labels *model.AttributeMap, namespaceKey string, podKey string, workloadKindKey string, workloadNameKey string) *hubble.Endpoint {
+	namespace := labels.GetStringValue(namespaceKey)
+	if namespace == constlabels.ExternalClusterNamespace {
+		return &hubble.Endpoint{Labels: []string{worldLabel}}
+	}
+	if constlabels.IsNamespaceNotFound(namespace) {
+		return &hubble.Endpoint{}
+	}
+	endpoint := &hubble.Endpoint{
+		Namespace: namespace,
+		PodName:   labels.GetStringValue(podKey),
+	}
+	if namespace != "" {
+		endpoint.Labels = []string{"k8s:io.kubernetes.pod.namespace=" + namespace}
+	}
+	if workloadName := labels.GetStringValue(workloadNameKey); workloadName != "" {
+		endpoint.Workloads = []*hubble.Workload{{Name: workloadName, Kind: labels.GetStringValue(workloadKindKey)}}
+	}
+	return endpoint
+}
+
+// getNodeName returns the node where the request is observed.
+func getNodeName(labels *model.AttributeMap, isServer bool, localNodeName string) string {
+	var nodeName string
+	if isServer {
+		nodeName = labels.GetStringValue(constlabels.DstNode)
+	} else {
+		nodeName = labels.GetStringValue(constlabels.SrcNode)
+	}
+	if nodeName == "" {
+		return localNodeName
+	}
+	return nodeName
+}
+
+func newLayer7(labels *model.AttributeMap, protocol string) *hubble.Layer7 {
+	l7 := &hubble.Layer7{Type: hubble.L7FlowType_RESPONSE}
+	switch protocol {
+	case constvalues.ProtocolHttp, constvalues.ProtocolHttp2:
+		version := labels.GetStringValue(constlabels.ProtocolVersion)
+		if version == "" {
+			version = "1.1"
+		}
+		l7.Http = &hubble.HTTP{
+			Code:     uint32(labels.GetIntValue(constlabels.HttpStatusCode)),
+			Method:   labels.GetStringValue(constlabels.HttpMethod),
+			Url:      labels.GetStringValue(constlabels.HttpUrl),
+			Protocol: "HTTP/" + version,
+		}
+	case constvalues.ProtocolDns:
+		l7.Dns = &hubble.DNS{
+			Query: labels.GetStringValue(constlabels.DnsDomain),
+			Rcode: uint32(labels.GetIntValue(constlabels.DnsRcode)),
+		}
+		if ips := labels.GetStringValue(constlabels.DnsIp); ips != "" {
+			l7.Dns.Ips = strings.Split(ips, ",")
+		}
+		if cnames := labels.GetStringValue(constlabels.DnsCname); cnames != "" {
+			l7.Dns.Cnames = strings.Split(cnames, ",")
+		}
+	case constvalues.ProtocolKafka:
+		apiKey := labels.GetIntValue(constlabels.KafkaApi)
+		name, ok := kafkaApiKeys[apiKey]
+		if !ok {
+			name = strconv.FormatInt(apiKey, 10)
+		}
+		l7.Kafka = &hubble.Kafka{
+			ErrorCode:     int32(labels.GetIntValue(constlabels.KafkaErrorCode)),
+			ApiVersion:    int32(labels.GetIntValue(constlabels.KafkaVersion)),
+			ApiKey:        name,
+			CorrelationId: int32(labels.GetIntValue(constlabels.KafkaCorrelationId)),
+			Topic:         labels.GetStringValue(constlabels.KafkaTopic),
+		}
+	default:
+		return nil
+	}
+	return l7
+}
@@ -0,0 +1,100 @@
+package hubbleprocessor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Kindling-project/kindling/collector/pkg/hubble"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constvalues"
+)
+
+func newRequest(protocol string, isServer bool) *model.DataGroup {
+	labels := model.NewAttributeMap()
+	labels.AddStringValue(constlabels.SrcIp, "10.0.0.1")
+	labels.AddIntValue(constlabels.SrcPort, 40000)
+	labels.AddStringValue(constlabels.SrcNamespace, "default")
+	labels.AddStringValue(constlabels.SrcPod, "frontend-0")
+	labels.AddStringValue(constlabels.SrcWorkloadKind, "statefulset")
+	labels.AddStringValue(constlabels.SrcWorkloadName, "frontend")
+	labels.AddStringValue(constlabels.SrcNode, "worker-1")
+	labels.AddStringValue(constlabels.DstIp, "10.0.0.2")
+	labels.AddIntValue(constlabels.DstPort, 8080)
+	labels.AddStringValue(constlabels.DstNamespace, "shop")
+	labels.AddStringValue(constlabels.DstPod, "backend-0")
+	labels.AddStringValue(constlabels.DstWorkloadKind, "deployment")
+	labels.AddStringValue(constlabels.DstWorkloadName, "backend")
+	labels.AddStringValue(constlabels.DstService, "backend-svc")
+	labels.AddStringValue(constlabels.DstNode, "worker-2")
+	labels.AddStringValue(constlabels.Protocol, protocol)
+	labels.AddBoolValue(constlabels.IsServer, isServer)
+	return model.NewDataGroup(constnames.NetRequestMetricGroupName, labels, 1_000_000_000,
+		model.NewIntMetric(constvalues.RequestTotalTime, 2_500_000))
+}
+
+func TestNewFlow_Http(t *testing.T) {
+	request := newRequest(constvalues.ProtocolHttp, true)
+	request.Labels.AddStringValue(constlabels.HttpMethod, "GET")
+	request.Labels.AddStringValue(constlabels.HttpUrl, "/api/items?id=1")
+	request.Labels.AddIntValue(constlabels.HttpStatusCode, 200)
+
+	flow, ok := newFlow(request, "local")
+	require.True(t, ok)
+	assert.Equal(t, &hubble.Timestamp{Seconds: 1, Nanos: 2_500_000}, flow.Time)
+	assert.Equal(t, hubble.FlowType_L7, flow.Type)
+	assert.Equal(t, hubble.TrafficDirection_INGRESS, flow.TrafficDirection)
+	assert.Equal(t, "worker-2", flow.NodeName)
+	assert.True(t, flow.IsReply.Value)
+	// The response goes from the server to the client.
+	assert.Equal(t, &hubble.IP{Source: "10.0.0.2", Destination: "10.0.0.1", IpVersion: hubble.IPVersion_IPv4}, flow.IP)
+	assert.Equal(t, &hubble.TCP{SourcePort: 8080, DestinationPort: 40000}, flow.L4.TCP)
+	assert.Equal(t, &hubble.Endpoint{
+		Namespace: "shop",
+		Labels:    []string{"k8s:io.kubernetes.pod.namespace=shop"},
+		PodName:   "backend-0",
+		Workloads: []*hubble.Workload{{Name: "backend", Kind: "deployment"}},
+	}, flow.Source)
+	assert.Equal(t, "frontend-0", flow.Destination.PodName)
+	assert.Equal(t, &hubble.Service{Name: "backend-svc", Namespace: "shop"}, flow.SourceService)
+	assert.Nil(t, flow.DestinationService)
+	assert.Equal(t, uint64(2_500_000), flow.L7.LatencyNs)
+	assert.Equal(t, &hubble.HTTP{Code: 200, Method: "GET", Url: "/api/items?id=1", Protocol: "HTTP/1.1"}, flow.L7.Http)
+}
+
+func TestNewFlow_Others(t *testing.T) {
+	dns := newRequest(constvalues.ProtocolDns, false)
+	dns.Labels.AddStringValue(constlabels.DnsDomain, "kindling.io.")
+	dns.Labels.AddStringValue(constlabels.DnsIp, "1.2.3.4,::1")
+	dns.Labels.AddStringValue(constlabels.DnsCname, "cdn.kindling.io.")
+	dns.Labels.UpdateAddStringValue(constlabels.DstNamespace, constlabels.ExternalClusterNamespace)
+	flow, ok := newFlow(dns, "local")
+	require.True(t, ok)
+	assert.Equal(t, hubble.TrafficDirection_EGRESS, flow.TrafficDirection)
+	assert.Equal(t, "worker-1", flow.NodeName)
+	assert.Equal(t, &hubble.UDP{SourcePort: 8080, DestinationPort: 40000}, flow.L4.UDP)
+	assert.Equal(t, []string{"reserved:world"}, flow.Source.Labels)
+	assert.Equal(t, &hubble.DNS{Query: "kindling.io.", Ips: []string{"1.2.3.4", "::1"}, Cnames: []string{"cdn.kindling.io."}}, flow.L7.Dns)
+
+	kafka := newRequest(constvalues.ProtocolKafka, true)
+	kafka.Labels.AddIntValue(constlabels.KafkaApi, 0)
+	kafka.Labels.AddStringValue(constlabels.KafkaTopic, "orders")
+	flow, ok = newFlow(kafka, "local")
+	require.True(t, ok)
+	assert.Equal(t, "produce", flow.L7.Kafka.ApiKey)
+	assert.Equal(t, "orders", flow.L7.Kafka.Topic)
+
+	mysql := newRequest(constvalues.ProtocolMysql, true)
+	mysql.Labels.RemoveAttribute(constlabels.DstNode)
+	flow, ok = newFlow(mysql, "local")
+	require.True(t, ok)
+	assert.Equal(t, hubble.FlowType_L3_L4, flow.Type)
+	assert.Nil(t, flow.L7)
+	assert.Equal(t, "local", flow.NodeName)
+
+	_, ok = newFlow(model.NewDataGroup(constnames.TcpRttMetricGroupName, model.NewAttributeMap(), 0), "local")
+	assert.False(t, ok)
+}
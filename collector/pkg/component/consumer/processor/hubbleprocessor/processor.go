@@ -0,0 +1,68 @@
+package hubbleprocessor
+
+import (
+	"net"
+	"os"
+
+	"go.uber.org/zap"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/processor"
+	"github.com/Kindling-project/kindling/collector/pkg/hubble"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+)
+
+const Type = "hubbleprocessor"
+
+// HubbleProcessor serves the requests as the flows of Hubble through the Observer API of Hubble Relay,
+// so the UIs built for Hubble show the traffic observed by Kindling. All the data groups are passed to
+// the next consumer unchanged.
+type HubbleProcessor struct {
+	cfg          *Config
+	telemetry    *component.TelemetryTools
+	nextConsumer consumer.Consumer
+
+	nodeName string
+	// server is nil if the processor is disabled.
+	server *hubble.Server
+}
+
+func New(config interface{}, telemetry *component.TelemetryTools, nextConsumer consumer.Consumer) processor.Processor {
+	cfg := config.(*Config)
+	p := &HubbleProcessor{
+		cfg:          cfg,
+		telemetry:    telemetry,
+		nextConsumer: nextConsumer,
+		nodeName:     os.Getenv("MY_NODE_NAME"),
+	}
+	if !cfg.Enable {
+		return p
+	}
+	listener, err := net.Listen("tcp", cfg.ListenAddress)
+	if err != nil {
+		telemetry.Logger.Error("Failed to listen on the address, the Hubble flows are disabled",
+			zap.String("address", cfg.ListenAddress), zap.Error(err))
+		return p
+	}
+	p.server = hubble.NewServer(hubble.ServerConfig{
+		NodeName: p.nodeName,
+		NodeIp:   os.Getenv("MY_NODE_IP"),
+		MaxFlows: cfg.MaxFlows,
+	})
+	go func() {
+		if err := p.server.Serve(listener); err != nil {
+			telemetry.Logger.Error("The Hubble server stopped", zap.Error(err))
+		}
+	}()
+	return p
+}
+
+func (p *HubbleProcessor) Consume(dataGroup *model.DataGroup) error {
+	if p.server != nil {
+		if flow, ok := newFlow(dataGroup, p.nodeName); ok {
+			p.server.Add(flow)
+		}
+	}
+	return p.nextConsumer.Consume(dataGroup)
+}
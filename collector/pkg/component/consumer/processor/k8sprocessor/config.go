@@ -23,6 +23,17 @@ type Config struct {
 	// Used to reduce the stress caused by agent directly on APIServer
 	// Set "metadata_provider_config.enable" true and "metadata_provider_config.endpoint" as target service to enable it
 	MetaDataProviderConfig *kubernetes.MetaDataProviderConfig `mapstructure:"metadata_provider_config"`
+	// LeaderElection elects one agent to run the cluster-scoped work, e.g. watching the events of all
+	// the nodes and sending the workload information, instead of every agent doing it.
+	LeaderElection *kubernetes.LeaderElectionConfig `mapstructure:"leader_election"`
+	// AgentConfigCrd watches the KindlingConfig objects to change the settings of the agents at runtime.
+	AgentConfigCrd *kubernetes.AgentConfigCrdConfig `mapstructure:"agent_config_crd"`
+
+	// VipMappings maps the VIPs of the load balancers outside the cluster to the services behind them,
+	// so the destinations of the requests sent through the load balancers are labeled with the services.
+	VipMappings []VipMapping `mapstructure:"vip_mappings"`
+	// VipMappingFile is an optional file holding more VIP mappings, which is reloaded every 30 seconds.
+	VipMappingFile string `mapstructure:"vip_mapping_file"`
 }
 
 var DefaultConfig Config = Config{
@@ -31,4 +42,6 @@ var DefaultConfig Config = Config{
 	GraceDeletePeriod:      60,
 	Enable:                 true,
 	MetaDataProviderConfig: &kubernetes.MetaDataProviderConfig{Enable: false, EnableTrace: false, Endpoint: ""},
+	LeaderElection:         kubernetes.NewDefaultLeaderElectionConfig(),
+	AgentConfigCrd:         kubernetes.NewDefaultAgentConfigCrdConfig(),
 }
@@ -28,6 +28,8 @@ type K8sMetadataProcessor struct {
 	localNodeIp   string
 	localNodeName string
 	telemetry     *component.TelemetryTools
+	// vipResolver is nil if no VIP mapping is configured.
+	vipResolver *vipResolver
 }
 
 func NewKubernetesProcessor(cfg interface{}, telemetry *component.TelemetryTools, nextConsumer consumer.Consumer) processor.Processor {
@@ -55,6 +57,12 @@ func NewKubernetesProcessor(cfg interface{}, telemetry *component.TelemetryTools
 		cli := mpclient.NewMetaDataWrapperClient(config.MetaDataProviderConfig.Endpoint, config.MetaDataProviderConfig.EnableTrace)
 		options = append(options, kubernetes.WithMetaDataProviderConfig(config.MetaDataProviderConfig, cli.ListAndWatch))
 	}
+	if config.LeaderElection != nil {
+		options = append(options, kubernetes.WithLeaderElection(config.LeaderElection))
+	}
+	if config.AgentConfigCrd != nil {
+		options = append(options, kubernetes.WithAgentConfigCrd(config.AgentConfigCrd))
+	}
 	err := kubernetes.InitK8sHandler(options...)
 	if err != nil {
 		telemetry.Logger.Panicf("Failed to initialize [%s]: %v. Set the option 'enable' false if you want to run the agent in the non-Kubernetes environment.", K8sMetadata, err)
@@ -75,11 +83,24 @@ func NewKubernetesProcessor(cfg interface{}, telemetry *component.TelemetryTools
 		localNodeIp:   localNodeIp,
 		localNodeName: localNodeName,
 		telemetry:     telemetry,
+		vipResolver:   newVipResolverFromConfig(config, telemetry),
+	}
+}
+
+func newVipResolverFromConfig(config *Config, telemetry *component.TelemetryTools) *vipResolver {
+	if len(config.VipMappings) == 0 && config.VipMappingFile == "" {
+		return nil
 	}
+	resolver := newVipResolver(config.VipMappings)
+	if config.VipMappingFile != "" {
+		go resolver.watchFile(config.VipMappingFile, telemetry.Logger)
+	}
+	return resolver
 }
 
 func (p *K8sMetadataProcessor) Consume(dataGroup *model.DataGroup) error {
 	if !p.config.Enable {
+		removeAgentNodeLabels(dataGroup.Labels)
 		return p.nextConsumer.Consume(dataGroup)
 	}
 	name := dataGroup.Name
@@ -92,12 +113,36 @@ func (p *K8sMetadataProcessor) Consume(dataGroup *model.DataGroup) error {
 		fallthrough
 	case constnames.TcpDropMetricGroupName:
 		p.processTcpMetric(dataGroup)
+	case constnames.ContainerProtocolMetricGroupName, constnames.ThreadPoolMetricGroupName:
+		p.processContainerMetric(dataGroup)
 	default:
 		p.processNetRequestMetric(dataGroup)
 	}
+	removeAgentNodeLabels(dataGroup.Labels)
 	return p.nextConsumer.Consume(dataGroup)
 }
 
+// nodeIp returns the node where the record is collected, which is the node of the agent forwarding
+// the record if it is received by the aggregator.
+func (p *K8sMetadataProcessor) nodeIp(labelMap *model.AttributeMap) string {
+	if nodeIp := labelMap.GetStringValue(constlabels.AgentNodeIp); nodeIp != "" {
+		return nodeIp
+	}
+	return p.localNodeIp
+}
+
+func (p *K8sMetadataProcessor) nodeName(labelMap *model.AttributeMap) string {
+	if nodeName := labelMap.GetStringValue(constlabels.AgentNodeName); nodeName != "" {
+		return nodeName
+	}
+	return p.localNodeName
+}
+
+func removeAgentNodeLabels(labelMap *model.AttributeMap) {
+	labelMap.RemoveAttribute(constlabels.AgentNodeIp)
+	labelMap.RemoveAttribute(constlabels.AgentNodeName)
+}
+
 func (p *K8sMetadataProcessor) processNetRequestMetric(dataGroup *model.DataGroup) {
 	isServer := dataGroup.Labels.GetBoolValue(constlabels.IsServer)
 	if isServer {
@@ -111,6 +156,25 @@ func (p *K8sMetadataProcessor) processTcpMetric(dataGroup *model.DataGroup) {
 	p.addK8sMetaDataViaIp(dataGroup.Labels)
 }
 
+// processContainerMetric adds the metadata of the container that the metric belongs to, without
+// the prefixes of the source and the destination.
+func (p *K8sMetadataProcessor) processContainerMetric(dataGroup *model.DataGroup) {
+	labelMap := dataGroup.Labels
+	containerInfo, ok := p.metadata.GetByContainerId(labelMap.GetStringValue(constlabels.ContainerId))
+	if !ok {
+		labelMap.UpdateAddStringValue(constlabels.Node, p.nodeName(labelMap))
+		labelMap.UpdateAddStringValue(constlabels.Namespace, constlabels.InternalClusterNamespace)
+		return
+	}
+	podInfo := containerInfo.RefPodInfo
+	labelMap.UpdateAddStringValue(constlabels.Container, containerInfo.Name)
+	labelMap.UpdateAddStringValue(constlabels.Node, podInfo.NodeName)
+	labelMap.UpdateAddStringValue(constlabels.Namespace, podInfo.Namespace)
+	labelMap.UpdateAddStringValue(constlabels.WorkloadKind, podInfo.WorkloadKind)
+	labelMap.UpdateAddStringValue(constlabels.WorkloadName, podInfo.WorkloadName)
+	labelMap.UpdateAddStringValue(constlabels.Pod, podInfo.PodName)
+}
+
 func (p *K8sMetadataProcessor) addK8sMetaDataForClientLabel(labelMap *model.AttributeMap) {
 	// add metadata for src
 	containerId := labelMap.GetStringValue(constlabels.ContainerId)
@@ -120,15 +184,15 @@ func (p *K8sMetadataProcessor) addK8sMetaDataForClientLabel(labelMap *model.Attr
 		if ok {
 			addContainerMetaInfoLabelSRC(labelMap, resInfo)
 		} else {
-			labelMap.UpdateAddStringValue(constlabels.SrcNodeIp, p.localNodeIp)
-			labelMap.UpdateAddStringValue(constlabels.SrcNode, p.localNodeName)
+			labelMap.UpdateAddStringValue(constlabels.SrcNodeIp, p.nodeIp(labelMap))
+			labelMap.UpdateAddStringValue(constlabels.SrcNode, p.nodeName(labelMap))
 			labelMap.UpdateAddStringValue(constlabels.SrcNamespace, constlabels.InternalClusterNamespace)
 		}
 	} else {
 		srcIp := labelMap.GetStringValue(constlabels.SrcIp)
 		if srcIp == loopbackIp {
-			labelMap.UpdateAddStringValue(constlabels.SrcNodeIp, p.localNodeIp)
-			labelMap.UpdateAddStringValue(constlabels.SrcNode, p.localNodeName)
+			labelMap.UpdateAddStringValue(constlabels.SrcNodeIp, p.nodeIp(labelMap))
+			labelMap.UpdateAddStringValue(constlabels.SrcNode, p.nodeName(labelMap))
 		}
 		podInfo, ok := p.metadata.GetPodByIp(srcIp)
 		if ok {
@@ -147,8 +211,8 @@ func (p *K8sMetadataProcessor) addK8sMetaDataForClientLabel(labelMap *model.Attr
 	// add metadata for dst
 	dstIp := labelMap.GetStringValue(constlabels.DstIp)
 	if dstIp == loopbackIp {
-		labelMap.UpdateAddStringValue(constlabels.DstNodeIp, p.localNodeIp)
-		labelMap.UpdateAddStringValue(constlabels.DstNode, p.localNodeName)
+		labelMap.UpdateAddStringValue(constlabels.DstNodeIp, p.nodeIp(labelMap))
+		labelMap.UpdateAddStringValue(constlabels.DstNode, p.nodeName(labelMap))
 		// If the dst IP is a loopback address, we use its src IP for further searching.
 		dstIp = labelMap.GetStringValue(constlabels.SrcIp)
 	}
@@ -182,8 +246,8 @@ func (p *K8sMetadataProcessor) addK8sMetaDataForClientLabel(labelMap *model.Attr
 		labelMap.UpdateAddStringValue(constlabels.DstIp, resInfo.RefPodInfo.Ip)
 		labelMap.UpdateAddIntValue(constlabels.DstPort, int64(resInfo.HostPortMap[int32(dstPort)]))
 		labelMap.UpdateAddStringValue(constlabels.DstService, dstIp+":"+strconv.Itoa(int(dstPort)))
-	} else {
-		// DstIp is a IP from external
+	} else if !p.addVipMetaInfoLabelDST(labelMap, dstIp, uint32(dstPort)) {
+		// DstIp is a IP from external and not a VIP of the load balancers
 		if nodeName, ok := p.metadata.GetNodeNameByIp(dstIp); ok {
 			labelMap.UpdateAddStringValue(constlabels.DstNodeIp, dstIp)
 			labelMap.UpdateAddStringValue(constlabels.DstNode, nodeName)
@@ -197,8 +261,8 @@ func (p *K8sMetadataProcessor) addK8sMetaDataForClientLabel(labelMap *model.Attr
 func (p *K8sMetadataProcessor) addK8sMetaDataForServerLabel(labelMap *model.AttributeMap) {
 	srcIp := labelMap.GetStringValue(constlabels.SrcIp)
 	if srcIp == loopbackIp {
-		labelMap.UpdateAddStringValue(constlabels.SrcNodeIp, p.localNodeIp)
-		labelMap.UpdateAddStringValue(constlabels.SrcNode, p.localNodeName)
+		labelMap.UpdateAddStringValue(constlabels.SrcNodeIp, p.nodeIp(labelMap))
+		labelMap.UpdateAddStringValue(constlabels.SrcNode, p.nodeName(labelMap))
 	}
 	podInfo, ok := p.metadata.GetPodByIp(srcIp)
 	if ok {
@@ -221,8 +285,8 @@ func (p *K8sMetadataProcessor) addK8sMetaDataForServerLabel(labelMap *model.Attr
 			labelMap.UpdateAddStringValue(constlabels.DstService, containerInfo.RefPodInfo.ServiceInfo.ServiceName)
 		}
 	} else {
-		labelMap.UpdateAddStringValue(constlabels.DstNodeIp, p.localNodeIp)
-		labelMap.UpdateAddStringValue(constlabels.DstNode, p.localNodeName)
+		labelMap.UpdateAddStringValue(constlabels.DstNodeIp, p.nodeIp(labelMap))
+		labelMap.UpdateAddStringValue(constlabels.DstNode, p.nodeName(labelMap))
 		labelMap.UpdateAddStringValue(constlabels.DstNamespace, constlabels.InternalClusterNamespace)
 	}
 }
@@ -345,6 +409,9 @@ func (p *K8sMetadataProcessor) addK8sMetaDataViaIpDST(labelMap *model.AttributeM
 		addPodMetaInfoLabelDST(labelMap, dstPodInfo)
 		return
 	}
+	if p.addVipMetaInfoLabelDST(labelMap, dstIp, uint32(dstPort)) {
+		return
+	}
 	if _, ok := p.metadata.GetNodeNameByIp(dstIp); ok {
 		labelMap.UpdateAddStringValue(constlabels.DstNamespace, constlabels.InternalClusterNamespace)
 	} else {
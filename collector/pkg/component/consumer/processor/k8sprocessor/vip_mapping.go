@@ -0,0 +1,124 @@
+package k8sprocessor
+
+import (
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+const vipMappingReloadInterval = 30 * time.Second
+
+// VipMapping maps a virtual IP of a load balancer outside the cluster to the service behind it.
+type VipMapping struct {
+	Ip string `mapstructure:"ip"`
+	// Port is optional. The mapping applies to all the ports of the IP if it is 0.
+	Port    uint32 `mapstructure:"port"`
+	Service string `mapstructure:"service"`
+	// Namespace is optional. The namespace of the external addresses is used if it is empty.
+	Namespace string `mapstructure:"namespace"`
+}
+
+type vipKey struct {
+	ip   string
+	port uint32
+}
+
+// vipResolver finds the services of the VIPs. The mappings from the configuration are merged
+// with the ones from the mapping file, which could be kept in sync with the API of the load
+// balancers by other tools. It is safe for concurrent use.
+type vipResolver struct {
+	staticMappings []VipMapping
+	mutex          sync.RWMutex
+	mappings       map[vipKey]VipMapping
+}
+
+func newVipResolver(staticMappings []VipMapping) *vipResolver {
+	r := &vipResolver{staticMappings: staticMappings}
+	r.update(nil)
+	return r
+}
+
+// update replaces the mappings from the file. The mappings from the file take precedence.
+func (r *vipResolver) update(fileMappings []VipMapping) {
+	mappings := make(map[vipKey]VipMapping, len(r.staticMappings)+len(fileMappings))
+	for _, mapping := range r.staticMappings {
+		mappings[vipKey{ip: mapping.Ip, port: mapping.Port}] = mapping
+	}
+	for _, mapping := range fileMappings {
+		mappings[vipKey{ip: mapping.Ip, port: mapping.Port}] = mapping
+	}
+	r.mutex.Lock()
+	r.mappings = mappings
+	r.mutex.Unlock()
+}
+
+// resolve returns the mapping of the IP and port. The mapping of the exact port is preferred
+// over the one of all ports.
+func (r *vipResolver) resolve(ip string, port uint32) (VipMapping, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	if mapping, ok := r.mappings[vipKey{ip: ip, port: port}]; ok {
+		return mapping, true
+	}
+	mapping, ok := r.mappings[vipKey{ip: ip}]
+	return mapping, ok
+}
+
+// loadVipMappingFile reads the mappings from a file with the key "vip_mappings", in the same
+// format as the configuration.
+func loadVipMappingFile(path string) ([]VipMapping, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, err
+	}
+	var mappings []VipMapping
+	if err := v.UnmarshalKey("vip_mappings", &mappings); err != nil {
+		return nil, err
+	}
+	return mappings, nil
+}
+
+// watchFile reloads the mapping file periodically. The previous mappings are kept if the file
+// can't be read.
+func (r *vipResolver) watchFile(path string, logger *component.TelemetryLogger) {
+	reload := func() {
+		mappings, err := loadVipMappingFile(path)
+		if err != nil {
+			logger.Warn("Failed to load the VIP mapping file", zap.String("path", path), zap.Error(err))
+			return
+		}
+		r.update(mappings)
+	}
+	reload()
+	ticker := time.NewTicker(vipMappingReloadInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		reload()
+	}
+}
+
+// addVipMetaInfoLabelDST labels the destination with the service of the VIP. It returns false
+// if the destination is not a VIP.
+func (p *K8sMetadataProcessor) addVipMetaInfoLabelDST(labelMap *model.AttributeMap, dstIp string, dstPort uint32) bool {
+	if p.vipResolver == nil {
+		return false
+	}
+	mapping, ok := p.vipResolver.resolve(dstIp, dstPort)
+	if !ok {
+		return false
+	}
+	labelMap.UpdateAddStringValue(constlabels.DstService, mapping.Service)
+	if mapping.Namespace != "" {
+		labelMap.UpdateAddStringValue(constlabels.DstNamespace, mapping.Namespace)
+	} else {
+		labelMap.UpdateAddStringValue(constlabels.DstNamespace, constlabels.ExternalClusterNamespace)
+	}
+	return true
+}
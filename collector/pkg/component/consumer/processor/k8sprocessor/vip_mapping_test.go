@@ -0,0 +1,62 @@
+package k8sprocessor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+func TestVipResolver(t *testing.T) {
+	r := newVipResolver([]VipMapping{
+		{Ip: "192.168.1.100", Service: "gateway"},
+		{Ip: "192.168.1.100", Port: 3306, Service: "mysql-primary", Namespace: "db"},
+	})
+	mapping, ok := r.resolve("192.168.1.100", 3306)
+	assert.True(t, ok)
+	assert.Equal(t, "mysql-primary", mapping.Service)
+	mapping, ok = r.resolve("192.168.1.100", 443)
+	assert.True(t, ok)
+	assert.Equal(t, "gateway", mapping.Service)
+	_, ok = r.resolve("192.168.1.101", 443)
+	assert.False(t, ok)
+
+	// The mappings from the file override the static ones.
+	r.update([]VipMapping{{Ip: "192.168.1.100", Service: "gateway-v2"}})
+	mapping, _ = r.resolve("192.168.1.100", 443)
+	assert.Equal(t, "gateway-v2", mapping.Service)
+	mapping, _ = r.resolve("192.168.1.100", 3306)
+	assert.Equal(t, "mysql-primary", mapping.Service)
+}
+
+func TestLoadVipMappingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vip-mappings.yaml")
+	content := `vip_mappings:
+  - ip: 10.10.0.1
+    port: 80
+    service: payment
+    namespace: prod
+`
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	mappings, err := loadVipMappingFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, []VipMapping{{Ip: "10.10.0.1", Port: 80, Service: "payment", Namespace: "prod"}}, mappings)
+
+	_, err = loadVipMappingFile(filepath.Join(t.TempDir(), "not-exist.yaml"))
+	assert.Error(t, err)
+}
+
+func TestAddVipMetaInfoLabelDST(t *testing.T) {
+	p := &K8sMetadataProcessor{vipResolver: newVipResolver([]VipMapping{{Ip: "192.168.1.100", Service: "gateway"}})}
+	labels := model.NewAttributeMap()
+	assert.True(t, p.addVipMetaInfoLabelDST(labels, "192.168.1.100", 443))
+	assert.Equal(t, "gateway", labels.GetStringValue(constlabels.DstService))
+	assert.Equal(t, constlabels.ExternalClusterNamespace, labels.GetStringValue(constlabels.DstNamespace))
+
+	assert.False(t, p.addVipMetaInfoLabelDST(model.NewAttributeMap(), "192.168.1.101", 443))
+	assert.False(t, (&K8sMetadataProcessor{}).addVipMetaInfoLabelDST(model.NewAttributeMap(), "192.168.1.100", 443))
+}
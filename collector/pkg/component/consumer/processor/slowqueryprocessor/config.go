@@ -0,0 +1,57 @@
+package slowqueryprocessor
+
+type Config struct {
+	Enable bool `mapstructure:"enable"`
+	// Thresholds are the latencies in milliseconds above which the queries of the protocols are logged.
+	// The protocols not listed are not logged. If the threshold of a protocol is 0, the queries marked
+	// as slow by the networkanalyzer are logged.
+	Thresholds map[string]int `mapstructure:"thresholds"`
+	// QueueSize is the number of the slow queries buffered for the writer. The queries are dropped
+	// when the queue is full, so a slow disk never blocks the metrics pipeline.
+	QueueSize int `mapstructure:"queue_size"`
+	// The unit is second. The buffered queries are written at least once every FlushInterval.
+	FlushInterval int         `mapstructure:"flush_interval"`
+	File          *FileConfig `mapstructure:"file"`
+}
+
+type FileConfig struct {
+	// Path is the file the slow queries are written to, one JSON object per line.
+	// The slow queries are written to stdout if it is empty.
+	Path string `mapstructure:"path"`
+	// The unit is MB. The file is rotated when it is larger than MaxSize.
+	MaxSize    int `mapstructure:"max_size"`
+	MaxBackups int `mapstructure:"max_backups"`
+}
+
+func NewDefaultConfig() *Config {
+	return &Config{
+		Enable: false,
+		Thresholds: map[string]int{
+			"mysql":      500,
+			"oracle":     500,
+			"postgresql": 500,
+			"redis":      50,
+		},
+		QueueSize:     10000,
+		FlushInterval: 5,
+		File: &FileConfig{
+			Path:       "/tmp/kindling/slow_queries.json",
+			MaxSize:    100,
+			MaxBackups: 5,
+		},
+	}
+}
+
+func (cfg *Config) getQueueSize() int {
+	if cfg.QueueSize > 0 {
+		return cfg.QueueSize
+	}
+	return 10000
+}
+
+func (cfg *Config) getFlushInterval() int {
+	if cfg.FlushInterval > 0 {
+		return cfg.FlushInterval
+	}
+	return 5
+}
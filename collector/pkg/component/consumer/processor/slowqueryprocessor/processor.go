@@ -0,0 +1,124 @@
+package slowqueryprocessor
+
+import (
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/processor"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constvalues"
+	"github.com/Kindling-project/kindling/collector/pkg/model/timeunit"
+)
+
+const Type = "slowqueryprocessor"
+
+// maxBatchSize is the maximum number of the slow queries written at once.
+const maxBatchSize = 500
+
+// SlowQueryProcessor logs the database queries above the thresholds like the slow logs of the databases,
+// without accessing the configurations of the databases. The queries are written asynchronously and all
+// the data groups are passed to the next consumer unchanged.
+type SlowQueryProcessor struct {
+	cfg          *Config
+	telemetry    *component.TelemetryTools
+	nextConsumer consumer.Consumer
+
+	thresholds map[string]timeunit.Duration
+	writer     *queryWriter
+	queue      chan *SlowQuery
+	dropped    int64
+}
+
+func New(config interface{}, telemetry *component.TelemetryTools, nextConsumer consumer.Consumer) processor.Processor {
+	cfg := config.(*Config)
+	p := &SlowQueryProcessor{
+		cfg:          cfg,
+		telemetry:    telemetry,
+		nextConsumer: nextConsumer,
+		thresholds:   newThresholds(cfg.Thresholds),
+	}
+	if !cfg.Enable {
+		return p
+	}
+	p.writer = newQueryWriter(cfg.File)
+	p.queue = make(chan *SlowQuery, cfg.getQueueSize())
+	go p.run()
+	return p
+}
+
+// newThresholds converts the thresholds in milliseconds, keyed by the lower-case protocols.
+func newThresholds(configs map[string]int) map[string]timeunit.Duration {
+	thresholds := make(map[string]timeunit.Duration, len(configs))
+	for protocol, threshold := range configs {
+		thresholds[strings.ToLower(protocol)] = timeunit.Duration(time.Duration(threshold) * time.Millisecond)
+	}
+	return thresholds
+}
+
+func (p *SlowQueryProcessor) Consume(dataGroup *model.DataGroup) error {
+	if p.writer != nil && dataGroup.Name == constnames.NetRequestMetricGroupName {
+		if duration, ok := p.isSlow(dataGroup); ok {
+			select {
+			case p.queue <- newSlowQuery(dataGroup, duration):
+			default:
+				atomic.AddInt64(&p.dropped, 1)
+			}
+		}
+	}
+	return p.nextConsumer.Consume(dataGroup)
+}
+
+// isSlow returns the latency of the query if it is above the threshold of its protocol.
+func (p *SlowQueryProcessor) isSlow(dataGroup *model.DataGroup) (timeunit.Duration, bool) {
+	threshold, ok := p.thresholds[dataGroup.Labels.GetStringValue(constlabels.Protocol)]
+	if !ok {
+		return 0, false
+	}
+	metric, ok := dataGroup.GetMetric(constvalues.RequestTotalTime)
+	if !ok || metric.GetInt() == nil {
+		return 0, false
+	}
+	duration := timeunit.Duration(metric.GetInt().Value)
+	if threshold == 0 {
+		return duration, dataGroup.Labels.GetBoolValue(constlabels.IsSlow)
+	}
+	return duration, duration >= threshold
+}
+
+func (p *SlowQueryProcessor) run() {
+	ticker := time.NewTicker(time.Duration(p.cfg.getFlushInterval()) * time.Second)
+	defer ticker.Stop()
+	batch := make([]*SlowQuery, 0, maxBatchSize)
+	for {
+		select {
+		case query := <-p.queue:
+			batch = append(batch, query)
+			if len(batch) >= maxBatchSize {
+				batch = p.export(batch)
+			}
+		case <-ticker.C:
+			batch = p.export(batch)
+		}
+	}
+}
+
+// export writes the slow queries and returns the emptied batch for reuse.
+func (p *SlowQueryProcessor) export(batch []*SlowQuery) []*SlowQuery {
+	if dropped := atomic.SwapInt64(&p.dropped, 0); dropped > 0 {
+		p.telemetry.Logger.Warn("The slow query queue is full, some queries are dropped", zap.Int64("dropped", dropped))
+	}
+	if len(batch) == 0 {
+		return batch
+	}
+	if err := p.writer.write(batch); err != nil {
+		p.telemetry.Logger.Warn("Failed to write the slow queries", zap.Int("queries", len(batch)), zap.Error(err))
+	}
+	return batch[:0]
+}
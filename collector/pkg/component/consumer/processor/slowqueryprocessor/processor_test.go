@@ -0,0 +1,84 @@
+package slowqueryprocessor
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constvalues"
+)
+
+func newQueryDataGroup(protocol string, latency int64, isSlow bool) *model.DataGroup {
+	labels := model.NewAttributeMapWithValues(map[string]model.AttributeValue{
+		constlabels.Protocol:     model.NewStringValue(protocol),
+		constlabels.IsSlow:       model.NewBoolValue(isSlow),
+		constlabels.SrcIp:        model.NewStringValue("10.0.0.1"),
+		constlabels.SrcPort:      model.NewIntValue(52000),
+		constlabels.SrcPod:       model.NewStringValue("app-0"),
+		constlabels.DstIp:        model.NewStringValue("10.0.0.2"),
+		constlabels.DstPort:      model.NewIntValue(3306),
+		constlabels.Sql:          model.NewStringValue("UPDATE t SET a = 1"),
+		constlabels.RedisCommand: model.NewStringValue("KEYS"),
+	})
+	return model.NewDataGroup(constnames.NetRequestMetricGroupName, labels, 1_000_000_000,
+		model.NewIntMetric(constvalues.RequestTotalTime, latency))
+}
+
+func TestSlowQueryProcessor_isSlow(t *testing.T) {
+	p := &SlowQueryProcessor{thresholds: newThresholds(map[string]int{"MySQL": 500, "redis": 0})}
+	tests := []struct {
+		name      string
+		dataGroup *model.DataGroup
+		want      bool
+	}{
+		{name: "above threshold", dataGroup: newQueryDataGroup("mysql", 600_000_000, false), want: true},
+		{name: "below threshold", dataGroup: newQueryDataGroup("mysql", 400_000_000, true), want: false},
+		{name: "marked as slow", dataGroup: newQueryDataGroup("redis", 1_000_000, true), want: true},
+		{name: "not marked as slow", dataGroup: newQueryDataGroup("redis", 900_000_000, false), want: false},
+		{name: "not configured", dataGroup: newQueryDataGroup("http", 900_000_000, true), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, got := p.isSlow(tt.dataGroup)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNewSlowQuery(t *testing.T) {
+	dataGroup := newQueryDataGroup("mysql", 600_000_000, true)
+	dataGroup.Labels.AddIntValue(constlabels.SqlAffectedRows, 3)
+	query := newSlowQuery(dataGroup, 600_000_000)
+	assert.Equal(t, "UPDATE t SET a = 1", query.Statement)
+	assert.Equal(t, 600.0, query.DurationMs)
+	if assert.NotNil(t, query.Rows) {
+		assert.Equal(t, int64(3), *query.Rows)
+	}
+	assert.Equal(t, Endpoint{Ip: "10.0.0.1", Port: 52000, Pod: "app-0"}, query.Client)
+	assert.Equal(t, Endpoint{Ip: "10.0.0.2", Port: 3306}, query.Server)
+
+	query = newSlowQuery(newQueryDataGroup("redis", 60_000_000, true), 60_000_000)
+	assert.Equal(t, "KEYS", query.Statement)
+	assert.Nil(t, query.Rows)
+}
+
+func TestQueryWriter(t *testing.T) {
+	var out bytes.Buffer
+	writer := &queryWriter{out: &out}
+	query := newSlowQuery(newQueryDataGroup("redis", 60_000_000, true), 60_000_000)
+	assert.NoError(t, writer.write([]*SlowQuery{query, query}))
+
+	lines := bytes.Split(bytes.TrimSpace(out.Bytes()), []byte("\n"))
+	if assert.Len(t, lines, 2) {
+		var entry map[string]interface{}
+		assert.NoError(t, json.Unmarshal(lines[0], &entry))
+		assert.Equal(t, "KEYS", entry["statement"])
+		assert.Equal(t, 60.0, entry["duration_ms"])
+		assert.NotContains(t, entry, "rows")
+	}
+}
@@ -0,0 +1,71 @@
+package slowqueryprocessor
+
+import (
+	"time"
+
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constvalues"
+	"github.com/Kindling-project/kindling/collector/pkg/model/timeunit"
+)
+
+// SlowQuery is an entry of the slow query log, which mirrors the slow logs of the databases.
+// The data groups are reused by the analyzers, so the values are copied when the entry is created.
+type SlowQuery struct {
+	Timestamp time.Time `json:"timestamp"`
+	Protocol  string    `json:"protocol"`
+	// Statement is the SQL or the Redis command.
+	Statement string `json:"statement"`
+	// Rows is the number of the affected rows, which is omitted if it is unknown.
+	Rows       *int64   `json:"rows,omitempty"`
+	DurationMs float64  `json:"duration_ms"`
+	IsError    bool     `json:"is_error"`
+	Error      string   `json:"error,omitempty"`
+	Client     Endpoint `json:"client"`
+	Server     Endpoint `json:"server"`
+}
+
+type Endpoint struct {
+	Ip        string `json:"ip"`
+	Port      int64  `json:"port"`
+	Pod       string `json:"pod,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Workload  string `json:"workload,omitempty"`
+}
+
+func newSlowQuery(dataGroup *model.DataGroup, duration timeunit.Duration) *SlowQuery {
+	labels := dataGroup.Labels
+	query := &SlowQuery{
+		Timestamp:  timeunit.Timestamp(dataGroup.Timestamp).Time(),
+		Protocol:   labels.GetStringValue(constlabels.Protocol),
+		DurationMs: float64(duration) / float64(timeunit.Millisecond.Nanoseconds()),
+		IsError:    labels.GetBoolValue(constlabels.IsError),
+		Client: Endpoint{
+			Ip:        labels.GetStringValue(constlabels.SrcIp),
+			Port:      labels.GetIntValue(constlabels.SrcPort),
+			Pod:       labels.GetStringValue(constlabels.SrcPod),
+			Namespace: labels.GetStringValue(constlabels.SrcNamespace),
+			Workload:  labels.GetStringValue(constlabels.SrcWorkloadName),
+		},
+		Server: Endpoint{
+			Ip:        labels.GetStringValue(constlabels.DstIp),
+			Port:      labels.GetIntValue(constlabels.DstPort),
+			Pod:       labels.GetStringValue(constlabels.DstPod),
+			Namespace: labels.GetStringValue(constlabels.DstNamespace),
+			Workload:  labels.GetStringValue(constlabels.DstWorkloadName),
+		},
+	}
+	switch query.Protocol {
+	case constvalues.ProtocolRedis:
+		query.Statement = labels.GetStringValue(constlabels.RedisCommand)
+		query.Error = labels.GetStringValue(constlabels.RedisErrMsg)
+	default:
+		query.Statement = labels.GetStringValue(constlabels.Sql)
+		query.Error = labels.GetStringValue(constlabels.SqlErrMsg)
+	}
+	if labels.HasAttribute(constlabels.SqlAffectedRows) {
+		rows := labels.GetIntValue(constlabels.SqlAffectedRows)
+		query.Rows = &rows
+	}
+	return query
+}
@@ -0,0 +1,37 @@
+package slowqueryprocessor
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// queryWriter writes one JSON object per line for each slow query.
+type queryWriter struct {
+	out io.Writer
+}
+
+func newQueryWriter(cfg *FileConfig) *queryWriter {
+	if cfg == nil || cfg.Path == "" {
+		return &queryWriter{out: os.Stdout}
+	}
+	return &queryWriter{out: &lumberjack.Logger{
+		Filename:   cfg.Path,
+		MaxSize:    cfg.MaxSize,
+		MaxBackups: cfg.MaxBackups,
+	}}
+}
+
+func (w *queryWriter) write(queries []*SlowQuery) error {
+	buf := bufio.NewWriter(w.out)
+	encoder := json.NewEncoder(buf)
+	for _, query := range queries {
+		if err := encoder.Encode(query); err != nil {
+			return err
+		}
+	}
+	return buf.Flush()
+}
@@ -13,6 +13,7 @@ import (
 type ControllerAPI interface {
 	RegistController(c Controller)
 	RegistModule(module string, subModules ...ExportSubModule)
+	RegistHandler(pattern string, handler http.Handler)
 }
 
 type Controller interface {
@@ -65,6 +66,8 @@ func (cf *ControllerFactory) ConstructConfig(viper *viper.Viper, tools *componen
 			case ProfileModule:
 				profileController := NewProfileController(tools)
 				httpAPI.RegistController(profileController)
+			case DebugModule:
+				registDebugHandlers(httpAPI)
 			}
 		}
 		go http.ListenAndServe(controllerConfig.Http.Port, httpAPI)
@@ -76,3 +79,12 @@ func (cf *ControllerFactory) ConstructConfig(viper *viper.Viper, tools *componen
 func (cf *ControllerFactory) RegistModule(module string, subModules ...ExportSubModule) {
 	cf.Controller.RegistModule(module, subModules...)
 }
+
+// RegistHandler exposes the handler of other components at the pattern.
+// It does nothing if the controller is disabled.
+func (cf *ControllerFactory) RegistHandler(pattern string, handler http.Handler) {
+	if cf.Controller == nil {
+		return
+	}
+	cf.Controller.RegistHandler(pattern, handler)
+}
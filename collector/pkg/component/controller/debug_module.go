@@ -0,0 +1,20 @@
+package controller
+
+import (
+	"expvar"
+	"net/http/pprof"
+)
+
+// DebugModule exposes the runtime internals for triaging the agent without redeploying a debug build.
+// The variables published via expvar, e.g. the internal state of the analyzers, are served at
+// "/debug/vars", and the goroutine and heap profiles are served at "/debug/pprof/".
+const DebugModule = "debug"
+
+func registDebugHandlers(httpAPI *HttpAPI) {
+	httpAPI.Handle("/debug/vars", expvar.Handler())
+	httpAPI.HandleFunc("/debug/pprof/", pprof.Index)
+	httpAPI.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	httpAPI.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	httpAPI.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	httpAPI.HandleFunc("/debug/pprof/trace", pprof.Trace)
+}
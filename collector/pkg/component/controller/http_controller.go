@@ -43,6 +43,10 @@ func (hc *HttpAPI) RegistModule(module string, subModules ...ExportSubModule) {
 	}
 }
 
+func (hc *HttpAPI) RegistHandler(pattern string, handler http.Handler) {
+	hc.Handle(pattern, handler)
+}
+
 func (hc *HttpAPI) RegistController(c Controller) {
 	hc.controllerMap[c.GetModuleKey()] = c
 	hc.HandleFunc(fmt.Sprintf("/%s", c.GetModuleKey()), func(w http.ResponseWriter, r *http.Request) {
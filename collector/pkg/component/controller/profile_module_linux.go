@@ -0,0 +1,47 @@
+//go:build linux
+// +build linux
+
+package controller
+
+/*
+#cgo LDFLAGS: -L ./ -lkindling  -lstdc++ -ldl
+#cgo CFLAGS: -I .
+#include <stdlib.h>
+#include <stdint.h>
+#include <stdio.h>
+#include "../receiver/cgoreceiver/cgo_func.h"
+*/
+import "C"
+import (
+	"unsafe"
+)
+
+func startAttachAgent(pid int) string {
+	result := C.startAttachAgent(C.int(pid))
+	errorMsg := C.GoString(result)
+	C.free(unsafe.Pointer(result))
+	if len(errorMsg) > 0 {
+		return errorMsg
+	}
+	return ""
+}
+
+func stopAttachAgent(pid int) string {
+	result := C.stopAttachAgent(C.int(pid))
+	errorMsg := C.GoString(result)
+	C.free(unsafe.Pointer(result))
+	if len(errorMsg) > 0 {
+		return errorMsg
+	}
+	return ""
+}
+
+func startDebug(pid int, tid int) error {
+	C.startProfileDebug(C.int(pid), C.int(tid))
+	return nil
+}
+
+func stopDebug() error {
+	C.stopProfileDebug()
+	return nil
+}
@@ -0,0 +1,27 @@
+//go:build !linux
+// +build !linux
+
+package controller
+
+import (
+	"errors"
+)
+
+// The probe is only available on Linux, so the operations attaching it fail on the other platforms.
+var errProbeNotSupported = errors.New("the probe is not supported on this platform")
+
+func startAttachAgent(pid int) string {
+	return errProbeNotSupported.Error()
+}
+
+func stopAttachAgent(pid int) string {
+	return errProbeNotSupported.Error()
+}
+
+func startDebug(pid int, tid int) error {
+	return errProbeNotSupported
+}
+
+func stopDebug() error {
+	return errProbeNotSupported
+}
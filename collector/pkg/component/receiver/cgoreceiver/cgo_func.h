@@ -20,6 +20,7 @@ void startProfileDebug(int pid, int tid);
 void stopProfileDebug();
 void getCaptureStatistics(struct capture_statistics_for_go* stats);
 void catchSignalUp();
+void setSnaplenForGo(int snaplen);
 #ifdef __cplusplus
 }
 
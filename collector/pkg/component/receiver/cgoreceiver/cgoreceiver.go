@@ -1,3 +1,6 @@
+//go:build linux
+// +build linux
+
 package cgoreceiver
 
 /*
@@ -11,6 +14,7 @@ package cgoreceiver
 import "C"
 import (
 	"fmt"
+	"math"
 	"sync"
 	"time"
 	"unsafe"
@@ -24,10 +28,6 @@ import (
 	"github.com/Kindling-project/kindling/collector/pkg/model"
 )
 
-const (
-	Cgo = "cgoreceiver"
-)
-
 type CKindlingEventForGo C.struct_kindling_event_t_for_go
 
 type CEventParamsForSubscribe C.struct_event_params_for_subscribe
@@ -42,6 +42,8 @@ type CgoReceiver struct {
 	stats             eventCounter
 	probeCounter      *probeCounter
 	probeCounterMutex sync.RWMutex
+	// snaplen is the snaplen negotiated with the analyzers, which is 0 if the configured one is used.
+	snaplen int
 }
 
 func NewCgoReceiver(config interface{}, telemetry *component.TelemetryTools, analyzerManager *analyzerpackage.Manager) receiver.Receiver {
@@ -77,6 +79,7 @@ func (r *CgoReceiver) Start() error {
 	go r.consumeEvents()
 	go r.startGetEvents()
 	go r.getCaptureStatisticsByInterval(15 * time.Second)
+	go r.adjustSnaplenByInterval(15 * time.Second)
 
 	return nil
 }
@@ -182,8 +185,11 @@ func (r *CgoReceiver) sendToNextConsumer(evt *model.KindlingEvent) error {
 		//r.telemetry.Logger.Info("analyzer not found for event ", zap.String("eventName", evt.Name))
 		return nil
 	}
+	costSampler := r.analyzerManager.CostSampler()
 	for _, analyzer := range analyzers {
+		start := costSampler.Start()
 		err := analyzer.ConsumeEvent(evt)
+		costSampler.Stop(analyzer.Type().String(), start)
 		if err != nil {
 			r.telemetry.Logger.Warn("Error sending event to next consumer: ", zap.Error(err))
 		}
@@ -273,6 +279,35 @@ func (r *CgoReceiver) getCaptureStatistics() {
 	r.probeCounter.tidsSuppressed = int64(captureStatistics.tids_suppressed)
 }
 
+// adjustSnaplenByInterval applies the snaplen negotiated with the analyzers, which changes when the
+// protocol settings are reloaded.
+func (r *CgoReceiver) adjustSnaplenByInterval(interval time.Duration) {
+	r.adjustSnaplen()
+	timer := time.NewTicker(interval)
+	for {
+		select {
+		case <-timer.C:
+			r.adjustSnaplen()
+		case <-r.stopCh:
+			return
+		}
+	}
+}
+
+func (r *CgoReceiver) adjustSnaplen() {
+	snaplen := r.analyzerManager.CaptureLength()
+	if snaplen == r.snaplen {
+		return
+	}
+	r.telemetry.Logger.Infof("Adjust the snaplen negotiated with the analyzers from %d to %d", r.snaplen, snaplen)
+	r.snaplen = snaplen
+	if snaplen <= 0 {
+		// The probe caps the snaplen with the configured one, so it is restored.
+		snaplen = math.MaxInt32
+	}
+	C.setSnaplenForGo(C.int(snaplen))
+}
+
 func (r *CgoReceiver) catchSignalUp() {
 	C.catchSignalUp()
 }
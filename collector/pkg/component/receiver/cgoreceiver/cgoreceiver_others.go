@@ -0,0 +1,48 @@
+//go:build !linux
+// +build !linux
+
+package cgoreceiver
+
+import (
+	"errors"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component"
+	analyzerpackage "github.com/Kindling-project/kindling/collector/pkg/component/analyzer"
+	"github.com/Kindling-project/kindling/collector/pkg/component/receiver"
+)
+
+// errProbeNotSupported is returned on the platforms the probe is not built for. See docs/windows_support.md
+// for the receiver planned for Windows.
+var errProbeNotSupported = errors.New("the cgoreceiver is only supported on Linux")
+
+// CgoReceiver is a stub which keeps the collector buildable on the other platforms.
+type CgoReceiver struct {
+	telemetry *component.TelemetryTools
+}
+
+func NewCgoReceiver(config interface{}, telemetry *component.TelemetryTools, analyzerManager *analyzerpackage.Manager) receiver.Receiver {
+	if _, ok := config.(*Config); !ok {
+		telemetry.Logger.Panicf("Cannot convert [%s] config", Cgo)
+	}
+	return &CgoReceiver{telemetry: telemetry}
+}
+
+func (r *CgoReceiver) Start() error {
+	return errProbeNotSupported
+}
+
+func (r *CgoReceiver) Shutdown() error {
+	return nil
+}
+
+func (r *CgoReceiver) StartProfile() error {
+	return errProbeNotSupported
+}
+
+func (r *CgoReceiver) StopProfile() error {
+	return errProbeNotSupported
+}
+
+func (r *CgoReceiver) ProfileModule() (submodule string, start func() error, stop func() error) {
+	return "cgoreceiver", r.StartProfile, r.StopProfile
+}
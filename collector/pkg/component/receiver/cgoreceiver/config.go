@@ -1,5 +1,9 @@
 package cgoreceiver
 
+const (
+	Cgo = "cgoreceiver"
+)
+
 type Config struct {
 	SubscribeInfo     []SubEvent    `mapstructure:"subscribe"`
 	ProcessFilterInfo ProcessFilter `mapstructure:"process_filter"`
@@ -1,3 +1,6 @@
+//go:build linux
+// +build linux
+
 package cgoreceiver
 
 import (
@@ -22,6 +25,7 @@ const (
 	preemptionsMetric      = "kindling_telemetry_cgoreceiver_preemptions_total"
 	skippedEventMetric     = "kindling_telemetry_cgoreceiver_skipped_events_total"
 	suppressedThreadMetric = "kindling_telemetry_cgoreceiver_suppressed_thread_total"
+	analyzerCpuTimeMetric  = "kindling_telemetry_analyzer_cpu_time_nanoseconds_total"
 )
 
 func newSelfMetrics(meterProvider metric.MeterProvider, receiver *CgoReceiver) {
@@ -70,6 +74,12 @@ func newSelfMetrics(meterProvider metric.MeterProvider, receiver *CgoReceiver) {
 				result.Observe(receiver.probeCounter.tidsSuppressed)
 				receiver.probeCounterMutex.RUnlock()
 			}, metric.WithDescription("Number of threads currently being suppressed"))
+		meter.NewInt64CounterObserver(analyzerCpuTimeMetric,
+			func(ctx context.Context, result metric.Int64ObserverResult) {
+				for name, value := range receiver.analyzerManager.CostSampler().Costs() {
+					result.Observe(value, attribute.String("analyzer", name))
+				}
+			}, metric.WithDescription("The estimated CPU time consumed by each analyzer, which is sampled"))
 
 	})
 }
@@ -0,0 +1,29 @@
+package etwreceiver
+
+import "time"
+
+const Type = "etwreceiver"
+
+type Config struct {
+	// SessionName is the name of the real-time ETW session. The session left by the previous run is
+	// stopped before starting a new one.
+	SessionName string `mapstructure:"session_name"`
+	// EnableHttp enables the provider Microsoft-Windows-HttpService, which traces the requests served by
+	// HTTP.sys, e.g. IIS and the services built on HttpListener.
+	EnableHttp bool `mapstructure:"enable_http"`
+	// EnableDns enables the provider Microsoft-Windows-DNS-Client, which traces the queries sent by the
+	// DNS client service.
+	EnableDns bool `mapstructure:"enable_dns"`
+	// PendingTimeout is how long the requests wait for their responses. The requests are converted into
+	// the events once their responses are traced, so the ones without responses are dropped.
+	PendingTimeout time.Duration `mapstructure:"pending_timeout"`
+}
+
+func NewDefaultConfig() *Config {
+	return &Config{
+		SessionName:    "kindling-etw",
+		EnableHttp:     true,
+		EnableDns:      true,
+		PendingTimeout: 60 * time.Second,
+	}
+}
@@ -0,0 +1,338 @@
+//go:build windows && (amd64 || arm64)
+// +build windows
+// +build amd64 arm64
+
+package etwreceiver
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	advapi32 = windows.NewLazySystemDLL("advapi32.dll")
+	tdh      = windows.NewLazySystemDLL("tdh.dll")
+
+	procStartTraceW           = advapi32.NewProc("StartTraceW")
+	procControlTraceW         = advapi32.NewProc("ControlTraceW")
+	procEnableTraceEx2        = advapi32.NewProc("EnableTraceEx2")
+	procOpenTraceW            = advapi32.NewProc("OpenTraceW")
+	procProcessTrace          = advapi32.NewProc("ProcessTrace")
+	procCloseTrace            = advapi32.NewProc("CloseTrace")
+	procTdhGetEventInfo       = tdh.NewProc("TdhGetEventInformation")
+	procTdhGetPropertySize    = tdh.NewProc("TdhGetPropertySize")
+	procTdhGetProperty        = tdh.NewProc("TdhGetProperty")
+	invalidProcessTraceHandle = ^uint64(0)
+)
+
+// The GUIDs of the providers.
+var (
+	httpServiceGuid = windows.GUID{Data1: 0xdd5ef90a, Data2: 0x6398, Data3: 0x47a4, Data4: [8]byte{0xad, 0x34, 0x4d, 0xce, 0xcd, 0xef, 0x79, 0x5f}}
+	dnsClientGuid   = windows.GUID{Data1: 0x1c95126e, Data2: 0x7eea, Data3: 0x49a9, Data4: [8]byte{0xa3, 0xfe, 0xa3, 0x78, 0xb0, 0x3d, 0xdb, 0x4d}}
+)
+
+// The constants of evntrace.h and evntcons.h.
+const (
+	wnodeFlagTracedGuid            = 0x00020000
+	eventTraceRealTimeMode         = 0x00000100
+	eventTraceControlStop          = 1
+	eventControlCodeEnableProvider = 1
+	traceLevelVerbose              = 5
+	processTraceModeRealTime       = 0x00000100
+	processTraceModeEventRecord    = 0x10000000
+	propertyStruct                 = 0x1
+	// clientContextSystemTime makes the timestamps of the events FILETIME.
+	clientContextSystemTime = 2
+	// filetimeToUnixEpoch is the number of 100ns from 1601-01-01 to 1970-01-01.
+	filetimeToUnixEpoch  = 116444736000000000
+	maxSessionNameLength = 1024
+)
+
+// wnodeHeader is WNODE_HEADER.
+type wnodeHeader struct {
+	BufferSize        uint32
+	ProviderId        uint32
+	HistoricalContext uint64
+	TimeStamp         int64
+	Guid              windows.GUID
+	ClientContext     uint32
+	Flags             uint32
+}
+
+// eventTraceProperties is EVENT_TRACE_PROPERTIES, followed by the session name in the buffer.
+type eventTraceProperties struct {
+	Wnode               wnodeHeader
+	BufferSize          uint32
+	MinimumBuffers      uint32
+	MaximumBuffers      uint32
+	MaximumFileSize     uint32
+	LogFileMode         uint32
+	FlushTimer          uint32
+	EnableFlags         uint32
+	AgeLimit            int32
+	NumberOfBuffers     uint32
+	FreeBuffers         uint32
+	EventsLost          uint32
+	BuffersWritten      uint32
+	LogBuffersLost      uint32
+	RealTimeBuffersLost uint32
+	LoggerThreadId      windows.Handle
+	LogFileNameOffset   uint32
+	LoggerNameOffset    uint32
+}
+
+// eventTraceLogfile is EVENT_TRACE_LOGFILEW. CurrentEvent (EVENT_TRACE) and LogfileHeader
+// (TRACE_LOGFILE_HEADER) are not used in the real-time mode.
+type eventTraceLogfile struct {
+	LogFileName         *uint16
+	LoggerName          *uint16
+	CurrentTime         int64
+	BuffersRead         uint32
+	ProcessTraceMode    uint32
+	CurrentEvent        [88]byte
+	LogfileHeader       [280]byte
+	BufferCallback      uintptr
+	BufferSize          uint32
+	Filled              uint32
+	EventsLost          uint32
+	EventRecordCallback uintptr
+	IsKernelTrace       uint32
+	Context             uintptr
+}
+
+// eventDescriptor is EVENT_DESCRIPTOR.
+type eventDescriptor struct {
+	Id      uint16
+	Version uint8
+	Channel uint8
+	Level   uint8
+	Opcode  uint8
+	Task    uint16
+	Keyword uint64
+}
+
+// eventHeader is EVENT_HEADER.
+type eventHeader struct {
+	Size            uint16
+	HeaderType      uint16
+	Flags           uint16
+	EventProperty   uint16
+	ThreadId        uint32
+	ProcessId       uint32
+	TimeStamp       int64
+	ProviderId      windows.GUID
+	EventDescriptor eventDescriptor
+	ProcessorTime   uint64
+	ActivityId      windows.GUID
+}
+
+// eventRecord is EVENT_RECORD.
+type eventRecord struct {
+	EventHeader       eventHeader
+	BufferContext     uint32
+	ExtendedDataCount uint16
+	UserDataLength    uint16
+	ExtendedData      uintptr
+	UserData          uintptr
+	UserContext       uintptr
+}
+
+// traceEventInfo is TRACE_EVENT_INFO, followed by TopLevelPropertyCount of EVENT_PROPERTY_INFO.
+type traceEventInfo struct {
+	ProviderGuid          windows.GUID
+	EventGuid             windows.GUID
+	EventDescriptor       eventDescriptor
+	DecodingSource        uint32
+	ProviderNameOffset    uint32
+	LevelNameOffset       uint32
+	ChannelNameOffset     uint32
+	KeywordsNameOffset    uint32
+	TaskNameOffset        uint32
+	OpcodeNameOffset      uint32
+	EventMessageOffset    uint32
+	ProviderMessageOffset uint32
+	BinaryXMLOffset       uint32
+	BinaryXMLSize         uint32
+	EventNameOffset       uint32
+	EventAttributesOffset uint32
+	PropertyCount         uint32
+	TopLevelPropertyCount uint32
+	Flags                 uint32
+}
+
+// eventPropertyInfo is EVENT_PROPERTY_INFO with the union of the non-struct type.
+type eventPropertyInfo struct {
+	Flags         uint32
+	NameOffset    uint32
+	InType        uint16
+	OutType       uint16
+	MapNameOffset uint32
+	Count         uint16
+	Length        uint16
+	Reserved      uint32
+}
+
+// propertyDataDescriptor is PROPERTY_DATA_DESCRIPTOR.
+type propertyDataDescriptor struct {
+	PropertyName uint64
+	ArrayIndex   uint32
+	Reserved     uint32
+}
+
+// etwSession is a real-time session with the providers enabled.
+type etwSession struct {
+	name          *uint16
+	sessionHandle uint64
+	traceHandle   uint64
+	properties    []byte
+}
+
+func newTraceProperties(name string) ([]byte, error) {
+	if len(name) >= maxSessionNameLength/2 {
+		return nil, fmt.Errorf("the session name %q is too long", name)
+	}
+	size := unsafe.Sizeof(eventTraceProperties{})
+	buffer := make([]byte, size+maxSessionNameLength)
+	properties := (*eventTraceProperties)(unsafe.Pointer(&buffer[0]))
+	properties.Wnode.BufferSize = uint32(len(buffer))
+	properties.Wnode.ClientContext = clientContextSystemTime
+	properties.Wnode.Flags = wnodeFlagTracedGuid
+	properties.LogFileMode = eventTraceRealTimeMode
+	properties.LoggerNameOffset = uint32(size)
+	return buffer, nil
+}
+
+// startSession starts the session and enables the providers. The session of the same name left by the
+// previous run is stopped first, as the sessions outlive the processes starting them.
+func startSession(name string, providers []windows.GUID) (*etwSession, error) {
+	namePtr, err := windows.UTF16PtrFromString(name)
+	if err != nil {
+		return nil, err
+	}
+	session := &etwSession{name: namePtr}
+	if session.properties, err = newTraceProperties(name); err != nil {
+		return nil, err
+	}
+	ret := session.startTrace()
+	if ret == uintptr(windows.ERROR_ALREADY_EXISTS) {
+		stopProperties, _ := newTraceProperties(name)
+		_, _, _ = procControlTraceW.Call(0, uintptr(unsafe.Pointer(namePtr)), uintptr(unsafe.Pointer(&stopProperties[0])), eventTraceControlStop)
+		ret = session.startTrace()
+	}
+	if ret != 0 {
+		return nil, fmt.Errorf("StartTraceW: %w", windows.Errno(ret))
+	}
+	for _, provider := range providers {
+		provider := provider
+		ret, _, _ := procEnableTraceEx2.Call(uintptr(session.sessionHandle), uintptr(unsafe.Pointer(&provider)),
+			eventControlCodeEnableProvider, traceLevelVerbose, 0, 0, 0, 0)
+		if ret != 0 {
+			_ = session.stop()
+			return nil, fmt.Errorf("EnableTraceEx2: %w", windows.Errno(ret))
+		}
+	}
+	return session, nil
+}
+
+func (s *etwSession) startTrace() uintptr {
+	ret, _, _ := procStartTraceW.Call(uintptr(unsafe.Pointer(&s.sessionHandle)), uintptr(unsafe.Pointer(s.name)),
+		uintptr(unsafe.Pointer(&s.properties[0])))
+	return ret
+}
+
+// process delivers the events to the callback until the session is stopped.
+func (s *etwSession) process(callback func(record *eventRecord) uintptr) error {
+	logfile := eventTraceLogfile{
+		LoggerName:          s.name,
+		ProcessTraceMode:    processTraceModeRealTime | processTraceModeEventRecord,
+		EventRecordCallback: windows.NewCallback(callback),
+	}
+	handle, _, err := procOpenTraceW.Call(uintptr(unsafe.Pointer(&logfile)))
+	if uint64(handle) == invalidProcessTraceHandle {
+		return fmt.Errorf("OpenTraceW: %w", err)
+	}
+	s.traceHandle = uint64(handle)
+	// ProcessTrace blocks until the session is stopped or the trace is closed.
+	ret, _, _ := procProcessTrace.Call(uintptr(unsafe.Pointer(&s.traceHandle)), 1, 0, 0)
+	if ret != 0 && ret != uintptr(windows.ERROR_CANCELLED) {
+		return fmt.Errorf("ProcessTrace: %w", windows.Errno(ret))
+	}
+	return nil
+}
+
+func (s *etwSession) stop() error {
+	if s.traceHandle != 0 {
+		_, _, _ = procCloseTrace.Call(uintptr(s.traceHandle))
+	}
+	ret, _, _ := procControlTraceW.Call(uintptr(s.sessionHandle), 0, uintptr(unsafe.Pointer(&s.properties[0])), eventTraceControlStop)
+	if ret != 0 {
+		return fmt.Errorf("ControlTraceW: %w", windows.Errno(ret))
+	}
+	return nil
+}
+
+var errUnknownProvider = errors.New("unknown provider")
+
+// decodeEvent decodes the top-level properties of the event by TDH.
+func decodeEvent(record *eventRecord) (*etwEvent, error) {
+	evt := &etwEvent{
+		id:        record.EventHeader.EventDescriptor.Id,
+		pid:       record.EventHeader.ProcessId,
+		tid:       record.EventHeader.ThreadId,
+		timestamp: uint64(record.EventHeader.TimeStamp-filetimeToUnixEpoch) * 100,
+	}
+	switch record.EventHeader.ProviderId {
+	case httpServiceGuid:
+		evt.provider = providerHttpService
+	case dnsClientGuid:
+		evt.provider = providerDnsClient
+	default:
+		return nil, errUnknownProvider
+	}
+	var size uint32
+	ret, _, _ := procTdhGetEventInfo.Call(uintptr(unsafe.Pointer(record)), 0, 0, 0, uintptr(unsafe.Pointer(&size)))
+	if ret != uintptr(windows.ERROR_INSUFFICIENT_BUFFER) {
+		return nil, fmt.Errorf("TdhGetEventInformation: %w", windows.Errno(ret))
+	}
+	buffer := make([]byte, size)
+	ret, _, _ = procTdhGetEventInfo.Call(uintptr(unsafe.Pointer(record)), 0, 0, uintptr(unsafe.Pointer(&buffer[0])), uintptr(unsafe.Pointer(&size)))
+	if ret != 0 {
+		return nil, fmt.Errorf("TdhGetEventInformation: %w", windows.Errno(ret))
+	}
+	info := (*traceEventInfo)(unsafe.Pointer(&buffer[0]))
+	if info.OpcodeNameOffset != 0 {
+		evt.name = windows.UTF16PtrToString((*uint16)(unsafe.Pointer(&buffer[info.OpcodeNameOffset])))
+	} else if info.TaskNameOffset != 0 {
+		evt.name = windows.UTF16PtrToString((*uint16)(unsafe.Pointer(&buffer[info.TaskNameOffset])))
+	}
+	propertyInfos := unsafe.Slice((*eventPropertyInfo)(unsafe.Pointer(&buffer[unsafe.Sizeof(*info)])), info.TopLevelPropertyCount)
+	evt.properties = make(map[string]interface{}, len(propertyInfos))
+	for _, propertyInfo := range propertyInfos {
+		if propertyInfo.Flags&propertyStruct != 0 {
+			continue
+		}
+		descriptor := propertyDataDescriptor{
+			PropertyName: uint64(uintptr(unsafe.Pointer(&buffer[propertyInfo.NameOffset]))),
+			ArrayIndex:   ^uint32(0),
+		}
+		var propertySize uint32
+		ret, _, _ = procTdhGetPropertySize.Call(uintptr(unsafe.Pointer(record)), 0, 0, 1, uintptr(unsafe.Pointer(&descriptor)), uintptr(unsafe.Pointer(&propertySize)))
+		if ret != 0 || propertySize == 0 {
+			continue
+		}
+		data := make([]byte, propertySize)
+		ret, _, _ = procTdhGetProperty.Call(uintptr(unsafe.Pointer(record)), 0, 0, 1, uintptr(unsafe.Pointer(&descriptor)),
+			uintptr(propertySize), uintptr(unsafe.Pointer(&data[0])))
+		if ret != 0 {
+			continue
+		}
+		name := windows.UTF16PtrToString((*uint16)(unsafe.Pointer(&buffer[propertyInfo.NameOffset])))
+		evt.properties[name] = propertyValue(propertyInfo.InType, data)
+	}
+	runtime.KeepAlive(buffer)
+	return evt, nil
+}
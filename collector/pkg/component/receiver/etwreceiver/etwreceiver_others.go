@@ -0,0 +1,32 @@
+//go:build !windows || !(amd64 || arm64)
+// +build !windows !amd64,!arm64
+
+package etwreceiver
+
+import (
+	"errors"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component"
+	analyzerpackage "github.com/Kindling-project/kindling/collector/pkg/component/analyzer"
+	"github.com/Kindling-project/kindling/collector/pkg/component/receiver"
+)
+
+var errEtwNotSupported = errors.New("the etwreceiver is only supported on Windows")
+
+// EtwReceiver is a stub which keeps the collector buildable on the other platforms.
+type EtwReceiver struct{}
+
+func NewEtwReceiver(config interface{}, telemetry *component.TelemetryTools, analyzerManager *analyzerpackage.Manager) receiver.Receiver {
+	if _, ok := config.(*Config); !ok {
+		telemetry.Logger.Panicf("Cannot convert [%s] config", Type)
+	}
+	return &EtwReceiver{}
+}
+
+func (r *EtwReceiver) Start() error {
+	return errEtwNotSupported
+}
+
+func (r *EtwReceiver) Shutdown() error {
+	return nil
+}
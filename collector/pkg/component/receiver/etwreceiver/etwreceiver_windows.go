@@ -0,0 +1,151 @@
+//go:build windows && (amd64 || arm64)
+// +build windows
+// +build amd64 arm64
+
+package etwreceiver
+
+import (
+	"fmt"
+	"sync"
+
+	"go.uber.org/zap"
+	"golang.org/x/sys/windows"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component"
+	analyzerpackage "github.com/Kindling-project/kindling/collector/pkg/component/analyzer"
+	"github.com/Kindling-project/kindling/collector/pkg/component/receiver"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+)
+
+// activeReceiver is the receiver the ETW callback delivers the events to. The callback is a C function
+// pointer which can't carry a Go closure, and only one session is started by the collector.
+var (
+	activeReceiver      *EtwReceiver
+	activeReceiverMutex sync.RWMutex
+	eventRecordCallback = func(record *eventRecord) uintptr {
+		activeReceiverMutex.RLock()
+		r := activeReceiver
+		activeReceiverMutex.RUnlock()
+		if r != nil {
+			r.onEventRecord(record)
+		}
+		return 0
+	}
+)
+
+// EtwReceiver traces the events of the ETW providers and maps them into the KindlingEvents consumed by
+// the analyzers. See docs/windows_support.md for the events mapped.
+type EtwReceiver struct {
+	cfg             *Config
+	analyzerManager *analyzerpackage.Manager
+	telemetry       *component.TelemetryTools
+	session         *etwSession
+	mapper          *eventMapper
+	eventChannel    chan *model.KindlingEvent
+	stopCh          chan interface{}
+	shutdownWG      sync.WaitGroup
+}
+
+func NewEtwReceiver(config interface{}, telemetry *component.TelemetryTools, analyzerManager *analyzerpackage.Manager) receiver.Receiver {
+	cfg, ok := config.(*Config)
+	if !ok {
+		telemetry.Logger.Panicf("Cannot convert [%s] config", Type)
+	}
+	return &EtwReceiver{
+		cfg:             cfg,
+		analyzerManager: analyzerManager,
+		telemetry:       telemetry,
+		mapper:          newEventMapper(uint64(cfg.PendingTimeout)),
+		eventChannel:    make(chan *model.KindlingEvent, 3e5),
+		stopCh:          make(chan interface{}, 1),
+	}
+}
+
+func (r *EtwReceiver) Start() error {
+	r.telemetry.Logger.Info("Start EtwReceiver")
+	var providers []windows.GUID
+	if r.cfg.EnableHttp {
+		providers = append(providers, httpServiceGuid)
+	}
+	if r.cfg.EnableDns {
+		providers = append(providers, dnsClientGuid)
+	}
+	if len(providers) == 0 {
+		return fmt.Errorf("no provider of %s is enabled", Type)
+	}
+	session, err := startSession(r.cfg.SessionName, providers)
+	if err != nil {
+		return fmt.Errorf("fail to start the ETW session %s: %w", r.cfg.SessionName, err)
+	}
+	r.session = session
+	activeReceiverMutex.Lock()
+	activeReceiver = r
+	activeReceiverMutex.Unlock()
+
+	r.shutdownWG.Add(2)
+	go r.consumeEvents()
+	go func() {
+		defer r.shutdownWG.Done()
+		if err := session.process(eventRecordCallback); err != nil {
+			r.telemetry.Logger.Error("Failed to process the ETW session: ", zap.Error(err))
+		}
+	}()
+	return nil
+}
+
+// onEventRecord is called on the thread of ProcessTrace, which also serializes the access to the mapper.
+func (r *EtwReceiver) onEventRecord(record *eventRecord) {
+	evt, err := decodeEvent(record)
+	if err != nil {
+		if err != errUnknownProvider {
+			r.telemetry.Logger.Debug("Failed to decode the ETW event: ", zap.Error(err))
+		}
+		return
+	}
+	for _, kindlingEvent := range r.mapper.mapEvent(evt) {
+		select {
+		case r.eventChannel <- kindlingEvent:
+		case <-r.stopCh:
+			return
+		}
+	}
+}
+
+func (r *EtwReceiver) consumeEvents() {
+	defer r.shutdownWG.Done()
+	for {
+		select {
+		case <-r.stopCh:
+			return
+		case ev := <-r.eventChannel:
+			r.sendToNextConsumer(ev)
+		}
+	}
+}
+
+func (r *EtwReceiver) sendToNextConsumer(evt *model.KindlingEvent) {
+	analyzers := r.analyzerManager.GetConsumableAnalyzers(evt.Name)
+	costSampler := r.analyzerManager.CostSampler()
+	for _, analyzer := range analyzers {
+		start := costSampler.Start()
+		err := analyzer.ConsumeEvent(evt)
+		costSampler.Stop(analyzer.Type().String(), start)
+		if err != nil {
+			r.telemetry.Logger.Warn("Error sending event to next consumer: ", zap.Error(err))
+		}
+	}
+}
+
+func (r *EtwReceiver) Shutdown() error {
+	activeReceiverMutex.Lock()
+	activeReceiver = nil
+	activeReceiverMutex.Unlock()
+	var err error
+	if r.session != nil {
+		// Stopping the session returns ProcessTrace.
+		err = r.session.stop()
+	}
+	close(r.stopCh)
+	r.shutdownWG.Wait()
+	return err
+}
@@ -0,0 +1,118 @@
+package etwreceiver
+
+import (
+	"encoding/binary"
+	"math"
+	"unicode/utf16"
+)
+
+// The providers whose events are mapped into the KindlingEvents.
+const (
+	providerHttpService = "Microsoft-Windows-HttpService"
+	providerDnsClient   = "Microsoft-Windows-DNS-Client"
+)
+
+// etwEvent is an event decoded by TDH (Trace Data Helper), whose properties are keyed by their names.
+type etwEvent struct {
+	provider string
+	id       uint16
+	// name is the opcode name of the event, or the task name if the event has no opcode name.
+	name string
+	pid  uint32
+	tid  uint32
+	// timestamp is in nanoseconds since the Unix epoch.
+	timestamp  uint64
+	properties map[string]interface{}
+}
+
+func (e *etwEvent) uintProperty(name string) (uint64, bool) {
+	switch value := e.properties[name].(type) {
+	case uint64:
+		return value, true
+	case int64:
+		return uint64(value), true
+	}
+	return 0, false
+}
+
+func (e *etwEvent) stringProperty(name string) string {
+	value, _ := e.properties[name].(string)
+	return value
+}
+
+// The input types of the properties defined by TDH. See TDH_IN_TYPE in tdh.h.
+const (
+	tdhInTypeUnicodeString = 1
+	tdhInTypeAnsiString    = 2
+	tdhInTypeInt8          = 3
+	tdhInTypeUint8         = 4
+	tdhInTypeInt16         = 5
+	tdhInTypeUint16        = 6
+	tdhInTypeInt32         = 7
+	tdhInTypeUint32        = 8
+	tdhInTypeInt64         = 9
+	tdhInTypeUint64        = 10
+	tdhInTypeBoolean       = 13
+	tdhInTypeBinary        = 14
+	tdhInTypePointer       = 16
+	tdhInTypeHexInt32      = 20
+	tdhInTypeHexInt64      = 21
+)
+
+// propertyValue converts the data of a property in the input type. The integers are uint64 or int64,
+// the strings are string, and the others are the raw bytes. ETW is always little-endian.
+func propertyValue(inType uint16, data []byte) interface{} {
+	switch inType {
+	case tdhInTypeUnicodeString:
+		units := make([]uint16, 0, len(data)/2)
+		for i := 0; i+1 < len(data); i += 2 {
+			unit := binary.LittleEndian.Uint16(data[i:])
+			if unit == 0 {
+				break
+			}
+			units = append(units, unit)
+		}
+		return string(utf16.Decode(units))
+	case tdhInTypeAnsiString:
+		for i, b := range data {
+			if b == 0 {
+				return string(data[:i])
+			}
+		}
+		return string(data)
+	case tdhInTypeInt8, tdhInTypeInt16, tdhInTypeInt32, tdhInTypeInt64:
+		return signedValue(data)
+	case tdhInTypeUint8, tdhInTypeUint16, tdhInTypeUint32, tdhInTypeUint64, tdhInTypeBoolean,
+		tdhInTypePointer, tdhInTypeHexInt32, tdhInTypeHexInt64:
+		return unsignedValue(data)
+	}
+	return data
+}
+
+func unsignedValue(data []byte) uint64 {
+	switch len(data) {
+	case 1:
+		return uint64(data[0])
+	case 2:
+		return uint64(binary.LittleEndian.Uint16(data))
+	case 4:
+		return uint64(binary.LittleEndian.Uint32(data))
+	case 8:
+		return binary.LittleEndian.Uint64(data)
+	}
+	return math.MaxUint64
+}
+
+func signedValue(data []byte) int64 {
+	switch len(data) {
+	case 1:
+		return int64(int8(data[0]))
+	case 2:
+		return int64(int16(binary.LittleEndian.Uint16(data)))
+	case 4:
+		return int64(int32(binary.LittleEndian.Uint32(data)))
+	case 8:
+		return int64(binary.LittleEndian.Uint64(data))
+	}
+	return math.MinInt64
+}
@@ -0,0 +1,409 @@
+package etwreceiver
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+)
+
+// The events of Microsoft-Windows-HttpService by their opcode names. The requests are identified by the
+// request ids, while the events parsing the requests only have the addresses of the request objects,
+// which are linked to the ids by the events having both.
+const (
+	// httpRecvReq has RequestObj, RequestId, ConnectionId and RemoteAddr.
+	httpRecvReq = "RecvReq"
+	// httpParse has RequestObj, HttpVerb and Url.
+	httpParse = "Parse"
+	// httpDeliver has RequestObj, RequestId and Url.
+	httpDeliver = "Deliver"
+	// httpFastResp and httpRecvResp have RequestId, ConnectionId, StatusCode and Verb.
+	httpFastResp = "FastResp"
+	httpRecvResp = "RecvResp"
+)
+
+// The events of Microsoft-Windows-DNS-Client sent to and received from the DNS servers, which have
+// QueryName, QueryType and DnsServerIpAddress. The received ones also have ResponseStatus.
+const (
+	dnsQuerySent        = 3010
+	dnsResponseReceived = 3011
+)
+
+// httpVerbs are the names of HTTP_VERB in http.h, which is the type of HttpVerb.
+var httpVerbs = []string{"", "", "", "OPTIONS", "GET", "HEAD", "POST", "PUT", "DELETE", "TRACE", "CONNECT",
+	"TRACK", "MOVE", "COPY", "PROPFIND", "PROPPATCH", "MKCOL", "LOCK", "UNLOCK", "SEARCH"}
+
+// The Win32 errors of the DNS responses. The RCODEs are offset by dnsErrorRcodeBase, and no record
+// is found if the status is dnsInfoNoRecords.
+const (
+	dnsErrorRcodeBase = 9000
+	dnsErrorRcodeMax  = 9015
+	dnsInfoNoRecords  = 9501
+	dnsServerPort     = 53
+)
+
+type httpRequest struct {
+	requestObj   uint64
+	timestamp    uint64
+	pid          uint32
+	tid          uint32
+	connectionId uint64
+	remoteAddr   []byte
+	method       string
+	url          string
+}
+
+type dnsQueryKey struct {
+	pid       uint32
+	name      string
+	queryType uint64
+	server    string
+}
+
+type dnsQuery struct {
+	timestamp uint64
+	tid       uint32
+	id        uint16
+}
+
+// eventMapper converts the events of HTTP.sys and the DNS client into the read and write events of the
+// sockets, whose payloads are built from the properties, so the networkanalyzer parses them like the
+// events from the probe. The HTTP requests are on the server side of TCP, and the DNS queries are on
+// the client side of UDP.
+type eventMapper struct {
+	pendingTimeout uint64
+	// httpRequests are the requests waiting for their responses by the request ids.
+	httpRequests map[uint64]*httpRequest
+	// httpRequestIds link the request objects to the request ids.
+	httpRequestIds map[uint64]uint64
+	// httpParsed are the parsed requests whose ids are not known yet by the request objects.
+	httpParsed  map[uint64]*httpRequest
+	dnsQueries  map[dnsQueryKey]*dnsQuery
+	nextDnsId   uint16
+	lastExpired uint64
+}
+
+func newEventMapper(pendingTimeout uint64) *eventMapper {
+	return &eventMapper{
+		pendingTimeout: pendingTimeout,
+		httpRequests:   make(map[uint64]*httpRequest),
+		httpRequestIds: make(map[uint64]uint64),
+		httpParsed:     make(map[uint64]*httpRequest),
+		dnsQueries:     make(map[dnsQueryKey]*dnsQuery),
+	}
+}
+
+// mapEvent returns the KindlingEvents of the event, which are returned once the response is traced.
+func (m *eventMapper) mapEvent(evt *etwEvent) []*model.KindlingEvent {
+	if evt.timestamp > m.lastExpired+uint64(1e9) {
+		m.expire(evt.timestamp)
+		m.lastExpired = evt.timestamp
+	}
+	switch evt.provider {
+	case providerHttpService:
+		return m.mapHttpEvent(evt)
+	case providerDnsClient:
+		return m.mapDnsEvent(evt)
+	}
+	return nil
+}
+
+// expire drops the requests waiting for their responses longer than the timeout.
+func (m *eventMapper) expire(now uint64) {
+	for id, request := range m.httpRequests {
+		if request.timestamp+m.pendingTimeout < now {
+			delete(m.httpRequests, id)
+			delete(m.httpRequestIds, request.requestObj)
+		}
+	}
+	for obj, request := range m.httpParsed {
+		if request.timestamp+m.pendingTimeout < now {
+			delete(m.httpParsed, obj)
+			delete(m.httpRequestIds, obj)
+		}
+	}
+	for key, query := range m.dnsQueries {
+		if query.timestamp+m.pendingTimeout < now {
+			delete(m.dnsQueries, key)
+		}
+	}
+}
+
+func (m *eventMapper) mapHttpEvent(evt *etwEvent) []*model.KindlingEvent {
+	switch evt.name {
+	case httpRecvReq:
+		requestId, ok := evt.uintProperty("RequestId")
+		if !ok {
+			return nil
+		}
+		request := m.linkHttpRequest(evt, requestId)
+		request.timestamp = evt.timestamp
+		request.pid = evt.pid
+		request.tid = evt.tid
+		request.connectionId, _ = evt.uintProperty("ConnectionId")
+		request.remoteAddr, _ = evt.properties["RemoteAddr"].([]byte)
+	case httpParse:
+		requestObj, ok := evt.uintProperty("RequestObj")
+		if !ok {
+			return nil
+		}
+		request := m.httpParsed[requestObj]
+		if requestId, ok := m.httpRequestIds[requestObj]; ok {
+			request = m.httpRequests[requestId]
+		}
+		if request == nil {
+			request = &httpRequest{requestObj: requestObj, timestamp: evt.timestamp}
+			m.httpParsed[requestObj] = request
+		}
+		request.method = httpVerb(evt.properties["HttpVerb"])
+		request.url = evt.stringProperty("Url")
+	case httpDeliver:
+		if requestId, ok := evt.uintProperty("RequestId"); ok {
+			request := m.linkHttpRequest(evt, requestId)
+			if request.url == "" {
+				request.url = evt.stringProperty("Url")
+			}
+		}
+	case httpFastResp, httpRecvResp:
+		requestId, ok := evt.uintProperty("RequestId")
+		if !ok {
+			return nil
+		}
+		request, ok := m.httpRequests[requestId]
+		if !ok {
+			return nil
+		}
+		delete(m.httpRequests, requestId)
+		delete(m.httpRequestIds, request.requestObj)
+		statusCode, _ := evt.uintProperty("StatusCode")
+		if request.method == "" {
+			request.method = evt.stringProperty("Verb")
+		}
+		return newHttpEvents(request, evt.timestamp, evt.tid, statusCode)
+	}
+	return nil
+}
+
+// linkHttpRequest returns the request of the id, which takes the parsed request of the request object
+// if the event has the object.
+func (m *eventMapper) linkHttpRequest(evt *etwEvent, requestId uint64) *httpRequest {
+	request, ok := m.httpRequests[requestId]
+	if !ok {
+		request = &httpRequest{timestamp: evt.timestamp, pid: evt.pid, tid: evt.tid}
+		m.httpRequests[requestId] = request
+	}
+	requestObj, ok := evt.uintProperty("RequestObj")
+	if !ok {
+		return request
+	}
+	request.requestObj = requestObj
+	m.httpRequestIds[requestObj] = requestId
+	if parsed, ok := m.httpParsed[requestObj]; ok {
+		delete(m.httpParsed, requestObj)
+		request.method = parsed.method
+		request.url = parsed.url
+	}
+	return request
+}
+
+func httpVerb(value interface{}) string {
+	switch verb := value.(type) {
+	case string:
+		return verb
+	case uint64:
+		if verb < uint64(len(httpVerbs)) {
+			return httpVerbs[verb]
+		}
+	}
+	return ""
+}
+
+// newHttpEvents returns the read event of the request and the write event of the response. The remote
+// address is the client, and the local address is the host of the URL if it is an IP.
+func newHttpEvents(request *httpRequest, timestamp uint64, tid uint32, statusCode uint64) []*model.KindlingEvent {
+	clientIp, clientPort, ok := parseSockaddr(request.remoteAddr)
+	if !ok || request.method == "" {
+		return nil
+	}
+	path, host, serverIp, serverPort := parseUrl(request.url)
+	requestData := fmt.Sprintf("%s %s HTTP/1.1\r\nHost: %s\r\n\r\n", request.method, path, host)
+	responseData := fmt.Sprintf("HTTP/1.1 %d %s\r\nContent-Length: 0\r\n\r\n", statusCode, http.StatusText(int(statusCode)))
+	fd := int32(request.connectionId)
+	return []*model.KindlingEvent{
+		newNetEvent(constnames.ReadEvent, request.timestamp, request.pid, request.tid, fd, model.L4Proto_TCP, true,
+			clientIp, serverIp, clientPort, serverPort, []byte(requestData)),
+		newNetEvent(constnames.WriteEvent, timestamp, request.pid, tid, fd, model.L4Proto_TCP, true,
+			clientIp, serverIp, clientPort, serverPort, []byte(responseData)),
+	}
+}
+
+// parseUrl returns the path with the query, the host, and the IP and the port of the host. The IP is
+// 0.0.0.0 if the host is a name.
+func parseUrl(rawUrl string) (path string, host string, ip uint32, port uint32) {
+	u, err := url.Parse(rawUrl)
+	if err != nil || u.Host == "" {
+		return "/", "", 0, 80
+	}
+	path = u.RequestURI()
+	port = 80
+	if u.Scheme == "https" {
+		port = 443
+	}
+	if p, err := strconv.ParseUint(u.Port(), 10, 16); err == nil {
+		port = uint32(p)
+	}
+	if parsed := net.ParseIP(u.Hostname()).To4(); parsed != nil {
+		ip = binary.LittleEndian.Uint32(parsed)
+	}
+	return path, u.Host, ip, port
+}
+
+// parseSockaddr returns the IPv4 address and the port of SOCKADDR_IN, or SOCKADDR_IN6 with an IPv4-mapped
+// address. The IPs are in the layout of model.IPs.
+func parseSockaddr(sockaddr []byte) (ip uint32, port uint32, ok bool) {
+	const (
+		afInet  = 2
+		afInet6 = 23
+	)
+	if len(sockaddr) < 8 {
+		return 0, 0, false
+	}
+	port = uint32(binary.BigEndian.Uint16(sockaddr[2:4]))
+	switch binary.LittleEndian.Uint16(sockaddr) {
+	case afInet:
+		return binary.LittleEndian.Uint32(sockaddr[4:8]), port, true
+	case afInet6:
+		if len(sockaddr) < 24 {
+			return 0, 0, false
+		}
+		if mapped := net.IP(sockaddr[8:24]).To4(); mapped != nil {
+			return binary.LittleEndian.Uint32(mapped), port, true
+		}
+	}
+	return 0, 0, false
+}
+
+func (m *eventMapper) mapDnsEvent(evt *etwEvent) []*model.KindlingEvent {
+	if evt.id != dnsQuerySent && evt.id != dnsResponseReceived {
+		return nil
+	}
+	queryType, _ := evt.uintProperty("QueryType")
+	key := dnsQueryKey{
+		pid:       evt.pid,
+		name:      strings.TrimSuffix(evt.stringProperty("QueryName"), "."),
+		queryType: queryType,
+		server:    dnsServer(evt.properties["DnsServerIpAddress"]),
+	}
+	if evt.id == dnsQuerySent {
+		m.nextDnsId++
+		m.dnsQueries[key] = &dnsQuery{timestamp: evt.timestamp, tid: evt.tid, id: m.nextDnsId}
+		return nil
+	}
+	query, ok := m.dnsQueries[key]
+	if !ok {
+		return nil
+	}
+	delete(m.dnsQueries, key)
+	status, _ := evt.uintProperty("ResponseStatus")
+	var rcode uint16
+	switch {
+	case status == 0 || status == dnsInfoNoRecords:
+	case status > dnsErrorRcodeBase && status <= dnsErrorRcodeMax:
+		rcode = uint16(status - dnsErrorRcodeBase)
+	default:
+		// The query is timed out or failed without a response.
+		return nil
+	}
+	question, ok := newDnsQuestion(key.name, uint16(queryType))
+	if !ok {
+		return nil
+	}
+	serverIp := uint32(0)
+	if ip := net.ParseIP(key.server).To4(); ip != nil {
+		serverIp = binary.LittleEndian.Uint32(ip)
+	}
+	// The queries of the same process to the same server share the "socket", and they are told apart by the ids.
+	return []*model.KindlingEvent{
+		newNetEvent(constnames.SendToEvent, query.timestamp, evt.pid, query.tid, 0, model.L4Proto_UDP, false,
+			0, serverIp, 0, dnsServerPort, newDnsMessage(query.id, 0x0100, question)),
+		newNetEvent(constnames.RecvFromEvent, evt.timestamp, evt.pid, evt.tid, 0, model.L4Proto_UDP, false,
+			0, serverIp, 0, dnsServerPort, newDnsMessage(query.id, 0x8180|rcode, question)),
+	}
+}
+
+// dnsServer returns the IP of the server, which is either a string or a SOCKADDR.
+func dnsServer(value interface{}) string {
+	switch server := value.(type) {
+	case string:
+		return server
+	case []byte:
+		if ip, _, ok := parseSockaddr(server); ok {
+			return model.IPLong2String(ip)
+		}
+	}
+	return ""
+}
+
+// newDnsQuestion encodes the question section of the name and the type with the class IN.
+func newDnsQuestion(name string, queryType uint16) ([]byte, bool) {
+	if name == "" {
+		return nil, false
+	}
+	question := make([]byte, 0, len(name)+6)
+	for _, label := range strings.Split(name, ".") {
+		if len(label) == 0 || len(label) > 63 {
+			return nil, false
+		}
+		question = append(question, byte(len(label)))
+		question = append(question, label...)
+	}
+	question = append(question, 0)
+	question = binary.BigEndian.AppendUint16(question, queryType)
+	return binary.BigEndian.AppendUint16(question, 1), true
+}
+
+// newDnsMessage returns the message with the header and the question only.
+func newDnsMessage(id uint16, flags uint16, question []byte) []byte {
+	message := make([]byte, 12, 12+len(question))
+	binary.BigEndian.PutUint16(message[0:], id)
+	binary.BigEndian.PutUint16(message[2:], flags)
+	binary.BigEndian.PutUint16(message[4:], 1)
+	return append(message, question...)
+}
+
+func newNetEvent(name string, timestamp uint64, pid uint32, tid uint32, fd int32, protocol model.L4Proto, role bool,
+	sip uint32, dip uint32, sport uint32, dport uint32, data []byte) *model.KindlingEvent {
+	// The values of the user attributes are in the native byte order, and Windows is always little-endian.
+	res := make([]byte, 8)
+	binary.LittleEndian.PutUint64(res, uint64(len(data)))
+	return &model.KindlingEvent{
+		Source:       model.Source_SYSCALL_EXIT,
+		Timestamp:    timestamp,
+		Name:         name,
+		Category:     model.Category_CAT_NET,
+		ParamsNumber: 2,
+		UserAttributes: [16]model.KeyValue{
+			{Key: "res", ValueType: model.ValueType_INT64, Value: res},
+			{Key: "data", ValueType: model.ValueType_BYTEBUF, Value: data},
+		},
+		Ctx: model.Context{
+			ThreadInfo: model.Thread{Pid: pid, Tid: tid},
+			FdInfo: model.Fd{
+				Num:      fd,
+				TypeFd:   model.FDType_FD_IPV4_SOCK,
+				Protocol: protocol,
+				Role:     role,
+				Sip:      model.IPs{sip},
+				Dip:      model.IPs{dip},
+				Sport:    sport,
+				Dport:    dport,
+			},
+		},
+	}
+}
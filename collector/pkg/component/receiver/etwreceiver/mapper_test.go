@@ -0,0 +1,192 @@
+package etwreceiver
+
+import (
+	"encoding/binary"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network/protocol"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+)
+
+// sockaddrIn returns SOCKADDR_IN of 10.0.0.1:40000.
+func sockaddrIn() []byte {
+	sockaddr := make([]byte, 16)
+	binary.LittleEndian.PutUint16(sockaddr, 2)
+	binary.BigEndian.PutUint16(sockaddr[2:], 40000)
+	copy(sockaddr[4:], []byte{10, 0, 0, 1})
+	return sockaddr
+}
+
+func httpEvents() []*etwEvent {
+	return []*etwEvent{
+		{provider: providerHttpService, name: httpRecvReq, pid: 4, tid: 10, timestamp: 1000, properties: map[string]interface{}{
+			"RequestObj": uint64(0xff01), "RequestId": uint64(7), "ConnectionId": uint64(3), "RemoteAddr": sockaddrIn(),
+		}},
+		{provider: providerHttpService, name: httpParse, pid: 4, tid: 10, timestamp: 1100, properties: map[string]interface{}{
+			"RequestObj": uint64(0xff01), "HttpVerb": uint64(4), "Url": "http://10.0.0.2:8080/api/users?id=1",
+		}},
+		{provider: providerHttpService, name: httpDeliver, pid: 4, tid: 10, timestamp: 1200, properties: map[string]interface{}{
+			"RequestObj": uint64(0xff01), "RequestId": uint64(7), "Url": "http://10.0.0.2:8080/api/users?id=1",
+		}},
+		{provider: providerHttpService, name: httpFastResp, pid: 4, tid: 11, timestamp: 5000, properties: map[string]interface{}{
+			"RequestId": uint64(7), "ConnectionId": uint64(3), "StatusCode": uint64(404), "Verb": "GET",
+		}},
+	}
+}
+
+func dnsEvents() []*etwEvent {
+	return []*etwEvent{
+		{provider: providerDnsClient, id: dnsQuerySent, pid: 900, tid: 20, timestamp: 1000, properties: map[string]interface{}{
+			"QueryName": "example.com", "QueryType": uint64(1), "DnsServerIpAddress": "10.0.0.53",
+		}},
+		{provider: providerDnsClient, id: dnsResponseReceived, pid: 900, tid: 21, timestamp: 3000, properties: map[string]interface{}{
+			"QueryName": "example.com", "QueryType": uint64(1), "DnsServerIpAddress": "10.0.0.53", "ResponseStatus": uint64(9003),
+		}},
+	}
+}
+
+func TestMapHttpEvents(t *testing.T) {
+	mapper := newEventMapper(uint64(time.Minute))
+	var events []*model.KindlingEvent
+	for _, evt := range httpEvents() {
+		events = append(events, mapper.mapEvent(evt)...)
+	}
+	if !assert.Len(t, events, 2) {
+		return
+	}
+	request, response := events[0], events[1]
+	assert.Equal(t, constnames.ReadEvent, request.Name)
+	assert.Equal(t, uint64(1000), request.Timestamp)
+	assert.Equal(t, "GET /api/users?id=1 HTTP/1.1\r\nHost: 10.0.0.2:8080\r\n\r\n", string(request.GetData()))
+	assert.Equal(t, int64(len(request.GetData())), request.GetResVal())
+	assert.Equal(t, "10.0.0.1", request.GetSip())
+	assert.Equal(t, uint32(40000), request.GetSport())
+	assert.Equal(t, "10.0.0.2", request.GetDip())
+	assert.Equal(t, uint32(8080), request.GetDport())
+	isRequest, err := request.IsRequest()
+	assert.NoError(t, err)
+	assert.True(t, isRequest)
+
+	assert.Equal(t, constnames.WriteEvent, response.Name)
+	assert.Equal(t, uint64(5000), response.Timestamp)
+	assert.Equal(t, "HTTP/1.1 404 Not Found\r\nContent-Length: 0\r\n\r\n", string(response.GetData()))
+	assert.Equal(t, request.GetSocketKey(), response.GetSocketKey())
+	assert.Empty(t, mapper.httpRequests)
+	assert.Empty(t, mapper.httpRequestIds)
+}
+
+func TestMapDnsEvents(t *testing.T) {
+	mapper := newEventMapper(uint64(time.Minute))
+	var events []*model.KindlingEvent
+	for _, evt := range dnsEvents() {
+		events = append(events, mapper.mapEvent(evt)...)
+	}
+	if !assert.Len(t, events, 2) {
+		return
+	}
+	query, answer := events[0], events[1]
+	assert.Equal(t, constnames.SendToEvent, query.Name)
+	assert.Equal(t, []byte("\x00\x01\x01\x00\x00\x01\x00\x00\x00\x00\x00\x00\x07example\x03com\x00\x00\x01\x00\x01"), query.GetData())
+	assert.Equal(t, "10.0.0.53", query.GetDip())
+	assert.Equal(t, uint32(53), query.GetDport())
+	assert.Equal(t, constnames.RecvFromEvent, answer.Name)
+	// NXDOMAIN
+	assert.Equal(t, []byte{0x81, 0x83}, answer.GetData()[2:4])
+
+	// The queries timed out have no response.
+	events = nil
+	for _, evt := range dnsEvents() {
+		if evt.id == dnsResponseReceived {
+			evt.properties["ResponseStatus"] = uint64(1460)
+		}
+		events = append(events, mapper.mapEvent(evt)...)
+	}
+	assert.Empty(t, events)
+}
+
+func TestMapperExpire(t *testing.T) {
+	mapper := newEventMapper(uint64(time.Second))
+	events := httpEvents()
+	for _, evt := range events[:3] {
+		mapper.mapEvent(evt)
+	}
+	assert.Len(t, mapper.httpRequests, 1)
+	// The response comes after the timeout.
+	response := events[3]
+	response.timestamp = 1000 + uint64(2*time.Second)
+	assert.Empty(t, mapper.mapEvent(response))
+	assert.Empty(t, mapper.httpRequests)
+	assert.Empty(t, mapper.httpRequestIds)
+}
+
+func TestPropertyValue(t *testing.T) {
+	assert.Equal(t, "/api", propertyValue(tdhInTypeUnicodeString, []byte{'/', 0, 'a', 0, 'p', 0, 'i', 0, 0, 0}))
+	assert.Equal(t, "GET", propertyValue(tdhInTypeAnsiString, []byte("GET\x00")))
+	assert.Equal(t, uint64(404), propertyValue(tdhInTypeUint16, []byte{0x94, 0x01}))
+	assert.Equal(t, int64(-1), propertyValue(tdhInTypeInt32, []byte{0xff, 0xff, 0xff, 0xff}))
+	assert.Equal(t, uint64(0xff01), propertyValue(tdhInTypePointer, []byte{0x01, 0xff, 0, 0, 0, 0, 0, 0}))
+	assert.Equal(t, []byte{1, 2}, propertyValue(tdhInTypeBinary, []byte{1, 2}))
+}
+
+type recordConsumer struct {
+	mutex      sync.Mutex
+	dataGroups []*model.DataGroup
+}
+
+func (c *recordConsumer) Consume(dataGroup *model.DataGroup) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.dataGroups = append(c.dataGroups, dataGroup.Clone())
+	return nil
+}
+
+func (c *recordConsumer) protocols() map[string]*model.DataGroup {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	ret := make(map[string]*model.DataGroup)
+	for _, dataGroup := range c.dataGroups {
+		ret[dataGroup.Labels.GetStringValue(constlabels.Protocol)] = dataGroup
+	}
+	return ret
+}
+
+// TestNetworkAnalyzer checks the mapped events are analyzed as the requests by the networkanalyzer.
+func TestNetworkAnalyzer(t *testing.T) {
+	c := &recordConsumer{}
+	cfg := network.NewDefaultConfig()
+	cfg.EnableConntrack = false
+	// The record of HTTP is sent soon after the response as the connection is not reused.
+	cfg.FdReuseTimeout = 1
+	na := network.NewNetworkAnalyzer(cfg, component.NewDefaultTelemetryTools(), []consumer.Consumer{c})
+	assert.NoError(t, na.Start())
+	defer na.Shutdown()
+
+	mapper := newEventMapper(uint64(time.Minute))
+	now := uint64(time.Now().UnixNano())
+	for _, evt := range append(httpEvents(), dnsEvents()...) {
+		evt.timestamp += now
+		for _, kindlingEvent := range mapper.mapEvent(evt) {
+			assert.NoError(t, na.ConsumeEvent(kindlingEvent))
+		}
+	}
+	assert.Eventually(t, func() bool { return len(c.protocols()) == 2 }, 5*time.Second, 100*time.Millisecond)
+	records := c.protocols()
+	if http, ok := records[protocol.HTTP]; assert.True(t, ok) {
+		assert.Equal(t, "/api/users?id=1", http.Labels.GetStringValue(constlabels.HttpUrl))
+		assert.Equal(t, int64(404), http.Labels.GetIntValue(constlabels.HttpStatusCode))
+		assert.Equal(t, "10.0.0.2", http.Labels.GetStringValue(constlabels.DstIp))
+	}
+	if dns, ok := records[protocol.DNS]; assert.True(t, ok) {
+		assert.Equal(t, "example.com.", dns.Labels.GetStringValue(constlabels.DnsDomain))
+		assert.Equal(t, int64(3), dns.Labels.GetIntValue(constlabels.DnsRcode))
+	}
+}
@@ -0,0 +1,23 @@
+package grpcreceiver
+
+import "github.com/Kindling-project/kindling/collector/pkg/forward"
+
+type Config struct {
+	// ListenAddress is where the aggregator receives the records from the node agents.
+	ListenAddress string `mapstructure:"listen_address"`
+	// Window is the number of the batches an agent could send before they are consumed.
+	Window uint32 `mapstructure:"window"`
+	// SessionTimeout is how long an agent could resume the stream without sending the consumed
+	// batches again after disconnecting. The unit is seconds.
+	SessionTimeout int                `mapstructure:"session_timeout"`
+	TLS            *forward.TLSConfig `mapstructure:"tls"`
+}
+
+func NewDefaultConfig() *Config {
+	return &Config{
+		ListenAddress:  ":9600",
+		Window:         16,
+		SessionTimeout: 300,
+		TLS:            &forward.TLSConfig{},
+	}
+}
@@ -0,0 +1,73 @@
+package grpcreceiver
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer"
+	"github.com/Kindling-project/kindling/collector/pkg/forward"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+)
+
+const Type = "grpcreceiver"
+
+// GrpcReceiver receives the records forwarded by the node agents and passes them to the consumer.
+// It is used by the aggregator instead of the CgoReceiver.
+type GrpcReceiver struct {
+	config    *Config
+	server    *forward.Server
+	consumer  consumer.Consumer
+	telemetry *component.TelemetryTools
+}
+
+func New(config interface{}, telemetry *component.TelemetryTools, consumer consumer.Consumer) (*GrpcReceiver, error) {
+	cfg, _ := config.(*Config)
+	r := &GrpcReceiver{
+		config:    cfg,
+		consumer:  consumer,
+		telemetry: telemetry,
+	}
+	server, err := forward.NewServer(r, forward.ServerConfig{
+		TLS:            cfg.TLS,
+		Window:         cfg.Window,
+		SessionTimeout: time.Duration(cfg.SessionTimeout) * time.Second,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fail to create the gRPC server: %w", err)
+	}
+	r.server = server
+	return r, nil
+}
+
+func (r *GrpcReceiver) Start() error {
+	listener, err := net.Listen("tcp", r.config.ListenAddress)
+	if err != nil {
+		return fmt.Errorf("fail to listen on [%s]: %w", r.config.ListenAddress, err)
+	}
+	r.telemetry.Logger.Infof("Start GrpcReceiver on %s", listener.Addr().String())
+	go func() {
+		if err := r.server.Serve(listener); err != nil {
+			r.telemetry.Logger.Errorf("GrpcReceiver stopped serving: %v", err)
+		}
+	}()
+	return nil
+}
+
+func (r *GrpcReceiver) Shutdown() error {
+	r.server.Stop()
+	return nil
+}
+
+// Consume is called by the server for each batch.
+func (r *GrpcReceiver) Consume(hello *forward.Hello, records []*forward.Record) {
+	for _, record := range records {
+		dataGroup := record.DataGroup()
+		dataGroup.Labels.AddStringValue(constlabels.AgentNodeIp, hello.NodeIp)
+		dataGroup.Labels.AddStringValue(constlabels.AgentNodeName, hello.NodeName)
+		if err := r.consumer.Consume(dataGroup); err != nil {
+			r.telemetry.Logger.Debugf("Error happened when consuming the forwarded record: %v", err)
+		}
+	}
+}
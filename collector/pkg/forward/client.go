@@ -0,0 +1,240 @@
+package forward
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+const (
+	minReconnectInterval = time.Second
+	maxReconnectInterval = 30 * time.Second
+)
+
+var ErrClientClosed = errors.New("the forward client is closed")
+
+type ClientConfig struct {
+	// Endpoint is the address of the aggregator.
+	Endpoint string
+	TLS      *TLSConfig
+	// NodeName and NodeIp are the node of the agent, which are sent in the hello.
+	NodeName string
+	NodeIp   string
+	Logger   *zap.Logger
+}
+
+// Client is the agent side of the Forwarder service. It keeps a stream to the aggregator, and
+// reconnects with the resume token once the stream breaks.
+type Client struct {
+	conn   *grpc.ClientConn
+	hello  Hello
+	logger *zap.Logger
+	done   chan struct{}
+
+	mutex sync.Mutex
+	// changed is closed and replaced once the pending batches or the window are changed.
+	changed      chan struct{}
+	closed       bool
+	token        string
+	nextSequence uint64
+	// window is 0 until the first stream is established, so no batch is accepted before that.
+	window uint64
+	// pending are the batches not acknowledged yet in the order of their sequences, including the
+	// ones not sent.
+	pending []*Batch
+}
+
+func NewClient(config ClientConfig) (*Client, error) {
+	creds, err := config.TLS.clientCredentials()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := grpc.Dial(config.Endpoint, grpc.WithTransportCredentials(creds), grpc.WithDefaultCallOptions(grpc.ForceCodec(protoCodec{})))
+	if err != nil {
+		return nil, fmt.Errorf("fail to connect to the aggregator [%s]: %w", config.Endpoint, err)
+	}
+	logger := config.Logger
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	c := &Client{
+		conn:    conn,
+		hello:   Hello{NodeName: config.NodeName, NodeIp: config.NodeIp, SchemaVersion: SchemaVersion},
+		logger:  logger,
+		done:    make(chan struct{}),
+		changed: make(chan struct{}),
+	}
+	go c.run()
+	return c, nil
+}
+
+// Send queues the records as a batch. It blocks while the window of the aggregator is used up, until
+// the ctx is done.
+func (c *Client) Send(ctx context.Context, records []*Record) error {
+	for {
+		c.mutex.Lock()
+		if c.closed {
+			c.mutex.Unlock()
+			return ErrClientClosed
+		}
+		if uint64(len(c.pending)) < c.window {
+			c.nextSequence++
+			c.pending = append(c.pending, &Batch{Sequence: c.nextSequence, Records: records})
+			c.notifyLocked()
+			c.mutex.Unlock()
+			return nil
+		}
+		changed := c.changed
+		c.mutex.Unlock()
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return fmt.Errorf("the window of the aggregator is used up: %w", ctx.Err())
+		}
+	}
+}
+
+// Pending returns the number of the batches not acknowledged yet.
+func (c *Client) Pending() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return len(c.pending)
+}
+
+// Close drops the pending batches.
+func (c *Client) Close() error {
+	c.mutex.Lock()
+	if c.closed {
+		c.mutex.Unlock()
+		return nil
+	}
+	c.closed = true
+	close(c.done)
+	c.notifyLocked()
+	c.mutex.Unlock()
+	return c.conn.Close()
+}
+
+func (c *Client) notifyLocked() {
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
+
+func (c *Client) run() {
+	interval := minReconnectInterval
+	for {
+		established, err := c.stream()
+		select {
+		case <-c.done:
+			return
+		default:
+		}
+		if established {
+			interval = minReconnectInterval
+		}
+		c.logger.Warn("The stream to the aggregator breaks, and will reconnect", zap.Duration("after", interval), zap.Error(err))
+		select {
+		case <-time.After(interval):
+		case <-c.done:
+			return
+		}
+		if interval *= 2; interval > maxReconnectInterval {
+			interval = maxReconnectInterval
+		}
+	}
+}
+
+// stream sends the pending batches through a new stream until it breaks. It returns whether the stream
+// is established.
+func (c *Client) stream() (bool, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-c.done:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	stream, err := c.conn.NewStream(ctx, &serviceDesc.Streams[0], streamMethod)
+	if err != nil {
+		return false, err
+	}
+	c.mutex.Lock()
+	hello := c.hello
+	hello.ResumeToken = c.token
+	c.mutex.Unlock()
+	if err = stream.SendMsg(&Frame{Hello: &hello}); err != nil {
+		return false, err
+	}
+	control := new(Control)
+	if err = stream.RecvMsg(control); err != nil {
+		return false, err
+	}
+	if control.ResumeToken != "" && control.ResumeToken != hello.ResumeToken {
+		c.logger.Info("A new session is started with the aggregator", zap.Uint64("ackedSequence", control.AckedSequence))
+	}
+	c.acknowledge(control)
+
+	errCh := make(chan error, 1)
+	go func() {
+		for {
+			control := new(Control)
+			if err := stream.RecvMsg(control); err != nil {
+				errCh <- err
+				return
+			}
+			c.acknowledge(control)
+		}
+	}()
+	// The batches after the acked sequence are sent again in the new stream.
+	sent := control.AckedSequence
+	for {
+		c.mutex.Lock()
+		var batches []*Batch
+		for _, batch := range c.pending {
+			if batch.Sequence > sent {
+				batches = append(batches, batch)
+			}
+		}
+		changed := c.changed
+		c.mutex.Unlock()
+		for _, batch := range batches {
+			if err = stream.SendMsg(&Frame{Batch: batch}); err != nil {
+				return true, err
+			}
+			sent = batch.Sequence
+		}
+		if len(batches) > 0 {
+			continue
+		}
+		select {
+		case <-changed:
+		case err = <-errCh:
+			return true, err
+		case <-c.done:
+			return true, ErrClientClosed
+		}
+	}
+}
+
+// acknowledge drops the pending batches consumed by the aggregator and updates the window.
+func (c *Client) acknowledge(control *Control) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if control.ResumeToken != "" {
+		c.token = control.ResumeToken
+	}
+	c.window = uint64(control.Window)
+	i := 0
+	for i < len(c.pending) && c.pending[i].Sequence <= control.AckedSequence {
+		i++
+	}
+	c.pending = c.pending[i:]
+	c.notifyLocked()
+}
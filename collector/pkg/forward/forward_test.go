@@ -0,0 +1,72 @@
+package forward
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constvalues"
+)
+
+func newTestDataGroup() *model.DataGroup {
+	labels := model.NewAttributeMap()
+	labels.AddStringValue(constlabels.SrcIp, "10.0.0.1")
+	labels.AddIntValue(constlabels.DstPort, 8080)
+	labels.AddBoolValue(constlabels.IsServer, true)
+	return model.NewDataGroup(constnames.NetRequestMetricGroupName, labels, 1000,
+		model.NewIntMetric(constvalues.RequestTotalTime, 200),
+		model.NewHistogramMetric("histogram", &model.Histogram{
+			Sum:                10,
+			Count:              2,
+			ExplicitBoundaries: []int64{5, 10},
+			BucketCounts:       []uint64{1, 2},
+		}))
+}
+
+func TestRecord_DataGroup(t *testing.T) {
+	dataGroup := newTestDataGroup()
+	record := NewRecord(dataGroup)
+	// The record doesn't reference the dataGroup.
+	histogram, _ := dataGroup.GetMetric("histogram")
+	histogram.GetHistogram().BucketCounts[0] = 0
+
+	got := record.DataGroup()
+	assert.Equal(t, constnames.NetRequestMetricGroupName, got.Name)
+	assert.Equal(t, uint64(1000), got.Timestamp)
+	assert.Equal(t, "10.0.0.1", got.Labels.GetStringValue(constlabels.SrcIp))
+	assert.Equal(t, int64(8080), got.Labels.GetIntValue(constlabels.DstPort))
+	assert.True(t, got.Labels.GetBoolValue(constlabels.IsServer))
+	totalTime, ok := got.GetMetric(constvalues.RequestTotalTime)
+	require.True(t, ok)
+	assert.Equal(t, int64(200), totalTime.GetInt().Value)
+	histogram, ok = got.GetMetric("histogram")
+	require.True(t, ok)
+	assert.Equal(t, []uint64{1, 2}, histogram.GetHistogram().BucketCounts)
+}
+
+func TestProtoCodec(t *testing.T) {
+	codec := protoCodec{}
+	data, err := codec.Marshal(&Frame{Batch: &Batch{Sequence: 3, Records: []*Record{NewRecord(newTestDataGroup())}}})
+	require.NoError(t, err)
+	frame := new(Frame)
+	require.NoError(t, codec.Unmarshal(data, frame))
+	require.NotNil(t, frame.Batch)
+	assert.Equal(t, uint64(3), frame.Batch.Sequence)
+	got := frame.Batch.Records[0].DataGroup()
+	assert.Equal(t, "10.0.0.1", got.Labels.GetStringValue(constlabels.SrcIp))
+	histogram, ok := got.GetMetric("histogram")
+	require.True(t, ok)
+	assert.Equal(t, []int64{5, 10}, histogram.GetHistogram().ExplicitBoundaries)
+
+	_, err = codec.Marshal(struct{}{})
+	assert.Error(t, err)
+}
+
+func TestTLSConfig_MissingFiles(t *testing.T) {
+	_, err := NewServer(nil, ServerConfig{TLS: &TLSConfig{Enable: true, CertFile: "not-exist.crt", KeyFile: "not-exist.key"}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,100 @@
+package forward
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLoad sends the batches from many agents concurrently while the connections are dropped, and
+// checks every record is consumed once in order.
+func TestLoad(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skip the load test in the short mode")
+	}
+	const (
+		agents          = 16
+		batchesPerAgent = 200
+		recordsPerBatch = 50
+	)
+	handler := newRecordingHandler()
+	proxy := newDropProxy(t, startTestServer(t, handler, 8))
+	clients := make([]*Client, agents)
+	for i := range clients {
+		clients[i] = newTestClient(t, proxy.listener.Addr().String(), "node-"+strconv.Itoa(i))
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	var wg sync.WaitGroup
+	for i, client := range clients {
+		wg.Add(1)
+		go func(i int, client *Client) {
+			defer wg.Done()
+			for j := 0; j < batchesPerAgent; j++ {
+				assert.NoError(t, client.Send(ctx, newNamedRecords(strconv.Itoa(j)+"-", recordsPerBatch)))
+			}
+		}(i, client)
+	}
+	stopDropping := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(50 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				proxy.drop()
+			case <-stopDropping:
+				return
+			}
+		}
+	}()
+	wg.Wait()
+	close(stopDropping)
+
+	for i, client := range clients {
+		require.Eventually(t, func() bool {
+			return client.Pending() == 0
+		}, 30*time.Second, 10*time.Millisecond)
+		records := handler.get("node-" + strconv.Itoa(i))
+		require.Len(t, records, batchesPerAgent*recordsPerBatch)
+		for j := 0; j < batchesPerAgent; j++ {
+			assert.Equal(t, strconv.Itoa(j)+"-0", records[j*recordsPerBatch])
+		}
+	}
+}
+
+func BenchmarkClient_Send(b *testing.B) {
+	handler := &countingHandler{}
+	client := newTestClient(b, startTestServer(b, handler, 16), "node-1")
+	records := make([]*Record, 100)
+	for i := range records {
+		records[i] = NewRecord(newTestDataGroup())
+	}
+	ctx := context.Background()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := client.Send(ctx, records); err != nil {
+			b.Fatal(err)
+		}
+	}
+	for client.Pending() > 0 {
+		time.Sleep(time.Millisecond)
+	}
+	b.ReportMetric(float64(b.N*len(records))/b.Elapsed().Seconds(), "records/s")
+}
+
+type countingHandler struct {
+	mutex sync.Mutex
+	count int
+}
+
+func (h *countingHandler) Consume(_ *Hello, records []*Record) {
+	h.mutex.Lock()
+	h.count += len(records)
+	h.mutex.Unlock()
+}
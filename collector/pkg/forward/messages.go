@@ -0,0 +1,86 @@
+package forward
+
+import (
+	proto "github.com/gogo/protobuf/proto"
+)
+
+// SchemaVersion is the version of the Record schema sent by the agents. It is increased once the schema
+// changes incompatibly, and the servers refuse the agents sending a version newer than theirs.
+const SchemaVersion uint32 = 1
+
+// The messages of forward.proto. They are encoded by gogo/protobuf through the struct tags, so keep
+// the tags in sync with the field numbers in forward.proto.
+
+type Frame struct {
+	Hello *Hello `protobuf:"bytes,1,opt,name=hello,proto3" json:"hello,omitempty"`
+	Batch *Batch `protobuf:"bytes,2,opt,name=batch,proto3" json:"batch,omitempty"`
+}
+
+func (m *Frame) Reset()         { *m = Frame{} }
+func (m *Frame) String() string { return proto.CompactTextString(m) }
+func (*Frame) ProtoMessage()    {}
+
+type Hello struct {
+	NodeName    string `protobuf:"bytes,1,opt,name=node_name,json=nodeName,proto3" json:"node_name,omitempty"`
+	NodeIp      string `protobuf:"bytes,2,opt,name=node_ip,json=nodeIp,proto3" json:"node_ip,omitempty"`
+	ResumeToken string `protobuf:"bytes,3,opt,name=resume_token,json=resumeToken,proto3" json:"resume_token,omitempty"`
+	// SchemaVersion is 0 for the agents sending the first version before it is introduced.
+	SchemaVersion uint32 `protobuf:"varint,4,opt,name=schema_version,json=schemaVersion,proto3" json:"schema_version,omitempty"`
+}
+
+func (m *Hello) Reset()         { *m = Hello{} }
+func (m *Hello) String() string { return proto.CompactTextString(m) }
+func (*Hello) ProtoMessage()    {}
+
+type Batch struct {
+	Sequence uint64    `protobuf:"varint,1,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	Records  []*Record `protobuf:"bytes,2,rep,name=records,proto3" json:"records,omitempty"`
+}
+
+func (m *Batch) Reset()         { *m = Batch{} }
+func (m *Batch) String() string { return proto.CompactTextString(m) }
+func (*Batch) ProtoMessage()    {}
+
+type Control struct {
+	ResumeToken   string `protobuf:"bytes,1,opt,name=resume_token,json=resumeToken,proto3" json:"resume_token,omitempty"`
+	AckedSequence uint64 `protobuf:"varint,2,opt,name=acked_sequence,json=ackedSequence,proto3" json:"acked_sequence,omitempty"`
+	Window        uint32 `protobuf:"varint,3,opt,name=window,proto3" json:"window,omitempty"`
+}
+
+func (m *Control) Reset()         { *m = Control{} }
+func (m *Control) String() string { return proto.CompactTextString(m) }
+func (*Control) ProtoMessage()    {}
+
+type Record struct {
+	Name         string            `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Timestamp    uint64            `protobuf:"varint,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	StringLabels map[string]string `protobuf:"bytes,3,rep,name=string_labels,json=stringLabels,proto3" json:"string_labels,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	IntLabels    map[string]int64  `protobuf:"bytes,4,rep,name=int_labels,json=intLabels,proto3" json:"int_labels,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+	BoolLabels   map[string]bool   `protobuf:"bytes,5,rep,name=bool_labels,json=boolLabels,proto3" json:"bool_labels,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+	Metrics      []*Metric         `protobuf:"bytes,6,rep,name=metrics,proto3" json:"metrics,omitempty"`
+}
+
+func (m *Record) Reset()         { *m = Record{} }
+func (m *Record) String() string { return proto.CompactTextString(m) }
+func (*Record) ProtoMessage()    {}
+
+type Metric struct {
+	Name      string     `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Int       int64      `protobuf:"varint,2,opt,name=int,proto3" json:"int,omitempty"`
+	Histogram *Histogram `protobuf:"bytes,3,opt,name=histogram,proto3" json:"histogram,omitempty"`
+}
+
+func (m *Metric) Reset()         { *m = Metric{} }
+func (m *Metric) String() string { return proto.CompactTextString(m) }
+func (*Metric) ProtoMessage()    {}
+
+type Histogram struct {
+	Sum                int64    `protobuf:"varint,1,opt,name=sum,proto3" json:"sum,omitempty"`
+	Count              uint64   `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	ExplicitBoundaries []int64  `protobuf:"varint,3,rep,packed,name=explicit_boundaries,json=explicitBoundaries,proto3" json:"explicit_boundaries,omitempty"`
+	BucketCounts       []uint64 `protobuf:"varint,4,rep,packed,name=bucket_counts,json=bucketCounts,proto3" json:"bucket_counts,omitempty"`
+}
+
+func (m *Histogram) Reset()         { *m = Histogram{} }
+func (m *Histogram) String() string { return proto.CompactTextString(m) }
+func (*Histogram) ProtoMessage()    {}
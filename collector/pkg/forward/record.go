@@ -0,0 +1,77 @@
+package forward
+
+import (
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+)
+
+// NewRecord converts the dataGroup to a Record. The dataGroup is not referenced by the Record, so it
+// could be reused after the conversion.
+func NewRecord(dataGroup *model.DataGroup) *Record {
+	record := &Record{
+		Name:      dataGroup.Name,
+		Timestamp: dataGroup.Timestamp,
+		Metrics:   make([]*Metric, 0, len(dataGroup.Metrics)),
+	}
+	for key, value := range dataGroup.Labels.GetValues() {
+		switch value.Type() {
+		case model.StringAttributeValueType:
+			if record.StringLabels == nil {
+				record.StringLabels = make(map[string]string)
+			}
+			record.StringLabels[key] = dataGroup.Labels.GetStringValue(key)
+		case model.IntAttributeValueType:
+			if record.IntLabels == nil {
+				record.IntLabels = make(map[string]int64)
+			}
+			record.IntLabels[key] = dataGroup.Labels.GetIntValue(key)
+		case model.BooleanAttributeValueType:
+			if record.BoolLabels == nil {
+				record.BoolLabels = make(map[string]bool)
+			}
+			record.BoolLabels[key] = dataGroup.Labels.GetBoolValue(key)
+		}
+	}
+	for _, metric := range dataGroup.Metrics {
+		switch metric.DataType() {
+		case model.IntMetricType:
+			record.Metrics = append(record.Metrics, &Metric{Name: metric.Name, Int: metric.GetInt().Value})
+		case model.HistogramMetricType:
+			histogram := metric.GetHistogram()
+			record.Metrics = append(record.Metrics, &Metric{Name: metric.Name, Histogram: &Histogram{
+				Sum:                histogram.Sum,
+				Count:              histogram.Count,
+				ExplicitBoundaries: append([]int64(nil), histogram.ExplicitBoundaries...),
+				BucketCounts:       append([]uint64(nil), histogram.BucketCounts...),
+			}})
+		}
+	}
+	return record
+}
+
+// DataGroup converts the record back to a DataGroup.
+func (r *Record) DataGroup() *model.DataGroup {
+	labels := model.NewAttributeMap()
+	for key, value := range r.StringLabels {
+		labels.AddStringValue(key, value)
+	}
+	for key, value := range r.IntLabels {
+		labels.AddIntValue(key, value)
+	}
+	for key, value := range r.BoolLabels {
+		labels.AddBoolValue(key, value)
+	}
+	metrics := make([]*model.Metric, 0, len(r.Metrics))
+	for _, metric := range r.Metrics {
+		if metric.Histogram != nil {
+			metrics = append(metrics, model.NewHistogramMetric(metric.Name, &model.Histogram{
+				Sum:                metric.Histogram.Sum,
+				Count:              metric.Histogram.Count,
+				ExplicitBoundaries: metric.Histogram.ExplicitBoundaries,
+				BucketCounts:       metric.Histogram.BucketCounts,
+			}))
+		} else {
+			metrics = append(metrics, model.NewIntMetric(metric.Name, metric.Int))
+		}
+	}
+	return model.NewDataGroup(r.Name, labels, r.Timestamp, metrics...)
+}
@@ -0,0 +1,170 @@
+package forward
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Handler consumes the records received by the aggregator.
+type Handler interface {
+	// Consume is called with the batches of an agent in the order of their sequences. A batch is
+	// acknowledged after Consume returns, so a slow Handler slows down the agents instead of buffering
+	// the records in the aggregator.
+	Consume(hello *Hello, records []*Record)
+}
+
+type ServerConfig struct {
+	TLS *TLSConfig
+	// Window is the number of the batches an agent could send before they are acknowledged.
+	Window uint32
+	// SessionTimeout is how long the acked sequence of an agent is kept after it stops sending, within
+	// which the agent could resume without sending the consumed batches again.
+	SessionTimeout time.Duration
+}
+
+// Server is the aggregator side of the Forwarder service.
+type Server struct {
+	config     ServerConfig
+	handler    Handler
+	grpcServer *grpc.Server
+
+	mutex    sync.Mutex
+	sessions map[string]*session
+}
+
+// session is the state of an agent kept across its streams.
+type session struct {
+	mutex      sync.Mutex
+	acked      uint64
+	lastActive time.Time
+}
+
+func NewServer(handler Handler, config ServerConfig) (*Server, error) {
+	creds, err := config.TLS.serverCredentials()
+	if err != nil {
+		return nil, err
+	}
+	if config.Window == 0 {
+		config.Window = 1
+	}
+	s := &Server{
+		config:   config,
+		handler:  handler,
+		sessions: make(map[string]*session),
+	}
+	s.grpcServer = grpc.NewServer(grpc.Creds(creds), grpc.ForceServerCodec(protoCodec{}))
+	s.grpcServer.RegisterService(&serviceDesc, s)
+	return s, nil
+}
+
+// Serve blocks until the listener fails or the server is stopped.
+func (s *Server) Serve(listener net.Listener) error {
+	return s.grpcServer.Serve(listener)
+}
+
+// Stop waits until the batches being consumed are acknowledged.
+func (s *Server) Stop() {
+	s.grpcServer.GracefulStop()
+}
+
+func (s *Server) serveStream(stream grpc.ServerStream) error {
+	frame := new(Frame)
+	if err := stream.RecvMsg(frame); err != nil {
+		return err
+	}
+	hello := frame.Hello
+	if hello == nil {
+		return status.Error(codes.InvalidArgument, "the first frame must be a hello")
+	}
+	if hello.SchemaVersion > SchemaVersion {
+		return status.Errorf(codes.FailedPrecondition, "the schema version %d is not supported, the latest one is %d", hello.SchemaVersion, SchemaVersion)
+	}
+	token, agentSession := s.resume(hello.ResumeToken)
+	if err := stream.SendMsg(&Control{
+		ResumeToken:   token,
+		AckedSequence: agentSession.ackedSequence(),
+		Window:        s.config.Window,
+	}); err != nil {
+		return err
+	}
+	for {
+		frame := new(Frame)
+		if err := stream.RecvMsg(frame); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		if frame.Batch == nil {
+			continue
+		}
+		acked := agentSession.consume(hello, frame.Batch, s.handler)
+		if err := stream.SendMsg(&Control{AckedSequence: acked, Window: s.config.Window}); err != nil {
+			return err
+		}
+	}
+}
+
+// resume returns the session of the token, or a new session if the token is unknown, e.g. it is
+// expired or issued by another aggregator.
+func (s *Server) resume(token string) (string, *session) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	now := time.Now()
+	for key, agentSession := range s.sessions {
+		if agentSession.expired(now, s.config.SessionTimeout) {
+			delete(s.sessions, key)
+		}
+	}
+	if agentSession, ok := s.sessions[token]; ok {
+		return token, agentSession
+	}
+	token = newResumeToken()
+	agentSession := &session{lastActive: now}
+	s.sessions[token] = agentSession
+	return token, agentSession
+}
+
+func newResumeToken() string {
+	token := make([]byte, 16)
+	_, _ = rand.Read(token)
+	return hex.EncodeToString(token)
+}
+
+// consume skips the batch if it has been consumed in the previous streams, and returns the acked
+// sequence. The batches of the session are consumed one by one even if the agent opens a new stream
+// before the old one is closed.
+func (s *session) consume(hello *Hello, batch *Batch, handler Handler) uint64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if batch.Sequence > s.acked {
+		handler.Consume(hello, batch.Records)
+		s.acked = batch.Sequence
+	}
+	s.lastActive = time.Now()
+	return s.acked
+}
+
+func (s *session) ackedSequence() uint64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.lastActive = time.Now()
+	return s.acked
+}
+
+func (s *session) expired(now time.Time, timeout time.Duration) bool {
+	// The session is consuming a batch.
+	if !s.mutex.TryLock() {
+		return false
+	}
+	defer s.mutex.Unlock()
+	return now.Sub(s.lastActive) > timeout
+}
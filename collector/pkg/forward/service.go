@@ -0,0 +1,59 @@
+package forward
+
+import (
+	"fmt"
+
+	"github.com/gogo/protobuf/proto"
+	"google.golang.org/grpc"
+)
+
+const (
+	serviceName  = "kindling.forward.Forwarder"
+	streamMethod = "/" + serviceName + "/Stream"
+)
+
+// protoCodec encodes the messages of forward.proto with gogo/protobuf.
+type protoCodec struct{}
+
+func (protoCodec) Marshal(v interface{}) ([]byte, error) {
+	message, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("%T is not a proto message", v)
+	}
+	return proto.Marshal(message)
+}
+
+func (protoCodec) Unmarshal(data []byte, v interface{}) error {
+	message, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("%T is not a proto message", v)
+	}
+	return proto.Unmarshal(data, message)
+}
+
+func (protoCodec) Name() string {
+	return "proto"
+}
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: serviceName,
+	HandlerType: (*forwarderServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Stream",
+			Handler:       streamHandler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "forward.proto",
+}
+
+type forwarderServer interface {
+	serveStream(stream grpc.ServerStream) error
+}
+
+func streamHandler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(forwarderServer).serveStream(stream)
+}
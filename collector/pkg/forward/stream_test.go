@@ -0,0 +1,218 @@
+package forward
+
+import (
+	"context"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// recordingHandler records the names of the records by the nodes.
+type recordingHandler struct {
+	mutex   sync.Mutex
+	records map[string][]string
+	// block is received from before consuming a batch if it is not nil.
+	block chan struct{}
+}
+
+func newRecordingHandler() *recordingHandler {
+	return &recordingHandler{records: make(map[string][]string)}
+}
+
+func (h *recordingHandler) Consume(hello *Hello, records []*Record) {
+	if h.block != nil {
+		<-h.block
+	}
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	for _, record := range records {
+		h.records[hello.NodeName] = append(h.records[hello.NodeName], record.Name)
+	}
+}
+
+func (h *recordingHandler) get(nodeName string) []string {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return append([]string(nil), h.records[nodeName]...)
+}
+
+func startTestServer(t testing.TB, handler Handler, window uint32) string {
+	server, err := NewServer(handler, ServerConfig{Window: window, SessionTimeout: time.Minute})
+	require.NoError(t, err)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.grpcServer.Stop)
+	return listener.Addr().String()
+}
+
+func newTestClient(t testing.TB, endpoint string, nodeName string) *Client {
+	client, err := NewClient(ClientConfig{Endpoint: endpoint, NodeName: nodeName})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = client.Close()
+	})
+	return client
+}
+
+func newNamedRecords(prefix string, count int) []*Record {
+	records := make([]*Record, count)
+	for i := range records {
+		records[i] = &Record{Name: prefix + strconv.Itoa(i)}
+	}
+	return records
+}
+
+func waitPending(t *testing.T, client *Client, pending int) {
+	require.Eventually(t, func() bool {
+		return client.Pending() == pending
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestClient_Send(t *testing.T) {
+	handler := newRecordingHandler()
+	client := newTestClient(t, startTestServer(t, handler, 4), "node-1")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, client.Send(ctx, newNamedRecords("a", 2)))
+	require.NoError(t, client.Send(ctx, newNamedRecords("b", 1)))
+	waitPending(t, client, 0)
+	assert.Equal(t, []string{"a0", "a1", "b0"}, handler.get("node-1"))
+}
+
+func TestClient_SendBlockedByWindow(t *testing.T) {
+	handler := newRecordingHandler()
+	handler.block = make(chan struct{})
+	client := newTestClient(t, startTestServer(t, handler, 2), "node-1")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, client.Send(ctx, newNamedRecords("a", 1)))
+	require.NoError(t, client.Send(ctx, newNamedRecords("b", 1)))
+
+	// The window is used up as the handler is blocked.
+	shortCtx, shortCancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer shortCancel()
+	assert.ErrorIs(t, client.Send(shortCtx, newNamedRecords("c", 1)), context.DeadlineExceeded)
+
+	close(handler.block)
+	require.NoError(t, client.Send(ctx, newNamedRecords("c", 1)))
+	waitPending(t, client, 0)
+	assert.Equal(t, []string{"a0", "b0", "c0"}, handler.get("node-1"))
+}
+
+func TestClient_SendBeforeConnected(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	endpoint := listener.Addr().String()
+	require.NoError(t, listener.Close())
+	client := newTestClient(t, endpoint, "node-1")
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	assert.Error(t, client.Send(ctx, newNamedRecords("a", 1)))
+}
+
+func TestClient_Closed(t *testing.T) {
+	client := newTestClient(t, startTestServer(t, newRecordingHandler(), 1), "node-1")
+	require.NoError(t, client.Close())
+	assert.ErrorIs(t, client.Send(context.Background(), newNamedRecords("a", 1)), ErrClientClosed)
+}
+
+// dropProxy forwards the connections to the target, and closes all of them by drop.
+type dropProxy struct {
+	listener net.Listener
+	target   string
+	mutex    sync.Mutex
+	conns    []net.Conn
+}
+
+func newDropProxy(t *testing.T, target string) *dropProxy {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	p := &dropProxy{listener: listener, target: target}
+	go p.serve()
+	t.Cleanup(func() {
+		_ = listener.Close()
+		p.drop()
+	})
+	return p
+}
+
+func (p *dropProxy) serve() {
+	for {
+		conn, err := p.listener.Accept()
+		if err != nil {
+			return
+		}
+		upstream, err := net.Dial("tcp", p.target)
+		if err != nil {
+			_ = conn.Close()
+			continue
+		}
+		p.mutex.Lock()
+		p.conns = append(p.conns, conn, upstream)
+		p.mutex.Unlock()
+		go func() {
+			_, _ = io.Copy(upstream, conn)
+		}()
+		go func() {
+			_, _ = io.Copy(conn, upstream)
+		}()
+	}
+}
+
+func (p *dropProxy) drop() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	for _, conn := range p.conns {
+		_ = conn.Close()
+	}
+	p.conns = nil
+}
+
+func TestClient_Resume(t *testing.T) {
+	handler := newRecordingHandler()
+	handler.block = make(chan struct{}, 100)
+	proxy := newDropProxy(t, startTestServer(t, handler, 8))
+	client := newTestClient(t, proxy.listener.Addr().String(), "node-1")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	require.NoError(t, client.Send(ctx, newNamedRecords("a", 1)))
+	handler.block <- struct{}{}
+	waitPending(t, client, 0)
+
+	// The batches in flight are sent again after reconnecting.
+	require.NoError(t, client.Send(ctx, newNamedRecords("b", 1)))
+	require.NoError(t, client.Send(ctx, newNamedRecords("c", 1)))
+	proxy.drop()
+	for i := 0; i < 2; i++ {
+		handler.block <- struct{}{}
+	}
+	require.NoError(t, client.Send(ctx, newNamedRecords("d", 1)))
+	handler.block <- struct{}{}
+	waitPending(t, client, 0)
+	assert.Equal(t, []string{"a0", "b0", "c0", "d0"}, handler.get("node-1"))
+}
+
+func TestServer_RefuseNewerSchema(t *testing.T) {
+	endpoint := startTestServer(t, newRecordingHandler(), 1)
+	conn, err := grpc.Dial(endpoint, grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithDefaultCallOptions(grpc.ForceCodec(protoCodec{})))
+	require.NoError(t, err)
+	defer conn.Close()
+	stream, err := conn.NewStream(context.Background(), &serviceDesc.Streams[0], streamMethod)
+	require.NoError(t, err)
+	require.NoError(t, stream.SendMsg(&Frame{Hello: &Hello{NodeName: "node1", SchemaVersion: SchemaVersion + 1}}))
+	err = stream.RecvMsg(new(Control))
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
@@ -0,0 +1,72 @@
+package forward
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// TLSConfig configures the mutual TLS between the agents and the aggregator. Both sides present
+// their certificates and verify the peer's with the CA, so only the agents holding a certificate
+// issued by the CA can send records to the aggregator.
+type TLSConfig struct {
+	Enable   bool   `mapstructure:"enable"`
+	CaFile   string `mapstructure:"ca_file"`
+	CertFile string `mapstructure:"cert_file"`
+	KeyFile  string `mapstructure:"key_file"`
+	// ServerName is used by the agents to verify the certificate of the aggregator. The host of the
+	// endpoint is used if it is empty.
+	ServerName string `mapstructure:"server_name"`
+}
+
+func (c *TLSConfig) load() (tls.Certificate, *x509.CertPool, error) {
+	cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+	if err != nil {
+		return tls.Certificate{}, nil, fmt.Errorf("fail to load the certificate: %w", err)
+	}
+	ca, err := os.ReadFile(c.CaFile)
+	if err != nil {
+		return tls.Certificate{}, nil, fmt.Errorf("fail to read the CA: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return tls.Certificate{}, nil, fmt.Errorf("no certificate is found in the CA file [%s]", c.CaFile)
+	}
+	return cert, pool, nil
+}
+
+func (c *TLSConfig) serverCredentials() (credentials.TransportCredentials, error) {
+	if c == nil || !c.Enable {
+		return insecure.NewCredentials(), nil
+	}
+	cert, pool, err := c.load()
+	if err != nil {
+		return nil, err
+	}
+	return credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		MinVersion:   tls.VersionTLS12,
+	}), nil
+}
+
+func (c *TLSConfig) clientCredentials() (credentials.TransportCredentials, error) {
+	if c == nil || !c.Enable {
+		return insecure.NewCredentials(), nil
+	}
+	cert, pool, err := c.load()
+	if err != nil {
+		return nil, err
+	}
+	return credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      pool,
+		ServerName:   c.ServerName,
+		MinVersion:   tls.VersionTLS12,
+	}), nil
+}
@@ -0,0 +1,93 @@
+package hubble
+
+import (
+	"sync"
+)
+
+// subscriberQueueSize is the number of the flows waiting to be sent to a following client. The new
+// flows are dropped for the client if it is slower than that.
+const subscriberQueueSize = 1024
+
+// entry is a flow with its sequence, which is used to skip the flows sent before the client follows.
+type entry struct {
+	sequence uint64
+	flow     *Flow
+}
+
+// flowBuffer keeps the latest flows in a ring and passes the new flows to the following clients.
+// It is safe for concurrent use.
+type flowBuffer struct {
+	mutex       sync.Mutex
+	entries     []entry
+	next        int
+	full        bool
+	seen        uint64
+	subscribers map[chan entry]struct{}
+}
+
+func newFlowBuffer(capacity int) *flowBuffer {
+	if capacity <= 0 {
+		capacity = 1
+	}
+	return &flowBuffer{
+		entries:     make([]entry, capacity),
+		subscribers: make(map[chan entry]struct{}),
+	}
+}
+
+func (b *flowBuffer) add(flow *Flow) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.seen++
+	e := entry{sequence: b.seen, flow: flow}
+	b.entries[b.next] = e
+	b.next++
+	if b.next == len(b.entries) {
+		b.next = 0
+		b.full = true
+	}
+	for ch := range b.subscribers {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+// snapshot returns the flows in the buffer from the earliest to the latest.
+func (b *flowBuffer) snapshot() []entry {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if !b.full {
+		return append([]entry(nil), b.entries[:b.next]...)
+	}
+	ret := make([]entry, 0, len(b.entries))
+	ret = append(ret, b.entries[b.next:]...)
+	return append(ret, b.entries[:b.next]...)
+}
+
+// subscribe returns the channel receiving the flows added later. The channel must be unsubscribed.
+func (b *flowBuffer) subscribe() chan entry {
+	ch := make(chan entry, subscriberQueueSize)
+	b.mutex.Lock()
+	b.subscribers[ch] = struct{}{}
+	b.mutex.Unlock()
+	return ch
+}
+
+func (b *flowBuffer) unsubscribe(ch chan entry) {
+	b.mutex.Lock()
+	delete(b.subscribers, ch)
+	b.mutex.Unlock()
+}
+
+// stats returns the number of the flows in the buffer, the capacity and the number of the flows ever added.
+func (b *flowBuffer) stats() (uint64, uint64, uint64) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	num := uint64(b.next)
+	if b.full {
+		num = uint64(len(b.entries))
+	}
+	return num, uint64(len(b.entries)), b.seen
+}
@@ -0,0 +1,270 @@
+package hubble
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// flowMatcher is a compiled FlowFilter.
+type flowMatcher []func(flow *Flow) bool
+
+// compileFilters compiles the filters, which match if any of them matches.
+func compileFilters(filters []*FlowFilter) ([]flowMatcher, error) {
+	matchers := make([]flowMatcher, 0, len(filters))
+	for _, filter := range filters {
+		matcher, err := compileFilter(filter)
+		if err != nil {
+			return nil, err
+		}
+		matchers = append(matchers, matcher)
+	}
+	return matchers, nil
+}
+
+func compileFilter(filter *FlowFilter) (flowMatcher, error) {
+	var matcher flowMatcher
+	if len(filter.SourceIp) > 0 {
+		ipMatcher, err := compileIps(filter.SourceIp)
+		if err != nil {
+			return nil, err
+		}
+		matcher = append(matcher, func(flow *Flow) bool { return flow.IP != nil && ipMatcher(flow.IP.Source) })
+	}
+	if len(filter.DestinationIp) > 0 {
+		ipMatcher, err := compileIps(filter.DestinationIp)
+		if err != nil {
+			return nil, err
+		}
+		matcher = append(matcher, func(flow *Flow) bool { return flow.IP != nil && ipMatcher(flow.IP.Destination) })
+	}
+	if len(filter.SourcePod) > 0 {
+		pods := filter.SourcePod
+		matcher = append(matcher, func(flow *Flow) bool { return matchPod(pods, flow.Source) })
+	}
+	if len(filter.DestinationPod) > 0 {
+		pods := filter.DestinationPod
+		matcher = append(matcher, func(flow *Flow) bool { return matchPod(pods, flow.Destination) })
+	}
+	if len(filter.SourcePort) > 0 {
+		ports := filter.SourcePort
+		matcher = append(matcher, func(flow *Flow) bool {
+			sourcePort, _ := getPorts(flow)
+			return matchPort(ports, sourcePort)
+		})
+	}
+	if len(filter.DestinationPort) > 0 {
+		ports := filter.DestinationPort
+		matcher = append(matcher, func(flow *Flow) bool {
+			_, destinationPort := getPorts(flow)
+			return matchPort(ports, destinationPort)
+		})
+	}
+	if len(filter.Protocol) > 0 {
+		protocols := filter.Protocol
+		matcher = append(matcher, func(flow *Flow) bool { return matchProtocol(protocols, flow) })
+	}
+	if len(filter.HttpStatusCode) > 0 {
+		codes := filter.HttpStatusCode
+		matcher = append(matcher, func(flow *Flow) bool {
+			return flow.L7 != nil && flow.L7.Http != nil && matchStatusCode(codes, flow.L7.Http.Code)
+		})
+	}
+	if len(filter.HttpMethod) > 0 {
+		methods := filter.HttpMethod
+		matcher = append(matcher, func(flow *Flow) bool {
+			return flow.L7 != nil && flow.L7.Http != nil && matchAnyFold(methods, flow.L7.Http.Method)
+		})
+	}
+	if len(filter.HttpPath) > 0 {
+		pathRegexp, err := compileRegexps(filter.HttpPath)
+		if err != nil {
+			return nil, err
+		}
+		matcher = append(matcher, func(flow *Flow) bool {
+			return flow.L7 != nil && flow.L7.Http != nil && pathRegexp.MatchString(getUrlPath(flow.L7.Http.Url))
+		})
+	}
+	if len(filter.DnsQuery) > 0 {
+		queryRegexp, err := compileRegexps(filter.DnsQuery)
+		if err != nil {
+			return nil, err
+		}
+		matcher = append(matcher, func(flow *Flow) bool {
+			return flow.L7 != nil && flow.L7.Dns != nil && queryRegexp.MatchString(flow.L7.Dns.Query)
+		})
+	}
+	if len(filter.NodeName) > 0 {
+		patterns := filter.NodeName
+		matcher = append(matcher, func(flow *Flow) bool { return matchNodeName(patterns, flow.NodeName) })
+	}
+	return matcher, nil
+}
+
+func (m flowMatcher) match(flow *Flow) bool {
+	for _, fn := range m {
+		if !fn(flow) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchAny(matchers []flowMatcher, flow *Flow) bool {
+	for _, matcher := range matchers {
+		if matcher.match(flow) {
+			return true
+		}
+	}
+	return false
+}
+
+// compileIps compiles the IPs and the CIDRs.
+func compileIps(values []string) (func(ip string) bool, error) {
+	ips := make(map[string]bool)
+	var networks []*net.IPNet
+	for _, value := range values {
+		if !strings.Contains(value, "/") {
+			ips[value] = true
+			continue
+		}
+		_, network, err := net.ParseCIDR(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR %q: %w", value, err)
+		}
+		networks = append(networks, network)
+	}
+	return func(ip string) bool {
+		if ips[ip] {
+			return true
+		}
+		if len(networks) == 0 {
+			return false
+		}
+		parsed := net.ParseIP(ip)
+		for _, network := range networks {
+			if parsed != nil && network.Contains(parsed) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+// compileRegexps compiles the regular expressions into one matching any of them.
+func compileRegexps(values []string) (*regexp.Regexp, error) {
+	for _, value := range values {
+		if _, err := regexp.Compile(value); err != nil {
+			return nil, fmt.Errorf("invalid regular expression %q: %w", value, err)
+		}
+	}
+	return regexp.Compile("(?:" + strings.Join(values, ")|(?:") + ")")
+}
+
+// matchPod matches the pod name prefixes, e.g. "default/frontend-" and "frontend-". The namespace
+// is not compared if it is absent.
+func matchPod(pods []string, endpoint *Endpoint) bool {
+	if endpoint == nil || endpoint.PodName == "" {
+		return false
+	}
+	for _, pod := range pods {
+		namespace, prefix := "", pod
+		if index := strings.IndexByte(pod, '/'); index >= 0 {
+			namespace, prefix = pod[:index], pod[index+1:]
+		}
+		if (namespace == "" || namespace == endpoint.Namespace) && strings.HasPrefix(endpoint.PodName, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func getPorts(flow *Flow) (uint32, uint32) {
+	if flow.L4 == nil {
+		return 0, 0
+	}
+	if flow.L4.TCP != nil {
+		return flow.L4.TCP.SourcePort, flow.L4.TCP.DestinationPort
+	}
+	if flow.L4.UDP != nil {
+		return flow.L4.UDP.SourcePort, flow.L4.UDP.DestinationPort
+	}
+	return 0, 0
+}
+
+func matchPort(ports []string, port uint32) bool {
+	for _, value := range ports {
+		if value == strconv.FormatUint(uint64(port), 10) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchProtocol matches the protocols of layer 4 and layer 7, e.g. "tcp" and "http".
+func matchProtocol(protocols []string, flow *Flow) bool {
+	var names []string
+	if flow.L4 != nil && flow.L4.TCP != nil {
+		names = append(names, "tcp")
+	}
+	if flow.L4 != nil && flow.L4.UDP != nil {
+		names = append(names, "udp")
+	}
+	if flow.L7 != nil {
+		switch {
+		case flow.L7.Http != nil:
+			names = append(names, "http")
+		case flow.L7.Dns != nil:
+			names = append(names, "dns")
+		case flow.L7.Kafka != nil:
+			names = append(names, "kafka")
+		}
+	}
+	for _, name := range names {
+		if matchAnyFold(protocols, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchStatusCode matches the codes, e.g. "404", and the classes of the codes, e.g. "5+".
+func matchStatusCode(codes []string, code uint32) bool {
+	value := strconv.FormatUint(uint64(code), 10)
+	for _, c := range codes {
+		if c == value || (strings.HasSuffix(c, "+") && strings.HasPrefix(value, strings.TrimSuffix(c, "+"))) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchAnyFold(values []string, s string) bool {
+	for _, value := range values {
+		if strings.EqualFold(value, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchNodeName matches the node names with the wildcards, e.g. "worker-*".
+func matchNodeName(patterns []string, nodeName string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, nodeName); matched {
+			return true
+		}
+	}
+	return false
+}
+
+func getUrlPath(rawUrl string) string {
+	if parsed, err := url.Parse(rawUrl); err == nil {
+		return parsed.Path
+	}
+	return rawUrl
+}
@@ -0,0 +1,290 @@
+package hubble
+
+import (
+	proto "github.com/gogo/protobuf/proto"
+)
+
+// The messages of observer.proto. They are encoded by gogo/protobuf through the struct tags, so keep
+// the tags in sync with the field numbers in observer.proto, which are the same as Hubble's.
+
+type Verdict int32
+
+const Verdict_FORWARDED Verdict = 1
+
+type FlowType int32
+
+const (
+	FlowType_L3_L4 FlowType = 1
+	FlowType_L7    FlowType = 2
+)
+
+type L7FlowType int32
+
+const L7FlowType_RESPONSE L7FlowType = 2
+
+type TrafficDirection int32
+
+const (
+	TrafficDirection_INGRESS TrafficDirection = 1
+	TrafficDirection_EGRESS  TrafficDirection = 2
+)
+
+type IPVersion int32
+
+const (
+	IPVersion_IPv4 IPVersion = 1
+	IPVersion_IPv6 IPVersion = 2
+)
+
+const NodeState_NODE_CONNECTED int32 = 1
+
+type GetFlowsRequest struct {
+	Number    uint64        `protobuf:"varint,1,opt,name=number,proto3" json:"number,omitempty"`
+	First     bool          `protobuf:"varint,9,opt,name=first,proto3" json:"first,omitempty"`
+	Follow    bool          `protobuf:"varint,3,opt,name=follow,proto3" json:"follow,omitempty"`
+	Blacklist []*FlowFilter `protobuf:"bytes,5,rep,name=blacklist,proto3" json:"blacklist,omitempty"`
+	Whitelist []*FlowFilter `protobuf:"bytes,6,rep,name=whitelist,proto3" json:"whitelist,omitempty"`
+	Since     *Timestamp    `protobuf:"bytes,7,opt,name=since,proto3" json:"since,omitempty"`
+	Until     *Timestamp    `protobuf:"bytes,8,opt,name=until,proto3" json:"until,omitempty"`
+}
+
+func (m *GetFlowsRequest) Reset()         { *m = GetFlowsRequest{} }
+func (m *GetFlowsRequest) String() string { return proto.CompactTextString(m) }
+func (*GetFlowsRequest) ProtoMessage()    {}
+
+type GetFlowsResponse struct {
+	Flow     *Flow      `protobuf:"bytes,1,opt,name=flow,proto3" json:"flow,omitempty"`
+	NodeName string     `protobuf:"bytes,1000,opt,name=node_name,json=nodeName,proto3" json:"node_name,omitempty"`
+	Time     *Timestamp `protobuf:"bytes,1001,opt,name=time,proto3" json:"time,omitempty"`
+}
+
+func (m *GetFlowsResponse) Reset()         { *m = GetFlowsResponse{} }
+func (m *GetFlowsResponse) String() string { return proto.CompactTextString(m) }
+func (*GetFlowsResponse) ProtoMessage()    {}
+
+type GetNodesRequest struct {
+}
+
+func (m *GetNodesRequest) Reset()         { *m = GetNodesRequest{} }
+func (m *GetNodesRequest) String() string { return proto.CompactTextString(m) }
+func (*GetNodesRequest) ProtoMessage()    {}
+
+type GetNodesResponse struct {
+	Nodes []*Node `protobuf:"bytes,1,rep,name=nodes,proto3" json:"nodes,omitempty"`
+}
+
+func (m *GetNodesResponse) Reset()         { *m = GetNodesResponse{} }
+func (m *GetNodesResponse) String() string { return proto.CompactTextString(m) }
+func (*GetNodesResponse) ProtoMessage()    {}
+
+type Node struct {
+	Name      string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Version   string `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	Address   string `protobuf:"bytes,3,opt,name=address,proto3" json:"address,omitempty"`
+	State     int32  `protobuf:"varint,4,opt,name=state,proto3" json:"state,omitempty"`
+	UptimeNs  uint64 `protobuf:"varint,6,opt,name=uptime_ns,json=uptimeNs,proto3" json:"uptime_ns,omitempty"`
+	NumFlows  uint64 `protobuf:"varint,7,opt,name=num_flows,json=numFlows,proto3" json:"num_flows,omitempty"`
+	MaxFlows  uint64 `protobuf:"varint,8,opt,name=max_flows,json=maxFlows,proto3" json:"max_flows,omitempty"`
+	SeenFlows uint64 `protobuf:"varint,9,opt,name=seen_flows,json=seenFlows,proto3" json:"seen_flows,omitempty"`
+}
+
+func (m *Node) Reset()         { *m = Node{} }
+func (m *Node) String() string { return proto.CompactTextString(m) }
+func (*Node) ProtoMessage()    {}
+
+type ServerStatusRequest struct {
+}
+
+func (m *ServerStatusRequest) Reset()         { *m = ServerStatusRequest{} }
+func (m *ServerStatusRequest) String() string { return proto.CompactTextString(m) }
+func (*ServerStatusRequest) ProtoMessage()    {}
+
+type ServerStatusResponse struct {
+	NumFlows            uint64       `protobuf:"varint,1,opt,name=num_flows,json=numFlows,proto3" json:"num_flows,omitempty"`
+	MaxFlows            uint64       `protobuf:"varint,2,opt,name=max_flows,json=maxFlows,proto3" json:"max_flows,omitempty"`
+	SeenFlows           uint64       `protobuf:"varint,3,opt,name=seen_flows,json=seenFlows,proto3" json:"seen_flows,omitempty"`
+	UptimeNs            uint64       `protobuf:"varint,4,opt,name=uptime_ns,json=uptimeNs,proto3" json:"uptime_ns,omitempty"`
+	NumConnectedNodes   *UInt32Value `protobuf:"bytes,5,opt,name=num_connected_nodes,json=numConnectedNodes,proto3" json:"num_connected_nodes,omitempty"`
+	NumUnavailableNodes *UInt32Value `protobuf:"bytes,6,opt,name=num_unavailable_nodes,json=numUnavailableNodes,proto3" json:"num_unavailable_nodes,omitempty"`
+	Version             string       `protobuf:"bytes,8,opt,name=version,proto3" json:"version,omitempty"`
+}
+
+func (m *ServerStatusResponse) Reset()         { *m = ServerStatusResponse{} }
+func (m *ServerStatusResponse) String() string { return proto.CompactTextString(m) }
+func (*ServerStatusResponse) ProtoMessage()    {}
+
+type FlowFilter struct {
+	SourceIp        []string `protobuf:"bytes,1,rep,name=source_ip,json=sourceIp,proto3" json:"source_ip,omitempty"`
+	SourcePod       []string `protobuf:"bytes,2,rep,name=source_pod,json=sourcePod,proto3" json:"source_pod,omitempty"`
+	DestinationIp   []string `protobuf:"bytes,3,rep,name=destination_ip,json=destinationIp,proto3" json:"destination_ip,omitempty"`
+	DestinationPod  []string `protobuf:"bytes,4,rep,name=destination_pod,json=destinationPod,proto3" json:"destination_pod,omitempty"`
+	HttpStatusCode  []string `protobuf:"bytes,9,rep,name=http_status_code,json=httpStatusCode,proto3" json:"http_status_code,omitempty"`
+	Protocol        []string `protobuf:"bytes,12,rep,name=protocol,proto3" json:"protocol,omitempty"`
+	SourcePort      []string `protobuf:"bytes,13,rep,name=source_port,json=sourcePort,proto3" json:"source_port,omitempty"`
+	DestinationPort []string `protobuf:"bytes,14,rep,name=destination_port,json=destinationPort,proto3" json:"destination_port,omitempty"`
+	DnsQuery        []string `protobuf:"bytes,18,rep,name=dns_query,json=dnsQuery,proto3" json:"dns_query,omitempty"`
+	HttpMethod      []string `protobuf:"bytes,21,rep,name=http_method,json=httpMethod,proto3" json:"http_method,omitempty"`
+	HttpPath        []string `protobuf:"bytes,22,rep,name=http_path,json=httpPath,proto3" json:"http_path,omitempty"`
+	NodeName        []string `protobuf:"bytes,24,rep,name=node_name,json=nodeName,proto3" json:"node_name,omitempty"`
+}
+
+func (m *FlowFilter) Reset()         { *m = FlowFilter{} }
+func (m *FlowFilter) String() string { return proto.CompactTextString(m) }
+func (*FlowFilter) ProtoMessage()    {}
+
+type Flow struct {
+	Time               *Timestamp       `protobuf:"bytes,1,opt,name=time,proto3" json:"time,omitempty"`
+	Verdict            Verdict          `protobuf:"varint,2,opt,name=verdict,proto3" json:"verdict,omitempty"`
+	IP                 *IP              `protobuf:"bytes,5,opt,name=IP,proto3" json:"IP,omitempty"`
+	L4                 *Layer4          `protobuf:"bytes,6,opt,name=l4,proto3" json:"l4,omitempty"`
+	Source             *Endpoint        `protobuf:"bytes,8,opt,name=source,proto3" json:"source,omitempty"`
+	Destination        *Endpoint        `protobuf:"bytes,9,opt,name=destination,proto3" json:"destination,omitempty"`
+	Type               FlowType         `protobuf:"varint,10,opt,name=Type,proto3" json:"Type,omitempty"`
+	NodeName           string           `protobuf:"bytes,11,opt,name=node_name,json=nodeName,proto3" json:"node_name,omitempty"`
+	L7                 *Layer7          `protobuf:"bytes,15,opt,name=l7,proto3" json:"l7,omitempty"`
+	SourceService      *Service         `protobuf:"bytes,20,opt,name=source_service,json=sourceService,proto3" json:"source_service,omitempty"`
+	DestinationService *Service         `protobuf:"bytes,21,opt,name=destination_service,json=destinationService,proto3" json:"destination_service,omitempty"`
+	TrafficDirection   TrafficDirection `protobuf:"varint,22,opt,name=traffic_direction,json=trafficDirection,proto3" json:"traffic_direction,omitempty"`
+	IsReply            *BoolValue       `protobuf:"bytes,26,opt,name=is_reply,json=isReply,proto3" json:"is_reply,omitempty"`
+}
+
+func (m *Flow) Reset()         { *m = Flow{} }
+func (m *Flow) String() string { return proto.CompactTextString(m) }
+func (*Flow) ProtoMessage()    {}
+
+type IP struct {
+	Source      string    `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	Destination string    `protobuf:"bytes,2,opt,name=destination,proto3" json:"destination,omitempty"`
+	IpVersion   IPVersion `protobuf:"varint,3,opt,name=ipVersion,proto3" json:"ipVersion,omitempty"`
+}
+
+func (m *IP) Reset()         { *m = IP{} }
+func (m *IP) String() string { return proto.CompactTextString(m) }
+func (*IP) ProtoMessage()    {}
+
+type Layer4 struct {
+	TCP *TCP `protobuf:"bytes,1,opt,name=TCP,proto3" json:"TCP,omitempty"`
+	UDP *UDP `protobuf:"bytes,2,opt,name=UDP,proto3" json:"UDP,omitempty"`
+}
+
+func (m *Layer4) Reset()         { *m = Layer4{} }
+func (m *Layer4) String() string { return proto.CompactTextString(m) }
+func (*Layer4) ProtoMessage()    {}
+
+type TCP struct {
+	SourcePort      uint32 `protobuf:"varint,1,opt,name=source_port,json=sourcePort,proto3" json:"source_port,omitempty"`
+	DestinationPort uint32 `protobuf:"varint,2,opt,name=destination_port,json=destinationPort,proto3" json:"destination_port,omitempty"`
+}
+
+func (m *TCP) Reset()         { *m = TCP{} }
+func (m *TCP) String() string { return proto.CompactTextString(m) }
+func (*TCP) ProtoMessage()    {}
+
+type UDP struct {
+	SourcePort      uint32 `protobuf:"varint,1,opt,name=source_port,json=sourcePort,proto3" json:"source_port,omitempty"`
+	DestinationPort uint32 `protobuf:"varint,2,opt,name=destination_port,json=destinationPort,proto3" json:"destination_port,omitempty"`
+}
+
+func (m *UDP) Reset()         { *m = UDP{} }
+func (m *UDP) String() string { return proto.CompactTextString(m) }
+func (*UDP) ProtoMessage()    {}
+
+type Endpoint struct {
+	Namespace string      `protobuf:"bytes,3,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	Labels    []string    `protobuf:"bytes,4,rep,name=labels,proto3" json:"labels,omitempty"`
+	PodName   string      `protobuf:"bytes,5,opt,name=pod_name,json=podName,proto3" json:"pod_name,omitempty"`
+	Workloads []*Workload `protobuf:"bytes,6,rep,name=workloads,proto3" json:"workloads,omitempty"`
+}
+
+func (m *Endpoint) Reset()         { *m = Endpoint{} }
+func (m *Endpoint) String() string { return proto.CompactTextString(m) }
+func (*Endpoint) ProtoMessage()    {}
+
+type Workload struct {
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Kind string `protobuf:"bytes,2,opt,name=kind,proto3" json:"kind,omitempty"`
+}
+
+func (m *Workload) Reset()         { *m = Workload{} }
+func (m *Workload) String() string { return proto.CompactTextString(m) }
+func (*Workload) ProtoMessage()    {}
+
+type Service struct {
+	Name      string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Namespace string `protobuf:"bytes,2,opt,name=namespace,proto3" json:"namespace,omitempty"`
+}
+
+func (m *Service) Reset()         { *m = Service{} }
+func (m *Service) String() string { return proto.CompactTextString(m) }
+func (*Service) ProtoMessage()    {}
+
+type Layer7 struct {
+	Type      L7FlowType `protobuf:"varint,1,opt,name=type,proto3" json:"type,omitempty"`
+	LatencyNs uint64     `protobuf:"varint,2,opt,name=latency_ns,json=latencyNs,proto3" json:"latency_ns,omitempty"`
+	Dns       *DNS       `protobuf:"bytes,100,opt,name=dns,proto3" json:"dns,omitempty"`
+	Http      *HTTP      `protobuf:"bytes,101,opt,name=http,proto3" json:"http,omitempty"`
+	Kafka     *Kafka     `protobuf:"bytes,102,opt,name=kafka,proto3" json:"kafka,omitempty"`
+}
+
+func (m *Layer7) Reset()         { *m = Layer7{} }
+func (m *Layer7) String() string { return proto.CompactTextString(m) }
+func (*Layer7) ProtoMessage()    {}
+
+type DNS struct {
+	Query  string   `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	Ips    []string `protobuf:"bytes,2,rep,name=ips,proto3" json:"ips,omitempty"`
+	Cnames []string `protobuf:"bytes,4,rep,name=cnames,proto3" json:"cnames,omitempty"`
+	Rcode  uint32   `protobuf:"varint,6,opt,name=rcode,proto3" json:"rcode,omitempty"`
+}
+
+func (m *DNS) Reset()         { *m = DNS{} }
+func (m *DNS) String() string { return proto.CompactTextString(m) }
+func (*DNS) ProtoMessage()    {}
+
+type HTTP struct {
+	Code     uint32 `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Method   string `protobuf:"bytes,2,opt,name=method,proto3" json:"method,omitempty"`
+	Url      string `protobuf:"bytes,3,opt,name=url,proto3" json:"url,omitempty"`
+	Protocol string `protobuf:"bytes,4,opt,name=protocol,proto3" json:"protocol,omitempty"`
+}
+
+func (m *HTTP) Reset()         { *m = HTTP{} }
+func (m *HTTP) String() string { return proto.CompactTextString(m) }
+func (*HTTP) ProtoMessage()    {}
+
+type Kafka struct {
+	ErrorCode     int32  `protobuf:"varint,1,opt,name=error_code,json=errorCode,proto3" json:"error_code,omitempty"`
+	ApiVersion    int32  `protobuf:"varint,2,opt,name=api_version,json=apiVersion,proto3" json:"api_version,omitempty"`
+	ApiKey        string `protobuf:"bytes,3,opt,name=api_key,json=apiKey,proto3" json:"api_key,omitempty"`
+	CorrelationId int32  `protobuf:"varint,4,opt,name=correlation_id,json=correlationId,proto3" json:"correlation_id,omitempty"`
+	Topic         string `protobuf:"bytes,5,opt,name=topic,proto3" json:"topic,omitempty"`
+}
+
+func (m *Kafka) Reset()         { *m = Kafka{} }
+func (m *Kafka) String() string { return proto.CompactTextString(m) }
+func (*Kafka) ProtoMessage()    {}
+
+type Timestamp struct {
+	Seconds int64 `protobuf:"varint,1,opt,name=seconds,proto3" json:"seconds,omitempty"`
+	Nanos   int32 `protobuf:"varint,2,opt,name=nanos,proto3" json:"nanos,omitempty"`
+}
+
+func (m *Timestamp) Reset()         { *m = Timestamp{} }
+func (m *Timestamp) String() string { return proto.CompactTextString(m) }
+func (*Timestamp) ProtoMessage()    {}
+
+type BoolValue struct {
+	Value bool `protobuf:"varint,1,opt,name=value,proto3" json:"value,omitempty"`
+}
+
+func (m *BoolValue) Reset()         { *m = BoolValue{} }
+func (m *BoolValue) String() string { return proto.CompactTextString(m) }
+func (*BoolValue) ProtoMessage()    {}
+
+type UInt32Value struct {
+	Value uint32 `protobuf:"varint,1,opt,name=value,proto3" json:"value,omitempty"`
+}
+
+func (m *UInt32Value) Reset()         { *m = UInt32Value{} }
+func (m *UInt32Value) String() string { return proto.CompactTextString(m) }
+func (*UInt32Value) ProtoMessage()    {}
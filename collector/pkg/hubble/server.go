@@ -0,0 +1,254 @@
+package hubble
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	serviceName = "observer.Observer"
+	// version is reported as the version of the server and the node.
+	version = "kindling"
+)
+
+// protoCodec encodes the messages of observer.proto with gogo/protobuf.
+type protoCodec struct{}
+
+func (protoCodec) Marshal(v interface{}) ([]byte, error) {
+	message, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("%T is not a proto message", v)
+	}
+	return proto.Marshal(message)
+}
+
+func (protoCodec) Unmarshal(data []byte, v interface{}) error {
+	message, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("%T is not a proto message", v)
+	}
+	return proto.Unmarshal(data, message)
+}
+
+func (protoCodec) Name() string {
+	return "proto"
+}
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: serviceName,
+	HandlerType: (*observerServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "GetNodes", Handler: getNodesHandler},
+		{MethodName: "ServerStatus", Handler: serverStatusHandler},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "GetFlows",
+			Handler:       getFlowsHandler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "observer.proto",
+}
+
+type observerServer interface {
+	getFlows(request *GetFlowsRequest, stream grpc.ServerStream) error
+	getNodes() *GetNodesResponse
+	serverStatus() *ServerStatusResponse
+}
+
+func getFlowsHandler(srv interface{}, stream grpc.ServerStream) error {
+	request := new(GetFlowsRequest)
+	if err := stream.RecvMsg(request); err != nil {
+		return err
+	}
+	return srv.(observerServer).getFlows(request, stream)
+}
+
+func getNodesHandler(srv interface{}, _ context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+	if err := dec(new(GetNodesRequest)); err != nil {
+		return nil, err
+	}
+	return srv.(observerServer).getNodes(), nil
+}
+
+func serverStatusHandler(srv interface{}, _ context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+	if err := dec(new(ServerStatusRequest)); err != nil {
+		return nil, err
+	}
+	return srv.(observerServer).serverStatus(), nil
+}
+
+type ServerConfig struct {
+	// NodeName is the node reported by GetNodes and set in the responses of GetFlows.
+	NodeName string
+	NodeIp   string
+	// MaxFlows is the number of the latest flows kept for the clients.
+	MaxFlows int
+}
+
+// Server serves the flows through the Observer service of Hubble, so the clients of Hubble Relay read
+// the flows observed by Kindling.
+type Server struct {
+	config     ServerConfig
+	buffer     *flowBuffer
+	startTime  time.Time
+	grpcServer *grpc.Server
+}
+
+func NewServer(config ServerConfig) *Server {
+	s := &Server{
+		config:    config,
+		buffer:    newFlowBuffer(config.MaxFlows),
+		startTime: time.Now(),
+	}
+	s.grpcServer = grpc.NewServer(grpc.ForceServerCodec(protoCodec{}))
+	s.grpcServer.RegisterService(&serviceDesc, s)
+	return s
+}
+
+// Add keeps the flow and sends it to the clients following the flows.
+func (s *Server) Add(flow *Flow) {
+	s.buffer.add(flow)
+}
+
+// Serve blocks until the listener fails or the server is stopped.
+func (s *Server) Serve(listener net.Listener) error {
+	return s.grpcServer.Serve(listener)
+}
+
+func (s *Server) Stop() {
+	s.grpcServer.Stop()
+}
+
+func (s *Server) getFlows(request *GetFlowsRequest, stream grpc.ServerStream) error {
+	whitelist, err := compileFilters(request.Whitelist)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	blacklist, err := compileFilters(request.Blacklist)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	match := func(flow *Flow) bool {
+		if request.Since != nil && compareTimestamp(flow.Time, request.Since) < 0 {
+			return false
+		}
+		if request.Until != nil && compareTimestamp(flow.Time, request.Until) > 0 {
+			return false
+		}
+		if len(whitelist) > 0 && !matchAny(whitelist, flow) {
+			return false
+		}
+		return !matchAny(blacklist, flow)
+	}
+
+	// Subscribe before taking the snapshot, so no flow is missed in between.
+	var ch chan entry
+	if request.Follow {
+		ch = s.buffer.subscribe()
+		defer s.buffer.unsubscribe(ch)
+	}
+	entries := make([]entry, 0)
+	for _, e := range s.buffer.snapshot() {
+		if match(e.flow) {
+			entries = append(entries, e)
+		}
+	}
+	if number := request.Number; number > 0 && uint64(len(entries)) > number {
+		if request.First {
+			entries = entries[:number]
+		} else {
+			entries = entries[uint64(len(entries))-number:]
+		}
+	}
+	var lastSequence uint64
+	for _, e := range entries {
+		if err := s.sendFlow(stream, e.flow); err != nil {
+			return err
+		}
+		lastSequence = e.sequence
+	}
+	if !request.Follow {
+		return nil
+	}
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case e := <-ch:
+			if e.sequence <= lastSequence || !match(e.flow) {
+				continue
+			}
+			if err := s.sendFlow(stream, e.flow); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (s *Server) sendFlow(stream grpc.ServerStream, flow *Flow) error {
+	return stream.SendMsg(&GetFlowsResponse{
+		Flow:     flow,
+		NodeName: flow.NodeName,
+		Time:     flow.Time,
+	})
+}
+
+func (s *Server) getNodes() *GetNodesResponse {
+	num, max, seen := s.buffer.stats()
+	return &GetNodesResponse{Nodes: []*Node{{
+		Name:      s.config.NodeName,
+		Version:   version,
+		Address:   s.config.NodeIp,
+		State:     NodeState_NODE_CONNECTED,
+		UptimeNs:  uint64(time.Since(s.startTime)),
+		NumFlows:  num,
+		MaxFlows:  max,
+		SeenFlows: seen,
+	}}}
+}
+
+func (s *Server) serverStatus() *ServerStatusResponse {
+	num, max, seen := s.buffer.stats()
+	return &ServerStatusResponse{
+		NumFlows:            num,
+		MaxFlows:            max,
+		SeenFlows:           seen,
+		UptimeNs:            uint64(time.Since(s.startTime)),
+		NumConnectedNodes:   &UInt32Value{Value: 1},
+		NumUnavailableNodes: &UInt32Value{Value: 0},
+		Version:             version,
+	}
+}
+
+// NewTimestamp converts the nanoseconds since the Unix epoch to a Timestamp.
+func NewTimestamp(nanoseconds uint64) *Timestamp {
+	return &Timestamp{Seconds: int64(nanoseconds / uint64(time.Second)), Nanos: int32(nanoseconds % uint64(time.Second))}
+}
+
+func compareTimestamp(a *Timestamp, b *Timestamp) int {
+	if a == nil {
+		a = &Timestamp{}
+	}
+	switch {
+	case a.Seconds != b.Seconds:
+		if a.Seconds < b.Seconds {
+			return -1
+		}
+		return 1
+	case a.Nanos != b.Nanos:
+		if a.Nanos < b.Nanos {
+			return -1
+		}
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,140 @@
+package hubble
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+func newHttpFlow(timestamp uint64, pod string, path string, code uint32) *Flow {
+	return &Flow{
+		Time:        NewTimestamp(timestamp),
+		Verdict:     Verdict_FORWARDED,
+		IP:          &IP{Source: "10.0.0.2", Destination: "10.0.0.1", IpVersion: IPVersion_IPv4},
+		L4:          &Layer4{TCP: &TCP{SourcePort: 8080, DestinationPort: 40000}},
+		Source:      &Endpoint{Namespace: "default", PodName: pod},
+		Destination: &Endpoint{Namespace: "default", PodName: "frontend-0"},
+		Type:        FlowType_L7,
+		NodeName:    "worker-1",
+		L7:          &Layer7{Type: L7FlowType_RESPONSE, Http: &HTTP{Code: code, Method: "GET", Url: "http://backend" + path}},
+	}
+}
+
+func startTestServer(t *testing.T, maxFlows int) (*Server, *grpc.ClientConn) {
+	server := NewServer(ServerConfig{NodeName: "worker-1", MaxFlows: maxFlows})
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(server.Stop)
+	conn, err := grpc.Dial(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(protoCodec{})))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	return server, conn
+}
+
+func getFlows(ctx context.Context, t *testing.T, conn *grpc.ClientConn, request *GetFlowsRequest) grpc.ClientStream {
+	stream, err := conn.NewStream(ctx, &serviceDesc.Streams[0], "/"+serviceName+"/GetFlows")
+	require.NoError(t, err)
+	require.NoError(t, stream.SendMsg(request))
+	require.NoError(t, stream.CloseSend())
+	return stream
+}
+
+func recvPaths(t *testing.T, stream grpc.ClientStream) []string {
+	var paths []string
+	for {
+		response := new(GetFlowsResponse)
+		err := stream.RecvMsg(response)
+		if err == io.EOF {
+			return paths
+		}
+		require.NoError(t, err)
+		assert.Equal(t, "worker-1", response.NodeName)
+		paths = append(paths, getUrlPath(response.Flow.L7.Http.Url))
+	}
+}
+
+func TestGetFlows(t *testing.T) {
+	server, conn := startTestServer(t, 3)
+	for i, path := range []string{"/a", "/b", "/c", "/d"} {
+		server.Add(newHttpFlow(uint64(i+1)*uint64(time.Second), "backend-0", path, 200))
+	}
+	server.Add(newHttpFlow(5*uint64(time.Second), "other-0", "/e", 500))
+	ctx := context.Background()
+
+	// The buffer keeps the latest 3 flows.
+	assert.Equal(t, []string{"/c", "/d", "/e"}, recvPaths(t, getFlows(ctx, t, conn, &GetFlowsRequest{})))
+	assert.Equal(t, []string{"/d", "/e"}, recvPaths(t, getFlows(ctx, t, conn, &GetFlowsRequest{Number: 2})))
+	assert.Equal(t, []string{"/c"}, recvPaths(t, getFlows(ctx, t, conn, &GetFlowsRequest{Number: 1, First: true})))
+	assert.Equal(t, []string{"/c", "/d"}, recvPaths(t, getFlows(ctx, t, conn, &GetFlowsRequest{
+		Whitelist: []*FlowFilter{{SourcePod: []string{"default/backend-"}, HttpPath: []string{"^/[cd]$"}}},
+	})))
+	assert.Equal(t, []string{"/e"}, recvPaths(t, getFlows(ctx, t, conn, &GetFlowsRequest{
+		Whitelist: []*FlowFilter{{HttpStatusCode: []string{"5+"}}, {Protocol: []string{"dns"}}},
+	})))
+	assert.Equal(t, []string{"/c", "/e"}, recvPaths(t, getFlows(ctx, t, conn, &GetFlowsRequest{
+		Blacklist: []*FlowFilter{{HttpPath: []string{"/d"}}},
+	})))
+	assert.Equal(t, []string{"/d"}, recvPaths(t, getFlows(ctx, t, conn, &GetFlowsRequest{
+		Since: NewTimestamp(4 * uint64(time.Second)), Until: NewTimestamp(4 * uint64(time.Second)),
+	})))
+
+	err := getFlows(ctx, t, conn, &GetFlowsRequest{Whitelist: []*FlowFilter{{HttpPath: []string{"("}}}}).RecvMsg(new(GetFlowsResponse))
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGetFlowsFollow(t *testing.T) {
+	server, conn := startTestServer(t, 10)
+	server.Add(newHttpFlow(uint64(time.Second), "backend-0", "/a", 200))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream := getFlows(ctx, t, conn, &GetFlowsRequest{Follow: true, Whitelist: []*FlowFilter{{SourcePod: []string{"backend-"}}}})
+
+	response := new(GetFlowsResponse)
+	require.NoError(t, stream.RecvMsg(response))
+	assert.Equal(t, "http://backend/a", response.Flow.L7.Http.Url)
+	// Wait until the stream follows the new flows.
+	require.Eventually(t, func() bool {
+		server.buffer.mutex.Lock()
+		defer server.buffer.mutex.Unlock()
+		return len(server.buffer.subscribers) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	server.Add(newHttpFlow(2*uint64(time.Second), "other-0", "/b", 200))
+	server.Add(newHttpFlow(3*uint64(time.Second), "backend-0", "/c", 200))
+	require.NoError(t, stream.RecvMsg(response))
+	assert.Equal(t, "http://backend/c", response.Flow.L7.Http.Url)
+}
+
+func TestServerStatus(t *testing.T) {
+	server, conn := startTestServer(t, 2)
+	for i := 0; i < 3; i++ {
+		server.Add(newHttpFlow(uint64(i), "backend-0", "/", 200))
+	}
+	response := new(ServerStatusResponse)
+	require.NoError(t, conn.Invoke(context.Background(), "/"+serviceName+"/ServerStatus", &ServerStatusRequest{}, response))
+	assert.Equal(t, uint64(2), response.NumFlows)
+	assert.Equal(t, uint64(2), response.MaxFlows)
+	assert.Equal(t, uint64(3), response.SeenFlows)
+	assert.Equal(t, uint32(1), response.NumConnectedNodes.Value)
+
+	nodes := new(GetNodesResponse)
+	require.NoError(t, conn.Invoke(context.Background(), "/"+serviceName+"/GetNodes", &GetNodesRequest{}, nodes))
+	if assert.Len(t, nodes.Nodes, 1) {
+		assert.Equal(t, "worker-1", nodes.Nodes[0].Name)
+		assert.Equal(t, NodeState_NODE_CONNECTED, nodes.Nodes[0].State)
+	}
+}
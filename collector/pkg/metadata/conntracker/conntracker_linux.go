@@ -75,6 +75,34 @@ func (ctr *NetlinkConntracker) GetDNATTuple(srcIP uint32, dstIP uint32, srcPort
 	return ctr.getDNATTuple(conn)
 }
 
+func (ctr *NetlinkConntracker) GetDNATChain(srcIP uint32, dstIP uint32, srcPort uint16, dstPort uint16, isUdp uint32, maxDepth int) []*IPTranslation {
+	conn := internal.ConnectionStats{
+		Source: int32ToIp(srcIP),
+		SPort:  srcPort,
+		Dest:   int32ToIp(dstIP),
+		DPort:  dstPort,
+		Type:   internal.ConnectionType(isUdp),
+	}
+	var chain []*IPTranslation
+	for len(chain) < maxDepth {
+		translation := ctr.getDNATTuple(conn)
+		if translation == nil {
+			break
+		}
+		chain = append(chain, translation)
+		// The translated connection is looked up again in case it is translated by the next hop,
+		// whose source is the one seen by the backend of this hop.
+		conn = internal.ConnectionStats{
+			Source: translation.ReplDstIP,
+			SPort:  translation.ReplDstPort,
+			Dest:   translation.ReplSrcIP,
+			DPort:  translation.ReplSrcPort,
+			Type:   conn.Type,
+		}
+	}
+	return chain
+}
+
 // getDNATTuple is a helper function for public methods with private parameter.
 func (ctr *NetlinkConntracker) getDNATTuple(conn internal.ConnectionStats) *IPTranslation {
 	ret := ctr.conntracker.GetTranslationForConn(conn)
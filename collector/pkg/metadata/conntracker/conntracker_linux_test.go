@@ -0,0 +1,53 @@
+//go:build linux
+
+package conntracker
+
+import (
+	"net"
+	"strconv"
+	"testing"
+
+	"github.com/Kindling-project/kindling/collector/pkg/metadata/conntracker/internal"
+)
+
+// staticConntracker translates the destinations "ip:port" by the map.
+type staticConntracker struct {
+	translations map[string]*internal.IPTranslation
+}
+
+func (c *staticConntracker) GetTranslationForConn(conn internal.ConnectionStats) *internal.IPTranslation {
+	return c.translations[net.JoinHostPort(conn.Dest.String(), strconv.Itoa(int(conn.DPort)))]
+}
+
+func (c *staticConntracker) DeleteTranslation(internal.ConnectionStats) {}
+
+func (c *staticConntracker) IsSampling() bool { return false }
+
+func (c *staticConntracker) GetStats() map[string]int64 { return nil }
+
+func (c *staticConntracker) Close() {}
+
+func TestGetDNATChain(t *testing.T) {
+	ctr := &NetlinkConntracker{
+		conntracker: &staticConntracker{translations: map[string]*internal.IPTranslation{
+			// service VIP -> NodePort
+			"10.96.0.10:80": {ReplSrcIP: net.ParseIP("192.168.1.2"), ReplSrcPort: 30080, ReplDstIP: net.ParseIP("192.168.1.1"), ReplDstPort: 40000},
+			// NodePort -> pod
+			"192.168.1.2:30080": {ReplSrcIP: net.ParseIP("172.16.0.5"), ReplSrcPort: 8080, ReplDstIP: net.ParseIP("192.168.1.1"), ReplDstPort: 40001},
+		}},
+		cfg: &Config{},
+	}
+	src := IPToUInt32(net.ParseIP("172.16.0.9"))
+	vip := IPToUInt32(net.ParseIP("10.96.0.10"))
+
+	chain := ctr.GetDNATChain(src, vip, 50000, 80, 0, 3)
+	if len(chain) != 2 || !chain[1].ReplSrcIP.Equal(net.ParseIP("172.16.0.5")) || chain[1].ReplSrcPort != 8080 {
+		t.Fatalf("GetDNATChain() = %v, want 2 hops ending with 172.16.0.5:8080", chain)
+	}
+	if chain = ctr.GetDNATChain(src, vip, 50000, 80, 0, 1); len(chain) != 1 {
+		t.Errorf("GetDNATChain() with depth 1 = %v, want 1 hop", chain)
+	}
+	if chain = ctr.GetDNATChain(src, IPToUInt32(net.ParseIP("10.96.0.11")), 50000, 80, 0, 3); chain != nil {
+		t.Errorf("GetDNATChain() without DNAT = %v, want nil", chain)
+	}
+}
@@ -3,6 +3,9 @@ package conntracker
 type Conntracker interface {
 	GetDNATTupleWithString(srcIP string, dstIP string, srcPort uint16, dstPort uint16, isUdp uint32) *IPTranslation
 	GetDNATTuple(srcIP uint32, dstIP uint32, srcPort uint16, dstPort uint16, isUdp uint32) *IPTranslation
+	// GetDNATChain follows the translations of multi-hop NAT, e.g. service VIP -> NodePort -> pod, at most
+	// maxDepth hops. The last translation is the real backend. It returns nil if there is no DNAT.
+	GetDNATChain(srcIP uint32, dstIP uint32, srcPort uint16, dstPort uint16, isUdp uint32, maxDepth int) []*IPTranslation
 	GetStats() map[string]int64
 }
 
@@ -26,6 +29,10 @@ func (ctr *NoopConntracker) GetDNATTuple(_ uint32, _ uint32, _ uint16, _ uint16,
 	return nil
 }
 
+func (ctr *NoopConntracker) GetDNATChain(_ uint32, _ uint32, _ uint16, _ uint16, _ uint32, _ int) []*IPTranslation {
+	return nil
+}
+
 func (ctr *NoopConntracker) GetStats() map[string]int64 {
 	return ctr.stats
 }
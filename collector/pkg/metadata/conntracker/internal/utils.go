@@ -5,6 +5,9 @@
 
 // Copied from github.com/DataDog/datadog-agent/pkg/util/kernel and pkg/process/util
 
+//go:build linux && !android
+// +build linux,!android
+
 package internal
 
 import (
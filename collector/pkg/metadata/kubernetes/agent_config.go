@@ -0,0 +1,146 @@
+package kubernetes
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+)
+
+// KindlingConfigResource is the custom resource holding the settings of the agents, which is defined by
+// deploy/agent/kindling-config-crd.yml.
+var KindlingConfigResource = schema.GroupVersionResource{Group: "kindling.io", Version: "v1alpha1", Resource: "kindlingconfigs"}
+
+// AgentConfigCrdConfig watches the KindlingConfig objects, so the settings of the agents could be
+// changed at runtime without editing the ConfigMap.
+type AgentConfigCrdConfig struct {
+	Enable bool `mapstructure:"enable"`
+	// Namespace limits the objects watched to the namespace. All the namespaces are watched if it is empty.
+	Namespace string `mapstructure:"namespace"`
+}
+
+func NewDefaultAgentConfigCrdConfig() *AgentConfigCrdConfig {
+	return &AgentConfigCrdConfig{
+		Enable:    false,
+		Namespace: "",
+	}
+}
+
+// AgentConfig is the spec of a KindlingConfig object.
+type AgentConfig struct {
+	Namespace string
+	Name      string
+	Spec      map[string]interface{}
+}
+
+type agentConfigWatch struct {
+	// updateMutex keeps the updates in order, so the watchers never see the outdated objects at last.
+	updateMutex sync.Mutex
+	mutex       sync.Mutex
+	synced      bool
+	configs     []*AgentConfig
+	watchers    []func(configs []*AgentConfig)
+}
+
+var agentConfigs = &agentConfigWatch{}
+
+// WatchAgentConfigs calls onChanged with all the KindlingConfig objects sorted by their namespaces and names
+// whenever any of them is changed. onChanged is called at once if the objects have been listed.
+func WatchAgentConfigs(onChanged func(configs []*AgentConfig)) {
+	agentConfigs.watch(onChanged)
+}
+
+func (w *agentConfigWatch) watch(onChanged func(configs []*AgentConfig)) {
+	w.mutex.Lock()
+	w.watchers = append(w.watchers, onChanged)
+	synced, configs := w.synced, w.configs
+	w.mutex.Unlock()
+	if synced {
+		onChanged(configs)
+	}
+}
+
+// update replaces the objects with the ones in the store and notifies the watchers.
+func (w *agentConfigWatch) update(store cache.Store) {
+	w.updateMutex.Lock()
+	defer w.updateMutex.Unlock()
+	configs := make([]*AgentConfig, 0)
+	for _, item := range store.List() {
+		object, ok := item.(*unstructured.Unstructured)
+		if !ok {
+			continue
+		}
+		spec, _, err := unstructured.NestedMap(object.Object, "spec")
+		if err != nil {
+			log.Printf("The spec of KindlingConfig %s/%s is invalid: %v", object.GetNamespace(), object.GetName(), err)
+			continue
+		}
+		configs = append(configs, &AgentConfig{
+			Namespace: object.GetNamespace(),
+			Name:      object.GetName(),
+			Spec:      spec,
+		})
+	}
+	sort.Slice(configs, func(i, j int) bool {
+		if configs[i].Namespace != configs[j].Namespace {
+			return configs[i].Namespace < configs[j].Namespace
+		}
+		return configs[i].Name < configs[j].Name
+	})
+
+	w.mutex.Lock()
+	w.synced = true
+	w.configs = configs
+	watchers := append([]func(configs []*AgentConfig){}, w.watchers...)
+	w.mutex.Unlock()
+	for _, onChanged := range watchers {
+		onChanged(configs)
+	}
+}
+
+func initAgentConfigWatch(k8sConfig config) error {
+	restConfig, err := createRestConfig(APIConfig{
+		AuthType:     k8sConfig.KubeAuthType,
+		AuthFilePath: k8sConfig.KubeConfigDir,
+	})
+	if err != nil {
+		return fmt.Errorf("cannot connect to kubernetes for the KindlingConfig objects: %w", err)
+	}
+	client, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return fmt.Errorf("cannot connect to kubernetes for the KindlingConfig objects: %w", err)
+	}
+	go AgentConfigWatch(client, k8sConfig.AgentConfigCrd.Namespace, make(chan struct{}))
+	return nil
+}
+
+// AgentConfigWatch watches the KindlingConfig objects until stopCh is closed. The objects are notified
+// once they are listed, and again after each change.
+func AgentConfigWatch(client dynamic.Interface, namespace string, stopCh <-chan struct{}) {
+	factory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(client, 0, namespace, nil)
+	informer := factory.ForResource(KindlingConfigResource).Informer()
+	store := informer.GetStore()
+	// The objects added by the initial list are notified together after the cache is synced.
+	notify := func() {
+		if informer.HasSynced() {
+			agentConfigs.update(store)
+		}
+	}
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { notify() },
+		UpdateFunc: func(oldObj, newObj interface{}) { notify() },
+		DeleteFunc: func(obj interface{}) { notify() },
+	})
+	go informer.Run(stopCh)
+	if !cache.WaitForCacheSync(stopCh, informer.HasSynced) {
+		return
+	}
+	log.Printf("The KindlingConfig objects are listed")
+	agentConfigs.update(store)
+}
@@ -0,0 +1,77 @@
+package kubernetes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+)
+
+func newKindlingConfig(namespace string, name string, ports []interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "kindling.io/v1alpha1",
+		"kind":       "KindlingConfig",
+		"metadata": map[string]interface{}{
+			"namespace": namespace,
+			"name":      name,
+		},
+		"spec": map[string]interface{}{
+			"protocol_config": []interface{}{
+				map[string]interface{}{"key": "mysql", "ports": ports},
+			},
+		},
+	}}
+}
+
+func TestAgentConfigWatch(t *testing.T) {
+	agentConfigs = &agentConfigWatch{}
+	client := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{KindlingConfigResource: "KindlingConfigList"},
+		newKindlingConfig("payment", "mysql", []interface{}{int64(3307)}),
+		newKindlingConfig("order", "mysql", []interface{}{int64(3308)}))
+
+	changes := make(chan []*AgentConfig, 10)
+	WatchAgentConfigs(func(configs []*AgentConfig) {
+		changes <- configs
+	})
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go AgentConfigWatch(client, "", stopCh)
+
+	receive := func() []*AgentConfig {
+		select {
+		case configs := <-changes:
+			return configs
+		case <-time.After(5 * time.Second):
+			t.Fatal("The KindlingConfig objects are not notified")
+			return nil
+		}
+	}
+	// The objects are sorted by their namespaces and names.
+	configs := receive()
+	assert.Equal(t, 2, len(configs))
+	assert.Equal(t, "order", configs[0].Namespace)
+	assert.Equal(t, "payment", configs[1].Namespace)
+	assert.Equal(t, "mysql", configs[1].Name)
+	assert.Equal(t, []interface{}{map[string]interface{}{"key": "mysql", "ports": []interface{}{int64(3307)}}},
+		configs[1].Spec["protocol_config"])
+
+	// The watcher registered later is notified at once.
+	late := make(chan []*AgentConfig, 1)
+	WatchAgentConfigs(func(configs []*AgentConfig) {
+		late <- configs
+	})
+	assert.Equal(t, 2, len(<-late))
+
+	err := client.Resource(KindlingConfigResource).Namespace("order").Delete(context.Background(), "mysql", metav1.DeleteOptions{})
+	assert.NoError(t, err)
+	configs = receive()
+	assert.Equal(t, 1, len(configs))
+	assert.Equal(t, "payment", configs[0].Namespace)
+}
@@ -20,6 +20,8 @@ type config struct {
 	EnableFetchReplicaSet bool
 
 	MetaDataProviderConfig *MetaDataProviderConfig `mapstructure:"metadata_provider_config"`
+	LeaderElection         *LeaderElectionConfig
+	AgentConfigCrd         *AgentConfigCrdConfig
 
 	listAndWatchFromProvider func(setup SetPreprocessingMetaDataCache) error
 	podEventHander           cache.ResourceEventHandler
@@ -76,6 +78,20 @@ func WithMetaDataProviderConfig(mpCfg *MetaDataProviderConfig, listAndWatch func
 	}
 }
 
+// WithLeaderElection sets the election of the agent running the cluster-scoped work.
+func WithLeaderElection(leaderElection *LeaderElectionConfig) Option {
+	return func(cfg *config) {
+		cfg.LeaderElection = leaderElection
+	}
+}
+
+// WithAgentConfigCrd sets the watch of the KindlingConfig objects.
+func WithAgentConfigCrd(agentConfigCrd *AgentConfigCrdConfig) Option {
+	return func(cfg *config) {
+		cfg.AgentConfigCrd = agentConfigCrd
+	}
+}
+
 func WithPodEventHander(handler cache.ResourceEventHandler) Option {
 	return func(cfg *config) {
 		cfg.podEventHander = handler
@@ -80,6 +80,12 @@ func InitK8sHandler(options ...Option) error {
 		} else {
 			retErr = initWatcherFromAPIServer(k8sConfig)
 		}
+		if retErr == nil && k8sConfig.LeaderElection != nil && k8sConfig.LeaderElection.Enable {
+			retErr = initLeaderElection(k8sConfig)
+		}
+		if retErr == nil && k8sConfig.AgentConfigCrd != nil && k8sConfig.AgentConfigCrd.Enable {
+			retErr = initAgentConfigWatch(k8sConfig)
+		}
 	})
 	return retErr
 }
@@ -111,6 +117,16 @@ func initWatcherFromMetadataProvider(k8sConfig config) error {
 	return nil
 }
 
+// initLeaderElection connects to the API-server for the Lease even if the metadata is watched from
+// the metadata-provider.
+func initLeaderElection(k8sConfig config) error {
+	clientSet, err := initClientSet(string(k8sConfig.KubeAuthType), k8sConfig.KubeConfigDir)
+	if err != nil {
+		return fmt.Errorf("cannot connect to kubernetes for the leader election: %w", err)
+	}
+	return startLeaderElection(clientSet, k8sConfig.LeaderElection)
+}
+
 func watchFromMPWithRetry(k8sConfig config) {
 	for {
 		for i := 0; i < 3; i++ {
@@ -130,6 +146,11 @@ func watchFromMPWithRetry(k8sConfig config) {
 	}
 }
 
+// NewClientSet creates a clientSet for the components which watch other resources by themselves.
+func NewClientSet(authType AuthType, kubeConfigDir string) (*k8s.Clientset, error) {
+	return initClientSet(string(authType), kubeConfigDir)
+}
+
 func initClientSet(authType string, dir string) (*k8s.Clientset, error) {
 	return makeClient(APIConfig{
 		AuthType:     AuthType(authType),
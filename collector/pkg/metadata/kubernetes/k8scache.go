@@ -31,6 +31,8 @@ type K8sPodInfo struct {
 	NodeAddress   string
 	isHostNetwork bool
 	ServiceInfo   *K8sServiceInfo
+	// AnalyzerOverrides is nil if the pod has no annotations overriding the network analyzer.
+	AnalyzerOverrides *AnalyzerOverrides
 }
 
 type K8sServiceInfo struct {
@@ -0,0 +1,161 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8s "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+// LeaderElectionConfig elects one agent as the leader through a Lease, and only the leader runs the
+// cluster-scoped work, e.g. watching the events of all the nodes, to reduce the load of the API-server
+// in the large clusters.
+type LeaderElectionConfig struct {
+	Enable bool `mapstructure:"enable"`
+	// LeaseNamespace and LeaseName locate the Lease held by the leader.
+	LeaseNamespace string `mapstructure:"lease_namespace"`
+	LeaseName      string `mapstructure:"lease_name"`
+	// LeaseDuration is how long the other agents wait before taking over the Lease that is not renewed.
+	// The unit is seconds.
+	LeaseDuration int `mapstructure:"lease_duration"`
+	// RenewDeadline is how long the leader retries renewing the Lease before giving up the leadership.
+	// The unit is seconds.
+	RenewDeadline int `mapstructure:"renew_deadline"`
+	// RetryPeriod is the interval between the attempts to acquire or renew the Lease. The unit is seconds.
+	RetryPeriod int `mapstructure:"retry_period"`
+}
+
+func NewDefaultLeaderElectionConfig() *LeaderElectionConfig {
+	return &LeaderElectionConfig{
+		Enable:         false,
+		LeaseNamespace: "kindling",
+		LeaseName:      "kindling-agent-leader",
+		LeaseDuration:  15,
+		RenewDeadline:  10,
+		RetryPeriod:    2,
+	}
+}
+
+type leadership struct {
+	mutex sync.Mutex
+	// enabled is false if the leader election is disabled, and then every agent is leading.
+	enabled          bool
+	leading          bool
+	onStartedLeading []func()
+	onStoppedLeading []func()
+}
+
+var agentLeadership = &leadership{leading: true}
+
+// IsLeader returns whether the agent runs the cluster-scoped work. It is always true if the leader
+// election is disabled.
+func IsLeader() bool {
+	return agentLeadership.isLeading()
+}
+
+// IsLeaderElectionEnabled returns whether the agents elect a leader to run the cluster-scoped work.
+func IsLeaderElectionEnabled() bool {
+	agentLeadership.mutex.Lock()
+	defer agentLeadership.mutex.Unlock()
+	return agentLeadership.enabled
+}
+
+// WatchLeadership calls onStartedLeading when the agent becomes the leader, and onStoppedLeading when it
+// loses the leadership. onStartedLeading is called at once if the agent is leading.
+func WatchLeadership(onStartedLeading func(), onStoppedLeading func()) {
+	agentLeadership.watch(onStartedLeading, onStoppedLeading)
+}
+
+func (l *leadership) isLeading() bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.leading
+}
+
+func (l *leadership) watch(onStartedLeading func(), onStoppedLeading func()) {
+	l.mutex.Lock()
+	l.onStartedLeading = append(l.onStartedLeading, onStartedLeading)
+	l.onStoppedLeading = append(l.onStoppedLeading, onStoppedLeading)
+	leading := l.leading
+	l.mutex.Unlock()
+	if leading {
+		onStartedLeading()
+	}
+}
+
+// enable makes the agent a follower until it is elected.
+func (l *leadership) enable() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.enabled = true
+	l.leading = false
+}
+
+func (l *leadership) setLeading(leading bool) {
+	l.mutex.Lock()
+	if l.leading == leading {
+		l.mutex.Unlock()
+		return
+	}
+	l.leading = leading
+	callbacks := l.onStoppedLeading
+	if leading {
+		callbacks = l.onStartedLeading
+	}
+	callbacks = append([]func(){}, callbacks...)
+	l.mutex.Unlock()
+	for _, callback := range callbacks {
+		callback()
+	}
+}
+
+// startLeaderElection campaigns for the Lease in the background until the process exits. The agent
+// campaigns again after losing the leadership.
+func startLeaderElection(clientSet k8s.Interface, cfg *LeaderElectionConfig) error {
+	identity, ok := os.LookupEnv("MY_NODE_NAME")
+	if !ok || identity == "" {
+		var err error
+		if identity, err = os.Hostname(); err != nil {
+			return fmt.Errorf("cannot get the identity of the agent: %w", err)
+		}
+	}
+	elector, err := leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock: &resourcelock.LeaseLock{
+			LeaseMeta:  metav1.ObjectMeta{Namespace: cfg.LeaseNamespace, Name: cfg.LeaseName},
+			Client:     clientSet.CoordinationV1(),
+			LockConfig: resourcelock.ResourceLockConfig{Identity: identity},
+		},
+		LeaseDuration: time.Duration(cfg.LeaseDuration) * time.Second,
+		RenewDeadline: time.Duration(cfg.RenewDeadline) * time.Second,
+		RetryPeriod:   time.Duration(cfg.RetryPeriod) * time.Second,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(ctx context.Context) {
+				log.Printf("[%s] becomes the leader and runs the cluster-scoped work", identity)
+				agentLeadership.setLeading(true)
+			},
+			OnStoppedLeading: func() {
+				log.Printf("[%s] loses the leadership", identity)
+				agentLeadership.setLeading(false)
+			},
+		},
+		Name: cfg.LeaseName,
+	})
+	if err != nil {
+		return fmt.Errorf("invalid leader election config: %w", err)
+	}
+	agentLeadership.enable()
+	go func() {
+		for {
+			// Run returns when the leadership is lost.
+			elector.Run(context.Background())
+		}
+	}()
+	return nil
+}
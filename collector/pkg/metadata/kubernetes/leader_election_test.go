@@ -0,0 +1,40 @@
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	k8s "k8s.io/client-go/kubernetes"
+)
+
+func TestLeadership(t *testing.T) {
+	l := &leadership{leading: true}
+	started, stopped := 0, 0
+	l.watch(func() { started++ }, func() { stopped++ })
+	// Every agent is leading if the leader election is disabled.
+	assert.Equal(t, 1, started)
+
+	l.enable()
+	assert.False(t, l.isLeading())
+	l.setLeading(true)
+	l.setLeading(true)
+	assert.Equal(t, 2, started)
+	l.setLeading(false)
+	assert.Equal(t, 1, stopped)
+
+	// The watchers added later are not called until the agent leads.
+	added := 0
+	l.watch(func() { added++ }, func() {})
+	assert.Equal(t, 0, added)
+	l.setLeading(true)
+	assert.Equal(t, 1, added)
+	assert.Equal(t, 3, started)
+}
+
+func TestStartLeaderElection_InvalidConfig(t *testing.T) {
+	cfg := NewDefaultLeaderElectionConfig()
+	cfg.RenewDeadline = cfg.LeaseDuration
+	assert.Error(t, startLeaderElection(&k8s.Clientset{}, cfg))
+	assert.False(t, IsLeaderElectionEnabled())
+	assert.True(t, IsLeader())
+}
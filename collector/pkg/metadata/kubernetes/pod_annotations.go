@@ -0,0 +1,60 @@
+package kubernetes
+
+import (
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The annotations of the pods overriding the settings of the network analyzer for their workloads.
+const (
+	// AnnotationSlowThreshold is the slow threshold of all the protocols, e.g. "200ms" or "1s". The number
+	// without a unit is taken as milliseconds.
+	AnnotationSlowThreshold = "kindling.io/slow-threshold"
+	// AnnotationPayload is "off" to drop the payloads of the requests and the responses.
+	AnnotationPayload = "kindling.io/payload"
+)
+
+// AnalyzerOverrides are the settings of the network analyzer overridden by the annotations of the pod.
+type AnalyzerOverrides struct {
+	// SlowThreshold replaces the slow thresholds of the config file if it is positive.
+	SlowThreshold time.Duration
+	// DisablePayload drops the payloads of the requests and the responses.
+	DisablePayload bool
+}
+
+// parseAnalyzerOverrides returns nil if the pod has none of the annotations. The invalid values are
+// logged and ignored.
+func parseAnalyzerOverrides(namespace string, name string, annotations map[string]string) *AnalyzerOverrides {
+	var overrides *AnalyzerOverrides
+	if value, ok := annotations[AnnotationSlowThreshold]; ok {
+		threshold, err := parseSlowThreshold(value)
+		if err != nil || threshold <= 0 {
+			log.Printf("The annotation %s=%q of the pod %s/%s is invalid and ignored", AnnotationSlowThreshold, value, namespace, name)
+		} else {
+			overrides = &AnalyzerOverrides{SlowThreshold: threshold}
+		}
+	}
+	if value, ok := annotations[AnnotationPayload]; ok {
+		switch strings.ToLower(strings.TrimSpace(value)) {
+		case "off", "false":
+			if overrides == nil {
+				overrides = &AnalyzerOverrides{}
+			}
+			overrides.DisablePayload = true
+		case "on", "true":
+		default:
+			log.Printf("The annotation %s=%q of the pod %s/%s is invalid and ignored", AnnotationPayload, value, namespace, name)
+		}
+	}
+	return overrides
+}
+
+func parseSlowThreshold(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if milliseconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(milliseconds) * time.Millisecond, nil
+	}
+	return time.ParseDuration(value)
+}
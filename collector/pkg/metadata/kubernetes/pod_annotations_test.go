@@ -0,0 +1,31 @@
+package kubernetes
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAnalyzerOverrides(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        *AnalyzerOverrides
+	}{
+		{name: "none", annotations: map[string]string{"app": "orders"}, want: nil},
+		{name: "duration", annotations: map[string]string{AnnotationSlowThreshold: "200ms"},
+			want: &AnalyzerOverrides{SlowThreshold: 200 * time.Millisecond}},
+		{name: "milliseconds", annotations: map[string]string{AnnotationSlowThreshold: "1500"},
+			want: &AnalyzerOverrides{SlowThreshold: 1500 * time.Millisecond}},
+		{name: "payload off", annotations: map[string]string{AnnotationSlowThreshold: "1s", AnnotationPayload: "off"},
+			want: &AnalyzerOverrides{SlowThreshold: time.Second, DisablePayload: true}},
+		{name: "payload on", annotations: map[string]string{AnnotationPayload: "on"}, want: nil},
+		{name: "invalid", annotations: map[string]string{AnnotationSlowThreshold: "-1s", AnnotationPayload: "partial"}, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, parseAnalyzerOverrides("default", "orders", tt.annotations))
+		})
+	}
+}
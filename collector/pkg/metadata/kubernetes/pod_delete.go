@@ -15,6 +15,43 @@ var (
 	podDeleteQueue    []deleteRequest
 )
 
+// DeletedPod is the pod removed from the cache after the grace period of its deletion.
+type DeletedPod struct {
+	Namespace    string
+	Name         string
+	ContainerIds []string
+}
+
+var (
+	podDeletionWatchersMut sync.Mutex
+	podDeletionWatchers    []func(pod *DeletedPod)
+)
+
+// WatchPodDeletion calls onDeleted when a deleted pod is removed from the cache, so the components holding
+// the state of the pods could release it.
+func WatchPodDeletion(onDeleted func(pod *DeletedPod)) {
+	podDeletionWatchersMut.Lock()
+	defer podDeletionWatchersMut.Unlock()
+	podDeletionWatchers = append(podDeletionWatchers, onDeleted)
+}
+
+func notifyPodDeletion(podInfo *deletedPodInfo) {
+	podDeletionWatchersMut.Lock()
+	watchers := podDeletionWatchers
+	podDeletionWatchersMut.Unlock()
+	if len(watchers) == 0 {
+		return
+	}
+	pod := &DeletedPod{
+		Namespace:    podInfo.namespace,
+		Name:         podInfo.name,
+		ContainerIds: podInfo.containerIds,
+	}
+	for _, onDeleted := range watchers {
+		onDeleted(pod)
+	}
+}
+
 type deleteRequest struct {
 	podInfo *deletedPodInfo
 	ts      time.Time
@@ -53,6 +90,7 @@ func podDeleteLoop(interval time.Duration, gracePeriod time.Duration, stopCh cha
 			podDeleteQueueMut.Unlock()
 			for _, d := range toDelete {
 				deletePodInfo(d.podInfo)
+				notifyPodDeletion(d.podInfo)
 			}
 
 		case <-stopCh:
@@ -57,3 +57,22 @@ func verifyIfPodExist(exist bool, t *testing.T) {
 		t.Errorf("Finding container using IP:Port. Expect %v, but get %v", exist, ok)
 	}
 }
+
+func TestWatchPodDeletion(t *testing.T) {
+	defer func() {
+		podDeletionWatchers = nil
+	}()
+	deleted := make([]*DeletedPod, 0)
+	WatchPodDeletion(func(pod *DeletedPod) {
+		deleted = append(deleted, pod)
+	})
+	notifyPodDeletion(&deletedPodInfo{name: "deploy-1a2b3c4d-5e6f7", namespace: "CustomNamespace",
+		containerIds: []string{"1a2b3c4d5e6f"}})
+	if len(deleted) != 1 {
+		t.Fatalf("The watcher should be called once, but is called %d times", len(deleted))
+	}
+	if deleted[0].Namespace != "CustomNamespace" || deleted[0].Name != "deploy-1a2b3c4d-5e6f7" ||
+		len(deleted[0].ContainerIds) != 1 || deleted[0].ContainerIds[0] != "1a2b3c4d5e6f" {
+		t.Errorf("Unexpected deleted pod: %+v", deleted[0])
+	}
+}
@@ -238,6 +238,8 @@ func AddPod(obj interface{}) {
 		NodeAddress:   pod.Status.HostIP,
 		isHostNetwork: pod.Spec.HostNetwork,
 		ServiceInfo:   serviceInfo,
+
+		AnalyzerOverrides: parseAnalyzerOverrides(pod.Namespace, pod.Name, pod.Annotations),
 	}
 
 	// Add containerId map
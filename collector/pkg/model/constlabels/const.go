@@ -50,14 +50,43 @@ const (
 	Service         = "service"
 	Pod             = "pod"
 	Container       = "container"
+	EventType       = "event_type"
+	EventReason     = "event_reason"
+	EventMessage    = "event_message"
 	Ip              = "ip"
 	Port            = "port"
 
+	// DnatChain is the addresses of all the DNAT hops separated by commas, which is added only if
+	// there are multiple hops.
+	DnatChain = "dnat_chain"
+
+	// AgentNodeIp and AgentNodeName are the node where the record forwarded to the aggregator is
+	// collected. They are removed once the Kubernetes metadata is added.
+	AgentNodeIp   = "agent_node_ip"
+	AgentNodeName = "agent_node_name"
+
 	// EndTimestamp is the end timestamp of a trace
 	EndTimestamp = "end_timestamp"
 
+	RequestThreadName  = "request_thread_name"
+	ResponseThreadName = "response_thread_name"
+
+	// The time in nanoseconds the request thread spent in the syscalls during a slow request.
+	SyscallReadTime  = "syscall_read_ns"
+	SyscallWriteTime = "syscall_write_ns"
+	SyscallPollTime  = "syscall_poll_ns"
+
+	// DnsTime is the time in nanoseconds the client spent on resolving the destination before the request.
+	DnsTime = "dns_time"
+
+	// FanOutCount is the number of the outbound requests the process sent while handling the inbound
+	// request, and FanOutLatencySum is the sum of their latencies in nanoseconds.
+	FanOutCount      = "fan_out_count"
+	FanOutLatencySum = "fan_out_latency_sum"
+
 	Errno           = "errno"
 	Success         = "success"
+	FailureReason   = "failure_reason"
 	RequestContent  = "request_content"
 	ResponseContent = "response_content"
 	StatusCode      = "status_code"
@@ -83,6 +112,17 @@ const (
 	Timestamp         = "timestamp"
 	IsConvergent      = "is_convergent"
 
+	// AggregationWindow is the name of the window in which the metrics are aggregated.
+	AggregationWindow = "aggregation_window"
+	// IsHealthCheck is true if the request is sent by a health checker, like the probes of kubelet.
+	IsHealthCheck = "is_health_check"
+	// PayloadTruncated is true if the payload of the request or response is truncated by the snaplen,
+	// in which case the labels parsed from the payload could be incomplete.
+	PayloadTruncated = "payload_truncated"
+	// TraceSampled is set by the network analyzer if it has decided whether the normal request is sampled
+	// as a trace, in which case the payloads of the requests sampled away are not built.
+	TraceSampled = "trace_sampled"
+
 	SpanSrcContainerId   = "src_containerid"
 	SpanSrcContainerName = "src_container_name"
 	SpanDstContainerId   = "dst_containerid"
@@ -97,10 +137,27 @@ const (
 	SpanHttpRequestBody     = "http.request_body"
 	SpanHttpResponseHeaders = "http.response_headers"
 	SpanHttpResponseBody    = "http.response_body"
+	SpanHttpSessionHash     = "http.session_hash"
+	SpanHttpSoapOperation   = "http.soap_operation"
+	SpanHttpSoapFault       = "http.soap_fault"
+
+	SpanEsOperation = "es.operation"
+	SpanEsIndex     = "es.index"
+	SpanEsTook      = "es.took"
+	SpanEsStatus    = "es.status"
+
+	SpanInfluxdbMeasurements = "influxdb.measurements"
+	SpanInfluxdbPoints       = "influxdb.points"
+
+	SpanGraphqlOperation     = "graphql.operation"
+	SpanGraphqlOperationType = "graphql.operation_type"
 
 	SpanDnsDomain = "dns.domain"
 	SpanDnsRCode  = "dns.rcode"
 
+	SpanServiceDiscovery   = "service_discovery.system"
+	SpanServiceDiscoveryOp = "service_discovery.operation"
+
 	SpanMysqlSql       = "mysql.sql"
 	SpanMysqlErrorCode = "mysql.error_code"
 	SpanMysqlErrorMsg  = "mysql.error_msg"
@@ -108,15 +165,118 @@ const (
 	SpanDubboErrorCode    = "dubbo.error_code"
 	SpanDubboRequestBody  = "dubbo.request_body"
 	SpanDubboResponseBody = "dubbo.response_body"
+	SpanDubboArgTypes     = "dubbo.argument_types"
+	SpanDubboArgSizes     = "dubbo.argument_sizes"
 
 	SpanRedisCommand         = "redis.command"
 	SpanRedisErrorMsg        = "redis.error_msg"
+	SpanRedisRedirect        = "redis.redirect"
+	SpanRedisRedirectTarget  = "redis.redirect_target"
 	SpanRedisRequestPayload  = "redis.request_payload"
 	SpanRedisResponsePayload = "redis.request_payload"
 
+	SpanKafkaApi       = "kafka.api"
+	SpanKafkaTopic     = "kafka.topic"
+	SpanKafkaGroupId   = "kafka.group_id"
+	SpanKafkaPartition = "kafka.partition"
+
 	SpanRocketMQRequestMsg = "rocketmq.request_msg"
 	SpanRocketMQErrMsg     = "rocketmq.error_msg"
 
+	SpanMongodbCommand    = "mongodb.command"
+	SpanMongodbDatabase   = "mongodb.database"
+	SpanMongodbCollection = "mongodb.collection"
+	SpanMongodbErrorCode  = "mongodb.error_code"
+	SpanMongodbErrorMsg   = "mongodb.error_msg"
+
+	SpanTarsServantName = "tars.servant_name"
+	SpanTarsFuncName    = "tars.func_name"
+	SpanTarsRetCode     = "tars.ret_code"
+	SpanTarsResultDesc  = "tars.result_desc"
+
+	SpanHttp2StreamId = "http2.stream_id"
+
+	SpanGrpcPath       = "grpc.path"
+	SpanGrpcStatusCode = "grpc.status_code"
+	SpanGrpcMessage    = "grpc.message"
+
+	SpanTripleService       = "triple.service"
+	SpanTripleMethod        = "triple.method"
+	SpanTripleVersion       = "triple.version"
+	SpanTripleGroup         = "triple.group"
+	SpanTripleStatusCode    = "triple.status_code"
+	SpanTripleStatus        = "triple.status"
+	SpanTripleMessage       = "triple.message"
+	SpanTripleExceptionCode = "triple.exception_code"
+
+	SpanBrpcService   = "brpc.service"
+	SpanBrpcMethod    = "brpc.method"
+	SpanBrpcErrorCode = "brpc.error_code"
+	SpanBrpcErrorText = "brpc.error_text"
+
+	SpanBoltService        = "bolt.service"
+	SpanBoltMethod         = "bolt.method"
+	SpanBoltResponseStatus = "bolt.response_status"
+
+	SpanCassandraOpcode    = "cassandra.opcode"
+	SpanCassandraKeyspace  = "cassandra.keyspace"
+	SpanCassandraQuery     = "cassandra.query"
+	SpanCassandraErrorCode = "cassandra.error_code"
+	SpanCassandraErrorMsg  = "cassandra.error_msg"
+
+	SpanFtpCommand      = "ftp.command"
+	SpanFtpArgument     = "ftp.argument"
+	SpanFtpReplyCode    = "ftp.reply_code"
+	SpanFtpReplyMessage = "ftp.reply_message"
+
+	SpanSshClientSoftware   = "ssh.client_software"
+	SpanSshServerSoftware   = "ssh.server_software"
+	SpanSshFileTransfer     = "ssh.file_transfer"
+	SpanSshDisconnectReason = "ssh.disconnect_reason"
+
+	SpanMqttPacketType = "mqtt.packet_type"
+	SpanMqttClientId   = "mqtt.client_id"
+	SpanMqttTopic      = "mqtt.topic"
+	SpanMqttQos        = "mqtt.qos"
+	SpanMqttReasonCode = "mqtt.reason_code"
+
+	SpanLdapOperation    = "ldap.operation"
+	SpanLdapDn           = "ldap.dn"
+	SpanLdapResultCode   = "ldap.result_code"
+	SpanLdapErrorMessage = "ldap.error_message"
+
+	SpanOracleFunction  = "oracle.function"
+	SpanOracleService   = "oracle.service"
+	SpanOracleSql       = "oracle.sql"
+	SpanOracleErrorCode = "oracle.error_code"
+	SpanOracleErrorMsg  = "oracle.error_msg"
+
+	SpanPostgresqlDatabase = "postgresql.database"
+	SpanPostgresqlSql      = "postgresql.sql"
+	SpanPostgresqlSqlState = "postgresql.sql_state"
+	SpanPostgresqlErrorMsg = "postgresql.error_msg"
+
+	SpanWebsocketOpcode              = "websocket.opcode"
+	SpanWebsocketDirection           = "websocket.direction"
+	SpanWebsocketPayloadSize         = "websocket.payload_size"
+	SpanWebsocketResponseOpcode      = "websocket.response_opcode"
+	SpanWebsocketResponsePayloadSize = "websocket.response_payload_size"
+	SpanWebsocketCloseCode           = "websocket.close_code"
+
+	SpanQuicSni  = "quic.sni"
+	SpanQuicAlpn = "quic.alpn"
+
+	SpanSipMethod     = "sip.method"
+	SpanSipCallId     = "sip.call_id"
+	SpanSipCseq       = "sip.cseq"
+	SpanSipStatusCode = "sip.status_code"
+
+	SpanTlsSni         = "tls.sni"
+	SpanTlsAlpn        = "tls.alpn"
+	SpanTlsCipherSuite = "tls.cipher_suite"
+	SpanTlsAlert       = "tls.alert"
+
+	SpanProtocolVersion = "protocol_version"
 	SpanRequestPayload  = "request_payload"
 	SpanResponsePayload = "response_payload"
 
@@ -4,6 +4,12 @@ const (
 	ContentKey      = "content_key"
 	RequestPayload  = "request_payload"
 	ResponsePayload = "response_payload"
+	// ProtocolVersion is the version of the protocol carried by the messages, e.g. "1.1" of HTTP and the
+	// api_version of Kafka.
+	ProtocolVersion = "protocol_version"
+	// ContentType is the serialization format of the payload, e.g. "json", "protobuf", "avro" and "form", which
+	// is normalized from the Content-Type of HTTP and the content-type header of the Kafka records.
+	ContentType = "content_type"
 
 	HttpMethod       = "http_method"
 	HttpUrl          = "http_url"
@@ -11,31 +17,226 @@ const (
 	HttpApmTraceId   = "trace_id"
 	HttpStatusCode   = "http_status_code"
 	HttpContinue     = "http_continue"
+	HttpUserAgent    = "http_user_agent"
+	// HttpSessionHash is the hash of the session cookie, which is used to observe the sticky sessions.
+	HttpSessionHash = "http_session_hash"
+	// HttpSoapOperation is the operation of the SOAP or XML-RPC request.
+	HttpSoapOperation = "http_soap_operation"
+	// HttpSoapFault is the fault code of the SOAP or XML-RPC response, e.g. "Server" and "Receiver".
+	HttpSoapFault = "http_soap_fault"
+	// HttpUpgrade is the protocol switched to by the response 101 Switching Protocols, e.g. "websocket".
+	HttpUpgrade = "http_upgrade"
+	// Http2StreamId is the stream of the HTTP/2 request, which pairs the response with it.
+	Http2StreamId = "http2_stream_id"
+
+	// EsOperation and EsIndex are the operation and the normalized indices of the Elasticsearch request.
+	EsOperation = "es_operation"
+	EsIndex     = "es_index"
+	// EsTook is the milliseconds taken by Elasticsearch, which is read from the response.
+	EsTook = "es_took"
+	// EsStatus is the status of the Elasticsearch error response.
+	EsStatus = "es_status"
+
+	// InfluxdbMeasurements are the sorted measurements of the InfluxDB write, and InfluxdbPoints is the
+	// number of the points in the captured body.
+	InfluxdbMeasurements = "influxdb_measurements"
+	InfluxdbPoints       = "influxdb_points"
+
+	// GraphqlOperation is the name of the GraphQL operation, which is absent if the operation is anonymous.
+	// GraphqlOperationType is one of "query", "mutation" and "subscription".
+	GraphqlOperation     = "graphql_operation"
+	GraphqlOperationType = "graphql_operation_type"
 
 	DnsId     = "dns_id"
 	DnsDomain = "dns_domain"
 	DnsRcode  = "dns_rcode"
 	DnsIp     = "dns_ip"
+	// DnsCname is the CNAME chain of the answers, DnsSrv are the targets of the SRV answers as
+	// "target:port" and DnsTxt are the TXT answers. The values of each label are joined by ",".
+	DnsCname = "dns_cname"
+	DnsSrv   = "dns_srv"
+	DnsTxt   = "dns_txt"
+	// DnsEdnsUdpSize is the UDP payload size advertised by the EDNS0 OPT record, DnsEdnsDo is its DO bit and
+	// DnsEdnsClientSubnet is its client subnet option as "address/prefix". The ones of the response are kept.
+	DnsEdnsUdpSize      = "dns_edns_udp_size"
+	DnsEdnsDo           = "dns_edns_do"
+	DnsEdnsClientSubnet = "dns_edns_client_subnet"
+	// DnsAttempts is the number of the identical queries collapsed into one record.
+	DnsAttempts = "dns_attempts"
+	// AmplificationCount is the number of the NXDOMAIN queries expanded from the search domains before
+	// the name is resolved, which are merged into the record of the successful query.
+	AmplificationCount = "amplification_count"
+	// DnsCacheHit is false if the query sent to the NodeLocal DNSCache is forwarded to the upstream.
+	DnsCacheHit        = "dns_cache_hit"
+	DnsUpstreamIp      = "dns_upstream_ip"
+	DnsUpstreamLatency = "dns_upstream_latency"
+
+	// ServiceDiscovery is the service-discovery system the request is sent to, i.e. consul or etcd.
+	ServiceDiscovery = "service_discovery"
+	// ServiceDiscoveryOp is the operation of the service-discovery request, e.g. catalog_query or watch.
+	ServiceDiscoveryOp = "service_discovery_op"
 
 	Oneway = "one_way"
 
 	Sql        = "sql"
 	SqlErrCode = "sql_error_code"
 	SqlErrMsg  = "sql_error_msg"
+	// SqlAffectedRows is the number of the rows changed by the statement, which is only known for the
+	// statements not returning a result set.
+	SqlAffectedRows = "sql_affected_rows"
 
 	RedisCommand = "redis_command"
 	RedisErrMsg  = "redis_error_msg"
+	// RedisRedirect is "moved" or "ask" for the redirections of Redis Cluster, and RedisRedirectTarget is the
+	// address of the node the key is redirected to.
+	RedisRedirect       = "redis_redirect"
+	RedisRedirectTarget = "redis_redirect_target"
 
 	KafkaApi           = "kafka_api"
 	KafkaVersion       = "kafka_version"
 	KafkaCorrelationId = "kafka_id"
 	KafkaTopic         = "kafka_topic"
 	KafkaErrorCode     = "kafka_error_code"
+	// KafkaGroupId is the consumer group of OffsetCommit, JoinGroup and Heartbeat, and KafkaPartition is
+	// the first partition of the first topic.
+	KafkaGroupId   = "kafka_group_id"
+	KafkaPartition = "kafka_partition"
 
 	DubboErrorCode = "dubbo_error_code"
+	// DubboArgumentTypes are the Java types of the arguments separated by commas.
+	DubboArgumentTypes = "dubbo_argument_types"
+	// DubboArgumentSizes are the sizes in bytes of the serialized arguments separated by commas,
+	// which are -1 if the arguments are truncated.
+	DubboArgumentSizes = "dubbo_argument_sizes"
 
 	RocketMQOpaque     = "rocketmq_opaque"
 	RocketMQRequestMsg = "rocketmq_request_msg"
 	RocketMQErrMsg     = "rocketmq_error_msg"
 	RocketMQErrCode    = "rocketmq_error_code"
+
+	MongodbRequestId  = "mongodb_request_id"
+	MongodbCommand    = "mongodb_command"
+	MongodbDatabase   = "mongodb_database"
+	MongodbCollection = "mongodb_collection"
+	MongodbErrCode    = "mongodb_error_code"
+	MongodbErrMsg     = "mongodb_error_msg"
+
+	TarsRequestId   = "tars_request_id"
+	TarsServantName = "tars_servant_name"
+	TarsFuncName    = "tars_func_name"
+	TarsRetCode     = "tars_ret_code"
+	TarsResultDesc  = "tars_result_desc"
+
+	GrpcStreamId   = "grpc_stream_id"
+	GrpcPath       = "grpc_path"
+	GrpcStatusCode = "grpc_status_code"
+	GrpcMessage    = "grpc_message"
+
+	// The Triple (Dubbo3) requests are paired with the responses by Http2StreamId. TripleStatusCode is the
+	// status code of gRPC, which is mapped from the HTTP status if grpc-status is not responded, and
+	// TripleStatus is its name, e.g. "DEADLINE_EXCEEDED". TripleExceptionCode is the code of the exception
+	// thrown by the provider.
+	TripleService       = "triple_service"
+	TripleMethod        = "triple_method"
+	TripleVersion       = "triple_version"
+	TripleGroup         = "triple_group"
+	TripleStatusCode    = "triple_status_code"
+	TripleStatus        = "triple_status"
+	TripleMessage       = "triple_message"
+	TripleExceptionCode = "triple_exception_code"
+
+	BrpcCorrelationId = "brpc_correlation_id"
+	BrpcService       = "brpc_service"
+	BrpcMethod        = "brpc_method"
+	BrpcErrorCode     = "brpc_error_code"
+	BrpcErrorText     = "brpc_error_text"
+
+	BoltRequestId      = "bolt_request_id"
+	BoltService        = "bolt_service"
+	BoltMethod         = "bolt_method"
+	BoltResponseStatus = "bolt_response_status"
+
+	CassandraStreamId = "cassandra_stream_id"
+	CassandraOpcode   = "cassandra_opcode"
+	CassandraKeyspace = "cassandra_keyspace"
+	CassandraQuery    = "cassandra_query"
+	CassandraErrCode  = "cassandra_error_code"
+	CassandraErrMsg   = "cassandra_error_msg"
+
+	FtpCommand      = "ftp_command"
+	FtpArgument     = "ftp_argument"
+	FtpReplyCode    = "ftp_reply_code"
+	FtpReplyMessage = "ftp_reply_message"
+
+	SshClientSoftware   = "ssh_client_software"
+	SshServerSoftware   = "ssh_server_software"
+	SshFileTransfer     = "ssh_file_transfer"
+	SshDisconnectReason = "ssh_disconnect_reason"
+	// SshNewKeys is true if SSH_MSG_NEWKEYS ending the key exchange is found in the request or the response.
+	SshNewKeys = "ssh_new_keys"
+
+	MqttPacketType   = "mqtt_packet_type"
+	MqttResponseType = "mqtt_response_type"
+	MqttPacketId     = "mqtt_packet_id"
+	MqttClientId     = "mqtt_client_id"
+	MqttTopic        = "mqtt_topic"
+	MqttQos          = "mqtt_qos"
+	MqttReasonCode   = "mqtt_reason_code"
+
+	LdapOperation    = "ldap_operation"
+	LdapMessageId    = "ldap_message_id"
+	LdapDn           = "ldap_dn"
+	LdapResultCode   = "ldap_result_code"
+	LdapErrorMessage = "ldap_error_message"
+
+	// The SQL and the errors of Oracle are recorded in the labels of MySQL.
+	OracleFunction = "oracle_function"
+	OracleService  = "oracle_service"
+
+	// The SQL and the errors of PostgreSQL are recorded in the labels of MySQL, except that the codes of
+	// the errors are the SQLSTATEs, e.g. "42P01".
+	PostgresqlDatabase = "postgresql_database"
+	PostgresqlSqlState = "postgresql_sql_state"
+
+	// BulkTransferDirection is "in" or "out" for the streams of the bulk loads and exports, e.g. the
+	// COPY statements of PostgreSQL, and BulkTransferRows is the number of the rows transferred.
+	// BulkTransferPending marks the statement starting a stream which is sent in the next request.
+	BulkTransferDirection = "bulk_transfer_direction"
+	BulkTransferRows      = "bulk_transfer_rows"
+	BulkTransferPending   = "bulk_transfer_pending"
+
+	// WebsocketDirection is "client_to_server" for the masked frames and "server_to_client" for the others.
+	WebsocketOpcode              = "websocket_opcode"
+	WebsocketDirection           = "websocket_direction"
+	WebsocketPayloadSize         = "websocket_payload_size"
+	WebsocketResponseOpcode      = "websocket_response_opcode"
+	WebsocketResponsePayloadSize = "websocket_response_payload_size"
+	WebsocketCloseCode           = "websocket_close_code"
+
+	// QuicSni is the server name of the ClientHello in the Initial packets, and QuicAlpn is the protocols
+	// offered by the client joined by commas, e.g. "h3" for HTTP/3.
+	QuicSni  = "quic_sni"
+	QuicAlpn = "quic_alpn"
+
+	// NtpMode is the mode of the NTP request, e.g. "client", and NtpStratum is the stratum of the server.
+	// NtpOffset is the offset of the local clock against the server in nanoseconds, which is positive if
+	// the local clock is behind. NtpKissCode is the code of the Kiss-o'-Death response, e.g. "RATE".
+	NtpMode     = "ntp_mode"
+	NtpStratum  = "ntp_stratum"
+	NtpOffset   = "ntp_offset"
+	NtpKissCode = "ntp_kiss_code"
+
+	// SipMethod is the method of the request, e.g. "INVITE", and SipCseq is the sequence number of its CSeq
+	// header. SipStatusCode is the status code of the final response, as the provisional ones are skipped.
+	SipMethod     = "sip_method"
+	SipCallId     = "sip_call_id"
+	SipCseq       = "sip_cseq"
+	SipStatusCode = "sip_status_code"
+
+	// TlsAlpn is the protocols offered by the client joined by commas, and TlsAlert is the description of
+	// the alert sent by the server instead of the ServerHello, e.g. 40 for handshake_failure.
+	TlsSni         = "tls_sni"
+	TlsAlpn        = "tls_alpn"
+	TlsCipherSuite = "tls_cipher_suite"
+	TlsAlert       = "tls_alert"
 )
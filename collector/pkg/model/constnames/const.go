@@ -15,6 +15,13 @@ const (
 	SendMMsgEvent = "sendmmsg"
 	RecvMsgEvent  = "recvmsg"
 	ConnectEvent  = "connect"
+	AcceptEvent   = "accept"
+	Accept4Event  = "accept4"
+
+	EpollWaitEvent = "epoll_wait"
+	PollEvent      = "poll"
+	PpollEvent     = "ppoll"
+	SelectEvent    = "select"
 
 	TcpCloseEvent          = "tcp_close"
 	TcpRcvEstablishedEvent = "tcp_rcv_established"
@@ -46,4 +53,20 @@ const (
 	NodeMetricGroupName          = "node_metric_metric_group"
 	TcpConnectMetricGroupName    = "tcp_connect_metric_group"
 	K8sWorkloadMetricGroupName   = "k8s_workload_metric_group"
+	// WorkloadRequestMetricGroupName stands for the dataGroup of requests rolled up by the server-side workload.
+	WorkloadRequestMetricGroupName = "workload_request_metric_group"
+	// ConnectionPoolMetricGroupName stands for the dataGroup of the connection reuse of each destination.
+	ConnectionPoolMetricGroupName = "connection_pool_metric_group"
+	// ProcessSocketMetricGroupName stands for the dataGroup of the sockets opened by each process.
+	ProcessSocketMetricGroupName = "process_socket_metric_group"
+	// ServerQueueMetricGroupName stands for the dataGroup of the time between accepting a connection and reading from it.
+	ServerQueueMetricGroupName = "server_queue_metric_group"
+	// BulkTransferMetricGroupName stands for the dataGroup of the bulk loads and exports, e.g. COPY of PostgreSQL.
+	BulkTransferMetricGroupName = "bulk_transfer_metric_group"
+	// ContainerProtocolMetricGroupName stands for the dataGroup of the protocols each container has been observed speaking.
+	ContainerProtocolMetricGroupName = "container_protocol_metric_group"
+	// ThreadPoolMetricGroupName stands for the dataGroup of the threads handling the requests of each process.
+	ThreadPoolMetricGroupName = "thread_pool_metric_group"
+	// K8sContainerEventGroupName stands for the dataGroup of container restarts, image pulls, etc.
+	K8sContainerEventGroupName = "k8s_container_event_group"
 )
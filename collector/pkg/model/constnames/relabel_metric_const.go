@@ -10,6 +10,11 @@ var metricNameDictionary = map[string]map[bool]string{
 	constvalues.RequestCount:              {true: EntityRequestCountMetric, false: TopologyRequestCountMetric},
 	constvalues.RequestTotalTime + "_avg": {true: EntityRequestLatencyAverageMetric, false: TopologyRequestLatencyAverageMetric},
 	constvalues.RequestTimeHistogram:      {true: EntityRequestTimeHistogramMetric, false: TopologyRequestTimeHistogramMetric},
+	// The phases of the requests, whose sum is the duration
+	constvalues.ConnectTime + "_avg":         {true: EntityConnectLatencyAverageMetric, false: TopologyConnectLatencyAverageMetric},
+	constvalues.RequestSentTime + "_avg":     {true: EntitySentLatencyAverageMetric, false: TopologySentLatencyAverageMetric},
+	constvalues.WaitingTtfbTime + "_avg":     {true: EntityWaitingTtfbLatencyAverageMetric, false: TopologyWaitingTtfbLatencyAverageMetric},
+	constvalues.ContentDownloadTime + "_avg": {true: EntityDownloadLatencyAverageMetric, false: TopologyDownloadLatencyAverageMetric},
 }
 
 const (
@@ -21,6 +26,11 @@ const (
 	TopologyRequestCountMetric          = "total"
 	// TopologyRequestTimeHistogramMetric is a histogram
 	TopologyRequestTimeHistogramMetric = "request_time_histogram"
+	// The phases of the requests are histograms like TopologyRequestLatencyAverageMetric.
+	TopologyConnectLatencyAverageMetric     = "connect_average_duration_nanoseconds"
+	TopologySentLatencyAverageMetric        = "sent_average_duration_nanoseconds"
+	TopologyWaitingTtfbLatencyAverageMetric = "waiting_ttfb_average_duration_nanoseconds"
+	TopologyDownloadLatencyAverageMetric    = "download_average_duration_nanoseconds"
 
 	EntityRequestIoMetric  = "receive_bytes_total"
 	EntityResponseIoMetric = "send_bytes_total"
@@ -29,15 +39,56 @@ const (
 	EntityRequestLatencyTotalMetric   = "duration_nanoseconds_total"
 	EntityRequestCountMetric          = "total"
 	EntityRequestTimeHistogramMetric  = "request_time_histogram"
+	// The phases of the requests are histograms like EntityRequestLatencyAverageMetric.
+	EntityConnectLatencyAverageMetric     = "connect_average_duration_nanoseconds"
+	EntitySentLatencyAverageMetric        = "sent_average_duration_nanoseconds"
+	EntityWaitingTtfbLatencyAverageMetric = "waiting_ttfb_average_duration_nanoseconds"
+	EntityDownloadLatencyAverageMetric    = "download_average_duration_nanoseconds"
 
 	TraceAsMetric           = NPMPrefixKindling + "_trace_request_duration_nanoseconds"
 	TcpRttMetricName        = "kindling_tcp_srtt_microseconds"
 	TcpRetransmitMetricName = "kindling_tcp_retransmit_total"
 	TcpDropMetricName       = "kindling_tcp_packet_loss_total"
 	K8sWorkLoadMetricName   = "kindling_k8s_workload_info"
+	// WorkloadRequestDurationMetric is the duration of each request used to build the workload metrics.
+	WorkloadRequestDurationMetric      = "kindling_workload_request_duration_nanoseconds"
+	WorkloadRequestDurationTotalMetric = "kindling_workload_request_duration_nanoseconds_total"
+	WorkloadRequestTotalMetric         = "kindling_workload_request_total"
+	WorkloadRequestErrorTotalMetric    = "kindling_workload_request_error_total"
+	// K8sContainerEventMetricName is the count of the container events.
+	K8sContainerEventMetricName = "kindling_k8s_container_event_total"
 
 	TcpConnectTotalMetric    = "kindling_tcp_connect_total"
 	TcpConnectDurationMetric = "kindling_tcp_connect_duration_nanoseconds_total"
+
+	ConnectionPoolConnectTotalMetric = "kindling_connection_pool_connect_total"
+	ConnectionPoolRequestTotalMetric = "kindling_connection_pool_request_total"
+	// ConnectionReuseRatioMetric is the percentage of the requests sent over the reused connections.
+	ConnectionReuseRatioMetric = "kindling_connection_reuse_ratio"
+	ProcessOpenSocketsMetric   = "kindling_process_open_sockets"
+
+	// ServerQueueTimeMetric is the time between accepting a connection and reading the first request from it.
+	ServerQueueTimeMetric      = "kindling_server_queue_time_nanoseconds"
+	ServerQueueTimeTotalMetric = "kindling_server_queue_time_nanoseconds_total"
+	ServerQueueTotalMetric     = "kindling_server_queue_total"
+
+	// BulkTransferBytesMetric, BulkTransferRowsMetric and BulkTransferDurationMetric are the bytes, the rows and the
+	// duration of a bulk transfer, which is not counted as a request so the latencies of the queries are not skewed.
+	BulkTransferBytesMetric         = "kindling_bulk_transfer_bytes"
+	BulkTransferRowsMetric          = "kindling_bulk_transfer_rows"
+	BulkTransferDurationMetric      = "kindling_bulk_transfer_duration_nanoseconds"
+	BulkTransferBytesTotalMetric    = "kindling_bulk_transfer_bytes_total"
+	BulkTransferRowsTotalMetric     = "kindling_bulk_transfer_rows_total"
+	BulkTransferDurationTotalMetric = "kindling_bulk_transfer_duration_nanoseconds_total"
+	BulkTransferTotalMetric         = "kindling_bulk_transfer_total"
+
+	// ContainerProtocolInfoMetric is always 1 and its labels tell the protocol and the port spoken by the container.
+	ContainerProtocolInfoMetric = "kindling_container_protocol_info"
+
+	// ProcessHandlingThreadsMetric is the number of the threads handling the server-side requests of the process, and
+	// ProcessThreadSaturationMetric is the percentage of the time they are busy with the requests.
+	ProcessHandlingThreadsMetric  = "kindling_process_handling_threads"
+	ProcessThreadSaturationMetric = "kindling_process_thread_saturation_ratio"
 )
 
 const (
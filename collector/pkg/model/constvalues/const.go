@@ -19,16 +19,37 @@ const (
 
 	ProtocolErrorStatus   = "1"
 	ProtocolNoErrorStatus = "0"
+
+	// BulkTransferIn and BulkTransferOut are the directions of the bulk transfers, loading the rows into the
+	// server or exporting them from it.
+	BulkTransferIn  = "in"
+	BulkTransferOut = "out"
 )
 
 const (
-	ProtocolHttp     = "http"
-	ProtocolHttp2    = "http2"
-	ProtocolGrpc     = "grpc"
-	ProtocolDubbo    = "dubbo"
-	ProtocolDns      = "dns"
-	ProtocolKafka    = "kafka"
-	ProtocolMysql    = "mysql"
-	ProtocolRedis    = "redis"
-	ProtocolRocketMQ = "rocketmq"
+	ProtocolHttp       = "http"
+	ProtocolHttp2      = "http2"
+	ProtocolGrpc       = "grpc"
+	ProtocolDubbo      = "dubbo"
+	ProtocolDns        = "dns"
+	ProtocolKafka      = "kafka"
+	ProtocolMysql      = "mysql"
+	ProtocolRedis      = "redis"
+	ProtocolRocketMQ   = "rocketmq"
+	ProtocolMongodb    = "mongodb"
+	ProtocolTars       = "tars"
+	ProtocolBrpc       = "brpc"
+	ProtocolBolt       = "bolt"
+	ProtocolCassandra  = "cassandra"
+	ProtocolFtp        = "ftp"
+	ProtocolSsh        = "ssh"
+	ProtocolMqtt       = "mqtt"
+	ProtocolLdap       = "ldap"
+	ProtocolOracle     = "oracle"
+	ProtocolPostgresql = "postgresql"
+	ProtocolWebsocket  = "websocket"
+	ProtocolQuic       = "quic"
+	ProtocolSip        = "sip"
+	ProtocolTls        = "tls"
+	ProtocolTriple     = "triple"
 )
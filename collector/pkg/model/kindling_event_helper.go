@@ -5,6 +5,7 @@ import (
 	"errors"
 	"math"
 	"net"
+	"unsafe"
 
 	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
 )
@@ -20,11 +21,12 @@ var (
 	byteOrder           = getByteOrder()
 )
 
+// getByteOrder returns the native byte order, in which the probe stores the values of user attributes.
 func getByteOrder() binary.ByteOrder {
-	// Check if littleendian or bigendian
+	// Check if littleendian or bigendian by the first byte in memory.
+	// Note converting the integer directly, e.g. byte(int8(s)), always returns the lower byte.
 	s := int16(0x1234)
-	littleVal := byte(0x34)
-	if littleVal == byte(int8(s)) {
+	if *(*byte)(unsafe.Pointer(&s)) == 0x34 {
 		return binary.LittleEndian
 	}
 	return binary.BigEndian
@@ -279,6 +281,10 @@ func (k *KindlingEvent) IsConnect() bool {
 	return k.Name == "connect"
 }
 
+func (k *KindlingEvent) IsAccept() bool {
+	return k.Name == constnames.AcceptEvent || k.Name == constnames.Accept4Event
+}
+
 func (k *KindlingEvent) IsRequest() (bool, error) {
 	if k.Category == Category_CAT_NET {
 		switch k.Name {
@@ -1,6 +1,8 @@
 package model
 
 import (
+	"encoding/binary"
+	"runtime"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -113,3 +115,12 @@ func TestGetDoubleUserAttribute(t *testing.T) {
 		})
 	}
 }
+
+func TestGetByteOrder(t *testing.T) {
+	bigEndianArchs := map[string]bool{"s390x": true, "ppc64": true, "mips": true, "mips64": true}
+	if bigEndianArchs[runtime.GOARCH] {
+		assert.Equal(t, binary.BigEndian, getByteOrder())
+	} else {
+		assert.Equal(t, binary.LittleEndian, getByteOrder())
+	}
+}
@@ -0,0 +1,122 @@
+// Package timeunit provides the types for the timestamps and the durations carried by the events and
+// the data groups. The events are always stamped in nanoseconds, so the values should be converted
+// with these types instead of dividing by the magic numbers, and converted to the configured unit only
+// when they are exported.
+package timeunit
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Unit is the unit of the exported timestamps or durations.
+type Unit string
+
+const (
+	Nanosecond  Unit = "ns"
+	Microsecond Unit = "us"
+	Millisecond Unit = "ms"
+	Second      Unit = "s"
+)
+
+// Parse returns the unit named by s. The aliases "µs", "nanosecond", "microsecond", "millisecond" and
+// "second" are also accepted.
+func Parse(s string) (Unit, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "ns", "nanosecond", "nanoseconds":
+		return Nanosecond, nil
+	case "us", "µs", "microsecond", "microseconds":
+		return Microsecond, nil
+	case "ms", "millisecond", "milliseconds":
+		return Millisecond, nil
+	case "s", "second", "seconds":
+		return Second, nil
+	default:
+		return "", fmt.Errorf("unknown time unit %q, must be one of ns, us, ms or s", s)
+	}
+}
+
+// Nanoseconds returns the number of the nanoseconds in one unit.
+func (u Unit) Nanoseconds() int64 {
+	switch u {
+	case Microsecond:
+		return int64(time.Microsecond)
+	case Millisecond:
+		return int64(time.Millisecond)
+	case Second:
+		return int64(time.Second)
+	default:
+		return 1
+	}
+}
+
+// Suffix returns the suffix of the label keys carrying the values in this unit, like "_ms".
+func (u Unit) Suffix() string {
+	if u == "" {
+		return "_" + string(Nanosecond)
+	}
+	return "_" + string(u)
+}
+
+// Timestamp is the time since the Unix epoch in nanoseconds, which is how the events are stamped.
+type Timestamp uint64
+
+// Now returns the current timestamp from the wall clock.
+func Now() Timestamp {
+	return FromTime(time.Now())
+}
+
+// FromTime returns the timestamp of t.
+func FromTime(t time.Time) Timestamp {
+	return Timestamp(t.UnixNano())
+}
+
+// Unix returns the timestamp of the whole seconds sec since the Unix epoch.
+func Unix(sec uint64) Timestamp {
+	return Timestamp(sec * uint64(time.Second))
+}
+
+// Time returns the timestamp as a time.Time.
+func (t Timestamp) Time() time.Time {
+	return time.Unix(0, int64(t))
+}
+
+// In returns the timestamp in the unit u, truncated.
+func (t Timestamp) In(u Unit) int64 {
+	return int64(t) / u.Nanoseconds()
+}
+
+// Seconds returns the whole seconds of the timestamp.
+func (t Timestamp) Seconds() uint64 {
+	return uint64(t.In(Second))
+}
+
+// Sub returns the duration t-u.
+func (t Timestamp) Sub(u Timestamp) Duration {
+	return Duration(int64(t) - int64(u))
+}
+
+// Add returns the timestamp t+d.
+func (t Timestamp) Add(d Duration) Timestamp {
+	return Timestamp(int64(t) + int64(d))
+}
+
+// Duration is the elapsed time in nanoseconds, which is how the latencies are measured.
+// It is convertible to time.Duration.
+type Duration int64
+
+// FromSeconds returns the duration of n seconds, which is how most of the timeouts are configured.
+func FromSeconds(n int) Duration {
+	return Duration(int64(n) * int64(time.Second))
+}
+
+// In returns the duration in the unit u, truncated.
+func (d Duration) In(u Unit) int64 {
+	return int64(d) / u.Nanoseconds()
+}
+
+// Std returns the duration as a time.Duration.
+func (d Duration) Std() time.Duration {
+	return time.Duration(d)
+}
@@ -0,0 +1,49 @@
+package timeunit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := map[string]Unit{
+		"ns":           Nanosecond,
+		"us":           Microsecond,
+		"µs":           Microsecond,
+		"ms":           Millisecond,
+		" Millisecond": Millisecond,
+		"s":            Second,
+	}
+	for name, want := range tests {
+		got, err := Parse(name)
+		assert.NoError(t, err, name)
+		assert.Equal(t, want, got, name)
+	}
+	_, err := Parse("minute")
+	assert.Error(t, err)
+}
+
+func TestTimestamp_In(t *testing.T) {
+	ts := Timestamp(1_650_000_000_123_456_789)
+	assert.Equal(t, int64(1_650_000_000_123_456_789), ts.In(Nanosecond))
+	assert.Equal(t, int64(1_650_000_000_123_456), ts.In(Microsecond))
+	assert.Equal(t, int64(1_650_000_000_123), ts.In(Millisecond))
+	assert.Equal(t, int64(1_650_000_000), ts.In(Second))
+	assert.Equal(t, uint64(1_650_000_000), ts.Seconds())
+	assert.Equal(t, ts, FromTime(ts.Time()))
+}
+
+func TestTimestamp_Sub(t *testing.T) {
+	start := Timestamp(10 * time.Second)
+	end := start.Add(FromSeconds(3))
+	assert.Equal(t, 3*time.Second, end.Sub(start).Std())
+	assert.Equal(t, int64(-3000), start.Sub(end).In(Millisecond))
+}
+
+func TestUnit_Suffix(t *testing.T) {
+	assert.Equal(t, "_ns", Unit("").Suffix())
+	assert.Equal(t, "_us", Microsecond.Suffix())
+	assert.Equal(t, "_s", Second.Suffix())
+}
@@ -17,10 +17,31 @@ type PrometheusConfig struct {
 type OtlpGrpcConfig struct {
 	CollectPeriod time.Duration `mapstructure:"collect_period,omitempty"`
 	Endpoint      string        `mapstructure:"endpoint,omitempty"`
+	ExtraMetrics  []string      `mapstructure:"extra_metrics"`
 }
 
 type StdoutConfig struct {
 	CollectPeriod time.Duration `mapstructure:"collect_period,omitempty"`
+	ExtraMetrics  []string      `mapstructure:"extra_metrics"`
+}
+
+// getExtraMetrics returns the extra metrics configured for the current exporter kind.
+func (c *Config) getExtraMetrics() []string {
+	switch c.ExportKind {
+	case PrometheusKindExporter:
+		if c.PromCfg != nil {
+			return c.PromCfg.ExtraMetrics
+		}
+	case OtlpGrpcKindExporter:
+		if c.OtlpGrpcCfg != nil {
+			return c.OtlpGrpcCfg.ExtraMetrics
+		}
+	case StdoutKindExporter:
+		if c.StdoutCfg != nil {
+			return c.StdoutCfg.ExtraMetrics
+		}
+	}
+	return nil
 }
 
 var DefaultConfig = Config{
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"runtime"
 	"sync"
 	"time"
 
@@ -40,15 +41,24 @@ var (
 	selfTelemetryOnce   sync.Once
 	agentCPUTimeSeconds metric.Float64CounterObserver
 	agentMemUsedBytes   metric.Float64GaugeObserver
+
+	runtimeTelemetryOnce sync.Once
 )
 
 const (
 	resourcePerformance = "resource"
+	runtimePerformance  = "runtime"
 )
 
 const (
 	agentCPUTimeSecondsMetric  = "kindling_telemetry_agent_cpu_time_seconds"
 	agentMemoryUsedBytesMetric = "kindling_telemetry_agent_memory_used_bytes"
+
+	agentGoroutinesMetric       = "kindling_telemetry_agent_goroutines"
+	agentGCCountMetric          = "kindling_telemetry_agent_gc_count"
+	agentGCPauseSecondsMetric   = "kindling_telemetry_agent_gc_pause_seconds"
+	agentHeapAllocBytesMetric   = "kindling_telemetry_agent_heap_alloc_bytes"
+	agentHeapObjectsCountMetric = "kindling_telemetry_agent_heap_objects_count"
 )
 
 type otelLoggerHandler struct {
@@ -64,6 +74,8 @@ func RegisterExtraMetrics(selectors []string, mp metric.MeterProvider) {
 		switch selector {
 		case resourcePerformance:
 			registerAgentResourcePerformanceMetrics(mp)
+		case runtimePerformance:
+			registerAgentRuntimeMetrics(mp)
 		}
 	}
 }
@@ -92,6 +104,47 @@ func registerAgentResourcePerformanceMetrics(mp metric.MeterProvider) (err error
 	return nil
 }
 
+// registerAgentRuntimeMetrics registers the metrics of the Go runtime, such as GC and goroutines.
+// All of them are observed in one batch so that runtime.ReadMemStats is called only once per collection.
+func registerAgentRuntimeMetrics(mp metric.MeterProvider) (err error) {
+	meter := mp.Meter("kindling")
+	runtimeTelemetryOnce.Do(func() {
+		var (
+			goroutines    metric.Int64GaugeObserver
+			gcCount       metric.Int64CounterObserver
+			gcPause       metric.Float64CounterObserver
+			heapAlloc     metric.Int64GaugeObserver
+			heapObjects   metric.Int64GaugeObserver
+			batchObserver metric.BatchObserver
+		)
+		batchObserver = meter.NewBatchObserver(func(ctx context.Context, result metric.BatchObserverResult) {
+			var memStats runtime.MemStats
+			runtime.ReadMemStats(&memStats)
+			result.Observe(nil,
+				goroutines.Observation(int64(runtime.NumGoroutine())),
+				gcCount.Observation(int64(memStats.NumGC)),
+				gcPause.Observation(float64(memStats.PauseTotalNs)/float64(time.Second)),
+				heapAlloc.Observation(int64(memStats.HeapAlloc)),
+				heapObjects.Observation(int64(memStats.HeapObjects)),
+			)
+		})
+		if goroutines, err = batchObserver.NewInt64GaugeObserver(agentGoroutinesMetric); err != nil {
+			return
+		}
+		if gcCount, err = batchObserver.NewInt64CounterObserver(agentGCCountMetric); err != nil {
+			return
+		}
+		if gcPause, err = batchObserver.NewFloat64CounterObserver(agentGCPauseSecondsMetric); err != nil {
+			return
+		}
+		if heapAlloc, err = batchObserver.NewInt64GaugeObserver(agentHeapAllocBytesMetric); err != nil {
+			return
+		}
+		heapObjects, err = batchObserver.NewInt64GaugeObserver(agentHeapObjectsCountMetric)
+	})
+	return err
+}
+
 func InitTelemetry(logger *zap.Logger, config *Config) (metric.MeterProvider, error) {
 	otel.SetErrorHandler(&otelLoggerHandler{logger: logger})
 	hostName, err := os.Hostname()
@@ -141,7 +194,7 @@ func InitTelemetry(logger *zap.Logger, config *Config) (metric.MeterProvider, er
 		}()
 
 		mp := exp.MeterProvider()
-		RegisterExtraMetrics(config.PromCfg.ExtraMetrics, mp)
+		RegisterExtraMetrics(config.getExtraMetrics(), mp)
 		return mp, nil
 	} else {
 		var collectPeriod time.Duration
@@ -170,6 +223,7 @@ func InitTelemetry(logger *zap.Logger, config *Config) (metric.MeterProvider, er
 			return nil, fmt.Errorf("failed to start self-telemetry controller: %w", err)
 		}
 
+		RegisterExtraMetrics(config.getExtraMetrics(), cont)
 		return cont, nil
 	}
 }
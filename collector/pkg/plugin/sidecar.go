@@ -0,0 +1,104 @@
+// Package plugin is the API of the sidecar plugins consuming the records of the agent. The agent streams
+// the records to the sidecar with the pluginexporter over gRPC, usually through a Unix domain socket,
+// so the teams could build their own sinks without modifying the collector.
+//
+// A sidecar implements consumer.Consumer and serves it:
+//
+//	server, err := plugin.NewServer(sink, plugin.Config{})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	log.Fatal(server.ListenAndServe("/var/run/kindling/plugin.sock"))
+//
+// The stream is the Forwarder service of the aggregator, see pkg/forward/forward.proto, so the
+// sidecars could also be written in other languages with the proto file.
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer"
+	"github.com/Kindling-project/kindling/collector/pkg/forward"
+)
+
+// SchemaVersion is the version of the records the sidecar understands. The agents sending the records
+// of a newer version are refused, so the sidecar doesn't consume the records it can't read.
+const SchemaVersion = forward.SchemaVersion
+
+type Config struct {
+	// Window is the number of the batches the agent could send before they are consumed. The agent stops
+	// reading the records once the window is used up, and drops the new records once its queue is full.
+	// It is 4 if not set.
+	Window uint32
+	// Logger logs the errors returned by the sink. Nothing is logged if it is nil.
+	Logger *zap.Logger
+}
+
+// Server serves the sink to the agent.
+type Server struct {
+	server *forward.Server
+}
+
+// sinkHandler passes the records to the sink one by one. A batch is acknowledged after all of its
+// records are consumed, so a slow sink pushes back on the agent instead of buffering the records.
+type sinkHandler struct {
+	sink   consumer.Consumer
+	logger *zap.Logger
+}
+
+func (h *sinkHandler) Consume(hello *forward.Hello, records []*forward.Record) {
+	for _, record := range records {
+		if err := h.sink.Consume(record.DataGroup()); err != nil {
+			h.logger.Warn("The sink fails to consume the record", zap.String("name", record.Name),
+				zap.String("node", hello.NodeName), zap.Error(err))
+		}
+	}
+}
+
+func NewServer(sink consumer.Consumer, config Config) (*Server, error) {
+	if config.Window == 0 {
+		config.Window = 4
+	}
+	logger := config.Logger
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	server, err := forward.NewServer(&sinkHandler{sink: sink, logger: logger}, forward.ServerConfig{
+		Window: config.Window,
+		// The sidecar serves the agent of its own pod, which resumes the session after reconnecting.
+		SessionTimeout: time.Minute,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Server{server: server}, nil
+}
+
+// ListenAndServe listens on the Unix domain socket at path and blocks until the server is stopped. The
+// socket left by the previous sidecar is removed.
+func (s *Server) ListenAndServe(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("fail to remove the socket [%s]: %w", path, err)
+	}
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return fmt.Errorf("fail to listen on the socket [%s]: %w", path, err)
+	}
+	return s.Serve(listener)
+}
+
+// Serve blocks until the listener fails or the server is stopped.
+func (s *Server) Serve(listener net.Listener) error {
+	return s.server.Serve(listener)
+}
+
+// Stop waits until the batches being consumed are acknowledged.
+func (s *Server) Stop() {
+	s.server.Stop()
+}
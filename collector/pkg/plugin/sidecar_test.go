@@ -0,0 +1,70 @@
+package plugin
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Kindling-project/kindling/collector/pkg/forward"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constlabels"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constnames"
+	"github.com/Kindling-project/kindling/collector/pkg/model/constvalues"
+)
+
+type recordingSink struct {
+	mutex      sync.Mutex
+	dataGroups []*model.DataGroup
+}
+
+func (s *recordingSink) Consume(dataGroup *model.DataGroup) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.dataGroups = append(s.dataGroups, dataGroup)
+	return nil
+}
+
+func (s *recordingSink) get() []*model.DataGroup {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]*model.DataGroup(nil), s.dataGroups...)
+}
+
+func TestServer_ListenAndServe(t *testing.T) {
+	sink := &recordingSink{}
+	server, err := NewServer(sink, Config{})
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "plugin.sock")
+	go func() {
+		_ = server.ListenAndServe(path)
+	}()
+	t.Cleanup(server.Stop)
+
+	client, err := forward.NewClient(forward.ClientConfig{Endpoint: "unix://" + path, NodeName: "node1"})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = client.Close()
+	})
+	labels := model.NewAttributeMap()
+	labels.AddStringValue(constlabels.DstPod, "pod1")
+	dataGroup := model.NewDataGroup(constnames.SingleNetRequestMetricGroup, labels, 1000,
+		model.NewIntMetric(constvalues.RequestTotalTime, 200))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, client.Send(ctx, []*forward.Record{forward.NewRecord(dataGroup)}))
+
+	require.Eventually(t, func() bool {
+		return len(sink.get()) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	got := sink.get()[0]
+	assert.Equal(t, constnames.SingleNetRequestMetricGroup, got.Name)
+	assert.Equal(t, "pod1", got.Labels.GetStringValue(constlabels.DstPod))
+	totalTime, ok := got.GetMetric(constvalues.RequestTotalTime)
+	require.True(t, ok)
+	assert.Equal(t, int64(200), totalTime.GetInt().Value)
+}
@@ -0,0 +1,208 @@
+package testbed
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+)
+
+// Fixture is a recorded trace of one connection. It uses the same format as the files under
+// pkg/component/analyzer/network/protocol/testdata: the event file holds the context shared by
+// all events, and the trace file holds the events and the records expected from them.
+type Fixture struct {
+	Common *EventCommon
+	Trace  *Trace
+}
+
+// LoadFixture reads the event file and the trace file.
+func LoadFixture(eventPath string, tracePath string) (*Fixture, error) {
+	common := &EventCommon{}
+	if err := unmarshalFile(eventPath, "eventCommon", common); err != nil {
+		return nil, err
+	}
+	trace := &Trace{}
+	if err := unmarshalFile(tracePath, "trace", trace); err != nil {
+		return nil, err
+	}
+	return &Fixture{Common: common, Trace: trace}, nil
+}
+
+func unmarshalFile(path string, key string, rawVal interface{}) error {
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if err := v.UnmarshalKey(key, rawVal); err != nil {
+		return fmt.Errorf("failed to unmarshal %s: %w", path, err)
+	}
+	return nil
+}
+
+// Events returns the events of the trace sorted by the timestamp.
+func (f *Fixture) Events() ([]*model.KindlingEvent, error) {
+	traceEvents := make([]TraceEvent, 0, len(f.Trace.Connects)+len(f.Trace.Requests)+len(f.Trace.Responses))
+	traceEvents = append(traceEvents, f.Trace.Connects...)
+	traceEvents = append(traceEvents, f.Trace.Requests...)
+	traceEvents = append(traceEvents, f.Trace.Responses...)
+	events := make([]*model.KindlingEvent, 0, len(traceEvents))
+	for _, traceEvent := range traceEvents {
+		event, err := traceEvent.toKindlingEvent(f.Common)
+		if err != nil {
+			return nil, fmt.Errorf("invalid event %s of trace %s: %w", traceEvent.Name, f.Trace.Key, err)
+		}
+		events = append(events, event)
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Timestamp < events[j].Timestamp
+	})
+	return events, nil
+}
+
+type EventCommon struct {
+	Source   int `mapstructure:"source"`
+	Category int `mapstructure:"category"`
+	Ctx      Ctx `mapstructure:"ctx"`
+}
+
+type Ctx struct {
+	Thread ThreadInfo `mapstructure:"thread_info"`
+	Fd     FdInfo     `mapstructure:"fd_info"`
+}
+
+type ThreadInfo struct {
+	Pid         uint32 `mapstructure:"pid"`
+	Tid         uint32 `mapstructure:"tid"`
+	Uid         uint32 `mapstructure:"uid"`
+	Gid         uint32 `mapstructure:"gid"`
+	Comm        string `mapstructure:"comm"`
+	ContainerId string `mapstructure:"container_id"`
+}
+
+type FdInfo struct {
+	Num      int32    `mapstructure:"num"`
+	TypeFd   int32    `mapstructure:"type_fd"`
+	Protocol uint32   `mapstructure:"protocol"`
+	Role     bool     `mapstructure:"role"`
+	Sip      []uint32 `mapstructure:"sip"`
+	Dip      []uint32 `mapstructure:"dip"`
+	Sport    uint32   `mapstructure:"sport"`
+	Dport    uint32   `mapstructure:"dport"`
+}
+
+type Trace struct {
+	Key       string        `mapstructure:"key"`
+	Connects  []TraceEvent  `mapstructure:"connects"`
+	Requests  []TraceEvent  `mapstructure:"requests"`
+	Responses []TraceEvent  `mapstructure:"responses"`
+	Expects   []TraceExpect `mapstructure:"expects"`
+}
+
+type TraceEvent struct {
+	Name           string         `mapstructure:"name"`
+	Timestamp      uint64         `mapstructure:"timestamp"`
+	UserAttributes UserAttributes `mapstructure:"user_attributes"`
+}
+
+type UserAttributes struct {
+	Latency int64    `mapstructure:"latency"`
+	Res     int64    `mapstructure:"res"`
+	Data    []string `mapstructure:"data"`
+}
+
+type TraceExpect struct {
+	Timestamp uint64                 `mapstructure:"Timestamp"`
+	Values    map[string]int64       `mapstructure:"Values"`
+	Labels    map[string]interface{} `mapstructure:"Labels"`
+}
+
+func (evt *TraceEvent) toKindlingEvent(common *EventCommon) (*model.KindlingEvent, error) {
+	data, err := decodeData(evt.UserAttributes.Data)
+	if err != nil {
+		return nil, err
+	}
+	return &model.KindlingEvent{
+		Source:       model.Source(common.Source),
+		Timestamp:    evt.Timestamp,
+		Latency:      uint64(evt.UserAttributes.Latency),
+		Name:         evt.Name,
+		Category:     model.Category(common.Category),
+		ParamsNumber: 3,
+		UserAttributes: [16]model.KeyValue{
+			{Key: "res", ValueType: model.ValueType_INT64, Value: int64ToBytes(evt.UserAttributes.Res)},
+			{Key: "data", ValueType: model.ValueType_BYTEBUF, Value: data},
+		},
+		Ctx: model.Context{
+			ThreadInfo: model.Thread{
+				Pid:         common.Ctx.Thread.Pid,
+				Tid:         common.Ctx.Thread.Tid,
+				Uid:         common.Ctx.Thread.Uid,
+				Gid:         common.Ctx.Thread.Gid,
+				Comm:        common.Ctx.Thread.Comm,
+				ContainerId: common.Ctx.Thread.ContainerId,
+			},
+			FdInfo: model.Fd{
+				Num:      common.Ctx.Fd.Num,
+				TypeFd:   model.FDType(common.Ctx.Fd.TypeFd),
+				Protocol: model.L4Proto(common.Ctx.Fd.Protocol),
+				Role:     common.Ctx.Fd.Role,
+				Sip:      common.Ctx.Fd.Sip,
+				Dip:      common.Ctx.Fd.Dip,
+				Sport:    common.Ctx.Fd.Sport,
+				Dport:    common.Ctx.Fd.Dport,
+			},
+		},
+	}, nil
+}
+
+// decodeData converts the data of the fixtures to bytes. Each line is one of the following formats:
+//  1. {hex number}|{string}: the hex part is followed by the string.
+//  2. hex|{hex value}
+//  3. string|{string value}
+//  4. {string value}: the line is a string if there is no separator "|".
+func decodeData(lines []string) ([]byte, error) {
+	data := make([]byte, 0)
+	for _, line := range lines {
+		if len(line) == 0 {
+			continue
+		}
+		splitIndex := strings.Index(line, "|")
+		// The whole line is a string if there is no separator or the separator is the first character.
+		if splitIndex <= 0 {
+			data = append(data, line...)
+			continue
+		}
+		prefix := strings.TrimSpace(line[:splitIndex])
+		suffix := strings.TrimSpace(line[splitIndex+1:])
+		switch prefix {
+		case "hex":
+			hexBytes, err := hex.DecodeString(suffix)
+			if err != nil {
+				return nil, fmt.Errorf("the second part is not a hexadecimal number: %w", err)
+			}
+			data = append(data, hexBytes...)
+		case "string":
+			data = append(data, suffix...)
+		default:
+			hexBytes, err := hex.DecodeString(prefix)
+			if err != nil {
+				return nil, fmt.Errorf("the first part is not a hexadecimal number: %w", err)
+			}
+			data = append(data, hexBytes...)
+			data = append(data, suffix...)
+		}
+	}
+	return data, nil
+}
+
+func int64ToBytes(value int64) []byte {
+	buf := make([]byte, 8)
+	binary.LittleEndian.PutUint64(buf, uint64(value))
+	return buf
+}
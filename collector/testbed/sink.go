@@ -0,0 +1,75 @@
+package testbed
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+)
+
+// Sink is the last consumer of the pipeline. It keeps a copy of every data group it receives,
+// because the analyzers reuse the data groups after they are consumed.
+type Sink struct {
+	mutex      sync.Mutex
+	cond       *sync.Cond
+	dataGroups []*model.DataGroup
+}
+
+func NewSink() *Sink {
+	s := &Sink{}
+	s.cond = sync.NewCond(&s.mutex)
+	return s
+}
+
+func (s *Sink) Consume(dataGroup *model.DataGroup) error {
+	s.mutex.Lock()
+	s.dataGroups = append(s.dataGroups, dataGroup.Clone())
+	s.mutex.Unlock()
+	s.cond.Broadcast()
+	return nil
+}
+
+// Wait blocks until at least n data groups with the name have been received or the timeout
+// expires. It returns the data groups with the name received so far and removes them from the sink.
+func (s *Sink) Wait(name string, n int, timeout time.Duration) []*model.DataGroup {
+	timer := time.AfterFunc(timeout, s.cond.Broadcast)
+	defer timer.Stop()
+	deadline := time.Now().Add(timeout)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for s.count(name) < n && time.Now().Before(deadline) {
+		s.cond.Wait()
+	}
+	return s.take(name)
+}
+
+// Reset removes all data groups received.
+func (s *Sink) Reset() {
+	s.mutex.Lock()
+	s.dataGroups = nil
+	s.mutex.Unlock()
+}
+
+func (s *Sink) count(name string) int {
+	count := 0
+	for _, dataGroup := range s.dataGroups {
+		if dataGroup.Name == name {
+			count++
+		}
+	}
+	return count
+}
+
+func (s *Sink) take(name string) []*model.DataGroup {
+	taken := make([]*model.DataGroup, 0)
+	kept := s.dataGroups[:0]
+	for _, dataGroup := range s.dataGroups {
+		if dataGroup.Name == name {
+			taken = append(taken, dataGroup)
+		} else {
+			kept = append(kept, dataGroup)
+		}
+	}
+	s.dataGroups = kept
+	return taken
+}
@@ -0,0 +1,130 @@
+// Package testbed runs the collector components in-process and replays the recorded event
+// fixtures through them, so the whole pipeline from the analyzer to the exporter can be tested
+// without the probe or a Kubernetes cluster. The Sink takes the place of the exporter.
+package testbed
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/viper"
+
+	"github.com/Kindling-project/kindling/collector/pkg/component"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer"
+	"github.com/Kindling-project/kindling/collector/pkg/component/analyzer/network"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer"
+	"github.com/Kindling-project/kindling/collector/pkg/component/consumer/processor/aggregateprocessor"
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+)
+
+// Testbed is a pipeline of the network analyzer, the optional aggregate processor and the sink.
+type Testbed struct {
+	analyzer analyzer.Analyzer
+	sink     *Sink
+}
+
+type options struct {
+	networkConfig   *network.Config
+	aggregateConfig *aggregateprocessor.Config
+}
+
+type Option func(*options)
+
+// WithNetworkConfig sets the config of the network analyzer. The timeout check must be enabled
+// because the last request of each connection is only flushed by it.
+func WithNetworkConfig(cfg *network.Config) Option {
+	return func(o *options) {
+		o.networkConfig = cfg
+	}
+}
+
+// WithAggregation puts the aggregate processor between the analyzer and the sink.
+func WithAggregation(cfg *aggregateprocessor.Config) Option {
+	return func(o *options) {
+		o.aggregateConfig = cfg
+	}
+}
+
+// New builds the pipeline. The default config of the network analyzer is read from
+// testdata/network-config.yml.
+func New(opts ...Option) (*Testbed, error) {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.networkConfig == nil {
+		cfg, err := LoadNetworkConfig("testdata/network-config.yml")
+		if err != nil {
+			return nil, err
+		}
+		o.networkConfig = cfg
+	}
+	telemetry := component.NewDefaultTelemetryTools()
+	sink := NewSink()
+	var next consumer.Consumer = sink
+	if o.aggregateConfig != nil {
+		next = aggregateprocessor.New(o.aggregateConfig, telemetry, next)
+	}
+	return &Testbed{
+		analyzer: network.NewNetworkAnalyzer(o.networkConfig, telemetry, []consumer.Consumer{next}),
+		sink:     sink,
+	}, nil
+}
+
+// LoadNetworkConfig reads the config of the network analyzer from the file in the format of
+// kindling-collector-config.yml.
+func LoadNetworkConfig(path string) (*network.Config, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	cfg := network.NewDefaultConfig()
+	// The slices are cleared otherwise the elements of the file are merged into the default ones.
+	cfg.ProtocolParser = nil
+	cfg.ProtocolConfigs = nil
+	if err := v.UnmarshalKey("analyzers.networkanalyzer", cfg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s: %w", path, err)
+	}
+	cfg.EnableTimeoutCheck = true
+	return cfg, nil
+}
+
+func (tb *Testbed) Start() error {
+	return tb.analyzer.Start()
+}
+
+func (tb *Testbed) Shutdown() error {
+	return tb.analyzer.Shutdown()
+}
+
+func (tb *Testbed) Sink() *Sink {
+	return tb.sink
+}
+
+// Replay sends the events to the analyzer in order.
+func (tb *Testbed) Replay(events []*model.KindlingEvent) error {
+	for _, event := range events {
+		if err := tb.analyzer.ConsumeEvent(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReplayFixture replays the fixture and waits for the records it expects. The records with the
+// name received within the timeout are returned. If the fixture expects no record, it waits for
+// the whole timeout so the pending requests are flushed before the next fixture is replayed.
+func (tb *Testbed) ReplayFixture(fixture *Fixture, name string, timeout time.Duration) ([]*model.DataGroup, error) {
+	events, err := fixture.Events()
+	if err != nil {
+		return nil, err
+	}
+	if err = tb.Replay(events); err != nil {
+		return nil, err
+	}
+	if len(fixture.Trace.Expects) == 0 {
+		time.Sleep(timeout)
+	}
+	return tb.sink.Wait(name, len(fixture.Trace.Expects), timeout), nil
+}
@@ -0,0 +1,57 @@
+package testbed
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Kindling-project/kindling/collector/pkg/model"
+)
+
+// Validate checks the records against the expects of the trace. All metrics and labels of the
+// records must be expected, except the labels with zero values. The data groups are reused by the
+// analyzer and keep the keys of the previous records with zero values.
+func Validate(t testing.TB, expects []TraceExpect, records []*model.DataGroup) {
+	if !assert.Len(t, records, len(expects), "the number of records") {
+		return
+	}
+	for i, record := range records {
+		expect := expects[i]
+		assert.Equal(t, expect.Timestamp, record.Timestamp, "timestamp of record %d", i)
+
+		assert.Len(t, record.Metrics, len(expect.Values), "metrics of record %d", i)
+		for _, metric := range record.Metrics {
+			want, ok := expect.Values[metric.Name]
+			if assert.True(t, ok, "unexpected metric %s of record %d", metric.Name, i) {
+				assert.Equal(t, want, metric.GetInt().Value, "metric %s of record %d", metric.Name, i)
+			}
+		}
+
+		for key, want := range expect.Labels {
+			switch want := want.(type) {
+			case int:
+				assert.Equal(t, int64(want), record.Labels.GetIntValue(key), "label %s of record %d", key, i)
+			case bool:
+				assert.Equal(t, want, record.Labels.GetBoolValue(key), "label %s of record %d", key, i)
+			default:
+				assert.Equal(t, want, record.Labels.GetStringValue(key), "label %s of record %d", key, i)
+			}
+		}
+		for key, value := range record.Labels.GetValues() {
+			if _, ok := expect.Labels[key]; !ok && !isZeroValue(value) {
+				t.Errorf("unexpected label %s=%s of record %d", key, value.ToString(), i)
+			}
+		}
+	}
+}
+
+func isZeroValue(value model.AttributeValue) bool {
+	switch value.Type() {
+	case model.IntAttributeValueType:
+		return value.ToString() == "0"
+	case model.BooleanAttributeValueType:
+		return value.ToString() == "false"
+	default:
+		return value.ToString() == ""
+	}
+}
@@ -4,6 +4,7 @@ kubectl create ns kindling
 kubectl create serviceaccount kindling-agent -nkindling
 kubectl apply -f kindling-clusterrole.yml
 kubectl apply -f kindling-clusterrolebinding.yml
+kubectl apply -f kindling-config-crd.yml
 kubectl create cm kindlingcfg -n kindling --from-file=kindling-collector-config.yml
 kubectl apply -f camera-front-configmap.yml
 kubectl apply -f kindling-deploy.yml
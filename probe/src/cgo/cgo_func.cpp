@@ -26,4 +26,5 @@ void stopProfileDebug() { stop_profile_debug(); }
 
 void getCaptureStatistics(struct capture_statistics_for_go* stats) { get_capture_statistics(stats); }
 void catchSignalUp() { sig_set_up(); }
+void setSnaplenForGo(int snaplen) { set_dynamic_snaplen(snaplen); }
 
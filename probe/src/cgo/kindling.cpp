@@ -125,6 +125,7 @@ void set_eventmask(sinsp* inspector) {
 }
 
 #define KINDLING_DEFAULT_SNAPLEN 1000
+uint32_t configured_snaplen = KINDLING_DEFAULT_SNAPLEN;
 void set_snaplen(sinsp* inspector) {
   uint32_t snaplen = KINDLING_DEFAULT_SNAPLEN;
 
@@ -138,6 +139,19 @@ void set_snaplen(sinsp* inspector) {
   }
 
   cout << "Set snaplen to value: " << snaplen << endl;
+  configured_snaplen = snaplen;
+  inspector->set_snaplen(snaplen);
+}
+
+// The snaplen negotiated by the collector never exceeds the configured one.
+void set_dynamic_snaplen(uint32_t snaplen) {
+  if (inspector == nullptr || snaplen == 0) {
+    return;
+  }
+  if (snaplen > configured_snaplen) {
+    snaplen = configured_snaplen;
+  }
+  cout << "Set snaplen to negotiated value: " << snaplen << endl;
   inspector->set_snaplen(snaplen);
 }
 
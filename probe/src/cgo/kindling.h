@@ -52,6 +52,8 @@ void attach_agent(int64_t pid, char* error_message, bool is_attach);
 
 void get_capture_statistics(struct capture_statistics_for_go* stats);
 
+void set_dynamic_snaplen(uint32_t snaplen);
+
 uint16_t get_protocol(scap_l4_proto proto);
 uint16_t get_type(ppm_param_type type);
 uint16_t get_kindling_source(uint16_t etype);